@@ -96,6 +96,10 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	if err := config.Load("config.yaml"); err != nil {
+		slog.Error("Failed to load config", "error", err)
+		os.Exit(1)
+	}
 	initI18n()
 	initLogger()
 	initRedis()
@@ -119,7 +123,7 @@ func main() {
 	// Tạo router và cấu hình routes
 	router := gin.New()
 	apiRouter := restapi.NewRouter(db)
-	apiRouter.SetupRoutes(router)
+	apiRouter.SetupRoutes(ctx, router)
 
 	// Cấu hình server
 	serverPort := config.MustGet().Port
@@ -161,5 +165,7 @@ func main() {
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		slog.Error("Server forced to shutdown", "error", err)
 	}
+	// Đợi các job nền dừng trước khi đóng kết nối database
+	apiRouter.Wait()
 	slog.Info("Server exited properly")
 }
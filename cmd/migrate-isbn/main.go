@@ -0,0 +1,134 @@
+// Command migrate-isbn is a one-off migration to canonical ISBN-13s. It
+// reports live books whose ISBNs are the same number in different forms,
+// and with -apply rewrites every other book's ISBN in canonical form.
+// Duplicates are left for an editor to merge or delete, then the command
+// can be run again. It needs the isbn10 column of
+// migrations/007_books_isbn10.sql. The database is the one of the
+// configuration file given with -config.
+package main
+
+import (
+	isbnlib "book_system/internal/baselib/isbn"
+	"book_system/internal/config"
+	"book_system/internal/infrastructure"
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+)
+
+// entry is the ISBN state of one book
+type entry struct {
+	id     uuid.UUID
+	title  string
+	isbn   string
+	isbn10 string
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout))
+}
+
+// run migrates the ISBNs as the command line args ask, writing its report
+// to stdout, and returns the exit status: 0 when done, 1 on failure and 2
+// when duplicate or invalid ISBNs were left for an editor
+func run(args []string, stdout io.Writer) int {
+	flags := flag.NewFlagSet("migrate-isbn", flag.ContinueOnError)
+	configPath := flags.String("config", "config.yaml", "configuration file with the database to migrate")
+	apply := flags.Bool("apply", false, "rewrite the ISBNs that have no duplicate (default: report only)")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 1
+	}
+
+	if err := config.Load(*configPath); err != nil {
+		slog.Error("Failed to load config", slog.Any("error", err))
+		return 1
+	}
+	db, err := infrastructure.InitDB()
+	if err != nil {
+		slog.Error("Failed to connect to database", slog.Any("error", err))
+		return 1
+	}
+	defer infrastructure.CloseDB()
+
+	ctx := context.Background()
+	bookRepo := repository.NewBookRepository(db)
+
+	groups := make(map[string][]entry)
+	var invalid []entry
+	err = bookRepo.FindInBatches(ctx, nil, 1000, func(books []*model.Book) error {
+		for _, book := range books {
+			e := entry{id: book.ID, title: book.Title, isbn: book.ISBN, isbn10: book.ISBN10}
+			isbn13, err := isbnlib.Normalize(book.ISBN)
+			if err != nil {
+				invalid = append(invalid, e)
+				continue
+			}
+			groups[isbn13] = append(groups[isbn13], e)
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to read books", slog.Any("error", err))
+		return 1
+	}
+
+	isbns := make([]string, 0, len(groups))
+	for isbn13 := range groups {
+		isbns = append(isbns, isbn13)
+	}
+	sort.Strings(isbns)
+
+	out := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	duplicates, pending, rewritten := 0, 0, 0
+	for _, isbn13 := range isbns {
+		entries := groups[isbn13]
+		if len(entries) > 1 {
+			duplicates++
+			fmt.Fprintf(out, "DUPLICATE %s\n", isbn13)
+			for _, e := range entries {
+				fmt.Fprintf(out, "\t%s\t%s\t%s\n", e.id, e.isbn, e.title)
+			}
+			continue
+		}
+
+		e := entries[0]
+		isbn10 := isbnlib.To10(isbn13)
+		if e.isbn == isbn13 && e.isbn10 == isbn10 {
+			continue
+		}
+		pending++
+		if !*apply {
+			continue
+		}
+		if err := bookRepo.UpdateISBN(ctx, e.id, isbn13, isbn10); err != nil {
+			slog.Error("Failed to rewrite ISBN", slog.String("book_id", e.id.String()), slog.Any("error", err))
+			continue
+		}
+		rewritten++
+	}
+	for _, e := range invalid {
+		fmt.Fprintf(out, "INVALID\t%s\t%s\t%s\n", e.id, e.isbn, e.title)
+	}
+	out.Flush()
+
+	fmt.Fprintf(stdout, "\n%d ISBNs shared by several books, %d invalid ISBNs, %d books to rewrite, %d rewritten\n",
+		duplicates, len(invalid), pending, rewritten)
+	if duplicates > 0 || len(invalid) > 0 {
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunHelp(t *testing.T) {
+	if status := run([]string{"-h"}, io.Discard); status != 0 {
+		t.Errorf("run(-h) = %d, want 0", status)
+	}
+	if status := run([]string{"-unknown"}, io.Discard); status != 1 {
+		t.Errorf("run(-unknown) = %d, want 1", status)
+	}
+}
+
+// TestRunLoadsConfig runs the command against a database that refuses
+// connections: it must load the configuration and fail to connect, not
+// panic for want of a configuration. The configuration loads once per
+// process, so this is the only test that gets that far.
+func TestRunLoadsConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	config := `database:
+  mysql:
+    host: 127.0.0.1
+    port: 1
+    user: user
+    password: password
+    database: book_system
+`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	if status := run([]string{"-config", path}, io.Discard); status != 1 {
+		t.Errorf("run() = %d, want 1 for an unreachable database", status)
+	}
+}
@@ -0,0 +1,469 @@
+-- Schema of the database, run by the MySQL container on its first start.
+-- Databases created before a change get it from the files in migrations/.
+-- active_isbn mirrors isbn while a book is live and is NULL once it is in the
+-- trash, so idx_books_active_isbn only keeps live books' ISBNs unique.
+
+CREATE TABLE IF NOT EXISTS books (
+    id           CHAR(36)       NOT NULL,
+    title        VARCHAR(255)   NOT NULL,
+    author       VARCHAR(255)   NOT NULL,
+    description  TEXT,
+    cover_image  VARCHAR(512),
+    price        DECIMAL(11, 3) NOT NULL,
+    currency     VARCHAR(3),
+    stock        BIGINT         NOT NULL DEFAULT 0,
+    isbn         VARCHAR(20),
+    isbn10       VARCHAR(10),
+    active_isbn  VARCHAR(20) GENERATED ALWAYS AS (IF(deleted_at IS NULL, isbn, NULL)) STORED,
+    published_at DATE,
+    work_id      CHAR(36),
+    category_id  CHAR(36),
+    format       VARCHAR(20),
+    language     VARCHAR(35),
+    page_count   BIGINT         NOT NULL DEFAULT 0,
+    translations JSON,
+    cover        JSON,
+    extra        JSON,
+    rating_sum   BIGINT         NOT NULL DEFAULT 0,
+    rating_count BIGINT         NOT NULL DEFAULT 0,
+    version      BIGINT         NOT NULL DEFAULT 1,
+    created_at   DATETIME(3)    NOT NULL,
+    updated_at   DATETIME(3)    NOT NULL,
+    deleted_at   DATETIME(3),
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_books_active_isbn (active_isbn),
+    INDEX idx_books_isbn (isbn),
+    INDEX idx_books_work_id (work_id),
+    INDEX idx_books_category_id (category_id),
+    INDEX idx_books_rating_count (rating_count),
+    INDEX idx_books_deleted_at (deleted_at)
+);
+
+CREATE TABLE IF NOT EXISTS book_versions (
+    id         CHAR(36)    NOT NULL,
+    book_id    CHAR(36)    NOT NULL,
+    version    BIGINT      NOT NULL,
+    action     VARCHAR(20) NOT NULL,
+    actor_id   VARCHAR(64),
+    snapshot   JSON        NOT NULL,
+    changes    JSON,
+    created_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_book_versions_book_version (book_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id            CHAR(36)     NOT NULL,
+    type          VARCHAR(50)  NOT NULL,
+    status        VARCHAR(20)  NOT NULL,
+    actor_id      VARCHAR(64),
+    params        JSON,
+    total         BIGINT       NOT NULL DEFAULT 0,
+    succeeded     BIGINT       NOT NULL DEFAULT 0,
+    failed        BIGINT       NOT NULL DEFAULT 0,
+    result_object VARCHAR(512),
+    unmapped      JSON,
+    error         TEXT,
+    started_at    DATETIME(3),
+    finished_at   DATETIME(3),
+    created_at    DATETIME(3)  NOT NULL,
+    updated_at    DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_jobs_type (type),
+    INDEX idx_jobs_status (status)
+);
+
+CREATE TABLE IF NOT EXISTS series (
+    id          CHAR(36)     NOT NULL,
+    title       VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at  DATETIME(3)  NOT NULL,
+    updated_at  DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS works (
+    id              CHAR(36)      NOT NULL,
+    title           VARCHAR(255)  NOT NULL,
+    author          VARCHAR(255),
+    description     TEXT,
+    series_id       CHAR(36),
+    series_position DECIMAL(6, 2) NOT NULL DEFAULT 0,
+    created_at      DATETIME(3)   NOT NULL,
+    updated_at      DATETIME(3)   NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_works_series_position (series_id, series_position)
+);
+
+CREATE TABLE IF NOT EXISTS categories (
+    id           CHAR(36)     NOT NULL,
+    name         VARCHAR(100) NOT NULL,
+    translations JSON,
+    created_at   DATETIME(3)  NOT NULL,
+    updated_at   DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_categories_name (name)
+);
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id          CHAR(36)     NOT NULL,
+    book_id     CHAR(36)     NOT NULL,
+    location_id CHAR(36),
+    transfer_id CHAR(36),
+    type        VARCHAR(20)  NOT NULL,
+    quantity    BIGINT       NOT NULL,
+    stock_after BIGINT       NOT NULL,
+    reason      VARCHAR(255),
+    actor_id    VARCHAR(64),
+    created_at  DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_stock_movements_book_created (book_id, created_at),
+    INDEX idx_stock_movements_location_id (location_id),
+    INDEX idx_stock_movements_transfer_id (transfer_id)
+);
+
+CREATE TABLE IF NOT EXISTS locations (
+    id         CHAR(36)     NOT NULL,
+    code       VARCHAR(20)  NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    type       VARCHAR(20)  NOT NULL,
+    address    VARCHAR(512),
+    created_at DATETIME(3)  NOT NULL,
+    updated_at DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_locations_code (code)
+);
+
+CREATE TABLE IF NOT EXISTS stock_levels (
+    book_id     CHAR(36)    NOT NULL,
+    location_id CHAR(36)    NOT NULL,
+    quantity    BIGINT      NOT NULL DEFAULT 0,
+    updated_at  DATETIME(3) NOT NULL,
+    PRIMARY KEY (book_id, location_id),
+    INDEX idx_stock_levels_location_id (location_id)
+);
+
+CREATE TABLE IF NOT EXISTS cart_items (
+    user_id    CHAR(36)    NOT NULL,
+    book_id    CHAR(36)    NOT NULL,
+    quantity   BIGINT      NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    updated_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (user_id, book_id)
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id               CHAR(36)       NOT NULL,
+    user_id          CHAR(36)       NOT NULL,
+    status           VARCHAR(20)    NOT NULL,
+    location_id      CHAR(36),
+    currency         VARCHAR(3)     NOT NULL,
+    subtotal         DECIMAL(16, 3) NOT NULL,
+    discount         DECIMAL(16, 3) NOT NULL DEFAULT 0,
+    coupon_id        CHAR(36),
+    coupon_code      VARCHAR(50),
+    total            DECIMAL(16, 3) NOT NULL,
+    shipping_address VARCHAR(512)   NOT NULL,
+    paid_at          DATETIME(3),
+    shipped_at       DATETIME(3),
+    delivered_at     DATETIME(3),
+    cancelled_at     DATETIME(3),
+    refunded_at      DATETIME(3),
+    created_at       DATETIME(3)    NOT NULL,
+    updated_at       DATETIME(3)    NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_orders_user_id (user_id),
+    INDEX idx_orders_status (status),
+    INDEX idx_orders_coupon_id (coupon_id),
+    INDEX idx_orders_created_at (created_at)
+);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id               CHAR(36)       NOT NULL,
+    order_id         CHAR(36)       NOT NULL,
+    book_id          CHAR(36)       NOT NULL,
+    title            VARCHAR(255)   NOT NULL,
+    isbn             VARCHAR(20),
+    list_price       DECIMAL(16, 3) NOT NULL,
+    unit_price       DECIMAL(16, 3) NOT NULL,
+    quantity         BIGINT         NOT NULL,
+    line_total       DECIMAL(16, 3) NOT NULL,
+    exchange_rate_id CHAR(36),
+    PRIMARY KEY (id),
+    INDEX idx_order_items_order_id (order_id),
+    INDEX idx_order_items_book_id (book_id)
+);
+
+CREATE TABLE IF NOT EXISTS payments (
+    id             CHAR(36)       NOT NULL,
+    order_id       CHAR(36)       NOT NULL,
+    attempt        BIGINT         NOT NULL DEFAULT 1,
+    user_id        CHAR(36)       NOT NULL,
+    amount         DECIMAL(16, 3) NOT NULL,
+    currency       VARCHAR(3)     NOT NULL,
+    book_ids       JSON,
+    provider       VARCHAR(50)    NOT NULL,
+    provider_ref   VARCHAR(100),
+    client_secret  VARCHAR(255),
+    status         VARCHAR(20)    NOT NULL,
+    failure_reason VARCHAR(255),
+    captured_at    DATETIME(3),
+    refunded_at    DATETIME(3),
+    created_at     DATETIME(3)    NOT NULL,
+    updated_at     DATETIME(3)    NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_payments_order_attempt (order_id, attempt),
+    UNIQUE INDEX idx_payments_provider_ref (provider_ref),
+    INDEX idx_payments_user_id (user_id),
+    INDEX idx_payments_status (status),
+    INDEX idx_payments_created_at (created_at)
+);
+
+CREATE TABLE IF NOT EXISTS payment_events (
+    id          VARCHAR(100) NOT NULL,
+    payment_id  CHAR(36)     NOT NULL,
+    status      VARCHAR(20)  NOT NULL,
+    received_at DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_payment_events_payment_id (payment_id)
+);
+
+CREATE TABLE IF NOT EXISTS price_rules (
+    id          CHAR(36)       NOT NULL,
+    name        VARCHAR(255)   NOT NULL,
+    type        VARCHAR(20)    NOT NULL,
+    value       DECIMAL(16, 3) NOT NULL,
+    currency    VARCHAR(3)     NOT NULL,
+    scope_type  VARCHAR(20)    NOT NULL,
+    scope_value VARCHAR(255),
+    starts_at   DATETIME(3),
+    ends_at     DATETIME(3),
+    active      BOOLEAN        NOT NULL DEFAULT TRUE,
+    created_at  DATETIME(3)    NOT NULL,
+    updated_at  DATETIME(3)    NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_price_rules_starts_at (starts_at),
+    INDEX idx_price_rules_ends_at (ends_at)
+);
+
+CREATE TABLE IF NOT EXISTS coupons (
+    id           CHAR(36)       NOT NULL,
+    code         VARCHAR(50)    NOT NULL,
+    type         VARCHAR(20)    NOT NULL,
+    value        DECIMAL(16, 3) NOT NULL,
+    currency     VARCHAR(3)     NOT NULL,
+    min_subtotal DECIMAL(16, 3) NOT NULL DEFAULT 0,
+    max_uses     BIGINT         NOT NULL DEFAULT 0,
+    uses         BIGINT         NOT NULL DEFAULT 0,
+    starts_at    DATETIME(3),
+    ends_at      DATETIME(3),
+    active       BOOLEAN        NOT NULL DEFAULT TRUE,
+    created_at   DATETIME(3)    NOT NULL,
+    updated_at   DATETIME(3)    NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_coupons_code (code)
+);
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id             CHAR(36)        NOT NULL,
+    base_currency  VARCHAR(3)      NOT NULL,
+    quote_currency VARCHAR(3)      NOT NULL,
+    rate           DECIMAL(20, 10) NOT NULL,
+    source         VARCHAR(20)     NOT NULL,
+    effective_at   DATETIME(3)     NOT NULL,
+    created_at     DATETIME(3)     NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_exchange_rates_pair (base_currency, quote_currency),
+    INDEX idx_exchange_rates_effective_at (effective_at)
+);
+
+CREATE TABLE IF NOT EXISTS book_prices (
+    book_id    CHAR(36)       NOT NULL,
+    currency   VARCHAR(3)     NOT NULL,
+    amount     DECIMAL(16, 3) NOT NULL,
+    updated_at DATETIME(3)    NOT NULL,
+    PRIMARY KEY (book_id, currency)
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id         CHAR(36)     NOT NULL,
+    username   VARCHAR(100) NOT NULL,
+    email      VARCHAR(100) NOT NULL,
+    password   VARCHAR(255) NOT NULL,
+    full_name  VARCHAR(100) NOT NULL,
+    role       VARCHAR(20)  NOT NULL DEFAULT 'user',
+    avatar     VARCHAR(255),
+    currency   VARCHAR(3),
+    is_active  BOOLEAN      NOT NULL DEFAULT TRUE,
+    last_login DATETIME(3),
+    created_at DATETIME(3)  NOT NULL,
+    updated_at DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_users_username (username),
+    UNIQUE INDEX idx_users_email (email)
+);
+
+CREATE TABLE IF NOT EXISTS copies (
+    id          CHAR(36)    NOT NULL,
+    book_id     CHAR(36)    NOT NULL,
+    barcode     VARCHAR(50) NOT NULL,
+    location_id CHAR(36),
+    status      VARCHAR(20) NOT NULL,
+    created_at  DATETIME(3) NOT NULL,
+    updated_at  DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_copies_barcode (barcode),
+    INDEX idx_copies_book_id (book_id),
+    INDEX idx_copies_location_id (location_id),
+    INDEX idx_copies_status (status)
+);
+
+CREATE TABLE IF NOT EXISTS loans (
+    id          CHAR(36)       NOT NULL,
+    copy_id     CHAR(36)       NOT NULL,
+    book_id     CHAR(36)       NOT NULL,
+    user_id     CHAR(36)       NOT NULL,
+    barcode     VARCHAR(50)    NOT NULL,
+    title       VARCHAR(255)   NOT NULL,
+    status      VARCHAR(20)    NOT NULL,
+    loaned_at   DATETIME(3)    NOT NULL,
+    due_at      DATETIME(3)    NOT NULL,
+    returned_at DATETIME(3),
+    renewals    BIGINT         NOT NULL DEFAULT 0,
+    fine        DECIMAL(16, 3) NOT NULL DEFAULT 0,
+    created_at  DATETIME(3)    NOT NULL,
+    updated_at  DATETIME(3)    NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_loans_copy_id (copy_id),
+    INDEX idx_loans_book_id (book_id),
+    INDEX idx_loans_user_id (user_id),
+    INDEX idx_loans_status (status),
+    INDEX idx_loans_loaned_at (loaned_at),
+    INDEX idx_loans_due_at (due_at)
+);
+
+CREATE TABLE IF NOT EXISTS holds (
+    id           CHAR(36)    NOT NULL,
+    book_id      CHAR(36)    NOT NULL,
+    user_id      CHAR(36)    NOT NULL,
+    status       VARCHAR(20) NOT NULL,
+    ready_at     DATETIME(3),
+    expires_at   DATETIME(3),
+    fulfilled_at DATETIME(3),
+    cancelled_at DATETIME(3),
+    expired_at   DATETIME(3),
+    created_at   DATETIME(3) NOT NULL,
+    updated_at   DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_holds_queue (book_id, status, created_at),
+    INDEX idx_holds_user_id (user_id),
+    INDEX idx_holds_expires_at (expires_at)
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id         CHAR(36)     NOT NULL,
+    user_id    CHAR(36)     NOT NULL,
+    type       VARCHAR(50)  NOT NULL,
+    message    VARCHAR(512) NOT NULL,
+    book_id    CHAR(36),
+    read_at    DATETIME(3),
+    created_at DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_notifications_user_id (user_id),
+    INDEX idx_notifications_created_at (created_at)
+);
+
+CREATE TABLE IF NOT EXISTS fine_policies (
+    id           CHAR(36)       NOT NULL,
+    role         VARCHAR(20)    NOT NULL,
+    daily_rate   DECIMAL(16, 3) NOT NULL,
+    grace_days   BIGINT         NOT NULL DEFAULT 0,
+    max_per_item DECIMAL(16, 3) NOT NULL DEFAULT 0,
+    created_at   DATETIME(3)    NOT NULL,
+    updated_at   DATETIME(3)    NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_fine_policies_role (role)
+);
+
+CREATE TABLE IF NOT EXISTS account_entries (
+    id         CHAR(36)       NOT NULL,
+    user_id    CHAR(36)       NOT NULL,
+    loan_id    CHAR(36),
+    type       VARCHAR(20)    NOT NULL,
+    amount     DECIMAL(16, 3) NOT NULL,
+    note       VARCHAR(255),
+    actor_id   VARCHAR(36),
+    created_at DATETIME(3)    NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_account_entries_user_id (user_id),
+    INDEX idx_account_entries_loan_id (loan_id),
+    INDEX idx_account_entries_created_at (created_at)
+);
+
+CREATE TABLE IF NOT EXISTS reviews (
+    id              CHAR(36)     NOT NULL,
+    book_id         CHAR(36)     NOT NULL,
+    user_id         CHAR(36)     NOT NULL,
+    author          VARCHAR(100) NOT NULL,
+    rating          BIGINT       NOT NULL,
+    body            TEXT,
+    status          VARCHAR(20)  NOT NULL,
+    moderation_note VARCHAR(255),
+    moderated_by    VARCHAR(36),
+    moderated_at    DATETIME(3),
+    created_at      DATETIME(3)  NOT NULL,
+    updated_at      DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_reviews_book_user (book_id, user_id),
+    INDEX idx_reviews_user_id (user_id),
+    INDEX idx_reviews_status (status),
+    INDEX idx_reviews_created_at (created_at)
+);
+
+CREATE TABLE IF NOT EXISTS reading_lists (
+    id          CHAR(36)     NOT NULL,
+    user_id     CHAR(36)     NOT NULL,
+    name        VARCHAR(100) NOT NULL,
+    kind        VARCHAR(20)  NOT NULL,
+    share_token VARCHAR(64),
+    created_at  DATETIME(3)  NOT NULL,
+    updated_at  DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_reading_lists_user_name (user_id, name),
+    UNIQUE INDEX idx_reading_lists_share_token (share_token)
+);
+
+CREATE TABLE IF NOT EXISTS reading_list_items (
+    list_id    CHAR(36)    NOT NULL,
+    book_id    CHAR(36)    NOT NULL,
+    position   BIGINT      NOT NULL,
+    page       BIGINT      NOT NULL DEFAULT 0,
+    percent    BIGINT      NOT NULL DEFAULT 0,
+    created_at DATETIME(3) NOT NULL,
+    updated_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (list_id, book_id),
+    INDEX idx_reading_list_items_book_id (book_id)
+);
+
+CREATE TABLE IF NOT EXISTS book_views (
+    id             CHAR(36)    NOT NULL,
+    user_id        CHAR(36)    NOT NULL,
+    book_id        CHAR(36)    NOT NULL,
+    views          BIGINT      NOT NULL DEFAULT 1,
+    last_viewed_at DATETIME(3) NOT NULL,
+    created_at     DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_book_views_user_book (user_id, book_id),
+    INDEX idx_book_views_book_id (book_id),
+    INDEX idx_book_views_last_viewed_at (last_viewed_at)
+);
+
+CREATE TABLE IF NOT EXISTS book_similarities (
+    book_id         CHAR(36)    NOT NULL,
+    similar_book_id CHAR(36)    NOT NULL,
+    score           DOUBLE      NOT NULL,
+    co_views        BIGINT      NOT NULL DEFAULT 0,
+    updated_at      DATETIME(3) NOT NULL,
+    PRIMARY KEY (book_id, similar_book_id),
+    INDEX idx_book_similarities_updated_at (updated_at)
+);
@@ -11,12 +11,15 @@ import (
 
 var localizer = initLocal()
 
+// languages lists the loaded languages, the bundle's default first
+var languages = []string{DefaultLanguage}
+
 func initLocal() map[string]*i18n.Localizer {
 	return map[string]*i18n.Localizer{}
 }
 
 func InitI18n(langs []string) {
-	bundle := i18n.NewBundle(language.English)
+	bundle := i18n.NewBundle(language.Make(DefaultLanguage))
 	bundle.RegisterUnmarshalFunc("json", json.Unmarshal)
 	for _, lang := range langs {
 		_, err := bundle.LoadMessageFile(fmt.Sprintf("i18n/%v.json", lang))
@@ -24,6 +27,9 @@ func InitI18n(langs []string) {
 			log.Fatal(err.Error())
 		}
 		localizer[lang] = i18n.NewLocalizer(bundle, lang)
+		if lang != DefaultLanguage {
+			languages = append(languages, lang)
+		}
 	}
 }
 
@@ -0,0 +1,51 @@
+package i18n
+
+import (
+	"slices"
+
+	"golang.org/x/text/language"
+)
+
+// DefaultLanguage is used when a request accepts none of the available languages
+const DefaultLanguage = "en"
+
+// Languages lists the languages messages are available in, the default first
+func Languages() []string {
+	return slices.Clone(languages)
+}
+
+// IsSupported reports whether messages are available in lang
+func IsSupported(lang string) bool {
+	return slices.Contains(languages, lang)
+}
+
+// Match picks the loaded language that best fits an Accept-Language header
+func Match(acceptLanguage string) string {
+	lang, _ := Negotiate(acceptLanguage, languages)
+	return lang
+}
+
+// Negotiate picks the entry of available that best fits an Accept-Language
+// header, honouring q-values and falling back from regional variants to
+// their base language. When nothing acceptable is available it returns
+// available[0] and false.
+func Negotiate(acceptLanguage string, available []string) (string, bool) {
+	if len(available) == 0 {
+		return "", false
+	}
+
+	accepted, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(accepted) == 0 {
+		return available[0], false
+	}
+
+	tags := make([]language.Tag, len(available))
+	for i, lang := range available {
+		tags[i] = language.Make(lang)
+	}
+	_, index, confidence := language.NewMatcher(tags).Match(accepted...)
+	if confidence == language.No {
+		return available[0], false
+	}
+	return available[index], true
+}
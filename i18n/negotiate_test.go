@@ -0,0 +1,36 @@
+package i18n
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	available := []string{"en", "vi", "pt-BR"}
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		want           string
+		wantOK         bool
+	}{
+		{name: "exact", acceptLanguage: "vi", want: "vi", wantOK: true},
+		{name: "q-values outrank order", acceptLanguage: "en;q=0.3, vi;q=0.9", want: "vi", wantOK: true},
+		{name: "regional variant falls back to its base", acceptLanguage: "vi-VN", want: "vi", wantOK: true},
+		{name: "base language finds a regional variant", acceptLanguage: "pt", want: "pt-BR", wantOK: true},
+		{name: "first acceptable wins over later ones", acceptLanguage: "fr, vi, en", want: "vi", wantOK: true},
+		{name: "nothing acceptable", acceptLanguage: "fr, de;q=0.5", want: "en"},
+		{name: "no header", want: "en"},
+		{name: "malformed header", acceptLanguage: ";;q=x", want: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Negotiate(tt.acceptLanguage, available)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Negotiate(%q) = %q, %v, want %q, %v", tt.acceptLanguage, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	if got, ok := Negotiate("en", nil); got != "" || ok {
+		t.Errorf("Negotiate() with nothing available = %q, %v", got, ok)
+	}
+}
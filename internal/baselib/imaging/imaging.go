@@ -0,0 +1,86 @@
+// Package imaging decodes uploaded images, checking that their content is
+// really an image, and produces resized, metadata-free copies of them.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Supported image formats, as named by image.Decode
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatWebP = "webp"
+)
+
+// MaxPixels bounds the decoded size of an image, so a small file cannot
+// expand into an image too large to hold in memory
+const MaxPixels = 40_000_000
+
+var (
+	// ErrUnsupported is returned for content that is not a JPEG, PNG or WebP image
+	ErrUnsupported = errors.New("unsupported image format")
+	// ErrTooLarge is returned for images with more than MaxPixels pixels
+	ErrTooLarge = errors.New("image dimensions are too large")
+)
+
+// Decode decodes a JPEG, PNG or WebP image from its content, whatever the
+// file is named or claims to be. JPEGs are turned upright according to
+// their EXIF orientation, since the EXIF data is not kept in any copy.
+func Decode(data []byte) (image.Image, string, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupported
+	}
+	if format != FormatJPEG && format != FormatPNG && format != FormatWebP {
+		return nil, "", ErrUnsupported
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, "", ErrUnsupported
+	}
+	if config.Width*config.Height > MaxPixels {
+		return nil, "", ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupported
+	}
+	if format == FormatJPEG {
+		img = orient(img, jpegOrientation(data))
+	}
+	return img, format, nil
+}
+
+// Fit scales img down to fit within maxWidth x maxHeight, keeping its
+// aspect ratio. Images that already fit are returned unchanged.
+func Fit(img image.Image, maxWidth, maxHeight int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxWidth && height <= maxHeight {
+		return img
+	}
+
+	scale := min(float64(maxWidth)/float64(width), float64(maxHeight)/float64(height))
+	dst := image.NewRGBA(image.Rect(0, 0, max(1, int(float64(width)*scale+0.5)), max(1, int(float64(height)*scale+0.5))))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// Encode writes img as a PNG when it has transparency and as a JPEG
+// otherwise, and returns the content type and file extension used. The
+// output carries no metadata.
+func Encode(w io.Writer, img image.Image) (string, string, error) {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && !opaque.Opaque() {
+		return "image/png", ".png", png.Encode(w, img)
+	}
+	return "image/jpeg", ".jpg", jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+}
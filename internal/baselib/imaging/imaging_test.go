@@ -0,0 +1,257 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// pattern is a width x height image whose pixels are all distinct
+func pattern(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 40), G: uint8(y * 40), B: 0, A: 255})
+		}
+	}
+	return img
+}
+
+// exif builds an APP1 segment carrying the orientation in the byte order
+func exif(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 26)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)       // first IFD
+	order.PutUint16(tiff[8:], 1)       // one entry
+	order.PutUint16(tiff[10:], 0x0112) // orientation
+	order.PutUint16(tiff[12:], 3)      // SHORT
+	order.PutUint32(tiff[14:], 1)      // count
+	order.PutUint16(tiff[18:], orientation)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	header := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(header[2:], uint16(len(segment)+2))
+	return append(header, segment...)
+}
+
+func encodeJPEG(t *testing.T, img image.Image, app1 []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+	data := buf.Bytes()
+	return append(append(append([]byte(nil), data[:2]...), app1...), data[2:]...)
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	// A PNG claiming dimensions above MaxPixels, with a valid header checksum
+	huge := encodePNG(t, pattern(1, 1))
+	binary.BigEndian.PutUint32(huge[16:], 10_000)
+	binary.BigEndian.PutUint32(huge[20:], 10_000)
+	binary.BigEndian.PutUint32(huge[29:], crc32.ChecksumIEEE(huge[12:29]))
+
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, pattern(2, 2), nil); err != nil {
+		t.Fatalf("gif.Encode() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		wantFormat string
+		wantSize   image.Point
+		wantErr    error
+	}{
+		{name: "png", data: encodePNG(t, pattern(4, 2)), wantFormat: FormatPNG, wantSize: image.Pt(4, 2)},
+		{name: "jpeg", data: encodeJPEG(t, pattern(4, 2), nil), wantFormat: FormatJPEG, wantSize: image.Pt(4, 2)},
+		{name: "rotated jpeg", data: encodeJPEG(t, pattern(4, 2), exif(binary.BigEndian, 6)), wantFormat: FormatJPEG, wantSize: image.Pt(2, 4)},
+		{name: "gif", data: gifData.Bytes(), wantErr: ErrUnsupported},
+		{name: "text", data: []byte("<svg></svg>"), wantErr: ErrUnsupported},
+		{name: "truncated png", data: encodePNG(t, pattern(4, 2))[:40], wantErr: ErrUnsupported},
+		{name: "too large", data: huge, wantErr: ErrTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, format, err := Decode(tt.data)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Decode() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if format != tt.wantFormat || img.Bounds().Size() != tt.wantSize {
+				t.Errorf("Decode() = %v %s, want %v %s", img.Bounds().Size(), format, tt.wantSize, tt.wantFormat)
+			}
+		})
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	img := pattern(2, 2)
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{name: "no exif", data: encodeJPEG(t, img, nil), want: 1},
+		{name: "big endian", data: encodeJPEG(t, img, exif(binary.BigEndian, 8)), want: 8},
+		{name: "little endian", data: encodeJPEG(t, img, exif(binary.LittleEndian, 3)), want: 3},
+		{name: "out of range", data: encodeJPEG(t, img, exif(binary.LittleEndian, 9)), want: 1},
+		{name: "not a jpeg", data: []byte("GIF89a"), want: 1},
+		{name: "truncated segment", data: []byte{0xFF, 0xD8, 0xFF, 0xE1, 0x10, 0x00}, want: 1},
+	}
+
+	for _, tt := range tests {
+		if got := jpegOrientation(tt.data); got != tt.want {
+			t.Errorf("%s: jpegOrientation() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// Sources in other color models and away from the origin are read the
+	// same as a plain RGBA image
+	gray := image.NewGray(image.Rect(5, 5, 8, 7))
+	for i := range gray.Pix {
+		gray.Pix[i] = uint8(i * 40)
+	}
+	sources := []struct {
+		name string
+		img  image.Image
+	}{
+		{name: "rgba", img: pattern(3, 2)},
+		{name: "sub-image", img: pattern(5, 4).SubImage(image.Rect(1, 1, 4, 3))},
+		{name: "gray", img: gray},
+	}
+
+	// Where the 3x2 source's top-left pixel lands, the size it ends up
+	// with, and the orientation that undoes it
+	tests := []struct {
+		orientation int
+		wantSize    image.Point
+		wantAt      image.Point
+		inverse     int
+	}{
+		{orientation: 1, wantSize: image.Pt(3, 2), wantAt: image.Pt(0, 0), inverse: 1},
+		{orientation: 2, wantSize: image.Pt(3, 2), wantAt: image.Pt(2, 0), inverse: 2},
+		{orientation: 3, wantSize: image.Pt(3, 2), wantAt: image.Pt(2, 1), inverse: 3},
+		{orientation: 4, wantSize: image.Pt(3, 2), wantAt: image.Pt(0, 1), inverse: 4},
+		{orientation: 5, wantSize: image.Pt(2, 3), wantAt: image.Pt(0, 0), inverse: 5},
+		{orientation: 6, wantSize: image.Pt(2, 3), wantAt: image.Pt(1, 0), inverse: 8},
+		{orientation: 7, wantSize: image.Pt(2, 3), wantAt: image.Pt(1, 2), inverse: 7},
+		{orientation: 8, wantSize: image.Pt(2, 3), wantAt: image.Pt(0, 2), inverse: 6},
+		{orientation: 9, wantSize: image.Pt(3, 2), wantAt: image.Pt(0, 0), inverse: 1},
+	}
+
+	for _, source := range sources {
+		src := source.img
+		bounds := src.Bounds()
+		pixel := func(img image.Image, x, y int) color.Color {
+			return color.RGBAModel.Convert(img.At(x, y))
+		}
+
+		for _, tt := range tests {
+			got := orient(src, tt.orientation)
+			origin := got.Bounds().Min
+			if got.Bounds().Size() != tt.wantSize {
+				t.Errorf("%s: orient(%d) size = %v, want %v", source.name, tt.orientation, got.Bounds().Size(), tt.wantSize)
+				continue
+			}
+			if pixel(got, origin.X+tt.wantAt.X, origin.Y+tt.wantAt.Y) != pixel(src, bounds.Min.X, bounds.Min.Y) {
+				t.Errorf("%s: orient(%d) did not move the top-left pixel to %v", source.name, tt.orientation, tt.wantAt)
+			}
+
+			back := orient(got, tt.inverse)
+			backMin := back.Bounds().Min
+			for y := 0; y < bounds.Dy(); y++ {
+				for x := 0; x < bounds.Dx(); x++ {
+					if pixel(back, backMin.X+x, backMin.Y+y) != pixel(src, bounds.Min.X+x, bounds.Min.Y+y) {
+						t.Errorf("%s: orient(%d) then orient(%d) moved pixel (%d, %d)", source.name, tt.orientation, tt.inverse, x, y)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name                string
+		width, height       int
+		maxWidth, maxHeight int
+		want                image.Point
+	}{
+		{name: "fits", width: 100, height: 50, maxWidth: 100, maxHeight: 100, want: image.Pt(100, 50)},
+		{name: "too wide", width: 400, height: 100, maxWidth: 200, maxHeight: 200, want: image.Pt(200, 50)},
+		{name: "too tall", width: 300, height: 600, maxWidth: 200, maxHeight: 200, want: image.Pt(100, 200)},
+		{name: "rounded", width: 1000, height: 333, maxWidth: 100, maxHeight: 100, want: image.Pt(100, 33)},
+		{name: "at least a pixel", width: 1000, height: 1, maxWidth: 10, maxHeight: 10, want: image.Pt(10, 1)},
+	}
+
+	for _, tt := range tests {
+		img := image.NewRGBA(image.Rect(0, 0, tt.width, tt.height))
+		got := Fit(img, tt.maxWidth, tt.maxHeight)
+		if got.Bounds().Size() != tt.want {
+			t.Errorf("%s: Fit() = %v, want %v", tt.name, got.Bounds().Size(), tt.want)
+		}
+		if tt.want == image.Pt(tt.width, tt.height) && got != image.Image(img) {
+			t.Errorf("%s: Fit() copied an image that fits", tt.name)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	transparent := pattern(2, 2)
+	transparent.Set(0, 0, color.Transparent)
+
+	tests := []struct {
+		name            string
+		img             image.Image
+		wantContentType string
+		wantExt         string
+	}{
+		{name: "opaque", img: pattern(2, 2), wantContentType: "image/jpeg", wantExt: ".jpg"},
+		{name: "transparent", img: transparent, wantContentType: "image/png", wantExt: ".png"},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		contentType, ext, err := Encode(&buf, tt.img)
+		if err != nil {
+			t.Fatalf("%s: Encode() error = %v", tt.name, err)
+		}
+		if contentType != tt.wantContentType || ext != tt.wantExt {
+			t.Errorf("%s: Encode() = %s %s, want %s %s", tt.name, contentType, ext, tt.wantContentType, tt.wantExt)
+		}
+		if _, _, err := Decode(buf.Bytes()); err != nil {
+			t.Errorf("%s: Decode() of the encoded image error = %v", tt.name, err)
+		}
+	}
+}
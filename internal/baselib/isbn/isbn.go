@@ -0,0 +1,80 @@
+// Package isbn validates International Standard Book Numbers and converts
+// between their 10 and 13 digit forms.
+package isbn
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalid is returned for values that are not a valid ISBN-10 or ISBN-13
+var ErrInvalid = errors.New("invalid ISBN")
+
+// Clean strips the hyphens and spaces ISBNs are usually printed with
+func Clean(value string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(value)))
+}
+
+// Normalize returns the canonical ISBN-13 of an ISBN-10 or ISBN-13 in any
+// hyphenation. The check digit must be correct.
+func Normalize(value string) (string, error) {
+	digits := Clean(value)
+	switch len(digits) {
+	case 13:
+		if !isDigits(digits) || checkDigit13(digits[:12]) != digits[12] {
+			return "", ErrInvalid
+		}
+		return digits, nil
+	case 10:
+		if !isDigits(digits[:9]) || checkDigit10(digits[:9]) != digits[9] {
+			return "", ErrInvalid
+		}
+		isbn13 := "978" + digits[:9]
+		return isbn13 + string(checkDigit13(isbn13)), nil
+	}
+	return "", ErrInvalid
+}
+
+// To10 returns the ISBN-10 form of a canonical ISBN-13, or "" when it has
+// none because it is outside the 978 prefix
+func To10(isbn13 string) string {
+	if len(isbn13) != 13 || !strings.HasPrefix(isbn13, "978") {
+		return ""
+	}
+	return isbn13[3:12] + string(checkDigit10(isbn13[3:12]))
+}
+
+// checkDigit13 computes the check digit for the first 12 digits of an ISBN-13
+func checkDigit13(digits string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(digits[i]-'0') * weight
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// checkDigit10 computes the check digit for the first 9 digits of an ISBN-10
+func checkDigit10(digits string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(digits[i]-'0') * (10 - i)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
+
+func isDigits(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
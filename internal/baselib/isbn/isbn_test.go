@@ -0,0 +1,72 @@
+package isbn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "9780306406157", want: "9780306406157"},
+		{value: " 978-0-306-40615-7 ", want: "9780306406157"},
+		{value: "979 10 90636 07 1", want: "9791090636071"},
+		{value: "0-306-40615-2", want: "9780306406157"},
+		{value: "080442957X", want: "9780804429573"},
+		{value: "080442957x", want: "9780804429573"},
+		{value: "9780306406158", wantErr: true},
+		{value: "0306406153", wantErr: true},
+		{value: "X306406152", wantErr: true},
+		{value: "97803064061X7", wantErr: true},
+		{value: "978030640615", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Normalize(tt.value)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalid) {
+				t.Errorf("Normalize(%q) = %q, %v, want ErrInvalid", tt.value, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Normalize(%q) = %q, %v, want %q", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestTo10(t *testing.T) {
+	tests := []struct {
+		isbn13 string
+		want   string
+	}{
+		{isbn13: "9780306406157", want: "0306406152"},
+		{isbn13: "9780804429573", want: "080442957X"},
+		{isbn13: "9791090636071", want: ""},
+		{isbn13: "978030640615", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := To10(tt.isbn13); got != tt.want {
+			t.Errorf("To10(%q) = %q, want %q", tt.isbn13, got, tt.want)
+		}
+	}
+}
+
+func TestCheckDigits(t *testing.T) {
+	// Every ISBN-10 converts to an ISBN-13 and back
+	for _, isbn10 := range []string{"0306406152", "080442957X", "0000000000", "1000000001", "9999999999"} {
+		isbn13, err := Normalize(isbn10)
+		if err != nil {
+			t.Errorf("Normalize(%q) error = %v", isbn10, err)
+			continue
+		}
+		if got := To10(isbn13); got != isbn10 {
+			t.Errorf("To10(Normalize(%q)) = %q", isbn10, got)
+		}
+	}
+}
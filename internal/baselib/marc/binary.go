@@ -0,0 +1,148 @@
+package marc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ISO 2709 delimiters
+const (
+	subfieldDelimiter = 0x1F
+	fieldTerminator   = 0x1E
+	recordTerminator  = 0x1D
+)
+
+const leaderLength = 24
+
+// ErrMARC8 is returned for records in the MARC-8 character set, which is not supported
+var ErrMARC8 = errors.New("marc: MARC-8 encoded records are not supported, convert them to UTF-8")
+
+// RecordError is a record that was read whole but could not be parsed.
+// Records carry their own length, so the Reader has already moved past it
+// and the next record can still be read.
+type RecordError struct {
+	Err error
+}
+
+func (e *RecordError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads ISO 2709 records one at a time
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader creates a Reader for the records on r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next record. io.EOF ends the records, and a
+// *RecordError only the one record; other errors leave the Reader lost.
+func (r *Reader) Next() (*Record, error) {
+	// Skip line breaks some tools put between records
+	for {
+		b, err := r.r.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if b[0] != '\n' && b[0] != '\r' {
+			break
+		}
+		_, _ = r.r.ReadByte()
+	}
+
+	prefix := make([]byte, 5)
+	if _, err := io.ReadFull(r.r, prefix); err != nil {
+		return nil, fmt.Errorf("marc: truncated record: %v", err)
+	}
+	length, err := strconv.Atoi(string(prefix))
+	if err != nil || length < leaderLength+1 {
+		return nil, fmt.Errorf("marc: invalid record length %q", prefix)
+	}
+
+	data := make([]byte, length)
+	copy(data, prefix)
+	if _, err := io.ReadFull(r.r, data[5:]); err != nil {
+		return nil, fmt.Errorf("marc: truncated record: %v", err)
+	}
+	record, err := parseRecord(data)
+	if err != nil {
+		return nil, &RecordError{Err: err}
+	}
+	return record, nil
+}
+
+func parseRecord(data []byte) (*Record, error) {
+	leader := string(data[:leaderLength])
+	if leader[9] != 'a' && !utf8.Valid(data) {
+		return nil, ErrMARC8
+	}
+
+	base, err := strconv.Atoi(leader[12:17])
+	if err != nil || base <= leaderLength || base > len(data) {
+		return nil, fmt.Errorf("marc: invalid base address %q", leader[12:17])
+	}
+
+	record := &Record{Leader: leader}
+	directory := data[leaderLength : base-1]
+	if len(directory)%12 != 0 {
+		return nil, errors.New("marc: invalid directory")
+	}
+
+	for i := 0; i < len(directory); i += 12 {
+		entry := directory[i : i+12]
+		tag := string(entry[:3])
+		length, err1 := strconv.Atoi(string(entry[3:7]))
+		start, err2 := strconv.Atoi(string(entry[7:12]))
+		if err1 != nil || err2 != nil || start < 0 || length < 1 || base+start+length > len(data) {
+			return nil, fmt.Errorf("marc: invalid directory entry for field %s", tag)
+		}
+
+		// Drop the field terminator
+		content := data[base+start : base+start+length-1]
+		field := Field{Tag: tag}
+		if field.IsControl() {
+			field.Value = string(content)
+		} else {
+			if len(content) < 2 {
+				return nil, fmt.Errorf("marc: field %s has no indicators", tag)
+			}
+			field.Ind1, field.Ind2 = string(content[0]), string(content[1])
+			for _, part := range splitSubfields(content[2:]) {
+				field.Subfields = append(field.Subfields, Subfield{Code: string(part[0]), Value: string(part[1:])})
+			}
+		}
+		record.Fields = append(record.Fields, field)
+	}
+
+	return record, nil
+}
+
+// splitSubfields splits data field content on subfield delimiters, dropping empty parts
+func splitSubfields(content []byte) [][]byte {
+	var parts [][]byte
+	start := -1
+	for i, b := range content {
+		if b != subfieldDelimiter {
+			continue
+		}
+		if start >= 0 && i > start {
+			parts = append(parts, content[start:i])
+		}
+		start = i + 1
+	}
+	if start >= 0 && start < len(content) {
+		parts = append(parts, content[start:])
+	}
+	return parts
+}
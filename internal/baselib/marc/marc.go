@@ -0,0 +1,52 @@
+// Package marc reads MARC 21 records in ISO 2709 (binary) and MARCXML
+// form and writes them as MARCXML.
+package marc
+
+import "strings"
+
+// Record is a MARC record: a leader followed by control and data fields
+type Record struct {
+	Leader string  `json:"leader"`
+	Fields []Field `json:"fields"`
+}
+
+// Field is a control field (tags 001-009), which only has a Value, or a
+// data field with indicators and subfields
+type Field struct {
+	Tag       string     `json:"tag"`
+	Value     string     `json:"value,omitempty"`
+	Ind1      string     `json:"ind1,omitempty"`
+	Ind2      string     `json:"ind2,omitempty"`
+	Subfields []Subfield `json:"subfields,omitempty"`
+}
+
+// Subfield is a coded element of a data field
+type Subfield struct {
+	Code  string `json:"code"`
+	Value string `json:"value"`
+}
+
+// IsControl reports whether the field is a control field
+func (f *Field) IsControl() bool {
+	return strings.HasPrefix(f.Tag, "00")
+}
+
+// Subfield returns the value of the first subfield with the code, or ""
+func (f *Field) Subfield(code string) string {
+	for _, sf := range f.Subfields {
+		if sf.Code == code {
+			return sf.Value
+		}
+	}
+	return ""
+}
+
+// ControlField returns the value of the first control field with the tag, or ""
+func (r *Record) ControlField(tag string) string {
+	for i := range r.Fields {
+		if r.Fields[i].Tag == tag {
+			return r.Fields[i].Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,247 @@
+package marc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// encode builds an ISO 2709 record of the fields, with the character
+// coding scheme of leader position 9
+func encode(coding byte, fields ...Field) []byte {
+	var directory, data bytes.Buffer
+	for _, field := range fields {
+		start := data.Len()
+		if field.IsControl() {
+			data.WriteString(field.Value)
+		} else {
+			data.WriteString(field.Ind1 + field.Ind2)
+			for _, sf := range field.Subfields {
+				data.WriteByte(subfieldDelimiter)
+				data.WriteString(sf.Code + sf.Value)
+			}
+		}
+		data.WriteByte(fieldTerminator)
+		fmt.Fprintf(&directory, "%s%04d%05d", field.Tag, data.Len()-start, start)
+	}
+	directory.WriteByte(fieldTerminator)
+	data.WriteByte(recordTerminator)
+
+	base := leaderLength + directory.Len()
+	leader := fmt.Sprintf("%05dnam %c22%05d   4500", base+data.Len(), coding, base)
+	return append(append([]byte(leader), directory.Bytes()...), data.Bytes()...)
+}
+
+var dune = []Field{
+	{Tag: "001", Value: "ocm123"},
+	{Tag: "020", Ind1: " ", Ind2: " ", Subfields: []Subfield{{Code: "a", Value: "9780306406157"}, {Code: "c", Value: "£7.99"}}},
+	{Tag: "245", Ind1: "1", Ind2: "0", Subfields: []Subfield{{Code: "a", Value: "Dune :"}, {Code: "b", Value: "a novel"}}},
+}
+
+func TestReader(t *testing.T) {
+	valid := encode('a', dune...)
+
+	// The first entry of the directory starts right after the leader
+	negativeStart := encode('a', dune...)
+	copy(negativeStart[leaderLength+7:], "-0001")
+
+	tests := []struct {
+		name  string
+		input []byte
+		// want holds the records expected in turn, with nil for one that fails
+		want    [][]Field
+		wantErr error
+	}{
+		{name: "one record", input: valid, want: [][]Field{dune}},
+		{name: "records separated by line breaks", input: bytes.Join([][]byte{valid, valid}, []byte("\r\n")), want: [][]Field{dune, dune}},
+		{name: "empty subfields are dropped", input: encode('a', Field{Tag: "500", Ind1: " ", Ind2: " ", Subfields: []Subfield{{Code: "", Value: ""}, {Code: "a", Value: "Note"}}}),
+			want: [][]Field{{{Tag: "500", Ind1: " ", Ind2: " ", Subfields: []Subfield{{Code: "a", Value: "Note"}}}}}},
+		{name: "negative field offset", input: append(negativeStart, valid...), want: [][]Field{nil, dune}},
+		{name: "MARC-8", input: encode(' ', Field{Tag: "245", Ind1: "0", Ind2: "0", Subfields: []Subfield{{Code: "a", Value: "Caf\xe9"}}}), want: [][]Field{nil}, wantErr: ErrMARC8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReader(bytes.NewReader(tt.input))
+			for i, want := range tt.want {
+				record, err := r.Next()
+				if want == nil {
+					var recordErr *RecordError
+					if !errors.As(err, &recordErr) {
+						t.Fatalf("record %d: Next() error = %v, want a RecordError", i, err)
+					}
+					if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+						t.Errorf("record %d: Next() error = %v, want %v", i, err, tt.wantErr)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("record %d: Next() error = %v", i, err)
+				}
+				if !reflect.DeepEqual(record.Fields, want) {
+					t.Errorf("record %d: Fields = %+v, want %+v", i, record.Fields, want)
+				}
+			}
+			if _, err := r.Next(); !errors.Is(err, io.EOF) {
+				t.Errorf("last Next() error = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestReaderInvalid(t *testing.T) {
+	valid := encode('a', dune...)
+
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{name: "invalid length", input: []byte("abcde")},
+		{name: "length shorter than a leader", input: []byte("00010nam a2200000   4500")},
+		{name: "truncated record", input: valid[:len(valid)-10]},
+		{name: "truncated length", input: []byte("001")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewReader(bytes.NewReader(tt.input)).Next()
+			var recordErr *RecordError
+			if err == nil || errors.Is(err, io.EOF) || errors.As(err, &recordErr) {
+				t.Errorf("Next() error = %v, want a stream error", err)
+			}
+		})
+	}
+}
+
+func TestParseRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		record func() []byte
+	}{
+		{name: "base address inside the leader", record: func() []byte {
+			data := encode('a', dune...)
+			copy(data[12:17], "00010")
+			return data
+		}},
+		{name: "base address past the record", record: func() []byte {
+			data := encode('a', dune...)
+			copy(data[12:17], "99999")
+			return data
+		}},
+		{name: "partial directory entry", record: func() []byte {
+			data := encode('a', dune...)
+			copy(data[12:17], fmt.Sprintf("%05d", leaderLength+14))
+			return data
+		}},
+		{name: "field past the record", record: func() []byte {
+			data := encode('a', dune...)
+			copy(data[leaderLength+3:], "9999")
+			return data
+		}},
+		{name: "empty field", record: func() []byte {
+			data := encode('a', dune...)
+			copy(data[leaderLength+3:], "0000")
+			return data
+		}},
+		{name: "data field without indicators", record: func() []byte {
+			return encode('a', Field{Tag: "245"})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if record, err := parseRecord(tt.record()); err == nil {
+				t.Errorf("parseRecord() = %+v, want an error", record)
+			}
+		})
+	}
+}
+
+func TestXMLReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Record
+		wantErr bool
+	}{
+		{
+			name: "collection keeps the field order",
+			input: `<?xml version="1.0"?><collection xmlns="http://www.loc.gov/MARC21/slim">
+				<record><leader>00000nam a2200000 i 4500</leader>
+					<datafield tag="020" ind1=" " ind2=" "><subfield code="a">9780306406157</subfield></datafield>
+					<controlfield tag="001">ocm123</controlfield>
+					<extra>ignored</extra>
+				</record>
+				<record><datafield tag="245" ind1="1" ind2="0"><subfield code="a">Emma</subfield><subfield code="c">Jane Austen</subfield></datafield></record>
+			</collection>`,
+			want: []Record{
+				{Leader: "00000nam a2200000 i 4500", Fields: []Field{
+					{Tag: "020", Ind1: " ", Ind2: " ", Subfields: []Subfield{{Code: "a", Value: "9780306406157"}}},
+					{Tag: "001", Value: "ocm123"},
+				}},
+				{Fields: []Field{{Tag: "245", Ind1: "1", Ind2: "0", Subfields: []Subfield{{Code: "a", Value: "Emma"}, {Code: "c", Value: "Jane Austen"}}}}},
+			},
+		},
+		{
+			name:  "single record document",
+			input: `<record xmlns="http://www.loc.gov/MARC21/slim"><controlfield tag="001">1</controlfield></record>`,
+			want:  []Record{{Fields: []Field{{Tag: "001", Value: "1"}}}},
+		},
+		{
+			name:    "truncated record",
+			input:   `<collection><record><controlfield tag="001">1</controlfield>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewXMLReader(strings.NewReader(tt.input))
+			for i, want := range tt.want {
+				record, err := r.Next()
+				if err != nil {
+					t.Fatalf("record %d: Next() error = %v", i, err)
+				}
+				if !reflect.DeepEqual(*record, want) {
+					t.Errorf("record %d: Next() = %+v, want %+v", i, *record, want)
+				}
+			}
+			_, err := r.Next()
+			if tt.wantErr {
+				if err == nil || errors.Is(err, io.EOF) {
+					t.Errorf("last Next() error = %v, want a syntax error", err)
+				}
+				return
+			}
+			if !errors.Is(err, io.EOF) {
+				t.Errorf("last Next() error = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestWriteXML(t *testing.T) {
+	record := &Record{Leader: "00000nam a2200000 i 4500", Fields: append(dune[:len(dune):len(dune)],
+		Field{Tag: "500", Subfields: []Subfield{{Code: "a", Value: "Tom & <Jerry>"}}})}
+
+	var buf bytes.Buffer
+	if err := WriteXML(&buf, record); err != nil {
+		t.Fatalf("WriteXML() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `<datafield tag="500" ind1=" " ind2=" ">`) {
+		t.Errorf("WriteXML() = %s, want blank indicators", buf.String())
+	}
+
+	got, err := NewXMLReader(&buf).Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	record.Fields[len(record.Fields)-1].Ind1, record.Fields[len(record.Fields)-1].Ind2 = " ", " "
+	if !reflect.DeepEqual(got, record) {
+		t.Errorf("Next() = %+v, want %+v", got, record)
+	}
+}
@@ -0,0 +1,154 @@
+package marc
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+// Namespace is the MARCXML (MARC 21 slim) namespace
+const Namespace = "http://www.loc.gov/MARC21/slim"
+
+// MediaType is the media type of MARCXML documents
+const MediaType = "application/marcxml+xml"
+
+type recordXML struct {
+	XMLName       xml.Name          `xml:"record"`
+	Namespace     string            `xml:"xmlns,attr,omitempty"`
+	Leader        string            `xml:"leader"`
+	ControlFields []controlFieldXML `xml:"controlfield"`
+	DataFields    []dataFieldXML    `xml:"datafield"`
+}
+
+type controlFieldXML struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type dataFieldXML struct {
+	Tag       string        `xml:"tag,attr"`
+	Ind1      string        `xml:"ind1,attr"`
+	Ind2      string        `xml:"ind2,attr"`
+	Subfields []subfieldXML `xml:"subfield"`
+}
+
+type subfieldXML struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// XMLReader reads the records of a MARCXML collection, or a single record
+// document, one at a time
+type XMLReader struct {
+	decoder *xml.Decoder
+}
+
+// NewXMLReader creates an XMLReader for the document on r
+func NewXMLReader(r io.Reader) *XMLReader {
+	return &XMLReader{decoder: xml.NewDecoder(r)}
+}
+
+// Next returns the next record. io.EOF ends the records.
+func (r *XMLReader) Next() (*Record, error) {
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+
+		// MARCXML keeps control and data fields in separate lists, so their
+		// relative order is read from the tokens rather than by unmarshalling
+		record := &Record{}
+		if err := r.decodeRecord(record); err != nil {
+			return nil, err
+		}
+		return record, nil
+	}
+}
+
+func (r *XMLReader) decodeRecord(record *Record) error {
+	for {
+		token, err := r.decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.EndElement:
+			if t.Name.Local == "record" {
+				return nil
+			}
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "leader":
+				var leader string
+				if err := r.decoder.DecodeElement(&leader, &t); err != nil {
+					return err
+				}
+				record.Leader = leader
+			case "controlfield":
+				var cf controlFieldXML
+				if err := r.decoder.DecodeElement(&cf, &t); err != nil {
+					return err
+				}
+				record.Fields = append(record.Fields, Field{Tag: cf.Tag, Value: cf.Value})
+			case "datafield":
+				var df dataFieldXML
+				if err := r.decoder.DecodeElement(&df, &t); err != nil {
+					return err
+				}
+				field := Field{Tag: df.Tag, Ind1: df.Ind1, Ind2: df.Ind2}
+				for _, sf := range df.Subfields {
+					field.Subfields = append(field.Subfields, Subfield{Code: sf.Code, Value: sf.Value})
+				}
+				record.Fields = append(record.Fields, field)
+			default:
+				if err := r.decoder.Skip(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// WriteXML writes the record as a standalone MARCXML document
+func WriteXML(w io.Writer, record *Record) error {
+	doc := recordXML{Namespace: Namespace, Leader: record.Leader}
+	for _, field := range record.Fields {
+		if field.IsControl() {
+			doc.ControlFields = append(doc.ControlFields, controlFieldXML{Tag: field.Tag, Value: field.Value})
+			continue
+		}
+		df := dataFieldXML{Tag: field.Tag, Ind1: indicator(field.Ind1), Ind2: indicator(field.Ind2)}
+		for _, sf := range field.Subfields {
+			df.Subfields = append(df.Subfields, subfieldXML{Code: sf.Code, Value: sf.Value})
+		}
+		doc.DataFields = append(doc.DataFields, df)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// indicator returns a blank for an unset indicator, as the schema requires one character
+func indicator(value string) string {
+	if value == "" {
+		return " "
+	}
+	return value
+}
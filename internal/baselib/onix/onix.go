@@ -0,0 +1,95 @@
+// Package onix reads and writes ONIX for Books 3.0 messages in the
+// reference-tag form. Only the product data a catalog needs is modelled;
+// Reader reports the paths of everything else it skipped.
+package onix
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Namespace is the ONIX 3.0 reference-tag namespace
+const Namespace = "http://ns.editeur.org/onix/3.0/reference"
+
+// Release is the release written to exported messages
+const Release = "3.0"
+
+// Code list values used when reading and writing products
+const (
+	NotificationConfirmed = "03" // List 1: notification confirmed on publication
+	NotificationDelete    = "05" // List 1: delete
+
+	ProductIDProprietary = "01" // List 5
+	ProductIDISBN10      = "02"
+	ProductIDGTIN13      = "03"
+	ProductIDISBN13      = "15"
+
+	TitleTypeDistinctive = "01" // List 15
+	TitleLevelProduct    = "01" // List 149
+
+	ContributorByAuthor = "A01" // List 17
+
+	TextTypeShortDescription = "02" // List 153
+	TextTypeDescription      = "03"
+	TextFormatDefault        = "06" // List 34
+
+	ResourceFrontCover = "01" // List 158
+	ResourceModeImage  = "03" // List 159
+	ResourceFormFile   = "02" // List 161: downloadable file
+
+	PublishingStatusActive = "04" // List 64
+	PublicationDate        = "01" // List 163
+
+	AvailabilityInStock    = "21" // List 65
+	AvailabilityOutOfStock = "31"
+
+	PriceRRPIncludingTax = "02" // List 58
+)
+
+// Product is the catalog data carried by an ONIX product record
+type Product struct {
+	RecordReference  string
+	NotificationType string
+	// ISBN is the ISBN-13 when the record has one, otherwise an ISBN-10
+	ISBN            string
+	Title           string
+	Contributors    []Contributor
+	Description     string
+	CoverURL        string
+	PublicationDate time.Time
+	// Price is the first price of the first supply detail, if any
+	Price *Price
+	// OnHand is the stock quantity of the first supply detail, if given
+	OnHand *int
+}
+
+// Contributor is a person or corporate body credited on a product
+type Contributor struct {
+	Role string
+	Name string
+}
+
+// Price is a product price in a currency. Amount is written with the
+// decimal places it carries, so round it to the currency first.
+type Price struct {
+	Type     string
+	Amount   decimal.Decimal
+	Currency string
+}
+
+// Authors returns the names of the "By (author)" contributors, or of all
+// contributors when none has that role
+func (p *Product) Authors() []string {
+	var authors, all []string
+	for _, c := range p.Contributors {
+		all = append(all, c.Name)
+		if c.Role == ContributorByAuthor {
+			authors = append(authors, c.Name)
+		}
+	}
+	if len(authors) > 0 {
+		return authors
+	}
+	return all
+}
@@ -0,0 +1,297 @@
+package onix
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// message wraps product records in an ONIX 3.0 message
+func message(products ...string) string {
+	return `<?xml version="1.0"?><ONIXMessage release="3.0" xmlns="http://ns.editeur.org/onix/3.0/reference">` +
+		`<Header><Sender><SenderName>Test</SenderName></Sender></Header>` +
+		strings.Join(products, "") + `</ONIXMessage>`
+}
+
+func TestReaderRoot(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		{name: "empty message", input: message(), wantErr: io.EOF},
+		{name: "empty input", input: ``, wantErr: ErrNotONIX},
+		{name: "other document", input: `<rss version="2.0"></rss>`, wantErr: ErrNotONIX},
+		{name: "short tags", input: `<ONIXmessage release="3.0"></ONIXmessage>`, wantErr: ErrNotONIX},
+		{name: "release 2.1", input: `<ONIXMessage release="2.1"></ONIXMessage>`, wantErr: ErrNotONIX},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			position, product, _, err := NewReader(strings.NewReader(tt.input)).Next()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Next() error = %v, want %v", err, tt.wantErr)
+			}
+			if position != 0 || product != nil {
+				t.Errorf("Next() = %d, %+v, want no product", position, product)
+			}
+		})
+	}
+}
+
+func TestReaderProduct(t *testing.T) {
+	onHand := 4
+
+	tests := []struct {
+		name        string
+		product     string
+		want        *Product
+		wantSkipped []string
+		wantErr     bool
+	}{
+		{
+			name: "full record",
+			product: `<Product><RecordReference>ref-1</RecordReference><NotificationType>03</NotificationType>
+				<ProductIdentifier><ProductIDType>02</ProductIDType><IDValue>0306406152</IDValue></ProductIdentifier>
+				<ProductIdentifier><ProductIDType>15</ProductIDType><IDValue>9780306406157</IDValue></ProductIdentifier>
+				<DescriptiveDetail>
+					<TitleDetail><TitleType>01</TitleType><TitleElement><TitleElementLevel>01</TitleElementLevel>
+						<TitlePrefix>The</TitlePrefix><TitleWithoutPrefix>Dune Saga</TitleWithoutPrefix><Subtitle>Book One</Subtitle>
+					</TitleElement></TitleDetail>
+					<Contributor><SequenceNumber>2</SequenceNumber><ContributorRole>B01</ContributorRole><NamesBeforeKey>Jane</NamesBeforeKey><KeyNames>Doe</KeyNames></Contributor>
+					<Contributor><SequenceNumber>1</SequenceNumber><ContributorRole>A01</ContributorRole><PersonName>Frank Herbert</PersonName></Contributor>
+				</DescriptiveDetail>
+				<CollateralDetail>
+					<TextContent><TextType>02</TextType><ContentAudience>00</ContentAudience><Text>Short</Text></TextContent>
+					<TextContent><TextType>03</TextType><ContentAudience>00</ContentAudience><Text textformat="05"><p>Long</p></Text></TextContent>
+					<SupportingResource><ResourceContentType>01</ResourceContentType><ContentAudience>00</ContentAudience><ResourceMode>03</ResourceMode>
+						<ResourceVersion><ResourceForm>02</ResourceForm><ResourceLink>https://covers.example/1.jpg</ResourceLink></ResourceVersion>
+					</SupportingResource>
+				</CollateralDetail>
+				<PublishingDetail><PublishingDate><PublishingDateRole>01</PublishingDateRole><Date dateformat="00">19650801</Date></PublishingDate></PublishingDetail>
+				<ProductSupply><SupplyDetail><Supplier><SupplierRole>00</SupplierRole></Supplier><ProductAvailability>21</ProductAvailability>
+					<Stock><OnHand>4</OnHand></Stock>
+					<Price><PriceType>02</PriceType><PriceAmount>19.990</PriceAmount><CurrencyCode>EUR</CurrencyCode></Price>
+				</SupplyDetail></ProductSupply>
+			</Product>`,
+			want: &Product{
+				RecordReference:  "ref-1",
+				NotificationType: "03",
+				ISBN:             "9780306406157",
+				Title:            "The Dune Saga: Book One",
+				Contributors:     []Contributor{{Role: "A01", Name: "Frank Herbert"}, {Role: "B01", Name: "Jane Doe"}},
+				Description:      "<p>Long</p>",
+				CoverURL:         "https://covers.example/1.jpg",
+				PublicationDate:  time.Date(1965, time.August, 1, 0, 0, 0, 0, time.UTC),
+				Price:            &Price{Type: "02", Amount: decimal.RequireFromString("19.990"), Currency: "EUR"},
+				OnHand:           &onHand,
+			},
+			// Written by the writer, but not held by Product
+			wantSkipped: []string{"ProductSupply/SupplyDetail/Supplier", "ProductSupply/SupplyDetail/ProductAvailability"},
+		},
+		{
+			name: "bookland GTIN over ISBN-10",
+			product: `<Product><RecordReference>ref-2</RecordReference><NotificationType>03</NotificationType>
+				<ProductIdentifier><ProductIDType>02</ProductIDType><IDValue>0306406152</IDValue></ProductIdentifier>
+				<ProductIdentifier><ProductIDType>03</ProductIDType><IDValue>9780306406157</IDValue></ProductIdentifier>
+				<ProductIdentifier><ProductIDType>01</ProductIDType><IDValue>X-1</IDValue></ProductIdentifier>
+			</Product>`,
+			want:        &Product{RecordReference: "ref-2", NotificationType: "03", ISBN: "9780306406157"},
+			wantSkipped: []string{"ProductIdentifier[ProductIDType=01]"},
+		},
+		{
+			name: "other GTIN falls back to ISBN-10",
+			product: `<Product><RecordReference>ref-3</RecordReference>
+				<ProductIdentifier><ProductIDType>03</ProductIDType><IDValue>4006381333931</IDValue></ProductIdentifier>
+				<ProductIdentifier><ProductIDType>02</ProductIDType><IDValue>0306406152</IDValue></ProductIdentifier>
+			</Product>`,
+			want: &Product{RecordReference: "ref-3", ISBN: "0306406152"},
+		},
+		{
+			name: "unmapped and skipped data",
+			product: `<Product><RecordReference>ref-4</RecordReference><Barcode><BarcodeType>01</BarcodeType></Barcode>
+				<DescriptiveDetail>
+					<TitleDetail><TitleType>10</TitleType><TitleElement><TitleElementLevel>01</TitleElementLevel><TitleText>Dune (spine)</TitleText></TitleElement></TitleDetail>
+					<TitleDetail><TitleType>01</TitleType><TitleElement><TitleElementLevel>01</TitleElementLevel><TitleText>Dune</TitleText></TitleElement></TitleDetail>
+					<Language><LanguageRole>01</LanguageRole></Language>
+				</DescriptiveDetail>
+				<CollateralDetail><TextContent><TextType>04</TextType><Text>Contents</Text></TextContent></CollateralDetail>
+				<PublishingDetail>
+					<PublishingDate><PublishingDateRole>01</PublishingDateRole><Date>1965</Date></PublishingDate>
+					<PublishingDate><PublishingDateRole>09</PublishingDateRole><Date>2005</Date></PublishingDate>
+				</PublishingDetail>
+				<ProductSupply>
+					<SupplyDetail><Price><PriceType>02</PriceType><PriceAmount>20</PriceAmount><CurrencyCode>EUR</CurrencyCode></Price>
+						<Price><PriceType>01</PriceType><PriceAmount>17</PriceAmount><CurrencyCode>EUR</CurrencyCode></Price></SupplyDetail>
+					<SupplyDetail><Price><PriceAmount>21</PriceAmount></Price></SupplyDetail>
+				</ProductSupply>
+			</Product>`,
+			want: &Product{
+				RecordReference: "ref-4",
+				Title:           "Dune",
+				PublicationDate: time.Date(1965, time.January, 1, 0, 0, 0, 0, time.UTC),
+				Price:           &Price{Type: "02", Amount: decimal.RequireFromString("20"), Currency: "EUR"},
+			},
+			wantSkipped: []string{
+				"Barcode",
+				"DescriptiveDetail/Language",
+				"DescriptiveDetail/TitleDetail[TitleType=10]",
+				"CollateralDetail/TextContent[TextType=04]",
+				"PublishingDetail/PublishingDate[PublishingDateRole=09]",
+				"ProductSupply/SupplyDetail/Price[PriceType=01]",
+				"ProductSupply/SupplyDetail[additional]",
+			},
+		},
+		{
+			name: "invalid price",
+			product: `<Product><RecordReference>ref-5</RecordReference>
+				<ProductSupply><SupplyDetail><Price><PriceAmount>12,50</PriceAmount></Price></SupplyDetail></ProductSupply>
+			</Product>`,
+			wantErr: true,
+		},
+		{
+			name: "invalid date",
+			product: `<Product><RecordReference>ref-6</RecordReference>
+				<PublishingDetail><PublishingDate><PublishingDateRole>01</PublishingDateRole><Date dateformat="00">1965</Date></PublishingDate></PublishingDetail>
+			</Product>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReader(strings.NewReader(message(tt.product)))
+			position, product, skipped, err := r.Next()
+			if position != 1 {
+				t.Errorf("Next() position = %d, want 1", position)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Next() = %+v, want an error", product)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Next() error = %v", err)
+			}
+			if !reflect.DeepEqual(product, tt.want) {
+				t.Errorf("Next() = %+v, want %+v", product, tt.want)
+			}
+			if !reflect.DeepEqual(skipped, tt.wantSkipped) {
+				t.Errorf("Next() skipped = %q, want %q", skipped, tt.wantSkipped)
+			}
+			if _, _, _, err := r.Next(); !errors.Is(err, io.EOF) {
+				t.Errorf("second Next() error = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	onHand := 0
+	products := []*Product{
+		{
+			RecordReference: "book-1",
+			ISBN:            "978-0-306-40615-7",
+			Title:           "Dune & Sons",
+			Contributors:    []Contributor{{Name: "Frank Herbert"}, {Role: "B01", Name: "Jane Doe"}},
+			Description:     "A <desert> planet",
+			CoverURL:        "https://covers.example/1.jpg",
+			PublicationDate: time.Date(1965, time.August, 1, 0, 0, 0, 0, time.UTC),
+			Price:           &Price{Amount: decimal.RequireFromString("19.990"), Currency: "TND"},
+			OnHand:          &onHand,
+		},
+		{RecordReference: "book-2", ISBN: "0306406152", Title: "Emma"},
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "Books", time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	for _, p := range products {
+		if err := w.Write(p); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := w.Write(products[0]); err == nil {
+		t.Errorf("Write() after Close succeeded")
+	}
+
+	output := buf.String()
+	for _, want := range []string{`<PriceAmount>19.990</PriceAmount>`, `<ProductAvailability>` + AvailabilityOutOfStock + `<`, `<NoContributor></NoContributor>`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("message does not contain %s:\n%s", want, output)
+		}
+	}
+
+	want := []*Product{
+		{
+			RecordReference:  "book-1",
+			NotificationType: NotificationConfirmed,
+			ISBN:             "9780306406157",
+			Title:            "Dune & Sons",
+			Contributors:     []Contributor{{Role: ContributorByAuthor, Name: "Frank Herbert"}, {Role: "B01", Name: "Jane Doe"}},
+			Description:      "A <desert> planet",
+			CoverURL:         "https://covers.example/1.jpg",
+			PublicationDate:  time.Date(1965, time.August, 1, 0, 0, 0, 0, time.UTC),
+			Price:            &Price{Type: PriceRRPIncludingTax, Amount: decimal.RequireFromString("19.990"), Currency: "TND"},
+			OnHand:           &onHand,
+		},
+		{RecordReference: "book-2", NotificationType: NotificationConfirmed, ISBN: "0306406152", Title: "Emma"},
+	}
+
+	r := NewReader(&buf)
+	for i, wantProduct := range want {
+		position, product, _, err := r.Next()
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if position != i+1 {
+			t.Errorf("Next() position = %d, want %d", position, i+1)
+		}
+		if !reflect.DeepEqual(product, wantProduct) {
+			t.Errorf("Next() = %+v, want %+v", product, wantProduct)
+		}
+	}
+	if _, _, _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Next() error = %v, want io.EOF", err)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		format  string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{format: "00", value: "20240315", want: time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)},
+		{format: "01", value: "202403", want: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{format: "05", value: "2024", want: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{format: "", value: " 202403 ", want: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{format: "", value: "24", wantErr: true},
+		{format: "13", value: "20240315T1200", wantErr: true},
+		{format: "00", value: "20241315", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDate(dateXML{Format: tt.format, Value: tt.value})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDate(%q, %q) error = %v, wantErr %v", tt.format, tt.value, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseDate(%q, %q) = %v, want %v", tt.format, tt.value, got, tt.want)
+		}
+	}
+}
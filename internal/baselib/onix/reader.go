@@ -0,0 +1,345 @@
+package onix
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrNotONIX is returned when the input is not an ONIX 3.0 reference-tag message
+var ErrNotONIX = errors.New("onix: not an ONIX 3.0 reference-tag message")
+
+// Reader reads the products of an ONIX message one at a time, without
+// loading the whole message
+type Reader struct {
+	decoder *xml.Decoder
+	started bool
+	count   int
+}
+
+// node is a generic element tree used to find the elements a product
+// carries that are not mapped
+type node struct {
+	XMLName xml.Name
+	Inner   []byte `xml:",innerxml"`
+	Nodes   []node `xml:",any"`
+}
+
+// NewReader creates a Reader for the message on r
+func NewReader(r io.Reader) *Reader {
+	return &Reader{decoder: xml.NewDecoder(r)}
+}
+
+// Next returns the next product with its position in the message and the
+// paths of the data it carries that Product does not hold. A product with
+// an error is unusable; a zero position with an error means the message
+// itself cannot be read. io.EOF ends the products.
+func (r *Reader) Next() (int, *Product, []string, error) {
+	for {
+		token, err := r.decoder.Token()
+		if errors.Is(err, io.EOF) {
+			if !r.started {
+				return 0, nil, nil, ErrNotONIX
+			}
+			return 0, nil, nil, io.EOF
+		}
+		if err != nil {
+			return 0, nil, nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if !r.started {
+			if err := checkRoot(start); err != nil {
+				return 0, nil, nil, err
+			}
+			r.started = true
+			continue
+		}
+
+		if start.Name.Local != "Product" {
+			if err := r.decoder.Skip(); err != nil {
+				return 0, nil, nil, err
+			}
+			continue
+		}
+
+		var n node
+		if err := r.decoder.DecodeElement(&n, &start); err != nil {
+			return 0, nil, nil, err
+		}
+		r.count++
+
+		var px productXML
+		if err := xml.Unmarshal(append(append([]byte("<Product>"), n.Inner...), "</Product>"...), &px); err != nil {
+			return r.count, nil, nil, fmt.Errorf("invalid product: %v", err)
+		}
+
+		var unmapped []string
+		collectUnmapped(n.Nodes, "", &unmapped)
+		product, skipped, err := px.toProduct()
+		return r.count, product, append(unmapped, skipped...), err
+	}
+}
+
+func checkRoot(start xml.StartElement) error {
+	if start.Name.Local == "ONIXmessage" {
+		return fmt.Errorf("%w: short-tag messages are not supported", ErrNotONIX)
+	}
+	if start.Name.Local != "ONIXMessage" {
+		return ErrNotONIX
+	}
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "release" && !strings.HasPrefix(attr.Value, "3.") {
+			return fmt.Errorf("%w: release %s", ErrNotONIX, attr.Value)
+		}
+	}
+	return nil
+}
+
+// collectUnmapped appends the paths of the outermost elements not in knownPaths
+func collectUnmapped(nodes []node, prefix string, out *[]string) {
+	for _, n := range nodes {
+		path := n.XMLName.Local
+		if prefix != "" {
+			path = prefix + "/" + path
+		}
+		if !knownPaths[path] {
+			*out = append(*out, path)
+			continue
+		}
+		if !opaquePaths[path] {
+			collectUnmapped(n.Nodes, path, out)
+		}
+	}
+}
+
+// toProduct maps the record onto a Product. Repeated composites that hold
+// other kinds of data than the one used, such as a table of contents next
+// to the description, are returned as skipped paths qualified by their type.
+func (px *productXML) toProduct() (*Product, []string, error) {
+	p := &Product{
+		RecordReference:  strings.TrimSpace(px.RecordReference),
+		NotificationType: strings.TrimSpace(px.NotificationType),
+	}
+	var skipped []string
+	skip := func(path, typeName, value string) {
+		skipped = append(skipped, fmt.Sprintf("%s[%s=%s]", path, typeName, value))
+	}
+
+	p.ISBN = pickISBN(px.Identifiers, skip)
+
+	if d := px.Descriptive; d != nil {
+		p.Title = pickTitle(d.Titles, skip)
+		contributors := append([]contributorXML(nil), d.Contributors...)
+		sort.SliceStable(contributors, func(i, j int) bool {
+			return contributors[i].SequenceNumber < contributors[j].SequenceNumber
+		})
+		for _, c := range contributors {
+			contributor := Contributor{Name: c.name()}
+			if len(c.Roles) > 0 {
+				contributor.Role = strings.TrimSpace(c.Roles[0])
+			}
+			if contributor.Name != "" {
+				p.Contributors = append(p.Contributors, contributor)
+			}
+		}
+	}
+
+	if c := px.Collateral; c != nil {
+		p.Description = pickDescription(c.TextContents, skip)
+		for _, resource := range c.Resources {
+			contentType := strings.TrimSpace(resource.ContentType)
+			if contentType != ResourceFrontCover || p.CoverURL != "" || len(resource.Versions) == 0 {
+				skip("CollateralDetail/SupportingResource", "ResourceContentType", contentType)
+				continue
+			}
+			p.CoverURL = strings.TrimSpace(resource.Versions[0].Link)
+		}
+	}
+
+	if pd := px.Publishing; pd != nil {
+		for _, date := range pd.Dates {
+			role := strings.TrimSpace(date.Role)
+			if role != PublicationDate || !p.PublicationDate.IsZero() {
+				skip("PublishingDetail/PublishingDate", "PublishingDateRole", role)
+				continue
+			}
+			publishedAt, err := parseDate(date.Date)
+			if err != nil {
+				return p, skipped, err
+			}
+			p.PublicationDate = publishedAt
+		}
+	}
+
+	if s := px.Supply; s != nil {
+		for i, detail := range s.Details {
+			if i > 0 {
+				skipped = append(skipped, "ProductSupply/SupplyDetail[additional]")
+				continue
+			}
+			for j, price := range detail.Prices {
+				if j > 0 {
+					skip("ProductSupply/SupplyDetail/Price", "PriceType", strings.TrimSpace(price.Type))
+					continue
+				}
+				amount, err := decimal.NewFromString(strings.TrimSpace(price.Amount))
+				if err != nil {
+					return p, skipped, fmt.Errorf("invalid PriceAmount %q", price.Amount)
+				}
+				p.Price = &Price{
+					Type:     strings.TrimSpace(price.Type),
+					Amount:   amount,
+					Currency: strings.TrimSpace(price.Currency),
+				}
+			}
+			for _, stock := range detail.Stocks {
+				if stock.OnHand != nil {
+					p.OnHand = stock.OnHand
+					break
+				}
+			}
+		}
+	}
+
+	return p, skipped, nil
+}
+
+// pickISBN prefers an ISBN-13, then a GTIN-13 in the Bookland range, then an ISBN-10
+func pickISBN(identifiers []identifierXML, skip func(path, typeName, value string)) string {
+	var isbn13, gtin, isbn10 string
+	for _, id := range identifiers {
+		value := strings.TrimSpace(id.Value)
+		switch strings.TrimSpace(id.Type) {
+		case ProductIDISBN13:
+			isbn13 = value
+		case ProductIDGTIN13:
+			if strings.HasPrefix(value, "978") || strings.HasPrefix(value, "979") {
+				gtin = value
+			}
+		case ProductIDISBN10:
+			isbn10 = value
+		default:
+			skip("ProductIdentifier", "ProductIDType", strings.TrimSpace(id.Type))
+		}
+	}
+	switch {
+	case isbn13 != "":
+		return isbn13
+	case gtin != "":
+		return gtin
+	}
+	return isbn10
+}
+
+// pickTitle returns the product-level distinctive title, falling back to the first one
+func pickTitle(details []titleDetailXML, skip func(path, typeName, value string)) string {
+	var chosen *titleDetailXML
+	for i := range details {
+		if strings.TrimSpace(details[i].Type) == TitleTypeDistinctive && chosen == nil {
+			chosen = &details[i]
+		}
+	}
+	if chosen == nil && len(details) > 0 {
+		chosen = &details[0]
+	}
+	for i := range details {
+		if &details[i] != chosen {
+			skip("DescriptiveDetail/TitleDetail", "TitleType", strings.TrimSpace(details[i].Type))
+		}
+	}
+	if chosen == nil || len(chosen.Elements) == 0 {
+		return ""
+	}
+
+	element := chosen.Elements[0]
+	for _, e := range chosen.Elements {
+		if strings.TrimSpace(e.Level) == TitleLevelProduct {
+			element = e
+			break
+		}
+	}
+
+	title := strings.TrimSpace(element.Text)
+	if title == "" {
+		title = strings.TrimSpace(strings.TrimSpace(element.Prefix) + " " + strings.TrimSpace(element.WithoutPrefix))
+	}
+	if subtitle := strings.TrimSpace(element.Subtitle); subtitle != "" {
+		title += ": " + subtitle
+	}
+	return title
+}
+
+// pickDescription prefers the main description over the short one
+func pickDescription(texts []textContentXML, skip func(path, typeName, value string)) string {
+	var description, short string
+	for _, text := range texts {
+		textType := strings.TrimSpace(text.Type)
+		switch {
+		case textType == TextTypeDescription && description == "":
+			description = text.Text.content()
+		case textType == TextTypeShortDescription && short == "":
+			short = text.Text.content()
+		default:
+			skip("CollateralDetail/TextContent", "TextType", textType)
+		}
+	}
+	if description != "" {
+		return description
+	}
+	return short
+}
+
+// content returns XHTML text as markup and any other format as plain text
+func (t textXML) content() string {
+	if t.Format == "05" {
+		return strings.TrimSpace(t.Markup)
+	}
+	return strings.TrimSpace(t.Value)
+}
+
+func (c contributorXML) name() string {
+	switch {
+	case strings.TrimSpace(c.PersonName) != "":
+		return strings.TrimSpace(c.PersonName)
+	case strings.TrimSpace(c.KeyNames) != "":
+		return strings.TrimSpace(strings.TrimSpace(c.NamesBeforeKey) + " " + strings.TrimSpace(c.KeyNames))
+	case strings.TrimSpace(c.Inverted) != "":
+		return strings.TrimSpace(c.Inverted)
+	}
+	return strings.TrimSpace(c.CorporateName)
+}
+
+// parseDate parses an ONIX date in the formats of List 55 that carry a day,
+// month or year
+func parseDate(d dateXML) (time.Time, error) {
+	value := strings.TrimSpace(d.Value)
+	layouts := map[string]string{"00": "20060102", "01": "200601", "05": "2006"}
+
+	layout, ok := layouts[d.Format]
+	if d.Format == "" {
+		switch len(value) {
+		case 8, 6, 4:
+			layout, ok = "20060102"[:len(value)], true
+		}
+	}
+	if !ok {
+		return time.Time{}, fmt.Errorf("unsupported date format %q for date %q", d.Format, value)
+	}
+
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return t, nil
+}
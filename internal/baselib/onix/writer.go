@@ -0,0 +1,166 @@
+package onix
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// sentDateTimeLayout is the SentDateTime format, in UTC
+const sentDateTimeLayout = "20060102T150405Z"
+
+// Writer streams products into an ONIX 3.0 message. Close must be called
+// to complete the message.
+type Writer struct {
+	w       io.Writer
+	encoder *xml.Encoder
+	sender  string
+	closed  bool
+}
+
+// NewWriter starts a message from sender on w
+func NewWriter(w io.Writer, sender string, sentAt time.Time) (*Writer, error) {
+	if _, err := fmt.Fprintf(w, "%s<ONIXMessage release=%q xmlns=%q>\n", xml.Header, Release, Namespace); err != nil {
+		return nil, err
+	}
+
+	encoder := xml.NewEncoder(w)
+	header := headerXML{
+		Sender:       senderXML{Name: sender},
+		SentDateTime: sentAt.UTC().Format(sentDateTimeLayout),
+	}
+	if err := encoder.Encode(header); err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return nil, err
+	}
+
+	return &Writer{w: w, encoder: encoder, sender: sender}, nil
+}
+
+// Write appends a product record
+func (w *Writer) Write(p *Product) error {
+	if w.closed {
+		return fmt.Errorf("onix: writer is closed")
+	}
+	if err := w.encoder.Encode(w.toXML(p)); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w.w, "\n")
+	return err
+}
+
+// Close ends the message. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	_, err := io.WriteString(w.w, "</ONIXMessage>\n")
+	return err
+}
+
+func (w *Writer) toXML(p *Product) *productXML {
+	notificationType := p.NotificationType
+	if notificationType == "" {
+		notificationType = NotificationConfirmed
+	}
+
+	px := &productXML{
+		RecordReference:  p.RecordReference,
+		NotificationType: notificationType,
+		Identifiers:      []identifierXML{isbnIdentifier(p.ISBN)},
+		Descriptive: &descriptiveXML{
+			Composition: "00", // single-component retail product
+			Form:        "00", // undefined
+			Titles: []titleDetailXML{{
+				Type:     TitleTypeDistinctive,
+				Elements: []titleElementXML{{Level: TitleLevelProduct, Text: p.Title}},
+			}},
+		},
+		Publishing: &publishingXML{Status: PublishingStatusActive},
+	}
+
+	for i, c := range p.Contributors {
+		role := c.Role
+		if role == "" {
+			role = ContributorByAuthor
+		}
+		px.Descriptive.Contributors = append(px.Descriptive.Contributors, contributorXML{
+			SequenceNumber: i + 1,
+			Roles:          []string{role},
+			PersonName:     c.Name,
+		})
+	}
+	if len(px.Descriptive.Contributors) == 0 {
+		px.Descriptive.NoContributor = &struct{}{}
+	}
+
+	if p.Description != "" || p.CoverURL != "" {
+		px.Collateral = &collateralXML{}
+		if p.Description != "" {
+			px.Collateral.TextContents = append(px.Collateral.TextContents, textContentXML{
+				Type:      TextTypeDescription,
+				Audiences: []string{"00"}, // unrestricted
+				Text:      textXML{Format: TextFormatDefault, Value: p.Description},
+			})
+		}
+		if p.CoverURL != "" {
+			px.Collateral.Resources = append(px.Collateral.Resources, resourceXML{
+				ContentType: ResourceFrontCover,
+				Audiences:   []string{"00"},
+				Mode:        ResourceModeImage,
+				Versions:    []resourceVersionXML{{Form: ResourceFormFile, Link: p.CoverURL}},
+			})
+		}
+	}
+
+	if !p.PublicationDate.IsZero() {
+		px.Publishing.Dates = []publishingDateXML{{
+			Role: PublicationDate,
+			Date: dateXML{Format: "00", Value: p.PublicationDate.Format("20060102")},
+		}}
+	}
+
+	// A supply detail must carry a price
+	if p.Price != nil {
+		detail := supplyDetailXML{
+			Supplier:     supplierXML{Role: "00", Name: w.sender}, // unspecified role
+			Availability: AvailabilityInStock,
+		}
+		if p.OnHand != nil {
+			detail.Stocks = []stockXML{{OnHand: p.OnHand}}
+			if *p.OnHand <= 0 {
+				detail.Availability = AvailabilityOutOfStock
+			}
+		}
+		priceType := p.Price.Type
+		if priceType == "" {
+			priceType = PriceRRPIncludingTax
+		}
+		detail.Prices = []priceXML{{
+			Type:     priceType,
+			Amount:   p.Price.Amount.StringFixed(max(-p.Price.Amount.Exponent(), 0)),
+			Currency: p.Price.Currency,
+		}}
+		px.Supply = &supplyXML{Details: []supplyDetailXML{detail}}
+	}
+
+	return px
+}
+
+// isbnIdentifier identifies a product by ISBN, or by a proprietary
+// identifier when the value is not a plain ISBN
+func isbnIdentifier(isbn string) identifierXML {
+	value := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+	switch len(value) {
+	case 13:
+		return identifierXML{Type: ProductIDISBN13, Value: value}
+	case 10:
+		return identifierXML{Type: ProductIDISBN10, Value: value}
+	}
+	return identifierXML{Type: ProductIDProprietary, TypeName: "ISBN", Value: isbn}
+}
@@ -0,0 +1,194 @@
+package onix
+
+import "encoding/xml"
+
+// The types below mirror the parts of the ONIX 3.0 schema that Product
+// models, in schema order so that written records validate.
+
+type headerXML struct {
+	XMLName      xml.Name  `xml:"Header"`
+	Sender       senderXML `xml:"Sender"`
+	SentDateTime string    `xml:"SentDateTime"`
+}
+
+type senderXML struct {
+	Name string `xml:"SenderName"`
+}
+
+type productXML struct {
+	XMLName          xml.Name        `xml:"Product"`
+	RecordReference  string          `xml:"RecordReference"`
+	NotificationType string          `xml:"NotificationType"`
+	Identifiers      []identifierXML `xml:"ProductIdentifier"`
+	Descriptive      *descriptiveXML `xml:"DescriptiveDetail"`
+	Collateral       *collateralXML  `xml:"CollateralDetail"`
+	Publishing       *publishingXML  `xml:"PublishingDetail"`
+	Supply           *supplyXML      `xml:"ProductSupply"`
+}
+
+type identifierXML struct {
+	Type     string `xml:"ProductIDType"`
+	TypeName string `xml:"IDTypeName,omitempty"`
+	Value    string `xml:"IDValue"`
+}
+
+type descriptiveXML struct {
+	Composition   string           `xml:"ProductComposition"`
+	Form          string           `xml:"ProductForm"`
+	Titles        []titleDetailXML `xml:"TitleDetail"`
+	Contributors  []contributorXML `xml:"Contributor"`
+	NoContributor *struct{}        `xml:"NoContributor"`
+}
+
+type titleDetailXML struct {
+	Type     string            `xml:"TitleType"`
+	Elements []titleElementXML `xml:"TitleElement"`
+}
+
+type titleElementXML struct {
+	Level         string `xml:"TitleElementLevel"`
+	Text          string `xml:"TitleText,omitempty"`
+	Prefix        string `xml:"TitlePrefix,omitempty"`
+	WithoutPrefix string `xml:"TitleWithoutPrefix,omitempty"`
+	Subtitle      string `xml:"Subtitle,omitempty"`
+}
+
+type contributorXML struct {
+	SequenceNumber int      `xml:"SequenceNumber,omitempty"`
+	Roles          []string `xml:"ContributorRole"`
+	PersonName     string   `xml:"PersonName,omitempty"`
+	Inverted       string   `xml:"PersonNameInverted,omitempty"`
+	NamesBeforeKey string   `xml:"NamesBeforeKey,omitempty"`
+	KeyNames       string   `xml:"KeyNames,omitempty"`
+	CorporateName  string   `xml:"CorporateName,omitempty"`
+}
+
+type collateralXML struct {
+	TextContents []textContentXML `xml:"TextContent"`
+	Resources    []resourceXML    `xml:"SupportingResource"`
+}
+
+type textContentXML struct {
+	Type      string   `xml:"TextType"`
+	Audiences []string `xml:"ContentAudience"`
+	Text      textXML  `xml:"Text"`
+}
+
+// textXML keeps both the text and the raw markup, since XHTML content is
+// made of child elements rather than character data
+type textXML struct {
+	Format string `xml:"textformat,attr,omitempty"`
+	Value  string `xml:",chardata"`
+	Markup string `xml:",innerxml"`
+}
+
+type resourceXML struct {
+	ContentType string               `xml:"ResourceContentType"`
+	Audiences   []string             `xml:"ContentAudience"`
+	Mode        string               `xml:"ResourceMode"`
+	Versions    []resourceVersionXML `xml:"ResourceVersion"`
+}
+
+type resourceVersionXML struct {
+	Form string `xml:"ResourceForm"`
+	Link string `xml:"ResourceLink"`
+}
+
+type publishingXML struct {
+	Status string              `xml:"PublishingStatus,omitempty"`
+	Dates  []publishingDateXML `xml:"PublishingDate"`
+}
+
+type publishingDateXML struct {
+	Role string  `xml:"PublishingDateRole"`
+	Date dateXML `xml:"Date"`
+}
+
+type dateXML struct {
+	Format string `xml:"dateformat,attr,omitempty"`
+	Value  string `xml:",chardata"`
+}
+
+type supplyXML struct {
+	Details []supplyDetailXML `xml:"SupplyDetail"`
+}
+
+type supplyDetailXML struct {
+	Supplier     supplierXML `xml:"Supplier"`
+	Availability string      `xml:"ProductAvailability"`
+	Stocks       []stockXML  `xml:"Stock"`
+	Prices       []priceXML  `xml:"Price"`
+}
+
+type supplierXML struct {
+	Role string `xml:"SupplierRole"`
+	Name string `xml:"SupplierName,omitempty"`
+}
+
+type stockXML struct {
+	OnHand *int `xml:"OnHand"`
+}
+
+type priceXML struct {
+	Type     string `xml:"PriceType,omitempty"`
+	Amount   string `xml:"PriceAmount"`
+	Currency string `xml:"CurrencyCode,omitempty"`
+}
+
+// knownPaths are the product element paths Reader maps onto Product or
+// needs in order to interpret them. Anything else is reported as unmapped.
+var knownPaths = map[string]bool{
+	"RecordReference":                                                  true,
+	"NotificationType":                                                 true,
+	"ProductIdentifier":                                                true,
+	"ProductIdentifier/ProductIDType":                                  true,
+	"ProductIdentifier/IDTypeName":                                     true,
+	"ProductIdentifier/IDValue":                                        true,
+	"DescriptiveDetail":                                                true,
+	"DescriptiveDetail/TitleDetail":                                    true,
+	"DescriptiveDetail/TitleDetail/TitleType":                          true,
+	"DescriptiveDetail/TitleDetail/TitleElement":                       true,
+	"DescriptiveDetail/TitleDetail/TitleElement/TitleElementLevel":     true,
+	"DescriptiveDetail/TitleDetail/TitleElement/TitleText":             true,
+	"DescriptiveDetail/TitleDetail/TitleElement/TitlePrefix":           true,
+	"DescriptiveDetail/TitleDetail/TitleElement/TitleWithoutPrefix":    true,
+	"DescriptiveDetail/TitleDetail/TitleElement/Subtitle":              true,
+	"DescriptiveDetail/Contributor":                                    true,
+	"DescriptiveDetail/Contributor/SequenceNumber":                     true,
+	"DescriptiveDetail/Contributor/ContributorRole":                    true,
+	"DescriptiveDetail/Contributor/PersonName":                         true,
+	"DescriptiveDetail/Contributor/PersonNameInverted":                 true,
+	"DescriptiveDetail/Contributor/NamesBeforeKey":                     true,
+	"DescriptiveDetail/Contributor/KeyNames":                           true,
+	"DescriptiveDetail/Contributor/CorporateName":                      true,
+	"DescriptiveDetail/NoContributor":                                  true,
+	"CollateralDetail":                                                 true,
+	"CollateralDetail/TextContent":                                     true,
+	"CollateralDetail/TextContent/TextType":                            true,
+	"CollateralDetail/TextContent/ContentAudience":                     true,
+	"CollateralDetail/TextContent/Text":                                true,
+	"CollateralDetail/SupportingResource":                              true,
+	"CollateralDetail/SupportingResource/ResourceContentType":          true,
+	"CollateralDetail/SupportingResource/ContentAudience":              true,
+	"CollateralDetail/SupportingResource/ResourceMode":                 true,
+	"CollateralDetail/SupportingResource/ResourceVersion":              true,
+	"CollateralDetail/SupportingResource/ResourceVersion/ResourceForm": true,
+	"CollateralDetail/SupportingResource/ResourceVersion/ResourceLink": true,
+	"PublishingDetail":                                                 true,
+	"PublishingDetail/PublishingDate":                                  true,
+	"PublishingDetail/PublishingDate/PublishingDateRole":               true,
+	"PublishingDetail/PublishingDate/Date":                             true,
+	"ProductSupply":                                                    true,
+	"ProductSupply/SupplyDetail":                                       true,
+	"ProductSupply/SupplyDetail/Stock":                                 true,
+	"ProductSupply/SupplyDetail/Stock/OnHand":                          true,
+	"ProductSupply/SupplyDetail/Price":                                 true,
+	"ProductSupply/SupplyDetail/Price/PriceType":                       true,
+	"ProductSupply/SupplyDetail/Price/PriceAmount":                     true,
+	"ProductSupply/SupplyDetail/Price/CurrencyCode":                    true,
+}
+
+// opaquePaths hold free content whose children are not ONIX elements
+var opaquePaths = map[string]bool{
+	"CollateralDetail/TextContent/Text": true,
+}
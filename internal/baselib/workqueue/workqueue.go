@@ -0,0 +1,69 @@
+// Package workqueue runs queued tasks on a fixed number of workers for as
+// long as a context lives.
+package workqueue
+
+import (
+	"context"
+	"sync"
+)
+
+// Queue holds tasks until one of its workers takes them
+type Queue[T any] struct {
+	tasks   chan T
+	workers int
+}
+
+// New creates a queue run by workers goroutines, holding at most capacity
+// tasks that wait for one
+func New[T any](workers, capacity int) *Queue[T] {
+	return &Queue[T]{
+		tasks:   make(chan T, capacity),
+		workers: workers,
+	}
+}
+
+// Push queues a task, reporting false without queueing it when the queue
+// is full
+func (q *Queue[T]) Push(task T) bool {
+	select {
+	case q.tasks <- task:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run hands the queued tasks to handle on the queue's workers until ctx is
+// done. It then waits for the tasks being handled, which see ctx done, and
+// passes each task still queued to drop. It must run only once at a time.
+func (q *Queue[T]) Run(ctx context.Context, handle func(ctx context.Context, task T), drop func(task T)) {
+	var wg sync.WaitGroup
+	for range q.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				// A closing ctx wins over queued tasks
+				if ctx.Err() != nil {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case task := <-q.tasks:
+					handle(ctx, task)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for {
+		select {
+		case task := <-q.tasks:
+			drop(task)
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package workqueue
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	q := New[int](2, 10)
+	for i := range 3 {
+		if !q.Push(i) {
+			t.Fatalf("Push(%d) = false on a queue with room", i)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var handled atomic.Int32
+	var running sync.WaitGroup
+	running.Add(1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.Run(ctx, func(ctx context.Context, task int) {
+			if handled.Add(1) == 3 {
+				running.Done()
+			}
+		}, func(int) { t.Error("drop() called for a task the workers had time to take") })
+	}()
+
+	running.Wait()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run() did not return once ctx was done")
+	}
+	if n := handled.Load(); n != 3 {
+		t.Errorf("handled %d tasks, want 3", n)
+	}
+}
+
+func TestRunDropsQueuedTasks(t *testing.T) {
+	q := New[int](1, 2)
+	q.Push(1)
+	q.Push(2)
+	if q.Push(3) {
+		t.Error("Push() = true on a full queue")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var dropped []int
+	q.Run(ctx, func(context.Context, int) { t.Error("handle() called after ctx was done") }, func(task int) {
+		dropped = append(dropped, task)
+	})
+	if len(dropped) != 2 {
+		t.Errorf("dropped %v, want both queued tasks", dropped)
+	}
+}
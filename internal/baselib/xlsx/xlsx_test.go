@@ -0,0 +1,143 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readPart returns the content of a part of a written workbook
+func readPart(t *testing.T, data []byte, name string) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("workbook is not a zip archive: %v", err)
+	}
+	f, err := zr.Open(name)
+	if err != nil {
+		t.Fatalf("workbook has no %s: %v", name, err)
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", name, err)
+	}
+	return string(content)
+}
+
+func TestWriteRow(t *testing.T) {
+	tests := []struct {
+		name  string
+		cells []any
+		want  string
+	}{
+		{
+			name:  "string",
+			cells: []any{"Dune & <Sons>"},
+			want:  `<row r="1"><c r="A1" t="inlineStr"><is><t xml:space="preserve">Dune &amp; &lt;Sons&gt;</t></is></c></row>`,
+		},
+		{
+			name:  "numbers",
+			cells: []any{3, int64(-4), 19.5, Number("19.990")},
+			want:  `<row r="1"><c r="A1"><v>3</v></c><c r="B1"><v>-4</v></c><c r="C1"><v>19.5</v></c><c r="D1"><v>19.990</v></c></row>`,
+		},
+		{
+			name:  "bools",
+			cells: []any{true, false},
+			want:  `<row r="1"><c r="A1" t="b"><v>1</v></c><c r="B1" t="b"><v>0</v></c></row>`,
+		},
+		{
+			name:  "date",
+			cells: []any{time.Date(2000, time.January, 1, 12, 0, 0, 0, time.UTC)},
+			want:  `<row r="1"><c r="A1" s="1"><v>36526.5</v></c></row>`,
+		},
+		{
+			name:  "empty cells keep their column",
+			cells: []any{nil, time.Time{}, "c"},
+			want:  `<row r="1"><c r="C1" t="inlineStr"><is><t xml:space="preserve">c</t></is></c></row>`,
+		},
+		{
+			name:  "other values",
+			cells: []any{[]int{1, 2}},
+			want:  `<row r="1"><c r="A1" t="inlineStr"><is><t xml:space="preserve">[1 2]</t></is></c></row>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, "Books")
+			if err != nil {
+				t.Fatalf("NewWriter() error = %v", err)
+			}
+			if err := w.WriteRow(tt.cells...); err != nil {
+				t.Fatalf("WriteRow() error = %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			sheet := readPart(t, buf.Bytes(), "xl/worksheets/sheet1.xml")
+			if !strings.Contains(sheet, "<sheetData>"+tt.want+"</sheetData>") {
+				t.Errorf("sheet = %s, want row %s", sheet, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriterWorkbook(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, `Books "A&B"`)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := w.WriteRow(i); err != nil {
+			t.Fatalf("WriteRow() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+	if err := w.WriteRow(1); !errors.Is(err, ErrClosed) {
+		t.Errorf("WriteRow() after Close error = %v, want ErrClosed", err)
+	}
+
+	for _, part := range []string{"[Content_Types].xml", "_rels/.rels", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		readPart(t, buf.Bytes(), part)
+	}
+	if workbook := readPart(t, buf.Bytes(), "xl/workbook.xml"); !strings.Contains(workbook, `name="Books &#34;A&amp;B&#34;"`) {
+		t.Errorf("workbook = %s, want the escaped sheet name", workbook)
+	}
+	if sheet := readPart(t, buf.Bytes(), "xl/worksheets/sheet1.xml"); !strings.Contains(sheet, `<row r="3"><c r="A3"><v>2</v></c></row></sheetData></worksheet>`) {
+		t.Errorf("sheet = %s, want three numbered rows", sheet)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, "A"},
+		{25, "Z"},
+		{26, "AA"},
+		{51, "AZ"},
+		{52, "BA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+
+	for _, tt := range tests {
+		if got := columnName(tt.index); got != tt.want {
+			t.Errorf("columnName(%d) = %q, want %q", tt.index, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"log/slog"
 	"sync"
 
@@ -23,6 +24,48 @@ type config struct {
 		Burst int `mapstructure:"burst"`
 		Rate  int `mapstructure:"rate"`
 	}
+	Book struct {
+		RequireIfMatch    bool   `mapstructure:"require-if-match"`
+		LowStockThreshold int    `mapstructure:"low-stock-threshold"`
+		Currency          string `mapstructure:"currency"`
+	}
+	Onix struct {
+		SenderName string `mapstructure:"sender-name"`
+	}
+	Lookup struct {
+		BaseURL  string `mapstructure:"base-url"`
+		Timeout  int    `mapstructure:"timeout"`
+		CacheTTL int    `mapstructure:"cache-ttl"`
+	}
+	Orders struct {
+		PendingMinutes int `mapstructure:"pending-minutes"`
+		ExpireInterval int `mapstructure:"expire-interval"`
+	}
+	Payment struct {
+		Provider          string `mapstructure:"provider"`
+		AllowFake         bool   `mapstructure:"allow-fake"`
+		WebhookSecret     string `mapstructure:"webhook-secret"`
+		ReconcileInterval int    `mapstructure:"reconcile-interval"`
+		StuckAfter        int    `mapstructure:"stuck-after"`
+	}
+	Circulation struct {
+		LoanDays        map[string]int `mapstructure:"loan-days"`
+		DefaultLoanDays int            `mapstructure:"default-loan-days"`
+		RenewalLimit    int            `mapstructure:"renewal-limit"`
+	}
+	Holds struct {
+		PickupHours    int `mapstructure:"pickup-hours"`
+		ExpireInterval int `mapstructure:"expire-interval"`
+	}
+	Fines struct {
+		BlockThreshold float64 `mapstructure:"block-threshold"`
+		AccrueHour     int     `mapstructure:"accrue-hour"`
+	}
+	Recommendations struct {
+		RefreshInterval int `mapstructure:"refresh-interval"`
+		CacheTTL        int `mapstructure:"cache-ttl"`
+		Neighbours      int `mapstructure:"neighbours"`
+	}
 	Codec struct {
 		SecretKey uint32 `mapstructure:"secret-key"`
 	}
@@ -70,34 +113,73 @@ func setDefault() {
 	viper.SetDefault("database.mysql.password", "default")
 	viper.SetDefault("database.mysql.database", "default")
 	viper.SetDefault("grpc.port", "default")
+	viper.SetDefault("book.low-stock-threshold", 5)
+	viper.SetDefault("book.currency", "USD")
+	viper.SetDefault("onix.sender-name", "Book System")
+	viper.SetDefault("lookup.base-url", "https://openlibrary.org")
+	viper.SetDefault("lookup.timeout", 10)
+	viper.SetDefault("lookup.cache-ttl", 1440)
+	viper.SetDefault("orders.pending-minutes", 60)
+	viper.SetDefault("orders.expire-interval", 5)
+	viper.SetDefault("payment.reconcile-interval", 5)
+	viper.SetDefault("payment.stuck-after", 15)
+	viper.SetDefault("circulation.default-loan-days", 21)
+	viper.SetDefault("circulation.renewal-limit", 2)
+	viper.SetDefault("holds.pickup-hours", 72)
+	viper.SetDefault("holds.expire-interval", 15)
+	viper.SetDefault("fines.block-threshold", 10.0)
+	viper.SetDefault("fines.accrue-hour", 2)
+	viper.SetDefault("recommendations.refresh-interval", 60)
+	viper.SetDefault("recommendations.cache-ttl", 30)
+	viper.SetDefault("recommendations.neighbours", 20)
 }
 
-// Initialize loads and validates the configuration.
-// It's safe to call this function multiple times, but the configuration
-// will only be loaded once.
-func init() {
+// Load reads the configuration file at path over the defaults. It must be
+// called before MustGet; only the first call loads anything.
+func Load(path string) error {
+	var err error
 	once.Do(func() {
 		setDefault()
-		viper.SetConfigFile("config.yaml")
-		if err := viper.ReadInConfig(); err != nil {
-			slog.Error("failed to read config file", "error", err)
-			panic(err)
+		viper.SetConfigFile(path)
+		if err = viper.ReadInConfig(); err != nil {
+			err = fmt.Errorf("failed to read config file: %w", err)
+			return
 		}
 
 		var cfg config
-		if err := viper.Unmarshal(&cfg); err != nil {
-			slog.Error("failed to unmarshal config", "error", err)
-			panic(err)
+		if err = viper.Unmarshal(&cfg); err != nil {
+			err = fmt.Errorf("failed to unmarshal config: %w", err)
+			return
 		}
 
 		instance = &cfg
-		slog.Info("configuration loaded successfully", slog.Any("config", cfg))
+		slog.Info("configuration loaded successfully", slog.Any("config", cfg.redacted()))
 	})
+	return err
+}
+
+// redacted returns a copy of the configuration that is safe to log, with
+// its secrets masked
+func (c config) redacted() config {
+	mask := func(secret *string) {
+		if *secret != "" {
+			*secret = "***"
+		}
+	}
+	mask(&c.JWT.AccessSecret)
+	mask(&c.JWT.RefreshSecret)
+	mask(&c.Payment.WebhookSecret)
+	mask(&c.Casbin.DSN)
+	mask(&c.Database.Mysql.Password)
+	mask(&c.Redis.Password)
+	mask(&c.Minio.SecretKey)
+	c.Codec.SecretKey = 0
+	return c
 }
 
 func get() *config {
 	if instance == nil {
-		panic("config not initialized, call Initialize() first")
+		panic("config not initialized, call Load() first")
 	}
 	return instance
 }
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedacted(t *testing.T) {
+	var cfg config
+	cfg.Payment.Provider = "stripe"
+	cfg.Payment.WebhookSecret = "whsec_live"
+	cfg.JWT.AccessSecret = "access-key"
+	cfg.Database.Mysql.Password = "db-password"
+	cfg.Codec.SecretKey = 424242
+
+	var logged bytes.Buffer
+	slog.New(slog.NewJSONHandler(&logged, nil)).Info("configuration loaded successfully", slog.Any("config", cfg.redacted()))
+
+	for _, secret := range []string{"whsec_live", "access-key", "db-password", "424242"} {
+		if strings.Contains(logged.String(), secret) {
+			t.Errorf("logged configuration contains %q: %s", secret, logged.String())
+		}
+	}
+	if !strings.Contains(logged.String(), "stripe") {
+		t.Errorf("logged configuration lost its other settings: %s", logged.String())
+	}
+	if cfg.Payment.WebhookSecret != "whsec_live" {
+		t.Errorf("redacted() changed the configuration it copied")
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/url"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -158,8 +160,41 @@ func UploadFile(ctx context.Context, fileHeader *multipart.FileHeader, customPat
 	}
 
 	// Generate URL for the uploaded file
-	fileURL := returnURL + "/" + defaultBucket + "/" + objectName
-	return fileURL, nil
+	return ObjectURL(objectName), nil
+}
+
+// ObjectURL returns the permanent URL of an object, the form UploadFile returns
+func ObjectURL(objectName string) string {
+	return returnURL + "/" + defaultBucket + "/" + objectName
+}
+
+// PutObject uploads the content of reader to MinIO under objectName.
+// A size of -1 streams content of unknown length.
+func PutObject(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) error {
+	if minioClient == nil {
+		return errors.New("minio client not initialized")
+	}
+
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	_, err := minioClient.PutObject(
+		ctx,
+		defaultBucket,
+		objectName,
+		reader,
+		size,
+		minio.PutObjectOptions{ContentType: contentType},
+	)
+	return err
+}
+
+// ObjectNameFromURL extracts the object name from a URL returned by
+// UploadFile. Values that are not such URLs are returned unchanged.
+func ObjectNameFromURL(fileURL string) string {
+	prefix := returnURL + "/" + defaultBucket + "/"
+	return strings.TrimPrefix(fileURL, prefix)
 }
 
 // GetFile retrieves a file from MinIO
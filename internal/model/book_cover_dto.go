@@ -0,0 +1,22 @@
+package model
+
+// BookCover holds the URLs of the resized copies of an uploaded cover.
+// Large is also the book's CoverImage.
+type BookCover struct {
+	Thumbnail string `json:"thumbnail"`
+	Medium    string `json:"medium"`
+	Large     string `json:"large"`
+}
+
+// URLs lists the URLs of all the variants
+func (c *BookCover) URLs() []string {
+	return []string{c.Thumbnail, c.Medium, c.Large}
+}
+
+// DropStaleCover forgets the cover variants once CoverImage has been set
+// to another image, since they no longer show the book's cover
+func (b *Book) DropStaleCover() {
+	if b.Cover != nil && b.Cover.Large != b.CoverImage {
+		b.Cover = nil
+	}
+}
@@ -2,58 +2,189 @@ package model
 
 import (
 	"book_system/internal/infrastructure"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
-// BookResponse represents the book data sent in responses
+// BookResponse represents the book data sent in responses. ContentLanguage
+// is the language Title and Description are shown in; Translations are
+// applied by Localize and listed through their own endpoint. Category is
+// filled in by the book service where it lists or gets books, and Localize
+// names it in the reader's language too. DisplayPrice is the list price in
+// the currency the reader asked for.
 type BookResponse struct {
-	ID          uuid.UUID `json:"id"`
-	Title       string    `json:"title"`
-	Author      string    `json:"author"`
-	Description string    `json:"description,omitempty"`
-	CoverImage  string    `json:"cover_image,omitempty"`
-	Price       float64   `json:"price"`
-	Stock       int       `json:"stock"`
-	ISBN        string    `json:"isbn"`
-	PublishedAt time.Time `json:"published_at"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              uuid.UUID         `json:"id"`
+	Title           string            `json:"title"`
+	Author          string            `json:"author"`
+	Description     string            `json:"description,omitempty"`
+	CoverImage      string            `json:"cover_image,omitempty"`
+	Cover           *BookCover        `json:"cover,omitempty"`
+	Price           decimal.Decimal   `json:"price" swaggertype:"number"`
+	Currency        string            `json:"currency,omitempty"`
+	DisplayPrice    *Money            `json:"display_price,omitempty"`
+	Stock           int               `json:"stock"`
+	ISBN            string            `json:"isbn"`
+	ISBN10          string            `json:"isbn10,omitempty"`
+	PublishedAt     time.Time         `json:"published_at"`
+	WorkID          *uuid.UUID        `json:"work_id,omitempty"`
+	CategoryID      *uuid.UUID        `json:"category_id,omitempty"`
+	Category        *CategoryResponse `json:"category,omitempty"`
+	Format          string            `json:"format,omitempty"`
+	Language        string            `json:"language,omitempty"`
+	PageCount       int               `json:"page_count,omitempty"`
+	ContentLanguage string            `json:"content_language,omitempty"`
+	Translations    BookTranslations  `json:"-"`
+	Rating          float64           `json:"rating"`
+	RatingCount     int               `json:"rating_count"`
+	Version         int               `json:"version"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+	DeletedAt       *time.Time        `json:"deleted_at,omitempty"`
+	Extra           *BookExtra        `json:"extra,omitempty"`
 }
 
 // CreateBookRequest represents the data needed to create a new book
 type CreateBookRequest struct {
-	Title       string    `json:"title" validate:"required,min=1,max=255"`
-	Author      string    `json:"author" validate:"required,min=1,max=255"`
-	Description string    `json:"description"`
-	CoverImage  string    `json:"cover_image"`
-	Price       float64   `json:"price" validate:"required,gt=0"`
-	Stock       int       `json:"stock" validate:"gte=0"`
-	ISBN        string    `json:"isbn" validate:"required,isbn"`
-	PublishedAt time.Time `json:"published_at" validate:"required"`
+	Title       string          `json:"title" validate:"required,min=1,max=255"`
+	Author      string          `json:"author" validate:"required,min=1,max=255"`
+	Description string          `json:"description"`
+	CoverImage  string          `json:"cover_image"`
+	Price       decimal.Decimal `json:"price" swaggertype:"number"`
+	Currency    string          `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	Stock       int             `json:"stock" validate:"gte=0"`
+	ISBN        string          `json:"isbn" validate:"required,isbn"`
+	PublishedAt time.Time       `json:"published_at" validate:"required"`
+	WorkID      *uuid.UUID      `json:"work_id,omitempty"`
+	CategoryID  *uuid.UUID      `json:"category_id,omitempty"`
+	Format      string          `json:"format" validate:"omitempty,oneof=hardcover paperback ebook audiobook"`
+	Language    string          `json:"language" validate:"omitempty,bcp47_language_tag"`
+	PageCount   int             `json:"page_count" validate:"gte=0"`
+	// Extra is only set by imports
+	Extra *BookExtra `json:"-"`
 }
 
 // Validate validates the CreateBookRequest
 func (r *CreateBookRequest) Validate() error {
-	return infrastructure.Validate.Struct(r)
+	if err := infrastructure.Validate.Struct(r); err != nil {
+		return err
+	}
+	return validatePrice(r.Price, r.Currency)
 }
 
 // UpdateBookRequest represents the data needed to update a book
 type UpdateBookRequest struct {
-	Title       *string    `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
-	Author      *string    `json:"author,omitempty" validate:"omitempty,min=1,max=255"`
-	Description *string    `json:"description,omitempty"`
-	CoverImage  *string    `json:"cover_image,omitempty"`
-	Price       *float64   `json:"price,omitempty" validate:"omitempty,gt=0"`
-	Stock       *int       `json:"stock,omitempty" validate:"omitempty,gte=0"`
+	Title       *string          `json:"title,omitempty" validate:"omitempty,min=1,max=255"`
+	Author      *string          `json:"author,omitempty" validate:"omitempty,min=1,max=255"`
+	Description *string          `json:"description,omitempty"`
+	CoverImage  *string          `json:"cover_image,omitempty"`
+	Price       *decimal.Decimal `json:"price,omitempty" swaggertype:"number"`
+	Currency    *string          `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	// Stock is refused
+	Stock       *int       `json:"stock,omitempty"`
 	ISBN        *string    `json:"isbn,omitempty" validate:"omitempty,isbn"`
 	PublishedAt *time.Time `json:"published_at,omitempty"`
+	WorkID      *uuid.UUID `json:"work_id,omitempty"`
+	CategoryID  *uuid.UUID `json:"category_id,omitempty"`
+	Format      *string    `json:"format,omitempty" validate:"omitempty,oneof=hardcover paperback ebook audiobook"`
+	Language    *string    `json:"language,omitempty" validate:"omitempty,bcp47_language_tag"`
+	PageCount   *int       `json:"page_count,omitempty" validate:"omitempty,gte=0"`
+	// Extra is only set by imports; nil leaves it unchanged
+	Extra *BookExtra `json:"-"`
+	// Cover is only set by cover uploads, together with CoverImage
+	Cover *BookCover `json:"-"`
 }
 
-// Validate validates the UpdateBookRequest
+// Validate validates the UpdateBookRequest. A price without a currency
+// is checked against cents, like a price in the catalog currency.
 func (r *UpdateBookRequest) Validate() error {
-	return infrastructure.Validate.Struct(r)
+	if err := infrastructure.Validate.Struct(r); err != nil {
+		return err
+	}
+	if r.Price == nil {
+		return nil
+	}
+	currency := ""
+	if r.Currency != nil {
+		currency = *r.Currency
+	}
+	return validatePrice(*r.Price, currency)
+}
+
+// ReplaceBookRequest represents the full editable state of a book. PUT
+// replaces a book with it, so omitted optional fields are cleared. Stock
+// is not part of it and sending one is refused.
+type ReplaceBookRequest struct {
+	Title       string          `json:"title" validate:"required,min=1,max=255"`
+	Author      string          `json:"author" validate:"required,min=1,max=255"`
+	Description string          `json:"description"`
+	CoverImage  string          `json:"cover_image"`
+	Price       decimal.Decimal `json:"price" swaggertype:"number"`
+	Currency    string          `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	Stock       *int            `json:"stock,omitempty"`
+	ISBN        string          `json:"isbn" validate:"required,isbn"`
+	PublishedAt time.Time       `json:"published_at" validate:"required"`
+	WorkID      *uuid.UUID      `json:"work_id,omitempty"`
+	CategoryID  *uuid.UUID      `json:"category_id,omitempty"`
+	Format      string          `json:"format" validate:"omitempty,oneof=hardcover paperback ebook audiobook"`
+	Language    string          `json:"language" validate:"omitempty,bcp47_language_tag"`
+	PageCount   int             `json:"page_count" validate:"gte=0"`
+	// Extra is only set by imports
+	Extra *BookExtra `json:"-"`
+}
+
+// Validate validates the ReplaceBookRequest
+func (r *ReplaceBookRequest) Validate() error {
+	if err := infrastructure.Validate.Struct(r); err != nil {
+		return err
+	}
+	return validatePrice(r.Price, r.Currency)
+}
+
+// validatePrice checks that a book price is positive and kept to the
+// minor unit of its currency, or to cents when the currency is left to
+// its default
+func validatePrice(price decimal.Decimal, currency string) error {
+	if !price.IsPositive() {
+		return errors.New("price must be positive")
+	}
+	if !price.Equal(RoundMoney(price, currency)) {
+		return fmt.Errorf("price must have at most %d decimal places", CurrencyExponent(currency))
+	}
+	return nil
+}
+
+// ToUpdate converts the replacement into an update that sets every field.
+// A missing work or category is sent as uuid.Nil, which detaches the book
+// from it.
+func (r *ReplaceBookRequest) ToUpdate() *UpdateBookRequest {
+	workID, categoryID := uuid.Nil, uuid.Nil
+	if r.WorkID != nil {
+		workID = *r.WorkID
+	}
+	if r.CategoryID != nil {
+		categoryID = *r.CategoryID
+	}
+	return &UpdateBookRequest{
+		Title:       &r.Title,
+		Author:      &r.Author,
+		Description: &r.Description,
+		CoverImage:  &r.CoverImage,
+		Price:       &r.Price,
+		Currency:    &r.Currency,
+		Stock:       r.Stock,
+		ISBN:        &r.ISBN,
+		PublishedAt: &r.PublishedAt,
+		WorkID:      &workID,
+		CategoryID:  &categoryID,
+		Format:      &r.Format,
+		Language:    &r.Language,
+		PageCount:   &r.PageCount,
+		Extra:       r.Extra,
+	}
 }
 
 // BookListResponse represents a paginated list of books
@@ -62,6 +193,13 @@ type BookListResponse struct {
 	Pagination Pagination      `json:"pagination"`
 }
 
+// Book list orders. Rating puts the best rated books first, reviews the
+// most rated; unrated books come last either way.
+const (
+	BookSortRating  = "rating"
+	BookSortReviews = "reviews"
+)
+
 // Pagination represents pagination metadata
 type Pagination struct {
 	Page      int   `json:"page"`
@@ -1,23 +1,44 @@
 package model
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
+// Book is a catalog entry, one edition of its Work
 type Book struct {
-	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
-	Title       string    `gorm:"size:255;not null"`
-	Author      string    `gorm:"size:255;not null"`
-	Description string    `gorm:"type:text"`
-	CoverImage  string    `gorm:"size:512"`
-	Price       float64   `gorm:"type:decimal(10,2);not null"`
-	Stock       int       `gorm:"not null;default:0"`
-	ISBN        string    `gorm:"size:20;uniqueIndex"`
-	PublishedAt time.Time `gorm:"type:date"`
-	CreatedAt   time.Time `gorm:"not null"`
-	UpdatedAt   time.Time `gorm:"not null"`
+	ID          uuid.UUID       `gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Title       string          `gorm:"size:255;not null"`
+	Author      string          `gorm:"size:255;not null"`
+	Description string          `gorm:"type:text"`
+	CoverImage  string          `gorm:"size:512"`
+	Price       decimal.Decimal `gorm:"type:decimal(11,3);not null"`
+	Currency    string          `gorm:"size:3"`
+	// Stock is physical and only changes through the inventory ledger
+	Stock  int    `gorm:"not null;default:0"`
+	ISBN   string `gorm:"size:20;index"`
+	ISBN10 string `gorm:"column:isbn10;size:10"`
+	// ActiveISBN is ISBN while the book is live, so trashed books can share theirs
+	ActiveISBN   *string          `gorm:"->;type:varchar(20) GENERATED ALWAYS AS (IF(deleted_at IS NULL, isbn, NULL)) STORED;uniqueIndex:idx_books_active_isbn"`
+	PublishedAt  time.Time        `gorm:"type:date"`
+	WorkID       *uuid.UUID       `gorm:"type:uuid;index"`
+	CategoryID   *uuid.UUID       `gorm:"type:uuid;index"`
+	Format       string           `gorm:"size:20"`
+	Language     string           `gorm:"size:35"`
+	PageCount    int              `gorm:"not null;default:0"`
+	Translations BookTranslations `gorm:"serializer:json;type:json"`
+	Cover        *BookCover       `gorm:"serializer:json;type:json"`
+	Extra        *BookExtra       `gorm:"serializer:json;type:json"`
+	RatingSum    int              `gorm:"not null;default:0"`
+	RatingCount  int              `gorm:"not null;default:0;index"`
+	Version      int              `gorm:"not null;default:1"`
+	CreatedAt    time.Time        `gorm:"not null"`
+	UpdatedAt    time.Time        `gorm:"not null"`
+	DeletedAt    gorm.DeletedAt   `gorm:"index"`
 }
 
 func (Book) TableName() string {
@@ -26,17 +47,61 @@ func (Book) TableName() string {
 
 // ToDTO converts Book entity to Book DTO
 func (b *Book) ToDTO() *BookResponse {
-	return &BookResponse{
-		ID:          b.ID,
+	dto := &BookResponse{
+		ID:           b.ID,
+		Title:        b.Title,
+		Author:       b.Author,
+		Description:  b.Description,
+		CoverImage:   b.CoverImage,
+		Cover:        b.Cover,
+		Price:        b.Price,
+		Currency:     b.Currency,
+		Stock:        b.Stock,
+		ISBN:         b.ISBN,
+		ISBN10:       b.ISBN10,
+		PublishedAt:  b.PublishedAt,
+		WorkID:       b.WorkID,
+		CategoryID:   b.CategoryID,
+		Format:       b.Format,
+		Language:     b.Language,
+		PageCount:    b.PageCount,
+		Translations: b.Translations,
+		Rating:       b.Rating(),
+		RatingCount:  b.RatingCount,
+		Version:      b.Version,
+		CreatedAt:    b.CreatedAt,
+		UpdatedAt:    b.UpdatedAt,
+		Extra:        b.Extra,
+	}
+	if b.DeletedAt.Valid {
+		dto.DeletedAt = &b.DeletedAt.Time
+	}
+	return dto
+}
+
+// Rating returns the average star rating, or 0 when the book has no ratings
+func (b *Book) Rating() float64 {
+	if b.RatingCount == 0 {
+		return 0
+	}
+	return math.Round(float64(b.RatingSum)/float64(b.RatingCount)*100) / 100
+}
+
+// ToReplaceRequest returns the editable state that PATCH requests apply to
+func (b *Book) ToReplaceRequest() *ReplaceBookRequest {
+	return &ReplaceBookRequest{
 		Title:       b.Title,
 		Author:      b.Author,
 		Description: b.Description,
 		CoverImage:  b.CoverImage,
 		Price:       b.Price,
-		Stock:       b.Stock,
+		Currency:    b.Currency,
 		ISBN:        b.ISBN,
 		PublishedAt: b.PublishedAt,
-		CreatedAt:   b.CreatedAt,
-		UpdatedAt:   b.UpdatedAt,
+		WorkID:      b.WorkID,
+		CategoryID:  b.CategoryID,
+		Format:      b.Format,
+		Language:    b.Language,
+		PageCount:   b.PageCount,
 	}
 }
@@ -0,0 +1,39 @@
+package model
+
+import "book_system/internal/infrastructure"
+
+// Book export formats
+const (
+	BookExportFormatCSV    = "csv"
+	BookExportFormatNDJSON = "ndjson"
+	BookExportFormatXLSX   = "xlsx"
+	BookExportFormatONIX   = "onix"
+)
+
+// BookExportContentTypes maps export formats to their media types
+var BookExportContentTypes = map[string]string{
+	BookExportFormatCSV:    "text/csv",
+	BookExportFormatNDJSON: "application/x-ndjson",
+	BookExportFormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	BookExportFormatONIX:   "application/xml",
+}
+
+// BookExportFileName returns the file name of an export in the given format
+func BookExportFileName(format string) string {
+	if format == BookExportFormatONIX {
+		return "books.xml"
+	}
+	return "books." + format
+}
+
+// BookExportRequest describes a catalog export. Filters are the same as
+// those of the book list.
+type BookExportRequest struct {
+	Format string `form:"format" validate:"omitempty,oneof=csv ndjson xlsx onix"`
+	Async  bool   `form:"async"`
+}
+
+// Validate validates the BookExportRequest
+func (r *BookExportRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
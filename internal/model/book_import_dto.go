@@ -0,0 +1,25 @@
+package model
+
+import "book_system/internal/infrastructure"
+
+// Book import formats
+const (
+	BookImportFormatCSV     = "csv"
+	BookImportFormatNDJSON  = "ndjson"
+	BookImportFormatONIX    = "onix"
+	BookImportFormatMARC    = "marc"
+	BookImportFormatMARCXML = "marcxml"
+)
+
+// BookImportRequest describes a catalog import. The rows come either from
+// a file uploaded with the request or from an object already in storage.
+type BookImportRequest struct {
+	Format string `form:"format" validate:"omitempty,oneof=csv ndjson onix marc marcxml"`
+	DryRun bool   `form:"dry_run"`
+	Object string `form:"object"`
+}
+
+// Validate validates the BookImportRequest
+func (r *BookImportRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
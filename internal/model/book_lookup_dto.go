@@ -0,0 +1,52 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// BookMetadata is bibliographic data about an ISBN fetched from an
+// external catalog
+type BookMetadata struct {
+	ISBN        string    `json:"isbn"`
+	Title       string    `json:"title"`
+	Authors     []string  `json:"authors,omitempty"`
+	Description string    `json:"description,omitempty"`
+	CoverURL    string    `json:"cover_url,omitempty"`
+	PublishedAt time.Time `json:"published_at,omitempty"`
+	Source      string    `json:"source"`
+}
+
+// ToCreateRequest pre-fills a create request with the metadata. Price and
+// stock are left for staff to set.
+func (m *BookMetadata) ToCreateRequest() *CreateBookRequest {
+	req := &CreateBookRequest{ISBN: m.ISBN}
+	m.Fill(req)
+	return req
+}
+
+// Fill sets the fields of a create request that are still empty from the metadata
+func (m *BookMetadata) Fill(req *CreateBookRequest) {
+	if req.Title == "" {
+		req.Title = m.Title
+	}
+	if req.Author == "" {
+		req.Author = strings.Join(m.Authors, "; ")
+	}
+	if req.Description == "" {
+		req.Description = m.Description
+	}
+	if req.CoverImage == "" {
+		req.CoverImage = m.CoverURL
+	}
+	if req.PublishedAt.IsZero() {
+		req.PublishedAt = m.PublishedAt
+	}
+}
+
+// BookLookupResponse is the result of an ISBN lookup: the fetched metadata
+// and a create request pre-filled from it
+type BookLookupResponse struct {
+	Metadata *BookMetadata      `json:"metadata"`
+	Book     *CreateBookRequest `json:"book"`
+}
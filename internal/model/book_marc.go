@@ -0,0 +1,381 @@
+package model
+
+import (
+	"book_system/internal/baselib/marc"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BookExtra keeps source record data that has no book column, so that an
+// import does not drop it
+type BookExtra struct {
+	// MARC is the whole imported MARC record. Its mapped fields are exported
+	// again as they were for as long as the book still matches them.
+	MARC *marc.Record `json:"marc,omitempty"`
+}
+
+// marcLeader is the leader of generated records: new, language material,
+// monograph, UTF-8, RDA
+const marcLeader = "00000nam a2200000 i 4500"
+
+var (
+	marcYear   = regexp.MustCompile(`\d{4}`)
+	marcAmount = regexp.MustCompile(`\d+(?:[.,]\d+)?`)
+	marcISBN   = regexp.MustCompile(`^[0-9Xx-]+`)
+)
+
+// marcMapping locates, by index, the fields of a record whose data maps
+// onto book columns
+type marcMapping struct {
+	isbn        int
+	title       int
+	authors     []int
+	publication int
+	summary     int
+}
+
+func mapMARC(record *marc.Record) marcMapping {
+	m := marcMapping{isbn: -1, title: -1, publication: -1, summary: -1}
+	for i := range record.Fields {
+		field := &record.Fields[i]
+		switch field.Tag {
+		case "020":
+			if m.isbn < 0 && marcISBNOf(field) != "" {
+				m.isbn = i
+			}
+		case "100":
+			// The main entry leads the authors
+			m.authors = append([]int{i}, m.authors...)
+		case "700":
+			m.authors = append(m.authors, i)
+		case "245":
+			if m.title < 0 {
+				m.title = i
+			}
+		case "260", "264":
+			// Other 264s than second indicator 1 state production,
+			// distribution or copyright rather than publication
+			if m.publication < 0 && marcYearOf(field) != 0 && (field.Tag == "260" || field.Ind2 == "1") {
+				m.publication = i
+			}
+		case "520":
+			if m.summary < 0 {
+				m.summary = i
+			}
+		}
+	}
+	return m
+}
+
+// CreateBookRequestFromMARC maps a MARC bibliographic record onto a create
+// request: 020 ISBN, 100/700 authors, 245 title, 260/264 (or 008)
+// publication year, 520 summary, and the price from 365 or 020. The whole
+// record is kept in Extra. A price in another currency than the catalog
+// currency is kept in that currency, as ONIX imports do.
+func CreateBookRequestFromMARC(record *marc.Record, currency string) (*CreateBookRequest, error) {
+	m := mapMARC(record)
+	req := &CreateBookRequest{Extra: &BookExtra{MARC: record}}
+
+	if m.isbn >= 0 {
+		req.ISBN = marcISBNOf(&record.Fields[m.isbn])
+	}
+	if m.title >= 0 {
+		req.Title = marcTitleOf(&record.Fields[m.title])
+	}
+	authors := make([]string, 0, len(m.authors))
+	for _, i := range m.authors {
+		if name := marcNameOf(&record.Fields[i]); name != "" {
+			authors = append(authors, name)
+		}
+	}
+	req.Author = strings.Join(authors, "; ")
+	if m.summary >= 0 {
+		req.Description = marcSummaryOf(&record.Fields[m.summary])
+	}
+
+	year := 0
+	if m.publication >= 0 {
+		year = marcYearOf(&record.Fields[m.publication])
+	} else if fixed := record.ControlField("008"); len(fixed) >= 11 {
+		year, _ = strconv.Atoi(fixed[7:11])
+	}
+	if year > 0 {
+		req.PublishedAt = time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	amount, priceCurrency := marcPriceOf(record)
+	if priceCurrency != "" && !strings.EqualFold(priceCurrency, currency) {
+		req.Currency = priceCurrency
+	}
+	req.Price = amount
+
+	return req, nil
+}
+
+// ToMARC builds a MARC record of the book. A book imported from MARC keeps
+// its record, except that mapped fields the book no longer matches are
+// replaced by fields generated from its columns. Prices are not exported.
+func (b *BookResponse) ToMARC() *marc.Record {
+	authors := SplitAuthors(b.Author)
+	covered := struct {
+		isbn, title, publication, summary bool
+		authors                           []bool
+	}{authors: make([]bool, len(authors))}
+
+	record := &marc.Record{Leader: marcLeader}
+	if b.Extra != nil && b.Extra.MARC != nil {
+		source := b.Extra.MARC
+		record.Leader = source.Leader
+		m := mapMARC(source)
+		authorAt := make(map[int]int, len(m.authors))
+		for k, i := range m.authors {
+			authorAt[i] = k
+		}
+
+		for i, field := range source.Fields {
+			keep := true
+			switch k, isAuthor := authorAt[i]; {
+			case i == m.isbn:
+				keep = marcISBNOf(&field) == normalizeISBN(b.ISBN)
+				covered.isbn = keep
+			case i == m.title:
+				keep = marcTitleOf(&field) == b.Title
+				covered.title = keep
+			case i == m.publication:
+				keep = marcYearOf(&field) == b.PublishedAt.Year()
+				covered.publication = keep
+			case i == m.summary:
+				keep = marcSummaryOf(&field) == b.Description
+				covered.summary = keep
+			case isAuthor:
+				keep = k < len(authors) && marcNameOf(&field) == authors[k]
+				if keep {
+					covered.authors[k] = true
+				}
+			}
+			if keep {
+				record.Fields = append(record.Fields, field)
+			}
+		}
+	} else {
+		record.Fields = append(record.Fields,
+			marc.Field{Tag: "001", Value: b.ID.String()},
+			marc.Field{Tag: "008", Value: b.marcFixedData()},
+		)
+	}
+
+	var generated []marc.Field
+	if !covered.isbn && b.ISBN != "" {
+		generated = append(generated, marcDataField("020", " ", " ", "a", normalizeISBN(b.ISBN)))
+	}
+	for k, name := range authors {
+		if covered.authors[k] {
+			continue
+		}
+		tag := "700"
+		if k == 0 {
+			tag = "100"
+		}
+		generated = append(generated, marcDataField(tag, marcNameIndicator(name), " ", "a", name))
+	}
+	if !covered.title {
+		ind1 := "0"
+		if len(authors) > 0 {
+			ind1 = "1"
+		}
+		field := marcDataField("245", ind1, "0", "a", b.Title)
+		if main, sub, ok := strings.Cut(b.Title, ": "); ok {
+			field = marcDataField("245", ind1, "0", "a", main+" :", "b", sub)
+		}
+		generated = append(generated, field)
+	}
+	if !covered.publication && !b.PublishedAt.IsZero() {
+		generated = append(generated, marcDataField("264", " ", "1", "c", strconv.Itoa(b.PublishedAt.Year())))
+	}
+	if !covered.summary && b.Description != "" {
+		generated = append(generated, marcDataField("520", " ", " ", "a", b.Description))
+	}
+	if b.CoverImage != "" && !hasMARCLink(record, b.CoverImage) {
+		generated = append(generated, marcDataField("856", "4", "2", "3", "Cover image", "u", b.CoverImage))
+	}
+
+	record.Fields = insertMARCFields(record.Fields, generated)
+	return record
+}
+
+// marcFixedData builds the 008 field of a generated record: entry date,
+// single known publication date, unknown place and language
+func (b *BookResponse) marcFixedData() string {
+	year := "    "
+	if !b.PublishedAt.IsZero() {
+		year = fmt.Sprintf("%04d", b.PublishedAt.Year())
+	}
+	return b.CreatedAt.Format("060102") + "s" + year + "    " + "xx " + strings.Repeat(" ", 17) + "und" + " " + "d"
+}
+
+// insertMARCFields adds fields in tag order, after any existing field with the same tag
+func insertMARCFields(fields, added []marc.Field) []marc.Field {
+	sort.SliceStable(added, func(i, j int) bool { return added[i].Tag < added[j].Tag })
+	for _, field := range added {
+		at := len(fields)
+		for i := range fields {
+			if fields[i].Tag > field.Tag {
+				at = i
+				break
+			}
+		}
+		fields = append(fields[:at], append([]marc.Field{field}, fields[at:]...)...)
+	}
+	return fields
+}
+
+func marcDataField(tag, ind1, ind2 string, subfields ...string) marc.Field {
+	field := marc.Field{Tag: tag, Ind1: ind1, Ind2: ind2}
+	for i := 0; i+1 < len(subfields); i += 2 {
+		field.Subfields = append(field.Subfields, marc.Subfield{Code: subfields[i], Value: subfields[i+1]})
+	}
+	return field
+}
+
+// marcNameIndicator is 1 for names in surname-first form and 0 otherwise
+func marcNameIndicator(name string) string {
+	if strings.Contains(name, ", ") {
+		return "1"
+	}
+	return "0"
+}
+
+func hasMARCLink(record *marc.Record, url string) bool {
+	for i := range record.Fields {
+		if record.Fields[i].Tag == "856" && record.Fields[i].Subfield("u") == url {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitAuthors splits an author column holding several names separated by semicolons
+func SplitAuthors(author string) []string {
+	var names []string
+	for _, name := range strings.Split(author, ";") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// trimISBD removes the ISBD punctuation that separates MARC subfields
+func trimISBD(value string) string {
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(value), " /:;,=."))
+}
+
+func normalizeISBN(isbn string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+}
+
+// marcISBNOf reads the ISBN of an 020 field, without qualifiers such as "(pbk.)"
+func marcISBNOf(field *marc.Field) string {
+	isbn := normalizeISBN(marcISBN.FindString(strings.TrimSpace(field.Subfield("a"))))
+	if len(isbn) != 10 && len(isbn) != 13 {
+		return ""
+	}
+	return isbn
+}
+
+func marcTitleOf(field *marc.Field) string {
+	title := trimISBD(field.Subfield("a"))
+	if subtitle := trimISBD(field.Subfield("b")); subtitle != "" {
+		title += ": " + subtitle
+	}
+	return title
+}
+
+func marcNameOf(field *marc.Field) string {
+	return trimISBD(field.Subfield("a"))
+}
+
+func marcYearOf(field *marc.Field) int {
+	year, _ := strconv.Atoi(marcYear.FindString(field.Subfield("c")))
+	return year
+}
+
+func marcSummaryOf(field *marc.Field) string {
+	return strings.TrimSpace(field.Subfield("a"))
+}
+
+// marcPriceOf reads the price from a 365 trade price field, or else from
+// the terms of availability of an 020 field. The currency is empty when
+// the record does not say.
+func marcPriceOf(record *marc.Record) (decimal.Decimal, string) {
+	for i := range record.Fields {
+		field := &record.Fields[i]
+		if field.Tag != "365" || field.Subfield("b") == "" {
+			continue
+		}
+		amount, _ := decimal.NewFromString(strings.ReplaceAll(marcAmount.FindString(field.Subfield("b")), ",", "."))
+		return amount, strings.ToUpper(strings.TrimSpace(field.Subfield("c")))
+	}
+
+	for i := range record.Fields {
+		field := &record.Fields[i]
+		terms := strings.TrimSpace(field.Subfield("c"))
+		if field.Tag != "020" || terms == "" {
+			continue
+		}
+		amount, _ := decimal.NewFromString(strings.ReplaceAll(marcAmount.FindString(terms), ",", "."))
+		currency := ""
+		switch {
+		case strings.HasPrefix(terms, "£"):
+			currency = "GBP"
+		case strings.HasPrefix(terms, "€"):
+			currency = "EUR"
+		case len(terms) > 3 && isUpperASCII(terms[:3]):
+			currency = terms[:3]
+		}
+		return amount, currency
+	}
+
+	return decimal.Zero, ""
+}
+
+func isUpperASCII(s string) bool {
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// UnmappedMARCTags returns the tags of the record's fields whose data has
+// no book column and is only kept in Extra
+func UnmappedMARCTags(record *marc.Record) []string {
+	m := mapMARC(record)
+	var tags []string
+	for i := range record.Fields {
+		if !m.mapped(i) {
+			tags = append(tags, record.Fields[i].Tag)
+		}
+	}
+	return tags
+}
+
+// mapped reports whether the field at index i is one of the mapped fields
+func (m marcMapping) mapped(i int) bool {
+	if i == m.isbn || i == m.title || i == m.publication || i == m.summary {
+		return true
+	}
+	for _, author := range m.authors {
+		if i == author {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"book_system/internal/baselib/marc"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCreateBookRequestFromMARC(t *testing.T) {
+	field := func(tag, ind2 string, subfields ...string) marc.Field {
+		return marcDataField(tag, " ", ind2, subfields...)
+	}
+
+	tests := []struct {
+		name         string
+		fields       []marc.Field
+		wantISBN     string
+		wantTitle    string
+		wantAuthor   string
+		wantYear     int
+		wantPrice    string
+		wantCurrency string
+	}{
+		{
+			name: "mapped fields",
+			fields: []marc.Field{
+				{Tag: "008", Value: "850101s1965    xx            000 1 eng d"},
+				field("020", " ", "a", "978-0-306-40615-7 (pbk.)", "c", "£7.99"),
+				field("700", " ", "a", "Doe, Jane,"),
+				field("100", " ", "a", "Herbert, Frank."),
+				field("245", "0", "a", "Dune /", "b", "a novel."),
+				field("264", "4", "c", "©1964"),
+				field("264", "1", "c", "[1966]"),
+			},
+			wantISBN:     "9780306406157",
+			wantTitle:    "Dune: a novel",
+			wantAuthor:   "Herbert, Frank; Doe, Jane",
+			wantYear:     1966,
+			wantPrice:    "7.99",
+			wantCurrency: "GBP",
+		},
+		{
+			name: "trade price over terms of availability",
+			fields: []marc.Field{
+				field("020", " ", "a", "0306406152", "c", "USD 12.00"),
+				field("365", " ", "b", "12,345", "c", "tnd"),
+			},
+			wantISBN:  "0306406152",
+			wantPrice: "12.345",
+		},
+		{
+			name: "terms with a currency code",
+			fields: []marc.Field{
+				field("020", " ", "c", "USD 12.50"),
+			},
+			wantPrice:    "12.5",
+			wantCurrency: "USD",
+		},
+		{
+			name: "year from the fixed data and no price",
+			fields: []marc.Field{
+				{Tag: "008", Value: "850101s1965    xx            000 1 eng d"},
+				field("020", " ", "a", "123"),
+			},
+			wantYear:  1965,
+			wantPrice: "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := CreateBookRequestFromMARC(&marc.Record{Leader: marcLeader, Fields: tt.fields}, "TND")
+			if err != nil {
+				t.Fatalf("CreateBookRequestFromMARC() error = %v", err)
+			}
+			if req.ISBN != tt.wantISBN || req.Title != tt.wantTitle || req.Author != tt.wantAuthor {
+				t.Errorf("ISBN, Title, Author = %q, %q, %q, want %q, %q, %q", req.ISBN, req.Title, req.Author, tt.wantISBN, tt.wantTitle, tt.wantAuthor)
+			}
+			var wantDate time.Time
+			if tt.wantYear > 0 {
+				wantDate = time.Date(tt.wantYear, time.January, 1, 0, 0, 0, 0, time.UTC)
+			}
+			if !req.PublishedAt.Equal(wantDate) {
+				t.Errorf("PublishedAt = %v, want %v", req.PublishedAt, wantDate)
+			}
+			if !req.Price.Equal(decimal.RequireFromString(tt.wantPrice)) || req.Currency != tt.wantCurrency {
+				t.Errorf("Price, Currency = %s %q, want %s %q", req.Price, req.Currency, tt.wantPrice, tt.wantCurrency)
+			}
+			if req.Extra == nil || req.Extra.MARC == nil || len(req.Extra.MARC.Fields) != len(tt.fields) {
+				t.Errorf("Extra does not keep the record")
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"book_system/i18n"
+	"book_system/internal/infrastructure"
+	"sort"
+)
+
+// BookTranslation holds a book's title and description in another language
+type BookTranslation struct {
+	Title       string `json:"title" validate:"required,min=1,max=255"`
+	Description string `json:"description"`
+}
+
+// Validate validates the BookTranslation
+func (t *BookTranslation) Validate() error {
+	return infrastructure.Validate.Struct(t)
+}
+
+// BookTranslations maps language codes to translations
+type BookTranslations map[string]BookTranslation
+
+// Localize shows the title and description in the language that best
+// matches an Accept-Language header, among the book's own language and its
+// translations. ContentLanguage is set to the language shown. The
+// category, when filled in, is named in the best match among its own
+// languages.
+func (b *BookResponse) Localize(acceptLanguage string) {
+	if b.Category != nil {
+		b.Category.Localize(acceptLanguage)
+	}
+
+	own := b.Language
+	if own == "" {
+		own = i18n.DefaultLanguage
+	}
+	// Map order is random, and the matcher breaks ties by position
+	translated := make([]string, 0, len(b.Translations))
+	for lang := range b.Translations {
+		translated = append(translated, lang)
+	}
+	sort.Strings(translated)
+	available := append([]string{own}, translated...)
+
+	lang, _ := i18n.Negotiate(acceptLanguage, available)
+	b.ContentLanguage = lang
+	if translation, ok := b.Translations[lang]; ok && lang != own {
+		b.Title = translation.Title
+		b.Description = translation.Description
+	}
+}
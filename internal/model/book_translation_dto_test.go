@@ -0,0 +1,62 @@
+package model
+
+import "testing"
+
+func TestBookResponseLocalize(t *testing.T) {
+	translations := BookTranslations{
+		"fr":    {Title: "Le Titre"},
+		"pt-BR": {Title: "O Título (Brasil)"},
+		"pt-PT": {Title: "O Título (Portugal)"},
+		"es-MX": {Title: "El Título (México)"},
+		"es-AR": {Title: "El Título (Argentina)"},
+	}
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		wantTitle      string
+		wantLanguage   string
+	}{
+		{name: "own language", acceptLanguage: "en", wantTitle: "Title", wantLanguage: "en"},
+		{name: "exact translation", acceptLanguage: "fr-FR, en;q=0.5", wantTitle: "Le Titre", wantLanguage: "fr"},
+		{name: "nothing acceptable", acceptLanguage: "de", wantTitle: "Title", wantLanguage: "en"},
+		{name: "no header", wantTitle: "Title", wantLanguage: "en"},
+		{name: "base language tie goes to the first variant", acceptLanguage: "es", wantTitle: "El Título (Argentina)", wantLanguage: "es-AR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Ties must not depend on map order, so every run agrees
+			for range 20 {
+				book := &BookResponse{Title: "Title", Language: "en", Translations: translations}
+				book.Localize(tt.acceptLanguage)
+				if book.Title != tt.wantTitle || book.ContentLanguage != tt.wantLanguage {
+					t.Fatalf("Localize(%q) = %q in %s, want %q in %s",
+						tt.acceptLanguage, book.Title, book.ContentLanguage, tt.wantTitle, tt.wantLanguage)
+				}
+			}
+		})
+	}
+}
+
+func TestBookResponseLocalizeCategory(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		wantName       string
+		wantLanguage   string
+	}{
+		{acceptLanguage: "vi, en;q=0.5", wantName: "Tiểu thuyết", wantLanguage: "vi"},
+		{acceptLanguage: "en", wantName: "Fiction", wantLanguage: "en"},
+		{acceptLanguage: "de", wantName: "Fiction", wantLanguage: "en"},
+	}
+
+	for _, tt := range tests {
+		category := &CategoryResponse{Name: "Fiction", Translations: CategoryTranslations{"vi": "Tiểu thuyết"}}
+		book := &BookResponse{Title: "Title", Language: "en", Category: category}
+		book.Localize(tt.acceptLanguage)
+		if category.Name != tt.wantName || category.ContentLanguage != tt.wantLanguage {
+			t.Errorf("Localize(%q) named the category %q in %s, want %q in %s",
+				tt.acceptLanguage, category.Name, category.ContentLanguage, tt.wantName, tt.wantLanguage)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// BookVersionResponse represents one entry of a book's change history
+type BookVersionResponse struct {
+	Version   int           `json:"version"`
+	Action    string        `json:"action"`
+	ActorID   string        `json:"actor_id,omitempty"`
+	Changes   []FieldChange `json:"changes"`
+	Snapshot  *BookSnapshot `json:"snapshot,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// BookHistoryResponse represents a paginated change history of a book
+type BookHistoryResponse struct {
+	Data       []*BookVersionResponse `json:"data"`
+	Pagination Pagination             `json:"pagination"`
+}
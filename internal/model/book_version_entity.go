@@ -0,0 +1,147 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Book version actions
+const (
+	BookActionCreate  = "create"
+	BookActionUpdate  = "update"
+	BookActionDelete  = "delete"
+	BookActionRestore = "restore"
+	BookActionPurge   = "purge"
+	BookActionRevert  = "revert"
+)
+
+// BookVersion is a point-in-time snapshot of a book, written on every change
+type BookVersion struct {
+	ID        uuid.UUID     `gorm:"type:uuid;primary_key;"`
+	BookID    uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_book_versions_book_version"`
+	Version   int           `gorm:"not null;uniqueIndex:idx_book_versions_book_version"`
+	Action    string        `gorm:"size:20;not null"`
+	ActorID   string        `gorm:"size:64"`
+	Snapshot  BookSnapshot  `gorm:"serializer:json;type:json;not null"`
+	Changes   []FieldChange `gorm:"serializer:json;type:json"`
+	CreatedAt time.Time     `gorm:"not null"`
+}
+
+func (BookVersion) TableName() string {
+	return "book_versions"
+}
+
+// BookSnapshot holds the editable fields of a book at one version
+type BookSnapshot struct {
+	Title        string           `json:"title"`
+	Author       string           `json:"author"`
+	Description  string           `json:"description"`
+	CoverImage   string           `json:"cover_image"`
+	Cover        *BookCover       `json:"cover"`
+	Price        decimal.Decimal  `json:"price"`
+	Currency     string           `json:"currency"`
+	ISBN         string           `json:"isbn"`
+	PublishedAt  time.Time        `json:"published_at"`
+	WorkID       *uuid.UUID       `json:"work_id"`
+	CategoryID   *uuid.UUID       `json:"category_id"`
+	Format       string           `json:"format"`
+	Language     string           `json:"language"`
+	PageCount    int              `json:"page_count"`
+	Translations BookTranslations `json:"translations"`
+	Extra        *BookExtra       `json:"extra"`
+}
+
+// FieldChange describes how one field changed between two versions
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// Snapshot captures the editable fields of the book
+func (b *Book) Snapshot() BookSnapshot {
+	return BookSnapshot{
+		Title:        b.Title,
+		Author:       b.Author,
+		Description:  b.Description,
+		CoverImage:   b.CoverImage,
+		Cover:        b.Cover,
+		Price:        b.Price,
+		Currency:     b.Currency,
+		ISBN:         b.ISBN,
+		PublishedAt:  b.PublishedAt,
+		WorkID:       b.WorkID,
+		CategoryID:   b.CategoryID,
+		Format:       b.Format,
+		Language:     b.Language,
+		PageCount:    b.PageCount,
+		Translations: b.Translations,
+		Extra:        b.Extra,
+	}
+}
+
+// ApplyTo copies the snapshot's fields onto book
+func (s BookSnapshot) ApplyTo(book *Book) {
+	book.Title = s.Title
+	book.Author = s.Author
+	book.Description = s.Description
+	book.CoverImage = s.CoverImage
+	book.Cover = s.Cover
+	book.Price = s.Price
+	book.Currency = s.Currency
+	book.ISBN = s.ISBN
+	book.PublishedAt = s.PublishedAt
+	book.WorkID = s.WorkID
+	book.CategoryID = s.CategoryID
+	book.Format = s.Format
+	book.Language = s.Language
+	book.PageCount = s.PageCount
+	book.Translations = s.Translations
+	book.Extra = s.Extra
+}
+
+// Diff lists the fields that differ from s to other, keyed by their JSON names
+func (s BookSnapshot) Diff(other BookSnapshot) []FieldChange {
+	changes := make([]FieldChange, 0)
+	from, to := reflect.ValueOf(s), reflect.ValueOf(other)
+	for i := 0; i < from.NumField(); i++ {
+		oldValue, newValue := from.Field(i).Interface(), to.Field(i).Interface()
+		if equalField(oldValue, newValue) {
+			continue
+		}
+		field := strings.Split(from.Type().Field(i).Tag.Get("json"), ",")[0]
+		changes = append(changes, FieldChange{Field: field, From: oldValue, To: newValue})
+	}
+	return changes
+}
+
+func equalField(a, b any) bool {
+	if t, ok := a.(time.Time); ok {
+		return t.Equal(b.(time.Time))
+	}
+	// 19.9 read back from the database is 19.900, the same price
+	if d, ok := a.(decimal.Decimal); ok {
+		return d.Equal(b.(decimal.Decimal))
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// ToDTO converts BookVersion entity to its DTO, with or without the snapshot
+func (v *BookVersion) ToDTO(withSnapshot bool) *BookVersionResponse {
+	dto := &BookVersionResponse{
+		Version:   v.Version,
+		Action:    v.Action,
+		ActorID:   v.ActorID,
+		Changes:   v.Changes,
+		CreatedAt: v.CreatedAt,
+	}
+	if withSnapshot {
+		snapshot := v.Snapshot
+		dto.Snapshot = &snapshot
+	}
+	return dto
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"book_system/internal/infrastructure"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// MaxCartQuantity is the most copies of one book a cart can hold
+const MaxCartQuantity = 100
+
+// CartItemRequest represents a book to add to the cart
+type CartItemRequest struct {
+	BookID   uuid.UUID `json:"book_id" validate:"required"`
+	Quantity int       `json:"quantity" validate:"required,gt=0,max=100"`
+}
+
+// Validate validates the CartItemRequest
+func (r *CartItemRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
+
+// CartQuantityRequest sets the quantity of a book in the cart; 0 removes it
+type CartQuantityRequest struct {
+	Quantity int `json:"quantity" validate:"min=0,max=100"`
+}
+
+// Validate validates the CartQuantityRequest
+func (r *CartQuantityRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
+
+// CartItemResponse is a line of the cart at the book's effective price.
+// Available is false when the book has fewer copies in stock than the
+// line asks for.
+type CartItemResponse struct {
+	Book      *BookResponse   `json:"book"`
+	Quantity  int             `json:"quantity"`
+	ListPrice decimal.Decimal `json:"list_price" swaggertype:"number"`
+	UnitPrice decimal.Decimal `json:"unit_price" swaggertype:"number"`
+	LineTotal decimal.Decimal `json:"line_total" swaggertype:"number"`
+	Available bool            `json:"available"`
+}
+
+// CartResponse represents a user's shopping cart. Coupon explains the
+// Discount a coupon takes off Subtotal.
+type CartResponse struct {
+	Items     []*CartItemResponse `json:"items"`
+	ItemCount int                 `json:"item_count"`
+	Subtotal  decimal.Decimal     `json:"subtotal" swaggertype:"number"`
+	Discount  decimal.Decimal     `json:"discount" swaggertype:"number"`
+	Coupon    *PriceAdjustment    `json:"coupon,omitempty"`
+	Total     decimal.Decimal     `json:"total" swaggertype:"number"`
+	Currency  string              `json:"currency"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CartItem is a book in a user's shopping cart. Prices are not kept in
+// the cart; they are read from the book until checkout.
+type CartItem struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primary_key"`
+	BookID    uuid.UUID `gorm:"type:uuid;primary_key"`
+	Quantity  int       `gorm:"not null"`
+	CreatedAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+func (CartItem) TableName() string {
+	return "cart_items"
+}
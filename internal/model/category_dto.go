@@ -0,0 +1,66 @@
+package model
+
+import (
+	"book_system/i18n"
+	"book_system/internal/infrastructure"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CategoryTranslations maps language codes to the name of a category in
+// that language
+type CategoryTranslations map[string]string
+
+// CategoryResponse represents the category data sent in responses.
+// ContentLanguage is the language Name is shown in once Localize has
+// picked one.
+type CategoryResponse struct {
+	ID              uuid.UUID            `json:"id"`
+	Name            string               `json:"name"`
+	ContentLanguage string               `json:"content_language,omitempty"`
+	Translations    CategoryTranslations `json:"translations,omitempty"`
+	CreatedAt       time.Time            `json:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+}
+
+// Localize shows the name in the language that best matches an
+// Accept-Language header, among the default language and the
+// translations. ContentLanguage is set to the language shown.
+func (c *CategoryResponse) Localize(acceptLanguage string) {
+	// Map order is random, and the matcher breaks ties by position
+	translated := make([]string, 0, len(c.Translations))
+	for lang := range c.Translations {
+		if lang != i18n.DefaultLanguage {
+			translated = append(translated, lang)
+		}
+	}
+	sort.Strings(translated)
+	available := append([]string{i18n.DefaultLanguage}, translated...)
+
+	lang, _ := i18n.Negotiate(acceptLanguage, available)
+	c.ContentLanguage = lang
+	if name, ok := c.Translations[lang]; ok {
+		c.Name = name
+	}
+}
+
+// CategoryRequest represents the editable fields of a category, used both
+// to create and to replace one. Name is in the default language;
+// Translations are keyed by the other languages of the i18n bundle.
+type CategoryRequest struct {
+	Name         string               `json:"name" validate:"required,min=1,max=100"`
+	Translations CategoryTranslations `json:"translations,omitempty" validate:"omitempty,dive,keys,required,endkeys,required,max=100"`
+}
+
+// Validate validates the CategoryRequest
+func (r *CategoryRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
+
+// CategoryListResponse represents a paginated list of categories
+type CategoryListResponse struct {
+	Data       []*CategoryResponse `json:"data"`
+	Pagination Pagination          `json:"pagination"`
+}
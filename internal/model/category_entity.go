@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Category is a subject books are filed under, such as fiction or
+// history. Name is in the default language of the i18n bundle and
+// Translations hold it in the others.
+type Category struct {
+	ID           uuid.UUID            `gorm:"type:uuid;primary_key"`
+	Name         string               `gorm:"size:100;not null;uniqueIndex"`
+	Translations CategoryTranslations `gorm:"serializer:json;type:json"`
+	CreatedAt    time.Time            `gorm:"not null"`
+	UpdatedAt    time.Time            `gorm:"not null"`
+}
+
+func (Category) TableName() string {
+	return "categories"
+}
+
+// ToDTO converts Category entity to Category DTO
+func (c *Category) ToDTO() *CategoryResponse {
+	return &CategoryResponse{
+		ID:           c.ID,
+		Name:         c.Name,
+		Translations: c.Translations,
+		CreatedAt:    c.CreatedAt,
+		UpdatedAt:    c.UpdatedAt,
+	}
+}
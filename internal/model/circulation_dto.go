@@ -0,0 +1,86 @@
+package model
+
+import (
+	"book_system/internal/infrastructure"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CopyResponse represents the copy data sent in responses
+type CopyResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	BookID     uuid.UUID  `json:"book_id"`
+	Barcode    string     `json:"barcode"`
+	LocationID *uuid.UUID `json:"location_id,omitempty"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CopyRequest represents the editable fields of a copy, used both to add
+// one and to replace one. Status defaults to available; copies on loan
+// only change status through checkout and checkin.
+type CopyRequest struct {
+	Barcode    string     `json:"barcode" validate:"required,printascii,max=50"`
+	LocationID *uuid.UUID `json:"location_id,omitempty"`
+	Status     string     `json:"status" validate:"omitempty,oneof=available lost withdrawn"`
+}
+
+// Validate validates the CopyRequest
+func (r *CopyRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
+
+// CopyListResponse represents a paginated list of copies
+type CopyListResponse struct {
+	Data       []*CopyResponse `json:"data"`
+	Pagination Pagination      `json:"pagination"`
+}
+
+// LoanResponse represents the loan data sent in responses
+type LoanResponse struct {
+	ID         uuid.UUID       `json:"id"`
+	CopyID     uuid.UUID       `json:"copy_id"`
+	BookID     uuid.UUID       `json:"book_id"`
+	UserID     uuid.UUID       `json:"user_id"`
+	Barcode    string          `json:"barcode"`
+	Title      string          `json:"title"`
+	Status     string          `json:"status"`
+	Overdue    bool            `json:"overdue"`
+	LoanedAt   time.Time       `json:"loaned_at"`
+	DueAt      time.Time       `json:"due_at"`
+	ReturnedAt *time.Time      `json:"returned_at,omitempty"`
+	Renewals   int             `json:"renewals"`
+	Fine       decimal.Decimal `json:"fine" swaggertype:"number"`
+}
+
+// LoanListResponse represents a paginated list of loans
+type LoanListResponse struct {
+	Data       []*LoanResponse `json:"data"`
+	Pagination Pagination      `json:"pagination"`
+}
+
+// LoanCheckoutRequest represents lending a copy to a member at the desk
+type LoanCheckoutRequest struct {
+	Barcode string    `json:"barcode" validate:"required,printascii,max=50"`
+	UserID  uuid.UUID `json:"user_id" validate:"required"`
+}
+
+// Validate validates the LoanCheckoutRequest
+func (r *LoanCheckoutRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
+
+// LoanCheckinRequest represents a copy handed back at the desk. A copy
+// returned at another branch is shelved at LocationID from then on.
+type LoanCheckinRequest struct {
+	Barcode    string     `json:"barcode" validate:"required,printascii,max=50"`
+	LocationID *uuid.UUID `json:"location_id,omitempty"`
+}
+
+// Validate validates the LoanCheckinRequest
+func (r *LoanCheckinRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
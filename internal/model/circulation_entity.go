@@ -0,0 +1,104 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Copy states
+const (
+	CopyStatusAvailable = "available"
+	CopyStatusOnLoan    = "on_loan"
+	CopyStatusLost      = "lost"
+	CopyStatusWithdrawn = "withdrawn"
+)
+
+// Copy is a physical copy of a book that members can borrow, identified
+// by the barcode on its label. Copies are the library's holdings and are
+// counted apart from the book's Stock, which is for sale. LocationID is
+// the branch the copy is shelved at, if any.
+type Copy struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key"`
+	BookID     uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Barcode    string     `gorm:"size:50;not null;uniqueIndex"`
+	LocationID *uuid.UUID `gorm:"type:uuid;index"`
+	Status     string     `gorm:"size:20;not null;index"`
+	CreatedAt  time.Time  `gorm:"not null"`
+	UpdatedAt  time.Time  `gorm:"not null"`
+}
+
+func (Copy) TableName() string {
+	return "copies"
+}
+
+// ToDTO converts Copy entity to Copy DTO
+func (c *Copy) ToDTO() *CopyResponse {
+	return &CopyResponse{
+		ID:         c.ID,
+		BookID:     c.BookID,
+		Barcode:    c.Barcode,
+		LocationID: c.LocationID,
+		Status:     c.Status,
+		CreatedAt:  c.CreatedAt,
+		UpdatedAt:  c.UpdatedAt,
+	}
+}
+
+// Loan states
+const (
+	LoanStatusActive   = "active"
+	LoanStatusReturned = "returned"
+)
+
+// Loan is the borrowing of a copy by a member. It keeps the barcode of
+// the copy and the title of its book as they were at checkout, so the
+// member's history survives copies being relabelled or withdrawn.
+// Renewals counts how many times DueAt has been pushed back; Fine is what
+// has been charged so far for returning it late.
+type Loan struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key"`
+	CopyID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	BookID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;index"`
+	Barcode    string    `gorm:"size:50;not null"`
+	Title      string    `gorm:"size:255;not null"`
+	Status     string    `gorm:"size:20;not null;index"`
+	LoanedAt   time.Time `gorm:"not null;index"`
+	DueAt      time.Time `gorm:"not null;index"`
+	ReturnedAt *time.Time
+	Renewals   int             `gorm:"not null;default:0"`
+	Fine       decimal.Decimal `gorm:"type:decimal(16,3);not null;default:0"`
+	CreatedAt  time.Time       `gorm:"not null"`
+	UpdatedAt  time.Time       `gorm:"not null"`
+}
+
+func (Loan) TableName() string {
+	return "loans"
+}
+
+// Overdue reports whether the loan was still out after its due date at
+// the given time
+func (l *Loan) Overdue(at time.Time) bool {
+	return l.Status == LoanStatusActive && at.After(l.DueAt)
+}
+
+// ToDTO converts Loan entity to Loan DTO
+func (l *Loan) ToDTO() *LoanResponse {
+	return &LoanResponse{
+		ID:         l.ID,
+		CopyID:     l.CopyID,
+		BookID:     l.BookID,
+		UserID:     l.UserID,
+		Barcode:    l.Barcode,
+		Title:      l.Title,
+		Status:     l.Status,
+		Overdue:    l.Overdue(time.Now()),
+		LoanedAt:   l.LoanedAt,
+		DueAt:      l.DueAt,
+		ReturnedAt: l.ReturnedAt,
+		Renewals:   l.Renewals,
+		Fine:       l.Fine,
+	}
+}
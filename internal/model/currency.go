@@ -0,0 +1,41 @@
+package model
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// Amounts were JSON numbers before they became decimals, and clients still
+// read them as numbers
+func init() {
+	decimal.MarshalJSONWithoutQuotes = true
+}
+
+// currencyExponents lists the ISO 4217 currencies whose minor unit is not
+// a hundredth. VND, for one, has no minor unit at all.
+var currencyExponents = map[string]int32{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// CurrencyExponent returns the number of decimal places amounts in a
+// currency are kept to
+func CurrencyExponent(currency string) int32 {
+	if exponent, ok := currencyExponents[currency]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// RoundMoney rounds an amount to the minor unit of its currency, halves
+// away from zero
+func RoundMoney(amount decimal.Decimal, currency string) decimal.Decimal {
+	return amount.Round(CurrencyExponent(currency))
+}
+
+// Money is an amount in a currency
+type Money struct {
+	Amount   decimal.Decimal `json:"amount" swaggertype:"number"`
+	Currency string          `json:"currency"`
+}
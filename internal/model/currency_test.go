@@ -0,0 +1,52 @@
+package model
+
+import "testing"
+
+func TestRoundMoney(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     string
+	}{
+		{amount: "19.995", currency: "EUR", want: "20"},
+		{amount: "19.994", currency: "EUR", want: "19.99"},
+		{amount: "-0.005", currency: "USD", want: "-0.01"},
+		{amount: "12.3456", currency: "TND", want: "12.346"},
+		{amount: "1234.5", currency: "JPY", want: "1235"},
+		{amount: "0.125", currency: "", want: "0.13"},
+	}
+
+	for _, tt := range tests {
+		if got := RoundMoney(dec(tt.amount), tt.currency); !got.Equal(dec(tt.want)) {
+			t.Errorf("RoundMoney(%s, %q) = %s, want %s", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestExchangeRatesConvert(t *testing.T) {
+	eurUSD := &ExchangeRate{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: dec("1.25")}
+	rates := NewExchangeRates([]*ExchangeRate{
+		{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: dec("1.1")},
+		eurUSD,
+	})
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     string
+		wantRate *ExchangeRate
+		wantOK   bool
+	}{
+		{name: "same currency", from: "EUR", to: "EUR", want: "10", wantOK: true},
+		{name: "direct rate, the later one", from: "EUR", to: "USD", want: "12.5", wantRate: eurUSD, wantOK: true},
+		{name: "inverse rate", from: "USD", to: "EUR", want: "8", wantRate: eurUSD, wantOK: true},
+		{name: "no rate", from: "EUR", to: "GBP", want: "0", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, rate, ok := rates.Convert(dec("10"), tt.from, tt.to)
+		if ok != tt.wantOK || rate != tt.wantRate || !got.Equal(dec(tt.want)) {
+			t.Errorf("%s: Convert() = %s, %v, %v, want %s, %v, %v", tt.name, got, rate, ok, tt.want, tt.wantRate, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"book_system/internal/infrastructure"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// MaxExchangeRateBatch is the most rates one request or import may record
+const MaxExchangeRateBatch = 500
+
+// ExchangeRateRequest records what one unit of BaseCurrency is worth in
+// QuoteCurrency. EffectiveAt defaults to now.
+type ExchangeRateRequest struct {
+	BaseCurrency  string          `json:"base_currency" validate:"required,iso4217"`
+	QuoteCurrency string          `json:"quote_currency" validate:"required,iso4217"`
+	Rate          decimal.Decimal `json:"rate" swaggertype:"number"`
+	EffectiveAt   *time.Time      `json:"effective_at,omitempty"`
+}
+
+// Validate validates the ExchangeRateRequest
+func (r *ExchangeRateRequest) Validate() error {
+	if err := infrastructure.Validate.Struct(r); err != nil {
+		return err
+	}
+	if r.BaseCurrency == r.QuoteCurrency {
+		return errors.New("base_currency and quote_currency must differ")
+	}
+	if !r.Rate.IsPositive() {
+		return errors.New("rate must be positive")
+	}
+	return nil
+}
+
+// ExchangeRateBatchRequest records several exchange rates at once
+type ExchangeRateBatchRequest struct {
+	Rates []*ExchangeRateRequest `json:"rates" validate:"required,min=1"`
+}
+
+// Validate validates the ExchangeRateBatchRequest
+func (r *ExchangeRateBatchRequest) Validate() error {
+	if err := infrastructure.Validate.Struct(r); err != nil {
+		return err
+	}
+	if len(r.Rates) > MaxExchangeRateBatch {
+		return fmt.Errorf("at most %d rates per request", MaxExchangeRateBatch)
+	}
+	for i, rate := range r.Rates {
+		if rate == nil {
+			return fmt.Errorf("rates[%d] is empty", i)
+		}
+		if err := rate.Validate(); err != nil {
+			return fmt.Errorf("rates[%d]: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// ExchangeRateResponse represents an exchange rate snapshot sent in responses
+type ExchangeRateResponse struct {
+	ID            uuid.UUID       `json:"id"`
+	BaseCurrency  string          `json:"base_currency"`
+	QuoteCurrency string          `json:"quote_currency"`
+	Rate          decimal.Decimal `json:"rate" swaggertype:"number"`
+	Source        string          `json:"source"`
+	EffectiveAt   time.Time       `json:"effective_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// ExchangeRateListResponse represents a paginated list of exchange rates
+type ExchangeRateListResponse struct {
+	Data       []*ExchangeRateResponse `json:"data"`
+	Pagination Pagination              `json:"pagination"`
+}
+
+// BookPriceRequest sets the list price of a book in a currency
+type BookPriceRequest struct {
+	Amount decimal.Decimal `json:"amount" swaggertype:"number"`
+}
+
+// Validate validates the BookPriceRequest for a currency
+func (r *BookPriceRequest) Validate(currency string) error {
+	if err := infrastructure.Validate.Var(currency, "required,iso4217"); err != nil {
+		return errors.New("currency must be an ISO 4217 code")
+	}
+	if !r.Amount.IsPositive() {
+		return errors.New("amount must be positive")
+	}
+	if !r.Amount.Equal(RoundMoney(r.Amount, currency)) {
+		return fmt.Errorf("%s amounts have at most %d decimal places", currency, CurrencyExponent(currency))
+	}
+	return nil
+}
+
+// BookPriceResponse represents a list price of a book in a currency
+type BookPriceResponse struct {
+	BookID    uuid.UUID       `json:"book_id"`
+	Currency  string          `json:"currency"`
+	Amount    decimal.Decimal `json:"amount" swaggertype:"number"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Exchange rate sources
+const (
+	ExchangeRateSourceManual = "manual"
+	ExchangeRateSourceImport = "import"
+)
+
+// ExchangeRate is a snapshot of what one unit of BaseCurrency is worth in
+// QuoteCurrency from EffectiveAt on. Snapshots are never changed; a newer
+// one supersedes them, and orders keep pointing at the one they used.
+type ExchangeRate struct {
+	ID            uuid.UUID       `gorm:"type:uuid;primary_key"`
+	BaseCurrency  string          `gorm:"size:3;not null;index:idx_exchange_rates_pair"`
+	QuoteCurrency string          `gorm:"size:3;not null;index:idx_exchange_rates_pair"`
+	Rate          decimal.Decimal `gorm:"type:decimal(20,10);not null"`
+	Source        string          `gorm:"size:20;not null"`
+	EffectiveAt   time.Time       `gorm:"not null;index"`
+	CreatedAt     time.Time       `gorm:"not null"`
+}
+
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
+
+// ToDTO converts ExchangeRate entity to ExchangeRate DTO
+func (r *ExchangeRate) ToDTO() *ExchangeRateResponse {
+	return &ExchangeRateResponse{
+		ID:            r.ID,
+		BaseCurrency:  r.BaseCurrency,
+		QuoteCurrency: r.QuoteCurrency,
+		Rate:          r.Rate,
+		Source:        r.Source,
+		EffectiveAt:   r.EffectiveAt,
+		CreatedAt:     r.CreatedAt,
+	}
+}
+
+// ExchangeRates holds the rates in force by currency pair
+type ExchangeRates map[string]*ExchangeRate
+
+// NewExchangeRates indexes rates by currency pair; later rates of a pair
+// win
+func NewExchangeRates(rates []*ExchangeRate) ExchangeRates {
+	byPair := make(ExchangeRates, len(rates))
+	for _, rate := range rates {
+		byPair[rate.BaseCurrency+"/"+rate.QuoteCurrency] = rate
+	}
+	return byPair
+}
+
+// Convert converts an amount between currencies with the direct rate of
+// the pair or, failing that, the inverse of the opposite pair. It returns
+// the rate used, nil when both currencies are the same, and false when no
+// rate is known. The result is not rounded.
+func (r ExchangeRates) Convert(amount decimal.Decimal, from, to string) (decimal.Decimal, *ExchangeRate, bool) {
+	if from == to {
+		return amount, nil, true
+	}
+	if rate, ok := r[from+"/"+to]; ok {
+		return amount.Mul(rate.Rate), rate, true
+	}
+	if rate, ok := r[to+"/"+from]; ok {
+		return amount.DivRound(rate.Rate, 16), rate, true
+	}
+	return decimal.Zero, nil, false
+}
+
+// BookPrice is a list price of a book set in a currency other than its
+// own, used instead of converting its price
+type BookPrice struct {
+	BookID    uuid.UUID       `gorm:"type:uuid;primary_key"`
+	Currency  string          `gorm:"size:3;primary_key"`
+	Amount    decimal.Decimal `gorm:"type:decimal(16,3);not null"`
+	UpdatedAt time.Time       `gorm:"not null"`
+}
+
+func (BookPrice) TableName() string {
+	return "book_prices"
+}
+
+// ToDTO converts BookPrice entity to BookPrice DTO
+func (p *BookPrice) ToDTO() *BookPriceResponse {
+	return &BookPriceResponse{
+		BookID:    p.BookID,
+		Currency:  p.Currency,
+		Amount:    p.Amount,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"book_system/internal/infrastructure"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// FinePolicyRequest represents the editable fields of a fine policy, used
+// both to create and to replace one. An empty Role makes it the default
+// policy; a MaxPerItem of 0 leaves fines uncapped.
+type FinePolicyRequest struct {
+	Role       string          `json:"role" validate:"max=20"`
+	DailyRate  decimal.Decimal `json:"daily_rate" swaggertype:"number"`
+	GraceDays  int             `json:"grace_days" validate:"min=0,max=365"`
+	MaxPerItem decimal.Decimal `json:"max_per_item" swaggertype:"number"`
+}
+
+// Validate validates the FinePolicyRequest
+func (r *FinePolicyRequest) Validate() error {
+	if err := infrastructure.Validate.Struct(r); err != nil {
+		return err
+	}
+	if !r.DailyRate.IsPositive() {
+		return errors.New("daily_rate must be positive")
+	}
+	if r.MaxPerItem.IsNegative() {
+		return errors.New("max_per_item must not be negative")
+	}
+	return nil
+}
+
+// FinePolicyResponse represents the fine policy data sent in responses
+type FinePolicyResponse struct {
+	ID         uuid.UUID       `json:"id"`
+	Role       string          `json:"role"`
+	DailyRate  decimal.Decimal `json:"daily_rate" swaggertype:"number"`
+	GraceDays  int             `json:"grace_days"`
+	MaxPerItem decimal.Decimal `json:"max_per_item" swaggertype:"number"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// FinePolicyListResponse represents the list of fine policies
+type FinePolicyListResponse struct {
+	Data []*FinePolicyResponse `json:"data"`
+}
+
+// AccountEntryResponse represents a balance ledger entry sent in responses
+type AccountEntryResponse struct {
+	ID        uuid.UUID       `json:"id"`
+	LoanID    *uuid.UUID      `json:"loan_id,omitempty"`
+	Type      string          `json:"type"`
+	Amount    decimal.Decimal `json:"amount" swaggertype:"number"`
+	Note      string          `json:"note,omitempty"`
+	ActorID   string          `json:"actor_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// AccountResponse is a member's balance with a page of its ledger, newest
+// first. Blocked members owe more than the limit and cannot borrow.
+type AccountResponse struct {
+	UserID     uuid.UUID               `json:"user_id"`
+	Balance    decimal.Decimal         `json:"balance" swaggertype:"number"`
+	Currency   string                  `json:"currency"`
+	Blocked    bool                    `json:"blocked"`
+	Entries    []*AccountEntryResponse `json:"entries"`
+	Pagination Pagination              `json:"pagination"`
+}
+
+// AccountCreditRequest represents a payment or waiver taken off a
+// member's balance. A waiver may name the loan whose fine it forgives.
+type AccountCreditRequest struct {
+	Amount decimal.Decimal `json:"amount" swaggertype:"number"`
+	LoanID *uuid.UUID      `json:"loan_id,omitempty"`
+	Note   string          `json:"note" validate:"max=255"`
+}
+
+// Validate validates the AccountCreditRequest
+func (r *AccountCreditRequest) Validate() error {
+	if err := infrastructure.Validate.Struct(r); err != nil {
+		return err
+	}
+	if !r.Amount.IsPositive() {
+		return errors.New("amount must be positive")
+	}
+	return nil
+}
+
+// FineAccrual is the result of a pass charging fines on overdue loans
+type FineAccrual struct {
+	Checked int             `json:"checked"`
+	Charged int             `json:"charged"`
+	Failed  int             `json:"failed"`
+	Amount  decimal.Decimal `json:"amount" swaggertype:"number"`
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// day is the unit overdue time is charged in
+const day = 24 * time.Hour
+
+// FinePolicy sets what members of a role are charged for returning a copy
+// late: DailyRate for every full day overdue beyond the first GraceDays,
+// up to MaxPerItem per loan when it is set. The policy with an empty Role
+// applies to roles without a policy of their own. Amounts are in the
+// catalog currency.
+type FinePolicy struct {
+	ID         uuid.UUID       `gorm:"type:uuid;primary_key"`
+	Role       string          `gorm:"size:20;not null;uniqueIndex"`
+	DailyRate  decimal.Decimal `gorm:"type:decimal(16,3);not null"`
+	GraceDays  int             `gorm:"not null;default:0"`
+	MaxPerItem decimal.Decimal `gorm:"type:decimal(16,3);not null;default:0"`
+	CreatedAt  time.Time       `gorm:"not null"`
+	UpdatedAt  time.Time       `gorm:"not null"`
+}
+
+func (FinePolicy) TableName() string {
+	return "fine_policies"
+}
+
+// FineFor returns the fine due at the given time for a copy due at dueAt
+func (p *FinePolicy) FineFor(dueAt, at time.Time) decimal.Decimal {
+	days := int(at.Sub(dueAt)/day) - p.GraceDays
+	if days <= 0 {
+		return decimal.Zero
+	}
+	fine := p.DailyRate.Mul(decimal.NewFromInt(int64(days)))
+	if p.MaxPerItem.IsPositive() && fine.GreaterThan(p.MaxPerItem) {
+		return p.MaxPerItem
+	}
+	return fine
+}
+
+// ToDTO converts FinePolicy entity to FinePolicy DTO
+func (p *FinePolicy) ToDTO() *FinePolicyResponse {
+	return &FinePolicyResponse{
+		ID:         p.ID,
+		Role:       p.Role,
+		DailyRate:  p.DailyRate,
+		GraceDays:  p.GraceDays,
+		MaxPerItem: p.MaxPerItem,
+		CreatedAt:  p.CreatedAt,
+		UpdatedAt:  p.UpdatedAt,
+	}
+}
+
+// Account entry types
+const (
+	AccountEntryFine    = "fine"
+	AccountEntryPayment = "payment"
+	AccountEntryWaiver  = "waiver"
+)
+
+// AccountEntry is a line of a member's balance ledger. Fines are positive
+// amounts the member owes; payments and waivers are negative. The
+// balance is the sum of the entries, in the catalog currency. LoanID is
+// the loan a fine accrued on or a waiver forgives, if any.
+type AccountEntry struct {
+	ID        uuid.UUID       `gorm:"type:uuid;primary_key"`
+	UserID    uuid.UUID       `gorm:"type:uuid;not null;index"`
+	LoanID    *uuid.UUID      `gorm:"type:uuid;index"`
+	Type      string          `gorm:"size:20;not null"`
+	Amount    decimal.Decimal `gorm:"type:decimal(16,3);not null"`
+	Note      string          `gorm:"size:255"`
+	ActorID   string          `gorm:"size:36"`
+	CreatedAt time.Time       `gorm:"not null;index"`
+}
+
+func (AccountEntry) TableName() string {
+	return "account_entries"
+}
+
+// ToDTO converts AccountEntry entity to AccountEntry DTO
+func (e *AccountEntry) ToDTO() *AccountEntryResponse {
+	return &AccountEntryResponse{
+		ID:        e.ID,
+		LoanID:    e.LoanID,
+		Type:      e.Type,
+		Amount:    e.Amount,
+		Note:      e.Note,
+		ActorID:   e.ActorID,
+		CreatedAt: e.CreatedAt,
+	}
+}
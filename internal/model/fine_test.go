@@ -0,0 +1,37 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestFineFor(t *testing.T) {
+	dueAt := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		policy  FinePolicy
+		overdue time.Duration
+		want    string
+	}{
+		{name: "not due yet", policy: FinePolicy{DailyRate: dec("0.5")}, overdue: -day, want: "0"},
+		{name: "less than a day", policy: FinePolicy{DailyRate: dec("0.5")}, overdue: day - time.Second, want: "0"},
+		{name: "full days only", policy: FinePolicy{DailyRate: dec("0.5")}, overdue: 3*day + 23*time.Hour, want: "1.5"},
+		{name: "within the grace days", policy: FinePolicy{DailyRate: dec("0.5"), GraceDays: 3}, overdue: 3 * day, want: "0"},
+		{name: "after the grace days", policy: FinePolicy{DailyRate: dec("0.5"), GraceDays: 3}, overdue: 5 * day, want: "1"},
+		{name: "three decimal rate", policy: FinePolicy{DailyRate: dec("0.125")}, overdue: 7 * day, want: "0.875"},
+		{name: "capped", policy: FinePolicy{DailyRate: dec("0.5"), MaxPerItem: dec("2")}, overdue: 30 * day, want: "2"},
+		{name: "under the cap", policy: FinePolicy{DailyRate: dec("0.5"), MaxPerItem: dec("2")}, overdue: 2 * day, want: "1"},
+		{name: "no cap", policy: FinePolicy{DailyRate: dec("0.5"), MaxPerItem: decimal.Zero}, overdue: 100 * day, want: "50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.FineFor(dueAt, dueAt.Add(tt.overdue)); !got.Equal(dec(tt.want)) {
+				t.Errorf("FineFor() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HoldResponse represents the hold data sent in responses. Position is
+// the place of a waiting hold in its book's queue, starting at 1.
+type HoldResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	BookID      uuid.UUID  `json:"book_id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Status      string     `json:"status"`
+	Position    int        `json:"position,omitempty"`
+	ReadyAt     *time.Time `json:"ready_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	FulfilledAt *time.Time `json:"fulfilled_at,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	ExpiredAt   *time.Time `json:"expired_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// HoldListResponse represents a paginated list of holds
+type HoldListResponse struct {
+	Data       []*HoldResponse `json:"data"`
+	Pagination Pagination      `json:"pagination"`
+}
+
+// HoldExpiry is the result of a pass over the ready holds past their
+// pickup window
+type HoldExpiry struct {
+	Expired  int `json:"expired"`
+	Assigned int `json:"assigned"`
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Hold states
+const (
+	HoldStatusWaiting   = "waiting"
+	HoldStatusReady     = "ready"
+	HoldStatusFulfilled = "fulfilled"
+	HoldStatusCancelled = "cancelled"
+	HoldStatusExpired   = "expired"
+)
+
+// Hold is a user's place in the queue for a copy of a book that is out of
+// stock. Holds are served first come, first served: when stock comes in
+// the oldest waiting hold becomes ready and a copy is set aside for its
+// user until ExpiresAt. The hold is fulfilled when the user checks the
+// book out, and expires otherwise, passing the copy to the next hold.
+type Hold struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key"`
+	BookID      uuid.UUID `gorm:"type:uuid;not null;index:idx_holds_queue,priority:1"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index"`
+	Status      string    `gorm:"size:20;not null;index:idx_holds_queue,priority:2"`
+	ReadyAt     *time.Time
+	ExpiresAt   *time.Time `gorm:"index"`
+	FulfilledAt *time.Time
+	CancelledAt *time.Time
+	ExpiredAt   *time.Time
+	CreatedAt   time.Time `gorm:"not null;index:idx_holds_queue,priority:3"`
+	UpdatedAt   time.Time `gorm:"not null"`
+}
+
+func (Hold) TableName() string {
+	return "holds"
+}
+
+// Active reports whether the hold is still waiting or ready
+func (h *Hold) Active() bool {
+	return h.Status == HoldStatusWaiting || h.Status == HoldStatusReady
+}
+
+// SetStatus moves the hold to status and stamps the time it did
+func (h *Hold) SetStatus(status string, at time.Time) {
+	h.Status = status
+	h.UpdatedAt = at
+	switch status {
+	case HoldStatusFulfilled:
+		h.FulfilledAt = &at
+	case HoldStatusCancelled:
+		h.CancelledAt = &at
+	case HoldStatusExpired:
+		h.ExpiredAt = &at
+	}
+}
+
+// ToDTO converts Hold entity to Hold DTO
+func (h *Hold) ToDTO() *HoldResponse {
+	return &HoldResponse{
+		ID:          h.ID,
+		BookID:      h.BookID,
+		UserID:      h.UserID,
+		Status:      h.Status,
+		ReadyAt:     h.ReadyAt,
+		ExpiresAt:   h.ExpiresAt,
+		FulfilledAt: h.FulfilledAt,
+		CancelledAt: h.CancelledAt,
+		ExpiredAt:   h.ExpiredAt,
+		CreatedAt:   h.CreatedAt,
+	}
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobResponse represents the status of a background job
+type JobResponse struct {
+	ID         uuid.UUID         `json:"id"`
+	Type       string            `json:"type"`
+	Status     string            `json:"status"`
+	Params     map[string]string `json:"params,omitempty"`
+	Total      int               `json:"total"`
+	Succeeded  int               `json:"succeeded"`
+	Failed     int               `json:"failed"`
+	ResultURL  string            `json:"result_url,omitempty"`
+	Unmapped   map[string]int    `json:"unmapped,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	StartedAt  *time.Time        `json:"started_at,omitempty"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
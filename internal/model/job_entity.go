@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job types
+const (
+	JobTypeBookImport = "book_import"
+	JobTypeBookExport = "book_export"
+)
+
+// Job statuses
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+)
+
+// Job tracks a long-running background task such as a catalog import.
+// Unmapped counts, by path, the source fields an import could not map.
+type Job struct {
+	ID           uuid.UUID         `gorm:"type:uuid;primary_key;"`
+	Type         string            `gorm:"size:50;not null;index"`
+	Status       string            `gorm:"size:20;not null;index"`
+	ActorID      string            `gorm:"size:64"`
+	Params       map[string]string `gorm:"serializer:json;type:json"`
+	Total        int               `gorm:"not null;default:0"`
+	Succeeded    int               `gorm:"not null;default:0"`
+	Failed       int               `gorm:"not null;default:0"`
+	ResultObject string            `gorm:"size:512"`
+	Unmapped     map[string]int    `gorm:"serializer:json;type:json"`
+	Error        string            `gorm:"type:text"`
+	StartedAt    *time.Time
+	FinishedAt   *time.Time
+	CreatedAt    time.Time `gorm:"not null"`
+	UpdatedAt    time.Time `gorm:"not null"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// ToDTO converts Job entity to Job DTO. resultURL is a download link for
+// ResultObject, if the caller generated one.
+func (j *Job) ToDTO(resultURL string) *JobResponse {
+	return &JobResponse{
+		ID:         j.ID,
+		Type:       j.Type,
+		Status:     j.Status,
+		Params:     j.Params,
+		Total:      j.Total,
+		Succeeded:  j.Succeeded,
+		Failed:     j.Failed,
+		ResultURL:  resultURL,
+		Unmapped:   j.Unmapped,
+		Error:      j.Error,
+		StartedAt:  j.StartedAt,
+		FinishedAt: j.FinishedAt,
+		CreatedAt:  j.CreatedAt,
+	}
+}
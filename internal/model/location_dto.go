@@ -0,0 +1,104 @@
+package model
+
+import (
+	"book_system/internal/infrastructure"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LocationResponse represents the location data sent in responses
+type LocationResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Address   string    `json:"address,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LocationRequest represents the editable fields of a location, used both
+// to create and to replace one
+type LocationRequest struct {
+	Code    string `json:"code" validate:"required,alphanum,max=20"`
+	Name    string `json:"name" validate:"required,min=1,max=255"`
+	Type    string `json:"type" validate:"required,oneof=store warehouse"`
+	Address string `json:"address" validate:"max=512"`
+}
+
+// Validate validates the LocationRequest
+func (r *LocationRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
+
+// LocationListResponse represents a paginated list of locations
+type LocationListResponse struct {
+	Data       []*LocationResponse `json:"data"`
+	Pagination Pagination          `json:"pagination"`
+}
+
+// LocationStock is the stock of a book at one location
+type LocationStock struct {
+	Location *LocationResponse `json:"location"`
+	Quantity int               `json:"quantity"`
+}
+
+// BookStockResponse breaks a book's stock down by location. Unallocated
+// is the part of Stock not assigned to any location.
+type BookStockResponse struct {
+	BookID      uuid.UUID        `json:"book_id"`
+	Stock       int              `json:"stock"`
+	Unallocated int              `json:"unallocated"`
+	Locations   []*LocationStock `json:"locations"`
+}
+
+// LocationBookStock is the stock of one book at a location
+type LocationBookStock struct {
+	Book     *BookResponse `json:"book"`
+	Quantity int           `json:"quantity"`
+}
+
+// LocationStockListResponse represents a paginated list of the books stocked at a location
+type LocationStockListResponse struct {
+	Data       []*LocationBookStock `json:"data"`
+	Pagination Pagination           `json:"pagination"`
+}
+
+// BookAvailability lists the locations that have copies of a book in stock
+type BookAvailability struct {
+	BookID    uuid.UUID        `json:"book_id"`
+	ISBN      string           `json:"isbn"`
+	Title     string           `json:"title"`
+	Locations []*LocationStock `json:"locations"`
+}
+
+// StockTransferRequest represents a move of copies between two locations
+type StockTransferRequest struct {
+	FromLocationID uuid.UUID `json:"from_location_id" validate:"required"`
+	ToLocationID   uuid.UUID `json:"to_location_id" validate:"required"`
+	Quantity       int       `json:"quantity" validate:"required,gt=0"`
+	Reason         string    `json:"reason" validate:"max=255"`
+}
+
+// Validate validates the StockTransferRequest
+func (r *StockTransferRequest) Validate() error {
+	if err := infrastructure.Validate.Struct(r); err != nil {
+		return err
+	}
+	if r.FromLocationID == r.ToLocationID {
+		return errors.New("from_location_id and to_location_id must differ")
+	}
+	return nil
+}
+
+// StockTransferResponse is the result of a transfer: the stock left at
+// both locations and the two ledger entries recording it
+type StockTransferResponse struct {
+	TransferID uuid.UUID                `json:"transfer_id"`
+	BookID     uuid.UUID                `json:"book_id"`
+	From       *LocationStock           `json:"from"`
+	To         *LocationStock           `json:"to"`
+	Movements  []*StockMovementResponse `json:"movements"`
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Location types
+const (
+	LocationTypeStore     = "store"
+	LocationTypeWarehouse = "warehouse"
+)
+
+// Location is a store or warehouse that holds stock
+type Location struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key"`
+	Code      string    `gorm:"size:20;not null;uniqueIndex"`
+	Name      string    `gorm:"size:255;not null"`
+	Type      string    `gorm:"size:20;not null"`
+	Address   string    `gorm:"size:512"`
+	CreatedAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+func (Location) TableName() string {
+	return "locations"
+}
+
+// ToDTO converts Location entity to Location DTO
+func (l *Location) ToDTO() *LocationResponse {
+	return &LocationResponse{
+		ID:        l.ID,
+		Code:      l.Code,
+		Name:      l.Name,
+		Type:      l.Type,
+		Address:   l.Address,
+		CreatedAt: l.CreatedAt,
+		UpdatedAt: l.UpdatedAt,
+	}
+}
+
+// StockLevel is the number of copies of a book held at a location. A
+// book's Stock is the sum of its levels plus any stock not yet assigned
+// to a location.
+type StockLevel struct {
+	BookID     uuid.UUID `gorm:"type:uuid;primary_key"`
+	LocationID uuid.UUID `gorm:"type:uuid;primary_key;index"`
+	Quantity   int       `gorm:"not null;default:0"`
+	UpdatedAt  time.Time `gorm:"not null"`
+}
+
+func (StockLevel) TableName() string {
+	return "stock_levels"
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationResponse represents the notification data sent in responses
+type NotificationResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Type      string     `json:"type"`
+	Message   string     `json:"message"`
+	BookID    *uuid.UUID `json:"book_id,omitempty"`
+	Read      bool       `json:"read"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NotificationListResponse represents a paginated list of notifications
+type NotificationListResponse struct {
+	Data       []*NotificationResponse `json:"data"`
+	Pagination Pagination              `json:"pagination"`
+	Unread     int64                   `json:"unread"`
+}
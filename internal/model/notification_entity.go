@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification types
+const (
+	NotificationHoldReady   = "hold_ready"
+	NotificationHoldExpired = "hold_expired"
+	NotificationLowStock    = "low_stock"
+)
+
+// Notification is a message to a user shown in their inbox. BookID is the
+// book it is about, if any.
+type Notification struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	Type      string     `gorm:"size:50;not null"`
+	Message   string     `gorm:"size:512;not null"`
+	BookID    *uuid.UUID `gorm:"type:uuid"`
+	ReadAt    *time.Time
+	CreatedAt time.Time `gorm:"not null;index"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// ToDTO converts Notification entity to Notification DTO
+func (n *Notification) ToDTO() *NotificationResponse {
+	return &NotificationResponse{
+		ID:        n.ID,
+		Type:      n.Type,
+		Message:   n.Message,
+		BookID:    n.BookID,
+		Read:      n.ReadAt != nil,
+		ReadAt:    n.ReadAt,
+		CreatedAt: n.CreatedAt,
+	}
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"book_system/internal/infrastructure"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CheckoutRequest turns the cart into an order. LocationID picks the
+// store or warehouse that fulfils it; CouponCode takes a coupon off it.
+// Currency defaults to the user's preferred currency, then the catalog's.
+type CheckoutRequest struct {
+	ShippingAddress string     `json:"shipping_address" validate:"required,max=512"`
+	LocationID      *uuid.UUID `json:"location_id,omitempty"`
+	CouponCode      string     `json:"coupon_code,omitempty" validate:"omitempty,alphanum,max=50"`
+	Currency        string     `json:"currency,omitempty" validate:"omitempty,iso4217"`
+}
+
+// Validate validates the CheckoutRequest
+func (r *CheckoutRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
+
+// OrderStatusRequest moves an order to another state. Orders only become
+// paid or refunded through their payment, so admins cannot set either.
+type OrderStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=shipped delivered cancelled"`
+}
+
+// Validate validates the OrderStatusRequest
+func (r *OrderStatusRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
+
+// OrderResponse represents the order data sent in responses
+type OrderResponse struct {
+	ID              uuid.UUID            `json:"id"`
+	UserID          uuid.UUID            `json:"user_id"`
+	Status          string               `json:"status"`
+	LocationID      *uuid.UUID           `json:"location_id,omitempty"`
+	Currency        string               `json:"currency"`
+	Subtotal        decimal.Decimal      `json:"subtotal" swaggertype:"number"`
+	Discount        decimal.Decimal      `json:"discount" swaggertype:"number"`
+	CouponCode      string               `json:"coupon_code,omitempty"`
+	Total           decimal.Decimal      `json:"total" swaggertype:"number"`
+	ShippingAddress string               `json:"shipping_address"`
+	Items           []*OrderItemResponse `json:"items"`
+	PaidAt          *time.Time           `json:"paid_at,omitempty"`
+	ShippedAt       *time.Time           `json:"shipped_at,omitempty"`
+	DeliveredAt     *time.Time           `json:"delivered_at,omitempty"`
+	CancelledAt     *time.Time           `json:"cancelled_at,omitempty"`
+	RefundedAt      *time.Time           `json:"refunded_at,omitempty"`
+	CreatedAt       time.Time            `json:"created_at"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+}
+
+// OrderItemResponse represents an order line sent in responses
+type OrderItemResponse struct {
+	BookID         uuid.UUID       `json:"book_id"`
+	Title          string          `json:"title"`
+	ISBN           string          `json:"isbn,omitempty"`
+	ListPrice      decimal.Decimal `json:"list_price" swaggertype:"number"`
+	UnitPrice      decimal.Decimal `json:"unit_price" swaggertype:"number"`
+	Quantity       int             `json:"quantity"`
+	LineTotal      decimal.Decimal `json:"line_total" swaggertype:"number"`
+	ExchangeRateID *uuid.UUID      `json:"exchange_rate_id,omitempty"`
+}
+
+// OrderListResponse represents a paginated list of orders
+type OrderListResponse struct {
+	Data       []*OrderResponse `json:"data"`
+	Pagination Pagination       `json:"pagination"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"book_system/internal/infrastructure"
+	"testing"
+)
+
+func TestOrderStatusRequestValidation(t *testing.T) {
+	tests := []struct {
+		status  string
+		wantErr bool
+	}{
+		{status: OrderStatusShipped},
+		{status: OrderStatusDelivered},
+		{status: OrderStatusCancelled},
+		{status: OrderStatusPaid, wantErr: true},
+		{status: OrderStatusRefunded, wantErr: true},
+		{status: OrderStatusPending, wantErr: true},
+		{status: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			err := infrastructure.Validate.Struct(&OrderStatusRequest{Status: tt.status})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.status, err, tt.wantErr)
+			}
+		})
+	}
+}
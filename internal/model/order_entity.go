@@ -0,0 +1,154 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Order states
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusPaid      = "paid"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+	OrderStatusRefunded  = "refunded"
+)
+
+// orderTransitions lists the states each order state can move to
+var orderTransitions = map[string][]string{
+	OrderStatusPending:   {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:      {OrderStatusShipped, OrderStatusCancelled, OrderStatusRefunded},
+	OrderStatusShipped:   {OrderStatusDelivered},
+	OrderStatusDelivered: {OrderStatusRefunded},
+}
+
+// Order is a checked-out cart. Its stock is reserved at checkout, from
+// LocationID when set and from the unallocated stock otherwise, and put
+// back when the order is cancelled or refunded before it ships. Items
+// keep the title and effective price the books had at checkout; Discount
+// is what the coupon took off Subtotal, leaving Total. Amounts are in
+// Currency.
+type Order struct {
+	ID              uuid.UUID       `gorm:"type:uuid;primary_key"`
+	UserID          uuid.UUID       `gorm:"type:uuid;not null;index"`
+	Status          string          `gorm:"size:20;not null;index"`
+	LocationID      *uuid.UUID      `gorm:"type:uuid"`
+	Currency        string          `gorm:"size:3;not null"`
+	Subtotal        decimal.Decimal `gorm:"type:decimal(16,3);not null"`
+	Discount        decimal.Decimal `gorm:"type:decimal(16,3);not null;default:0"`
+	CouponID        *uuid.UUID      `gorm:"type:uuid;index"`
+	CouponCode      string          `gorm:"size:50"`
+	Total           decimal.Decimal `gorm:"type:decimal(16,3);not null"`
+	ShippingAddress string          `gorm:"size:512;not null"`
+	Items           []*OrderItem    `gorm:"foreignKey:OrderID"`
+	PaidAt          *time.Time
+	ShippedAt       *time.Time
+	DeliveredAt     *time.Time
+	CancelledAt     *time.Time
+	RefundedAt      *time.Time
+	CreatedAt       time.Time `gorm:"not null;index"`
+	UpdatedAt       time.Time `gorm:"not null"`
+}
+
+func (Order) TableName() string {
+	return "orders"
+}
+
+// CanTransition reports whether the order can move to status
+func (o *Order) CanTransition(status string) bool {
+	for _, next := range orderTransitions[o.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Shipped reports whether the order has left the warehouse
+func (o *Order) Shipped() bool {
+	return o.ShippedAt != nil
+}
+
+// SetStatus moves the order to status and stamps the time it did
+func (o *Order) SetStatus(status string, at time.Time) {
+	o.Status = status
+	o.UpdatedAt = at
+	switch status {
+	case OrderStatusPaid:
+		o.PaidAt = &at
+	case OrderStatusShipped:
+		o.ShippedAt = &at
+	case OrderStatusDelivered:
+		o.DeliveredAt = &at
+	case OrderStatusCancelled:
+		o.CancelledAt = &at
+	case OrderStatusRefunded:
+		o.RefundedAt = &at
+	}
+}
+
+// ToDTO converts Order entity to Order DTO
+func (o *Order) ToDTO() *OrderResponse {
+	dto := &OrderResponse{
+		ID:              o.ID,
+		UserID:          o.UserID,
+		Status:          o.Status,
+		LocationID:      o.LocationID,
+		Currency:        o.Currency,
+		Subtotal:        o.Subtotal,
+		Discount:        o.Discount,
+		CouponCode:      o.CouponCode,
+		Total:           o.Total,
+		ShippingAddress: o.ShippingAddress,
+		Items:           make([]*OrderItemResponse, len(o.Items)),
+		PaidAt:          o.PaidAt,
+		ShippedAt:       o.ShippedAt,
+		DeliveredAt:     o.DeliveredAt,
+		CancelledAt:     o.CancelledAt,
+		RefundedAt:      o.RefundedAt,
+		CreatedAt:       o.CreatedAt,
+		UpdatedAt:       o.UpdatedAt,
+	}
+	for i, item := range o.Items {
+		dto.Items[i] = item.ToDTO()
+	}
+	return dto
+}
+
+// OrderItem is a line of an order. ListPrice is the book's price before
+// price rules, UnitPrice the price it sold at, both in the order's
+// currency. ExchangeRateID is the rate snapshot the list price was
+// converted with, if it was.
+type OrderItem struct {
+	ID             uuid.UUID       `gorm:"type:uuid;primary_key"`
+	OrderID        uuid.UUID       `gorm:"type:uuid;not null;index"`
+	BookID         uuid.UUID       `gorm:"type:uuid;not null;index"`
+	Title          string          `gorm:"size:255;not null"`
+	ISBN           string          `gorm:"size:20"`
+	ListPrice      decimal.Decimal `gorm:"type:decimal(16,3);not null"`
+	UnitPrice      decimal.Decimal `gorm:"type:decimal(16,3);not null"`
+	Quantity       int             `gorm:"not null"`
+	LineTotal      decimal.Decimal `gorm:"type:decimal(16,3);not null"`
+	ExchangeRateID *uuid.UUID      `gorm:"type:uuid"`
+}
+
+func (OrderItem) TableName() string {
+	return "order_items"
+}
+
+// ToDTO converts OrderItem entity to OrderItem DTO
+func (i *OrderItem) ToDTO() *OrderItemResponse {
+	return &OrderItemResponse{
+		BookID:         i.BookID,
+		Title:          i.Title,
+		ISBN:           i.ISBN,
+		ListPrice:      i.ListPrice,
+		UnitPrice:      i.UnitPrice,
+		Quantity:       i.Quantity,
+		LineTotal:      i.LineTotal,
+		ExchangeRateID: i.ExchangeRateID,
+	}
+}
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// PaymentResponse represents the payment data sent in responses
+type PaymentResponse struct {
+	ID            uuid.UUID       `json:"id"`
+	OrderID       uuid.UUID       `json:"order_id"`
+	Attempt       int             `json:"attempt"`
+	UserID        uuid.UUID       `json:"user_id"`
+	Amount        decimal.Decimal `json:"amount" swaggertype:"number"`
+	Currency      string          `json:"currency"`
+	BookIDs       []uuid.UUID     `json:"book_ids"`
+	Provider      string          `json:"provider"`
+	ProviderRef   string          `json:"provider_ref,omitempty"`
+	ClientSecret  string          `json:"client_secret,omitempty"`
+	Status        string          `json:"status"`
+	FailureReason string          `json:"failure_reason,omitempty"`
+	CapturedAt    *time.Time      `json:"captured_at,omitempty"`
+	RefundedAt    *time.Time      `json:"refunded_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// PaymentListResponse represents a paginated list of payments
+type PaymentListResponse struct {
+	Data       []*PaymentResponse `json:"data"`
+	Pagination Pagination         `json:"pagination"`
+}
+
+// PaymentIntent asks a provider to start collecting a payment. Reference
+// is our payment ID, echoed back in the provider's events. Requests with
+// the same IdempotencyKey start a single payment at the provider.
+type PaymentIntent struct {
+	Amount         decimal.Decimal
+	Currency       string
+	Reference      string
+	IdempotencyKey string
+}
+
+// ProviderPayment is the state of a payment at its provider
+type ProviderPayment struct {
+	Ref           string
+	ClientSecret  string
+	Status        string
+	FailureReason string
+}
+
+// PaymentWebhookEvent is a verified provider event about a payment
+type PaymentWebhookEvent struct {
+	ID      string
+	Payment ProviderPayment
+}
+
+// PaymentReconciliation reports a run of the stuck payment reconciler
+type PaymentReconciliation struct {
+	Checked  int `json:"checked"`
+	Resolved int `json:"resolved"`
+	Failed   int `json:"failed"`
+}
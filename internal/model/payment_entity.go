@@ -0,0 +1,119 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Payment states, shared with payment providers
+const (
+	PaymentStatusPending    = "pending"
+	PaymentStatusAuthorized = "authorized"
+	PaymentStatusCaptured   = "captured"
+	PaymentStatusFailed     = "failed"
+	PaymentStatusRefunded   = "refunded"
+)
+
+// paymentTransitions lists the states each payment state can move to.
+// Payments only move forward, so replayed or late provider events that
+// would move them back are ignored.
+var paymentTransitions = map[string][]string{
+	PaymentStatusPending:    {PaymentStatusAuthorized, PaymentStatusCaptured, PaymentStatusFailed},
+	PaymentStatusAuthorized: {PaymentStatusCaptured, PaymentStatusFailed},
+	PaymentStatusCaptured:   {PaymentStatusRefunded},
+}
+
+// Payment is the money taken for an order through a payment provider.
+// Attempt numbers the payments of an order from 1. ProviderRef is the
+// provider's ID of the payment intent, nil while the payment is reserved
+// and its intent not yet recorded, and BookIDs the books the order bought.
+type Payment struct {
+	ID            uuid.UUID       `gorm:"type:uuid;primary_key"`
+	OrderID       uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex:idx_payments_order_attempt"`
+	Attempt       int             `gorm:"not null;default:1;uniqueIndex:idx_payments_order_attempt"`
+	UserID        uuid.UUID       `gorm:"type:uuid;not null;index"`
+	Amount        decimal.Decimal `gorm:"type:decimal(16,3);not null"`
+	Currency      string          `gorm:"size:3;not null"`
+	BookIDs       []uuid.UUID     `gorm:"serializer:json;type:json"`
+	Provider      string          `gorm:"size:50;not null"`
+	ProviderRef   *string         `gorm:"size:100;uniqueIndex"`
+	ClientSecret  string          `gorm:"size:255"`
+	Status        string          `gorm:"size:20;not null;index"`
+	FailureReason string          `gorm:"size:255"`
+	CapturedAt    *time.Time
+	RefundedAt    *time.Time
+	CreatedAt     time.Time `gorm:"not null;index"`
+	UpdatedAt     time.Time `gorm:"not null"`
+}
+
+func (Payment) TableName() string {
+	return "payments"
+}
+
+// CanTransition reports whether the payment can move to status
+func (p *Payment) CanTransition(status string) bool {
+	for _, next := range paymentTransitions[p.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IdempotencyKey identifies the payment's intent at its provider. It is
+// derived from the order and attempt, so every request for an attempt,
+// including retries after a crash, starts the same intent.
+func (p *Payment) IdempotencyKey() string {
+	return fmt.Sprintf("order-%s-%d", p.OrderID, p.Attempt)
+}
+
+// Ref returns the provider's ID of the payment intent, empty while none is recorded
+func (p *Payment) Ref() string {
+	if p.ProviderRef == nil {
+		return ""
+	}
+	return *p.ProviderRef
+}
+
+// Active reports whether the payment may still be completed
+func (p *Payment) Active() bool {
+	return p.Status == PaymentStatusPending || p.Status == PaymentStatusAuthorized
+}
+
+// ToDTO converts Payment entity to Payment DTO. The client secret is
+// only handed out when the payment is created.
+func (p *Payment) ToDTO() *PaymentResponse {
+	return &PaymentResponse{
+		ID:            p.ID,
+		OrderID:       p.OrderID,
+		Attempt:       p.Attempt,
+		UserID:        p.UserID,
+		Amount:        p.Amount,
+		Currency:      p.Currency,
+		BookIDs:       p.BookIDs,
+		Provider:      p.Provider,
+		ProviderRef:   p.Ref(),
+		Status:        p.Status,
+		FailureReason: p.FailureReason,
+		CapturedAt:    p.CapturedAt,
+		RefundedAt:    p.RefundedAt,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+	}
+}
+
+// PaymentEvent is a provider webhook event that was processed, kept so
+// redelivered events are recognised
+type PaymentEvent struct {
+	ID         string    `gorm:"size:100;primary_key"`
+	PaymentID  uuid.UUID `gorm:"type:uuid;not null;index"`
+	Status     string    `gorm:"size:20;not null"`
+	ReceivedAt time.Time `gorm:"not null"`
+}
+
+func (PaymentEvent) TableName() string {
+	return "payment_events"
+}
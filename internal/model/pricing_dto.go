@@ -0,0 +1,189 @@
+package model
+
+import (
+	"book_system/internal/infrastructure"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// PriceRuleRequest represents the editable fields of a price rule, used
+// both to create and to replace one. Value is a price for sale_price, a
+// percentage for percent_off and an amount for amount_off; prices and
+// amounts are in Currency, the catalog currency by default.
+type PriceRuleRequest struct {
+	Name       string          `json:"name" validate:"required,max=255"`
+	Type       string          `json:"type" validate:"required,oneof=sale_price percent_off amount_off"`
+	Value      decimal.Decimal `json:"value" swaggertype:"number"`
+	Currency   string          `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	ScopeType  string          `json:"scope_type" validate:"required,oneof=all book author work category format"`
+	ScopeValue string          `json:"scope_value" validate:"required_unless=ScopeType all,max=255"`
+	StartsAt   *time.Time      `json:"starts_at,omitempty"`
+	EndsAt     *time.Time      `json:"ends_at,omitempty"`
+	Active     *bool           `json:"active,omitempty"`
+}
+
+// Validate validates the PriceRuleRequest
+func (r *PriceRuleRequest) Validate() error {
+	if err := infrastructure.Validate.Struct(r); err != nil {
+		return err
+	}
+	if err := validateAmount(r.Type, r.Value, r.Currency); err != nil {
+		return err
+	}
+	return validateWindow(r.StartsAt, r.EndsAt)
+}
+
+// PriceRuleResponse represents the price rule data sent in responses
+type PriceRuleResponse struct {
+	ID         uuid.UUID       `json:"id"`
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	Value      decimal.Decimal `json:"value" swaggertype:"number"`
+	Currency   string          `json:"currency"`
+	ScopeType  string          `json:"scope_type"`
+	ScopeValue string          `json:"scope_value,omitempty"`
+	StartsAt   *time.Time      `json:"starts_at,omitempty"`
+	EndsAt     *time.Time      `json:"ends_at,omitempty"`
+	Active     bool            `json:"active"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// PriceRuleListResponse represents a paginated list of price rules
+type PriceRuleListResponse struct {
+	Data       []*PriceRuleResponse `json:"data"`
+	Pagination Pagination           `json:"pagination"`
+}
+
+// CouponRequest represents the editable fields of a coupon, used both to
+// create and to replace one. Amounts are in Currency, the catalog currency
+// by default.
+type CouponRequest struct {
+	Code        string          `json:"code" validate:"required,alphanum,max=50"`
+	Type        string          `json:"type" validate:"required,oneof=percent_off amount_off"`
+	Value       decimal.Decimal `json:"value" swaggertype:"number"`
+	Currency    string          `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	MinSubtotal decimal.Decimal `json:"min_subtotal" swaggertype:"number"`
+	MaxUses     int             `json:"max_uses" validate:"min=0"`
+	StartsAt    *time.Time      `json:"starts_at,omitempty"`
+	EndsAt      *time.Time      `json:"ends_at,omitempty"`
+	Active      *bool           `json:"active,omitempty"`
+}
+
+// Validate validates the CouponRequest
+func (r *CouponRequest) Validate() error {
+	if err := infrastructure.Validate.Struct(r); err != nil {
+		return err
+	}
+	if err := validateAmount(r.Type, r.Value, r.Currency); err != nil {
+		return err
+	}
+	if r.MinSubtotal.IsNegative() {
+		return errors.New("min_subtotal must not be negative")
+	}
+	return validateWindow(r.StartsAt, r.EndsAt)
+}
+
+// CouponResponse represents the coupon data sent in responses
+type CouponResponse struct {
+	ID          uuid.UUID       `json:"id"`
+	Code        string          `json:"code"`
+	Type        string          `json:"type"`
+	Value       decimal.Decimal `json:"value" swaggertype:"number"`
+	Currency    string          `json:"currency"`
+	MinSubtotal decimal.Decimal `json:"min_subtotal" swaggertype:"number"`
+	MaxUses     int             `json:"max_uses"`
+	Uses        int             `json:"uses"`
+	StartsAt    *time.Time      `json:"starts_at,omitempty"`
+	EndsAt      *time.Time      `json:"ends_at,omitempty"`
+	Active      bool            `json:"active"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// CouponListResponse represents a paginated list of coupons
+type CouponListResponse struct {
+	Data       []*CouponResponse `json:"data"`
+	Pagination Pagination        `json:"pagination"`
+}
+
+// PriceAdjustment explains one step of a price calculation: the rule or
+// coupon applied, how much it took off and the price left after it
+type PriceAdjustment struct {
+	Source     string          `json:"source"`
+	ID         uuid.UUID       `json:"id"`
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	Value      decimal.Decimal `json:"value" swaggertype:"number"`
+	Amount     decimal.Decimal `json:"amount" swaggertype:"number"`
+	PriceAfter decimal.Decimal `json:"price_after" swaggertype:"number"`
+}
+
+// PriceQuote is the effective price of a book, with the adjustments that
+// led from its list price to it. ExchangeRate is the snapshot the list
+// price was converted with, if it was.
+type PriceQuote struct {
+	BookID       uuid.UUID             `json:"book_id"`
+	Currency     string                `json:"currency"`
+	ListPrice    decimal.Decimal       `json:"list_price" swaggertype:"number"`
+	ExchangeRate *ExchangeRateResponse `json:"exchange_rate,omitempty"`
+	Price        decimal.Decimal       `json:"price" swaggertype:"number"`
+	Adjustments  []*PriceAdjustment    `json:"adjustments"`
+}
+
+// CouponDiscount is what a coupon takes off a subtotal
+type CouponDiscount struct {
+	Coupon     *Coupon
+	Amount     decimal.Decimal
+	Adjustment *PriceAdjustment
+}
+
+// Discount returns what a percent_off or amount_off value takes off a
+// price in currency, rounded to its minor unit and never more than price.
+// Amounts off must already be in currency.
+func Discount(kind string, value, price decimal.Decimal, currency string) decimal.Decimal {
+	var amount decimal.Decimal
+	switch kind {
+	case PriceTypePercentOff:
+		amount = RoundMoney(price.Mul(value).Div(hundred), currency)
+	case PriceTypeAmountOff:
+		amount = value
+	}
+	if amount.GreaterThan(price) {
+		return price
+	}
+	return amount
+}
+
+// validateAmount checks the value of a price rule or coupon of a type.
+// Prices and amounts are kept to the minor unit of currency, or to cents
+// when the currency is left to its default.
+func validateAmount(kind string, value decimal.Decimal, currency string) error {
+	if !value.IsPositive() {
+		return errors.New("value must be positive")
+	}
+	if kind == PriceTypePercentOff {
+		if value.GreaterThan(hundred) {
+			return errors.New("percent_off value must be at most 100")
+		}
+		currency = ""
+	}
+	if !value.Equal(RoundMoney(value, currency)) {
+		return fmt.Errorf("value must have at most %d decimal places", CurrencyExponent(currency))
+	}
+	return nil
+}
+
+// validateWindow checks that a validity window ends after it starts
+func validateWindow(startsAt, endsAt *time.Time) error {
+	if startsAt != nil && endsAt != nil && !endsAt.After(*startsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Price rule and coupon types. A sale price replaces the list price; the
+// others take a percentage or an amount off.
+const (
+	PriceTypeSalePrice  = "sale_price"
+	PriceTypePercentOff = "percent_off"
+	PriceTypeAmountOff  = "amount_off"
+)
+
+// Price rule scopes. Rules target a single book, an author, every edition
+// of a work, the books of a category, a format, or the whole catalog.
+const (
+	PriceScopeAll      = "all"
+	PriceScopeBook     = "book"
+	PriceScopeAuthor   = "author"
+	PriceScopeWork     = "work"
+	PriceScopeCategory = "category"
+	PriceScopeFormat   = "format"
+)
+
+// PriceRule changes the price of the books in its scope while it is
+// active and within its window. A nil StartsAt or EndsAt leaves that end
+// of the window open. Sale prices and amounts off are in Currency and are
+// converted to the currency a book is priced in.
+type PriceRule struct {
+	ID         uuid.UUID       `gorm:"type:uuid;primary_key"`
+	Name       string          `gorm:"size:255;not null"`
+	Type       string          `gorm:"size:20;not null"`
+	Value      decimal.Decimal `gorm:"type:decimal(16,3);not null"`
+	Currency   string          `gorm:"size:3;not null"`
+	ScopeType  string          `gorm:"size:20;not null"`
+	ScopeValue string          `gorm:"size:255"`
+	StartsAt   *time.Time      `gorm:"index"`
+	EndsAt     *time.Time      `gorm:"index"`
+	Active     bool            `gorm:"not null;default:true"`
+	CreatedAt  time.Time       `gorm:"not null"`
+	UpdatedAt  time.Time       `gorm:"not null"`
+}
+
+func (PriceRule) TableName() string {
+	return "price_rules"
+}
+
+// Matches reports whether a book is in the rule's scope
+func (r *PriceRule) Matches(book *Book) bool {
+	switch r.ScopeType {
+	case PriceScopeAll:
+		return true
+	case PriceScopeBook:
+		return r.ScopeValue == book.ID.String()
+	case PriceScopeAuthor:
+		return strings.EqualFold(r.ScopeValue, book.Author)
+	case PriceScopeWork:
+		return book.WorkID != nil && r.ScopeValue == book.WorkID.String()
+	case PriceScopeCategory:
+		return book.CategoryID != nil && r.ScopeValue == book.CategoryID.String()
+	case PriceScopeFormat:
+		return r.ScopeValue == book.Format
+	}
+	return false
+}
+
+// ToDTO converts PriceRule entity to PriceRule DTO
+func (r *PriceRule) ToDTO() *PriceRuleResponse {
+	return &PriceRuleResponse{
+		ID:         r.ID,
+		Name:       r.Name,
+		Type:       r.Type,
+		Value:      r.Value,
+		Currency:   r.Currency,
+		ScopeType:  r.ScopeType,
+		ScopeValue: r.ScopeValue,
+		StartsAt:   r.StartsAt,
+		EndsAt:     r.EndsAt,
+		Active:     r.Active,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}
+}
+
+// Coupon is a code that takes a percentage or an amount off an order's
+// subtotal. MaxUses of 0 means unlimited; Uses counts redemptions at
+// checkout. Amounts off and MinSubtotal are in Currency.
+type Coupon struct {
+	ID          uuid.UUID       `gorm:"type:uuid;primary_key"`
+	Code        string          `gorm:"size:50;not null;uniqueIndex"`
+	Type        string          `gorm:"size:20;not null"`
+	Value       decimal.Decimal `gorm:"type:decimal(16,3);not null"`
+	Currency    string          `gorm:"size:3;not null"`
+	MinSubtotal decimal.Decimal `gorm:"type:decimal(16,3);not null;default:0"`
+	MaxUses     int             `gorm:"not null;default:0"`
+	Uses        int             `gorm:"not null;default:0"`
+	StartsAt    *time.Time
+	EndsAt      *time.Time
+	Active      bool      `gorm:"not null;default:true"`
+	CreatedAt   time.Time `gorm:"not null"`
+	UpdatedAt   time.Time `gorm:"not null"`
+}
+
+func (Coupon) TableName() string {
+	return "coupons"
+}
+
+// ToDTO converts Coupon entity to Coupon DTO
+func (c *Coupon) ToDTO() *CouponResponse {
+	return &CouponResponse{
+		ID:          c.ID,
+		Code:        c.Code,
+		Type:        c.Type,
+		Value:       c.Value,
+		Currency:    c.Currency,
+		MinSubtotal: c.MinSubtotal,
+		MaxUses:     c.MaxUses,
+		Uses:        c.Uses,
+		StartsAt:    c.StartsAt,
+		EndsAt:      c.EndsAt,
+		Active:      c.Active,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+// InWindow reports whether at falls within a validity window whose open
+// ends are nil
+func InWindow(startsAt, endsAt *time.Time, at time.Time) bool {
+	if startsAt != nil && at.Before(*startsAt) {
+		return false
+	}
+	return endsAt == nil || at.Before(*endsAt)
+}
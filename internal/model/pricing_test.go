@@ -0,0 +1,163 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func dec(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func TestAmountsMarshalAsNumbers(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{name: "book price", value: BookResponse{Price: dec("12.50")}, want: `"price":12.5,`},
+		{name: "replaced book price", value: ReplaceBookRequest{Price: dec("9.99")}, want: `"price":9.99,`},
+		{name: "order total", value: OrderResponse{Total: dec("30")}, want: `"total":30,`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !strings.Contains(string(data), tt.want) {
+				t.Errorf("Marshal() = %s, want it to contain %s", data, tt.want)
+			}
+		})
+	}
+
+	var book BookResponse
+	if err := json.Unmarshal([]byte(`{"price":"7.25"}`), &book); err != nil || !book.Price.Equal(dec("7.25")) {
+		t.Errorf("quoted price unmarshalled to %s, %v", book.Price, err)
+	}
+}
+
+func TestDiscount(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     string
+		value    string
+		price    string
+		currency string
+		want     string
+	}{
+		{name: "percent", kind: PriceTypePercentOff, value: "10", price: "19.99", currency: "EUR", want: "2"},
+		{name: "percent rounded half up", kind: PriceTypePercentOff, value: "15", price: "0.30", currency: "EUR", want: "0.05"},
+		{name: "percent in a three decimal currency", kind: PriceTypePercentOff, value: "12.5", price: "9.999", currency: "TND", want: "1.25"},
+		{name: "percent in a currency without minor unit", kind: PriceTypePercentOff, value: "33", price: "1000", currency: "JPY", want: "330"},
+		{name: "whole price", kind: PriceTypePercentOff, value: "100", price: "7.50", currency: "EUR", want: "7.50"},
+		{name: "amount", kind: PriceTypeAmountOff, value: "5", price: "19.99", currency: "EUR", want: "5"},
+		{name: "amount capped at the price", kind: PriceTypeAmountOff, value: "25", price: "19.99", currency: "EUR", want: "19.99"},
+		{name: "sale price is not a discount", kind: PriceTypeSalePrice, value: "9.99", price: "19.99", currency: "EUR", want: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Discount(tt.kind, dec(tt.value), dec(tt.price), tt.currency); !got.Equal(dec(tt.want)) {
+				t.Errorf("Discount() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		kind     string
+		value    string
+		currency string
+		wantErr  bool
+	}{
+		{name: "price in cents", kind: PriceTypeSalePrice, value: "9.99", currency: "EUR"},
+		{name: "price in millimes", kind: PriceTypeSalePrice, value: "9.999", currency: "TND"},
+		{name: "too precise for cents", kind: PriceTypeAmountOff, value: "9.999", currency: "EUR", wantErr: true},
+		{name: "too precise for the default currency", kind: PriceTypeAmountOff, value: "0.001", currency: "", wantErr: true},
+		{name: "fractional yen", kind: PriceTypeAmountOff, value: "0.5", currency: "JPY", wantErr: true},
+		{name: "zero", kind: PriceTypeAmountOff, value: "0", currency: "EUR", wantErr: true},
+		{name: "negative", kind: PriceTypeSalePrice, value: "-1", currency: "EUR", wantErr: true},
+		{name: "percent ignores the currency", kind: PriceTypePercentOff, value: "12.5", currency: "JPY"},
+		{name: "whole price off", kind: PriceTypePercentOff, value: "100", currency: "EUR"},
+		{name: "more than the whole price", kind: PriceTypePercentOff, value: "100.01", currency: "EUR", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateAmount(tt.kind, dec(tt.value), tt.currency); (err != nil) != tt.wantErr {
+				t.Errorf("validateAmount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPriceRuleMatches(t *testing.T) {
+	workID, categoryID := uuid.New(), uuid.New()
+	book := &Book{ID: uuid.New(), Author: "Frank Herbert", WorkID: &workID, CategoryID: &categoryID, Format: "paperback"}
+
+	tests := []struct {
+		scopeType  string
+		scopeValue string
+		want       bool
+	}{
+		{scopeType: PriceScopeAll, want: true},
+		{scopeType: PriceScopeBook, scopeValue: book.ID.String(), want: true},
+		{scopeType: PriceScopeBook, scopeValue: uuid.NewString(), want: false},
+		{scopeType: PriceScopeAuthor, scopeValue: "frank herbert", want: true},
+		{scopeType: PriceScopeAuthor, scopeValue: "Brian Herbert", want: false},
+		{scopeType: PriceScopeWork, scopeValue: workID.String(), want: true},
+		{scopeType: PriceScopeFormat, scopeValue: "paperback", want: true},
+		{scopeType: PriceScopeFormat, scopeValue: "hardcover", want: false},
+		{scopeType: PriceScopeCategory, scopeValue: categoryID.String(), want: true},
+		{scopeType: PriceScopeCategory, scopeValue: uuid.NewString(), want: false},
+		{scopeType: "publisher", scopeValue: "Chilton", want: false},
+	}
+
+	for _, tt := range tests {
+		rule := &PriceRule{ScopeType: tt.scopeType, ScopeValue: tt.scopeValue}
+		if got := rule.Matches(book); got != tt.want {
+			t.Errorf("Matches() with %s %q = %v, want %v", tt.scopeType, tt.scopeValue, got, tt.want)
+		}
+	}
+
+	if (&PriceRule{ScopeType: PriceScopeWork, ScopeValue: workID.String()}).Matches(&Book{}) {
+		t.Errorf("Matches() matched the work of a book without one")
+	}
+	if (&PriceRule{ScopeType: PriceScopeCategory, ScopeValue: categoryID.String()}).Matches(&Book{}) {
+		t.Errorf("Matches() matched the category of a book without one")
+	}
+}
+
+func TestInWindow(t *testing.T) {
+	now := time.Now()
+	before, after := now.Add(-time.Hour), now.Add(time.Hour)
+
+	tests := []struct {
+		name             string
+		startsAt, endsAt *time.Time
+		want             bool
+	}{
+		{name: "open", want: true},
+		{name: "started", startsAt: &before, want: true},
+		{name: "not started", startsAt: &after, want: false},
+		{name: "not ended", endsAt: &after, want: true},
+		{name: "ended", endsAt: &before, want: false},
+		{name: "ends now", endsAt: &now, want: false},
+		{name: "starts now", startsAt: &now, endsAt: &after, want: true},
+	}
+
+	for _, tt := range tests {
+		if got := InWindow(tt.startsAt, tt.endsAt, now); got != tt.want {
+			t.Errorf("%s: InWindow() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"book_system/internal/infrastructure"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReadingListResponse represents the reading list data sent in responses.
+// ShareToken is only sent to the list's owner; Items are only sent when a
+// single list is read.
+type ReadingListResponse struct {
+	ID         uuid.UUID                  `json:"id"`
+	Name       string                     `json:"name"`
+	Kind       string                     `json:"kind"`
+	Shared     bool                       `json:"shared"`
+	ShareToken string                     `json:"share_token,omitempty"`
+	ItemCount  int                        `json:"item_count"`
+	Items      []*ReadingListItemResponse `json:"items,omitempty"`
+	CreatedAt  time.Time                  `json:"created_at"`
+	UpdatedAt  time.Time                  `json:"updated_at"`
+}
+
+// ReadingListItemResponse is a book on a reading list, with the reading
+// progress on the reading list
+type ReadingListItemResponse struct {
+	Book     *BookResponse `json:"book"`
+	Position int           `json:"position"`
+	Page     int           `json:"page,omitempty"`
+	Percent  int           `json:"percent,omitempty"`
+	AddedAt  time.Time     `json:"added_at"`
+}
+
+// ReadingListCollection represents every reading list of a user
+type ReadingListCollection struct {
+	Data []*ReadingListResponse `json:"data"`
+}
+
+// ReadingListRequest represents the name of a reading list, used both to
+// create a custom list and to rename any list
+type ReadingListRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// Validate validates the ReadingListRequest
+func (r *ReadingListRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
+
+// ReadingListItemRequest represents a book to add to a reading list
+type ReadingListItemRequest struct {
+	BookID uuid.UUID `json:"book_id" validate:"required"`
+}
+
+// Validate validates the ReadingListItemRequest
+func (r *ReadingListItemRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
+
+// ReadingListOrderRequest represents a new order for the books of a
+// reading list; it must hold every book on the list exactly once
+type ReadingListOrderRequest struct {
+	BookIDs []uuid.UUID `json:"book_ids" validate:"required,unique"`
+}
+
+// Validate validates the ReadingListOrderRequest
+func (r *ReadingListOrderRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
+
+// ReadingProgressRequest represents how far a user has read a book, as the
+// page they are on or a percentage. A page sets the percentage too when
+// the book's page count is known.
+type ReadingProgressRequest struct {
+	Page    *int `json:"page,omitempty" validate:"omitempty,min=0"`
+	Percent *int `json:"percent,omitempty" validate:"omitempty,min=0,max=100"`
+}
+
+// Validate validates the ReadingProgressRequest
+func (r *ReadingProgressRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reading list kinds. Every user has one list of each built-in kind and
+// any number of custom lists. To read, reading and finished are shelves a
+// book is on one at a time.
+const (
+	ReadingListWishlist = "wishlist"
+	ReadingListToRead   = "to_read"
+	ReadingListReading  = "reading"
+	ReadingListFinished = "finished"
+	ReadingListCustom   = "custom"
+)
+
+// DefaultReadingLists are the built-in lists every user has, by kind, with
+// the names they start with
+var DefaultReadingLists = []struct{ Kind, Name string }{
+	{ReadingListWishlist, "Wishlist"},
+	{ReadingListToRead, "To read"},
+	{ReadingListReading, "Reading"},
+	{ReadingListFinished, "Finished"},
+}
+
+// ReadingList is a named list of books a user saved. ShareToken is set
+// while the list is shared, and anyone with the link it appears in can
+// read the list.
+type ReadingList struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_reading_lists_user_name,priority:1"`
+	Name       string    `gorm:"size:100;not null;uniqueIndex:idx_reading_lists_user_name,priority:2"`
+	Kind       string    `gorm:"size:20;not null"`
+	ShareToken *string   `gorm:"size:64;uniqueIndex"`
+	CreatedAt  time.Time `gorm:"not null"`
+	UpdatedAt  time.Time `gorm:"not null"`
+}
+
+func (ReadingList) TableName() string {
+	return "reading_lists"
+}
+
+// Shelf reports whether the list is one of the to read, reading and
+// finished shelves
+func (l *ReadingList) Shelf() bool {
+	return l.Kind == ReadingListToRead || l.Kind == ReadingListReading || l.Kind == ReadingListFinished
+}
+
+// ToDTO converts ReadingList entity to ReadingList DTO. The share token is
+// only for the list's owner and left to the caller.
+func (l *ReadingList) ToDTO() *ReadingListResponse {
+	return &ReadingListResponse{
+		ID:        l.ID,
+		Name:      l.Name,
+		Kind:      l.Kind,
+		Shared:    l.ShareToken != nil,
+		CreatedAt: l.CreatedAt,
+		UpdatedAt: l.UpdatedAt,
+	}
+}
+
+// ReadingListItem is a book on a reading list. Position orders the list,
+// starting at 1. Page and Percent are how far the user has read, tracked
+// on the reading list only.
+type ReadingListItem struct {
+	ListID    uuid.UUID `gorm:"type:uuid;primary_key"`
+	BookID    uuid.UUID `gorm:"type:uuid;primary_key;index"`
+	Position  int       `gorm:"not null"`
+	Page      int       `gorm:"not null;default:0"`
+	Percent   int       `gorm:"not null;default:0"`
+	CreatedAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+func (ReadingListItem) TableName() string {
+	return "reading_list_items"
+}
@@ -0,0 +1,23 @@
+package model
+
+// Recommendation sources
+const (
+	RecommendationSimilar = "similar"
+	RecommendationPopular = "popular"
+)
+
+// RecommendationListResponse represents a list of recommended books, best
+// first. Source is popular when there was nothing to go on and the most
+// viewed books were recommended instead.
+type RecommendationListResponse struct {
+	Data   []*BookResponse `json:"data"`
+	Source string          `json:"source"`
+}
+
+// SimilarityRefresh is the result of recomputing the similarities between
+// books
+type SimilarityRefresh struct {
+	Books        int `json:"books"`
+	Viewers      int `json:"viewers"`
+	Similarities int `json:"similarities"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BookView records that a user opened the detail page of a book: how many
+// times and when they last did. Books viewed by the same users are what
+// item-to-item recommendations are built from.
+type BookView struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_book_views_user_book,priority:1"`
+	BookID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_book_views_user_book,priority:2;index"`
+	Views        int       `gorm:"not null;default:1"`
+	LastViewedAt time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time `gorm:"not null"`
+}
+
+func (BookView) TableName() string {
+	return "book_views"
+}
+
+// BookSimilarity is how similar SimilarBookID is to BookID, from 0 to 1,
+// as last computed by the similarity refresh. CoViews counts the users
+// who viewed both books. Each book keeps only its closest neighbours.
+type BookSimilarity struct {
+	BookID        uuid.UUID `gorm:"type:uuid;primary_key"`
+	SimilarBookID uuid.UUID `gorm:"type:uuid;primary_key"`
+	Score         float64   `gorm:"not null"`
+	CoViews       int       `gorm:"not null;default:0"`
+	UpdatedAt     time.Time `gorm:"not null;index"`
+}
+
+func (BookSimilarity) TableName() string {
+	return "book_similarities"
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"book_system/internal/infrastructure"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReviewResponse represents the review data sent in responses
+type ReviewResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	BookID         uuid.UUID  `json:"book_id"`
+	UserID         uuid.UUID  `json:"user_id"`
+	Author         string     `json:"author"`
+	Rating         int        `json:"rating"`
+	Body           string     `json:"body,omitempty"`
+	Status         string     `json:"status"`
+	ModerationNote string     `json:"moderation_note,omitempty"`
+	ModeratedAt    *time.Time `json:"moderated_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// ReviewListResponse represents a paginated list of reviews
+type ReviewListResponse struct {
+	Data       []*ReviewResponse `json:"data"`
+	Pagination Pagination        `json:"pagination"`
+}
+
+// ReviewRequest represents a star rating with an optional written review,
+// used both to write a review and to replace one
+type ReviewRequest struct {
+	Rating int    `json:"rating" validate:"required,min=1,max=5"`
+	Body   string `json:"body" validate:"max=5000"`
+}
+
+// Validate validates the ReviewRequest
+func (r *ReviewRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
+
+// ReviewModerationRequest represents a moderator's decision on a review
+type ReviewModerationRequest struct {
+	Status string `json:"status" validate:"required,oneof=published flagged hidden"`
+	Note   string `json:"note" validate:"max=255"`
+}
+
+// Validate validates the ReviewModerationRequest
+func (r *ReviewModerationRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
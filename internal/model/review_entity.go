@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Review states
+const (
+	ReviewStatusPublished = "published"
+	ReviewStatusFlagged   = "flagged"
+	ReviewStatusHidden    = "hidden"
+)
+
+// Review is a user's star rating of a book, with an optional written
+// review. Each user reviews a book at most once. Author keeps the
+// reviewer's name as it was when the review was last written. Flagged
+// reviews were marked by a moderator for a closer look and stay public;
+// hidden ones are only shown to their author and admins, and do not count
+// towards the book's rating.
+type Review struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key"`
+	BookID         uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_reviews_book_user,priority:1"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_reviews_book_user,priority:2;index"`
+	Author         string    `gorm:"size:100;not null"`
+	Rating         int       `gorm:"not null"`
+	Body           string    `gorm:"type:text"`
+	Status         string    `gorm:"size:20;not null;index"`
+	ModerationNote string    `gorm:"size:255"`
+	ModeratedBy    string    `gorm:"size:36"`
+	ModeratedAt    *time.Time
+	CreatedAt      time.Time `gorm:"not null;index"`
+	UpdatedAt      time.Time `gorm:"not null"`
+}
+
+func (Review) TableName() string {
+	return "reviews"
+}
+
+// Counted reports whether the review counts towards its book's rating
+func (r *Review) Counted() bool {
+	return r.Status != ReviewStatusHidden
+}
+
+// ToDTO converts Review entity to Review DTO
+func (r *Review) ToDTO() *ReviewResponse {
+	return &ReviewResponse{
+		ID:             r.ID,
+		BookID:         r.BookID,
+		UserID:         r.UserID,
+		Author:         r.Author,
+		Rating:         r.Rating,
+		Body:           r.Body,
+		Status:         r.Status,
+		ModerationNote: r.ModerationNote,
+		ModeratedAt:    r.ModeratedAt,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+	}
+}
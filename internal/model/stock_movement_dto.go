@@ -0,0 +1,89 @@
+package model
+
+import (
+	"book_system/internal/infrastructure"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StockMovementResponse represents a ledger entry sent in responses
+type StockMovementResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	BookID     uuid.UUID  `json:"book_id"`
+	LocationID *uuid.UUID `json:"location_id,omitempty"`
+	TransferID *uuid.UUID `json:"transfer_id,omitempty"`
+	Type       string     `json:"type"`
+	Quantity   int        `json:"quantity"`
+	StockAfter int        `json:"stock_after"`
+	Reason     string     `json:"reason,omitempty"`
+	ActorID    string     `json:"actor_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// StockAdjustRequest represents a change to a book's stock. Quantity is a
+// count of copies for receive, sell and return, and a signed correction
+// for adjust. Without a LocationID the change applies to the book's
+// unallocated stock.
+type StockAdjustRequest struct {
+	Type       string     `json:"type" validate:"required,oneof=receive sell adjust return"`
+	Quantity   int        `json:"quantity" validate:"required,ne=0"`
+	LocationID *uuid.UUID `json:"location_id,omitempty"`
+	Reason     string     `json:"reason" validate:"max=255"`
+}
+
+// Validate validates the StockAdjustRequest
+func (r *StockAdjustRequest) Validate() error {
+	if err := infrastructure.Validate.Struct(r); err != nil {
+		return err
+	}
+	if r.Type != StockMovementAdjust && r.Quantity < 0 {
+		return errors.New("quantity must be positive for " + r.Type)
+	}
+	return nil
+}
+
+// Delta returns the signed change the request makes to the stock
+func (r *StockAdjustRequest) Delta() int {
+	if r.Type == StockMovementSell {
+		return -r.Quantity
+	}
+	return r.Quantity
+}
+
+// StockAdjustResponse is the result of a stock adjustment. LocationStock
+// is the stock left at the location adjusted, if any.
+type StockAdjustResponse struct {
+	BookID        uuid.UUID              `json:"book_id"`
+	Stock         int                    `json:"stock"`
+	LocationStock *int                   `json:"location_stock,omitempty"`
+	LowStock      bool                   `json:"low_stock"`
+	Movement      *StockMovementResponse `json:"movement"`
+}
+
+// StockMovementListResponse represents a paginated list of ledger entries
+type StockMovementListResponse struct {
+	Data       []*StockMovementResponse `json:"data"`
+	Pagination Pagination               `json:"pagination"`
+}
+
+// StockReconciliation compares a book's stock with its ledger. Corrected
+// is set when the stock was rewritten to match the ledger, and Opening
+// when the ledger was empty and got an opening balance instead. Locations
+// lists the location levels that did not match and were corrected.
+type StockReconciliation struct {
+	BookID      uuid.UUID                      `json:"book_id"`
+	Stock       int                            `json:"stock"`
+	LedgerStock int                            `json:"ledger_stock"`
+	Corrected   bool                           `json:"corrected"`
+	Opening     bool                           `json:"opening"`
+	Locations   []*LocationStockReconciliation `json:"locations,omitempty"`
+}
+
+// LocationStockReconciliation is a location level that differed from the ledger
+type LocationStockReconciliation struct {
+	LocationID     uuid.UUID `json:"location_id"`
+	Quantity       int       `json:"quantity"`
+	LedgerQuantity int       `json:"ledger_quantity"`
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Stock movement types
+const (
+	StockMovementReceive  = "receive"
+	StockMovementSell     = "sell"
+	StockMovementAdjust   = "adjust"
+	StockMovementReturn   = "return"
+	StockMovementTransfer = "transfer"
+)
+
+// StockMovement is an entry of the inventory ledger. Quantity is the signed
+// change to the book's stock and StockAfter the stock it left; the sum of a
+// book's quantities is its stock. Movements at a location carry its
+// LocationID, and both legs of a transfer share a TransferID.
+type StockMovement struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key"`
+	BookID     uuid.UUID  `gorm:"type:uuid;not null;index:idx_stock_movements_book_created"`
+	LocationID *uuid.UUID `gorm:"type:uuid;index"`
+	TransferID *uuid.UUID `gorm:"type:uuid;index"`
+	Type       string     `gorm:"size:20;not null"`
+	Quantity   int        `gorm:"not null"`
+	StockAfter int        `gorm:"not null"`
+	Reason     string     `gorm:"size:255"`
+	ActorID    string     `gorm:"size:64"`
+	CreatedAt  time.Time  `gorm:"not null;index:idx_stock_movements_book_created"`
+}
+
+func (StockMovement) TableName() string {
+	return "stock_movements"
+}
+
+// ToDTO converts StockMovement entity to its DTO
+func (m *StockMovement) ToDTO() *StockMovementResponse {
+	return &StockMovementResponse{
+		ID:         m.ID,
+		BookID:     m.BookID,
+		LocationID: m.LocationID,
+		TransferID: m.TransferID,
+		Type:       m.Type,
+		Quantity:   m.Quantity,
+		StockAfter: m.StockAfter,
+		Reason:     m.Reason,
+		ActorID:    m.ActorID,
+		CreatedAt:  m.CreatedAt,
+	}
+}
@@ -15,6 +15,7 @@ type UserResponse struct {
 	FullName  string    `json:"full_name" validate:"required"`
 	Role      string    `json:"role" validate:"oneof=admin user"`
 	Avatar    string    `json:"avatar,omitempty"`
+	Currency  string    `json:"currency,omitempty"`
 	IsActive  bool      `json:"is_active"`
 	LastLogin time.Time `json:"last_login,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
@@ -39,6 +40,7 @@ type UpdateUserRequest struct {
 	Role     *string `json:"role,omitempty" validate:"omitempty,oneof=admin user"`
 	IsActive *bool   `json:"is_active,omitempty"`
 	Avatar   *string `json:"avatar,omitempty"`
+	Currency *string `json:"currency,omitempty" validate:"omitempty,iso4217"`
 }
 
 func (r *UpdateUserRequest) Validate() error {
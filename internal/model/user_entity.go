@@ -14,6 +14,7 @@ type User struct {
 	FullName  string    `gorm:"size:100;not null"`
 	Role      string    `gorm:"size:20;not null;default:'user'"`
 	Avatar    string    `gorm:"size:255"`
+	Currency  string    `gorm:"size:3"`
 	IsActive  bool      `gorm:"not null;default:true"`
 	LastLogin time.Time
 	CreatedAt time.Time `gorm:"not null"`
@@ -33,6 +34,7 @@ func (u *User) ToDTO() *UserResponse {
 		FullName:  u.FullName,
 		Role:      u.Role,
 		Avatar:    u.Avatar,
+		Currency:  u.Currency,
 		IsActive:  u.IsActive,
 		LastLogin: u.LastLogin,
 		CreatedAt: u.CreatedAt,
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"book_system/internal/infrastructure"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkResponse represents the work data sent in responses
+type WorkResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	Title          string     `json:"title"`
+	Author         string     `json:"author,omitempty"`
+	Description    string     `json:"description,omitempty"`
+	SeriesID       *uuid.UUID `json:"series_id,omitempty"`
+	SeriesPosition float64    `json:"series_position,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// WorkRequest represents the editable fields of a work. It is used both to
+// create a work and to replace one, so omitted optional fields are cleared.
+type WorkRequest struct {
+	Title          string     `json:"title" validate:"required,min=1,max=255"`
+	Author         string     `json:"author" validate:"max=255"`
+	Description    string     `json:"description"`
+	SeriesID       *uuid.UUID `json:"series_id,omitempty"`
+	SeriesPosition float64    `json:"series_position" validate:"required_with=SeriesID,gte=0,lt=10000"`
+}
+
+// Validate validates the WorkRequest
+func (r *WorkRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
+
+// WorkListResponse represents a paginated list of works
+type WorkListResponse struct {
+	Data       []*WorkResponse `json:"data"`
+	Pagination Pagination      `json:"pagination"`
+}
+
+// WorkEditionsResponse lists the editions of a work
+type WorkEditionsResponse struct {
+	Work     *WorkResponse   `json:"work"`
+	Editions []*BookResponse `json:"editions"`
+}
+
+// SeriesResponse represents the series data sent in responses. Volumes
+// are only included when a single series is requested.
+type SeriesResponse struct {
+	ID          uuid.UUID       `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description,omitempty"`
+	Volumes     []*WorkResponse `json:"volumes,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// SeriesRequest represents the editable fields of a series, used both to
+// create and to replace one
+type SeriesRequest struct {
+	Title       string `json:"title" validate:"required,min=1,max=255"`
+	Description string `json:"description"`
+}
+
+// Validate validates the SeriesRequest
+func (r *SeriesRequest) Validate() error {
+	return infrastructure.Validate.Struct(r)
+}
+
+// SeriesListResponse represents a paginated list of series
+type SeriesListResponse struct {
+	Data       []*SeriesResponse `json:"data"`
+	Pagination Pagination        `json:"pagination"`
+}
+
+// SeriesNavigation places a work within its series. Previous and Next are
+// nil at either end of the series.
+type SeriesNavigation struct {
+	Series   *SeriesResponse `json:"series"`
+	Work     *WorkResponse   `json:"work"`
+	Previous *WorkResponse   `json:"previous"`
+	Next     *WorkResponse   `json:"next"`
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Work groups the editions of one book, such as its hardcover, paperback,
+// ebook and audiobook. A work may be a volume of a series, ordered by its
+// SeriesPosition; positions may be fractional for in-between novellas.
+type Work struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key"`
+	Title          string     `gorm:"size:255;not null"`
+	Author         string     `gorm:"size:255"`
+	Description    string     `gorm:"type:text"`
+	SeriesID       *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_works_series_position"`
+	SeriesPosition float64    `gorm:"type:decimal(6,2);not null;default:0;uniqueIndex:idx_works_series_position"`
+	CreatedAt      time.Time  `gorm:"not null"`
+	UpdatedAt      time.Time  `gorm:"not null"`
+}
+
+func (Work) TableName() string {
+	return "works"
+}
+
+// ToDTO converts Work entity to Work DTO
+func (w *Work) ToDTO() *WorkResponse {
+	return &WorkResponse{
+		ID:             w.ID,
+		Title:          w.Title,
+		Author:         w.Author,
+		Description:    w.Description,
+		SeriesID:       w.SeriesID,
+		SeriesPosition: w.SeriesPosition,
+		CreatedAt:      w.CreatedAt,
+		UpdatedAt:      w.UpdatedAt,
+	}
+}
+
+// Series is an ordered sequence of works
+type Series struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key"`
+	Title       string    `gorm:"size:255;not null"`
+	Description string    `gorm:"type:text"`
+	CreatedAt   time.Time `gorm:"not null"`
+	UpdatedAt   time.Time `gorm:"not null"`
+}
+
+func (Series) TableName() string {
+	return "series"
+}
+
+// ToDTO converts Series entity to Series DTO
+func (s *Series) ToDTO() *SeriesResponse {
+	return &SeriesResponse{
+		ID:          s.ID,
+		Title:       s.Title,
+		Description: s.Description,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type accountEntryRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountEntryRepository creates a new account entry repository
+func NewAccountEntryRepository(db *gorm.DB) IAccountEntryRepository {
+	return &accountEntryRepository{
+		db: db,
+	}
+}
+
+// Create saves a new ledger entry
+func (r *accountEntryRepository) Create(ctx context.Context, entry *model.AccountEntry) error {
+	return conn(ctx, r.db).Create(entry).Error
+}
+
+// FindByUserID returns a paginated ledger of a member, newest first
+func (r *accountEntryRepository) FindByUserID(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]*model.AccountEntry, int64, error) {
+	var entries []*model.AccountEntry
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.AccountEntry{}).Where("user_id = ?", userID)
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return entries, count, nil
+}
+
+// Balance returns the sum of a member's ledger entries
+func (r *accountEntryRepository) Balance(ctx context.Context, userID uuid.UUID) (decimal.Decimal, error) {
+	var result struct {
+		Balance decimal.Decimal
+	}
+	err := conn(ctx, r.db).Model(&model.AccountEntry{}).
+		Select("COALESCE(SUM(amount), 0) AS balance").
+		Where("user_id = ?", userID).
+		Scan(&result).Error
+	return result.Balance, err
+}
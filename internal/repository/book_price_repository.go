@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type bookPriceRepository struct {
+	db *gorm.DB
+}
+
+// NewBookPriceRepository creates a new book price repository
+func NewBookPriceRepository(db *gorm.DB) IBookPriceRepository {
+	return &bookPriceRepository{
+		db: db,
+	}
+}
+
+// FindByBookID returns the list prices of a book by currency code order
+func (r *bookPriceRepository) FindByBookID(ctx context.Context, bookID uuid.UUID) ([]*model.BookPrice, error) {
+	var prices []*model.BookPrice
+	err := conn(ctx, r.db).
+		Where("book_id = ?", bookID).
+		Order("currency").
+		Find(&prices).Error
+	return prices, err
+}
+
+// FindByBookIDs returns the list prices in a currency of the given books
+func (r *bookPriceRepository) FindByBookIDs(ctx context.Context, bookIDs []uuid.UUID, currency string) ([]*model.BookPrice, error) {
+	var prices []*model.BookPrice
+	if len(bookIDs) == 0 {
+		return prices, nil
+	}
+	err := conn(ctx, r.db).
+		Where("book_id IN ? AND currency = ?", bookIDs, currency).
+		Find(&prices).Error
+	return prices, err
+}
+
+// Save creates a list price or overwrites the amount of an existing one
+func (r *bookPriceRepository) Save(ctx context.Context, price *model.BookPrice) error {
+	price.UpdatedAt = time.Now()
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "book_id"}, {Name: "currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"amount", "updated_at"}),
+	}).Create(price).Error
+}
+
+// Delete removes the list price of a book in a currency, reporting false if there was none
+func (r *bookPriceRepository) Delete(ctx context.Context, bookID uuid.UUID, currency string) (bool, error) {
+	result := conn(ctx, r.db).Delete(&model.BookPrice{}, "book_id = ? AND currency = ?", bookID, currency)
+	return result.RowsAffected > 0, result.Error
+}
@@ -1,11 +1,14 @@
 package repository
 
 import (
+	isbnlib "book_system/internal/baselib/isbn"
 	"book_system/internal/model"
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type bookRepository struct {
@@ -21,28 +24,28 @@ func NewBookRepository(db *gorm.DB) IBookRepository {
 
 // Create saves a new book
 func (r *bookRepository) Create(ctx context.Context, book *model.Book) error {
-	return r.db.WithContext(ctx).Create(book).Error
+	return conn(ctx, r.db).Create(book).Error
 }
 
 // FindByID finds a book by ID
 func (r *bookRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Book, error) {
 	var book model.Book
-	err := r.db.WithContext(ctx).First(&book, "id = ?", id).Error
+	err := conn(ctx, r.db).First(&book, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &book, nil
 }
 
-// FindAll returns a paginated list of books
-func (r *bookRepository) FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.Book, int64, error) {
+// FindAll returns a paginated list of books in the given order, if any
+func (r *bookRepository) FindAll(ctx context.Context, page, pageSize int, filters map[string]any, sort string) ([]*model.Book, int64, error) {
 	var books []*model.Book
 	var count int64
 
 	offset := (page - 1) * pageSize
 
 	// Start building the query
-	query := r.db.WithContext(ctx).Model(&model.Book{})
+	query := conn(ctx, r.db).Model(&model.Book{})
 
 	// Apply filters if any
 	for key, value := range filters {
@@ -54,6 +57,13 @@ func (r *bookRepository) FindAll(ctx context.Context, page, pageSize int, filter
 		return nil, 0, err
 	}
 
+	switch sort {
+	case model.BookSortRating:
+		query = query.Order("rating_sum / NULLIF(rating_count, 0) DESC, rating_count DESC")
+	case model.BookSortReviews:
+		query = query.Order("rating_count DESC")
+	}
+
 	// Get paginated books
 	if err := query.Offset(offset).
 		Limit(pageSize).
@@ -64,21 +74,53 @@ func (r *bookRepository) FindAll(ctx context.Context, page, pageSize int, filter
 	return books, count, nil
 }
 
-// Update updates a book
+// Update saves a book if it is still at book.Version and bumps the version.
+// It returns ErrVersionConflict when the book was changed in the meantime.
+// Stock and the rating are left to AdjustStock, SetStock and AdjustRating.
 func (r *bookRepository) Update(ctx context.Context, book *model.Book) error {
-	return r.db.WithContext(ctx).Save(book).Error
+	current := book.Version
+	book.Version = current + 1
+
+	result := conn(ctx, r.db).Model(book).
+		Where("version = ?", current).
+		Select("*").
+		Omit("id", "created_at", "stock", "rating_sum", "rating_count").
+		Updates(book)
+	if result.Error != nil {
+		book.Version = current
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		book.Version = current
+		return ErrVersionConflict
+	}
+	return nil
 }
 
-// Delete deletes a book by ID
-func (r *bookRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return r.db.WithContext(ctx).Delete(&model.Book{}, "id = ?", id).Error
+// Delete soft-deletes a book by ID if it is still at the given version and
+// bumps the version. It returns ErrVersionConflict when the book was
+// changed in the meantime.
+func (r *bookRepository) Delete(ctx context.Context, id uuid.UUID, version int) error {
+	result := conn(ctx, r.db).Model(&model.Book{}).
+		Where("id = ? AND version = ?", id, version).
+		UpdateColumns(map[string]any{
+			"deleted_at": time.Now(),
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
 }
 
-// ExistsByISBN checks if a book with the given ISBN exists
+// ExistsByISBN checks if a book with the given ISBN, in any form, exists
 func (r *bookRepository) ExistsByISBN(ctx context.Context, isbn string) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&model.Book{}).
-		Where("isbn = ?", isbn).
+	err := conn(ctx, r.db).Model(&model.Book{}).
+		Where("isbn IN ?", isbnForms(isbn)).
 		Count(&count).Error
 
 	if err != nil {
@@ -87,3 +129,224 @@ func (r *bookRepository) ExistsByISBN(ctx context.Context, isbn string) (bool, e
 
 	return count > 0, nil
 }
+
+// FindByISBN finds a book by ISBN, in any form
+func (r *bookRepository) FindByISBN(ctx context.Context, isbn string) (*model.Book, error) {
+	var book model.Book
+	err := conn(ctx, r.db).Where("isbn IN ?", isbnForms(isbn)).First(&book).Error
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// UpdateISBN rewrites a book's ISBN columns in place, without a new
+// version, for changes of form rather than of value
+func (r *bookRepository) UpdateISBN(ctx context.Context, id uuid.UUID, isbn13, isbn10 string) error {
+	return conn(ctx, r.db).Model(&model.Book{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]any{"isbn": isbn13, "isbn10": isbn10}).Error
+}
+
+// isbnForms lists the values an ISBN may be stored as: as given, and its
+// ISBN-13 and ISBN-10 forms for rows written before ISBNs were normalized
+func isbnForms(value string) []string {
+	forms := []string{value}
+	isbn13, err := isbnlib.Normalize(value)
+	if err != nil {
+		return forms
+	}
+	forms = append(forms, isbn13)
+	if isbn10 := isbnlib.To10(isbn13); isbn10 != "" {
+		forms = append(forms, isbn10)
+	}
+	return forms
+}
+
+// FindInBatches walks the books matching filters in batches of batchSize,
+// calling fn for each batch until it returns an error
+func (r *bookRepository) FindInBatches(ctx context.Context, filters map[string]any, batchSize int, fn func(books []*model.Book) error) error {
+	query := conn(ctx, r.db).Model(&model.Book{})
+	for key, value := range filters {
+		query = query.Where(key, value)
+	}
+
+	var books []*model.Book
+	return query.FindInBatches(&books, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(books)
+	}).Error
+}
+
+// FindTrashed returns a paginated list of soft-deleted books
+func (r *bookRepository) FindTrashed(ctx context.Context, page, pageSize int) ([]*model.Book, int64, error) {
+	var books []*model.Book
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Unscoped().Model(&model.Book{}).
+		Where("deleted_at IS NOT NULL")
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("deleted_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&books).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return books, count, nil
+}
+
+// FindByIDUnscoped finds a book by ID, whether it is in the trash or not
+func (r *bookRepository) FindByIDUnscoped(ctx context.Context, id uuid.UUID) (*model.Book, error) {
+	var book model.Book
+	err := conn(ctx, r.db).Unscoped().First(&book, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// FindTrashedByID finds a soft-deleted book by ID
+func (r *bookRepository) FindTrashedByID(ctx context.Context, id uuid.UUID) (*model.Book, error) {
+	var book model.Book
+	err := conn(ctx, r.db).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		First(&book, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// Restore moves a soft-deleted book back out of the trash and bumps its version
+func (r *bookRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	result := conn(ctx, r.db).Unscoped().Model(&model.Book{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		UpdateColumns(map[string]any{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Purge permanently deletes a book in the trash. Live books are left
+// alone, even one restored since the caller found it in the trash.
+func (r *bookRepository) Purge(ctx context.Context, id uuid.UUID) error {
+	result := conn(ctx, r.db).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Delete(&model.Book{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindByWorkID returns the editions of a work, oldest first
+func (r *bookRepository) FindByWorkID(ctx context.Context, workID uuid.UUID) ([]*model.Book, error) {
+	var books []*model.Book
+	err := conn(ctx, r.db).
+		Where("work_id = ?", workID).
+		Order("published_at, title").
+		Find(&books).Error
+	return books, err
+}
+
+// ExistsByWorkID checks if a work has any editions, including ones in the trash
+func (r *bookRepository) ExistsByWorkID(ctx context.Context, workID uuid.UUID) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Unscoped().Model(&model.Book{}).
+		Where("work_id = ?", workID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ExistsByCategoryID checks if any book is filed under a category,
+// including books in the trash
+func (r *bookRepository) ExistsByCategoryID(ctx context.Context, categoryID uuid.UUID) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Unscoped().Model(&model.Book{}).
+		Where("category_id = ?", categoryID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// AdjustStock adds delta to a book's stock in a single conditional update
+// and returns the new stock. It returns ErrInsufficientStock instead of
+// letting the stock drop below what is held at its locations, so
+// location levels must be adjusted first within the same transaction.
+func (r *bookRepository) AdjustStock(ctx context.Context, id uuid.UUID, delta int) (int, error) {
+	db := conn(ctx, r.db)
+	allocated := db.Model(&model.StockLevel{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("book_id = ?", id)
+	result := db.Model(&model.Book{}).
+		Where("id = ? AND stock + ? >= (?)", id, delta, allocated).
+		UpdateColumn("stock", gorm.Expr("stock + ?", delta))
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	var book model.Book
+	if err := db.Select("stock").First(&book, "id = ?", id).Error; err != nil {
+		return 0, err
+	}
+	if result.RowsAffected == 0 {
+		return book.Stock, ErrInsufficientStock
+	}
+	return book.Stock, nil
+}
+
+// FindByIDs finds the books with the given IDs, in no particular order
+func (r *bookRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Book, error) {
+	var books []*model.Book
+	if len(ids) == 0 {
+		return books, nil
+	}
+	err := conn(ctx, r.db).Where("id IN ?", ids).Find(&books).Error
+	return books, err
+}
+
+// SetStock overwrites a book's stock, for reconciliation with its ledger
+func (r *bookRepository) SetStock(ctx context.Context, id uuid.UUID, stock int) error {
+	return conn(ctx, r.db).Model(&model.Book{}).
+		Where("id = ?", id).
+		UpdateColumn("stock", stock).Error
+}
+
+// FindByIDForUpdate finds a book by ID and locks its row until the
+// surrounding transaction ends
+func (r *bookRepository) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Book, error) {
+	var book model.Book
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&book, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// AdjustRating adds to the sum of stars and count of ratings of a book in
+// a single update, without touching its version
+func (r *bookRepository) AdjustRating(ctx context.Context, id uuid.UUID, sumDelta, countDelta int) error {
+	return conn(ctx, r.db).Model(&model.Book{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]any{
+			"rating_sum":   gorm.Expr("rating_sum + ?", sumDelta),
+			"rating_count": gorm.Expr("rating_count + ?", countDelta),
+		}).Error
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type bookSimilarityRepository struct {
+	db *gorm.DB
+}
+
+// NewBookSimilarityRepository creates a new book similarity repository
+func NewBookSimilarityRepository(db *gorm.DB) IBookSimilarityRepository {
+	return &bookSimilarityRepository{
+		db: db,
+	}
+}
+
+// FindByBookID returns the books most similar to a book, most similar
+// first, up to limit
+func (r *bookSimilarityRepository) FindByBookID(ctx context.Context, bookID uuid.UUID, limit int) ([]*model.BookSimilarity, error) {
+	var similarities []*model.BookSimilarity
+	err := conn(ctx, r.db).
+		Where("book_id = ?", bookID).
+		Order("score DESC").
+		Limit(limit).
+		Find(&similarities).Error
+	return similarities, err
+}
+
+// FindByBookIDs returns the similar books of each of the given books
+func (r *bookSimilarityRepository) FindByBookIDs(ctx context.Context, bookIDs []uuid.UUID) ([]*model.BookSimilarity, error) {
+	var similarities []*model.BookSimilarity
+	err := conn(ctx, r.db).
+		Where("book_id IN ?", bookIDs).
+		Find(&similarities).Error
+	return similarities, err
+}
+
+// Replace overwrites the similar books of a book
+func (r *bookSimilarityRepository) Replace(ctx context.Context, bookID uuid.UUID, similarities []*model.BookSimilarity) error {
+	db := conn(ctx, r.db)
+	if err := db.Delete(&model.BookSimilarity{}, "book_id = ?", bookID).Error; err != nil {
+		return err
+	}
+	if len(similarities) == 0 {
+		return nil
+	}
+	return db.Create(similarities).Error
+}
+
+// DeleteBefore deletes the similarities last computed before the given time
+func (r *bookSimilarityRepository) DeleteBefore(ctx context.Context, before time.Time) error {
+	return conn(ctx, r.db).Delete(&model.BookSimilarity{}, "updated_at < ?", before).Error
+}
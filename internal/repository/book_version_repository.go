@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type bookVersionRepository struct {
+	db *gorm.DB
+}
+
+// NewBookVersionRepository creates a new book version repository
+func NewBookVersionRepository(db *gorm.DB) IBookVersionRepository {
+	return &bookVersionRepository{
+		db: db,
+	}
+}
+
+// Create saves a new book version
+func (r *bookVersionRepository) Create(ctx context.Context, version *model.BookVersion) error {
+	return conn(ctx, r.db).Create(version).Error
+}
+
+// FindByBookID returns a paginated history of a book, newest first
+func (r *bookVersionRepository) FindByBookID(ctx context.Context, bookID uuid.UUID, page, pageSize int) ([]*model.BookVersion, int64, error) {
+	var versions []*model.BookVersion
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.BookVersion{}).Where("book_id = ?", bookID)
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("version DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&versions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return versions, count, nil
+}
+
+// FindByVersion finds a single version of a book
+func (r *bookVersionRepository) FindByVersion(ctx context.Context, bookID uuid.UUID, version int) (*model.BookVersion, error) {
+	var bookVersion model.BookVersion
+	err := conn(ctx, r.db).
+		Where("book_id = ? AND version = ?", bookID, version).
+		First(&bookVersion).Error
+	if err != nil {
+		return nil, err
+	}
+	return &bookVersion, nil
+}
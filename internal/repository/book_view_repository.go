@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type bookViewRepository struct {
+	db *gorm.DB
+}
+
+// NewBookViewRepository creates a new book view repository
+func NewBookViewRepository(db *gorm.DB) IBookViewRepository {
+	return &bookViewRepository{
+		db: db,
+	}
+}
+
+// Record counts a view of a book by a user
+func (r *bookViewRepository) Record(ctx context.Context, userID, bookID uuid.UUID, at time.Time) error {
+	view := &model.BookView{
+		ID:           uuid.New(),
+		UserID:       userID,
+		BookID:       bookID,
+		Views:        1,
+		LastViewedAt: at,
+		CreatedAt:    at,
+	}
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "book_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"views":          gorm.Expr("views + 1"),
+			"last_viewed_at": at,
+		}),
+	}).Create(view).Error
+}
+
+// FindByUserID returns the books a user viewed most recently, up to limit
+func (r *bookViewRepository) FindByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*model.BookView, error) {
+	var views []*model.BookView
+	err := conn(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("last_viewed_at DESC").
+		Limit(limit).
+		Find(&views).Error
+	return views, err
+}
+
+// FindRecentByUserInBatches walks the users who viewed books in batches of
+// batchSize users, in ID order, calling fn with the views of each batch
+// until it returns an error. A user's views of live books come together,
+// most recent first, and only the perUser most recent of them, so a batch
+// never holds more than batchSize*perUser views however long the
+// histories are.
+func (r *bookViewRepository) FindRecentByUserInBatches(ctx context.Context, perUser, batchSize int, fn func(views []*model.BookView) error) error {
+	var after *uuid.UUID
+	for {
+		var users []uuid.UUID
+		query := conn(ctx, r.db).Model(&model.BookView{}).Distinct("user_id").Order("user_id").Limit(batchSize)
+		if after != nil {
+			query = query.Where("user_id > ?", *after)
+		}
+		if err := query.Pluck("user_id", &users).Error; err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return nil
+		}
+
+		live := conn(ctx, r.db).Model(&model.Book{}).Select("id")
+		ranked := conn(ctx, r.db).Model(&model.BookView{}).
+			Select("*, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY last_viewed_at DESC) AS recency").
+			Where("user_id IN ? AND book_id IN (?)", users, live)
+		var views []*model.BookView
+		err := conn(ctx, r.db).Table("(?) AS ranked", ranked).
+			Where("recency <= ?", perUser).
+			Order("user_id, recency").
+			Find(&views).Error
+		if err != nil {
+			return err
+		}
+		if err := fn(views); err != nil {
+			return err
+		}
+
+		if len(users) < batchSize {
+			return nil
+		}
+		after = &users[len(users)-1]
+	}
+}
+
+// MostViewed returns the IDs of the books viewed by the most users, most
+// viewed first, up to limit
+func (r *bookViewRepository) MostViewed(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	var rows []struct {
+		BookID uuid.UUID
+	}
+	err := conn(ctx, r.db).Model(&model.BookView{}).
+		Select("book_id, COUNT(*) AS viewers, SUM(views) AS total").
+		Group("book_id").
+		Order("viewers DESC, total DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	bookIDs := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		bookIDs[i] = row.BookID
+	}
+	return bookIDs, nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type cartRepository struct {
+	db *gorm.DB
+}
+
+// NewCartRepository creates a new shopping cart repository
+func NewCartRepository(db *gorm.DB) ICartRepository {
+	return &cartRepository{
+		db: db,
+	}
+}
+
+// FindByUserID returns the items in a user's cart, oldest first
+func (r *cartRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*model.CartItem, error) {
+	var items []*model.CartItem
+	err := conn(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("created_at").
+		Find(&items).Error
+	return items, err
+}
+
+// Find finds a book in a user's cart
+func (r *cartRepository) Find(ctx context.Context, userID, bookID uuid.UUID) (*model.CartItem, error) {
+	var item model.CartItem
+	err := conn(ctx, r.db).First(&item, "user_id = ? AND book_id = ?", userID, bookID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Save creates a cart item or overwrites the quantity of an existing one
+func (r *cartRepository) Save(ctx context.Context, item *model.CartItem) error {
+	item.UpdatedAt = time.Now()
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = item.UpdatedAt
+	}
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "book_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quantity", "updated_at"}),
+	}).Create(item).Error
+}
+
+// Delete removes a book from a user's cart
+func (r *cartRepository) Delete(ctx context.Context, userID, bookID uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&model.CartItem{}, "user_id = ? AND book_id = ?", userID, bookID).Error
+}
+
+// DeleteByUserID empties a user's cart
+func (r *cartRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&model.CartItem{}, "user_id = ?", userID).Error
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+// NewCategoryRepository creates a new category repository
+func NewCategoryRepository(db *gorm.DB) ICategoryRepository {
+	return &categoryRepository{
+		db: db,
+	}
+}
+
+// Create saves a new category
+func (r *categoryRepository) Create(ctx context.Context, category *model.Category) error {
+	return conn(ctx, r.db).Create(category).Error
+}
+
+// FindByID finds a category by ID
+func (r *categoryRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Category, error) {
+	var category model.Category
+	err := conn(ctx, r.db).First(&category, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// FindByIDs finds the categories with the given IDs, in no particular order
+func (r *categoryRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Category, error) {
+	var categories []*model.Category
+	if len(ids) == 0 {
+		return categories, nil
+	}
+	err := conn(ctx, r.db).Where("id IN ?", ids).Find(&categories).Error
+	return categories, err
+}
+
+// FindAll returns a paginated list of categories
+func (r *categoryRepository) FindAll(ctx context.Context, page, pageSize int) ([]*model.Category, int64, error) {
+	var categories []*model.Category
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.Category{})
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("name").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&categories).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return categories, count, nil
+}
+
+// ExistsByName checks if a category other than excludeID has a name
+func (r *categoryRepository) ExistsByName(ctx context.Context, name string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.Category{}).
+		Where("name = ? AND id <> ?", name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Update updates a category
+func (r *categoryRepository) Update(ctx context.Context, category *model.Category) error {
+	return conn(ctx, r.db).Save(category).Error
+}
+
+// Delete deletes a category by ID
+func (r *categoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&model.Category{}, "id = ?", id).Error
+}
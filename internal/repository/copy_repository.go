@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type copyRepository struct {
+	db *gorm.DB
+}
+
+// NewCopyRepository creates a new copy repository
+func NewCopyRepository(db *gorm.DB) ICopyRepository {
+	return &copyRepository{
+		db: db,
+	}
+}
+
+// Create saves a new copy
+func (r *copyRepository) Create(ctx context.Context, bookCopy *model.Copy) error {
+	return conn(ctx, r.db).Create(bookCopy).Error
+}
+
+// FindByID finds a copy by ID
+func (r *copyRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Copy, error) {
+	var bookCopy model.Copy
+	err := conn(ctx, r.db).First(&bookCopy, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &bookCopy, nil
+}
+
+// FindByBarcodeForUpdate finds a copy by barcode and locks its row until
+// the surrounding transaction ends
+func (r *copyRepository) FindByBarcodeForUpdate(ctx context.Context, barcode string) (*model.Copy, error) {
+	var bookCopy model.Copy
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&bookCopy, "barcode = ?", barcode).Error
+	if err != nil {
+		return nil, err
+	}
+	return &bookCopy, nil
+}
+
+// FindAll returns a paginated list of copies ordered by barcode
+func (r *copyRepository) FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.Copy, int64, error) {
+	var copies []*model.Copy
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.Copy{})
+	for key, value := range filters {
+		query = query.Where(key, value)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("barcode").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&copies).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return copies, count, nil
+}
+
+// ExistsByBarcode checks if another copy than excludeID uses a barcode
+func (r *copyRepository) ExistsByBarcode(ctx context.Context, barcode string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.Copy{}).
+		Where("barcode = ? AND id <> ?", barcode, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Update updates a copy
+func (r *copyRepository) Update(ctx context.Context, bookCopy *model.Copy) error {
+	return conn(ctx, r.db).Save(bookCopy).Error
+}
+
+// Delete deletes a copy by ID
+func (r *copyRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&model.Copy{}, "id = ?", id).Error
+}
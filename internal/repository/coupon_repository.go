@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type couponRepository struct {
+	db *gorm.DB
+}
+
+// NewCouponRepository creates a new coupon repository
+func NewCouponRepository(db *gorm.DB) ICouponRepository {
+	return &couponRepository{
+		db: db,
+	}
+}
+
+// Create saves a new coupon
+func (r *couponRepository) Create(ctx context.Context, coupon *model.Coupon) error {
+	return conn(ctx, r.db).Create(coupon).Error
+}
+
+// FindByID finds a coupon by ID
+func (r *couponRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Coupon, error) {
+	var coupon model.Coupon
+	err := conn(ctx, r.db).First(&coupon, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// FindByCode finds a coupon by code
+func (r *couponRepository) FindByCode(ctx context.Context, code string) (*model.Coupon, error) {
+	var coupon model.Coupon
+	err := conn(ctx, r.db).First(&coupon, "code = ?", code).Error
+	if err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// FindAll returns a paginated list of coupons, newest first
+func (r *couponRepository) FindAll(ctx context.Context, page, pageSize int) ([]*model.Coupon, int64, error) {
+	var coupons []*model.Coupon
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.Coupon{})
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&coupons).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return coupons, count, nil
+}
+
+// ExistsByCode checks if another coupon than excludeID uses a code
+func (r *couponRepository) ExistsByCode(ctx context.Context, code string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.Coupon{}).
+		Where("code = ? AND id <> ?", code, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Redeem counts a use of a coupon, reporting false if it has no uses left
+func (r *couponRepository) Redeem(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := conn(ctx, r.db).Model(&model.Coupon{}).
+		Where("id = ? AND (max_uses = 0 OR uses < max_uses)", id).
+		UpdateColumn("uses", gorm.Expr("uses + 1"))
+	return result.RowsAffected > 0, result.Error
+}
+
+// Release gives back a use of a coupon
+func (r *couponRepository) Release(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Model(&model.Coupon{}).
+		Where("id = ? AND uses > 0", id).
+		UpdateColumn("uses", gorm.Expr("uses - 1")).Error
+}
+
+// Update updates a coupon. Its use count is left alone; it only changes
+// through Redeem and Release, which may commit while the edit is made.
+func (r *couponRepository) Update(ctx context.Context, coupon *model.Coupon) error {
+	return conn(ctx, r.db).Model(coupon).
+		Select("*").
+		Omit("id", "created_at", "uses").
+		Updates(coupon).Error
+}
+
+// Delete deletes a coupon by ID
+func (r *couponRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&model.Coupon{}, "id = ?", id).Error
+}
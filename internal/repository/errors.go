@@ -0,0 +1,11 @@
+package repository
+
+import "errors"
+
+// ErrVersionConflict is returned by conditional writes when the row was
+// changed by someone else since it was read
+var ErrVersionConflict = errors.New("record was modified concurrently")
+
+// ErrInsufficientStock is returned by stock adjustments that would leave a
+// negative stock
+var ErrInsufficientStock = errors.New("insufficient stock")
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type exchangeRateRepository struct {
+	db *gorm.DB
+}
+
+// NewExchangeRateRepository creates a new exchange rate repository
+func NewExchangeRateRepository(db *gorm.DB) IExchangeRateRepository {
+	return &exchangeRateRepository{
+		db: db,
+	}
+}
+
+// Create saves new exchange rate snapshots
+func (r *exchangeRateRepository) Create(ctx context.Context, rates []*model.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	return conn(ctx, r.db).CreateInBatches(rates, 100).Error
+}
+
+// FindAll returns a paginated history of exchange rates, latest first
+func (r *exchangeRateRepository) FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.ExchangeRate, int64, error) {
+	var rates []*model.ExchangeRate
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.ExchangeRate{})
+	for condition, value := range filters {
+		query = query.Where(condition, value)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("effective_at DESC, created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&rates).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return rates, count, nil
+}
+
+// FindLatest returns the newest snapshot of every currency pair in effect
+// at the given time. Snapshots effective at the same time are told apart
+// by when they were recorded.
+func (r *exchangeRateRepository) FindLatest(ctx context.Context, at time.Time) ([]*model.ExchangeRate, error) {
+	var rates []*model.ExchangeRate
+	err := conn(ctx, r.db).
+		Where("effective_at <= ?", at).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM exchange_rates newer
+			WHERE newer.base_currency = exchange_rates.base_currency
+			AND newer.quote_currency = exchange_rates.quote_currency
+			AND newer.effective_at <= ?
+			AND (newer.effective_at > exchange_rates.effective_at
+				OR (newer.effective_at = exchange_rates.effective_at AND newer.created_at > exchange_rates.created_at)))`, at).
+		Order("base_currency, quote_currency").
+		Find(&rates).Error
+	return rates, err
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type finePolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewFinePolicyRepository creates a new fine policy repository
+func NewFinePolicyRepository(db *gorm.DB) IFinePolicyRepository {
+	return &finePolicyRepository{
+		db: db,
+	}
+}
+
+// Create saves a new fine policy
+func (r *finePolicyRepository) Create(ctx context.Context, policy *model.FinePolicy) error {
+	return conn(ctx, r.db).Create(policy).Error
+}
+
+// FindByID finds a fine policy by ID
+func (r *finePolicyRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.FinePolicy, error) {
+	var policy model.FinePolicy
+	err := conn(ctx, r.db).First(&policy, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// FindAll returns every fine policy ordered by role, the default first
+func (r *finePolicyRepository) FindAll(ctx context.Context) ([]*model.FinePolicy, error) {
+	var policies []*model.FinePolicy
+	err := conn(ctx, r.db).Order("role").Find(&policies).Error
+	return policies, err
+}
+
+// ExistsByRole checks if another fine policy than excludeID is for a role
+func (r *finePolicyRepository) ExistsByRole(ctx context.Context, role string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.FinePolicy{}).
+		Where("role = ? AND id <> ?", role, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Update updates a fine policy
+func (r *finePolicyRepository) Update(ctx context.Context, policy *model.FinePolicy) error {
+	return conn(ctx, r.db).Save(policy).Error
+}
+
+// Delete deletes a fine policy by ID
+func (r *finePolicyRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&model.FinePolicy{}, "id = ?", id).Error
+}
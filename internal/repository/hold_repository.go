@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type holdRepository struct {
+	db *gorm.DB
+}
+
+// NewHoldRepository creates a new hold repository
+func NewHoldRepository(db *gorm.DB) IHoldRepository {
+	return &holdRepository{
+		db: db,
+	}
+}
+
+// Create saves a new hold
+func (r *holdRepository) Create(ctx context.Context, hold *model.Hold) error {
+	return conn(ctx, r.db).Create(hold).Error
+}
+
+// FindByID finds a hold by ID
+func (r *holdRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Hold, error) {
+	var hold model.Hold
+	err := conn(ctx, r.db).First(&hold, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// FindByIDForUpdate finds a hold by ID and locks its row until the
+// surrounding transaction ends
+func (r *holdRepository) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Hold, error) {
+	var hold model.Hold
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&hold, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// FindActive finds the waiting or ready hold a user has on a book, locking
+// it until the surrounding transaction ends
+func (r *holdRepository) FindActive(ctx context.Context, bookID, userID uuid.UUID) (*model.Hold, error) {
+	var hold model.Hold
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id = ? AND user_id = ? AND status IN ?", bookID, userID, []string{model.HoldStatusWaiting, model.HoldStatusReady}).
+		First(&hold).Error
+	if err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// FindAll returns a paginated list of holds in the order they were placed
+func (r *holdRepository) FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.Hold, int64, error) {
+	var holds []*model.Hold
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.Hold{})
+	for key, value := range filters {
+		query = query.Where(key, value)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("created_at, id").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&holds).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return holds, count, nil
+}
+
+// FindNextWaiting returns up to limit waiting holds at the front of a
+// book's queue, locking them until the surrounding transaction ends
+func (r *holdRepository) FindNextWaiting(ctx context.Context, bookID uuid.UUID, limit int) ([]*model.Hold, error) {
+	var holds []*model.Hold
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id = ? AND status = ?", bookID, model.HoldStatusWaiting).
+		Order("created_at, id").
+		Limit(limit).
+		Find(&holds).Error
+	return holds, err
+}
+
+// FindExpired returns up to limit ready holds whose pickup window ended
+// before the given time, oldest first
+func (r *holdRepository) FindExpired(ctx context.Context, before time.Time, limit int) ([]*model.Hold, error) {
+	var holds []*model.Hold
+	err := conn(ctx, r.db).
+		Where("status = ? AND expires_at < ?", model.HoldStatusReady, before).
+		Order("expires_at").
+		Limit(limit).
+		Find(&holds).Error
+	return holds, err
+}
+
+// CountReady counts the ready holds on a book of users other than excludeUserID
+func (r *holdRepository) CountReady(ctx context.Context, bookID, excludeUserID uuid.UUID) (int, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.Hold{}).
+		Where("book_id = ? AND status = ? AND user_id <> ?", bookID, model.HoldStatusReady, excludeUserID).
+		Count(&count).Error
+	return int(count), err
+}
+
+// Position returns the place of a waiting hold in its book's queue,
+// starting at 1
+func (r *holdRepository) Position(ctx context.Context, hold *model.Hold) (int, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.Hold{}).
+		Where("book_id = ? AND status = ?", hold.BookID, model.HoldStatusWaiting).
+		Where("created_at < ? OR (created_at = ? AND id < ?)", hold.CreatedAt, hold.CreatedAt, hold.ID).
+		Count(&count).Error
+	return int(count) + 1, err
+}
+
+// Update updates a hold
+func (r *holdRepository) Update(ctx context.Context, hold *model.Hold) error {
+	return conn(ctx, r.db).Save(hold).Error
+}
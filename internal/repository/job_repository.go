@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+// NewJobRepository creates a new job repository
+func NewJobRepository(db *gorm.DB) IJobRepository {
+	return &jobRepository{
+		db: db,
+	}
+}
+
+// Create saves a new job
+func (r *jobRepository) Create(ctx context.Context, job *model.Job) error {
+	return conn(ctx, r.db).Create(job).Error
+}
+
+// FindByID finds a job by ID
+func (r *jobRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	var job model.Job
+	err := conn(ctx, r.db).First(&job, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Update updates a job
+func (r *jobRepository) Update(ctx context.Context, job *model.Job) error {
+	return conn(ctx, r.db).Save(job).Error
+}
+
+// FailUnfinished marks the pending and running jobs of a type created
+// before the given time as failed with reason, returning how many there were
+func (r *jobRepository) FailUnfinished(ctx context.Context, jobType string, before time.Time, reason string) (int64, error) {
+	result := conn(ctx, r.db).Model(&model.Job{}).
+		Where("type = ? AND status IN ? AND created_at < ?", jobType, []string{model.JobStatusPending, model.JobStatusRunning}, before).
+		Updates(map[string]any{
+			"status":      model.JobStatusFailed,
+			"error":       reason,
+			"finished_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type loanRepository struct {
+	db *gorm.DB
+}
+
+// NewLoanRepository creates a new loan repository
+func NewLoanRepository(db *gorm.DB) ILoanRepository {
+	return &loanRepository{
+		db: db,
+	}
+}
+
+// Create saves a new loan
+func (r *loanRepository) Create(ctx context.Context, loan *model.Loan) error {
+	return conn(ctx, r.db).Create(loan).Error
+}
+
+// FindByID finds a loan by ID
+func (r *loanRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Loan, error) {
+	var loan model.Loan
+	err := conn(ctx, r.db).First(&loan, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// FindByIDForUpdate finds a loan by ID and locks its row until the
+// surrounding transaction ends
+func (r *loanRepository) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Loan, error) {
+	var loan model.Loan
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&loan, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// FindActiveByCopyID finds the loan a copy is currently out on
+func (r *loanRepository) FindActiveByCopyID(ctx context.Context, copyID uuid.UUID) (*model.Loan, error) {
+	var loan model.Loan
+	err := conn(ctx, r.db).
+		Where("copy_id = ? AND status = ?", copyID, model.LoanStatusActive).
+		First(&loan).Error
+	if err != nil {
+		return nil, err
+	}
+	return &loan, nil
+}
+
+// FindAll returns a paginated list of loans, newest first
+func (r *loanRepository) FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.Loan, int64, error) {
+	var loans []*model.Loan
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.Loan{})
+	for key, value := range filters {
+		query = query.Where(key, value)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("loaned_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&loans).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return loans, count, nil
+}
+
+// FindOverdue returns up to limit active loans due before the given time,
+// in ID order after afterID, so callers can walk them all in batches
+func (r *loanRepository) FindOverdue(ctx context.Context, before time.Time, afterID uuid.UUID, limit int) ([]*model.Loan, error) {
+	var loans []*model.Loan
+	err := conn(ctx, r.db).
+		Where("status = ? AND due_at < ? AND id > ?", model.LoanStatusActive, before, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&loans).Error
+	return loans, err
+}
+
+// Update updates a loan
+func (r *loanRepository) Update(ctx context.Context, loan *model.Loan) error {
+	return conn(ctx, r.db).Save(loan).Error
+}
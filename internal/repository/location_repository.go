@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type locationRepository struct {
+	db *gorm.DB
+}
+
+// NewLocationRepository creates a new location repository
+func NewLocationRepository(db *gorm.DB) ILocationRepository {
+	return &locationRepository{
+		db: db,
+	}
+}
+
+// Create saves a new location
+func (r *locationRepository) Create(ctx context.Context, location *model.Location) error {
+	return conn(ctx, r.db).Create(location).Error
+}
+
+// FindByID finds a location by ID
+func (r *locationRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Location, error) {
+	var location model.Location
+	err := conn(ctx, r.db).First(&location, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// FindByIDs finds the locations with the given IDs, in no particular order
+func (r *locationRepository) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Location, error) {
+	var locations []*model.Location
+	if len(ids) == 0 {
+		return locations, nil
+	}
+	err := conn(ctx, r.db).Where("id IN ?", ids).Find(&locations).Error
+	return locations, err
+}
+
+// FindAll returns a paginated list of locations
+func (r *locationRepository) FindAll(ctx context.Context, page, pageSize int) ([]*model.Location, int64, error) {
+	var locations []*model.Location
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.Location{})
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("code").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&locations).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return locations, count, nil
+}
+
+// ExistsByCode checks if another location than excludeID uses a code
+func (r *locationRepository) ExistsByCode(ctx context.Context, code string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.Location{}).
+		Where("code = ? AND id <> ?", code, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Update updates a location
+func (r *locationRepository) Update(ctx context.Context, location *model.Location) error {
+	return conn(ctx, r.db).Save(location).Error
+}
+
+// Delete deletes a location by ID
+func (r *locationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&model.Location{}, "id = ?", id).Error
+}
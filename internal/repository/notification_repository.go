@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *gorm.DB) INotificationRepository {
+	return &notificationRepository{
+		db: db,
+	}
+}
+
+// Create saves a new notification
+func (r *notificationRepository) Create(ctx context.Context, notification *model.Notification) error {
+	return conn(ctx, r.db).Create(notification).Error
+}
+
+// FindByUserID returns a paginated list of a user's notifications, newest
+// first, only the unread ones when unreadOnly is set
+func (r *notificationRepository) FindByUserID(ctx context.Context, userID uuid.UUID, unreadOnly bool, page, pageSize int) ([]*model.Notification, int64, error) {
+	var notifications []*model.Notification
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&notifications).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, count, nil
+}
+
+// CountUnread counts the notifications of a user that have not been read
+func (r *notificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks a notification of a user as read at the given time. It
+// reports false when the user has no such notification.
+func (r *notificationRepository) MarkRead(ctx context.Context, id, userID uuid.UUID, at time.Time) (bool, error) {
+	var notification model.Notification
+	err := conn(ctx, r.db).First(&notification, "id = ? AND user_id = ?", id, userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if notification.ReadAt != nil {
+		return true, nil
+	}
+	return true, conn(ctx, r.db).Model(&notification).UpdateColumn("read_at", at).Error
+}
+
+// MarkAllRead marks every unread notification of a user as read at the
+// given time and returns how many there were
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID uuid.UUID, at time.Time) (int64, error) {
+	result := conn(ctx, r.db).Model(&model.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		UpdateColumn("read_at", at)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type orderRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderRepository creates a new order repository
+func NewOrderRepository(db *gorm.DB) IOrderRepository {
+	return &orderRepository{
+		db: db,
+	}
+}
+
+// Create saves a new order together with its items
+func (r *orderRepository) Create(ctx context.Context, order *model.Order) error {
+	return conn(ctx, r.db).Create(order).Error
+}
+
+// FindByID finds an order by ID with its items
+func (r *orderRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Order, error) {
+	var order model.Order
+	err := conn(ctx, r.db).Preload("Items").First(&order, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// FindByIDForUpdate finds an order by ID with its items and locks its row
+// until the surrounding transaction ends
+func (r *orderRepository) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Order, error) {
+	var order model.Order
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Items").
+		First(&order, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// FindAll returns a paginated list of orders with their items, newest first
+func (r *orderRepository) FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.Order, int64, error) {
+	var orders []*model.Order
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.Order{})
+	for key, value := range filters {
+		query = query.Where(key, value)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Preload("Items").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&orders).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return orders, count, nil
+}
+
+// FindExpired returns up to limit orders still pending that were placed
+// before the given time, oldest first
+func (r *orderRepository) FindExpired(ctx context.Context, before time.Time, limit int) ([]*model.Order, error) {
+	var orders []*model.Order
+	err := conn(ctx, r.db).
+		Where("status = ? AND created_at < ?", model.OrderStatusPending, before).
+		Order("created_at").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
+}
+
+// Update saves an order, without touching its items
+func (r *orderRepository) Update(ctx context.Context, order *model.Order) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Save(order).Error
+}
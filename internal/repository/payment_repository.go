@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+// NewPaymentRepository creates a new payment repository
+func NewPaymentRepository(db *gorm.DB) IPaymentRepository {
+	return &paymentRepository{
+		db: db,
+	}
+}
+
+// Create saves a new payment
+func (r *paymentRepository) Create(ctx context.Context, payment *model.Payment) error {
+	return conn(ctx, r.db).Create(payment).Error
+}
+
+// FindByID finds a payment by ID
+func (r *paymentRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Payment, error) {
+	var payment model.Payment
+	err := conn(ctx, r.db).First(&payment, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// FindByIDForUpdate finds a payment by ID and locks its row until the
+// surrounding transaction ends
+func (r *paymentRepository) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Payment, error) {
+	var payment model.Payment
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&payment, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// FindByProviderRef finds a payment by its provider's reference
+func (r *paymentRepository) FindByProviderRef(ctx context.Context, provider, ref string) (*model.Payment, error) {
+	var payment model.Payment
+	err := conn(ctx, r.db).First(&payment, "provider = ? AND provider_ref = ?", provider, ref).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// FindActiveByOrderID finds the payment of an order that is still pending or authorized
+func (r *paymentRepository) FindActiveByOrderID(ctx context.Context, orderID uuid.UUID) (*model.Payment, error) {
+	var payment model.Payment
+	err := conn(ctx, r.db).
+		Where("order_id = ? AND status IN ?", orderID, []string{model.PaymentStatusPending, model.PaymentStatusAuthorized}).
+		Order("created_at DESC").
+		First(&payment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// CountByOrderID counts the payments made for an order, whatever their status
+func (r *paymentRepository) CountByOrderID(ctx context.Context, orderID uuid.UUID) (int64, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.Payment{}).
+		Where("order_id = ?", orderID).
+		Count(&count).Error
+	return count, err
+}
+
+// FindAll returns a paginated list of payments, newest first
+func (r *paymentRepository) FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.Payment, int64, error) {
+	var payments []*model.Payment
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.Payment{})
+	for key, value := range filters {
+		query = query.Where(key, value)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&payments).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return payments, count, nil
+}
+
+// FindStuck returns up to limit payments last updated before the given
+// time that are still pending or authorized, or that were captured for an
+// order since cancelled, oldest first
+func (r *paymentRepository) FindStuck(ctx context.Context, before time.Time, limit int) ([]*model.Payment, error) {
+	var payments []*model.Payment
+	cancelled := conn(ctx, r.db).Model(&model.Order{}).Select("id").Where("status = ?", model.OrderStatusCancelled)
+	err := conn(ctx, r.db).
+		Where("updated_at < ?", before).
+		Where(conn(ctx, r.db).
+			Where("status IN ?", []string{model.PaymentStatusPending, model.PaymentStatusAuthorized}).
+			Or("status = ? AND order_id IN (?)", model.PaymentStatusCaptured, cancelled)).
+		Order("updated_at").
+		Limit(limit).
+		Find(&payments).Error
+	return payments, err
+}
+
+// Update updates a payment
+func (r *paymentRepository) Update(ctx context.Context, payment *model.Payment) error {
+	return conn(ctx, r.db).Save(payment).Error
+}
+
+// RecordEvent saves a processed webhook event. It reports false, without
+// an error, when the event was recorded before.
+func (r *paymentRepository) RecordEvent(ctx context.Context, event *model.PaymentEvent) (bool, error) {
+	result := conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type priceRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewPriceRuleRepository creates a new price rule repository
+func NewPriceRuleRepository(db *gorm.DB) IPriceRuleRepository {
+	return &priceRuleRepository{
+		db: db,
+	}
+}
+
+// Create saves a new price rule
+func (r *priceRuleRepository) Create(ctx context.Context, rule *model.PriceRule) error {
+	return conn(ctx, r.db).Create(rule).Error
+}
+
+// FindByID finds a price rule by ID
+func (r *priceRuleRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.PriceRule, error) {
+	var rule model.PriceRule
+	err := conn(ctx, r.db).First(&rule, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// FindAll returns a paginated list of price rules, newest first
+func (r *priceRuleRepository) FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.PriceRule, int64, error) {
+	var rules []*model.PriceRule
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.PriceRule{})
+	for condition, value := range filters {
+		query = query.Where(condition, value)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&rules).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return rules, count, nil
+}
+
+// FindEffective returns the active price rules whose window contains the given time
+func (r *priceRuleRepository) FindEffective(ctx context.Context, at time.Time) ([]*model.PriceRule, error) {
+	var rules []*model.PriceRule
+	err := conn(ctx, r.db).
+		Where("active = ?", true).
+		Where("starts_at IS NULL OR starts_at <= ?", at).
+		Where("ends_at IS NULL OR ends_at > ?", at).
+		Order("created_at").
+		Find(&rules).Error
+	return rules, err
+}
+
+// Update updates a price rule
+func (r *priceRuleRepository) Update(ctx context.Context, rule *model.PriceRule) error {
+	return conn(ctx, r.db).Save(rule).Error
+}
+
+// Delete deletes a price rule by ID
+func (r *priceRuleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&model.PriceRule{}, "id = ?", id).Error
+}
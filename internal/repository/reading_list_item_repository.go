@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type readingListItemRepository struct {
+	db *gorm.DB
+}
+
+// NewReadingListItemRepository creates a new reading list item repository
+func NewReadingListItemRepository(db *gorm.DB) IReadingListItemRepository {
+	return &readingListItemRepository{
+		db: db,
+	}
+}
+
+// FindByListID returns the books on a reading list in list order
+func (r *readingListItemRepository) FindByListID(ctx context.Context, listID uuid.UUID) ([]*model.ReadingListItem, error) {
+	var items []*model.ReadingListItem
+	err := conn(ctx, r.db).
+		Where("list_id = ?", listID).
+		Order("position, created_at").
+		Find(&items).Error
+	return items, err
+}
+
+// Find finds a book on a reading list
+func (r *readingListItemRepository) Find(ctx context.Context, listID, bookID uuid.UUID) (*model.ReadingListItem, error) {
+	var item model.ReadingListItem
+	err := conn(ctx, r.db).First(&item, "list_id = ? AND book_id = ?", listID, bookID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// CountByListIDs returns how many books are on each of the given reading lists
+func (r *readingListItemRepository) CountByListIDs(ctx context.Context, listIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		ListID uuid.UUID
+		Total  int
+	}
+	err := conn(ctx, r.db).Model(&model.ReadingListItem{}).
+		Select("list_id, COUNT(*) AS total").
+		Where("list_id IN ?", listIDs).
+		Group("list_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		counts[row.ListID] = row.Total
+	}
+	return counts, nil
+}
+
+// MaxPosition returns the position of the last book on a reading list, or
+// 0 when it is empty
+func (r *readingListItemRepository) MaxPosition(ctx context.Context, listID uuid.UUID) (int, error) {
+	var result struct {
+		Position int
+	}
+	err := conn(ctx, r.db).Model(&model.ReadingListItem{}).
+		Select("COALESCE(MAX(position), 0) AS position").
+		Where("list_id = ?", listID).
+		Scan(&result).Error
+	return result.Position, err
+}
+
+// Create puts a book on a reading list
+func (r *readingListItemRepository) Create(ctx context.Context, item *model.ReadingListItem) error {
+	return conn(ctx, r.db).Create(item).Error
+}
+
+// Update updates a book on a reading list
+func (r *readingListItemRepository) Update(ctx context.Context, item *model.ReadingListItem) error {
+	return conn(ctx, r.db).Save(item).Error
+}
+
+// Delete takes a book off the given reading lists
+func (r *readingListItemRepository) Delete(ctx context.Context, listIDs []uuid.UUID, bookID uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&model.ReadingListItem{}, "list_id IN ? AND book_id = ?", listIDs, bookID).Error
+}
+
+// DeleteByListID empties a reading list
+func (r *readingListItemRepository) DeleteByListID(ctx context.Context, listID uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&model.ReadingListItem{}, "list_id = ?", listID).Error
+}
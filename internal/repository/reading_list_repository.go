@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type readingListRepository struct {
+	db *gorm.DB
+}
+
+// NewReadingListRepository creates a new reading list repository
+func NewReadingListRepository(db *gorm.DB) IReadingListRepository {
+	return &readingListRepository{
+		db: db,
+	}
+}
+
+// Create saves a new reading list
+func (r *readingListRepository) Create(ctx context.Context, list *model.ReadingList) error {
+	return conn(ctx, r.db).Create(list).Error
+}
+
+// FindByID finds a reading list by ID
+func (r *readingListRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.ReadingList, error) {
+	var list model.ReadingList
+	err := conn(ctx, r.db).First(&list, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// FindByIDForUpdate finds a reading list by ID and locks its row until
+// the surrounding transaction ends
+func (r *readingListRepository) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.ReadingList, error) {
+	var list model.ReadingList
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&list, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// FindByShareToken finds a shared reading list by its share token
+func (r *readingListRepository) FindByShareToken(ctx context.Context, token string) (*model.ReadingList, error) {
+	var list model.ReadingList
+	err := conn(ctx, r.db).First(&list, "share_token = ?", token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// FindByUserID returns the reading lists of a user, oldest first
+func (r *readingListRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*model.ReadingList, error) {
+	var lists []*model.ReadingList
+	err := conn(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("created_at, name").
+		Find(&lists).Error
+	return lists, err
+}
+
+// ExistsByName checks if a user has another reading list with the given name
+func (r *readingListRepository) ExistsByName(ctx context.Context, userID uuid.UUID, name string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.ReadingList{}).
+		Where("user_id = ? AND name = ? AND id <> ?", userID, name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Update updates a reading list
+func (r *readingListRepository) Update(ctx context.Context, list *model.ReadingList) error {
+	return conn(ctx, r.db).Save(list).Error
+}
+
+// Delete deletes a reading list by ID
+func (r *readingListRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&model.ReadingList{}, "id = ?", id).Error
+}
@@ -3,8 +3,10 @@ package repository
 import (
 	"book_system/internal/model"
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // UserRepository defines the interface for user data operations
@@ -29,6 +31,9 @@ type IUserRepository interface {
 
 	// ExistsByEmail checks if a user with the given email exists
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
+
+	// FindByRole returns every user with a role
+	FindByRole(ctx context.Context, role string) ([]*model.User, error)
 }
 
 // IBookRepository defines the interface for book data operations
@@ -39,15 +44,622 @@ type IBookRepository interface {
 	// FindByID finds a book by ID
 	FindByID(ctx context.Context, id uuid.UUID) (*model.Book, error)
 
-	// FindAll returns a paginated list of books
-	FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.Book, int64, error)
+	// FindAll returns a paginated list of books, in the order of a model.BookSort value if not empty
+	FindAll(ctx context.Context, page, pageSize int, filters map[string]any, sort string) ([]*model.Book, int64, error)
 
-	// Update updates a book
+	// Update saves a book, except its stock and rating, if it is still at book.Version and bumps the version
 	Update(ctx context.Context, book *model.Book) error
 
-	// Delete deletes a book by ID
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Delete soft-deletes a book by ID if it is still at the given version, bumping the version
+	Delete(ctx context.Context, id uuid.UUID, version int) error
 
-	// ExistsByISBN checks if a book with the given ISBN exists
+	// ExistsByISBN checks if a book with the given ISBN, in any form, exists
 	ExistsByISBN(ctx context.Context, isbn string) (bool, error)
+
+	// FindByISBN finds a book by ISBN, in any form
+	FindByISBN(ctx context.Context, isbn string) (*model.Book, error)
+
+	// UpdateISBN rewrites a book's ISBN columns without a new version
+	UpdateISBN(ctx context.Context, id uuid.UUID, isbn13, isbn10 string) error
+
+	// FindInBatches walks the books matching filters in batches of batchSize,
+	// calling fn for each batch until it returns an error
+	FindInBatches(ctx context.Context, filters map[string]any, batchSize int, fn func(books []*model.Book) error) error
+
+	// FindTrashed returns a paginated list of soft-deleted books
+	FindTrashed(ctx context.Context, page, pageSize int) ([]*model.Book, int64, error)
+
+	// FindByIDUnscoped finds a book by ID, whether it is in the trash or not
+	FindByIDUnscoped(ctx context.Context, id uuid.UUID) (*model.Book, error)
+
+	// FindTrashedByID finds a soft-deleted book by ID
+	FindTrashedByID(ctx context.Context, id uuid.UUID) (*model.Book, error)
+
+	// Restore moves a soft-deleted book back out of the trash, bumping its version
+	Restore(ctx context.Context, id uuid.UUID) error
+
+	// Purge permanently deletes a book in the trash, returning
+	// gorm.ErrRecordNotFound when there is no such book in the trash
+	Purge(ctx context.Context, id uuid.UUID) error
+
+	// FindByWorkID returns the editions of a work, oldest first
+	FindByWorkID(ctx context.Context, workID uuid.UUID) ([]*model.Book, error)
+
+	// ExistsByWorkID checks if a work has any editions, including ones in the trash
+	ExistsByWorkID(ctx context.Context, workID uuid.UUID) (bool, error)
+
+	// ExistsByCategoryID checks if any book is filed under a category, including books in the trash
+	ExistsByCategoryID(ctx context.Context, categoryID uuid.UUID) (bool, error)
+
+	// AdjustStock atomically adds delta to a book's stock and returns the new
+	// stock, or ErrInsufficientStock when it would drop below the stock held
+	// at its locations
+	AdjustStock(ctx context.Context, id uuid.UUID, delta int) (int, error)
+
+	// FindByIDs finds the books with the given IDs
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Book, error)
+
+	// SetStock overwrites a book's stock
+	SetStock(ctx context.Context, id uuid.UUID, stock int) error
+
+	// FindByIDForUpdate finds a book by ID and locks it for the surrounding transaction
+	FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Book, error)
+
+	// AdjustRating atomically adds to the sum of stars and count of ratings of a book
+	AdjustRating(ctx context.Context, id uuid.UUID, sumDelta, countDelta int) error
+}
+
+// IStockMovementRepository defines the interface for inventory ledger operations
+type IStockMovementRepository interface {
+	// Create saves a new stock movement
+	Create(ctx context.Context, movement *model.StockMovement) error
+
+	// FindByBookID returns a paginated ledger of a book, newest first
+	FindByBookID(ctx context.Context, bookID uuid.UUID, page, pageSize int) ([]*model.StockMovement, int64, error)
+
+	// SumByBookID returns the stock a book's ledger adds up to and its number of entries
+	SumByBookID(ctx context.Context, bookID uuid.UUID) (int, int64, error)
+
+	// FindByLocationID returns a paginated ledger of a location, newest first
+	FindByLocationID(ctx context.Context, locationID uuid.UUID, page, pageSize int) ([]*model.StockMovement, int64, error)
+
+	// SumByLocation returns the stock a book's ledger adds up to at each location
+	SumByLocation(ctx context.Context, bookID uuid.UUID) (map[uuid.UUID]int, error)
+}
+
+// ILocationRepository defines the interface for store and warehouse operations
+type ILocationRepository interface {
+	// Create saves a new location
+	Create(ctx context.Context, location *model.Location) error
+
+	// FindByID finds a location by ID
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Location, error)
+
+	// FindByIDs finds the locations with the given IDs
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Location, error)
+
+	// FindAll returns a paginated list of locations
+	FindAll(ctx context.Context, page, pageSize int) ([]*model.Location, int64, error)
+
+	// ExistsByCode checks if another location than excludeID uses a code
+	ExistsByCode(ctx context.Context, code string, excludeID uuid.UUID) (bool, error)
+
+	// Update updates a location
+	Update(ctx context.Context, location *model.Location) error
+
+	// Delete deletes a location by ID
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// IStockLevelRepository defines the interface for per-location stock operations
+type IStockLevelRepository interface {
+	// Adjust atomically adds delta to the stock of a book at a location and
+	// returns the new quantity, or ErrInsufficientStock when it would go negative
+	Adjust(ctx context.Context, bookID, locationID uuid.UUID, delta int) (int, error)
+
+	// Set overwrites the stock of a book at a location
+	Set(ctx context.Context, bookID, locationID uuid.UUID, quantity int) error
+
+	// FindByBookID returns the stock levels of a book at every location that has one
+	FindByBookID(ctx context.Context, bookID uuid.UUID) ([]*model.StockLevel, error)
+
+	// FindInStockByLocationID returns a paginated list of the books in stock at a location
+	FindInStockByLocationID(ctx context.Context, locationID uuid.UUID, page, pageSize int) ([]*model.StockLevel, int64, error)
+
+	// ExistsInStockAtLocation checks if any book is in stock at a location
+	ExistsInStockAtLocation(ctx context.Context, locationID uuid.UUID) (bool, error)
+
+	// DeleteByLocationID removes the stock levels recorded at a location
+	DeleteByLocationID(ctx context.Context, locationID uuid.UUID) error
+}
+
+// ICartRepository defines the interface for shopping cart operations
+type ICartRepository interface {
+	// FindByUserID returns the items in a user's cart, oldest first
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*model.CartItem, error)
+
+	// Find finds a book in a user's cart
+	Find(ctx context.Context, userID, bookID uuid.UUID) (*model.CartItem, error)
+
+	// Save creates a cart item or overwrites the quantity of an existing one
+	Save(ctx context.Context, item *model.CartItem) error
+
+	// Delete removes a book from a user's cart
+	Delete(ctx context.Context, userID, bookID uuid.UUID) error
+
+	// DeleteByUserID empties a user's cart
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+// IOrderRepository defines the interface for order data operations
+type IOrderRepository interface {
+	// Create saves a new order together with its items
+	Create(ctx context.Context, order *model.Order) error
+
+	// FindByID finds an order by ID with its items
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Order, error)
+
+	// FindByIDForUpdate finds an order by ID and locks it for the surrounding transaction
+	FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Order, error)
+
+	// FindAll returns a paginated list of orders, newest first
+	FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.Order, int64, error)
+
+	// FindExpired returns up to limit orders still pending that were placed before the given time
+	FindExpired(ctx context.Context, before time.Time, limit int) ([]*model.Order, error)
+
+	// Update saves an order, without touching its items
+	Update(ctx context.Context, order *model.Order) error
+}
+
+// IPaymentRepository defines the interface for payment data operations
+type IPaymentRepository interface {
+	// Create saves a new payment
+	Create(ctx context.Context, payment *model.Payment) error
+
+	// FindByID finds a payment by ID
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Payment, error)
+
+	// FindByIDForUpdate finds a payment by ID and locks it for the surrounding transaction
+	FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Payment, error)
+
+	// FindByProviderRef finds a payment by its provider's reference
+	FindByProviderRef(ctx context.Context, provider, ref string) (*model.Payment, error)
+
+	// FindActiveByOrderID finds the payment of an order that is still pending or authorized
+	FindActiveByOrderID(ctx context.Context, orderID uuid.UUID) (*model.Payment, error)
+
+	// CountByOrderID counts the payments made for an order, whatever their status
+	CountByOrderID(ctx context.Context, orderID uuid.UUID) (int64, error)
+
+	// FindAll returns a paginated list of payments, newest first
+	FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.Payment, int64, error)
+
+	// FindStuck returns up to limit unfinished payments, or payments captured
+	// for a cancelled order, last updated before the given time
+	FindStuck(ctx context.Context, before time.Time, limit int) ([]*model.Payment, error)
+
+	// Update updates a payment
+	Update(ctx context.Context, payment *model.Payment) error
+
+	// RecordEvent saves a processed webhook event, reporting false if it was recorded before
+	RecordEvent(ctx context.Context, event *model.PaymentEvent) (bool, error)
+}
+
+// IPriceRuleRepository defines the interface for price rule data operations
+type IPriceRuleRepository interface {
+	// Create saves a new price rule
+	Create(ctx context.Context, rule *model.PriceRule) error
+
+	// FindByID finds a price rule by ID
+	FindByID(ctx context.Context, id uuid.UUID) (*model.PriceRule, error)
+
+	// FindAll returns a paginated list of price rules, newest first
+	FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.PriceRule, int64, error)
+
+	// FindEffective returns the active price rules whose window contains the given time
+	FindEffective(ctx context.Context, at time.Time) ([]*model.PriceRule, error)
+
+	// Update updates a price rule
+	Update(ctx context.Context, rule *model.PriceRule) error
+
+	// Delete deletes a price rule by ID
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// ICouponRepository defines the interface for coupon data operations
+type ICouponRepository interface {
+	// Create saves a new coupon
+	Create(ctx context.Context, coupon *model.Coupon) error
+
+	// FindByID finds a coupon by ID
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Coupon, error)
+
+	// FindByCode finds a coupon by code
+	FindByCode(ctx context.Context, code string) (*model.Coupon, error)
+
+	// FindAll returns a paginated list of coupons, newest first
+	FindAll(ctx context.Context, page, pageSize int) ([]*model.Coupon, int64, error)
+
+	// ExistsByCode checks if another coupon than excludeID uses a code
+	ExistsByCode(ctx context.Context, code string, excludeID uuid.UUID) (bool, error)
+
+	// Redeem counts a use of a coupon, reporting false if it has no uses left
+	Redeem(ctx context.Context, id uuid.UUID) (bool, error)
+
+	// Release gives back a use of a coupon
+	Release(ctx context.Context, id uuid.UUID) error
+
+	// Update updates a coupon, leaving its use count alone
+	Update(ctx context.Context, coupon *model.Coupon) error
+
+	// Delete deletes a coupon by ID
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// IExchangeRateRepository defines the interface for exchange rate snapshots
+type IExchangeRateRepository interface {
+	// Create saves new exchange rate snapshots
+	Create(ctx context.Context, rates []*model.ExchangeRate) error
+
+	// FindAll returns a paginated history of exchange rates, latest first
+	FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.ExchangeRate, int64, error)
+
+	// FindLatest returns the newest snapshot of every currency pair in effect at the given time
+	FindLatest(ctx context.Context, at time.Time) ([]*model.ExchangeRate, error)
+}
+
+// IBookPriceRepository defines the interface for per-currency list prices
+type IBookPriceRepository interface {
+	// FindByBookID returns the list prices of a book by currency code order
+	FindByBookID(ctx context.Context, bookID uuid.UUID) ([]*model.BookPrice, error)
+
+	// FindByBookIDs returns the list prices in a currency of the given books
+	FindByBookIDs(ctx context.Context, bookIDs []uuid.UUID, currency string) ([]*model.BookPrice, error)
+
+	// Save creates a list price or overwrites the amount of an existing one
+	Save(ctx context.Context, price *model.BookPrice) error
+
+	// Delete removes the list price of a book in a currency, reporting false if there was none
+	Delete(ctx context.Context, bookID uuid.UUID, currency string) (bool, error)
+}
+
+// ICopyRepository defines the interface for data operations on physical copies
+type ICopyRepository interface {
+	// Create saves a new copy
+	Create(ctx context.Context, bookCopy *model.Copy) error
+
+	// FindByID finds a copy by ID
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Copy, error)
+
+	// FindByBarcodeForUpdate finds a copy by barcode and locks it for the surrounding transaction
+	FindByBarcodeForUpdate(ctx context.Context, barcode string) (*model.Copy, error)
+
+	// FindAll returns a paginated list of copies ordered by barcode
+	FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.Copy, int64, error)
+
+	// ExistsByBarcode checks if another copy than excludeID uses a barcode
+	ExistsByBarcode(ctx context.Context, barcode string, excludeID uuid.UUID) (bool, error)
+
+	// Update updates a copy
+	Update(ctx context.Context, bookCopy *model.Copy) error
+
+	// Delete deletes a copy by ID
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// ILoanRepository defines the interface for loan data operations
+type ILoanRepository interface {
+	// Create saves a new loan
+	Create(ctx context.Context, loan *model.Loan) error
+
+	// FindByID finds a loan by ID
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Loan, error)
+
+	// FindByIDForUpdate finds a loan by ID and locks it for the surrounding transaction
+	FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Loan, error)
+
+	// FindActiveByCopyID finds the loan a copy is currently out on
+	FindActiveByCopyID(ctx context.Context, copyID uuid.UUID) (*model.Loan, error)
+
+	// FindAll returns a paginated list of loans, newest first
+	FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.Loan, int64, error)
+
+	// FindOverdue returns up to limit active loans due before the given time, in ID order after afterID
+	FindOverdue(ctx context.Context, before time.Time, afterID uuid.UUID, limit int) ([]*model.Loan, error)
+
+	// Update updates a loan
+	Update(ctx context.Context, loan *model.Loan) error
+}
+
+// IFinePolicyRepository defines the interface for fine policy data operations
+type IFinePolicyRepository interface {
+	// Create saves a new fine policy
+	Create(ctx context.Context, policy *model.FinePolicy) error
+
+	// FindByID finds a fine policy by ID
+	FindByID(ctx context.Context, id uuid.UUID) (*model.FinePolicy, error)
+
+	// FindAll returns every fine policy ordered by role
+	FindAll(ctx context.Context) ([]*model.FinePolicy, error)
+
+	// ExistsByRole checks if another fine policy than excludeID is for a role
+	ExistsByRole(ctx context.Context, role string, excludeID uuid.UUID) (bool, error)
+
+	// Update updates a fine policy
+	Update(ctx context.Context, policy *model.FinePolicy) error
+
+	// Delete deletes a fine policy by ID
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// IAccountEntryRepository defines the interface for member balance ledger operations
+type IAccountEntryRepository interface {
+	// Create saves a new ledger entry
+	Create(ctx context.Context, entry *model.AccountEntry) error
+
+	// FindByUserID returns a paginated ledger of a member, newest first
+	FindByUserID(ctx context.Context, userID uuid.UUID, page, pageSize int) ([]*model.AccountEntry, int64, error)
+
+	// Balance returns the sum of a member's ledger entries
+	Balance(ctx context.Context, userID uuid.UUID) (decimal.Decimal, error)
+}
+
+// IHoldRepository defines the interface for hold data operations
+type IHoldRepository interface {
+	// Create saves a new hold
+	Create(ctx context.Context, hold *model.Hold) error
+
+	// FindByID finds a hold by ID
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Hold, error)
+
+	// FindByIDForUpdate finds a hold by ID and locks it for the surrounding transaction
+	FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Hold, error)
+
+	// FindActive finds the waiting or ready hold a user has on a book and locks it for the surrounding transaction
+	FindActive(ctx context.Context, bookID, userID uuid.UUID) (*model.Hold, error)
+
+	// FindAll returns a paginated list of holds in the order they were placed
+	FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.Hold, int64, error)
+
+	// FindNextWaiting returns up to limit waiting holds at the front of a book's queue and locks them
+	FindNextWaiting(ctx context.Context, bookID uuid.UUID, limit int) ([]*model.Hold, error)
+
+	// FindExpired returns up to limit ready holds whose pickup window ended before the given time
+	FindExpired(ctx context.Context, before time.Time, limit int) ([]*model.Hold, error)
+
+	// CountReady counts the ready holds on a book of users other than excludeUserID
+	CountReady(ctx context.Context, bookID, excludeUserID uuid.UUID) (int, error)
+
+	// Position returns the place of a waiting hold in its book's queue, starting at 1
+	Position(ctx context.Context, hold *model.Hold) (int, error)
+
+	// Update updates a hold
+	Update(ctx context.Context, hold *model.Hold) error
+}
+
+// INotificationRepository defines the interface for notification data operations
+type INotificationRepository interface {
+	// Create saves a new notification
+	Create(ctx context.Context, notification *model.Notification) error
+
+	// FindByUserID returns a paginated list of a user's notifications, newest first, optionally only the unread ones
+	FindByUserID(ctx context.Context, userID uuid.UUID, unreadOnly bool, page, pageSize int) ([]*model.Notification, int64, error)
+
+	// CountUnread counts the notifications of a user that have not been read
+	CountUnread(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// MarkRead marks a notification of a user as read, reporting false if the user has no such notification
+	MarkRead(ctx context.Context, id, userID uuid.UUID, at time.Time) (bool, error)
+
+	// MarkAllRead marks every unread notification of a user as read and returns how many there were
+	MarkAllRead(ctx context.Context, userID uuid.UUID, at time.Time) (int64, error)
+}
+
+// IReviewRepository defines the interface for review data operations
+type IReviewRepository interface {
+	// Create saves a new review
+	Create(ctx context.Context, review *model.Review) error
+
+	// FindByID finds a review by ID
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Review, error)
+
+	// FindByIDForUpdate finds a review by ID and locks it for the surrounding transaction
+	FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Review, error)
+
+	// FindByBookAndUser finds the review a user wrote of a book
+	FindByBookAndUser(ctx context.Context, bookID, userID uuid.UUID) (*model.Review, error)
+
+	// FindAll returns a paginated list of reviews, newest first
+	FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.Review, int64, error)
+
+	// Update updates a review
+	Update(ctx context.Context, review *model.Review) error
+
+	// Delete deletes a review by ID
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// IReadingListRepository defines the interface for reading list data operations
+type IReadingListRepository interface {
+	// Create saves a new reading list
+	Create(ctx context.Context, list *model.ReadingList) error
+
+	// FindByID finds a reading list by ID
+	FindByID(ctx context.Context, id uuid.UUID) (*model.ReadingList, error)
+
+	// FindByIDForUpdate finds a reading list by ID and locks it for the surrounding transaction
+	FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.ReadingList, error)
+
+	// FindByShareToken finds a shared reading list by its share token
+	FindByShareToken(ctx context.Context, token string) (*model.ReadingList, error)
+
+	// FindByUserID returns the reading lists of a user, oldest first
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*model.ReadingList, error)
+
+	// ExistsByName checks if a user has another reading list with the given name
+	ExistsByName(ctx context.Context, userID uuid.UUID, name string, excludeID uuid.UUID) (bool, error)
+
+	// Update updates a reading list
+	Update(ctx context.Context, list *model.ReadingList) error
+
+	// Delete deletes a reading list by ID
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// IReadingListItemRepository defines the interface for the books on reading lists
+type IReadingListItemRepository interface {
+	// FindByListID returns the books on a reading list in list order
+	FindByListID(ctx context.Context, listID uuid.UUID) ([]*model.ReadingListItem, error)
+
+	// Find finds a book on a reading list
+	Find(ctx context.Context, listID, bookID uuid.UUID) (*model.ReadingListItem, error)
+
+	// CountByListIDs returns how many books are on each of the given reading lists
+	CountByListIDs(ctx context.Context, listIDs []uuid.UUID) (map[uuid.UUID]int, error)
+
+	// MaxPosition returns the position of the last book on a reading list, or 0 when it is empty
+	MaxPosition(ctx context.Context, listID uuid.UUID) (int, error)
+
+	// Create puts a book on a reading list
+	Create(ctx context.Context, item *model.ReadingListItem) error
+
+	// Update updates a book on a reading list
+	Update(ctx context.Context, item *model.ReadingListItem) error
+
+	// Delete takes a book off the given reading lists
+	Delete(ctx context.Context, listIDs []uuid.UUID, bookID uuid.UUID) error
+
+	// DeleteByListID empties a reading list
+	DeleteByListID(ctx context.Context, listID uuid.UUID) error
+}
+
+// IBookViewRepository defines the interface for recorded book detail views
+type IBookViewRepository interface {
+	// Record counts a view of a book by a user
+	Record(ctx context.Context, userID, bookID uuid.UUID, at time.Time) error
+
+	// FindByUserID returns the books a user viewed most recently, up to limit
+	FindByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*model.BookView, error)
+
+	// FindRecentByUserInBatches walks the users who viewed books in batches
+	// of batchSize users, calling fn with the views of each batch until it
+	// returns an error. A user's views of live books come together, most
+	// recent first, and only the perUser most recent of them.
+	FindRecentByUserInBatches(ctx context.Context, perUser, batchSize int, fn func(views []*model.BookView) error) error
+
+	// MostViewed returns the IDs of the books viewed by the most users, up to limit
+	MostViewed(ctx context.Context, limit int) ([]uuid.UUID, error)
+}
+
+// IBookSimilarityRepository defines the interface for computed book similarities
+type IBookSimilarityRepository interface {
+	// FindByBookID returns the books most similar to a book, up to limit
+	FindByBookID(ctx context.Context, bookID uuid.UUID, limit int) ([]*model.BookSimilarity, error)
+
+	// FindByBookIDs returns the similar books of each of the given books
+	FindByBookIDs(ctx context.Context, bookIDs []uuid.UUID) ([]*model.BookSimilarity, error)
+
+	// Replace overwrites the similar books of a book
+	Replace(ctx context.Context, bookID uuid.UUID, similarities []*model.BookSimilarity) error
+
+	// DeleteBefore deletes the similarities last computed before the given time
+	DeleteBefore(ctx context.Context, before time.Time) error
+}
+
+// IWorkRepository defines the interface for work data operations
+type IWorkRepository interface {
+	// Create saves a new work
+	Create(ctx context.Context, work *model.Work) error
+
+	// FindByID finds a work by ID
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Work, error)
+
+	// FindAll returns a paginated list of works
+	FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.Work, int64, error)
+
+	// FindBySeriesID returns the volumes of a series in reading order
+	FindBySeriesID(ctx context.Context, seriesID uuid.UUID) ([]*model.Work, error)
+
+	// ExistsInSeries checks if another work than excludeID holds a position in a series
+	ExistsInSeries(ctx context.Context, seriesID uuid.UUID, position float64, excludeID uuid.UUID) (bool, error)
+
+	// Update updates a work
+	Update(ctx context.Context, work *model.Work) error
+
+	// Delete deletes a work by ID
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// DetachSeries takes every volume out of a series
+	DetachSeries(ctx context.Context, seriesID uuid.UUID) error
+}
+
+// ISeriesRepository defines the interface for series data operations
+type ISeriesRepository interface {
+	// Create saves a new series
+	Create(ctx context.Context, series *model.Series) error
+
+	// FindByID finds a series by ID
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Series, error)
+
+	// FindAll returns a paginated list of series
+	FindAll(ctx context.Context, page, pageSize int) ([]*model.Series, int64, error)
+
+	// Update updates a series
+	Update(ctx context.Context, series *model.Series) error
+
+	// Delete deletes a series by ID
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// ICategoryRepository defines the interface for category data operations
+type ICategoryRepository interface {
+	// Create saves a new category
+	Create(ctx context.Context, category *model.Category) error
+
+	// FindByID finds a category by ID
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Category, error)
+
+	// FindByIDs finds the categories with the given IDs, in no particular order
+	FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Category, error)
+
+	// FindAll returns a paginated list of categories
+	FindAll(ctx context.Context, page, pageSize int) ([]*model.Category, int64, error)
+
+	// ExistsByName checks if a category other than excludeID has a name
+	ExistsByName(ctx context.Context, name string, excludeID uuid.UUID) (bool, error)
+
+	// Update updates a category
+	Update(ctx context.Context, category *model.Category) error
+
+	// Delete deletes a category by ID
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// IBookVersionRepository defines the interface for book history operations
+type IBookVersionRepository interface {
+	// Create saves a new book version
+	Create(ctx context.Context, version *model.BookVersion) error
+
+	// FindByBookID returns a paginated history of a book, newest first
+	FindByBookID(ctx context.Context, bookID uuid.UUID, page, pageSize int) ([]*model.BookVersion, int64, error)
+
+	// FindByVersion finds a single version of a book
+	FindByVersion(ctx context.Context, bookID uuid.UUID, version int) (*model.BookVersion, error)
+}
+
+// IJobRepository defines the interface for background job operations
+type IJobRepository interface {
+	// Create saves a new job
+	Create(ctx context.Context, job *model.Job) error
+
+	// FindByID finds a job by ID
+	FindByID(ctx context.Context, id uuid.UUID) (*model.Job, error)
+
+	// Update updates a job
+	Update(ctx context.Context, job *model.Job) error
+
+	// FailUnfinished marks the pending and running jobs of a type created
+	// before the given time as failed with reason, returning how many there were
+	FailUnfinished(ctx context.Context, jobType string, before time.Time, reason string) (int64, error)
 }
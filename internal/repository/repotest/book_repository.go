@@ -0,0 +1,306 @@
+package repotest
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errFilters is returned by the queries taking SQL filters, which the
+// in-memory repositories cannot evaluate
+var errFilters = errors.New("repotest: SQL filters are not supported")
+
+// Books is an in-memory book repository. Books added with NewBooks or Put
+// are kept by pointer and changed in place, so a test can check them
+// directly; lookups return copies.
+type Books struct {
+	mu    sync.Mutex
+	books map[uuid.UUID]*model.Book
+}
+
+var _ repository.IBookRepository = (*Books)(nil)
+
+// NewBooks creates a book repository holding books
+func NewBooks(books ...*model.Book) *Books {
+	r := &Books{books: make(map[uuid.UUID]*model.Book)}
+	for _, book := range books {
+		r.Put(book)
+	}
+	return r
+}
+
+// Put stores a book as is, replacing any book with its ID
+func (r *Books) Put(book *model.Book) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.books[book.ID] = book
+}
+
+// Get returns the stored book with the given ID, in the trash or not, or nil
+func (r *Books) Get(id uuid.UUID) *model.Book {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.books[id]
+}
+
+// find returns a copy of a book, found the way the trash scope asks
+func (r *Books) find(id uuid.UUID, live, trashed bool) (*model.Book, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	book, ok := r.books[id]
+	if !ok || (book.DeletedAt.Valid && !trashed) || (!book.DeletedAt.Valid && !live) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *book
+	return &copied, nil
+}
+
+// live returns copies of the books not in the trash matching keep, oldest first
+func (r *Books) live(keep func(book *model.Book) bool) []*model.Book {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var books []*model.Book
+	for _, book := range r.books {
+		if !book.DeletedAt.Valid && keep(book) {
+			copied := *book
+			books = append(books, &copied)
+		}
+	}
+	sort.Slice(books, func(i, j int) bool { return books[i].CreatedAt.Before(books[j].CreatedAt) })
+	return books
+}
+
+func (r *Books) Create(ctx context.Context, book *model.Book) error {
+	if book.ID == uuid.Nil {
+		book.ID = uuid.New()
+	}
+	if book.Version == 0 {
+		book.Version = 1
+	}
+	copied := *book
+	r.Put(&copied)
+	return nil
+}
+
+func (r *Books) FindByID(ctx context.Context, id uuid.UUID) (*model.Book, error) {
+	return r.find(id, true, false)
+}
+
+func (r *Books) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Book, error) {
+	return r.find(id, true, false)
+}
+
+func (r *Books) FindByIDUnscoped(ctx context.Context, id uuid.UUID) (*model.Book, error) {
+	return r.find(id, true, true)
+}
+
+func (r *Books) FindTrashedByID(ctx context.Context, id uuid.UUID) (*model.Book, error) {
+	return r.find(id, false, true)
+}
+
+func (r *Books) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Book, error) {
+	var books []*model.Book
+	for _, id := range ids {
+		if book, err := r.FindByID(ctx, id); err == nil {
+			books = append(books, book)
+		}
+	}
+	return books, nil
+}
+
+// FindAll lists the books oldest first. Sorting by rating is supported;
+// filters are not.
+func (r *Books) FindAll(ctx context.Context, page, pageSize int, filters map[string]any, order string) ([]*model.Book, int64, error) {
+	if len(filters) > 0 {
+		return nil, 0, errFilters
+	}
+	books := r.live(func(*model.Book) bool { return true })
+	switch order {
+	case model.BookSortRating:
+		sort.SliceStable(books, func(i, j int) bool { return books[i].Rating() > books[j].Rating() })
+	case model.BookSortReviews:
+		sort.SliceStable(books, func(i, j int) bool { return books[i].RatingCount > books[j].RatingCount })
+	}
+	total := int64(len(books))
+	start := min((page-1)*pageSize, len(books))
+	return books[start:min(start+pageSize, len(books))], total, nil
+}
+
+// FindInBatches walks the books oldest first; filters are not supported
+func (r *Books) FindInBatches(ctx context.Context, filters map[string]any, batchSize int, fn func(books []*model.Book) error) error {
+	if len(filters) > 0 {
+		return errFilters
+	}
+	books := r.live(func(*model.Book) bool { return true })
+	for start := 0; start < len(books); start += batchSize {
+		if err := fn(books[start:min(start+batchSize, len(books))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Books) FindTrashed(ctx context.Context, page, pageSize int) ([]*model.Book, int64, error) {
+	r.mu.Lock()
+	var books []*model.Book
+	for _, book := range r.books {
+		if book.DeletedAt.Valid {
+			copied := *book
+			books = append(books, &copied)
+		}
+	}
+	r.mu.Unlock()
+	sort.Slice(books, func(i, j int) bool { return books[i].DeletedAt.Time.After(books[j].DeletedAt.Time) })
+	total := int64(len(books))
+	start := min((page-1)*pageSize, len(books))
+	return books[start:min(start+pageSize, len(books))], total, nil
+}
+
+// Update saves a book, except its stock and rating, if it is still at
+// book.Version, and bumps the version
+func (r *Books) Update(ctx context.Context, book *model.Book) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.books[book.ID]
+	if !ok || stored.DeletedAt.Valid || stored.Version != book.Version {
+		return repository.ErrVersionConflict
+	}
+	book.Version++
+	stock, ratingSum, ratingCount, createdAt := stored.Stock, stored.RatingSum, stored.RatingCount, stored.CreatedAt
+	*stored = *book
+	stored.Stock, stored.RatingSum, stored.RatingCount, stored.CreatedAt = stock, ratingSum, ratingCount, createdAt
+	return nil
+}
+
+func (r *Books) Delete(ctx context.Context, id uuid.UUID, version int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	book, ok := r.books[id]
+	if !ok || book.DeletedAt.Valid || book.Version != version {
+		return repository.ErrVersionConflict
+	}
+	book.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	book.Version++
+	return nil
+}
+
+func (r *Books) Restore(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	book, ok := r.books[id]
+	if !ok || !book.DeletedAt.Valid {
+		return gorm.ErrRecordNotFound
+	}
+	book.DeletedAt = gorm.DeletedAt{}
+	book.Version++
+	return nil
+}
+
+// Purge permanently deletes a book in the trash
+func (r *Books) Purge(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if book, ok := r.books[id]; !ok || !book.DeletedAt.Valid {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.books, id)
+	return nil
+}
+
+// ExistsByISBN checks the ISBN as given against the live books
+func (r *Books) ExistsByISBN(ctx context.Context, isbn string) (bool, error) {
+	_, err := r.FindByISBN(ctx, isbn)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// FindByISBN finds a live book by its ISBN as given
+func (r *Books) FindByISBN(ctx context.Context, isbn string) (*model.Book, error) {
+	books := r.live(func(book *model.Book) bool { return book.ISBN == isbn })
+	if len(books) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return books[0], nil
+}
+
+func (r *Books) UpdateISBN(ctx context.Context, id uuid.UUID, isbn13, isbn10 string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if book, ok := r.books[id]; ok {
+		book.ISBN, book.ISBN10 = isbn13, isbn10
+	}
+	return nil
+}
+
+func (r *Books) FindByWorkID(ctx context.Context, workID uuid.UUID) ([]*model.Book, error) {
+	books := r.live(func(book *model.Book) bool { return book.WorkID != nil && *book.WorkID == workID })
+	sort.SliceStable(books, func(i, j int) bool { return books[i].PublishedAt.Before(books[j].PublishedAt) })
+	return books, nil
+}
+
+// ExistsByWorkID checks the books in the trash too
+func (r *Books) ExistsByWorkID(ctx context.Context, workID uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, book := range r.books {
+		if book.WorkID != nil && *book.WorkID == workID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ExistsByCategoryID checks the books in the trash too
+func (r *Books) ExistsByCategoryID(ctx context.Context, categoryID uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, book := range r.books {
+		if book.CategoryID != nil && *book.CategoryID == categoryID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// AdjustStock adds delta to a book's stock, refusing to take it below zero
+func (r *Books) AdjustStock(ctx context.Context, id uuid.UUID, delta int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	book, ok := r.books[id]
+	if !ok || book.DeletedAt.Valid {
+		return 0, gorm.ErrRecordNotFound
+	}
+	if book.Stock+delta < 0 {
+		return book.Stock, repository.ErrInsufficientStock
+	}
+	book.Stock += delta
+	return book.Stock, nil
+}
+
+func (r *Books) SetStock(ctx context.Context, id uuid.UUID, stock int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if book, ok := r.books[id]; ok {
+		book.Stock = stock
+	}
+	return nil
+}
+
+func (r *Books) AdjustRating(ctx context.Context, id uuid.UUID, sumDelta, countDelta int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if book, ok := r.books[id]; ok {
+		book.RatingSum += sumDelta
+		book.RatingCount += countDelta
+	}
+	return nil
+}
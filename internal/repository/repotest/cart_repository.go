@@ -0,0 +1,90 @@
+package repotest
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type cartKey struct {
+	userID, bookID uuid.UUID
+}
+
+// Carts is an in-memory cart repository. Unlike Books, it keeps copies of
+// the items it is given.
+type Carts struct {
+	mu    sync.Mutex
+	items map[cartKey]model.CartItem
+}
+
+var _ repository.ICartRepository = (*Carts)(nil)
+
+// NewCarts creates a cart repository holding items
+func NewCarts(items ...*model.CartItem) *Carts {
+	r := &Carts{items: make(map[cartKey]model.CartItem)}
+	for _, item := range items {
+		_ = r.Save(context.Background(), item)
+	}
+	return r
+}
+
+func (r *Carts) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*model.CartItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var items []*model.CartItem
+	for key, item := range r.items {
+		if key.userID == userID {
+			copied := item
+			items = append(items, &copied)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+	return items, nil
+}
+
+func (r *Carts) Find(ctx context.Context, userID, bookID uuid.UUID) (*model.CartItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item, ok := r.items[cartKey{userID, bookID}]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &item, nil
+}
+
+func (r *Carts) Save(ctx context.Context, item *model.CartItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	item.UpdatedAt = time.Now()
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = item.UpdatedAt
+	}
+	r.items[cartKey{item.UserID, item.BookID}] = *item
+	return nil
+}
+
+func (r *Carts) Delete(ctx context.Context, userID, bookID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.items, cartKey{userID, bookID})
+	return nil
+}
+
+func (r *Carts) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key := range r.items {
+		if key.userID == userID {
+			delete(r.items, key)
+		}
+	}
+	return nil
+}
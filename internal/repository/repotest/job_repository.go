@@ -0,0 +1,92 @@
+package repotest
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Jobs is an in-memory job repository. Like Books, it keeps the jobs it is
+// given by pointer and returns copies.
+type Jobs struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]*model.Job
+}
+
+var _ repository.IJobRepository = (*Jobs)(nil)
+
+// NewJobs creates a job repository holding jobs
+func NewJobs(jobs ...*model.Job) *Jobs {
+	r := &Jobs{jobs: make(map[uuid.UUID]*model.Job)}
+	for _, job := range jobs {
+		r.Put(job)
+	}
+	return r
+}
+
+// Put stores a job as is, replacing any job with its ID
+func (r *Jobs) Put(job *model.Job) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[job.ID] = job
+}
+
+// Get returns a copy of the stored job with id, or nil
+func (r *Jobs) Get(id uuid.UUID) *model.Job {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil
+	}
+	copied := *job
+	return &copied
+}
+
+func (r *Jobs) Create(ctx context.Context, job *model.Job) error {
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now()
+	}
+	copied := *job
+	r.Put(&copied)
+	return nil
+}
+
+func (r *Jobs) FindByID(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	if job := r.Get(id); job != nil {
+		return job, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *Jobs) Update(ctx context.Context, job *model.Job) error {
+	copied := *job
+	r.Put(&copied)
+	return nil
+}
+
+func (r *Jobs) FailUnfinished(ctx context.Context, jobType string, before time.Time, reason string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var failed int64
+	now := time.Now()
+	for _, job := range r.jobs {
+		if job.Type != jobType || !job.CreatedAt.Before(before) ||
+			(job.Status != model.JobStatusPending && job.Status != model.JobStatusRunning) {
+			continue
+		}
+		job.Status = model.JobStatusFailed
+		job.Error = reason
+		job.FinishedAt = &now
+		failed++
+	}
+	return failed, nil
+}
@@ -0,0 +1,31 @@
+// Package repotest provides in-memory implementations of the repositories
+// shared by the service tests. They implement the whole repository
+// interface, so a service calling a method a test did not plan for gets
+// an answer rather than a nil-interface panic.
+package repotest
+
+import (
+	"context"
+	"sync"
+)
+
+// Transactor runs transactions directly, without isolation
+type Transactor struct{}
+
+// WithinTransaction runs fn
+func (Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// LockingTransactor runs one transaction at a time, as a row lock taken
+// on a single row would
+type LockingTransactor struct {
+	mu sync.Mutex
+}
+
+// WithinTransaction runs fn once no other transaction is running
+func (t *LockingTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return fn(ctx)
+}
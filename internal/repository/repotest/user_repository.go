@@ -0,0 +1,119 @@
+package repotest
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Users is an in-memory user repository. Like Books, it keeps the users
+// it is given by pointer and returns copies.
+type Users struct {
+	mu    sync.Mutex
+	users map[uuid.UUID]*model.User
+}
+
+var _ repository.IUserRepository = (*Users)(nil)
+
+// NewUsers creates a user repository holding users
+func NewUsers(users ...*model.User) *Users {
+	r := &Users{users: make(map[uuid.UUID]*model.User)}
+	for _, user := range users {
+		r.Put(user)
+	}
+	return r
+}
+
+// Put stores a user as is, replacing any user with its ID
+func (r *Users) Put(user *model.User) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.users[user.ID] = user
+}
+
+func (r *Users) Create(ctx context.Context, user *model.User) error {
+	if user.ID == uuid.Nil {
+		user.ID = uuid.New()
+	}
+	copied := *user
+	r.Put(&copied)
+	return nil
+}
+
+func (r *Users) FindByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *Users) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// FindAll lists the users oldest first
+func (r *Users) FindAll(ctx context.Context, page, pageSize int) ([]*model.User, int64, error) {
+	r.mu.Lock()
+	var users []*model.User
+	for _, user := range r.users {
+		copied := *user
+		users = append(users, &copied)
+	}
+	r.mu.Unlock()
+	sort.Slice(users, func(i, j int) bool { return users[i].CreatedAt.Before(users[j].CreatedAt) })
+	start := min((page-1)*pageSize, len(users))
+	return users[start:min(start+pageSize, len(users))], int64(len(users)), nil
+}
+
+func (r *Users) Update(ctx context.Context, user *model.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.users[user.ID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	*stored = *user
+	return nil
+}
+
+func (r *Users) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.users, id)
+	return nil
+}
+
+func (r *Users) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	_, err := r.FindByEmail(ctx, email)
+	return err == nil, nil
+}
+
+func (r *Users) FindByRole(ctx context.Context, role string) ([]*model.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var users []*model.User
+	for _, user := range r.users {
+		if user.Role == role {
+			copied := *user
+			users = append(users, &copied)
+		}
+	}
+	return users, nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+// NewReviewRepository creates a new review repository
+func NewReviewRepository(db *gorm.DB) IReviewRepository {
+	return &reviewRepository{
+		db: db,
+	}
+}
+
+// Create saves a new review
+func (r *reviewRepository) Create(ctx context.Context, review *model.Review) error {
+	return conn(ctx, r.db).Create(review).Error
+}
+
+// FindByID finds a review by ID
+func (r *reviewRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Review, error) {
+	var review model.Review
+	err := conn(ctx, r.db).First(&review, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// FindByIDForUpdate finds a review by ID and locks its row until the
+// surrounding transaction ends
+func (r *reviewRepository) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Review, error) {
+	var review model.Review
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&review, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// FindByBookAndUser finds the review a user wrote of a book
+func (r *reviewRepository) FindByBookAndUser(ctx context.Context, bookID, userID uuid.UUID) (*model.Review, error) {
+	var review model.Review
+	err := conn(ctx, r.db).
+		Where("book_id = ? AND user_id = ?", bookID, userID).
+		First(&review).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// FindAll returns a paginated list of reviews, newest first
+func (r *reviewRepository) FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.Review, int64, error) {
+	var reviews []*model.Review
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.Review{})
+	for key, value := range filters {
+		query = query.Where(key, value)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&reviews).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return reviews, count, nil
+}
+
+// Update updates a review
+func (r *reviewRepository) Update(ctx context.Context, review *model.Review) error {
+	return conn(ctx, r.db).Save(review).Error
+}
+
+// Delete deletes a review by ID
+func (r *reviewRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&model.Review{}, "id = ?", id).Error
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type seriesRepository struct {
+	db *gorm.DB
+}
+
+// NewSeriesRepository creates a new series repository
+func NewSeriesRepository(db *gorm.DB) ISeriesRepository {
+	return &seriesRepository{
+		db: db,
+	}
+}
+
+// Create saves a new series
+func (r *seriesRepository) Create(ctx context.Context, series *model.Series) error {
+	return conn(ctx, r.db).Create(series).Error
+}
+
+// FindByID finds a series by ID
+func (r *seriesRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Series, error) {
+	var series model.Series
+	err := conn(ctx, r.db).First(&series, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+// FindAll returns a paginated list of series
+func (r *seriesRepository) FindAll(ctx context.Context, page, pageSize int) ([]*model.Series, int64, error) {
+	var series []*model.Series
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.Series{})
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("title").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&series).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return series, count, nil
+}
+
+// Update updates a series
+func (r *seriesRepository) Update(ctx context.Context, series *model.Series) error {
+	return conn(ctx, r.db).Save(series).Error
+}
+
+// Delete deletes a series by ID
+func (r *seriesRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&model.Series{}, "id = ?", id).Error
+}
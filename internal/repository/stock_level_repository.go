@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type stockLevelRepository struct {
+	db *gorm.DB
+}
+
+// NewStockLevelRepository creates a new per-location stock repository
+func NewStockLevelRepository(db *gorm.DB) IStockLevelRepository {
+	return &stockLevelRepository{
+		db: db,
+	}
+}
+
+// Adjust adds delta to the stock of a book at a location and returns the
+// new quantity. Positive deltas create the level if it does not exist
+// yet; negative ones are a single conditional update that returns
+// ErrInsufficientStock instead of letting the quantity go negative.
+func (r *stockLevelRepository) Adjust(ctx context.Context, bookID, locationID uuid.UUID, delta int) (int, error) {
+	db := conn(ctx, r.db)
+	if delta > 0 {
+		level := model.StockLevel{
+			BookID:     bookID,
+			LocationID: locationID,
+			Quantity:   delta,
+			UpdatedAt:  time.Now(),
+		}
+		err := db.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "book_id"}, {Name: "location_id"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"quantity":   gorm.Expr("quantity + ?", delta),
+				"updated_at": level.UpdatedAt,
+			}),
+		}).Create(&level).Error
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		result := db.Model(&model.StockLevel{}).
+			Where("book_id = ? AND location_id = ? AND quantity + ? >= 0", bookID, locationID, delta).
+			Updates(map[string]any{
+				"quantity":   gorm.Expr("quantity + ?", delta),
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		if result.RowsAffected == 0 {
+			quantity, err := r.quantity(ctx, bookID, locationID)
+			if err != nil {
+				return 0, err
+			}
+			return quantity, ErrInsufficientStock
+		}
+	}
+	return r.quantity(ctx, bookID, locationID)
+}
+
+// quantity reads the stock of a book at a location, 0 if it has no level there
+func (r *stockLevelRepository) quantity(ctx context.Context, bookID, locationID uuid.UUID) (int, error) {
+	var quantity int
+	err := conn(ctx, r.db).Model(&model.StockLevel{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("book_id = ? AND location_id = ?", bookID, locationID).
+		Scan(&quantity).Error
+	return quantity, err
+}
+
+// Set overwrites the stock of a book at a location, for reconciliation with its ledger
+func (r *stockLevelRepository) Set(ctx context.Context, bookID, locationID uuid.UUID, quantity int) error {
+	level := model.StockLevel{
+		BookID:     bookID,
+		LocationID: locationID,
+		Quantity:   quantity,
+		UpdatedAt:  time.Now(),
+	}
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "book_id"}, {Name: "location_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"quantity", "updated_at"}),
+	}).Create(&level).Error
+}
+
+// FindByBookID returns the stock levels of a book at every location that has one
+func (r *stockLevelRepository) FindByBookID(ctx context.Context, bookID uuid.UUID) ([]*model.StockLevel, error) {
+	var levels []*model.StockLevel
+	err := conn(ctx, r.db).
+		Where("book_id = ?", bookID).
+		Order("quantity DESC").
+		Find(&levels).Error
+	return levels, err
+}
+
+// FindInStockByLocationID returns a paginated list of the books in stock at a location, largest quantities first
+func (r *stockLevelRepository) FindInStockByLocationID(ctx context.Context, locationID uuid.UUID, page, pageSize int) ([]*model.StockLevel, int64, error) {
+	var levels []*model.StockLevel
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.StockLevel{}).
+		Where("location_id = ? AND quantity > 0", locationID)
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("quantity DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&levels).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return levels, count, nil
+}
+
+// ExistsInStockAtLocation checks if any book is in stock at a location
+func (r *stockLevelRepository) ExistsInStockAtLocation(ctx context.Context, locationID uuid.UUID) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.StockLevel{}).
+		Where("location_id = ? AND quantity > 0", locationID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// DeleteByLocationID removes the stock levels recorded at a location
+func (r *stockLevelRepository) DeleteByLocationID(ctx context.Context, locationID uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&model.StockLevel{}, "location_id = ?", locationID).Error
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type stockMovementRepository struct {
+	db *gorm.DB
+}
+
+// NewStockMovementRepository creates a new inventory ledger repository
+func NewStockMovementRepository(db *gorm.DB) IStockMovementRepository {
+	return &stockMovementRepository{
+		db: db,
+	}
+}
+
+// Create saves a new stock movement
+func (r *stockMovementRepository) Create(ctx context.Context, movement *model.StockMovement) error {
+	return conn(ctx, r.db).Create(movement).Error
+}
+
+// FindByBookID returns a paginated ledger of a book, newest first
+func (r *stockMovementRepository) FindByBookID(ctx context.Context, bookID uuid.UUID, page, pageSize int) ([]*model.StockMovement, int64, error) {
+	var movements []*model.StockMovement
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.StockMovement{}).Where("book_id = ?", bookID)
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&movements).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return movements, count, nil
+}
+
+// SumByBookID returns the stock a book's ledger adds up to and its number of entries
+func (r *stockMovementRepository) SumByBookID(ctx context.Context, bookID uuid.UUID) (int, int64, error) {
+	var result struct {
+		Total int
+		Count int64
+	}
+	err := conn(ctx, r.db).Model(&model.StockMovement{}).
+		Select("COALESCE(SUM(quantity), 0) AS total, COUNT(*) AS count").
+		Where("book_id = ?", bookID).
+		Scan(&result).Error
+	return result.Total, result.Count, err
+}
+
+// FindByLocationID returns a paginated ledger of a location, newest first
+func (r *stockMovementRepository) FindByLocationID(ctx context.Context, locationID uuid.UUID, page, pageSize int) ([]*model.StockMovement, int64, error) {
+	var movements []*model.StockMovement
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.StockMovement{}).Where("location_id = ?", locationID)
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&movements).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return movements, count, nil
+}
+
+// SumByLocation returns the stock a book's ledger adds up to at each location it has entries for
+func (r *stockMovementRepository) SumByLocation(ctx context.Context, bookID uuid.UUID) (map[uuid.UUID]int, error) {
+	var rows []struct {
+		LocationID uuid.UUID
+		Total      int
+	}
+	err := conn(ctx, r.db).Model(&model.StockMovement{}).
+		Select("location_id, COALESCE(SUM(quantity), 0) AS total").
+		Where("book_id = ? AND location_id IS NOT NULL", bookID).
+		Group("location_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	sums := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		sums[row.LocationID] = row.Total
+	}
+	return sums, nil
+}
@@ -90,3 +90,10 @@ func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool,
 
 	return count > 0, nil
 }
+
+// FindByRole returns every user with a role
+func (r *UserRepository) FindByRole(ctx context.Context, role string) ([]*model.User, error) {
+	var users []*model.User
+	err := r.db.WithContext(ctx).Where("role = ?", role).Find(&users).Error
+	return users, err
+}
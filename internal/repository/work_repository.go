@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"book_system/internal/model"
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type workRepository struct {
+	db *gorm.DB
+}
+
+// NewWorkRepository creates a new work repository
+func NewWorkRepository(db *gorm.DB) IWorkRepository {
+	return &workRepository{
+		db: db,
+	}
+}
+
+// Create saves a new work
+func (r *workRepository) Create(ctx context.Context, work *model.Work) error {
+	return conn(ctx, r.db).Create(work).Error
+}
+
+// FindByID finds a work by ID
+func (r *workRepository) FindByID(ctx context.Context, id uuid.UUID) (*model.Work, error) {
+	var work model.Work
+	err := conn(ctx, r.db).First(&work, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &work, nil
+}
+
+// FindAll returns a paginated list of works
+func (r *workRepository) FindAll(ctx context.Context, page, pageSize int, filters map[string]any) ([]*model.Work, int64, error) {
+	var works []*model.Work
+	var count int64
+
+	offset := (page - 1) * pageSize
+
+	query := conn(ctx, r.db).Model(&model.Work{})
+	for key, value := range filters {
+		query = query.Where(key, value)
+	}
+
+	if err := query.Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("title").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&works).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return works, count, nil
+}
+
+// FindBySeriesID returns the volumes of a series in reading order
+func (r *workRepository) FindBySeriesID(ctx context.Context, seriesID uuid.UUID) ([]*model.Work, error) {
+	var works []*model.Work
+	err := conn(ctx, r.db).
+		Where("series_id = ?", seriesID).
+		Order("series_position").
+		Find(&works).Error
+	return works, err
+}
+
+// ExistsInSeries checks if another work than excludeID holds a position in a series
+func (r *workRepository) ExistsInSeries(ctx context.Context, seriesID uuid.UUID, position float64, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := conn(ctx, r.db).Model(&model.Work{}).
+		Where("series_id = ? AND series_position = ? AND id <> ?", seriesID, position, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Update updates a work
+func (r *workRepository) Update(ctx context.Context, work *model.Work) error {
+	return conn(ctx, r.db).Save(work).Error
+}
+
+// Delete deletes a work by ID
+func (r *workRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Delete(&model.Work{}, "id = ?", id).Error
+}
+
+// DetachSeries takes every volume out of a series
+func (r *workRepository) DetachSeries(ctx context.Context, seriesID uuid.UUID) error {
+	return conn(ctx, r.db).Model(&model.Work{}).
+		Where("series_id = ?", seriesID).
+		Updates(map[string]any{"series_id": nil, "series_position": 0}).Error
+}
@@ -0,0 +1,175 @@
+package book_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetBookHistory gets a paginated change history of a book, newest first
+func (s *bookService) GetBookHistory(ctx context.Context, id string, page, pageSize int) (*model.BookHistoryResponse, error) {
+	bookID, err := parseBookID(id)
+	if err != nil {
+		return nil, err
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	versions, total, err := s.versionRepo.FindByBookID(ctx, bookID, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book history: %v", err)
+	}
+	// A book may have no history yet, while a purged book only has its
+	// history left, so only a book with neither is not found
+	if total == 0 {
+		if _, err := s.repo.FindByIDUnscoped(ctx, bookID); err != nil {
+			return nil, wrapFindErr(err)
+		}
+	}
+
+	versionDTOs := make([]*model.BookVersionResponse, len(versions))
+	for i, version := range versions {
+		versionDTOs[i] = version.ToDTO(false)
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.BookHistoryResponse{
+		Data: versionDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// GetBookVersion gets a book as it was at the given version
+func (s *bookService) GetBookVersion(ctx context.Context, id string, version int) (*model.BookVersionResponse, error) {
+	bookID, err := parseBookID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	bookVersion, err := s.findVersion(ctx, bookID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return bookVersion.ToDTO(true), nil
+}
+
+// RevertBook restores a book's fields to those of a previous version.
+// The revert is itself recorded as a new version.
+func (s *bookService) RevertBook(ctx context.Context, id string, version int) (*model.BookResponse, error) {
+	bookID, err := parseBookID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	bookVersion, err := s.findVersion(ctx, bookID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := s.repo.FindByID(ctx, bookID)
+	if err != nil {
+		return nil, wrapFindErr(err)
+	}
+	before := book.Snapshot()
+
+	// Versions recorded before ISBNs were normalized may hold another form
+	isbn13, isbn10 := canonicalISBN(bookVersion.Snapshot.ISBN)
+	if isbn13 != book.ISBN {
+		exists, err := s.repo.ExistsByISBN(ctx, isbn13)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check ISBN existence: %v", err)
+		}
+		if exists {
+			return nil, fmt.Errorf("%w: %s", service.ErrBookISBNExists, isbn13)
+		}
+	}
+	if err := s.checkWork(ctx, bookVersion.Snapshot.WorkID); err != nil {
+		return nil, err
+	}
+	if err := s.checkCategory(ctx, bookVersion.Snapshot.CategoryID); err != nil {
+		return nil, err
+	}
+	bookVersion.Snapshot.ApplyTo(book)
+	book.ISBN, book.ISBN10 = isbn13, isbn10
+	book.DropStaleCover()
+	if len(before.Diff(book.Snapshot())) == 0 {
+		return book.ToDTO(), nil
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, book); err != nil {
+			return wrapWriteErr("failed to revert book", err)
+		}
+		return s.recordVersion(ctx, book, model.BookActionRevert, &before)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.deleteStaleCover(ctx, before.Cover, book.Cover)
+
+	return book.ToDTO(), nil
+}
+
+func (s *bookService) findVersion(ctx context.Context, bookID uuid.UUID, version int) (*model.BookVersion, error) {
+	bookVersion, err := s.versionRepo.FindByVersion(ctx, bookID, version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrBookVersionNotFound
+		}
+		return nil, fmt.Errorf("failed to find book version: %v", err)
+	}
+	return bookVersion, nil
+}
+
+// recordVersion appends a snapshot of book to its history, numbered with
+// the version the change brought the book to, so history versions and
+// the book's own version count the same changes. before is the state
+// prior to an update; nil diffs against an empty book on create and
+// records no field changes for other actions.
+func (s *bookService) recordVersion(ctx context.Context, book *model.Book, action string, before *model.BookSnapshot) error {
+	snapshot := book.Snapshot()
+
+	var changes []model.FieldChange
+	switch {
+	case before != nil:
+		changes = before.Diff(snapshot)
+	case action == model.BookActionCreate:
+		changes = model.BookSnapshot{}.Diff(snapshot)
+	default:
+		changes = []model.FieldChange{}
+	}
+
+	version := &model.BookVersion{
+		ID:        uuid.New(),
+		BookID:    book.ID,
+		Version:   book.Version,
+		Action:    action,
+		ActorID:   utils.UserIDFromContext(ctx),
+		Snapshot:  snapshot,
+		Changes:   changes,
+		CreatedAt: time.Now(),
+	}
+	if err := s.versionRepo.Create(ctx, version); err != nil {
+		return fmt.Errorf("failed to record book version: %v", err)
+	}
+
+	return nil
+}
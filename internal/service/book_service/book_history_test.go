@@ -0,0 +1,117 @@
+package book_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestBookHistory(t *testing.T) {
+	ctx := utils.WithCurrentUser(context.Background(), "editor-1", "admin")
+	book := &model.Book{ID: uuid.New(), Title: "Dune", ISBN: "9780306406157", Price: decimal.RequireFromString("9.99"), Version: 1}
+	books := repotest.NewBooks(book)
+	versions := &fakeVersions{}
+	s := newTestService(books, versions)
+	id := book.ID.String()
+
+	title, price := "Dune Messiah", decimal.RequireFromString("12.50")
+	if _, err := s.UpdateBook(ctx, id, &model.UpdateBookRequest{Title: &title, Price: &price}, 0); err != nil {
+		t.Fatalf("UpdateBook() error = %v", err)
+	}
+	// An update that changes nothing is not a new version
+	if _, err := s.UpdateBook(ctx, id, &model.UpdateBookRequest{Title: &title}, 0); err != nil {
+		t.Fatalf("UpdateBook() error = %v", err)
+	}
+	if err := s.DeleteBook(ctx, id, 0); err != nil {
+		t.Fatalf("DeleteBook() error = %v", err)
+	}
+	if _, err := s.RestoreBook(ctx, id); err != nil {
+		t.Fatalf("RestoreBook() error = %v", err)
+	}
+
+	wantActions := []string{model.BookActionUpdate, model.BookActionDelete, model.BookActionRestore}
+	if len(versions.versions) != len(wantActions) {
+		t.Fatalf("recorded %d versions, want %d", len(versions.versions), len(wantActions))
+	}
+	for i, version := range versions.versions {
+		if version.Action != wantActions[i] || version.Version != i+2 || version.ActorID != "editor-1" {
+			t.Errorf("version %d = %s %d by %q, want %s %d by editor-1",
+				i, version.Action, version.Version, version.ActorID, wantActions[i], i+2)
+		}
+	}
+	// The history and the book count the same changes
+	if got := books.Get(book.ID).Version; got != 4 {
+		t.Errorf("book version = %d, want 4", got)
+	}
+
+	changes := versions.versions[0].Changes
+	if len(changes) != 2 || changes[0].Field != "title" || changes[1].Field != "price" {
+		t.Fatalf("update changes = %+v, want title and price", changes)
+	}
+	if changes[0].From != "Dune" || changes[0].To != "Dune Messiah" {
+		t.Errorf("title changed from %v to %v", changes[0].From, changes[0].To)
+	}
+}
+
+func TestRevertBook(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		// taken puts a live book on the ISBN the version had
+		taken        bool
+		wantErr      error
+		wantTitle    string
+		wantRecorded bool
+	}{
+		{name: "back to an earlier version", version: 1, wantTitle: "Dune", wantRecorded: true},
+		{name: "to the current state changes nothing", version: 2, wantTitle: "Dune Messiah"},
+		{name: "unknown version", version: 7, wantErr: service.ErrBookVersionNotFound, wantTitle: "Dune Messiah"},
+		{name: "ISBN taken since", version: 1, taken: true, wantErr: service.ErrBookISBNExists, wantTitle: "Dune Messiah"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := &model.Book{ID: uuid.New(), Title: "Dune Messiah", ISBN: "9780306406157", Stock: 3, Version: 2}
+			versions := &fakeVersions{versions: []*model.BookVersion{
+				{BookID: book.ID, Version: 1, Action: model.BookActionCreate,
+					Snapshot: model.BookSnapshot{Title: "Dune", ISBN: "9780131103627"}},
+				{BookID: book.ID, Version: 2, Action: model.BookActionUpdate, Snapshot: book.Snapshot()},
+			}}
+			books := repotest.NewBooks(book)
+			if tt.taken {
+				books.Put(&model.Book{ID: uuid.New(), ISBN: "9780131103627", Version: 1})
+			}
+			s := newTestService(books, versions)
+
+			_, err := s.RevertBook(context.Background(), book.ID.String(), tt.version)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RevertBook() error = %v, want %v", err, tt.wantErr)
+			}
+
+			saved := books.Get(book.ID)
+			if saved.Title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", saved.Title, tt.wantTitle)
+			}
+			if saved.Stock != 3 {
+				t.Errorf("stock = %d, want it kept at 3", saved.Stock)
+			}
+			recorded := len(versions.versions) == 3
+			if recorded != tt.wantRecorded {
+				t.Fatalf("revert recorded = %v, want %v", recorded, tt.wantRecorded)
+			}
+			if recorded {
+				revert := versions.versions[2]
+				if revert.Action != model.BookActionRevert || revert.Version != 3 || saved.Version != 3 {
+					t.Errorf("revert recorded as %s %d, book at version %d", revert.Action, revert.Version, saved.Version)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package book_service
+
+import (
+	"book_system/internal/baselib/jsonpatch"
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// PatchBook applies a JSON Merge Patch or JSON Patch, as named by mediaType,
+// to the book's current state. The patched book must pass the same
+// validation as UpdateBookRequest, and required fields cannot be removed.
+func (s *bookService) PatchBook(ctx context.Context, id, mediaType string, patch []byte, expectedVersion int) (*model.BookResponse, error) {
+	bookID, err := parseBookID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := s.repo.FindByID(ctx, bookID)
+	if err != nil {
+		return nil, wrapFindErr(err)
+	}
+	if expectedVersion != 0 && expectedVersion != book.Version {
+		return nil, service.ErrBookVersionMismatch
+	}
+
+	doc, err := json.Marshal(book.ToReplaceRequest())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode book: %v", err)
+	}
+
+	var patched []byte
+	switch mediaType {
+	case jsonpatch.MergePatchMediaType:
+		patched, err = jsonpatch.MergePatch(doc, patch)
+	case jsonpatch.JSONPatchMediaType:
+		patched, err = jsonpatch.Apply(doc, patch)
+	default:
+		return nil, fmt.Errorf("%w: %s", service.ErrUnsupportedPatch, mediaType)
+	}
+	if err != nil {
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return nil, fmt.Errorf("%w: %v", service.ErrBookPatchTestFailed, err)
+		}
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookPatch, err)
+	}
+
+	req, err := decodePatchedBook(patched)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.applyUpdate(ctx, book, req.ToUpdate())
+}
+
+// decodePatchedBook turns a patched document back into a full replacement,
+// treating removed optional fields as cleared
+func decodePatchedBook(patched []byte) (*model.ReplaceBookRequest, error) {
+	var update model.UpdateBookRequest
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&update); err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookPatch, err)
+	}
+	if err := update.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookPatch, err)
+	}
+
+	req := &model.ReplaceBookRequest{}
+	if update.Title != nil {
+		req.Title = *update.Title
+	}
+	if update.Author != nil {
+		req.Author = *update.Author
+	}
+	if update.Description != nil {
+		req.Description = *update.Description
+	}
+	if update.CoverImage != nil {
+		req.CoverImage = *update.CoverImage
+	}
+	if update.Price != nil {
+		req.Price = *update.Price
+	}
+	if update.Currency != nil {
+		req.Currency = *update.Currency
+	}
+	req.Stock = update.Stock
+	if update.ISBN != nil {
+		req.ISBN = *update.ISBN
+	}
+	if update.PublishedAt != nil {
+		req.PublishedAt = *update.PublishedAt
+	}
+	if update.WorkID != nil && *update.WorkID != uuid.Nil {
+		req.WorkID = update.WorkID
+	}
+	if update.CategoryID != nil && *update.CategoryID != uuid.Nil {
+		req.CategoryID = update.CategoryID
+	}
+	if update.Format != nil {
+		req.Format = *update.Format
+	}
+	if update.Language != nil {
+		req.Language = *update.Language
+	}
+	if update.PageCount != nil {
+		req.PageCount = *update.PageCount
+	}
+
+	// Catches required fields that the patch removed
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookPatch, err)
+	}
+
+	return req, nil
+}
@@ -1,52 +1,103 @@
 package book_service
 
 import (
+	isbnlib "book_system/internal/baselib/isbn"
 	"book_system/internal/model"
 	"book_system/internal/repository"
 	"book_system/internal/service"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type bookService struct {
-	repo repository.IBookRepository
+	repo         repository.IBookRepository
+	versionRepo  repository.IBookVersionRepository
+	workRepo     repository.IWorkRepository
+	categoryRepo repository.ICategoryRepository
+	inventory    service.IInventoryService
+	uploads      service.IUploadService
+	transactor   repository.ITransactor
 }
 
-// NewBookService creates a new book service
-func NewBookService(repo repository.IBookRepository) service.IBookService {
+// NewBookService creates a new book service. Cover variants a book stops
+// using are deleted from uploads.
+func NewBookService(
+	repo repository.IBookRepository,
+	versionRepo repository.IBookVersionRepository,
+	workRepo repository.IWorkRepository,
+	categoryRepo repository.ICategoryRepository,
+	inventory service.IInventoryService,
+	uploads service.IUploadService,
+	transactor repository.ITransactor,
+) service.IBookService {
 	return &bookService{
-		repo: repo,
+		repo:         repo,
+		versionRepo:  versionRepo,
+		workRepo:     workRepo,
+		categoryRepo: categoryRepo,
+		inventory:    inventory,
+		uploads:      uploads,
+		transactor:   transactor,
 	}
 }
 
 // CreateBook creates a new book
 func (s *bookService) CreateBook(ctx context.Context, req *model.CreateBookRequest) (*model.BookResponse, error) {
+	isbn13, isbn10 := canonicalISBN(req.ISBN)
+
 	// Check if book with same ISBN already exists
-	exists, err := s.repo.ExistsByISBN(ctx, req.ISBN)
+	exists, err := s.repo.ExistsByISBN(ctx, isbn13)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check book existence: %v", err)
 	}
 	if exists {
-		return nil, fmt.Errorf("book with ISBN %s already exists", req.ISBN)
+		return nil, fmt.Errorf("%w: %s", service.ErrBookISBNExists, isbn13)
+	}
+	if err := s.checkWork(ctx, req.WorkID); err != nil {
+		return nil, err
+	}
+	if err := s.checkCategory(ctx, req.CategoryID); err != nil {
+		return nil, err
 	}
 
 	// Create new book entity
 	book := &model.Book{
+		ID:          uuid.New(),
+		Version:     1,
 		Title:       req.Title,
 		Author:      req.Author,
 		Description: req.Description,
 		CoverImage:  req.CoverImage,
 		Price:       req.Price,
-		Stock:       req.Stock,
-		ISBN:        req.ISBN,
+		Currency:    req.Currency,
+		ISBN:        isbn13,
+		ISBN10:      isbn10,
 		PublishedAt: req.PublishedAt,
+		WorkID:      req.WorkID,
+		CategoryID:  req.CategoryID,
+		Format:      req.Format,
+		Language:    req.Language,
+		PageCount:   req.PageCount,
+		Extra:       req.Extra,
 	}
 
-	// Save to database
-	if err := s.repo.Create(ctx, book); err != nil {
-		return nil, fmt.Errorf("failed to create book: %v", err)
+	// Save to database together with its first version
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, book); err != nil {
+			return fmt.Errorf("failed to create book: %v", err)
+		}
+		if err := s.changeStock(ctx, book, req.Stock, model.StockMovementReceive, "initial stock"); err != nil {
+			return err
+		}
+		return s.recordVersion(ctx, book, model.BookActionCreate, nil)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return book.ToDTO(), nil
@@ -54,29 +105,39 @@ func (s *bookService) CreateBook(ctx context.Context, req *model.CreateBookReque
 
 // GetBookByID gets a book by ID
 func (s *bookService) GetBookByID(ctx context.Context, id string) (*model.BookResponse, error) {
-	bookID, err := uuid.Parse(id)
+	bookID, err := parseBookID(id)
 	if err != nil {
-		return nil, fmt.Errorf("invalid book ID format: %v", err)
+		return nil, err
 	}
 
 	book, err := s.repo.FindByID(ctx, bookID)
 	if err != nil {
-		return nil, fmt.Errorf("book not found: %v", err)
+		return nil, wrapFindErr(err)
 	}
 
-	return book.ToDTO(), nil
+	dto := book.ToDTO()
+	if err := s.fillCategories(ctx, dto); err != nil {
+		return nil, err
+	}
+	return dto, nil
 }
 
-// ListBooks gets a paginated list of books
-func (s *bookService) ListBooks(ctx context.Context, page, pageSize int, filters map[string]any) (*model.BookListResponse, error) {
+// ListBooks gets a paginated list of books, sorted by rating or number of
+// ratings when asked to
+func (s *bookService) ListBooks(ctx context.Context, page, pageSize int, filters map[string]any, sort string) (*model.BookListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
 	if pageSize < 1 || pageSize > 100 {
 		pageSize = 10
 	}
+	switch sort {
+	case "", model.BookSortRating, model.BookSortReviews:
+	default:
+		return nil, fmt.Errorf("%w: %q, use %s or %s", service.ErrInvalidBookSort, sort, model.BookSortRating, model.BookSortReviews)
+	}
 
-	books, total, err := s.repo.FindAll(ctx, page, pageSize, filters)
+	books, total, err := s.repo.FindAll(ctx, page, pageSize, filters, sort)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list books: %v", err)
 	}
@@ -86,6 +147,9 @@ func (s *bookService) ListBooks(ctx context.Context, page, pageSize int, filters
 	for i, book := range books {
 		bookDTOs[i] = book.ToDTO()
 	}
+	if err := s.fillCategories(ctx, bookDTOs...); err != nil {
+		return nil, err
+	}
 
 	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
 
@@ -100,18 +164,34 @@ func (s *bookService) ListBooks(ctx context.Context, page, pageSize int, filters
 	}, nil
 }
 
-// UpdateBook updates a book
-func (s *bookService) UpdateBook(ctx context.Context, id string, req *model.UpdateBookRequest) (*model.BookResponse, error) {
-	bookID, err := uuid.Parse(id)
+// UpdateBook updates a book. A non-zero expectedVersion must match the
+// book's current version.
+func (s *bookService) UpdateBook(ctx context.Context, id string, req *model.UpdateBookRequest, expectedVersion int) (*model.BookResponse, error) {
+	bookID, err := parseBookID(id)
 	if err != nil {
-		return nil, fmt.Errorf("invalid book ID format: %v", err)
+		return nil, err
 	}
 
 	// Get existing book
 	book, err := s.repo.FindByID(ctx, bookID)
 	if err != nil {
-		return nil, fmt.Errorf("book not found: %v", err)
+		return nil, wrapFindErr(err)
+	}
+	if expectedVersion != 0 && expectedVersion != book.Version {
+		return nil, service.ErrBookVersionMismatch
+	}
+
+	return s.applyUpdate(ctx, book, req)
+}
+
+// applyUpdate applies the provided fields of req to book and saves it.
+// Stock is refused: setting it from a value read earlier would undo the
+// sales and restocks recorded since.
+func (s *bookService) applyUpdate(ctx context.Context, book *model.Book, req *model.UpdateBookRequest) (*model.BookResponse, error) {
+	if req.Stock != nil {
+		return nil, service.ErrStockNotEditable
 	}
+	before := book.Snapshot()
 
 	// Update fields if provided
 	if req.Title != nil {
@@ -129,49 +209,351 @@ func (s *bookService) UpdateBook(ctx context.Context, id string, req *model.Upda
 	if req.Price != nil {
 		book.Price = *req.Price
 	}
-	if req.Stock != nil {
-		book.Stock = *req.Stock
+	if req.Currency != nil {
+		book.Currency = *req.Currency
 	}
-	if req.ISBN != nil && *req.ISBN != book.ISBN {
-		// Check if new ISBN already exists
-		exists, err := s.repo.ExistsByISBN(ctx, *req.ISBN)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check ISBN existence: %v", err)
-		}
-		if exists {
-			return nil, fmt.Errorf("book with ISBN %s already exists", *req.ISBN)
+	if req.ISBN != nil {
+		isbn13, isbn10 := canonicalISBN(*req.ISBN)
+		if isbn13 != book.ISBN {
+			// Check if new ISBN already exists
+			exists, err := s.repo.ExistsByISBN(ctx, isbn13)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check ISBN existence: %v", err)
+			}
+			if exists {
+				return nil, fmt.Errorf("%w: %s", service.ErrBookISBNExists, isbn13)
+			}
 		}
-		book.ISBN = *req.ISBN
+		book.ISBN, book.ISBN10 = isbn13, isbn10
 	}
 	if req.PublishedAt != nil {
 		book.PublishedAt = *req.PublishedAt
 	}
+	if req.WorkID != nil {
+		// uuid.Nil detaches the book from its work
+		book.WorkID = nil
+		if *req.WorkID != uuid.Nil {
+			workID := *req.WorkID
+			if err := s.checkWork(ctx, &workID); err != nil {
+				return nil, err
+			}
+			book.WorkID = &workID
+		}
+	}
+	if req.CategoryID != nil {
+		// uuid.Nil takes the book out of its category
+		book.CategoryID = nil
+		if *req.CategoryID != uuid.Nil {
+			categoryID := *req.CategoryID
+			if err := s.checkCategory(ctx, &categoryID); err != nil {
+				return nil, err
+			}
+			book.CategoryID = &categoryID
+		}
+	}
+	if req.Format != nil {
+		book.Format = *req.Format
+	}
+	if req.Language != nil {
+		book.Language = *req.Language
+	}
+	if req.PageCount != nil {
+		book.PageCount = *req.PageCount
+	}
+	if req.Extra != nil {
+		book.Extra = req.Extra
+	}
+	if req.Cover != nil {
+		book.Cover = req.Cover
+	}
+	book.DropStaleCover()
+	// A new version is only made for an actual change
+	if len(before.Diff(book.Snapshot())) == 0 {
+		return book.ToDTO(), nil
+	}
 
 	// Save updates
-	if err := s.repo.Update(ctx, book); err != nil {
-		return nil, fmt.Errorf("failed to update book: %v", err)
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, book); err != nil {
+			return wrapWriteErr("failed to update book", err)
+		}
+		return s.recordVersion(ctx, book, model.BookActionUpdate, &before)
+	})
+	if err != nil {
+		return nil, err
 	}
+	s.deleteStaleCover(ctx, before.Cover, book.Cover)
 
 	return book.ToDTO(), nil
 }
 
-// DeleteBook deletes a book
-func (s *bookService) DeleteBook(ctx context.Context, id string) error {
-	bookID, err := uuid.Parse(id)
+// DeleteBook moves a book to the trash. A non-zero expectedVersion must
+// match the book's current version.
+func (s *bookService) DeleteBook(ctx context.Context, id string, expectedVersion int) error {
+	bookID, err := parseBookID(id)
 	if err != nil {
-		return fmt.Errorf("invalid book ID format: %v", err)
+		return err
 	}
 
 	// Check if book exists
-	_, err = s.repo.FindByID(ctx, bookID)
+	book, err := s.repo.FindByID(ctx, bookID)
+	if err != nil {
+		return wrapFindErr(err)
+	}
+	if expectedVersion != 0 && expectedVersion != book.Version {
+		return service.ErrBookVersionMismatch
+	}
+
+	// Soft-delete book
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, bookID, book.Version); err != nil {
+			return wrapWriteErr("failed to delete book", err)
+		}
+		book.Version++
+		return s.recordVersion(ctx, book, model.BookActionDelete, nil)
+	})
+}
+
+// ListTrashedBooks gets a paginated list of soft-deleted books
+func (s *bookService) ListTrashedBooks(ctx context.Context, page, pageSize int) (*model.BookListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	books, total, err := s.repo.FindTrashed(ctx, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trashed books: %v", err)
+	}
+
+	bookDTOs := make([]*model.BookResponse, len(books))
+	for i, book := range books {
+		bookDTOs[i] = book.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.BookListResponse{
+		Data: bookDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// RestoreBook moves a book out of the trash
+func (s *bookService) RestoreBook(ctx context.Context, id string) (*model.BookResponse, error) {
+	bookID, err := parseBookID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := s.repo.FindTrashedByID(ctx, bookID)
 	if err != nil {
-		return fmt.Errorf("book not found: %v", err)
+		return nil, wrapFindErr(err)
 	}
 
-	// Delete book
-	if err := s.repo.Delete(ctx, bookID); err != nil {
-		return fmt.Errorf("failed to delete book: %v", err)
+	// A live book may have taken the ISBN while this one was in the trash
+	exists, err := s.repo.ExistsByISBN(ctx, book.ISBN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check ISBN existence: %v", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("%w: %s", service.ErrBookISBNExists, book.ISBN)
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Restore(ctx, bookID); err != nil {
+			return wrapFindErr(err)
+		}
+		book.Version++
+		// Books trashed before ISBNs were normalized come back in canonical form
+		if isbn13, isbn10 := canonicalISBN(book.ISBN); isbn13 != book.ISBN || isbn10 != book.ISBN10 {
+			if err := s.repo.UpdateISBN(ctx, bookID, isbn13, isbn10); err != nil {
+				return fmt.Errorf("failed to normalize ISBN: %v", err)
+			}
+			book.ISBN, book.ISBN10 = isbn13, isbn10
+		}
+		return s.recordVersion(ctx, book, model.BookActionRestore, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	book.DeletedAt = gorm.DeletedAt{}
+	return book.ToDTO(), nil
+}
+
+// PurgeBook permanently deletes a book in the trash. Live books must be
+// deleted first, so a purge never skips the trash.
+func (s *bookService) PurgeBook(ctx context.Context, id string) error {
+	bookID, err := parseBookID(id)
+	if err != nil {
+		return err
+	}
+
+	book, err := s.repo.FindTrashedByID(ctx, bookID)
+	if err != nil {
+		return wrapFindErr(err)
+	}
+
+	// The history outlives the book so the purge itself stays auditable,
+	// as the version after the book's last one
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Purge(ctx, bookID); err != nil {
+			// Restored in the meantime
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return service.ErrBookNotFound
+			}
+			return fmt.Errorf("failed to purge book: %v", err)
+		}
+		book.Version++
+		return s.recordVersion(ctx, book, model.BookActionPurge, nil)
+	})
+}
+
+// parseBookID parses a book ID, reporting ErrInvalidBookID on bad input
+func parseBookID(id string) (uuid.UUID, error) {
+	bookID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %v", service.ErrInvalidBookID, err)
+	}
+	return bookID, nil
+}
+
+// wrapWriteErr maps a lost conditional write to ErrBookVersionMismatch
+func wrapWriteErr(msg string, err error) error {
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return service.ErrBookVersionMismatch
+	}
+	return fmt.Errorf("%s: %v", msg, err)
+}
+
+// wrapFindErr maps a missing record to ErrBookNotFound
+func wrapFindErr(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return service.ErrBookNotFound
+	}
+	return fmt.Errorf("failed to find book: %v", err)
+}
+
+// deleteStaleCover removes the stored variants of a cover once the book
+// has moved on to another one. Failures only leave unused objects behind,
+// so they are logged rather than returned.
+func (s *bookService) deleteStaleCover(ctx context.Context, old, current *model.BookCover) {
+	if old == nil || (current != nil && current.Large == old.Large) {
+		return
+	}
+	for _, url := range old.URLs() {
+		if url == "" {
+			continue
+		}
+		if err := s.uploads.DeleteFile(ctx, url); err != nil {
+			slog.Warn("Failed to delete cover variant", slog.String("url", url), slog.Any("error", err))
+		}
 	}
+}
+
+// changeStock brings a book's stock to target through the inventory ledger,
+// so the initial stock of a new book is accounted for like any other
+// movement. It must run inside a transaction: the difference is taken
+// from the locked row, so movements committing meanwhile cannot make the
+// stock land anywhere but on target.
+func (s *bookService) changeStock(ctx context.Context, book *model.Book, target int, movementType, reason string) error {
+	current, err := s.repo.FindByIDForUpdate(ctx, book.ID)
+	if err != nil {
+		return wrapFindErr(err)
+	}
+	delta := target - current.Stock
+	if delta == 0 {
+		book.Stock = current.Stock
+		return nil
+	}
+
+	result, err := s.inventory.AdjustStock(ctx, book.ID.String(), &model.StockAdjustRequest{
+		Type:     movementType,
+		Quantity: delta,
+		Reason:   reason,
+	})
+	if err != nil {
+		return err
+	}
+	book.Stock = result.Stock
+	return nil
+}
+
+// checkWork verifies that the work a book is assigned to exists
+func (s *bookService) checkWork(ctx context.Context, workID *uuid.UUID) error {
+	if workID == nil {
+		return nil
+	}
+	if _, err := s.workRepo.FindByID(ctx, *workID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %s", service.ErrWorkNotFound, workID)
+		}
+		return fmt.Errorf("failed to find work: %v", err)
+	}
+	return nil
+}
+
+// checkCategory verifies that the category a book is filed under exists
+func (s *bookService) checkCategory(ctx context.Context, categoryID *uuid.UUID) error {
+	if categoryID == nil {
+		return nil
+	}
+	if _, err := s.categoryRepo.FindByID(ctx, *categoryID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %s", service.ErrCategoryNotFound, categoryID)
+		}
+		return fmt.Errorf("failed to find category: %v", err)
+	}
+	return nil
+}
 
+// fillCategories sets the category of the books filed under one, loading
+// the categories in a single query
+func (s *bookService) fillCategories(ctx context.Context, books ...*model.BookResponse) error {
+	var ids []uuid.UUID
+	for _, book := range books {
+		if book.CategoryID != nil {
+			ids = append(ids, *book.CategoryID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	categories, err := s.categoryRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to find categories: %v", err)
+	}
+	byID := make(map[uuid.UUID]*model.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+	for _, book := range books {
+		if book.CategoryID == nil {
+			continue
+		}
+		if category, ok := byID[*book.CategoryID]; ok {
+			book.Category = category.ToDTO()
+		}
+	}
 	return nil
 }
+
+// canonicalISBN returns the ISBN-13 and derived ISBN-10 forms of an ISBN.
+// Values that are not valid ISBNs, which request validation keeps out, are
+// returned as given.
+func canonicalISBN(value string) (string, string) {
+	isbn13, err := isbnlib.Normalize(value)
+	if err != nil {
+		return value, ""
+	}
+	return isbn13, isbnlib.To10(isbn13)
+}
@@ -0,0 +1,148 @@
+package book_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeVersions keeps the history of every book in the order it was recorded
+type fakeVersions struct {
+	repository.IBookVersionRepository
+	versions []*model.BookVersion
+}
+
+func (r *fakeVersions) Create(ctx context.Context, version *model.BookVersion) error {
+	r.versions = append(r.versions, version)
+	return nil
+}
+
+func (r *fakeVersions) FindByVersion(ctx context.Context, bookID uuid.UUID, version int) (*model.BookVersion, error) {
+	for _, v := range r.versions {
+		if v.BookID == bookID && v.Version == version {
+			copied := *v
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func newTestService(books *repotest.Books, versions *fakeVersions) service.IBookService {
+	return NewBookService(books, versions, nil, nil, nil, nil, repotest.Transactor{})
+}
+
+func TestTrash(t *testing.T) {
+	const isbn = "9780306406157"
+
+	tests := []struct {
+		name    string
+		trashed bool
+		// taken puts a live book with the same ISBN next to the one acted on
+		taken       bool
+		action      func(s service.IBookService, id string) error
+		wantErr     error
+		wantTrashed bool
+		wantPurged  bool
+	}{
+		{
+			name:        "delete moves a book to the trash",
+			action:      func(s service.IBookService, id string) error { return s.DeleteBook(context.Background(), id, 0) },
+			wantTrashed: true,
+		},
+		{
+			name:        "a book in the trash cannot be deleted again",
+			trashed:     true,
+			action:      func(s service.IBookService, id string) error { return s.DeleteBook(context.Background(), id, 0) },
+			wantErr:     service.ErrBookNotFound,
+			wantTrashed: true,
+		},
+		{
+			name:    "restore brings a book back",
+			trashed: true,
+			action: func(s service.IBookService, id string) error {
+				_, err := s.RestoreBook(context.Background(), id)
+				return err
+			},
+		},
+		{
+			name: "only books in the trash are restored",
+			action: func(s service.IBookService, id string) error {
+				_, err := s.RestoreBook(context.Background(), id)
+				return err
+			},
+			wantErr: service.ErrBookNotFound,
+		},
+		{
+			name:    "restore refuses an ISBN a live book took meanwhile",
+			trashed: true,
+			taken:   true,
+			action: func(s service.IBookService, id string) error {
+				_, err := s.RestoreBook(context.Background(), id)
+				return err
+			},
+			wantErr:     service.ErrBookISBNExists,
+			wantTrashed: true,
+		},
+		{
+			name:       "purge deletes a book in the trash for good",
+			trashed:    true,
+			action:     func(s service.IBookService, id string) error { return s.PurgeBook(context.Background(), id) },
+			wantPurged: true,
+		},
+		{
+			name:    "only books in the trash are purged",
+			action:  func(s service.IBookService, id string) error { return s.PurgeBook(context.Background(), id) },
+			wantErr: service.ErrBookNotFound,
+		},
+		{
+			name: "bad IDs are refused",
+			action: func(s service.IBookService, id string) error {
+				return s.DeleteBook(context.Background(), "not-a-uuid", 0)
+			},
+			wantErr: service.ErrInvalidBookID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := &model.Book{ID: uuid.New(), ISBN: isbn, Version: 1}
+			if tt.trashed {
+				book.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+			}
+			books := repotest.NewBooks(book)
+			if tt.taken {
+				books.Put(&model.Book{ID: uuid.New(), ISBN: isbn, Version: 1})
+			}
+			s := newTestService(books, &fakeVersions{})
+
+			err := tt.action(s, book.ID.String())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			_, err = s.GetBookByID(context.Background(), book.ID.String())
+			switch {
+			case tt.wantPurged:
+				if books.Get(book.ID) != nil {
+					t.Errorf("book is still stored")
+				}
+			case tt.wantTrashed:
+				if !errors.Is(err, service.ErrBookNotFound) {
+					t.Errorf("GetBookByID() error = %v, want the book hidden in the trash", err)
+				}
+			default:
+				if err != nil {
+					t.Errorf("GetBookByID() error = %v, want the book live", err)
+				}
+			}
+		})
+	}
+}
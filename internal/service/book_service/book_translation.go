@@ -0,0 +1,104 @@
+package book_service
+
+import (
+	"book_system/i18n"
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"context"
+	"fmt"
+	"maps"
+)
+
+// GetTranslations gets the translations of a book's title and description
+func (s *bookService) GetTranslations(ctx context.Context, id string) (model.BookTranslations, error) {
+	book, err := s.GetBookByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if book.Translations == nil {
+		return model.BookTranslations{}, nil
+	}
+	return book.Translations, nil
+}
+
+// SetTranslation adds or replaces the translation of a book into lang,
+// which must be one of the languages of the i18n bundle other than the
+// book's own. A non-zero expectedVersion must match the current version.
+func (s *bookService) SetTranslation(ctx context.Context, id, lang string, translation *model.BookTranslation, expectedVersion int) (*model.BookResponse, error) {
+	book, err := s.findForUpdate(ctx, id, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+	if !i18n.IsSupported(lang) {
+		return nil, fmt.Errorf("%w: %s, expected one of %v", service.ErrUnsupportedLanguage, lang, i18n.Languages())
+	}
+	if lang == book.Language {
+		return nil, fmt.Errorf("%w: %s is the book's own language", service.ErrUnsupportedLanguage, lang)
+	}
+
+	before := book.Snapshot()
+	// Copy so the snapshot keeps the previous translations
+	translations := maps.Clone(book.Translations)
+	if translations == nil {
+		translations = model.BookTranslations{}
+	}
+	translations[lang] = *translation
+	book.Translations = translations
+
+	return s.saveTranslations(ctx, book, &before)
+}
+
+// DeleteTranslation removes the translation of a book into lang. A
+// non-zero expectedVersion must match the current version.
+func (s *bookService) DeleteTranslation(ctx context.Context, id, lang string, expectedVersion int) (*model.BookResponse, error) {
+	book, err := s.findForUpdate(ctx, id, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := book.Translations[lang]; !ok {
+		return nil, fmt.Errorf("%w: %s", service.ErrTranslationNotFound, lang)
+	}
+
+	before := book.Snapshot()
+	translations := maps.Clone(book.Translations)
+	delete(translations, lang)
+	book.Translations = translations
+
+	return s.saveTranslations(ctx, book, &before)
+}
+
+// findForUpdate gets a book that is about to be changed, checking expectedVersion
+func (s *bookService) findForUpdate(ctx context.Context, id string, expectedVersion int) (*model.Book, error) {
+	bookID, err := parseBookID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := s.repo.FindByID(ctx, bookID)
+	if err != nil {
+		return nil, wrapFindErr(err)
+	}
+	if expectedVersion != 0 && expectedVersion != book.Version {
+		return nil, service.ErrBookVersionMismatch
+	}
+	return book, nil
+}
+
+// saveTranslations saves a book whose translations changed, with a new version
+func (s *bookService) saveTranslations(ctx context.Context, book *model.Book, before *model.BookSnapshot) (*model.BookResponse, error) {
+	if len(before.Diff(book.Snapshot())) == 0 {
+		return book.ToDTO(), nil
+	}
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, book); err != nil {
+			return wrapWriteErr("failed to update book translations", err)
+		}
+		return s.recordVersion(ctx, book, model.BookActionUpdate, before)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return book.ToDTO(), nil
+}
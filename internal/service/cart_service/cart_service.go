@@ -0,0 +1,210 @@
+package cart_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type cartService struct {
+	repo     repository.ICartRepository
+	bookRepo repository.IBookRepository
+	pricing  service.IPricingService
+}
+
+// NewCartService creates a new shopping cart service. Carts are priced
+// through the pricing service.
+func NewCartService(repo repository.ICartRepository, bookRepo repository.IBookRepository, pricing service.IPricingService) service.ICartService {
+	return &cartService{
+		repo:     repo,
+		bookRepo: bookRepo,
+		pricing:  pricing,
+	}
+}
+
+// GetCart gets the current user's cart at the books' effective prices in
+// currency, with the discount of a coupon when couponCode is set. An empty
+// currency falls back to the user's preferred currency and then to the
+// catalog currency. Books that were deleted since they were added are
+// dropped from it.
+func (s *cartService) GetCart(ctx context.Context, couponCode, currency string) (*model.CartResponse, error) {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	currency, err = s.pricing.ResolveCurrency(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find cart: %v", err)
+	}
+
+	bookIDs := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		bookIDs[i] = item.BookID
+	}
+	books, err := s.bookRepo.FindByIDs(ctx, bookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find books: %v", err)
+	}
+	byID := make(map[uuid.UUID]*model.Book, len(books))
+	for _, book := range books {
+		byID[book.ID] = book
+	}
+	quotes, err := s.pricing.PriceBooks(ctx, books, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	subtotal := decimal.Zero
+	cart := &model.CartResponse{
+		Items:    make([]*model.CartItemResponse, 0, len(items)),
+		Currency: currency,
+	}
+	for _, item := range items {
+		book, ok := byID[item.BookID]
+		if !ok {
+			if err := s.repo.Delete(ctx, userID, item.BookID); err != nil {
+				return nil, fmt.Errorf("failed to drop deleted book from cart: %v", err)
+			}
+			continue
+		}
+
+		quote := quotes[book.ID]
+		lineTotal := quote.Price.Mul(decimal.NewFromInt(int64(item.Quantity)))
+		cart.Items = append(cart.Items, &model.CartItemResponse{
+			Book:      book.ToDTO(),
+			Quantity:  item.Quantity,
+			ListPrice: quote.ListPrice,
+			UnitPrice: quote.Price,
+			LineTotal: lineTotal,
+			Available: book.Stock >= item.Quantity,
+		})
+		cart.ItemCount += item.Quantity
+		subtotal = subtotal.Add(lineTotal)
+	}
+
+	total := subtotal
+	if couponCode != "" && len(cart.Items) > 0 {
+		discount, err := s.pricing.CouponDiscount(ctx, couponCode, subtotal, currency)
+		if err != nil {
+			return nil, err
+		}
+		cart.Discount = discount.Amount
+		cart.Coupon = discount.Adjustment
+		total = total.Sub(discount.Amount)
+	}
+	cart.Subtotal = subtotal
+	cart.Total = total
+
+	return cart, nil
+}
+
+// AddItem adds copies of a book to the current user's cart, on top of any
+// already in it
+func (s *cartService) AddItem(ctx context.Context, req *model.CartItemRequest) (*model.CartResponse, error) {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkBook(ctx, req.BookID); err != nil {
+		return nil, err
+	}
+
+	item, err := s.repo.Find(ctx, userID, req.BookID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to find cart item: %v", err)
+		}
+		item = &model.CartItem{UserID: userID, BookID: req.BookID}
+	}
+
+	item.Quantity += req.Quantity
+	if item.Quantity > model.MaxCartQuantity {
+		return nil, fmt.Errorf("%w: at most %d copies of a book", service.ErrInvalidCartQuantity, model.MaxCartQuantity)
+	}
+	if err := s.repo.Save(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to save cart item: %v", err)
+	}
+
+	return s.GetCart(ctx, "", "")
+}
+
+// SetItemQuantity sets the number of copies of a book in the current
+// user's cart. A quantity of 0 removes the book.
+func (s *cartService) SetItemQuantity(ctx context.Context, bookID string, req *model.CartQuantityRequest) (*model.CartResponse, error) {
+	if req.Quantity == 0 {
+		return s.RemoveItem(ctx, bookID)
+	}
+
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(bookID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookID, err)
+	}
+	if err := s.checkBook(ctx, id); err != nil {
+		return nil, err
+	}
+
+	item := &model.CartItem{UserID: userID, BookID: id, Quantity: req.Quantity}
+	if err := s.repo.Save(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to save cart item: %v", err)
+	}
+
+	return s.GetCart(ctx, "", "")
+}
+
+// RemoveItem removes a book from the current user's cart
+func (s *cartService) RemoveItem(ctx context.Context, bookID string) (*model.CartResponse, error) {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(bookID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookID, err)
+	}
+
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		return nil, fmt.Errorf("failed to remove cart item: %v", err)
+	}
+
+	return s.GetCart(ctx, "", "")
+}
+
+// ClearCart empties the current user's cart
+func (s *cartService) ClearCart(ctx context.Context) error {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to clear cart: %v", err)
+	}
+	return nil
+}
+
+// checkBook makes sure a book exists and is not in the trash
+func (s *cartService) checkBook(ctx context.Context, bookID uuid.UUID) error {
+	if _, err := s.bookRepo.FindByID(ctx, bookID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return service.ErrBookNotFound
+		}
+		return fmt.Errorf("failed to find book: %v", err)
+	}
+	return nil
+}
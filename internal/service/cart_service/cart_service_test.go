@@ -0,0 +1,157 @@
+package cart_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// fakePricing prices books at their list price in the catalog currency
+type fakePricing struct {
+	service.IPricingService
+}
+
+func (s *fakePricing) ResolveCurrency(ctx context.Context, requested string) (string, error) {
+	if requested == "" {
+		return "USD", nil
+	}
+	return requested, nil
+}
+
+func (s *fakePricing) PriceBooks(ctx context.Context, books []*model.Book, currency string) (map[uuid.UUID]*model.PriceQuote, error) {
+	quotes := make(map[uuid.UUID]*model.PriceQuote, len(books))
+	for _, book := range books {
+		quotes[book.ID] = &model.PriceQuote{BookID: book.ID, Currency: currency, ListPrice: book.Price, Price: book.Price}
+	}
+	return quotes, nil
+}
+
+func TestAddItem(t *testing.T) {
+	userID := uuid.New()
+	ctx := utils.WithCurrentUser(context.Background(), userID.String(), "user")
+
+	tests := []struct {
+		name     string
+		book     *model.Book
+		inCart   int
+		quantity int
+		wantErr  error
+		// wantQuantity is the quantity of the book in the cart afterwards
+		wantQuantity  int
+		wantAvailable bool
+	}{
+		{name: "adds a book", book: &model.Book{Stock: 5}, quantity: 2, wantQuantity: 2, wantAvailable: true},
+		{name: "merges with the copies already in the cart", book: &model.Book{Stock: 5}, inCart: 2, quantity: 3,
+			wantQuantity: 5, wantAvailable: true},
+		{name: "out of stock book is added but unavailable", book: &model.Book{Stock: 0}, quantity: 1, wantQuantity: 1},
+		{name: "more copies than in stock are unavailable", book: &model.Book{Stock: 2}, inCart: 2, quantity: 1, wantQuantity: 3},
+		{name: "deleted book is refused", book: &model.Book{Stock: 5, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}},
+			quantity: 1, wantErr: service.ErrBookNotFound},
+		{name: "unknown book is refused", quantity: 1, wantErr: service.ErrBookNotFound},
+		{name: "merged quantity is capped", book: &model.Book{Stock: 500}, inCart: model.MaxCartQuantity, quantity: 1,
+			wantErr: service.ErrInvalidCartQuantity, wantQuantity: model.MaxCartQuantity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bookID := uuid.New()
+			books := repotest.NewBooks()
+			if tt.book != nil {
+				tt.book.ID, tt.book.Title, tt.book.Price = bookID, "Dune", decimal.RequireFromString("9.99")
+				books.Put(tt.book)
+			}
+			carts := repotest.NewCarts()
+			if tt.inCart > 0 {
+				_ = carts.Save(ctx, &model.CartItem{UserID: userID, BookID: bookID, Quantity: tt.inCart})
+			}
+			s := NewCartService(carts, books, &fakePricing{})
+
+			cart, err := s.AddItem(ctx, &model.CartItemRequest{BookID: bookID, Quantity: tt.quantity})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AddItem() error = %v, want %v", err, tt.wantErr)
+			}
+
+			quantity := 0
+			if item, err := carts.Find(ctx, userID, bookID); err == nil {
+				quantity = item.Quantity
+			}
+			if quantity != tt.wantQuantity {
+				t.Errorf("cart holds %d copies, want %d", quantity, tt.wantQuantity)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if len(cart.Items) != 1 || cart.ItemCount != tt.wantQuantity {
+				t.Fatalf("cart = %+v", cart)
+			}
+			item := cart.Items[0]
+			if item.Available != tt.wantAvailable {
+				t.Errorf("available = %v, want %v", item.Available, tt.wantAvailable)
+			}
+			wantTotal := decimal.RequireFromString("9.99").Mul(decimal.NewFromInt(int64(tt.wantQuantity)))
+			if !item.LineTotal.Equal(wantTotal) || !cart.Total.Equal(wantTotal) {
+				t.Errorf("line total = %s and total = %s, want %s", item.LineTotal, cart.Total, wantTotal)
+			}
+		})
+	}
+}
+
+func TestGetCartDropsDeletedBooks(t *testing.T) {
+	userID := uuid.New()
+	ctx := utils.WithCurrentUser(context.Background(), userID.String(), "user")
+	kept := &model.Book{ID: uuid.New(), Price: decimal.NewFromInt(10), Stock: 3}
+	deleted := &model.Book{ID: uuid.New(), Price: decimal.NewFromInt(20), Stock: 3}
+	books := repotest.NewBooks(kept, deleted)
+	carts := repotest.NewCarts(
+		&model.CartItem{UserID: userID, BookID: kept.ID, Quantity: 1},
+		&model.CartItem{UserID: userID, BookID: deleted.ID, Quantity: 1},
+	)
+	s := NewCartService(carts, books, &fakePricing{})
+
+	deleted.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	cart, err := s.GetCart(ctx, "", "")
+	if err != nil {
+		t.Fatalf("GetCart() error = %v", err)
+	}
+	if len(cart.Items) != 1 || cart.Items[0].Book.ID != kept.ID || !cart.Total.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("cart = %+v", cart)
+	}
+	if _, err := carts.Find(ctx, userID, deleted.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("deleted book left in the cart")
+	}
+}
+
+func TestClearCart(t *testing.T) {
+	userID, otherID := uuid.New(), uuid.New()
+	ctx := utils.WithCurrentUser(context.Background(), userID.String(), "user")
+	book := &model.Book{ID: uuid.New(), Price: decimal.NewFromInt(10), Stock: 3}
+	carts := repotest.NewCarts(
+		&model.CartItem{UserID: userID, BookID: book.ID, Quantity: 1},
+		&model.CartItem{UserID: otherID, BookID: book.ID, Quantity: 2},
+	)
+	s := NewCartService(carts, repotest.NewBooks(book), &fakePricing{})
+
+	if err := s.ClearCart(ctx); err != nil {
+		t.Fatalf("ClearCart() error = %v", err)
+	}
+	cart, err := s.GetCart(ctx, "", "")
+	if err != nil {
+		t.Fatalf("GetCart() error = %v", err)
+	}
+	if len(cart.Items) != 0 || cart.ItemCount != 0 || !cart.Total.IsZero() {
+		t.Errorf("cart = %+v, want it empty", cart)
+	}
+	if items, _ := carts.FindByUserID(ctx, otherID); len(items) != 1 {
+		t.Errorf("another user's cart holds %d items, want 1", len(items))
+	}
+}
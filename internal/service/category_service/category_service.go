@@ -0,0 +1,168 @@
+package category_service
+
+import (
+	"book_system/i18n"
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type categoryService struct {
+	repo     repository.ICategoryRepository
+	bookRepo repository.IBookRepository
+}
+
+// NewCategoryService creates a new category service
+func NewCategoryService(repo repository.ICategoryRepository, bookRepo repository.IBookRepository) service.ICategoryService {
+	return &categoryService{
+		repo:     repo,
+		bookRepo: bookRepo,
+	}
+}
+
+// CreateCategory creates a new category
+func (s *categoryService) CreateCategory(ctx context.Context, req *model.CategoryRequest) (*model.CategoryResponse, error) {
+	now := time.Now()
+	category := &model.Category{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.apply(ctx, category, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to create category: %v", err)
+	}
+
+	return category.ToDTO(), nil
+}
+
+// GetCategory gets a category by ID
+func (s *categoryService) GetCategory(ctx context.Context, id string) (*model.CategoryResponse, error) {
+	category, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return category.ToDTO(), nil
+}
+
+// ListCategories gets a paginated list of categories
+func (s *categoryService) ListCategories(ctx context.Context, page, pageSize int) (*model.CategoryListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	categories, total, err := s.repo.FindAll(ctx, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list categories: %v", err)
+	}
+
+	categoryDTOs := make([]*model.CategoryResponse, len(categories))
+	for i, category := range categories {
+		categoryDTOs[i] = category.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.CategoryListResponse{
+		Data: categoryDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// UpdateCategory replaces the name and translations of a category
+func (s *categoryService) UpdateCategory(ctx context.Context, id string, req *model.CategoryRequest) (*model.CategoryResponse, error) {
+	category, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(ctx, category, req); err != nil {
+		return nil, err
+	}
+	category.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, category); err != nil {
+		return nil, fmt.Errorf("failed to update category: %v", err)
+	}
+
+	return category.ToDTO(), nil
+}
+
+// DeleteCategory deletes a category no book is filed under, counting the
+// books in the trash, which may come back
+func (s *categoryService) DeleteCategory(ctx context.Context, id string) error {
+	category, err := s.find(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	hasBooks, err := s.bookRepo.ExistsByCategoryID(ctx, category.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check category books: %v", err)
+	}
+	if hasBooks {
+		return service.ErrCategoryHasBooks
+	}
+
+	if err := s.repo.Delete(ctx, category.ID); err != nil {
+		return fmt.Errorf("failed to delete category: %v", err)
+	}
+	return nil
+}
+
+// apply copies a request onto a category after checking its name is free.
+// Translations must be into the languages of the i18n bundle other than
+// the default one, which Name is in.
+func (s *categoryService) apply(ctx context.Context, category *model.Category, req *model.CategoryRequest) error {
+	for lang := range req.Translations {
+		if !i18n.IsSupported(lang) || lang == i18n.DefaultLanguage {
+			return fmt.Errorf("%w: %s, expected one of %v other than %s", service.ErrUnsupportedLanguage, lang, i18n.Languages(), i18n.DefaultLanguage)
+		}
+	}
+
+	taken, err := s.repo.ExistsByName(ctx, req.Name, category.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check category name: %v", err)
+	}
+	if taken {
+		return fmt.Errorf("%w: %s", service.ErrCategoryNameTaken, req.Name)
+	}
+
+	category.Name = req.Name
+	category.Translations = req.Translations
+	return nil
+}
+
+// find gets a category by its ID in string form
+func (s *categoryService) find(ctx context.Context, id string) (*model.Category, error) {
+	categoryID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidCategoryID, err)
+	}
+
+	category, err := s.repo.FindByID(ctx, categoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrCategoryNotFound
+		}
+		return nil, fmt.Errorf("failed to find category: %v", err)
+	}
+	return category, nil
+}
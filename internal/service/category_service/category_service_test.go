@@ -0,0 +1,154 @@
+package category_service
+
+import (
+	"book_system/i18n"
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	// The bundle loads its message files relative to the repository root
+	if err := os.Chdir("../../.."); err != nil {
+		panic(err)
+	}
+	i18n.InitI18n([]string{"vi", "en"})
+	os.Exit(m.Run())
+}
+
+type fakeCategories struct {
+	repository.ICategoryRepository
+	categories map[uuid.UUID]*model.Category
+}
+
+func (r *fakeCategories) Create(ctx context.Context, category *model.Category) error {
+	r.categories[category.ID] = category
+	return nil
+}
+
+func (r *fakeCategories) FindByID(ctx context.Context, id uuid.UUID) (*model.Category, error) {
+	category, ok := r.categories[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *category
+	return &copied, nil
+}
+
+func (r *fakeCategories) ExistsByName(ctx context.Context, name string, excludeID uuid.UUID) (bool, error) {
+	for _, category := range r.categories {
+		if category.Name == name && category.ID != excludeID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeCategories) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(r.categories, id)
+	return nil
+}
+
+func TestCreateCategory(t *testing.T) {
+	categories := &fakeCategories{categories: make(map[uuid.UUID]*model.Category)}
+	s := NewCategoryService(categories, repotest.NewBooks())
+	if _, err := s.CreateCategory(context.Background(), &model.CategoryRequest{Name: "Poetry"}); err != nil {
+		t.Fatalf("CreateCategory() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		req     *model.CategoryRequest
+		wantErr error
+	}{
+		{name: "translated", req: &model.CategoryRequest{Name: "Fiction", Translations: model.CategoryTranslations{"vi": "Tiểu thuyết"}}},
+		{name: "language without messages", req: &model.CategoryRequest{Name: "History", Translations: model.CategoryTranslations{"de": "Geschichte"}},
+			wantErr: service.ErrUnsupportedLanguage},
+		{name: "default language as a translation", req: &model.CategoryRequest{Name: "Science", Translations: model.CategoryTranslations{"en": "Science"}},
+			wantErr: service.ErrUnsupportedLanguage},
+		{name: "name taken", req: &model.CategoryRequest{Name: "Poetry"}, wantErr: service.ErrCategoryNameTaken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			category, err := s.CreateCategory(context.Background(), tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateCategory() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && category.Translations["vi"] != tt.req.Translations["vi"] {
+				t.Errorf("translations = %v, want %v", category.Translations, tt.req.Translations)
+			}
+		})
+	}
+}
+
+func TestGetCategoryLocalized(t *testing.T) {
+	categories := &fakeCategories{categories: make(map[uuid.UUID]*model.Category)}
+	s := NewCategoryService(categories, repotest.NewBooks())
+	translated, err := s.CreateCategory(context.Background(), &model.CategoryRequest{
+		Name: "Fiction", Translations: model.CategoryTranslations{"vi": "Tiểu thuyết"}})
+	if err != nil {
+		t.Fatalf("CreateCategory() error = %v", err)
+	}
+	untranslated, err := s.CreateCategory(context.Background(), &model.CategoryRequest{Name: "Poetry"})
+	if err != nil {
+		t.Fatalf("CreateCategory() error = %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		id             uuid.UUID
+		acceptLanguage string
+		wantName       string
+		wantLanguage   string
+	}{
+		{name: "translation", id: translated.ID, acceptLanguage: "vi", wantName: "Tiểu thuyết", wantLanguage: "vi"},
+		{name: "regional variant falls back to the translation", id: translated.ID, acceptLanguage: "vi-VN", wantName: "Tiểu thuyết", wantLanguage: "vi"},
+		{name: "first acceptable language", id: translated.ID, acceptLanguage: "de, vi;q=0.8, en;q=0.5", wantName: "Tiểu thuyết", wantLanguage: "vi"},
+		{name: "default language preferred", id: translated.ID, acceptLanguage: "en, vi;q=0.5", wantName: "Fiction", wantLanguage: "en"},
+		{name: "nothing acceptable falls back to the default", id: translated.ID, acceptLanguage: "de", wantName: "Fiction", wantLanguage: "en"},
+		{name: "no header", id: translated.ID, wantName: "Fiction", wantLanguage: "en"},
+		{name: "missing translation falls back to the default", id: untranslated.ID, acceptLanguage: "vi", wantName: "Poetry", wantLanguage: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			category, err := s.GetCategory(context.Background(), tt.id.String())
+			if err != nil {
+				t.Fatalf("GetCategory() error = %v", err)
+			}
+			category.Localize(tt.acceptLanguage)
+			if category.Name != tt.wantName || category.ContentLanguage != tt.wantLanguage {
+				t.Errorf("Localize(%q) = %q in %s, want %q in %s",
+					tt.acceptLanguage, category.Name, category.ContentLanguage, tt.wantName, tt.wantLanguage)
+			}
+		})
+	}
+}
+
+func TestDeleteCategory(t *testing.T) {
+	filed, empty := &model.Category{ID: uuid.New(), Name: "Fiction"}, &model.Category{ID: uuid.New(), Name: "Poetry"}
+	categories := &fakeCategories{categories: map[uuid.UUID]*model.Category{filed.ID: filed, empty.ID: empty}}
+	// Books in the trash may come back, so they keep their category
+	trashed := &model.Book{ID: uuid.New(), CategoryID: &filed.ID, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}}
+	s := NewCategoryService(categories, repotest.NewBooks(trashed))
+
+	if err := s.DeleteCategory(context.Background(), filed.ID.String()); !errors.Is(err, service.ErrCategoryHasBooks) {
+		t.Errorf("DeleteCategory() of a category with books error = %v, want %v", err, service.ErrCategoryHasBooks)
+	}
+	if err := s.DeleteCategory(context.Background(), empty.ID.String()); err != nil {
+		t.Errorf("DeleteCategory() error = %v", err)
+	}
+	if _, ok := categories.categories[empty.ID]; ok {
+		t.Errorf("empty category was not deleted")
+	}
+}
@@ -0,0 +1,528 @@
+package circulation_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type circulationService struct {
+	copyRepo          repository.ICopyRepository
+	loanRepo          repository.ILoanRepository
+	bookRepo          repository.IBookRepository
+	locationRepo      repository.ILocationRepository
+	userRepo          repository.IUserRepository
+	fines             service.IFineService
+	transactor        repository.ITransactor
+	loanPeriods       map[string]time.Duration
+	defaultLoanPeriod time.Duration
+	renewalLimit      int
+}
+
+// NewCirculationService creates a new circulation service. Copies are lent
+// for the loan period of the borrower's role, or defaultLoanPeriod for
+// roles without one, and each loan may be renewed renewalLimit times.
+func NewCirculationService(
+	copyRepo repository.ICopyRepository,
+	loanRepo repository.ILoanRepository,
+	bookRepo repository.IBookRepository,
+	locationRepo repository.ILocationRepository,
+	userRepo repository.IUserRepository,
+	fines service.IFineService,
+	transactor repository.ITransactor,
+	loanPeriods map[string]time.Duration,
+	defaultLoanPeriod time.Duration,
+	renewalLimit int,
+) service.ICirculationService {
+	return &circulationService{
+		copyRepo:          copyRepo,
+		loanRepo:          loanRepo,
+		bookRepo:          bookRepo,
+		locationRepo:      locationRepo,
+		userRepo:          userRepo,
+		fines:             fines,
+		transactor:        transactor,
+		loanPeriods:       loanPeriods,
+		defaultLoanPeriod: defaultLoanPeriod,
+		renewalLimit:      renewalLimit,
+	}
+}
+
+// AddCopy adds a physical copy of a book to the holdings. Barcodes must be
+// unique.
+func (s *circulationService) AddCopy(ctx context.Context, bookID string, req *model.CopyRequest) (*model.CopyResponse, error) {
+	book, err := s.findBook(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	bookCopy := &model.Copy{
+		ID:        uuid.New(),
+		BookID:    book.ID,
+		Status:    model.CopyStatusAvailable,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.apply(ctx, bookCopy, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.copyRepo.Create(ctx, bookCopy); err != nil {
+		return nil, fmt.Errorf("failed to create copy: %v", err)
+	}
+
+	return bookCopy.ToDTO(), nil
+}
+
+// GetCopy gets a copy by ID
+func (s *circulationService) GetCopy(ctx context.Context, id string) (*model.CopyResponse, error) {
+	bookCopy, err := s.findCopy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return bookCopy.ToDTO(), nil
+}
+
+// ListCopies gets a paginated list of copies ordered by barcode
+func (s *circulationService) ListCopies(ctx context.Context, page, pageSize int, filters map[string]any) (*model.CopyListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	copies, total, err := s.copyRepo.FindAll(ctx, page, pageSize, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list copies: %v", err)
+	}
+
+	copyDTOs := make([]*model.CopyResponse, len(copies))
+	for i, bookCopy := range copies {
+		copyDTOs[i] = bookCopy.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.CopyListResponse{
+		Data: copyDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// ListBookCopies gets a paginated list of the copies of a book
+func (s *circulationService) ListBookCopies(ctx context.Context, bookID string, page, pageSize int) (*model.CopyListResponse, error) {
+	book, err := s.findBook(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListCopies(ctx, page, pageSize, map[string]any{"book_id = ?": book.ID})
+}
+
+// UpdateCopy replaces the editable fields of a copy. The status of a copy
+// on loan is left to checkin.
+func (s *circulationService) UpdateCopy(ctx context.Context, id string, req *model.CopyRequest) (*model.CopyResponse, error) {
+	bookCopy, err := s.findCopy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if bookCopy.Status == model.CopyStatusOnLoan && req.Status != "" && req.Status != bookCopy.Status {
+		return nil, fmt.Errorf("%w: check it in first", service.ErrCopyOnLoan)
+	}
+
+	if err := s.apply(ctx, bookCopy, req); err != nil {
+		return nil, err
+	}
+	bookCopy.UpdatedAt = time.Now()
+	if err := s.copyRepo.Update(ctx, bookCopy); err != nil {
+		return nil, fmt.Errorf("failed to update copy: %v", err)
+	}
+
+	return bookCopy.ToDTO(), nil
+}
+
+// DeleteCopy removes a copy that is not on loan from the holdings. Its
+// loans are kept as history.
+func (s *circulationService) DeleteCopy(ctx context.Context, id string) error {
+	bookCopy, err := s.findCopy(ctx, id)
+	if err != nil {
+		return err
+	}
+	if bookCopy.Status == model.CopyStatusOnLoan {
+		return service.ErrCopyOnLoan
+	}
+
+	if err := s.copyRepo.Delete(ctx, bookCopy.ID); err != nil {
+		return fmt.Errorf("failed to delete copy: %v", err)
+	}
+	return nil
+}
+
+// CheckoutCopy lends an available copy to an active member, due back
+// after the loan period of the member's role. Members owing more than the
+// fine block threshold cannot borrow.
+func (s *circulationService) CheckoutCopy(ctx context.Context, req *model.LoanCheckoutRequest) (*model.LoanResponse, error) {
+	member, err := s.userRepo.FindByID(ctx, req.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrMemberNotFound
+		}
+		return nil, fmt.Errorf("failed to find member: %v", err)
+	}
+	if !member.IsActive {
+		return nil, service.ErrMemberInactive
+	}
+	if err := s.fines.CheckCanBorrow(ctx, member.ID); err != nil {
+		return nil, err
+	}
+
+	var loan *model.Loan
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		bookCopy, err := s.lockCopy(ctx, req.Barcode)
+		if err != nil {
+			return err
+		}
+		if bookCopy.Status != model.CopyStatusAvailable {
+			return fmt.Errorf("%w: copy is %s", service.ErrCopyNotAvailable, bookCopy.Status)
+		}
+		book, err := s.bookRepo.FindByID(ctx, bookCopy.BookID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return service.ErrBookNotFound
+			}
+			return fmt.Errorf("failed to find book: %v", err)
+		}
+
+		now := time.Now()
+		loan = &model.Loan{
+			ID:        uuid.New(),
+			CopyID:    bookCopy.ID,
+			BookID:    book.ID,
+			UserID:    member.ID,
+			Barcode:   bookCopy.Barcode,
+			Title:     book.Title,
+			Status:    model.LoanStatusActive,
+			LoanedAt:  now,
+			DueAt:     now.Add(s.loanPeriod(member.Role)),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := s.loanRepo.Create(ctx, loan); err != nil {
+			return fmt.Errorf("failed to create loan: %v", err)
+		}
+
+		bookCopy.Status = model.CopyStatusOnLoan
+		bookCopy.UpdatedAt = now
+		if err := s.copyRepo.Update(ctx, bookCopy); err != nil {
+			return fmt.Errorf("failed to update copy: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loan.ToDTO(), nil
+}
+
+// CheckinCopy ends the loan of a returned copy, charging the fine due if
+// it is late, and puts the copy back on the shelf, at the location it was
+// returned to when one is given
+func (s *circulationService) CheckinCopy(ctx context.Context, req *model.LoanCheckinRequest) (*model.LoanResponse, error) {
+	if req.LocationID != nil {
+		if err := s.checkLocation(ctx, *req.LocationID); err != nil {
+			return nil, err
+		}
+	}
+
+	var loan *model.Loan
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		bookCopy, err := s.lockCopy(ctx, req.Barcode)
+		if err != nil {
+			return err
+		}
+		if bookCopy.Status != model.CopyStatusOnLoan {
+			return service.ErrCopyNotOnLoan
+		}
+		loan, err = s.loanRepo.FindActiveByCopyID(ctx, bookCopy.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return service.ErrCopyNotOnLoan
+			}
+			return fmt.Errorf("failed to find loan: %v", err)
+		}
+		// Lock the loan against the nightly accrual charging it at the same time
+		loan, err = s.loanRepo.FindByIDForUpdate(ctx, loan.ID)
+		if err != nil {
+			return fmt.Errorf("failed to find loan: %v", err)
+		}
+
+		now := time.Now()
+		if err := s.fines.ChargeFine(ctx, loan, now); err != nil {
+			return err
+		}
+		loan.Status = model.LoanStatusReturned
+		loan.ReturnedAt = &now
+		loan.UpdatedAt = now
+		if err := s.loanRepo.Update(ctx, loan); err != nil {
+			return fmt.Errorf("failed to update loan: %v", err)
+		}
+
+		bookCopy.Status = model.CopyStatusAvailable
+		if req.LocationID != nil {
+			bookCopy.LocationID = req.LocationID
+		}
+		bookCopy.UpdatedAt = now
+		if err := s.copyRepo.Update(ctx, bookCopy); err != nil {
+			return fmt.Errorf("failed to update copy: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loan.ToDTO(), nil
+}
+
+// RenewLoan pushes the due date of an active loan back by the loan period
+// of the borrower's role. Members may renew their own loans until they
+// are overdue, up to the renewal limit.
+func (s *circulationService) RenewLoan(ctx context.Context, id string) (*model.LoanResponse, error) {
+	loanID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidLoanID, err)
+	}
+
+	var loan *model.Loan
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		loan, err = s.loanRepo.FindByIDForUpdate(ctx, loanID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return service.ErrLoanNotFound
+			}
+			return fmt.Errorf("failed to find loan: %v", err)
+		}
+		if !canSee(ctx, loan) {
+			return service.ErrLoanNotFound
+		}
+		if loan.Status != model.LoanStatusActive {
+			return service.ErrLoanNotActive
+		}
+		now := time.Now()
+		if loan.Overdue(now) {
+			return fmt.Errorf("%w: return it to the library", service.ErrLoanOverdue)
+		}
+		if loan.Renewals >= s.renewalLimit {
+			return fmt.Errorf("%w: renewed %d of %d times", service.ErrRenewalLimitReached, loan.Renewals, s.renewalLimit)
+		}
+
+		role := ""
+		member, err := s.userRepo.FindByID(ctx, loan.UserID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to find member: %v", err)
+		}
+		if member != nil {
+			role = member.Role
+		}
+
+		loan.DueAt = loan.DueAt.Add(s.loanPeriod(role))
+		loan.Renewals++
+		loan.UpdatedAt = now
+		if err := s.loanRepo.Update(ctx, loan); err != nil {
+			return fmt.Errorf("failed to update loan: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loan.ToDTO(), nil
+}
+
+// GetLoan gets a loan by ID. Members only see their own loans; admins see
+// every loan.
+func (s *circulationService) GetLoan(ctx context.Context, id string) (*model.LoanResponse, error) {
+	loanID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidLoanID, err)
+	}
+
+	loan, err := s.loanRepo.FindByID(ctx, loanID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrLoanNotFound
+		}
+		return nil, fmt.Errorf("failed to find loan: %v", err)
+	}
+	if !canSee(ctx, loan) {
+		return nil, service.ErrLoanNotFound
+	}
+
+	return loan.ToDTO(), nil
+}
+
+// ListMyLoans gets a paginated list of the current user's loans, newest
+// first. Status is active, overdue or returned; empty lists them all.
+func (s *circulationService) ListMyLoans(ctx context.Context, page, pageSize int, status string) (*model.LoanListResponse, error) {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.ListLoans(ctx, page, pageSize, status, map[string]any{"user_id = ?": userID})
+}
+
+// ListLoans gets a paginated list of all loans matching filters, newest
+// first. Status is active, overdue or returned; empty lists them all.
+func (s *circulationService) ListLoans(ctx context.Context, page, pageSize int, status string, filters map[string]any) (*model.LoanListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	switch status {
+	case "":
+	case model.LoanStatusActive, model.LoanStatusReturned:
+		filters["status = ?"] = status
+	case "overdue":
+		filters["status = ?"] = model.LoanStatusActive
+		filters["due_at < ?"] = time.Now()
+	default:
+		return nil, fmt.Errorf("%w: status must be active, overdue or returned", service.ErrInvalidLoanStatus)
+	}
+
+	loans, total, err := s.loanRepo.FindAll(ctx, page, pageSize, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list loans: %v", err)
+	}
+
+	loanDTOs := make([]*model.LoanResponse, len(loans))
+	for i, loan := range loans {
+		loanDTOs[i] = loan.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.LoanListResponse{
+		Data: loanDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// loanPeriod returns how long members with a role may keep a copy
+func (s *circulationService) loanPeriod(role string) time.Duration {
+	if period, ok := s.loanPeriods[role]; ok {
+		return period
+	}
+	return s.defaultLoanPeriod
+}
+
+// apply copies a request onto a copy after checking its barcode is free
+// and its location exists
+func (s *circulationService) apply(ctx context.Context, bookCopy *model.Copy, req *model.CopyRequest) error {
+	barcode := strings.TrimSpace(req.Barcode)
+	taken, err := s.copyRepo.ExistsByBarcode(ctx, barcode, bookCopy.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check copy barcode: %v", err)
+	}
+	if taken {
+		return fmt.Errorf("%w: %s", service.ErrCopyBarcodeTaken, barcode)
+	}
+	if req.LocationID != nil {
+		if err := s.checkLocation(ctx, *req.LocationID); err != nil {
+			return err
+		}
+	}
+
+	bookCopy.Barcode = barcode
+	bookCopy.LocationID = req.LocationID
+	if req.Status != "" {
+		bookCopy.Status = req.Status
+	}
+	return nil
+}
+
+// checkLocation checks a location exists
+func (s *circulationService) checkLocation(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.locationRepo.FindByID(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return service.ErrLocationNotFound
+		}
+		return fmt.Errorf("failed to find location: %v", err)
+	}
+	return nil
+}
+
+// lockCopy finds a copy by barcode and locks it for the surrounding transaction
+func (s *circulationService) lockCopy(ctx context.Context, barcode string) (*model.Copy, error) {
+	bookCopy, err := s.copyRepo.FindByBarcodeForUpdate(ctx, strings.TrimSpace(barcode))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrCopyNotFound
+		}
+		return nil, fmt.Errorf("failed to find copy: %v", err)
+	}
+	return bookCopy, nil
+}
+
+// findCopy finds a copy by ID
+func (s *circulationService) findCopy(ctx context.Context, id string) (*model.Copy, error) {
+	copyID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidCopyID, err)
+	}
+
+	bookCopy, err := s.copyRepo.FindByID(ctx, copyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrCopyNotFound
+		}
+		return nil, fmt.Errorf("failed to find copy: %v", err)
+	}
+	return bookCopy, nil
+}
+
+// findBook finds a book by ID
+func (s *circulationService) findBook(ctx context.Context, id string) (*model.Book, error) {
+	bookID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookID, err)
+	}
+
+	book, err := s.bookRepo.FindByID(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrBookNotFound
+		}
+		return nil, fmt.Errorf("failed to find book: %v", err)
+	}
+	return book, nil
+}
+
+// canSee reports whether the current user may see a loan
+func canSee(ctx context.Context, loan *model.Loan) bool {
+	return utils.UserRoleFromContext(ctx) == "admin" || utils.UserIDFromContext(ctx) == loan.UserID.String()
+}
@@ -0,0 +1,383 @@
+package circulation_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type fakeCopies struct {
+	repository.ICopyRepository
+	copies map[uuid.UUID]*model.Copy
+}
+
+func (r *fakeCopies) Create(ctx context.Context, bookCopy *model.Copy) error {
+	r.copies[bookCopy.ID] = bookCopy
+	return nil
+}
+
+func (r *fakeCopies) FindByBarcodeForUpdate(ctx context.Context, barcode string) (*model.Copy, error) {
+	for _, bookCopy := range r.copies {
+		if bookCopy.Barcode == barcode {
+			copied := *bookCopy
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeCopies) ExistsByBarcode(ctx context.Context, barcode string, excludeID uuid.UUID) (bool, error) {
+	for _, bookCopy := range r.copies {
+		if bookCopy.Barcode == barcode && bookCopy.ID != excludeID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeCopies) Update(ctx context.Context, bookCopy *model.Copy) error {
+	copied := *bookCopy
+	r.copies[bookCopy.ID] = &copied
+	return nil
+}
+
+type fakeLoans struct {
+	repository.ILoanRepository
+	loans map[uuid.UUID]*model.Loan
+}
+
+func (r *fakeLoans) Create(ctx context.Context, loan *model.Loan) error {
+	copied := *loan
+	r.loans[loan.ID] = &copied
+	return nil
+}
+
+func (r *fakeLoans) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Loan, error) {
+	loan, ok := r.loans[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *loan
+	return &copied, nil
+}
+
+func (r *fakeLoans) FindActiveByCopyID(ctx context.Context, copyID uuid.UUID) (*model.Loan, error) {
+	for _, loan := range r.loans {
+		if loan.CopyID == copyID && loan.Status == model.LoanStatusActive {
+			copied := *loan
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeLoans) Update(ctx context.Context, loan *model.Loan) error {
+	copied := *loan
+	r.loans[loan.ID] = &copied
+	return nil
+}
+
+type fakeLocations struct {
+	repository.ILocationRepository
+	location *model.Location
+}
+
+func (r *fakeLocations) FindByID(ctx context.Context, id uuid.UUID) (*model.Location, error) {
+	if id != r.location.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.location, nil
+}
+
+// fakeFines blocks the members in blocked and records the loans charged
+type fakeFines struct {
+	service.IFineService
+	blocked map[uuid.UUID]bool
+	charged []uuid.UUID
+}
+
+func (s *fakeFines) CheckCanBorrow(ctx context.Context, userID uuid.UUID) error {
+	if s.blocked[userID] {
+		return service.ErrBalanceOverLimit
+	}
+	return nil
+}
+
+func (s *fakeFines) ChargeFine(ctx context.Context, loan *model.Loan, at time.Time) error {
+	if loan.Overdue(at) {
+		s.charged = append(s.charged, loan.ID)
+	}
+	return nil
+}
+
+const (
+	day               = 24 * time.Hour
+	defaultLoanPeriod = 14 * day
+	staffLoanPeriod   = 28 * day
+	renewalLimit      = 2
+)
+
+// library wraps a circulation service with its fakes, holding one book,
+// one branch, an active member, a staff member and an inactive member
+type library struct {
+	service.ICirculationService
+	copies   *fakeCopies
+	loans    *fakeLoans
+	fines    *fakeFines
+	book     *model.Book
+	branch   *model.Location
+	member   *model.User
+	staff    *model.User
+	inactive *model.User
+}
+
+func newLibrary() *library {
+	l := &library{
+		copies:   &fakeCopies{copies: make(map[uuid.UUID]*model.Copy)},
+		loans:    &fakeLoans{loans: make(map[uuid.UUID]*model.Loan)},
+		fines:    &fakeFines{blocked: make(map[uuid.UUID]bool)},
+		book:     &model.Book{ID: uuid.New(), Title: "Dune"},
+		branch:   &model.Location{ID: uuid.New(), Code: "EAST"},
+		member:   &model.User{ID: uuid.New(), Role: "user", IsActive: true},
+		staff:    &model.User{ID: uuid.New(), Role: "staff", IsActive: true},
+		inactive: &model.User{ID: uuid.New(), Role: "user"},
+	}
+	users := repotest.NewUsers(l.member, l.staff, l.inactive)
+	l.ICirculationService = NewCirculationService(l.copies, l.loans, repotest.NewBooks(l.book),
+		&fakeLocations{location: l.branch}, users, l.fines, repotest.Transactor{},
+		map[string]time.Duration{"staff": staffLoanPeriod}, defaultLoanPeriod, renewalLimit)
+	return l
+}
+
+// addCopy shelves a copy of the book with a status
+func (l *library) addCopy(barcode, status string) *model.Copy {
+	bookCopy := &model.Copy{ID: uuid.New(), BookID: l.book.ID, Barcode: barcode, Status: status}
+	l.copies.copies[bookCopy.ID] = bookCopy
+	return bookCopy
+}
+
+// lend puts a loan of a new copy on the books for a member, due at dueAt
+func (l *library) lend(barcode string, member *model.User, dueAt time.Time, renewals int) *model.Loan {
+	bookCopy := l.addCopy(barcode, model.CopyStatusOnLoan)
+	loan := &model.Loan{ID: uuid.New(), CopyID: bookCopy.ID, BookID: l.book.ID, UserID: member.ID, Barcode: barcode,
+		Status: model.LoanStatusActive, LoanedAt: dueAt.Add(-defaultLoanPeriod), DueAt: dueAt, Renewals: renewals}
+	l.loans.loans[loan.ID] = loan
+	return loan
+}
+
+func TestCheckoutCopy(t *testing.T) {
+	tests := []struct {
+		name        string
+		barcode     string
+		copyStatus  string
+		borrower    func(l *library) uuid.UUID
+		blocked     bool
+		wantErr     error
+		wantLoanFor time.Duration
+	}{
+		{name: "member", barcode: "B1", borrower: func(l *library) uuid.UUID { return l.member.ID }, wantLoanFor: defaultLoanPeriod},
+		{name: "staff keep copies longer", barcode: "B1", borrower: func(l *library) uuid.UUID { return l.staff.ID }, wantLoanFor: staffLoanPeriod},
+		{name: "barcode is trimmed", barcode: " B1 ", borrower: func(l *library) uuid.UUID { return l.member.ID }, wantLoanFor: defaultLoanPeriod},
+		{name: "inactive member", barcode: "B1", borrower: func(l *library) uuid.UUID { return l.inactive.ID }, wantErr: service.ErrMemberInactive},
+		{name: "unknown member", barcode: "B1", borrower: func(l *library) uuid.UUID { return uuid.New() }, wantErr: service.ErrMemberNotFound},
+		{name: "member owing too much", barcode: "B1", borrower: func(l *library) uuid.UUID { return l.member.ID }, blocked: true, wantErr: service.ErrBalanceOverLimit},
+		{name: "copy on loan", barcode: "B1", copyStatus: model.CopyStatusOnLoan, borrower: func(l *library) uuid.UUID { return l.member.ID }, wantErr: service.ErrCopyNotAvailable},
+		{name: "lost copy", barcode: "B1", copyStatus: model.CopyStatusLost, borrower: func(l *library) uuid.UUID { return l.member.ID }, wantErr: service.ErrCopyNotAvailable},
+		{name: "unknown barcode", barcode: "B2", borrower: func(l *library) uuid.UUID { return l.member.ID }, wantErr: service.ErrCopyNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLibrary()
+			status := tt.copyStatus
+			if status == "" {
+				status = model.CopyStatusAvailable
+			}
+			bookCopy := l.addCopy("B1", status)
+			borrower := tt.borrower(l)
+			l.fines.blocked[borrower] = tt.blocked
+
+			loan, err := l.CheckoutCopy(context.Background(), &model.LoanCheckoutRequest{Barcode: tt.barcode, UserID: borrower})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckoutCopy() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if got := l.copies.copies[bookCopy.ID].Status; got != status || len(l.loans.loans) != 0 {
+					t.Errorf("refused checkout left the copy %s with %d loans", got, len(l.loans.loans))
+				}
+				return
+			}
+
+			if loan.CopyID != bookCopy.ID || loan.UserID != borrower || loan.Title != "Dune" || loan.Status != model.LoanStatusActive {
+				t.Errorf("loan = %+v", loan)
+			}
+			if got := loan.DueAt.Sub(loan.LoanedAt); got != tt.wantLoanFor {
+				t.Errorf("loan is due after %v, want %v", got, tt.wantLoanFor)
+			}
+			if got := l.copies.copies[bookCopy.ID].Status; got != model.CopyStatusOnLoan {
+				t.Errorf("copy status = %s, want %s", got, model.CopyStatusOnLoan)
+			}
+		})
+	}
+}
+
+func TestCheckinCopy(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		// dueAt is when the loan of the copy is due; zero shelves the copy instead
+		dueAt        time.Time
+		returnedTo   func(l *library) *uuid.UUID
+		wantErr      error
+		wantCharged  bool
+		wantLocation bool
+	}{
+		{name: "on time", dueAt: now.Add(day)},
+		{name: "late return is charged", dueAt: now.Add(-3 * day), wantCharged: true},
+		{name: "to another branch", dueAt: now.Add(day), returnedTo: func(l *library) *uuid.UUID { return &l.branch.ID }, wantLocation: true},
+		{name: "to an unknown branch", dueAt: now.Add(day), returnedTo: func(l *library) *uuid.UUID { id := uuid.New(); return &id }, wantErr: service.ErrLocationNotFound},
+		{name: "copy not on loan", wantErr: service.ErrCopyNotOnLoan},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLibrary()
+			var loan *model.Loan
+			if tt.dueAt.IsZero() {
+				l.addCopy("B1", model.CopyStatusAvailable)
+			} else {
+				loan = l.lend("B1", l.member, tt.dueAt, 0)
+			}
+			req := &model.LoanCheckinRequest{Barcode: "B1"}
+			if tt.returnedTo != nil {
+				req.LocationID = tt.returnedTo(l)
+			}
+
+			returned, err := l.CheckinCopy(context.Background(), req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckinCopy() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			saved := l.loans.loans[loan.ID]
+			if saved.Status != model.LoanStatusReturned || saved.ReturnedAt == nil || returned.ID != loan.ID {
+				t.Errorf("loan = %+v, want it returned", saved)
+			}
+			if charged := len(l.fines.charged) == 1; charged != tt.wantCharged {
+				t.Errorf("charged = %v, want %v", charged, tt.wantCharged)
+			}
+			bookCopy := l.copies.copies[loan.CopyID]
+			if bookCopy.Status != model.CopyStatusAvailable {
+				t.Errorf("copy status = %s, want %s", bookCopy.Status, model.CopyStatusAvailable)
+			}
+			if located := bookCopy.LocationID != nil && *bookCopy.LocationID == l.branch.ID; located != tt.wantLocation {
+				t.Errorf("copy shelved at %v", bookCopy.LocationID)
+			}
+		})
+	}
+}
+
+func TestRenewLoan(t *testing.T) {
+	dueAt := time.Now().Add(3 * day)
+
+	tests := []struct {
+		name     string
+		borrower func(l *library) *model.User
+		// asAdmin and asOther renew as an admin or as another member rather
+		// than as the borrower
+		asAdmin   bool
+		asOther   bool
+		dueAt     time.Time
+		renewals  int
+		returned  bool
+		wantErr   error
+		wantDueAt time.Time
+	}{
+		{name: "own loan", borrower: func(l *library) *model.User { return l.member }, dueAt: dueAt, wantDueAt: dueAt.Add(defaultLoanPeriod)},
+		{name: "staff loan period", borrower: func(l *library) *model.User { return l.staff }, dueAt: dueAt, wantDueAt: dueAt.Add(staffLoanPeriod)},
+		{name: "last renewal", borrower: func(l *library) *model.User { return l.member }, dueAt: dueAt, renewals: renewalLimit - 1, wantDueAt: dueAt.Add(defaultLoanPeriod)},
+		{name: "admin renews for a member", borrower: func(l *library) *model.User { return l.member }, asAdmin: true, dueAt: dueAt, wantDueAt: dueAt.Add(defaultLoanPeriod)},
+		{name: "renewal limit reached", borrower: func(l *library) *model.User { return l.member }, dueAt: dueAt, renewals: renewalLimit, wantErr: service.ErrRenewalLimitReached},
+		{name: "overdue", borrower: func(l *library) *model.User { return l.member }, dueAt: time.Now().Add(-day), wantErr: service.ErrLoanOverdue},
+		{name: "returned", borrower: func(l *library) *model.User { return l.member }, dueAt: dueAt, returned: true, wantErr: service.ErrLoanNotActive},
+		{name: "another member's loan", borrower: func(l *library) *model.User { return l.member }, asOther: true, dueAt: dueAt, wantErr: service.ErrLoanNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLibrary()
+			borrower := tt.borrower(l)
+			loan := l.lend("B1", borrower, tt.dueAt, tt.renewals)
+			if tt.returned {
+				loan.Status = model.LoanStatusReturned
+			}
+
+			ctx := utils.WithCurrentUser(context.Background(), borrower.ID.String(), borrower.Role)
+			switch {
+			case tt.asAdmin:
+				ctx = utils.WithCurrentUser(context.Background(), uuid.NewString(), "admin")
+			case tt.asOther:
+				ctx = utils.WithCurrentUser(context.Background(), l.staff.ID.String(), l.staff.Role)
+			}
+
+			renewed, err := l.RenewLoan(ctx, loan.ID.String())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RenewLoan() error = %v, want %v", err, tt.wantErr)
+			}
+			saved := l.loans.loans[loan.ID]
+			if err != nil {
+				if !saved.DueAt.Equal(tt.dueAt) || saved.Renewals != tt.renewals {
+					t.Errorf("refused renewal moved the loan to %v after %d renewals", saved.DueAt, saved.Renewals)
+				}
+				return
+			}
+
+			if !renewed.DueAt.Equal(tt.wantDueAt) || !saved.DueAt.Equal(tt.wantDueAt) {
+				t.Errorf("loan due at %v, want %v", saved.DueAt, tt.wantDueAt)
+			}
+			if saved.Renewals != tt.renewals+1 {
+				t.Errorf("renewals = %d, want %d", saved.Renewals, tt.renewals+1)
+			}
+		})
+	}
+}
+
+func TestAddCopy(t *testing.T) {
+	l := newLibrary()
+	l.addCopy("B1", model.CopyStatusAvailable)
+	unknown := uuid.New()
+
+	tests := []struct {
+		name    string
+		req     *model.CopyRequest
+		wantErr error
+	}{
+		{name: "new barcode", req: &model.CopyRequest{Barcode: " B2 ", LocationID: &l.branch.ID}},
+		{name: "barcode taken", req: &model.CopyRequest{Barcode: "B1"}, wantErr: service.ErrCopyBarcodeTaken},
+		{name: "unknown branch", req: &model.CopyRequest{Barcode: "B3", LocationID: &unknown}, wantErr: service.ErrLocationNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bookCopy, err := l.AddCopy(context.Background(), l.book.ID.String(), tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AddCopy() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (bookCopy.Barcode != "B2" || bookCopy.Status != model.CopyStatusAvailable) {
+				t.Errorf("AddCopy() = %+v", bookCopy)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"book_system/internal/utils"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// CurrentUserID gets the ID of the authenticated user from ctx, or
+// ErrInvalidUserID when ctx carries none
+func CurrentUserID(ctx context.Context) (uuid.UUID, error) {
+	userID, err := uuid.Parse(utils.UserIDFromContext(ctx))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %v", ErrInvalidUserID, err)
+	}
+	return userID, nil
+}
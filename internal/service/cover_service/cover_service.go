@@ -0,0 +1,127 @@
+package cover_service
+
+import (
+	"book_system/internal/baselib/imaging"
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// MaxCoverSize bounds uploaded cover images
+const MaxCoverSize = 10 << 20 // 10 MB
+
+// coverVariant is one resized copy made of every cover
+type coverVariant struct {
+	name      string
+	maxWidth  int
+	maxHeight int
+	// set stores the variant's URL on the cover
+	set func(cover *model.BookCover, url string)
+}
+
+var coverVariants = []coverVariant{
+	{"thumbnail", 160, 240, func(c *model.BookCover, url string) { c.Thumbnail = url }},
+	{"medium", 400, 600, func(c *model.BookCover, url string) { c.Medium = url }},
+	{"large", 800, 1200, func(c *model.BookCover, url string) { c.Large = url }},
+}
+
+type bookCoverService struct {
+	bookService   service.IBookService
+	uploadService service.IUploadService
+}
+
+// NewBookCoverService creates a new book cover service
+func NewBookCoverService(bookService service.IBookService, uploadService service.IUploadService) service.IBookCoverService {
+	return &bookCoverService{
+		bookService:   bookService,
+		uploadService: uploadService,
+	}
+}
+
+// UploadCover stores resized variants of a cover image and sets them on
+// the book, whose service deletes the variants they replace. The image is
+// re-encoded, so no EXIF or other metadata of the upload is kept.
+func (s *bookCoverService) UploadCover(ctx context.Context, id string, upload io.Reader, expectedVersion int) (*model.BookResponse, error) {
+	data, err := io.ReadAll(io.LimitReader(upload, MaxCoverSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cover: %v", err)
+	}
+	if len(data) > MaxCoverSize {
+		return nil, fmt.Errorf("%w: file size exceeds the limit of 10MB", service.ErrInvalidCover)
+	}
+
+	book, err := s.bookService.GetBookByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := imaging.Decode(data)
+	if err != nil {
+		if errors.Is(err, imaging.ErrUnsupported) {
+			return nil, fmt.Errorf("%w: must be a JPEG, PNG or WebP image", service.ErrInvalidCover)
+		}
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidCover, err)
+	}
+
+	cover, err := s.storeVariants(ctx, book.ID, img)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.bookService.UpdateBook(ctx, id, &model.UpdateBookRequest{
+		CoverImage: &cover.Large,
+		Cover:      cover,
+	}, expectedVersion)
+	if err != nil {
+		s.deleteVariants(ctx, cover)
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// storeVariants uploads every variant of img under a prefix of its own,
+// so replaced covers never share URLs with the new one
+func (s *bookCoverService) storeVariants(ctx context.Context, bookID uuid.UUID, img image.Image) (*model.BookCover, error) {
+	prefix := fmt.Sprintf("covers/%s/%s/", bookID, uuid.New())
+	cover := &model.BookCover{}
+
+	for _, variant := range coverVariants {
+		var buf bytes.Buffer
+		contentType, extension, err := imaging.Encode(&buf, imaging.Fit(img, variant.maxWidth, variant.maxHeight))
+		if err != nil {
+			s.deleteVariants(ctx, cover)
+			return nil, fmt.Errorf("failed to encode %s cover: %v", variant.name, err)
+		}
+
+		objectName := prefix + variant.name + extension
+		if err := s.uploadService.PutObject(ctx, objectName, &buf, int64(buf.Len()), contentType); err != nil {
+			s.deleteVariants(ctx, cover)
+			return nil, fmt.Errorf("failed to store %s cover: %v", variant.name, err)
+		}
+		variant.set(cover, s.uploadService.ObjectURL(objectName))
+	}
+
+	return cover, nil
+}
+
+// deleteVariants removes the stored variants of a cover. Failures only
+// leave unused objects behind, so they are logged rather than returned.
+func (s *bookCoverService) deleteVariants(ctx context.Context, cover *model.BookCover) {
+	for _, url := range cover.URLs() {
+		if url == "" {
+			continue
+		}
+		if err := s.uploadService.DeleteFile(ctx, url); err != nil {
+			slog.Warn("Failed to delete cover variant", slog.String("url", url), slog.Any("error", err))
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package cover_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type storedObject struct {
+	data        []byte
+	contentType string
+}
+
+// fakeUploads keeps stored objects in memory, keyed by URL
+type fakeUploads struct {
+	service.IUploadService
+	objects map[string]storedObject
+}
+
+func (u *fakeUploads) PutObject(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	u.objects[u.ObjectURL(objectName)] = storedObject{data: data, contentType: contentType}
+	return nil
+}
+
+func (u *fakeUploads) ObjectURL(objectName string) string {
+	return "https://storage.example/" + objectName
+}
+
+func (u *fakeUploads) DeleteFile(ctx context.Context, objectName string) error {
+	delete(u.objects, objectName)
+	return nil
+}
+
+// fakeBooks records the update setting the cover, failing it with err
+type fakeBooks struct {
+	service.IBookService
+	id     uuid.UUID
+	update *model.UpdateBookRequest
+	err    error
+}
+
+func (s *fakeBooks) GetBookByID(ctx context.Context, id string) (*model.BookResponse, error) {
+	if id != s.id.String() {
+		return nil, service.ErrBookNotFound
+	}
+	return &model.BookResponse{ID: s.id}, nil
+}
+
+func (s *fakeBooks) UpdateBook(ctx context.Context, id string, req *model.UpdateBookRequest, expectedVersion int) (*model.BookResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.update = req
+	return &model.BookResponse{ID: s.id, CoverImage: *req.CoverImage, Cover: req.Cover}, nil
+}
+
+// photo encodes a width x height JPEG carrying an EXIF segment that
+// rotates it 90 degrees clockwise
+func photo(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetGray(x, y, color.Gray{Y: uint8(x + y)})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+
+	tiff := make([]byte, 26)
+	copy(tiff, "MM")
+	binary.BigEndian.PutUint16(tiff[2:], 42)
+	binary.BigEndian.PutUint32(tiff[4:], 8)       // first IFD
+	binary.BigEndian.PutUint16(tiff[8:], 1)       // one entry
+	binary.BigEndian.PutUint16(tiff[10:], 0x0112) // orientation
+	binary.BigEndian.PutUint16(tiff[12:], 3)      // SHORT
+	binary.BigEndian.PutUint32(tiff[14:], 1)      // count
+	binary.BigEndian.PutUint16(tiff[18:], 6)      // rotated 90 clockwise
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), append(app1, segment...)...), data[2:]...)
+}
+
+func TestUploadCover(t *testing.T) {
+	books := &fakeBooks{id: uuid.New()}
+	uploads := &fakeUploads{objects: make(map[string]storedObject)}
+	s := NewBookCoverService(books, uploads)
+
+	// Stored 1000x1500, displayed 1500x1000 once turned upright
+	resp, err := s.UploadCover(context.Background(), books.id.String(), bytes.NewReader(photo(t, 1000, 1500)), 1)
+	if err != nil {
+		t.Fatalf("UploadCover() error = %v", err)
+	}
+	if books.update == nil || *books.update.CoverImage != resp.Cover.Large {
+		t.Fatalf("book update = %+v, want the large variant as cover image", books.update)
+	}
+
+	wantSizes := []struct {
+		url           string
+		width, height int
+	}{
+		{resp.Cover.Thumbnail, 160, 107},
+		{resp.Cover.Medium, 400, 267},
+		{resp.Cover.Large, 800, 533},
+	}
+	if len(uploads.objects) != len(wantSizes) {
+		t.Errorf("stored %d objects, want %d", len(uploads.objects), len(wantSizes))
+	}
+	for _, want := range wantSizes {
+		object, ok := uploads.objects[want.url]
+		if !ok {
+			t.Errorf("variant %q not stored", want.url)
+			continue
+		}
+		if object.contentType != "image/jpeg" || !strings.HasSuffix(want.url, ".jpg") {
+			t.Errorf("variant %q stored as %s", want.url, object.contentType)
+		}
+		if bytes.Contains(object.data, []byte("Exif\x00\x00")) {
+			t.Errorf("variant %q kept the EXIF segment", want.url)
+		}
+		config, err := jpeg.DecodeConfig(bytes.NewReader(object.data))
+		if err != nil {
+			t.Fatalf("variant %q: jpeg.DecodeConfig() error = %v", want.url, err)
+		}
+		if config.Width != want.width || config.Height != want.height {
+			t.Errorf("variant %q is %dx%d, want %dx%d", want.url, config.Width, config.Height, want.width, want.height)
+		}
+	}
+}
+
+func TestUploadCoverRejected(t *testing.T) {
+	// A PNG claiming dimensions above the pixel limit, with a valid header checksum
+	var huge bytes.Buffer
+	if err := png.Encode(&huge, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	hugeData := huge.Bytes()
+	binary.BigEndian.PutUint32(hugeData[16:], 10_000)
+	binary.BigEndian.PutUint32(hugeData[20:], 10_000)
+	binary.BigEndian.PutUint32(hugeData[29:], crc32.ChecksumIEEE(hugeData[12:29]))
+
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, image.NewGray(image.Rect(0, 0, 2, 2)), nil); err != nil {
+		t.Fatalf("gif.Encode() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		data      []byte
+		updateErr error
+		wantErr   error
+	}{
+		{name: "not an image", data: []byte("%PDF-1.7 not a cover"), wantErr: service.ErrInvalidCover},
+		{name: "unsupported format", data: gifData.Bytes(), wantErr: service.ErrInvalidCover},
+		{name: "file over the size limit", data: bytes.Repeat([]byte{0}, MaxCoverSize+1), wantErr: service.ErrInvalidCover},
+		{name: "too many pixels", data: hugeData, wantErr: service.ErrInvalidCover},
+		{name: "book update fails", data: photo(t, 30, 40), updateErr: service.ErrBookVersionMismatch,
+			wantErr: service.ErrBookVersionMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			books := &fakeBooks{id: uuid.New(), err: tt.updateErr}
+			uploads := &fakeUploads{objects: make(map[string]storedObject)}
+			s := NewBookCoverService(books, uploads)
+
+			_, err := s.UploadCover(context.Background(), books.id.String(), bytes.NewReader(tt.data), 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UploadCover() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(uploads.objects) != 0 {
+				t.Errorf("%d objects left stored", len(uploads.objects))
+			}
+			if books.update != nil {
+				t.Errorf("book updated with %+v", books.update)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import "errors"
+
+// Book errors returned by IBookService implementations.
+// Controllers match them with errors.Is to pick the HTTP status.
+var (
+	ErrInvalidBookID  = errors.New("invalid book ID format")
+	ErrBookNotFound   = errors.New("book not found")
+	ErrBookISBNExists = errors.New("book with this ISBN already exists")
+
+	ErrBookVersionNotFound = errors.New("book version not found")
+	ErrBookVersionMismatch = errors.New("book has been modified since it was read")
+	ErrUnsupportedPatch    = errors.New("unsupported patch media type")
+	ErrInvalidBookPatch    = errors.New("invalid book patch")
+	ErrBookPatchTestFailed = errors.New("book patch test failed")
+	ErrInvalidCover        = errors.New("invalid cover image")
+	ErrUnsupportedLanguage = errors.New("unsupported language")
+	ErrTranslationNotFound = errors.New("book translation not found")
+	ErrInvalidBookSort     = errors.New("invalid book sort")
+	ErrStockNotEditable    = errors.New("stock cannot be set by a book update, adjust it through POST /books/{id}/stock/adjust")
+)
+
+// Inventory errors
+var (
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrInvalidLocationID = errors.New("invalid location ID format")
+	ErrLocationNotFound  = errors.New("location not found")
+	ErrLocationCodeTaken = errors.New("location code is already taken")
+	ErrLocationHasStock  = errors.New("location still has stock")
+)
+
+// Cart and order errors
+var (
+	ErrInvalidUserID          = errors.New("invalid user ID format")
+	ErrInvalidCartQuantity    = errors.New("invalid cart quantity")
+	ErrCartEmpty              = errors.New("cart is empty")
+	ErrInvalidOrderID         = errors.New("invalid order ID format")
+	ErrOrderNotFound          = errors.New("order not found")
+	ErrInvalidOrderTransition = errors.New("order cannot move to this status")
+)
+
+// Payment errors
+var (
+	ErrInvalidPaymentID         = errors.New("invalid payment ID format")
+	ErrPaymentNotFound          = errors.New("payment not found")
+	ErrOrderNotPayable          = errors.New("order is not awaiting payment")
+	ErrInvalidPaymentTransition = errors.New("payment cannot move to this status")
+	ErrInvalidWebhookSignature  = errors.New("invalid webhook signature")
+	ErrPaymentProviderError     = errors.New("payment provider is unavailable")
+	ErrPaymentIntentNotFound    = errors.New("payment provider does not know this payment")
+	ErrNoPaymentProvider        = errors.New("no payment provider configured")
+)
+
+// Pricing errors
+var (
+	ErrInvalidPriceRuleID  = errors.New("invalid price rule ID format")
+	ErrPriceRuleNotFound   = errors.New("price rule not found")
+	ErrInvalidCouponID     = errors.New("invalid coupon ID format")
+	ErrCouponNotFound      = errors.New("coupon not found")
+	ErrCouponCodeTaken     = errors.New("coupon code is already taken")
+	ErrCouponNotApplicable = errors.New("coupon cannot be applied")
+	ErrCouponExhausted     = errors.New("coupon has no uses left")
+)
+
+// Currency errors
+var (
+	ErrInvalidCurrency         = errors.New("invalid currency code")
+	ErrExchangeRateNotFound    = errors.New("no exchange rate between these currencies")
+	ErrBookPriceNotFound       = errors.New("book has no list price in this currency")
+	ErrInvalidExchangeRateFile = errors.New("invalid exchange rate file")
+)
+
+// Circulation errors
+var (
+	ErrInvalidCopyID       = errors.New("invalid copy ID format")
+	ErrCopyNotFound        = errors.New("copy not found")
+	ErrCopyBarcodeTaken    = errors.New("copy barcode is already taken")
+	ErrCopyNotAvailable    = errors.New("copy is not available for loan")
+	ErrCopyOnLoan          = errors.New("copy is on loan")
+	ErrCopyNotOnLoan       = errors.New("copy is not on loan")
+	ErrMemberNotFound      = errors.New("member not found")
+	ErrMemberInactive      = errors.New("member account is inactive")
+	ErrInvalidLoanID       = errors.New("invalid loan ID format")
+	ErrLoanNotFound        = errors.New("loan not found")
+	ErrInvalidLoanStatus   = errors.New("invalid loan status")
+	ErrLoanNotActive       = errors.New("loan has been returned")
+	ErrLoanOverdue         = errors.New("loan is overdue")
+	ErrRenewalLimitReached = errors.New("loan cannot be renewed again")
+)
+
+// Hold and notification errors
+var (
+	ErrInvalidHoldID         = errors.New("invalid hold ID format")
+	ErrHoldNotFound          = errors.New("hold not found")
+	ErrHoldExists            = errors.New("book is already on hold for this user")
+	ErrHoldNotActive         = errors.New("hold is no longer active")
+	ErrBookInStock           = errors.New("book is in stock")
+	ErrInvalidNotificationID = errors.New("invalid notification ID format")
+	ErrNotificationNotFound  = errors.New("notification not found")
+)
+
+// Fine and account errors
+var (
+	ErrInvalidFinePolicyID  = errors.New("invalid fine policy ID format")
+	ErrFinePolicyNotFound   = errors.New("fine policy not found")
+	ErrFinePolicyRoleTaken  = errors.New("role already has a fine policy")
+	ErrInvalidAmount        = errors.New("invalid amount")
+	ErrCreditExceedsBalance = errors.New("amount exceeds the balance owed")
+	ErrBalanceOverLimit     = errors.New("member owes too much to borrow")
+)
+
+// Review errors
+var (
+	ErrInvalidReviewID     = errors.New("invalid review ID format")
+	ErrReviewNotFound      = errors.New("review not found")
+	ErrReviewExists        = errors.New("book has already been reviewed by this user")
+	ErrInvalidReviewStatus = errors.New("invalid review status")
+)
+
+// Reading list errors
+var (
+	ErrInvalidReadingListID   = errors.New("invalid reading list ID format")
+	ErrReadingListNotFound    = errors.New("reading list not found")
+	ErrInvalidReadingListName = errors.New("invalid reading list name")
+	ErrReadingListNameTaken   = errors.New("reading list name is already taken")
+	ErrReadingListBuiltIn     = errors.New("built-in reading lists cannot be deleted")
+	ErrBookOnList             = errors.New("book is already on this list")
+	ErrBookNotOnList          = errors.New("book is not on this list")
+	ErrInvalidListOrder       = errors.New("invalid reading list order")
+	ErrInvalidProgress        = errors.New("invalid reading progress")
+	ErrProgressNotTracked     = errors.New("reading progress is only tracked on the reading list")
+)
+
+// Work and series errors
+var (
+	ErrInvalidWorkID       = errors.New("invalid work ID format")
+	ErrWorkNotFound        = errors.New("work not found")
+	ErrWorkHasEditions     = errors.New("work still has editions")
+	ErrWorkNotInSeries     = errors.New("work is not part of a series")
+	ErrInvalidSeriesID     = errors.New("invalid series ID format")
+	ErrSeriesNotFound      = errors.New("series not found")
+	ErrSeriesPositionTaken = errors.New("series position is already taken")
+)
+
+// Category errors
+var (
+	ErrInvalidCategoryID = errors.New("invalid category ID format")
+	ErrCategoryNotFound  = errors.New("category not found")
+	ErrCategoryNameTaken = errors.New("category name is already taken")
+	ErrCategoryHasBooks  = errors.New("category still has books")
+)
+
+// Metadata lookup errors
+var (
+	ErrInvalidISBN           = errors.New("invalid ISBN")
+	ErrBookMetadataNotFound  = errors.New("no metadata found for this ISBN")
+	ErrMetadataProviderError = errors.New("metadata provider is unavailable")
+)
+
+// Job errors
+var (
+	ErrJobNotFound   = errors.New("job not found")
+	ErrInvalidImport = errors.New("invalid import")
+	ErrInvalidExport = errors.New("invalid export")
+	ErrJobQueueFull  = errors.New("too many jobs are waiting, try again later")
+)
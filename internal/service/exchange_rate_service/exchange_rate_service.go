@@ -0,0 +1,180 @@
+package exchange_rate_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type exchangeRateService struct {
+	repo repository.IExchangeRateRepository
+}
+
+// NewExchangeRateService creates a new exchange rate service
+func NewExchangeRateService(repo repository.IExchangeRateRepository) service.IExchangeRateService {
+	return &exchangeRateService{
+		repo: repo,
+	}
+}
+
+// RecordRates records new exchange rate snapshots. Earlier snapshots are
+// kept, so prices converted with them can still be explained.
+func (s *exchangeRateService) RecordRates(ctx context.Context, req *model.ExchangeRateBatchRequest) ([]*model.ExchangeRateResponse, error) {
+	return s.record(ctx, req.Rates, model.ExchangeRateSourceManual)
+}
+
+// ImportRates records the exchange rates of a CSV file with the columns
+// base_currency, quote_currency, rate and optionally effective_at, in any
+// order. The file is recorded whole or not at all.
+func (s *exchangeRateService) ImportRates(ctx context.Context, file io.Reader) ([]*model.ExchangeRateResponse, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot read header: %v", service.ErrInvalidExchangeRateFile, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"base_currency", "quote_currency", "rate"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: header is missing the %s column", service.ErrInvalidExchangeRateFile, required)
+		}
+	}
+
+	var rates []*model.ExchangeRateRequest
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", service.ErrInvalidExchangeRateFile, err)
+		}
+		if len(rates) == model.MaxExchangeRateBatch {
+			return nil, fmt.Errorf("%w: at most %d rates per file", service.ErrInvalidExchangeRateFile, model.MaxExchangeRateBatch)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		rate := &model.ExchangeRateRequest{
+			BaseCurrency:  strings.ToUpper(field("base_currency")),
+			QuoteCurrency: strings.ToUpper(field("quote_currency")),
+		}
+		if rate.Rate, err = decimal.NewFromString(field("rate")); err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid rate %q", service.ErrInvalidExchangeRateFile, line, field("rate"))
+		}
+		if effectiveAt := field("effective_at"); effectiveAt != "" {
+			t, err := time.Parse(time.RFC3339, effectiveAt)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: invalid effective_at %q", service.ErrInvalidExchangeRateFile, line, effectiveAt)
+			}
+			rate.EffectiveAt = &t
+		}
+		if err := rate.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", service.ErrInvalidExchangeRateFile, line, err)
+		}
+		rates = append(rates, rate)
+	}
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("%w: no rates", service.ErrInvalidExchangeRateFile)
+	}
+
+	return s.record(ctx, rates, model.ExchangeRateSourceImport)
+}
+
+// ListRates gets a paginated history of exchange rates, latest first
+func (s *exchangeRateService) ListRates(ctx context.Context, page, pageSize int, filters map[string]any) (*model.ExchangeRateListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	rates, total, err := s.repo.FindAll(ctx, page, pageSize, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exchange rates: %v", err)
+	}
+
+	rateDTOs := make([]*model.ExchangeRateResponse, len(rates))
+	for i, rate := range rates {
+		rateDTOs[i] = rate.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.ExchangeRateListResponse{
+		Data: rateDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// LatestRates gets the rate in force for every currency pair
+func (s *exchangeRateService) LatestRates(ctx context.Context) ([]*model.ExchangeRateResponse, error) {
+	rates, err := s.repo.FindLatest(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find exchange rates: %v", err)
+	}
+
+	rateDTOs := make([]*model.ExchangeRateResponse, len(rates))
+	for i, rate := range rates {
+		rateDTOs[i] = rate.ToDTO()
+	}
+	return rateDTOs, nil
+}
+
+// record saves validated rate requests as snapshots from a source
+func (s *exchangeRateService) record(ctx context.Context, reqs []*model.ExchangeRateRequest, source string) ([]*model.ExchangeRateResponse, error) {
+	now := time.Now()
+	rates := make([]*model.ExchangeRate, len(reqs))
+	for i, req := range reqs {
+		effectiveAt := now
+		if req.EffectiveAt != nil {
+			effectiveAt = *req.EffectiveAt
+		}
+		rates[i] = &model.ExchangeRate{
+			ID:            uuid.New(),
+			BaseCurrency:  req.BaseCurrency,
+			QuoteCurrency: req.QuoteCurrency,
+			Rate:          req.Rate,
+			Source:        source,
+			EffectiveAt:   effectiveAt,
+			CreatedAt:     now,
+		}
+	}
+
+	if err := s.repo.Create(ctx, rates); err != nil {
+		return nil, fmt.Errorf("failed to record exchange rates: %v", err)
+	}
+
+	rateDTOs := make([]*model.ExchangeRateResponse, len(rates))
+	for i, rate := range rates {
+		rateDTOs[i] = rate.ToDTO()
+	}
+	return rateDTOs, nil
+}
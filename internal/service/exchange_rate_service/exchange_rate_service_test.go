@@ -0,0 +1,116 @@
+package exchange_rate_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type fakeRates struct {
+	repository.IExchangeRateRepository
+	rates []*model.ExchangeRate
+}
+
+func (r *fakeRates) Create(ctx context.Context, rates []*model.ExchangeRate) error {
+	r.rates = append(r.rates, rates...)
+	return nil
+}
+
+func TestImportRates(t *testing.T) {
+	tooMany := "base_currency,quote_currency,rate\n" + strings.Repeat("EUR,USD,1.1\n", model.MaxExchangeRateBatch+1)
+
+	tests := []struct {
+		name string
+		file string
+		// wantRates lists the recorded pairs and rates, in order
+		wantRates []string
+		wantErr   string
+	}{
+		{
+			name:      "columns in any order",
+			file:      "rate,quote_currency,base_currency\n1.1,usd,eur\n0.0062,EUR,JPY\n",
+			wantRates: []string{"EUR/USD 1.1", "JPY/EUR 0.0062"},
+		},
+		{
+			name:      "byte order mark and effective date",
+			file:      "\ufeffBase_Currency, quote_currency, rate, effective_at\nGBP, EUR, 1.17, 2024-05-01T00:00:00Z\n",
+			wantRates: []string{"GBP/EUR 1.17"},
+		},
+		{name: "missing column", file: "base_currency,rate\nEUR,1.1\n", wantErr: "missing the quote_currency column"},
+		{name: "invalid rate", file: "base_currency,quote_currency,rate\nEUR,USD,1.1\nEUR,GBP,high\n", wantErr: `line 3: invalid rate "high"`},
+		{name: "invalid date", file: "base_currency,quote_currency,rate,effective_at\nEUR,USD,1.1,today\n", wantErr: "line 2: invalid effective_at"},
+		{name: "same currency", file: "base_currency,quote_currency,rate\nEUR,EUR,1\n", wantErr: "line 2: base_currency and quote_currency must differ"},
+		{name: "negative rate", file: "base_currency,quote_currency,rate\nEUR,USD,-1\n", wantErr: "line 2: rate must be positive"},
+		{name: "no rates", file: "base_currency,quote_currency,rate\n", wantErr: "no rates"},
+		{name: "too many rates", file: tooMany, wantErr: fmt.Sprintf("at most %d rates", model.MaxExchangeRateBatch)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRates{}
+			s := NewExchangeRateService(repo)
+
+			_, err := s.ImportRates(context.Background(), strings.NewReader(tt.file))
+			if tt.wantErr != "" {
+				if !errors.Is(err, service.ErrInvalidExchangeRateFile) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ImportRates() error = %v, want %q", err, tt.wantErr)
+				}
+				// A file is recorded whole or not at all
+				if len(repo.rates) != 0 {
+					t.Errorf("recorded %d rates from a bad file", len(repo.rates))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ImportRates() error = %v", err)
+			}
+
+			var got []string
+			for _, rate := range repo.rates {
+				got = append(got, rate.BaseCurrency+"/"+rate.QuoteCurrency+" "+rate.Rate.String())
+				if rate.Source != model.ExchangeRateSourceImport {
+					t.Errorf("rate recorded from %q, want %q", rate.Source, model.ExchangeRateSourceImport)
+				}
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.wantRates, ", ") {
+				t.Errorf("recorded %q, want %q", got, tt.wantRates)
+			}
+		})
+	}
+}
+
+func TestRecordRates(t *testing.T) {
+	repo := &fakeRates{}
+	s := NewExchangeRateService(repo)
+	effectiveAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	before := time.Now()
+	rates, err := s.RecordRates(context.Background(), &model.ExchangeRateBatchRequest{Rates: []*model.ExchangeRateRequest{
+		{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: decimal.RequireFromString("1.1")},
+		{BaseCurrency: "EUR", QuoteCurrency: "GBP", Rate: decimal.RequireFromString("0.85"), EffectiveAt: &effectiveAt},
+	}})
+	if err != nil {
+		t.Fatalf("RecordRates() error = %v", err)
+	}
+	if len(rates) != 2 || len(repo.rates) != 2 {
+		t.Fatalf("RecordRates() returned %d and recorded %d rates, want 2", len(rates), len(repo.rates))
+	}
+
+	// Rates without a date take effect when they are recorded
+	if repo.rates[0].EffectiveAt.Before(before) || !repo.rates[1].EffectiveAt.Equal(effectiveAt) {
+		t.Errorf("rates take effect at %v and %v", repo.rates[0].EffectiveAt, repo.rates[1].EffectiveAt)
+	}
+	for _, rate := range repo.rates {
+		if rate.Source != model.ExchangeRateSourceManual {
+			t.Errorf("rate recorded from %q, want %q", rate.Source, model.ExchangeRateSourceManual)
+		}
+	}
+}
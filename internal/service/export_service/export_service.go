@@ -0,0 +1,223 @@
+package export_service
+
+import (
+	"book_system/internal/baselib/workqueue"
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// batchSize is how many books are loaded from the database at a time
+	batchSize = 500
+	// maxConcurrentExports bounds how many exports run at once; later ones wait as pending
+	maxConcurrentExports = 2
+	// maxQueuedExports bounds how many exports may wait as pending
+	maxQueuedExports = 100
+)
+
+// errInterrupted fails the exports that a shutdown stopped or kept from starting
+var errInterrupted = errors.New("interrupted by a shutdown, start the export again")
+
+// exportTask is a queued export job and the books it selects
+type exportTask struct {
+	job     *model.Job
+	format  string
+	filters map[string]any
+}
+
+type bookExportService struct {
+	bookRepo      repository.IBookRepository
+	jobRepo       repository.IJobRepository
+	uploadService service.IUploadService
+	onix          onixSettings
+	queue         *workqueue.Queue[*exportTask]
+	// startedAt separates the jobs of this process from those left by earlier ones
+	startedAt time.Time
+}
+
+// NewBookExportService creates a new book export service. ONIX messages
+// are sent as onixSender, with prices in currency.
+func NewBookExportService(
+	bookRepo repository.IBookRepository,
+	jobRepo repository.IJobRepository,
+	uploadService service.IUploadService,
+	onixSender string,
+	currency string,
+) service.IBookExportService {
+	return &bookExportService{
+		bookRepo:      bookRepo,
+		jobRepo:       jobRepo,
+		uploadService: uploadService,
+		onix:          onixSettings{sender: onixSender, currency: currency},
+		queue:         workqueue.New[*exportTask](maxConcurrentExports, maxQueuedExports),
+		startedAt:     time.Now(),
+	}
+}
+
+// ExportBooks streams the books matching filters to w in the given format
+func (s *bookExportService) ExportBooks(ctx context.Context, format string, filters map[string]any, w io.Writer) error {
+	_, err := s.export(ctx, format, filters, w)
+	return err
+}
+
+// export streams the books matching filters to w and returns how many were written
+func (s *bookExportService) export(ctx context.Context, format string, filters map[string]any, w io.Writer) (int, error) {
+	writer, err := newRowWriter(format, w, s.onix)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", service.ErrInvalidExport, err)
+	}
+
+	rows := 0
+	err = s.bookRepo.FindInBatches(ctx, filters, batchSize, func(books []*model.Book) error {
+		for _, book := range books {
+			if err := writer.Write(book.ToDTO()); err != nil {
+				return err
+			}
+			rows++
+		}
+		return nil
+	})
+	if err != nil {
+		return rows, fmt.Errorf("failed to export books: %v", err)
+	}
+
+	return rows, writer.Close()
+}
+
+// StartExport queues an export of the books matching filters to storage
+func (s *bookExportService) StartExport(ctx context.Context, format string, filters map[string]any) (*model.JobResponse, error) {
+	if _, ok := model.BookExportContentTypes[format]; !ok {
+		return nil, fmt.Errorf("%w: unsupported format %q", service.ErrInvalidExport, format)
+	}
+
+	job := &model.Job{
+		ID:      uuid.New(),
+		Type:    model.JobTypeBookExport,
+		Status:  model.JobStatusPending,
+		ActorID: utils.UserIDFromContext(ctx),
+		Params:  map[string]string{"format": format},
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create export job: %v", err)
+	}
+
+	// A worker may take the job as soon as it is queued
+	resp := job.ToDTO("")
+	if !s.queue.Push(&exportTask{job: job, format: format, filters: filters}) {
+		s.finish(ctx, job, service.ErrJobQueueFull)
+		return nil, service.ErrJobQueueFull
+	}
+
+	return resp, nil
+}
+
+// GetExportJob gets the status of an export job, with a download URL once
+// it is done. Users only see the jobs they started; admins see every job.
+func (s *bookExportService) GetExportJob(ctx context.Context, id string) (*model.JobResponse, error) {
+	jobID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, service.ErrJobNotFound
+	}
+
+	job, err := s.jobRepo.FindByID(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to find job: %v", err)
+	}
+	if job.Type != model.JobTypeBookExport {
+		return nil, service.ErrJobNotFound
+	}
+	if utils.UserRoleFromContext(ctx) != "admin" && utils.UserIDFromContext(ctx) != job.ActorID {
+		return nil, service.ErrJobNotFound
+	}
+
+	var downloadURL string
+	if job.Status == model.JobStatusCompleted && job.ResultObject != "" {
+		downloadURL, err = s.uploadService.GetFileURL(ctx, job.ResultObject)
+		if err != nil {
+			slog.Error("Failed to generate export URL", slog.String("job_id", job.ID.String()), slog.Any("error", err))
+		}
+	}
+
+	return job.ToDTO(downloadURL), nil
+}
+
+// RunWorkers first fails the exports a previous process left pending or
+// running, whose queue went with it, then processes queued exports until
+// ctx is done. Exports still running then are stopped and, like the ones
+// still queued, marked failed.
+func (s *bookExportService) RunWorkers(ctx context.Context) {
+	failed, err := s.jobRepo.FailUnfinished(ctx, model.JobTypeBookExport, s.startedAt, errInterrupted.Error())
+	if err != nil {
+		slog.Error("Failed to fail interrupted export jobs", slog.Any("error", err))
+	} else if failed > 0 {
+		slog.Warn("Failed export jobs interrupted by a restart", slog.Int64("jobs", failed))
+	}
+
+	s.queue.Run(ctx, s.run, func(task *exportTask) {
+		s.finish(context.WithoutCancel(ctx), task.job, errInterrupted)
+	})
+}
+
+// run writes the export straight into storage through a pipe, so the file
+// is never held in memory or on local disk
+func (s *bookExportService) run(ctx context.Context, task *exportTask) {
+	job := task.job
+	startedAt := time.Now()
+	job.Status = model.JobStatusRunning
+	job.StartedAt = &startedAt
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		slog.Error("Failed to mark export job running", slog.String("job_id", job.ID.String()), slog.Any("error", err))
+	}
+
+	objectName := fmt.Sprintf("exports/%s/%s", job.ID, model.BookExportFileName(task.format))
+	reader, writer := io.Pipe()
+
+	rows := make(chan int, 1)
+	go func() {
+		n, err := s.export(ctx, task.format, task.filters, writer)
+		writer.CloseWithError(err)
+		rows <- n
+	}()
+	err := s.uploadService.PutObject(ctx, objectName, reader, -1, model.BookExportContentTypes[task.format])
+	reader.CloseWithError(err)
+
+	job.Total = <-rows
+	if err == nil && ctx.Err() != nil {
+		err = errInterrupted
+	}
+	if err == nil {
+		job.Succeeded = job.Total
+		job.ResultObject = objectName
+	}
+	// The outcome is saved even when ctx is done
+	s.finish(context.WithoutCancel(ctx), job, err)
+}
+
+// finish records the outcome of an export job
+func (s *bookExportService) finish(ctx context.Context, job *model.Job, err error) {
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	job.Status = model.JobStatusCompleted
+	if err != nil {
+		job.Status = model.JobStatusFailed
+		job.Error = err.Error()
+		slog.Error("Book export failed", slog.String("job_id", job.ID.String()), slog.Any("error", err))
+	}
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		slog.Error("Failed to save export job", slog.String("job_id", job.ID.String()), slog.Any("error", err))
+	}
+}
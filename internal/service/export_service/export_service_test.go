@@ -0,0 +1,68 @@
+package export_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// fakeUploads signs a fixed URL for every object
+type fakeUploads struct {
+	service.IUploadService
+}
+
+func (fakeUploads) GetFileURL(ctx context.Context, objectName string) (string, error) {
+	return "https://storage.example/" + objectName + "?signature=secret", nil
+}
+
+func TestGetExportJob(t *testing.T) {
+	job := &model.Job{ID: uuid.New(), Type: model.JobTypeBookExport, Status: model.JobStatusCompleted,
+		ActorID: "owner", ResultObject: "exports/books.csv"}
+	s := NewBookExportService(nil, repotest.NewJobs(job), fakeUploads{}, "Sender", "USD")
+
+	tests := []struct {
+		name    string
+		userID  string
+		role    string
+		wantErr error
+	}{
+		{name: "owner", userID: "owner", role: "user"},
+		{name: "admin", userID: "admin", role: "admin"},
+		{name: "someone else", userID: "other", role: "user", wantErr: service.ErrJobNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := utils.WithCurrentUser(context.Background(), tt.userID, tt.role)
+			resp, err := s.GetExportJob(ctx, job.ID.String())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetExportJob() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && resp.ResultURL == "" {
+				t.Errorf("GetExportJob() gave no download URL")
+			}
+		})
+	}
+}
+
+func TestRunWorkersFailsQueuedExports(t *testing.T) {
+	jobs := repotest.NewJobs()
+	s := NewBookExportService(nil, jobs, fakeUploads{}, "Sender", "USD").(*bookExportService)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	started, err := s.StartExport(ctx, model.BookExportFormatCSV, nil)
+	if err != nil {
+		t.Fatalf("StartExport() error = %v", err)
+	}
+	s.RunWorkers(ctx)
+
+	if job := jobs.Get(started.ID); job.Status != model.JobStatusFailed {
+		t.Errorf("export queued at shutdown is %s, want failed", job.Status)
+	}
+}
@@ -0,0 +1,62 @@
+package export_service
+
+import (
+	"book_system/internal/baselib/onix"
+	"book_system/internal/model"
+	"bufio"
+	"io"
+	"time"
+)
+
+// onixSettings describe the exported ONIX messages
+type onixSettings struct {
+	sender   string
+	currency string
+}
+
+type onixWriter struct {
+	buffer   *bufio.Writer
+	writer   *onix.Writer
+	currency string
+}
+
+func newONIXWriter(w io.Writer, settings onixSettings) (*onixWriter, error) {
+	buffer := bufio.NewWriter(w)
+	writer, err := onix.NewWriter(buffer, settings.sender, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &onixWriter{buffer: buffer, writer: writer, currency: settings.currency}, nil
+}
+
+// Write adds a book as a product record referenced by its ID
+func (o *onixWriter) Write(book *model.BookResponse) error {
+	stock := book.Stock
+	var contributors []onix.Contributor
+	for _, name := range model.SplitAuthors(book.Author) {
+		contributors = append(contributors, onix.Contributor{Role: onix.ContributorByAuthor, Name: name})
+	}
+	currency := o.currency
+	if book.Currency != "" {
+		currency = book.Currency
+	}
+	product := &onix.Product{
+		RecordReference: book.ID.String(),
+		ISBN:            book.ISBN,
+		Title:           book.Title,
+		Contributors:    contributors,
+		Description:     book.Description,
+		CoverURL:        book.CoverImage,
+		PublicationDate: book.PublishedAt,
+		Price:           &onix.Price{Type: onix.PriceRRPIncludingTax, Amount: model.RoundMoney(book.Price, currency), Currency: currency},
+		OnHand:          &stock,
+	}
+	return o.writer.Write(product)
+}
+
+func (o *onixWriter) Close() error {
+	if err := o.writer.Close(); err != nil {
+		return err
+	}
+	return o.buffer.Flush()
+}
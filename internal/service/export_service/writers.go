@@ -0,0 +1,156 @@
+package export_service
+
+import (
+	"book_system/internal/baselib/xlsx"
+	"book_system/internal/model"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// exportColumns are the exported fields. The CSV form can be fed back to
+// the bulk import, which ignores the columns it does not know.
+var exportColumns = []string{"id", "title", "author", "description", "cover_image", "price", "currency", "stock", "isbn", "published_at",
+	"format", "language", "page_count", "work_id", "category_id", "created_at", "updated_at"}
+
+// rowWriter writes books one at a time in an export format
+type rowWriter interface {
+	Write(book *model.BookResponse) error
+	// Close flushes buffered output and completes the file; it does not close the destination
+	Close() error
+}
+
+func newRowWriter(format string, w io.Writer, onix onixSettings) (rowWriter, error) {
+	switch format {
+	case model.BookExportFormatCSV:
+		return newCSVWriter(w)
+	case model.BookExportFormatNDJSON:
+		return newNDJSONWriter(w), nil
+	case model.BookExportFormatXLSX:
+		return newXLSXWriter(w)
+	case model.BookExportFormatONIX:
+		return newONIXWriter(w, onix)
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(exportColumns); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer: writer}, nil
+}
+
+func (c *csvWriter) Write(book *model.BookResponse) error {
+	return c.writer.Write([]string{
+		book.ID.String(),
+		book.Title,
+		book.Author,
+		book.Description,
+		book.CoverImage,
+		model.RoundMoney(book.Price, book.Currency).StringFixed(model.CurrencyExponent(book.Currency)),
+		book.Currency,
+		strconv.Itoa(book.Stock),
+		book.ISBN,
+		book.PublishedAt.Format(time.DateOnly),
+		book.Format,
+		book.Language,
+		strconv.Itoa(book.PageCount),
+		optionalID(book.WorkID),
+		optionalID(book.CategoryID),
+		book.CreatedAt.Format(time.RFC3339),
+		book.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// optionalID formats an optional reference, empty when it is not set
+func optionalID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+type ndjsonWriter struct {
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	buffer := bufio.NewWriter(w)
+	return &ndjsonWriter{buffer: buffer, encoder: json.NewEncoder(buffer)}
+}
+
+func (n *ndjsonWriter) Write(book *model.BookResponse) error {
+	return n.encoder.Encode(book)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.buffer.Flush()
+}
+
+type xlsxWriter struct {
+	buffer *bufio.Writer
+	writer *xlsx.Writer
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	buffer := bufio.NewWriter(w)
+	writer, err := xlsx.NewWriter(buffer, "Books")
+	if err != nil {
+		return nil, err
+	}
+	header := make([]any, len(exportColumns))
+	for i, column := range exportColumns {
+		header[i] = column
+	}
+	if err := writer.WriteRow(header...); err != nil {
+		return nil, err
+	}
+	return &xlsxWriter{buffer: buffer, writer: writer}, nil
+}
+
+func (x *xlsxWriter) Write(book *model.BookResponse) error {
+	return x.writer.WriteRow(
+		book.ID.String(),
+		book.Title,
+		book.Author,
+		book.Description,
+		book.CoverImage,
+		xlsx.Number(book.Price.String()),
+		book.Currency,
+		book.Stock,
+		book.ISBN,
+		book.PublishedAt,
+		book.Format,
+		book.Language,
+		book.PageCount,
+		optionalID(book.WorkID),
+		optionalID(book.CategoryID),
+		book.CreatedAt,
+		book.UpdatedAt,
+	)
+}
+
+func (x *xlsxWriter) Close() error {
+	if err := x.writer.Close(); err != nil {
+		return err
+	}
+	return x.buffer.Flush()
+}
@@ -0,0 +1,469 @@
+package fine_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// accrueBatchSize is how many overdue loans are charged per batch
+const accrueBatchSize = 100
+
+type fineService struct {
+	policyRepo     repository.IFinePolicyRepository
+	entryRepo      repository.IAccountEntryRepository
+	loanRepo       repository.ILoanRepository
+	userRepo       repository.IUserRepository
+	transactor     repository.ITransactor
+	currency       string
+	blockThreshold decimal.Decimal
+}
+
+// NewFineService creates a new fine service. Fines and balances are in
+// currency; members owing more than blockThreshold cannot borrow.
+func NewFineService(
+	policyRepo repository.IFinePolicyRepository,
+	entryRepo repository.IAccountEntryRepository,
+	loanRepo repository.ILoanRepository,
+	userRepo repository.IUserRepository,
+	transactor repository.ITransactor,
+	currency string,
+	blockThreshold decimal.Decimal,
+) service.IFineService {
+	return &fineService{
+		policyRepo:     policyRepo,
+		entryRepo:      entryRepo,
+		loanRepo:       loanRepo,
+		userRepo:       userRepo,
+		transactor:     transactor,
+		currency:       currency,
+		blockThreshold: blockThreshold,
+	}
+}
+
+// CreateFinePolicy creates a fine policy for a role, or the default
+// policy when the role is empty. Each role has at most one policy.
+func (s *fineService) CreateFinePolicy(ctx context.Context, req *model.FinePolicyRequest) (*model.FinePolicyResponse, error) {
+	now := time.Now()
+	policy := &model.FinePolicy{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.apply(ctx, policy, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.policyRepo.Create(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to create fine policy: %v", err)
+	}
+
+	return policy.ToDTO(), nil
+}
+
+// ListFinePolicies gets every fine policy, the default first
+func (s *fineService) ListFinePolicies(ctx context.Context) (*model.FinePolicyListResponse, error) {
+	policies, err := s.policyRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fine policies: %v", err)
+	}
+
+	policyDTOs := make([]*model.FinePolicyResponse, len(policies))
+	for i, policy := range policies {
+		policyDTOs[i] = policy.ToDTO()
+	}
+	return &model.FinePolicyListResponse{Data: policyDTOs}, nil
+}
+
+// UpdateFinePolicy replaces the editable fields of a fine policy. Fines
+// already charged are left alone.
+func (s *fineService) UpdateFinePolicy(ctx context.Context, id string, req *model.FinePolicyRequest) (*model.FinePolicyResponse, error) {
+	policy, err := s.findPolicy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(ctx, policy, req); err != nil {
+		return nil, err
+	}
+	policy.UpdatedAt = time.Now()
+	if err := s.policyRepo.Update(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to update fine policy: %v", err)
+	}
+
+	return policy.ToDTO(), nil
+}
+
+// DeleteFinePolicy deletes a fine policy. Members of its role fall back
+// to the default policy.
+func (s *fineService) DeleteFinePolicy(ctx context.Context, id string) error {
+	policy, err := s.findPolicy(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.policyRepo.Delete(ctx, policy.ID); err != nil {
+		return fmt.Errorf("failed to delete fine policy: %v", err)
+	}
+	return nil
+}
+
+// ChargeFine brings the fine of a loan up to what its borrower's policy
+// makes due at the given time, recording the difference in the member's
+// ledger. The caller saves the loan, in the same transaction.
+func (s *fineService) ChargeFine(ctx context.Context, loan *model.Loan, at time.Time) error {
+	policies, err := s.policyRepo.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find fine policies: %v", err)
+	}
+	_, err = s.charge(ctx, loan, at, policies)
+	return err
+}
+
+// AccrueFines charges the fines due so far on every overdue loan still
+// out. Each loan is charged in its own transaction; charging again the
+// same day adds nothing.
+func (s *fineService) AccrueFines(ctx context.Context) (*model.FineAccrual, error) {
+	policies, err := s.policyRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find fine policies: %v", err)
+	}
+
+	now := time.Now()
+	result := &model.FineAccrual{Amount: decimal.Zero}
+	afterID := uuid.Nil
+	for {
+		loans, err := s.loanRepo.FindOverdue(ctx, now, afterID, accrueBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find overdue loans: %v", err)
+		}
+		if len(loans) == 0 {
+			break
+		}
+		afterID = loans[len(loans)-1].ID
+
+		for _, loan := range loans {
+			result.Checked++
+
+			var charged decimal.Decimal
+			err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+				loan, err := s.loanRepo.FindByIDForUpdate(ctx, loan.ID)
+				if err != nil {
+					return fmt.Errorf("failed to find loan: %v", err)
+				}
+				// Returned since it was found; its fine was settled at checkin
+				if loan.Status != model.LoanStatusActive {
+					return nil
+				}
+				charged, err = s.charge(ctx, loan, now, policies)
+				if err != nil || charged.IsZero() {
+					return err
+				}
+				if err := s.loanRepo.Update(ctx, loan); err != nil {
+					return fmt.Errorf("failed to update loan: %v", err)
+				}
+				return nil
+			})
+			if err != nil {
+				result.Failed++
+				slog.Error("Failed to charge fine", slog.String("loan_id", loan.ID.String()), slog.Any("error", err))
+				continue
+			}
+			if charged.IsPositive() {
+				result.Charged++
+				result.Amount = result.Amount.Add(charged)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// RunNightly accrues fines every day at the given hour, local time, until
+// ctx is done
+func (s *fineService) RunNightly(ctx context.Context, hour int) {
+	for {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			result, err := s.AccrueFines(ctx)
+			if err != nil {
+				slog.Error("Fine accrual failed", slog.Any("error", err))
+				continue
+			}
+			slog.Info("Accrued fines",
+				slog.Int("checked", result.Checked),
+				slog.Int("charged", result.Charged),
+				slog.Int("failed", result.Failed),
+				slog.String("amount", result.Amount.StringFixed(model.CurrencyExponent(s.currency))),
+			)
+		}
+	}
+}
+
+// CheckCanBorrow returns ErrBalanceOverLimit when a member owes more than
+// the block threshold
+func (s *fineService) CheckCanBorrow(ctx context.Context, userID uuid.UUID) error {
+	balance, err := s.entryRepo.Balance(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to find balance: %v", err)
+	}
+	if balance.GreaterThan(s.blockThreshold) {
+		return fmt.Errorf("%w: %s %s owed, the limit is %s", service.ErrBalanceOverLimit,
+			balance.StringFixed(model.CurrencyExponent(s.currency)), s.currency,
+			s.blockThreshold.StringFixed(model.CurrencyExponent(s.currency)))
+	}
+	return nil
+}
+
+// GetMyAccount gets the current user's balance with a page of their ledger
+func (s *fineService) GetMyAccount(ctx context.Context, page, pageSize int) (*model.AccountResponse, error) {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.account(ctx, userID, page, pageSize)
+}
+
+// GetAccount gets a member's balance with a page of their ledger
+func (s *fineService) GetAccount(ctx context.Context, userID string, page, pageSize int) (*model.AccountResponse, error) {
+	member, err := s.findMember(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.account(ctx, member.ID, page, pageSize)
+}
+
+// RecordPayment takes a payment made by a member off their balance
+func (s *fineService) RecordPayment(ctx context.Context, userID string, req *model.AccountCreditRequest) (*model.AccountEntryResponse, error) {
+	return s.credit(ctx, userID, model.AccountEntryPayment, req)
+}
+
+// WaiveFine forgives part or all of a member's balance
+func (s *fineService) WaiveFine(ctx context.Context, userID string, req *model.AccountCreditRequest) (*model.AccountEntryResponse, error) {
+	return s.credit(ctx, userID, model.AccountEntryWaiver, req)
+}
+
+// charge brings the fine of a loan up to what the policy for its
+// borrower's role makes due at the given time and returns the amount
+// added. Loans of members without a policy are not charged.
+func (s *fineService) charge(ctx context.Context, loan *model.Loan, at time.Time, policies []*model.FinePolicy) (decimal.Decimal, error) {
+	role := ""
+	member, err := s.userRepo.FindByID(ctx, loan.UserID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return decimal.Zero, fmt.Errorf("failed to find member: %v", err)
+	}
+	if member != nil {
+		role = member.Role
+	}
+	policy := policyFor(policies, role)
+	if policy == nil {
+		return decimal.Zero, nil
+	}
+
+	due := model.RoundMoney(policy.FineFor(loan.DueAt, at), s.currency)
+	charged := due.Sub(loan.Fine)
+	if !charged.IsPositive() {
+		return decimal.Zero, nil
+	}
+
+	entry := &model.AccountEntry{
+		ID:        uuid.New(),
+		UserID:    loan.UserID,
+		LoanID:    &loan.ID,
+		Type:      model.AccountEntryFine,
+		Amount:    charged,
+		Note:      fmt.Sprintf("Overdue %q", loan.Title),
+		CreatedAt: at,
+	}
+	if err := s.entryRepo.Create(ctx, entry); err != nil {
+		return decimal.Zero, fmt.Errorf("failed to record fine: %v", err)
+	}
+	loan.Fine = due
+	loan.UpdatedAt = at
+	return charged, nil
+}
+
+// credit records a payment or waiver of at most a member's balance
+func (s *fineService) credit(ctx context.Context, userID, kind string, req *model.AccountCreditRequest) (*model.AccountEntryResponse, error) {
+	member, err := s.findMember(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !req.Amount.Equal(model.RoundMoney(req.Amount, s.currency)) {
+		return nil, fmt.Errorf("%w: %s amounts have at most %d decimal places", service.ErrInvalidAmount, s.currency, model.CurrencyExponent(s.currency))
+	}
+
+	entry := &model.AccountEntry{
+		ID:        uuid.New(),
+		UserID:    member.ID,
+		LoanID:    req.LoanID,
+		Type:      kind,
+		Amount:    req.Amount.Neg(),
+		Note:      req.Note,
+		ActorID:   utils.UserIDFromContext(ctx),
+		CreatedAt: time.Now(),
+	}
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if req.LoanID != nil {
+			loan, err := s.loanRepo.FindByID(ctx, *req.LoanID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("failed to find loan: %v", err)
+			}
+			if loan == nil || loan.UserID != member.ID {
+				return service.ErrLoanNotFound
+			}
+		}
+
+		balance, err := s.entryRepo.Balance(ctx, member.ID)
+		if err != nil {
+			return fmt.Errorf("failed to find balance: %v", err)
+		}
+		if req.Amount.GreaterThan(balance) {
+			return fmt.Errorf("%w: %s %s owed", service.ErrCreditExceedsBalance, balance.StringFixed(model.CurrencyExponent(s.currency)), s.currency)
+		}
+
+		if err := s.entryRepo.Create(ctx, entry); err != nil {
+			return fmt.Errorf("failed to record %s: %v", kind, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry.ToDTO(), nil
+}
+
+// account gets a member's balance with a page of their ledger
+func (s *fineService) account(ctx context.Context, userID uuid.UUID, page, pageSize int) (*model.AccountResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	balance, err := s.entryRepo.Balance(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find balance: %v", err)
+	}
+	entries, total, err := s.entryRepo.FindByUserID(ctx, userID, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account entries: %v", err)
+	}
+
+	entryDTOs := make([]*model.AccountEntryResponse, len(entries))
+	for i, entry := range entries {
+		entryDTOs[i] = entry.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.AccountResponse{
+		UserID:   userID,
+		Balance:  balance,
+		Currency: s.currency,
+		Blocked:  balance.GreaterThan(s.blockThreshold),
+		Entries:  entryDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// apply copies a request onto a fine policy after checking its role is
+// free and its amounts fit the currency
+func (s *fineService) apply(ctx context.Context, policy *model.FinePolicy, req *model.FinePolicyRequest) error {
+	for _, amount := range []decimal.Decimal{req.DailyRate, req.MaxPerItem} {
+		if !amount.Equal(model.RoundMoney(amount, s.currency)) {
+			return fmt.Errorf("%w: %s amounts have at most %d decimal places", service.ErrInvalidAmount, s.currency, model.CurrencyExponent(s.currency))
+		}
+	}
+
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+	taken, err := s.policyRepo.ExistsByRole(ctx, role, policy.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check fine policy role: %v", err)
+	}
+	if taken {
+		return fmt.Errorf("%w: %q", service.ErrFinePolicyRoleTaken, role)
+	}
+
+	policy.Role = role
+	policy.DailyRate = req.DailyRate
+	policy.GraceDays = req.GraceDays
+	policy.MaxPerItem = req.MaxPerItem
+	return nil
+}
+
+// findPolicy finds a fine policy by ID
+func (s *fineService) findPolicy(ctx context.Context, id string) (*model.FinePolicy, error) {
+	policyID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidFinePolicyID, err)
+	}
+
+	policy, err := s.policyRepo.FindByID(ctx, policyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrFinePolicyNotFound
+		}
+		return nil, fmt.Errorf("failed to find fine policy: %v", err)
+	}
+	return policy, nil
+}
+
+// findMember finds a member by ID
+func (s *fineService) findMember(ctx context.Context, id string) (*model.User, error) {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidUserID, err)
+	}
+
+	member, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrMemberNotFound
+		}
+		return nil, fmt.Errorf("failed to find member: %v", err)
+	}
+	return member, nil
+}
+
+// policyFor returns the policy for a role, else the default policy, else nil
+func policyFor(policies []*model.FinePolicy, role string) *model.FinePolicy {
+	var fallback *model.FinePolicy
+	for _, policy := range policies {
+		switch policy.Role {
+		case role:
+			return policy
+		case "":
+			fallback = policy
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,224 @@
+package fine_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type fakeLoans struct {
+	repository.ILoanRepository
+	loans map[uuid.UUID]*model.Loan
+}
+
+// FindOverdue does not check the status, as a loan may be returned
+// between the query and the charge
+func (r *fakeLoans) FindOverdue(ctx context.Context, before time.Time, afterID uuid.UUID, limit int) ([]*model.Loan, error) {
+	var loans []*model.Loan
+	for _, loan := range r.loans {
+		if loan.DueAt.Before(before) && loan.ID.String() > afterID.String() {
+			copied := *loan
+			loans = append(loans, &copied)
+		}
+	}
+	sort.Slice(loans, func(i, j int) bool { return loans[i].ID.String() < loans[j].ID.String() })
+	return loans[:min(limit, len(loans))], nil
+}
+
+func (r *fakeLoans) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Loan, error) {
+	loan, ok := r.loans[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *loan
+	return &copied, nil
+}
+
+func (r *fakeLoans) Update(ctx context.Context, loan *model.Loan) error {
+	copied := *loan
+	r.loans[loan.ID] = &copied
+	return nil
+}
+
+type fakeEntries struct {
+	repository.IAccountEntryRepository
+	entries []*model.AccountEntry
+}
+
+func (r *fakeEntries) Create(ctx context.Context, entry *model.AccountEntry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *fakeEntries) Balance(ctx context.Context, userID uuid.UUID) (decimal.Decimal, error) {
+	balance := decimal.Zero
+	for _, entry := range r.entries {
+		if entry.UserID == userID {
+			balance = balance.Add(entry.Amount)
+		}
+	}
+	return balance, nil
+}
+
+type fakePolicies struct {
+	repository.IFinePolicyRepository
+	policies []*model.FinePolicy
+}
+
+func (r *fakePolicies) FindAll(ctx context.Context) ([]*model.FinePolicy, error) {
+	return r.policies, nil
+}
+
+func dec(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func TestAccrueFines(t *testing.T) {
+	const day = 24 * time.Hour
+	member := &model.User{ID: uuid.New(), Role: "user"}
+	staff := &model.User{ID: uuid.New(), Role: "staff"}
+	policies := []*model.FinePolicy{
+		{Role: "", DailyRate: dec("0.25"), GraceDays: 1, MaxPerItem: dec("5")},
+		{Role: "staff", DailyRate: dec("0.1")},
+	}
+
+	tests := []struct {
+		name        string
+		user        *model.User
+		status      string
+		overdue     time.Duration
+		fine        string
+		wantFine    string
+		wantCharged string
+	}{
+		{name: "default policy after grace", user: member, status: model.LoanStatusActive, overdue: 5*day + time.Hour, fine: "0", wantFine: "1", wantCharged: "1"},
+		{name: "role policy", user: staff, status: model.LoanStatusActive, overdue: 3*day + time.Hour, fine: "0", wantFine: "0.3", wantCharged: "0.3"},
+		{name: "only the difference is charged", user: member, status: model.LoanStatusActive, overdue: 5*day + time.Hour, fine: "0.75", wantFine: "1", wantCharged: "0.25"},
+		{name: "already charged today", user: member, status: model.LoanStatusActive, overdue: 5*day + time.Hour, fine: "1", wantFine: "1", wantCharged: "0"},
+		{name: "capped", user: member, status: model.LoanStatusActive, overdue: 60 * day, fine: "4.5", wantFine: "5", wantCharged: "0.5"},
+		{name: "within the grace day", user: member, status: model.LoanStatusActive, overdue: day + time.Hour, fine: "0", wantFine: "0", wantCharged: "0"},
+		{name: "returned meanwhile", user: member, status: model.LoanStatusReturned, overdue: 5*day + time.Hour, fine: "0", wantFine: "0", wantCharged: "0"},
+		{name: "unknown member gets the default", user: &model.User{ID: uuid.New()}, status: model.LoanStatusActive, overdue: 2*day + time.Hour, fine: "0", wantFine: "0.25", wantCharged: "0.25"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loan := &model.Loan{
+				ID:     uuid.New(),
+				UserID: tt.user.ID,
+				Title:  "Dune",
+				Status: tt.status,
+				DueAt:  time.Now().Add(-tt.overdue),
+				Fine:   dec(tt.fine),
+			}
+			loans := &fakeLoans{loans: map[uuid.UUID]*model.Loan{loan.ID: loan}}
+			entries := &fakeEntries{}
+			users := repotest.NewUsers(member, staff)
+			s := NewFineService(&fakePolicies{policies: policies}, entries, loans, users, repotest.Transactor{}, "EUR", dec("10"))
+
+			// A second run the same day adds nothing
+			for run := 0; run < 2; run++ {
+				result, err := s.AccrueFines(context.Background())
+				if err != nil {
+					t.Fatalf("AccrueFines() error = %v", err)
+				}
+				wantCharged := dec(tt.wantCharged)
+				if run > 0 {
+					wantCharged = decimal.Zero
+				}
+				if result.Checked != 1 || result.Failed != 0 || !result.Amount.Equal(wantCharged) {
+					t.Errorf("run %d: AccrueFines() = %+v, want %s charged", run, result, wantCharged)
+				}
+			}
+
+			if fine := loans.loans[loan.ID].Fine; !fine.Equal(dec(tt.wantFine)) {
+				t.Errorf("loan fine = %s, want %s", fine, tt.wantFine)
+			}
+			balance, _ := entries.Balance(context.Background(), tt.user.ID)
+			if !balance.Equal(dec(tt.wantCharged)) {
+				t.Errorf("balance = %s, want %s", balance, tt.wantCharged)
+			}
+			for _, entry := range entries.entries {
+				if entry.Type != model.AccountEntryFine || entry.LoanID == nil || *entry.LoanID != loan.ID {
+					t.Errorf("entry = %+v, want a fine on the loan", entry)
+				}
+			}
+		})
+	}
+}
+
+func TestAccrueFinesBatches(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Role: "user"}
+	loans := &fakeLoans{loans: map[uuid.UUID]*model.Loan{}}
+	for i := 0; i < accrueBatchSize*2+1; i++ {
+		loan := &model.Loan{ID: uuid.New(), UserID: user.ID, Status: model.LoanStatusActive, DueAt: time.Now().Add(-49 * time.Hour)}
+		loans.loans[loan.ID] = loan
+	}
+	s := NewFineService(
+		&fakePolicies{policies: []*model.FinePolicy{{DailyRate: dec("0.5")}}},
+		&fakeEntries{}, loans, repotest.NewUsers(user),
+		repotest.Transactor{}, "EUR", dec("10"),
+	)
+
+	result, err := s.AccrueFines(context.Background())
+	if err != nil {
+		t.Fatalf("AccrueFines() error = %v", err)
+	}
+	if result.Checked != len(loans.loans) || result.Charged != len(loans.loans) || !result.Amount.Equal(dec("201")) {
+		t.Errorf("AccrueFines() = %+v, want all %d loans charged 1", result, len(loans.loans))
+	}
+}
+
+func TestCheckCanBorrow(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name    string
+		balance string
+		wantErr error
+	}{
+		{name: "nothing owed", balance: "0"},
+		{name: "at the limit", balance: "10"},
+		{name: "over the limit", balance: "10.01", wantErr: service.ErrBalanceOverLimit},
+	}
+
+	for _, tt := range tests {
+		entries := &fakeEntries{entries: []*model.AccountEntry{{UserID: userID, Amount: dec(tt.balance)}}}
+		s := NewFineService(&fakePolicies{}, entries, &fakeLoans{}, repotest.NewUsers(), repotest.Transactor{}, "EUR", dec("10"))
+		if err := s.CheckCanBorrow(context.Background(), userID); !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: CheckCanBorrow() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPolicyFor(t *testing.T) {
+	fallback := &model.FinePolicy{Role: ""}
+	staff := &model.FinePolicy{Role: "staff"}
+
+	tests := []struct {
+		name     string
+		policies []*model.FinePolicy
+		role     string
+		want     *model.FinePolicy
+	}{
+		{name: "own policy", policies: []*model.FinePolicy{fallback, staff}, role: "staff", want: staff},
+		{name: "default", policies: []*model.FinePolicy{fallback, staff}, role: "user", want: fallback},
+		{name: "no default", policies: []*model.FinePolicy{staff}, role: "user", want: nil},
+	}
+
+	for _, tt := range tests {
+		if got := policyFor(tt.policies, tt.role); got != tt.want {
+			t.Errorf("%s: policyFor() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,414 @@
+package hold_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// expireBatchSize is how many lapsed holds are expired per pass
+const expireBatchSize = 100
+
+type holdService struct {
+	repo          repository.IHoldRepository
+	bookRepo      repository.IBookRepository
+	notifications service.INotificationService
+	transactor    repository.ITransactor
+	pickupWindow  time.Duration
+}
+
+// NewHoldService creates a new hold service. Ready holds keep their copy
+// set aside for pickupWindow.
+func NewHoldService(
+	repo repository.IHoldRepository,
+	bookRepo repository.IBookRepository,
+	notifications service.INotificationService,
+	transactor repository.ITransactor,
+	pickupWindow time.Duration,
+) service.IHoldService {
+	return &holdService{
+		repo:          repo,
+		bookRepo:      bookRepo,
+		notifications: notifications,
+		transactor:    transactor,
+		pickupWindow:  pickupWindow,
+	}
+}
+
+// PlaceHold puts the current user at the back of the queue for a book
+// none of whose stock is free. Users hold at most one place per book.
+func (s *holdService) PlaceHold(ctx context.Context, bookID string) (*model.HoldResponse, error) {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := uuid.Parse(bookID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookID, err)
+	}
+
+	var hold *model.Hold
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		book, err := s.bookRepo.FindByIDForUpdate(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return service.ErrBookNotFound
+			}
+			return fmt.Errorf("failed to find book: %v", err)
+		}
+
+		_, err = s.repo.FindActive(ctx, book.ID, userID)
+		if err == nil {
+			return service.ErrHoldExists
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to find hold: %v", err)
+		}
+
+		reserved, err := s.repo.CountReady(ctx, book.ID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to count ready holds: %v", err)
+		}
+		if free := book.Stock - reserved; free > 0 {
+			return fmt.Errorf("%w: %d in stock", service.ErrBookInStock, free)
+		}
+
+		now := time.Now()
+		hold = &model.Hold{
+			ID:        uuid.New(),
+			BookID:    book.ID,
+			UserID:    userID,
+			Status:    model.HoldStatusWaiting,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := s.repo.Create(ctx, hold); err != nil {
+			return fmt.Errorf("failed to create hold: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toDTO(ctx, hold)
+}
+
+// GetHold gets a hold by ID with its place in the queue. Users only see
+// their own holds; admins see every hold.
+func (s *holdService) GetHold(ctx context.Context, id string) (*model.HoldResponse, error) {
+	holdID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidHoldID, err)
+	}
+
+	hold, err := s.repo.FindByID(ctx, holdID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrHoldNotFound
+		}
+		return nil, fmt.Errorf("failed to find hold: %v", err)
+	}
+	if !canSee(ctx, hold) {
+		return nil, service.ErrHoldNotFound
+	}
+
+	return s.toDTO(ctx, hold)
+}
+
+// CancelHold gives up a waiting or ready hold. The copy set aside for a
+// ready hold goes to the next hold in the queue.
+func (s *holdService) CancelHold(ctx context.Context, id string) (*model.HoldResponse, error) {
+	holdID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidHoldID, err)
+	}
+
+	var hold *model.Hold
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		hold, err = s.repo.FindByIDForUpdate(ctx, holdID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return service.ErrHoldNotFound
+			}
+			return fmt.Errorf("failed to find hold: %v", err)
+		}
+		if !canSee(ctx, hold) {
+			return service.ErrHoldNotFound
+		}
+		if !hold.Active() {
+			return fmt.Errorf("%w: hold is %s", service.ErrHoldNotActive, hold.Status)
+		}
+
+		wasReady := hold.Status == model.HoldStatusReady
+		hold.SetStatus(model.HoldStatusCancelled, time.Now())
+		if err := s.repo.Update(ctx, hold); err != nil {
+			return fmt.Errorf("failed to update hold: %v", err)
+		}
+		if wasReady {
+			if _, err := s.AssignHolds(ctx, hold.BookID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hold.ToDTO(), nil
+}
+
+// ListMyHolds gets a paginated list of the current user's holds in the
+// order they were placed, optionally only those in a status
+func (s *holdService) ListMyHolds(ctx context.Context, page, pageSize int, status string) (*model.HoldListResponse, error) {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := map[string]any{"user_id = ?": userID}
+	if status != "" {
+		filters["status = ?"] = status
+	}
+	return s.list(ctx, page, pageSize, filters)
+}
+
+// ListBookHolds gets the queue of a book: its waiting and ready holds in
+// the order they were placed
+func (s *holdService) ListBookHolds(ctx context.Context, bookID string, page, pageSize int) (*model.HoldListResponse, error) {
+	id, err := uuid.Parse(bookID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookID, err)
+	}
+	if _, err := s.bookRepo.FindByID(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrBookNotFound
+		}
+		return nil, fmt.Errorf("failed to find book: %v", err)
+	}
+
+	return s.list(ctx, page, pageSize, map[string]any{
+		"book_id = ?":   id,
+		"status IN (?)": []string{model.HoldStatusWaiting, model.HoldStatusReady},
+	})
+}
+
+// AssignHolds sets the free stock of a book aside for the holds at the
+// front of its queue, first come first served, and tells their users the
+// book is ready. Free stock is the stock not already set aside for ready
+// holds. It returns how many holds became ready.
+//
+// Nothing watches the stock for this: the inventory service calls it from
+// AdjustStock for every positive delta, which is the only way stock comes
+// in, and CancelHold and ExpireHolds call it when a ready hold gives its
+// copy back.
+func (s *holdService) AssignHolds(ctx context.Context, bookID uuid.UUID) (int, error) {
+	var assigned int
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		book, err := s.bookRepo.FindByIDForUpdate(ctx, bookID)
+		if err != nil {
+			// Books in the trash keep their queue until they are restored
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return fmt.Errorf("failed to find book: %v", err)
+		}
+		reserved, err := s.repo.CountReady(ctx, book.ID, uuid.Nil)
+		if err != nil {
+			return fmt.Errorf("failed to count ready holds: %v", err)
+		}
+		free := book.Stock - reserved
+		if free <= 0 {
+			return nil
+		}
+
+		holds, err := s.repo.FindNextWaiting(ctx, book.ID, free)
+		if err != nil {
+			return fmt.Errorf("failed to find waiting holds: %v", err)
+		}
+		now := time.Now()
+		expiresAt := now.Add(s.pickupWindow)
+		for _, hold := range holds {
+			hold.Status = model.HoldStatusReady
+			hold.ReadyAt = &now
+			hold.ExpiresAt = &expiresAt
+			hold.UpdatedAt = now
+			if err := s.repo.Update(ctx, hold); err != nil {
+				return fmt.Errorf("failed to update hold: %v", err)
+			}
+
+			message := fmt.Sprintf("A copy of %q is set aside for you until %s", book.Title, expiresAt.Format("2006-01-02 15:04 MST"))
+			if err := s.notifications.Notify(ctx, hold.UserID, model.NotificationHoldReady, message, &book.ID); err != nil {
+				return err
+			}
+		}
+		assigned = len(holds)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return assigned, nil
+}
+
+// ReservedStock returns how much of a book's stock is set aside for the
+// ready holds of users other than userID
+func (s *holdService) ReservedStock(ctx context.Context, bookID, userID uuid.UUID) (int, error) {
+	reserved, err := s.repo.CountReady(ctx, bookID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count ready holds: %v", err)
+	}
+	return reserved, nil
+}
+
+// FulfillHold closes the hold a user has on a book they bought, if any
+func (s *holdService) FulfillHold(ctx context.Context, bookID, userID uuid.UUID) error {
+	hold, err := s.repo.FindActive(ctx, bookID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to find hold: %v", err)
+	}
+
+	hold.SetStatus(model.HoldStatusFulfilled, time.Now())
+	if err := s.repo.Update(ctx, hold); err != nil {
+		return fmt.Errorf("failed to update hold: %v", err)
+	}
+	return nil
+}
+
+// ExpireHolds expires the ready holds whose pickup window has ended,
+// telling their users, and passes their copies on to the next holds
+func (s *holdService) ExpireHolds(ctx context.Context) (*model.HoldExpiry, error) {
+	holds, err := s.repo.FindExpired(ctx, time.Now(), expireBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find expired holds: %v", err)
+	}
+
+	result := &model.HoldExpiry{}
+	for _, hold := range holds {
+		var assigned int
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			hold, err := s.repo.FindByIDForUpdate(ctx, hold.ID)
+			if err != nil {
+				return fmt.Errorf("failed to find hold: %v", err)
+			}
+			// Picked up or cancelled since it was found
+			now := time.Now()
+			if hold.Status != model.HoldStatusReady || hold.ExpiresAt == nil || hold.ExpiresAt.After(now) {
+				return nil
+			}
+
+			hold.SetStatus(model.HoldStatusExpired, now)
+			if err := s.repo.Update(ctx, hold); err != nil {
+				return fmt.Errorf("failed to update hold: %v", err)
+			}
+			message := "Your hold has expired and the copy set aside for you went to the next reader"
+			if err := s.notifications.Notify(ctx, hold.UserID, model.NotificationHoldExpired, message, &hold.BookID); err != nil {
+				return err
+			}
+			result.Expired++
+
+			assigned, err = s.AssignHolds(ctx, hold.BookID)
+			return err
+		})
+		if err != nil {
+			slog.Error("Failed to expire hold", slog.String("hold_id", hold.ID.String()), slog.Any("error", err))
+			continue
+		}
+		result.Assigned += assigned
+	}
+
+	return result, nil
+}
+
+// RunExpirer expires lapsed holds every interval until ctx is done
+func (s *holdService) RunExpirer(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.ExpireHolds(ctx)
+			if err != nil {
+				slog.Error("Hold expiry failed", slog.Any("error", err))
+				continue
+			}
+			if result.Expired > 0 {
+				slog.Info("Expired holds",
+					slog.Int("expired", result.Expired),
+					slog.Int("assigned", result.Assigned),
+				)
+			}
+		}
+	}
+}
+
+// list gets a paginated list of the holds matching filters with their
+// places in the queue
+func (s *holdService) list(ctx context.Context, page, pageSize int, filters map[string]any) (*model.HoldListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	holds, total, err := s.repo.FindAll(ctx, page, pageSize, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list holds: %v", err)
+	}
+
+	holdDTOs := make([]*model.HoldResponse, len(holds))
+	for i, hold := range holds {
+		holdDTOs[i], err = s.toDTO(ctx, hold)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.HoldListResponse{
+		Data: holdDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// toDTO converts a hold to its DTO with its place in the queue, if it is
+// waiting
+func (s *holdService) toDTO(ctx context.Context, hold *model.Hold) (*model.HoldResponse, error) {
+	dto := hold.ToDTO()
+	if hold.Status == model.HoldStatusWaiting {
+		position, err := s.repo.Position(ctx, hold)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find queue position: %v", err)
+		}
+		dto.Position = position
+	}
+	return dto, nil
+}
+
+// canSee reports whether the current user may see a hold
+func canSee(ctx context.Context, hold *model.Hold) bool {
+	return utils.UserRoleFromContext(ctx) == "admin" || utils.UserIDFromContext(ctx) == hold.UserID.String()
+}
@@ -0,0 +1,286 @@
+package hold_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeHolds keeps holds in the order they were placed
+type fakeHolds struct {
+	repository.IHoldRepository
+	holds []*model.Hold
+}
+
+func (r *fakeHolds) Create(ctx context.Context, hold *model.Hold) error {
+	copied := *hold
+	r.holds = append(r.holds, &copied)
+	return nil
+}
+
+func (r *fakeHolds) FindByID(ctx context.Context, id uuid.UUID) (*model.Hold, error) {
+	for _, hold := range r.holds {
+		if hold.ID == id {
+			copied := *hold
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeHolds) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Hold, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (r *fakeHolds) FindActive(ctx context.Context, bookID, userID uuid.UUID) (*model.Hold, error) {
+	for _, hold := range r.holds {
+		if hold.BookID == bookID && hold.UserID == userID && hold.Active() {
+			return r.FindByID(ctx, hold.ID)
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeHolds) FindNextWaiting(ctx context.Context, bookID uuid.UUID, limit int) ([]*model.Hold, error) {
+	var holds []*model.Hold
+	for _, hold := range r.holds {
+		if hold.BookID == bookID && hold.Status == model.HoldStatusWaiting && len(holds) < limit {
+			copied := *hold
+			holds = append(holds, &copied)
+		}
+	}
+	return holds, nil
+}
+
+func (r *fakeHolds) FindExpired(ctx context.Context, before time.Time, limit int) ([]*model.Hold, error) {
+	var holds []*model.Hold
+	for _, hold := range r.holds {
+		if hold.Status == model.HoldStatusReady && hold.ExpiresAt.Before(before) && len(holds) < limit {
+			copied := *hold
+			holds = append(holds, &copied)
+		}
+	}
+	return holds, nil
+}
+
+func (r *fakeHolds) CountReady(ctx context.Context, bookID, excludeUserID uuid.UUID) (int, error) {
+	count := 0
+	for _, hold := range r.holds {
+		if hold.BookID == bookID && hold.Status == model.HoldStatusReady && hold.UserID != excludeUserID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeHolds) Position(ctx context.Context, hold *model.Hold) (int, error) {
+	position := 1
+	for _, other := range r.holds {
+		if other.ID == hold.ID {
+			break
+		}
+		if other.BookID == hold.BookID && other.Status == model.HoldStatusWaiting {
+			position++
+		}
+	}
+	return position, nil
+}
+
+func (r *fakeHolds) Update(ctx context.Context, hold *model.Hold) error {
+	for i := range r.holds {
+		if r.holds[i].ID == hold.ID {
+			copied := *hold
+			r.holds[i] = &copied
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+type fakeNotifications struct {
+	service.INotificationService
+	users map[uuid.UUID]int
+	sent  []string
+}
+
+func (s *fakeNotifications) Notify(ctx context.Context, userID uuid.UUID, kind, message string, bookID *uuid.UUID) error {
+	s.sent = append(s.sent, fmt.Sprintf("%s:%d", kind, s.users[userID]))
+	return nil
+}
+
+func TestHoldQueue(t *testing.T) {
+	// Steps act for one of three users, numbered from 0
+	type step struct {
+		// action is place, restock (to stock copies), cancel, lapse (ends
+		// the pickup window of ready holds and expires them) or fulfill
+		action string
+		user   int
+		stock  int
+		// other acts on the user's hold as another user
+		other        bool
+		wantErr      error
+		wantPosition int
+	}
+
+	tests := []struct {
+		name         string
+		stock        int
+		steps        []step
+		wantStatus   []string
+		wantNotified []string
+	}{
+		{
+			name:       "holds need the book out of stock",
+			stock:      1,
+			steps:      []step{{action: "place", user: 0, wantErr: service.ErrBookInStock}},
+			wantStatus: []string{"", "", ""},
+		},
+		{
+			name: "one place per user",
+			steps: []step{
+				{action: "place", user: 0, wantPosition: 1},
+				{action: "place", user: 0, wantErr: service.ErrHoldExists},
+			},
+			wantStatus: []string{model.HoldStatusWaiting, "", ""},
+		},
+		{
+			name: "stock goes to the front of the queue",
+			steps: []step{
+				{action: "place", user: 0, wantPosition: 1},
+				{action: "place", user: 1, wantPosition: 2},
+				{action: "place", user: 2, wantPosition: 3},
+				{action: "restock", stock: 2},
+			},
+			wantStatus:   []string{model.HoldStatusReady, model.HoldStatusReady, model.HoldStatusWaiting},
+			wantNotified: []string{"hold_ready:0", "hold_ready:1"},
+		},
+		{
+			name: "stock set aside for ready holds is not free",
+			steps: []step{
+				{action: "place", user: 0},
+				{action: "restock", stock: 1},
+				{action: "place", user: 1, wantPosition: 1},
+				{action: "restock", stock: 1},
+			},
+			wantStatus:   []string{model.HoldStatusReady, model.HoldStatusWaiting, ""},
+			wantNotified: []string{"hold_ready:0"},
+		},
+		{
+			name: "cancelled ready holds pass their copy on",
+			steps: []step{
+				{action: "place", user: 0},
+				{action: "place", user: 1},
+				{action: "restock", stock: 1},
+				{action: "cancel", user: 0},
+			},
+			wantStatus:   []string{model.HoldStatusCancelled, model.HoldStatusReady, ""},
+			wantNotified: []string{"hold_ready:0", "hold_ready:1"},
+		},
+		{
+			name: "expired holds pass their copy on",
+			steps: []step{
+				{action: "place", user: 0},
+				{action: "place", user: 1},
+				{action: "restock", stock: 1},
+				{action: "lapse"},
+			},
+			wantStatus:   []string{model.HoldStatusExpired, model.HoldStatusReady, ""},
+			wantNotified: []string{"hold_ready:0", "hold_expired:0", "hold_ready:1"},
+		},
+		{
+			name: "fulfilled holds leave the queue",
+			steps: []step{
+				{action: "place", user: 0},
+				{action: "place", user: 1},
+				{action: "restock", stock: 1},
+				{action: "fulfill", user: 0},
+				{action: "fulfill", user: 2},
+			},
+			wantStatus:   []string{model.HoldStatusFulfilled, model.HoldStatusWaiting, ""},
+			wantNotified: []string{"hold_ready:0"},
+		},
+		{
+			name: "only active holds of the user can be cancelled",
+			steps: []step{
+				{action: "place", user: 0},
+				{action: "cancel", user: 0, other: true, wantErr: service.ErrHoldNotFound},
+				{action: "cancel", user: 0},
+				{action: "cancel", user: 0, wantErr: service.ErrHoldNotActive},
+			},
+			wantStatus: []string{model.HoldStatusCancelled, "", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+			book := &model.Book{ID: uuid.New(), Title: "Dune", Stock: tt.stock}
+			holds := &fakeHolds{}
+			notifications := &fakeNotifications{users: map[uuid.UUID]int{users[0]: 0, users[1]: 1, users[2]: 2}}
+			s := NewHoldService(holds, repotest.NewBooks(book), notifications, repotest.Transactor{}, time.Hour)
+			holdOf := map[int]uuid.UUID{}
+
+			for i, step := range tt.steps {
+				ctx := utils.WithCurrentUser(context.Background(), users[step.user].String(), "user")
+				if step.other {
+					ctx = utils.WithCurrentUser(context.Background(), uuid.NewString(), "user")
+				}
+
+				var err error
+				switch step.action {
+				case "place":
+					var hold *model.HoldResponse
+					hold, err = s.PlaceHold(ctx, book.ID.String())
+					if err == nil {
+						holdOf[step.user] = hold.ID
+						if step.wantPosition > 0 && hold.Position != step.wantPosition {
+							t.Errorf("step %d: position = %d, want %d", i, hold.Position, step.wantPosition)
+						}
+					}
+				case "restock":
+					book.Stock = step.stock
+					_, err = s.AssignHolds(context.Background(), book.ID)
+				case "cancel":
+					_, err = s.CancelHold(ctx, holdOf[step.user].String())
+				case "lapse":
+					for _, hold := range holds.holds {
+						if hold.Status == model.HoldStatusReady {
+							lapsed := time.Now().Add(-time.Minute)
+							hold.ExpiresAt = &lapsed
+						}
+					}
+					_, err = s.ExpireHolds(context.Background())
+				case "fulfill":
+					err = s.FulfillHold(context.Background(), book.ID, users[step.user])
+				}
+				if !errors.Is(err, step.wantErr) {
+					t.Fatalf("step %d (%s): error = %v, want %v", i, step.action, err, step.wantErr)
+				}
+			}
+
+			status := make([]string, len(users))
+			for i := range users {
+				if hold, err := holds.FindByID(context.Background(), holdOf[i]); err == nil {
+					status[i] = hold.Status
+				}
+			}
+			if !reflect.DeepEqual(status, tt.wantStatus) {
+				t.Errorf("statuses = %q, want %q", status, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(notifications.sent, tt.wantNotified) {
+				t.Errorf("notified %q, want %q", notifications.sent, tt.wantNotified)
+			}
+		})
+	}
+}
@@ -0,0 +1,381 @@
+package import_service
+
+import (
+	"book_system/internal/baselib/workqueue"
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxConcurrentImports bounds how many imports run at once; later ones wait as pending
+	maxConcurrentImports = 2
+	// maxQueuedImports bounds how many imports may wait as pending
+	maxQueuedImports = 100
+	// progressEvery is how many rows are processed between job progress saves
+	progressEvery = 500
+)
+
+// importTask is a queued import job and where to read it from
+type importTask struct {
+	job    *model.Job
+	source string
+	format string
+	dryRun bool
+}
+
+// errInterrupted fails the imports that a shutdown stopped or kept from starting
+var errInterrupted = errors.New("interrupted by a shutdown, start the import again")
+
+type bookImportService struct {
+	bookService   service.IBookService
+	bookRepo      repository.IBookRepository
+	jobRepo       repository.IJobRepository
+	uploadService service.IUploadService
+	currency      string
+	queue         *workqueue.Queue[*importTask]
+	// startedAt separates the jobs of this process from those left by earlier ones
+	startedAt time.Time
+}
+
+// NewBookImportService creates a new book import service. currency is the
+// catalog currency; imported prices in another one keep their currency.
+func NewBookImportService(
+	bookService service.IBookService,
+	bookRepo repository.IBookRepository,
+	jobRepo repository.IJobRepository,
+	uploadService service.IUploadService,
+	currency string,
+) service.IBookImportService {
+	return &bookImportService{
+		bookService:   bookService,
+		bookRepo:      bookRepo,
+		jobRepo:       jobRepo,
+		uploadService: uploadService,
+		currency:      currency,
+		queue:         workqueue.New[*importTask](maxConcurrentImports, maxQueuedImports),
+		startedAt:     time.Now(),
+	}
+}
+
+// StartImport stores the import source and queues it for the workers
+func (s *bookImportService) StartImport(ctx context.Context, req *model.BookImportRequest, upload io.Reader, size int64, filename string) (*model.JobResponse, error) {
+	source := req.Object
+	if upload != nil {
+		source = filename
+	}
+	if source == "" {
+		return nil, fmt.Errorf("%w: a file or an object is required", service.ErrInvalidImport)
+	}
+
+	format := req.Format
+	if format == "" {
+		format = formatFromName(source)
+		if format == "" {
+			return nil, fmt.Errorf("%w: cannot infer format of %s, set format to csv, ndjson, onix, marc or marcxml", service.ErrInvalidImport, source)
+		}
+	}
+
+	job := &model.Job{
+		ID:      uuid.New(),
+		Type:    model.JobTypeBookImport,
+		Status:  model.JobStatusPending,
+		ActorID: utils.UserIDFromContext(ctx),
+		Params: map[string]string{
+			"format":  format,
+			"dry_run": fmt.Sprint(req.DryRun),
+			"source":  source,
+		},
+	}
+
+	// Uploaded files are spooled to storage so the job never depends on the request
+	if upload != nil {
+		objectName := fmt.Sprintf("imports/%s/source.%s", job.ID, format)
+		if err := s.uploadService.PutObject(ctx, objectName, upload, size, ""); err != nil {
+			return nil, fmt.Errorf("failed to store import file: %v", err)
+		}
+		source = objectName
+	} else {
+		// Fail fast on a missing object instead of in the background
+		reader, err := s.uploadService.OpenObject(ctx, source)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot open object %s: %v", service.ErrInvalidImport, source, err)
+		}
+		reader.Close()
+	}
+
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to create import job: %v", err)
+	}
+
+	// A worker may take the job as soon as it is queued
+	resp := job.ToDTO("")
+	if !s.queue.Push(&importTask{job: job, source: source, format: format, dryRun: req.DryRun}) {
+		s.finish(ctx, job, service.ErrJobQueueFull)
+		return nil, service.ErrJobQueueFull
+	}
+
+	return resp, nil
+}
+
+// GetImportJob gets the status of an import job. Users only see the jobs
+// they started; admins see every job.
+func (s *bookImportService) GetImportJob(ctx context.Context, id string) (*model.JobResponse, error) {
+	jobID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, service.ErrJobNotFound
+	}
+
+	job, err := s.jobRepo.FindByID(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrJobNotFound
+		}
+		return nil, fmt.Errorf("failed to find job: %v", err)
+	}
+	if job.Type != model.JobTypeBookImport {
+		return nil, service.ErrJobNotFound
+	}
+	if utils.UserRoleFromContext(ctx) != "admin" && utils.UserIDFromContext(ctx) != job.ActorID {
+		return nil, service.ErrJobNotFound
+	}
+
+	var reportURL string
+	if job.ResultObject != "" {
+		reportURL, err = s.uploadService.GetFileURL(ctx, job.ResultObject)
+		if err != nil {
+			slog.Error("Failed to generate import report URL", slog.String("job_id", job.ID.String()), slog.Any("error", err))
+		}
+	}
+
+	return job.ToDTO(reportURL), nil
+}
+
+// RunWorkers first fails the imports a previous process left pending or
+// running, whose queue went with it, then processes queued imports until
+// ctx is done. Imports still running then are stopped and, like the ones
+// still queued, marked failed.
+func (s *bookImportService) RunWorkers(ctx context.Context) {
+	failed, err := s.jobRepo.FailUnfinished(ctx, model.JobTypeBookImport, s.startedAt, errInterrupted.Error())
+	if err != nil {
+		slog.Error("Failed to fail interrupted import jobs", slog.Any("error", err))
+	} else if failed > 0 {
+		slog.Warn("Failed import jobs interrupted by a restart", slog.Int64("jobs", failed))
+	}
+
+	s.queue.Run(ctx, s.run, func(task *importTask) {
+		s.finish(context.WithoutCancel(ctx), task.job, errInterrupted)
+	})
+}
+
+// run processes an import job as the user who started it, recording its
+// outcome on the job
+func (s *bookImportService) run(ctx context.Context, task *importTask) {
+	job := task.job
+	// The outcome is saved even when ctx is done
+	saveCtx := context.WithoutCancel(ctx)
+	defer func() {
+		if rec := recover(); rec != nil {
+			slog.Error("Book import panicked", slog.String("job_id", job.ID.String()), slog.Any("panic", rec))
+			s.finish(saveCtx, job, fmt.Errorf("internal error: %v", rec))
+		}
+	}()
+
+	ctx = utils.WithCurrentUser(ctx, job.ActorID, "")
+	startedAt := time.Now()
+	job.Status = model.JobStatusRunning
+	job.StartedAt = &startedAt
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		slog.Error("Failed to mark import job running", slog.String("job_id", job.ID.String()), slog.Any("error", err))
+	}
+
+	s.finish(saveCtx, job, s.process(ctx, job, task.source, task.format, task.dryRun))
+}
+
+func (s *bookImportService) process(ctx context.Context, job *model.Job, source, format string, dryRun bool) error {
+	reader, err := s.uploadService.OpenObject(ctx, source)
+	if err != nil {
+		return fmt.Errorf("failed to open import source: %v", err)
+	}
+	defer reader.Close()
+
+	rows, err := newRowReader(format, reader, s.currency)
+	if err != nil {
+		return err
+	}
+	if reporter, ok := rows.(unmappedReporter); ok {
+		job.Unmapped = reporter.Unmapped()
+	}
+
+	report, err := os.CreateTemp("", "book-import-report-*.csv")
+	if err != nil {
+		return fmt.Errorf("failed to create import report: %v", err)
+	}
+	defer os.Remove(report.Name())
+	defer report.Close()
+
+	reportWriter := csv.NewWriter(report)
+	_ = reportWriter.Write([]string{"row", "isbn", "error"})
+
+	for {
+		if ctx.Err() != nil {
+			return errInterrupted
+		}
+		row, req, err := rows.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && row == 0 {
+			return fmt.Errorf("failed to read import source: %v", err)
+		}
+
+		job.Total++
+		if err == nil {
+			err = s.importRow(ctx, req, dryRun)
+		}
+		if err != nil {
+			job.Failed++
+			isbn := ""
+			if req != nil {
+				isbn = req.ISBN
+			}
+			_ = reportWriter.Write([]string{fmt.Sprint(row), isbn, err.Error()})
+		} else {
+			job.Succeeded++
+		}
+
+		if job.Total%progressEvery == 0 {
+			if err := s.jobRepo.Update(ctx, job); err != nil {
+				slog.Error("Failed to save import progress", slog.String("job_id", job.ID.String()), slog.Any("error", err))
+			}
+		}
+	}
+
+	reportWriter.Flush()
+	if err := reportWriter.Error(); err != nil {
+		return fmt.Errorf("failed to write import report: %v", err)
+	}
+	if job.Failed == 0 {
+		return nil
+	}
+
+	if _, err := report.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read import report: %v", err)
+	}
+	objectName := fmt.Sprintf("imports/%s/errors.csv", job.ID)
+	if err := s.uploadService.PutObject(ctx, objectName, report, -1, "text/csv"); err != nil {
+		return fmt.Errorf("failed to store import report: %v", err)
+	}
+	job.ResultObject = objectName
+
+	return nil
+}
+
+// importRow validates a row and upserts it by ISBN. Dry runs stop after the
+// lookup. An existing book only takes the fields the row has a value for,
+// and a row changing its stock is refused.
+func (s *bookImportService) importRow(ctx context.Context, req *model.CreateBookRequest, dryRun bool) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	existing, err := s.bookRepo.FindByISBN(ctx, req.ISBN)
+	switch {
+	case err == nil:
+		if req.Stock != 0 && req.Stock != existing.Stock {
+			return service.ErrStockNotEditable
+		}
+		if dryRun {
+			return nil
+		}
+		_, err = s.bookService.UpdateBook(ctx, existing.ID.String(), mergeRequest(req), 0)
+		return err
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if dryRun {
+			return nil
+		}
+		_, err = s.bookService.CreateBook(ctx, req)
+		return err
+	default:
+		return fmt.Errorf("failed to look up ISBN: %v", err)
+	}
+}
+
+// mergeRequest converts an import row into an update of the fields it has a
+// value for, so a row without a work, format or language does not clear
+// the book's.
+func mergeRequest(req *model.CreateBookRequest) *model.UpdateBookRequest {
+	update := &model.UpdateBookRequest{
+		Title:       &req.Title,
+		Author:      &req.Author,
+		Price:       &req.Price,
+		ISBN:        &req.ISBN,
+		PublishedAt: &req.PublishedAt,
+		WorkID:      req.WorkID,
+		CategoryID:  req.CategoryID,
+		Extra:       req.Extra,
+	}
+	if req.Description != "" {
+		update.Description = &req.Description
+	}
+	if req.CoverImage != "" {
+		update.CoverImage = &req.CoverImage
+	}
+	if req.Currency != "" {
+		update.Currency = &req.Currency
+	}
+	if req.Format != "" {
+		update.Format = &req.Format
+	}
+	if req.Language != "" {
+		update.Language = &req.Language
+	}
+	if req.PageCount != 0 {
+		update.PageCount = &req.PageCount
+	}
+	return update
+}
+
+func (s *bookImportService) finish(ctx context.Context, job *model.Job, err error) {
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	job.Status = model.JobStatusCompleted
+	if err != nil {
+		job.Status = model.JobStatusFailed
+		job.Error = err.Error()
+		slog.Error("Book import failed", slog.String("job_id", job.ID.String()), slog.Any("error", err))
+	}
+	if err := s.jobRepo.Update(ctx, job); err != nil {
+		slog.Error("Failed to save import job", slog.String("job_id", job.ID.String()), slog.Any("error", err))
+	}
+}
+
+func formatFromName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return model.BookImportFormatCSV
+	case ".ndjson", ".jsonl":
+		return model.BookImportFormatNDJSON
+	case ".xml", ".onix":
+		return model.BookImportFormatONIX
+	case ".mrc", ".marc":
+		return model.BookImportFormatMARC
+	case ".marcxml":
+		return model.BookImportFormatMARCXML
+	}
+	return ""
+}
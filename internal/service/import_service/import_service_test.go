@@ -0,0 +1,259 @@
+package import_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fakeUploads serves objects from a map and keeps what is put
+type fakeUploads struct {
+	service.IUploadService
+	objects map[string]string
+}
+
+func (s *fakeUploads) OpenObject(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	data, ok := s.objects[objectName]
+	if !ok {
+		return nil, errors.New("no such object")
+	}
+	return io.NopCloser(strings.NewReader(data)), nil
+}
+
+func (s *fakeUploads) PutObject(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) error {
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, reader); err != nil {
+		return err
+	}
+	s.objects[objectName] = buf.String()
+	return nil
+}
+
+// fakeBookService records the creates and updates an import makes
+type fakeBookService struct {
+	service.IBookService
+	created []*model.CreateBookRequest
+	updated map[string]*model.UpdateBookRequest
+}
+
+func (s *fakeBookService) CreateBook(ctx context.Context, req *model.CreateBookRequest) (*model.BookResponse, error) {
+	s.created = append(s.created, req)
+	return &model.BookResponse{}, nil
+}
+
+func (s *fakeBookService) UpdateBook(ctx context.Context, id string, req *model.UpdateBookRequest, expectedVersion int) (*model.BookResponse, error) {
+	s.updated[id] = req
+	return &model.BookResponse{}, nil
+}
+
+func TestImport(t *testing.T) {
+	const csvSource = `title,author,price,isbn,published_at,description
+Dune,Frank Herbert,9.99,9780306406157,1965-08-01,
+The C Programming Language,Kernighan,45.00,9780131103627,1978-02-22,Classic
+No ISBN,Nobody,1.00,,2000-01-01,
+Bad price,Nobody,cheap,9780306406157,2000-01-01,
+`
+	const ndjsonSource = `{"title": "Dune", "author": "Frank Herbert", "price": 9.99, "isbn": "9780306406157", "published_at": "1965-08-01"}
+
+{"title": "Broken",
+{"title": "Late", "author": "Someone", "price": 5, "isbn": "9780131103627", "published_at": "yesterday"}
+`
+
+	tests := []struct {
+		name   string
+		format string
+		source string
+		dryRun bool
+		// wantReport lists the row numbers of the failed rows, in order
+		wantTotal, wantSucceeded int
+		wantCreated, wantUpdated int
+		wantReport               []string
+	}{
+		{name: "csv upserts by ISBN", format: model.BookImportFormatCSV, source: csvSource,
+			wantTotal: 4, wantSucceeded: 2, wantCreated: 1, wantUpdated: 1, wantReport: []string{"4,", "5,"}},
+		{name: "dry run writes nothing", format: model.BookImportFormatCSV, source: csvSource, dryRun: true,
+			wantTotal: 4, wantSucceeded: 2, wantReport: []string{"4,", "5,"}},
+		{name: "ndjson", format: model.BookImportFormatNDJSON, source: ndjsonSource,
+			wantTotal: 3, wantSucceeded: 1, wantCreated: 1, wantReport: []string{"3,", "4,"}},
+		{name: "stock of an existing book is refused", format: model.BookImportFormatCSV,
+			source:    "title,author,price,isbn,published_at,stock\nK&R,Kernighan,45.00,9780131103627,1978-02-22,7\n",
+			wantTotal: 1, wantReport: []string{`2,9780131103627,"stock cannot be set`}},
+		{name: "clean import has no report", format: model.BookImportFormatCSV,
+			source:    "title,author,price,isbn,published_at\nDune,Frank Herbert,9.99,9780306406157,1965-08-01\n",
+			wantTotal: 1, wantSucceeded: 1, wantCreated: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := &model.Book{ID: uuid.New(), ISBN: "9780131103627"}
+			uploads := &fakeUploads{objects: map[string]string{"source": tt.source}}
+			books := &fakeBookService{updated: make(map[string]*model.UpdateBookRequest)}
+			s := NewBookImportService(books, repotest.NewBooks(existing),
+				nil, uploads, "USD").(*bookImportService)
+
+			job := &model.Job{ID: uuid.New()}
+			if err := s.process(context.Background(), job, "source", tt.format, tt.dryRun); err != nil {
+				t.Fatalf("process() error = %v", err)
+			}
+
+			if job.Total != tt.wantTotal || job.Succeeded != tt.wantSucceeded || job.Failed != tt.wantTotal-tt.wantSucceeded {
+				t.Errorf("job counted %d rows, %d succeeded, %d failed; want %d, %d, %d",
+					job.Total, job.Succeeded, job.Failed, tt.wantTotal, tt.wantSucceeded, tt.wantTotal-tt.wantSucceeded)
+			}
+			if len(books.created) != tt.wantCreated || len(books.updated) != tt.wantUpdated {
+				t.Errorf("created %d and updated %d books, want %d and %d",
+					len(books.created), len(books.updated), tt.wantCreated, tt.wantUpdated)
+			}
+			if tt.wantUpdated > 0 {
+				// A row without a description keeps the book's
+				if update := books.updated[existing.ID.String()]; update == nil || update.Description == nil || *update.Description != "Classic" {
+					t.Errorf("update = %+v", update)
+				}
+			}
+
+			if len(tt.wantReport) == 0 {
+				if job.ResultObject != "" {
+					t.Errorf("report written for a clean import")
+				}
+				return
+			}
+			report := strings.Split(strings.TrimSpace(uploads.objects[job.ResultObject]), "\n")
+			if len(report) != len(tt.wantReport)+1 || report[0] != "row,isbn,error" {
+				t.Fatalf("report = %q", report)
+			}
+			for i, prefix := range tt.wantReport {
+				if !strings.HasPrefix(report[i+1], prefix) {
+					t.Errorf("report line %d = %q, want row %s", i+1, report[i+1], prefix)
+				}
+			}
+		})
+	}
+}
+
+func TestCSVRows(t *testing.T) {
+	workID := uuid.New()
+	source := "title,author,price,currency,isbn,published_at,format,language,page_count,work_id\n" +
+		"Dune,Frank Herbert,9.99,eur,9780306406157,1965-08-01,paperback,en,412," + workID.String() + "\n" +
+		"Dune,Frank Herbert,9.99,EUR,9780306406157,1965-08-01,paperback,en,many,\n" +
+		"Dune,Frank Herbert,9.99,EUR,9780306406157,1965-08-01,paperback,en,412,dune\n"
+
+	rows, err := newCSVRows(strings.NewReader(source))
+	if err != nil {
+		t.Fatalf("newCSVRows() error = %v", err)
+	}
+
+	line, req, err := rows.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if line != 2 || req.Currency != "EUR" || req.Format != "paperback" || req.Language != "en" ||
+		req.PageCount != 412 || req.WorkID == nil || *req.WorkID != workID {
+		t.Errorf("row %d = %+v", line, req)
+	}
+	for _, want := range []string{"invalid page_count", "invalid work_id"} {
+		if _, _, err := rows.Next(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Next() error = %v, want %s", err, want)
+		}
+	}
+	if _, _, err := rows.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Next() error = %v, want io.EOF", err)
+	}
+}
+
+func TestFormatFromName(t *testing.T) {
+	tests := map[string]string{
+		"books.csv":       model.BookImportFormatCSV,
+		"books.NDJSON":    model.BookImportFormatNDJSON,
+		"books.jsonl":     model.BookImportFormatNDJSON,
+		"feed.xml":        model.BookImportFormatONIX,
+		"records.mrc":     model.BookImportFormatMARC,
+		"records.marcxml": model.BookImportFormatMARCXML,
+		"books.txt":       "",
+		"books":           "",
+	}
+	for name, want := range tests {
+		if got := formatFromName(name); got != want {
+			t.Errorf("formatFromName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestGetImportJob(t *testing.T) {
+	job := &model.Job{ID: uuid.New(), Type: model.JobTypeBookImport, Status: model.JobStatusCompleted, ActorID: "owner"}
+	s := NewBookImportService(nil, nil, repotest.NewJobs(job), nil, "USD")
+
+	tests := []struct {
+		name    string
+		userID  string
+		role    string
+		wantErr error
+	}{
+		{name: "owner", userID: "owner", role: "user"},
+		{name: "admin", userID: "admin", role: "admin"},
+		{name: "someone else", userID: "other", role: "user", wantErr: service.ErrJobNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := utils.WithCurrentUser(context.Background(), tt.userID, tt.role)
+			if _, err := s.GetImportJob(ctx, job.ID.String()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetImportJob() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunWorkers(t *testing.T) {
+	orphan := &model.Job{ID: uuid.New(), Type: model.JobTypeBookImport, Status: model.JobStatusRunning,
+		CreatedAt: time.Now().Add(-time.Hour)}
+	jobs := repotest.NewJobs(orphan)
+	uploads := &fakeUploads{objects: map[string]string{
+		"source": "title,author,price,isbn,published_at\nDune,Frank Herbert,9.99,9780306406157,1965-08-01\n",
+	}}
+	books := &fakeBookService{updated: make(map[string]*model.UpdateBookRequest)}
+	s := NewBookImportService(books, repotest.NewBooks(), jobs, uploads, "USD").(*bookImportService)
+
+	ctx, cancel := context.WithCancel(utils.WithCurrentUser(context.Background(), "owner", "user"))
+	done := make(chan struct{})
+	go func() {
+		s.RunWorkers(ctx)
+		close(done)
+	}()
+
+	started, err := s.StartImport(ctx, &model.BookImportRequest{Object: "source", Format: model.BookImportFormatCSV}, nil, 0, "")
+	if err != nil {
+		t.Fatalf("StartImport() error = %v", err)
+	}
+	id := started.ID
+	for deadline := time.Now().Add(5 * time.Second); jobs.Get(id).Status != model.JobStatusCompleted; {
+		if time.Now().After(deadline) {
+			t.Fatalf("import is %s, want completed", jobs.Get(id).Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if job := jobs.Get(orphan.ID); job.Status != model.JobStatusFailed {
+		t.Errorf("job left by a previous process is %s, want failed", job.Status)
+	}
+
+	cancel()
+	<-done
+
+	// Imports queued once the workers have stopped are failed by the next
+	// process, not silently kept pending
+	queued := &importTask{job: &model.Job{ID: uuid.New(), Type: model.JobTypeBookImport, Status: model.JobStatusPending}}
+	jobs.Put(queued.job)
+	s.queue.Push(queued)
+	s.RunWorkers(ctx)
+	if job := jobs.Get(queued.job.ID); job.Status != model.JobStatusFailed {
+		t.Errorf("import queued at shutdown is %s, want failed", job.Status)
+	}
+}
@@ -0,0 +1,58 @@
+package import_service
+
+import (
+	"book_system/internal/baselib/marc"
+	"book_system/internal/model"
+	"errors"
+	"io"
+)
+
+// marcRows maps MARC records to import rows, numbered by their position.
+// Each record is kept whole on its book, so nothing is dropped; Unmapped
+// counts the tags that have no book column.
+type marcRows struct {
+	next     func() (*marc.Record, error)
+	currency string
+	row      int
+	unmapped map[string]int
+}
+
+func newMARCRows(r io.Reader, format, currency string) *marcRows {
+	rows := &marcRows{currency: currency, unmapped: make(map[string]int)}
+	if format == model.BookImportFormatMARCXML {
+		rows.next = marc.NewXMLReader(r).Next
+	} else {
+		rows.next = marc.NewReader(r).Next
+	}
+	return rows
+}
+
+func (m *marcRows) Next() (int, *model.CreateBookRequest, error) {
+	record, err := m.next()
+	if errors.Is(err, io.EOF) {
+		return 0, nil, io.EOF
+	}
+	// Binary records carry their own length, so a bad one is skipped as a
+	// failed row; anything else leaves no way to find the next record
+	var recordErr *marc.RecordError
+	if errors.As(err, &recordErr) {
+		m.row++
+		return m.row, nil, err
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	m.row++
+
+	for _, tag := range model.UnmappedMARCTags(record) {
+		m.unmapped[tag]++
+	}
+
+	req, err := model.CreateBookRequestFromMARC(record, m.currency)
+	return m.row, req, err
+}
+
+// Unmapped returns how many times each unmapped tag occurred so far
+func (m *marcRows) Unmapped() map[string]int {
+	return m.unmapped
+}
@@ -0,0 +1,72 @@
+package import_service
+
+import (
+	"book_system/internal/baselib/onix"
+	"book_system/internal/model"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// onixRows maps ONIX products to import rows, counting the product data
+// that has no place on a book
+type onixRows struct {
+	reader   *onix.Reader
+	currency string
+	unmapped map[string]int
+}
+
+func newONIXRows(r io.Reader, currency string) *onixRows {
+	return &onixRows{
+		reader:   onix.NewReader(r),
+		currency: currency,
+		unmapped: make(map[string]int),
+	}
+}
+
+// Next returns the next product as a row numbered by its position in the message
+func (o *onixRows) Next() (int, *model.CreateBookRequest, error) {
+	row, product, unmapped, err := o.reader.Next()
+	if errors.Is(err, io.EOF) {
+		return 0, nil, io.EOF
+	}
+	for _, path := range unmapped {
+		o.unmapped[path]++
+	}
+	if product == nil {
+		return row, nil, err
+	}
+
+	req := &model.CreateBookRequest{
+		Title:       product.Title,
+		Author:      strings.Join(product.Authors(), "; "),
+		Description: product.Description,
+		CoverImage:  product.CoverURL,
+		ISBN:        product.ISBN,
+		PublishedAt: product.PublicationDate,
+	}
+	if product.OnHand != nil {
+		req.Stock = *product.OnHand
+	}
+	if err != nil {
+		return row, req, err
+	}
+
+	if product.NotificationType == onix.NotificationDelete {
+		return row, req, fmt.Errorf("record %s is a delete notification, which is not supported", product.RecordReference)
+	}
+	if product.Price != nil {
+		if product.Price.Currency != "" && !strings.EqualFold(product.Price.Currency, o.currency) {
+			req.Currency = strings.ToUpper(product.Price.Currency)
+		}
+		req.Price = product.Price.Amount
+	}
+
+	return row, req, nil
+}
+
+// Unmapped returns how many times each unmapped path occurred so far
+func (o *onixRows) Unmapped() map[string]int {
+	return o.unmapped
+}
@@ -0,0 +1,197 @@
+package import_service
+
+import (
+	"book_system/internal/model"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// rowReader yields import rows one at a time. Next returns the row's line
+// number with either the parsed request or a row-level error; a zero line
+// number with an error means the source itself is unreadable. io.EOF ends
+// the rows.
+type rowReader interface {
+	Next() (int, *model.CreateBookRequest, error)
+}
+
+// unmappedReporter is implemented by row readers for formats carrying more
+// data than a book holds
+type unmappedReporter interface {
+	Unmapped() map[string]int
+}
+
+func newRowReader(format string, r io.Reader, currency string) (rowReader, error) {
+	switch format {
+	case model.BookImportFormatCSV:
+		return newCSVRows(r)
+	case model.BookImportFormatNDJSON:
+		return newNDJSONRows(r), nil
+	case model.BookImportFormatONIX:
+		return newONIXRows(r, currency), nil
+	case model.BookImportFormatMARC, model.BookImportFormatMARCXML:
+		return newMARCRows(r, format, currency), nil
+	}
+	return nil, fmt.Errorf("unsupported import format %q", format)
+}
+
+type csvRows struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVRows(r io.Reader) (*csvRows, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, required := range []string{"title", "author", "price", "isbn", "published_at"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header is missing the %s column", required)
+		}
+	}
+
+	return &csvRows{reader: reader, columns: columns}, nil
+}
+
+func (c *csvRows) Next() (int, *model.CreateBookRequest, error) {
+	record, err := c.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return 0, nil, io.EOF
+	}
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return parseErr.StartLine, nil, err
+		}
+		return 0, nil, err
+	}
+	// FieldPos is only valid for a record Read returned without error
+	line, _ := c.reader.FieldPos(0)
+
+	field := func(name string) string {
+		if i, ok := c.columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	req := &model.CreateBookRequest{
+		Title:       field("title"),
+		Author:      field("author"),
+		Description: field("description"),
+		CoverImage:  field("cover_image"),
+		Currency:    strings.ToUpper(field("currency")),
+		ISBN:        field("isbn"),
+		Format:      field("format"),
+		Language:    field("language"),
+	}
+
+	if req.Price, err = decimal.NewFromString(field("price")); err != nil {
+		return line, req, fmt.Errorf("invalid price %q", field("price"))
+	}
+	if stock := field("stock"); stock != "" {
+		if req.Stock, err = strconv.Atoi(stock); err != nil {
+			return line, req, fmt.Errorf("invalid stock %q", stock)
+		}
+	}
+	if req.PublishedAt, err = parseDate(field("published_at")); err != nil {
+		return line, req, fmt.Errorf("invalid published_at %q", field("published_at"))
+	}
+	if pageCount := field("page_count"); pageCount != "" {
+		if req.PageCount, err = strconv.Atoi(pageCount); err != nil {
+			return line, req, fmt.Errorf("invalid page_count %q", pageCount)
+		}
+	}
+	if req.WorkID, err = parseOptionalID(field("work_id")); err != nil {
+		return line, req, fmt.Errorf("invalid work_id %q", field("work_id"))
+	}
+	if req.CategoryID, err = parseOptionalID(field("category_id")); err != nil {
+		return line, req, fmt.Errorf("invalid category_id %q", field("category_id"))
+	}
+
+	return line, req, nil
+}
+
+// parseOptionalID parses a UUID, returning nil for an empty value
+func parseOptionalID(value string) (*uuid.UUID, error) {
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// parseDate accepts a plain date or an RFC 3339 timestamp
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+type ndjsonRows struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newNDJSONRows(r io.Reader) *ndjsonRows {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	return &ndjsonRows{scanner: scanner}
+}
+
+func (n *ndjsonRows) Next() (int, *model.CreateBookRequest, error) {
+	for n.scanner.Scan() {
+		n.line++
+		raw := bytes.TrimSpace(n.scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		var row struct {
+			model.CreateBookRequest
+			PublishedAt string `json:"published_at"`
+		}
+		if err := json.Unmarshal(raw, &row); err != nil {
+			return n.line, nil, fmt.Errorf("invalid JSON: %v", err)
+		}
+
+		req := row.CreateBookRequest
+		if row.PublishedAt != "" {
+			publishedAt, err := parseDate(row.PublishedAt)
+			if err != nil {
+				return n.line, &req, fmt.Errorf("invalid published_at %q", row.PublishedAt)
+			}
+			req.PublishedAt = publishedAt
+		}
+		return n.line, &req, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return 0, nil, err
+	}
+	return 0, nil, io.EOF
+}
@@ -0,0 +1,102 @@
+package import_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository/repotest"
+	export_service "book_system/internal/service/export_service"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// TestExportRoundTrip feeds every export format the import reads back to
+// the import, which must recover the fields the format carries
+func TestExportRoundTrip(t *testing.T) {
+	workID, categoryID := uuid.New(), uuid.New()
+	published := time.Date(1965, 8, 1, 0, 0, 0, 0, time.UTC)
+	// The catalog currency is USD; the second book is priced in euros
+	dune := &model.Book{ID: uuid.New(), Title: "Dune", Author: "Frank Herbert",
+		Description: "Spice, sand and \"worms\", on Arrakis", CoverImage: "https://covers.example/dune.jpg",
+		Price: decimal.RequireFromString("12.5"), Stock: 7, ISBN: "9780441013593", PublishedAt: published,
+		WorkID: &workID, CategoryID: &categoryID, Format: "paperback", Language: "en", PageCount: 896,
+		CreatedAt: time.Now().Add(-time.Hour)}
+	manifold := &model.Book{ID: uuid.New(), Title: "Manifold: Time", Author: "Stephen Baxter; Ian Stewart",
+		Price: decimal.RequireFromString("9.9"), Currency: "EUR", ISBN: "9780345430762", PublishedAt: published,
+		Format: "ebook", Language: "en-GB", CreatedAt: time.Now()}
+	exporter := export_service.NewBookExportService(repotest.NewBooks(dune, manifold), nil, nil, "Sender", "USD")
+
+	// request is what importing book should give, keeping only the fields
+	// a format carries
+	request := func(book *model.Book, everyField bool) *model.CreateBookRequest {
+		req := &model.CreateBookRequest{Title: book.Title, Author: book.Author, Description: book.Description,
+			CoverImage: book.CoverImage, Price: book.Price, Currency: book.Currency, Stock: book.Stock,
+			ISBN: book.ISBN, PublishedAt: book.PublishedAt}
+		if everyField {
+			req.WorkID, req.CategoryID = book.WorkID, book.CategoryID
+			req.Format, req.Language, req.PageCount = book.Format, book.Language, book.PageCount
+		}
+		return req
+	}
+
+	tests := []struct {
+		format string
+		want   []*model.CreateBookRequest
+	}{
+		{format: model.BookExportFormatCSV, want: []*model.CreateBookRequest{request(dune, true), request(manifold, true)}},
+		{format: model.BookExportFormatNDJSON, want: []*model.CreateBookRequest{request(dune, true), request(manifold, true)}},
+		// ONIX carries neither the edition details nor the catalog's own references
+		{format: model.BookExportFormatONIX, want: []*model.CreateBookRequest{request(dune, false), request(manifold, false)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var exported bytes.Buffer
+			if err := exporter.ExportBooks(context.Background(), tt.format, nil, &exported); err != nil {
+				t.Fatalf("ExportBooks() error = %v", err)
+			}
+
+			rows, err := newRowReader(tt.format, &exported, "USD")
+			if err != nil {
+				t.Fatalf("newRowReader() error = %v", err)
+			}
+			var got []*model.CreateBookRequest
+			for {
+				line, req, err := rows.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("row %d: %v", line, err)
+				}
+				got = append(got, req)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("imported %d rows, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if !sameRequest(got[i], tt.want[i]) {
+					t.Errorf("row %d = %+v, want %+v", i+1, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// sameRequest compares import requests, prices and dates by value
+func sameRequest(got, want *model.CreateBookRequest) bool {
+	if !got.Price.Equal(want.Price) || !got.PublishedAt.Equal(want.PublishedAt) {
+		return false
+	}
+	gotRest, wantRest := *got, *want
+	gotRest.Price, wantRest.Price = decimal.Zero, decimal.Zero
+	gotRest.PublishedAt, wantRest.PublishedAt = time.Time{}, time.Time{}
+	return reflect.DeepEqual(gotRest, wantRest)
+}
@@ -0,0 +1,544 @@
+package inventory_service
+
+import (
+	isbnlib "book_system/internal/baselib/isbn"
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type inventoryService struct {
+	bookRepo          repository.IBookRepository
+	movementRepo      repository.IStockMovementRepository
+	locationRepo      repository.ILocationRepository
+	levelRepo         repository.IStockLevelRepository
+	transactor        repository.ITransactor
+	holds             service.IHoldService
+	userRepo          repository.IUserRepository
+	notifications     service.INotificationService
+	lowStockThreshold int
+}
+
+// NewInventoryService creates a new inventory service. Adjustments that
+// take a book down to lowStockThreshold copies or fewer notify the admins;
+// stock coming in is set aside for the book's holds first.
+func NewInventoryService(
+	bookRepo repository.IBookRepository,
+	movementRepo repository.IStockMovementRepository,
+	locationRepo repository.ILocationRepository,
+	levelRepo repository.IStockLevelRepository,
+	transactor repository.ITransactor,
+	holds service.IHoldService,
+	userRepo repository.IUserRepository,
+	notifications service.INotificationService,
+	lowStockThreshold int,
+) service.IInventoryService {
+	return &inventoryService{
+		bookRepo:          bookRepo,
+		movementRepo:      movementRepo,
+		locationRepo:      locationRepo,
+		levelRepo:         levelRepo,
+		transactor:        transactor,
+		holds:             holds,
+		userRepo:          userRepo,
+		notifications:     notifications,
+		lowStockThreshold: lowStockThreshold,
+	}
+}
+
+// AdjustStock atomically changes a book's stock and records the movement in
+// the same transaction. The stock never goes negative: a sale of more copies
+// than are left fails with ErrInsufficientStock. Adjustments at a location
+// change its level together with the book's aggregate stock; the others
+// can only use the stock not held at any location. Stock coming in goes to
+// the book's waiting holds, in the same transaction. An adjustment that
+// takes the stock from above the low-stock threshold to or below it
+// notifies the admins, also in the same transaction, so each crossing
+// alerts once and a rolled back sale does not alert at all.
+func (s *inventoryService) AdjustStock(ctx context.Context, id string, req *model.StockAdjustRequest) (*model.StockAdjustResponse, error) {
+	bookID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookID, err)
+	}
+
+	delta := req.Delta()
+	movement := &model.StockMovement{
+		ID:         uuid.New(),
+		BookID:     bookID,
+		LocationID: req.LocationID,
+		Type:       req.Type,
+		Quantity:   delta,
+		Reason:     req.Reason,
+		ActorID:    utils.UserIDFromContext(ctx),
+		CreatedAt:  time.Now(),
+	}
+
+	var locationStock *int
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// The level goes first so the aggregate never drops below it
+		if req.LocationID != nil {
+			if _, err := s.findLocation(ctx, *req.LocationID); err != nil {
+				return err
+			}
+			quantity, err := s.levelRepo.Adjust(ctx, bookID, *req.LocationID, delta)
+			if err != nil {
+				if errors.Is(err, repository.ErrInsufficientStock) {
+					return fmt.Errorf("%w: %d in stock at this location", service.ErrInsufficientStock, quantity)
+				}
+				return fmt.Errorf("failed to adjust location stock: %v", err)
+			}
+			locationStock = &quantity
+		}
+
+		stock, err := s.bookRepo.AdjustStock(ctx, bookID, delta)
+		if err != nil {
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				return service.ErrBookNotFound
+			case errors.Is(err, repository.ErrInsufficientStock):
+				return fmt.Errorf("%w: %d in stock", service.ErrInsufficientStock, stock)
+			}
+			return fmt.Errorf("failed to adjust stock: %v", err)
+		}
+
+		movement.StockAfter = stock
+		if err := s.movementRepo.Create(ctx, movement); err != nil {
+			return fmt.Errorf("failed to record stock movement: %v", err)
+		}
+
+		if delta > 0 {
+			if _, err := s.holds.AssignHolds(ctx, bookID); err != nil {
+				return err
+			}
+		}
+		// The update is atomic, so stock - delta is the stock it found
+		if stock <= s.lowStockThreshold && stock-delta > s.lowStockThreshold {
+			return s.alertLowStock(ctx, bookID, stock)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lowStock := movement.StockAfter <= s.lowStockThreshold
+
+	return &model.StockAdjustResponse{
+		BookID:        bookID,
+		Stock:         movement.StockAfter,
+		LocationStock: locationStock,
+		LowStock:      lowStock,
+		Movement:      movement.ToDTO(),
+	}, nil
+}
+
+// alertLowStock notifies every admin that a book is down to stock copies
+func (s *inventoryService) alertLowStock(ctx context.Context, bookID uuid.UUID, stock int) error {
+	book, err := s.bookRepo.FindByID(ctx, bookID)
+	if err != nil {
+		return fmt.Errorf("failed to find book: %v", err)
+	}
+	admins, err := s.userRepo.FindByRole(ctx, "admin")
+	if err != nil {
+		return fmt.Errorf("failed to find admins: %v", err)
+	}
+
+	slog.Warn("Book stock is low",
+		slog.String("book_id", bookID.String()),
+		slog.Int("stock", stock),
+		slog.Int("threshold", s.lowStockThreshold),
+	)
+	message := fmt.Sprintf("Only %d copies of %q are left in stock", stock, book.Title)
+	for _, admin := range admins {
+		if err := s.notifications.Notify(ctx, admin.ID, model.NotificationLowStock, message, &bookID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TransferStock moves copies of a book from one location to another in a
+// single transaction, recording a transfer movement at each end under a
+// shared transfer ID. The book's aggregate stock does not change.
+func (s *inventoryService) TransferStock(ctx context.Context, id string, req *model.StockTransferRequest) (*model.StockTransferResponse, error) {
+	bookID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookID, err)
+	}
+
+	transferID := uuid.New()
+	now := time.Now()
+	actorID := utils.UserIDFromContext(ctx)
+	result := &model.StockTransferResponse{
+		TransferID: transferID,
+		BookID:     bookID,
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		book, err := s.bookRepo.FindByID(ctx, bookID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return service.ErrBookNotFound
+			}
+			return fmt.Errorf("failed to find book: %v", err)
+		}
+		from, err := s.findLocation(ctx, req.FromLocationID)
+		if err != nil {
+			return err
+		}
+		to, err := s.findLocation(ctx, req.ToLocationID)
+		if err != nil {
+			return err
+		}
+
+		fromQuantity, err := s.levelRepo.Adjust(ctx, bookID, from.ID, -req.Quantity)
+		if err != nil {
+			if errors.Is(err, repository.ErrInsufficientStock) {
+				return fmt.Errorf("%w: %d in stock at %s", service.ErrInsufficientStock, fromQuantity, from.Code)
+			}
+			return fmt.Errorf("failed to adjust location stock: %v", err)
+		}
+		toQuantity, err := s.levelRepo.Adjust(ctx, bookID, to.ID, req.Quantity)
+		if err != nil {
+			return fmt.Errorf("failed to adjust location stock: %v", err)
+		}
+
+		legs := []*model.StockMovement{
+			{LocationID: &from.ID, Quantity: -req.Quantity},
+			{LocationID: &to.ID, Quantity: req.Quantity},
+		}
+		for _, leg := range legs {
+			leg.ID = uuid.New()
+			leg.BookID = bookID
+			leg.TransferID = &transferID
+			leg.Type = model.StockMovementTransfer
+			leg.StockAfter = book.Stock
+			leg.Reason = req.Reason
+			leg.ActorID = actorID
+			leg.CreatedAt = now
+			if err := s.movementRepo.Create(ctx, leg); err != nil {
+				return fmt.Errorf("failed to record stock movement: %v", err)
+			}
+			result.Movements = append(result.Movements, leg.ToDTO())
+		}
+
+		result.From = &model.LocationStock{Location: from.ToDTO(), Quantity: fromQuantity}
+		result.To = &model.LocationStock{Location: to.ToDTO(), Quantity: toQuantity}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetBookStock breaks a book's stock down by location. The stock not held
+// at any location is reported as unallocated.
+func (s *inventoryService) GetBookStock(ctx context.Context, id string) (*model.BookStockResponse, error) {
+	bookID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookID, err)
+	}
+	book, err := s.bookRepo.FindByID(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrBookNotFound
+		}
+		return nil, fmt.Errorf("failed to find book: %v", err)
+	}
+
+	locations, err := s.locationStock(ctx, book.ID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	allocated := 0
+	for _, location := range locations {
+		allocated += location.Quantity
+	}
+
+	return &model.BookStockResponse{
+		BookID:      book.ID,
+		Stock:       book.Stock,
+		Unallocated: book.Stock - allocated,
+		Locations:   locations,
+	}, nil
+}
+
+// GetAvailability lists the locations that have copies of a book in stock,
+// largest quantities first. The ISBN may be given in any form.
+func (s *inventoryService) GetAvailability(ctx context.Context, isbn string) (*model.BookAvailability, error) {
+	if _, err := isbnlib.Normalize(isbn); err != nil {
+		return nil, fmt.Errorf("%w: %s", service.ErrInvalidISBN, isbn)
+	}
+	book, err := s.bookRepo.FindByISBN(ctx, isbn)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrBookNotFound
+		}
+		return nil, fmt.Errorf("failed to find book: %v", err)
+	}
+
+	locations, err := s.locationStock(ctx, book.ID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.BookAvailability{
+		BookID:    book.ID,
+		ISBN:      book.ISBN,
+		Title:     book.Title,
+		Locations: locations,
+	}, nil
+}
+
+// locationStock gets the stock of a book at each location, skipping the
+// locations where it is out of stock when inStockOnly is set
+func (s *inventoryService) locationStock(ctx context.Context, bookID uuid.UUID, inStockOnly bool) ([]*model.LocationStock, error) {
+	levels, err := s.levelRepo.FindByBookID(ctx, bookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stock levels: %v", err)
+	}
+
+	locationIDs := make([]uuid.UUID, len(levels))
+	for i, level := range levels {
+		locationIDs[i] = level.LocationID
+	}
+	locations, err := s.locationRepo.FindByIDs(ctx, locationIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find locations: %v", err)
+	}
+	byID := make(map[uuid.UUID]*model.Location, len(locations))
+	for _, location := range locations {
+		byID[location.ID] = location
+	}
+
+	stock := make([]*model.LocationStock, 0, len(levels))
+	for _, level := range levels {
+		location, ok := byID[level.LocationID]
+		if !ok || (inStockOnly && level.Quantity <= 0) {
+			continue
+		}
+		stock = append(stock, &model.LocationStock{
+			Location: location.ToDTO(),
+			Quantity: level.Quantity,
+		})
+	}
+	return stock, nil
+}
+
+// findLocation gets a location by ID
+func (s *inventoryService) findLocation(ctx context.Context, id uuid.UUID) (*model.Location, error) {
+	location, err := s.locationRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", service.ErrLocationNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to find location: %v", err)
+	}
+	return location, nil
+}
+
+// ListMovements gets a paginated ledger of a book, newest first
+func (s *inventoryService) ListMovements(ctx context.Context, id string, page, pageSize int) (*model.StockMovementListResponse, error) {
+	bookID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookID, err)
+	}
+	if _, err := s.bookRepo.FindByIDUnscoped(ctx, bookID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrBookNotFound
+		}
+		return nil, fmt.Errorf("failed to find book: %v", err)
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	movements, total, err := s.movementRepo.FindByBookID(ctx, bookID, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock movements: %v", err)
+	}
+
+	movementDTOs := make([]*model.StockMovementResponse, len(movements))
+	for i, movement := range movements {
+		movementDTOs[i] = movement.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.StockMovementListResponse{
+		Data: movementDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// ReconcileStock compares a book's stock with the sum of its ledger and
+// rewrites the stock to match. Books whose stock predates the ledger get
+// an opening adjustment for their current stock instead. The level at
+// each location is checked against that location's ledger entries the
+// same way.
+func (s *inventoryService) ReconcileStock(ctx context.Context, id string) (*model.StockReconciliation, error) {
+	bookID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookID, err)
+	}
+
+	var result *model.StockReconciliation
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// The lock keeps adjustments out until the comparison is settled
+		book, err := s.bookRepo.FindByIDForUpdate(ctx, bookID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return service.ErrBookNotFound
+			}
+			return fmt.Errorf("failed to find book: %v", err)
+		}
+
+		ledgerStock, entries, err := s.movementRepo.SumByBookID(ctx, bookID)
+		if err != nil {
+			return fmt.Errorf("failed to sum stock movements: %v", err)
+		}
+
+		result = &model.StockReconciliation{
+			BookID:      bookID,
+			Stock:       book.Stock,
+			LedgerStock: ledgerStock,
+		}
+		switch {
+		case entries == 0 && book.Stock != 0:
+			result.Opening = true
+			result.LedgerStock = book.Stock
+			err := s.movementRepo.Create(ctx, &model.StockMovement{
+				ID:         uuid.New(),
+				BookID:     bookID,
+				Type:       model.StockMovementAdjust,
+				Quantity:   book.Stock,
+				StockAfter: book.Stock,
+				Reason:     "opening balance",
+				ActorID:    utils.UserIDFromContext(ctx),
+				CreatedAt:  time.Now(),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to record opening balance: %v", err)
+			}
+		case ledgerStock != book.Stock:
+			if ledgerStock < 0 {
+				return fmt.Errorf("ledger of book %s adds up to a negative stock of %d", bookID, ledgerStock)
+			}
+			result.Corrected = true
+			slog.Warn("Book stock did not match its ledger",
+				slog.String("book_id", bookID.String()),
+				slog.Int("stock", book.Stock),
+				slog.Int("ledger_stock", ledgerStock),
+			)
+			if err := s.bookRepo.SetStock(ctx, bookID, ledgerStock); err != nil {
+				return fmt.Errorf("failed to correct stock: %v", err)
+			}
+		}
+		return s.reconcileLocations(ctx, result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// reconcileLocations rewrites the levels of a book that do not match the
+// ledger entries of their location
+func (s *inventoryService) reconcileLocations(ctx context.Context, result *model.StockReconciliation) error {
+	ledger, err := s.movementRepo.SumByLocation(ctx, result.BookID)
+	if err != nil {
+		return fmt.Errorf("failed to sum stock movements: %v", err)
+	}
+	levels, err := s.levelRepo.FindByBookID(ctx, result.BookID)
+	if err != nil {
+		return fmt.Errorf("failed to find stock levels: %v", err)
+	}
+
+	quantities := make(map[uuid.UUID]int, len(levels))
+	for _, level := range levels {
+		quantities[level.LocationID] = level.Quantity
+		if _, ok := ledger[level.LocationID]; !ok {
+			ledger[level.LocationID] = 0
+		}
+	}
+
+	for locationID, ledgerQuantity := range ledger {
+		quantity := quantities[locationID]
+		if quantity == ledgerQuantity {
+			continue
+		}
+		if ledgerQuantity < 0 {
+			return fmt.Errorf("ledger of book %s adds up to a negative stock of %d at location %s", result.BookID, ledgerQuantity, locationID)
+		}
+
+		slog.Warn("Location stock did not match its ledger",
+			slog.String("book_id", result.BookID.String()),
+			slog.String("location_id", locationID.String()),
+			slog.Int("quantity", quantity),
+			slog.Int("ledger_quantity", ledgerQuantity),
+		)
+		if err := s.levelRepo.Set(ctx, result.BookID, locationID, ledgerQuantity); err != nil {
+			return fmt.Errorf("failed to correct location stock: %v", err)
+		}
+		result.Locations = append(result.Locations, &model.LocationStockReconciliation{
+			LocationID:     locationID,
+			Quantity:       quantity,
+			LedgerQuantity: ledgerQuantity,
+		})
+	}
+	return nil
+}
+
+// ListLowStock gets a paginated list of books at or below the low-stock threshold
+func (s *inventoryService) ListLowStock(ctx context.Context, page, pageSize int) (*model.BookListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	books, total, err := s.bookRepo.FindAll(ctx, page, pageSize, map[string]any{"stock <= ?": s.lowStockThreshold}, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list low-stock books: %v", err)
+	}
+
+	bookDTOs := make([]*model.BookResponse, len(books))
+	for i, book := range books {
+		bookDTOs[i] = book.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.BookListResponse{
+		Data: bookDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
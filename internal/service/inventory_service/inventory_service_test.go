@@ -0,0 +1,617 @@
+package inventory_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	hold_service "book_system/internal/service/hold_service"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeBooks refuses, like the repository, to take a book below the stock
+// held at its locations
+type fakeBooks struct {
+	*repotest.Books
+	levels *fakeLevels
+}
+
+func (r *fakeBooks) AdjustStock(ctx context.Context, id uuid.UUID, delta int) (int, error) {
+	book := r.Get(id)
+	if book == nil {
+		return 0, gorm.ErrRecordNotFound
+	}
+	allocated := 0
+	for key, quantity := range r.levels.quantities {
+		if key.bookID == id {
+			allocated += quantity
+		}
+	}
+	if book.Stock+delta < allocated {
+		return book.Stock, repository.ErrInsufficientStock
+	}
+	book.Stock += delta
+	return book.Stock, nil
+}
+
+// setStock stores a book with the given stock
+func (r *fakeBooks) setStock(id uuid.UUID, stock int) {
+	r.Put(&model.Book{ID: id, Stock: stock, Version: 1})
+}
+
+// stock returns the stock of a book
+func (r *fakeBooks) stock(id uuid.UUID) int {
+	return r.Get(id).Stock
+}
+
+// fakeMovements is the ledger, in the order movements were recorded
+type fakeMovements struct {
+	repository.IStockMovementRepository
+	movements []*model.StockMovement
+}
+
+func (r *fakeMovements) Create(ctx context.Context, movement *model.StockMovement) error {
+	r.movements = append(r.movements, movement)
+	return nil
+}
+
+func (r *fakeMovements) SumByBookID(ctx context.Context, bookID uuid.UUID) (int, int64, error) {
+	sum, entries := 0, int64(0)
+	for _, movement := range r.movements {
+		if movement.BookID == bookID {
+			sum += movement.Quantity
+			entries++
+		}
+	}
+	return sum, entries, nil
+}
+
+func (r *fakeMovements) SumByLocation(ctx context.Context, bookID uuid.UUID) (map[uuid.UUID]int, error) {
+	sums := make(map[uuid.UUID]int)
+	for _, movement := range r.movements {
+		if movement.BookID == bookID && movement.LocationID != nil {
+			sums[*movement.LocationID] += movement.Quantity
+		}
+	}
+	return sums, nil
+}
+
+type levelKey struct {
+	bookID, locationID uuid.UUID
+}
+
+// fakeLevels holds the stock of each book at each location
+type fakeLevels struct {
+	repository.IStockLevelRepository
+	quantities map[levelKey]int
+}
+
+func (r *fakeLevels) Adjust(ctx context.Context, bookID, locationID uuid.UUID, delta int) (int, error) {
+	key := levelKey{bookID, locationID}
+	if r.quantities[key]+delta < 0 {
+		return r.quantities[key], repository.ErrInsufficientStock
+	}
+	r.quantities[key] += delta
+	return r.quantities[key], nil
+}
+
+func (r *fakeLevels) Set(ctx context.Context, bookID, locationID uuid.UUID, quantity int) error {
+	r.quantities[levelKey{bookID, locationID}] = quantity
+	return nil
+}
+
+func (r *fakeLevels) FindByBookID(ctx context.Context, bookID uuid.UUID) ([]*model.StockLevel, error) {
+	var levels []*model.StockLevel
+	for key, quantity := range r.quantities {
+		if key.bookID == bookID {
+			levels = append(levels, &model.StockLevel{BookID: bookID, LocationID: key.locationID, Quantity: quantity})
+		}
+	}
+	return levels, nil
+}
+
+type fakeLocations struct {
+	repository.ILocationRepository
+	locations map[uuid.UUID]*model.Location
+}
+
+func (r *fakeLocations) FindByID(ctx context.Context, id uuid.UUID) (*model.Location, error) {
+	location, ok := r.locations[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return location, nil
+}
+
+func (r *fakeLocations) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Location, error) {
+	var locations []*model.Location
+	for _, id := range ids {
+		if location, ok := r.locations[id]; ok {
+			locations = append(locations, location)
+		}
+	}
+	return locations, nil
+}
+
+// fakeHolds counts the books whose incoming stock was offered to their holds
+type fakeHolds struct {
+	service.IHoldService
+	assigned []uuid.UUID
+}
+
+func (s *fakeHolds) AssignHolds(ctx context.Context, bookID uuid.UUID) (int, error) {
+	s.assigned = append(s.assigned, bookID)
+	return 0, nil
+}
+
+// fakeNotifications records who was notified of what
+type fakeNotifications struct {
+	service.INotificationService
+	sent map[uuid.UUID][]string
+}
+
+func (s *fakeNotifications) Notify(ctx context.Context, userID uuid.UUID, kind, message string, bookID *uuid.UUID) error {
+	s.sent[userID] = append(s.sent[userID], kind)
+	return nil
+}
+
+// inventory wires the service to empty fakes, an admin and a member; the
+// low-stock threshold is 2
+type inventory struct {
+	service.IInventoryService
+	books         *fakeBooks
+	movements     *fakeMovements
+	levels        *fakeLevels
+	locations     *fakeLocations
+	holds         *fakeHolds
+	notifications *fakeNotifications
+	users         *repotest.Users
+	admin, member uuid.UUID
+}
+
+func newInventory() *inventory {
+	i := &inventory{
+		movements:     &fakeMovements{},
+		levels:        &fakeLevels{quantities: make(map[levelKey]int)},
+		locations:     &fakeLocations{locations: make(map[uuid.UUID]*model.Location)},
+		holds:         &fakeHolds{},
+		notifications: &fakeNotifications{sent: make(map[uuid.UUID][]string)},
+		admin:         uuid.New(),
+		member:        uuid.New(),
+	}
+	i.books = &fakeBooks{Books: repotest.NewBooks(), levels: i.levels}
+	i.users = repotest.NewUsers(&model.User{ID: i.admin, Role: "admin"}, &model.User{ID: i.member, Role: "user"})
+	i.withHolds(i.holds)
+	return i
+}
+
+// withHolds rewires the service to offer incoming stock to holds
+func (i *inventory) withHolds(holds service.IHoldService) {
+	i.IInventoryService = NewInventoryService(i.books, i.movements, i.locations, i.levels, repotest.Transactor{},
+		holds, i.users, i.notifications, 2)
+}
+
+func TestAdjustStock(t *testing.T) {
+	tests := []struct {
+		name         string
+		stock        int
+		req          model.StockAdjustRequest
+		wantErr      error
+		wantStock    int
+		wantLowStock bool
+		wantAssigned bool
+		// wantAlerted is set when the adjustment crosses the threshold
+		wantAlerted bool
+	}{
+		{name: "receive", stock: 1, req: model.StockAdjustRequest{Type: model.StockMovementReceive, Quantity: 4},
+			wantStock: 5, wantAssigned: true},
+		{name: "sell", stock: 5, req: model.StockAdjustRequest{Type: model.StockMovementSell, Quantity: 2}, wantStock: 3},
+		{name: "sell down to the threshold", stock: 5, req: model.StockAdjustRequest{Type: model.StockMovementSell, Quantity: 3},
+			wantStock: 2, wantLowStock: true, wantAlerted: true},
+		{name: "sell while already low", stock: 2, req: model.StockAdjustRequest{Type: model.StockMovementSell, Quantity: 1},
+			wantStock: 1, wantLowStock: true},
+		{name: "sell more than is left", stock: 1, req: model.StockAdjustRequest{Type: model.StockMovementSell, Quantity: 2},
+			wantErr: service.ErrInsufficientStock, wantStock: 1},
+		{name: "negative adjustment", stock: 4, req: model.StockAdjustRequest{Type: model.StockMovementAdjust, Quantity: -1}, wantStock: 3},
+		{name: "return", stock: 0, req: model.StockAdjustRequest{Type: model.StockMovementReturn, Quantity: 1},
+			wantStock: 1, wantLowStock: true, wantAssigned: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := newInventory()
+			bookID := uuid.New()
+			i.books.setStock(bookID, tt.stock)
+
+			result, err := i.AdjustStock(context.Background(), bookID.String(), &tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AdjustStock() error = %v, want %v", err, tt.wantErr)
+			}
+			if i.books.stock(bookID) != tt.wantStock {
+				t.Errorf("stock = %d, want %d", i.books.stock(bookID), tt.wantStock)
+			}
+			if err != nil {
+				if len(i.movements.movements) > 0 {
+					t.Errorf("a refused adjustment was recorded")
+				}
+				return
+			}
+
+			if result.Stock != tt.wantStock || result.LowStock != tt.wantLowStock {
+				t.Errorf("result = stock %d, low %v; want %d, %v", result.Stock, result.LowStock, tt.wantStock, tt.wantLowStock)
+			}
+			if len(i.movements.movements) != 1 {
+				t.Fatalf("recorded %d movements, want 1", len(i.movements.movements))
+			}
+			movement := i.movements.movements[0]
+			if movement.Quantity != tt.wantStock-tt.stock || movement.StockAfter != tt.wantStock || movement.Type != tt.req.Type {
+				t.Errorf("movement = %+v", movement)
+			}
+			if assigned := len(i.holds.assigned) == 1; assigned != tt.wantAssigned {
+				t.Errorf("offered to holds = %v, want %v", assigned, tt.wantAssigned)
+			}
+			if alerted := len(i.notifications.sent[i.admin]) == 1; alerted != tt.wantAlerted {
+				t.Errorf("admin alerted = %v, want %v", alerted, tt.wantAlerted)
+			}
+			if len(i.notifications.sent[i.member]) > 0 {
+				t.Errorf("a member was alerted of low stock")
+			}
+		})
+	}
+
+	if _, err := newInventory().AdjustStock(context.Background(), uuid.NewString(),
+		&model.StockAdjustRequest{Type: model.StockMovementReceive, Quantity: 1}); !errors.Is(err, service.ErrBookNotFound) {
+		t.Errorf("AdjustStock() of a missing book error = %v, want ErrBookNotFound", err)
+	}
+}
+
+func TestReconcileStock(t *testing.T) {
+	tests := []struct {
+		name          string
+		stock         int
+		ledger        []int
+		wantStock     int
+		wantCorrected bool
+		wantOpening   bool
+	}{
+		{name: "matching ledger", stock: 3, ledger: []int{5, -2}, wantStock: 3},
+		{name: "stock drifted from the ledger", stock: 7, ledger: []int{5, -2}, wantStock: 3, wantCorrected: true},
+		{name: "stock from before the ledger", stock: 4, wantStock: 4, wantOpening: true},
+		{name: "no stock and no ledger", wantStock: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := newInventory()
+			bookID := uuid.New()
+			i.books.setStock(bookID, tt.stock)
+			for _, quantity := range tt.ledger {
+				i.movements.movements = append(i.movements.movements, &model.StockMovement{BookID: bookID, Quantity: quantity})
+			}
+
+			result, err := i.ReconcileStock(context.Background(), bookID.String())
+			if err != nil {
+				t.Fatalf("ReconcileStock() error = %v", err)
+			}
+			if result.Corrected != tt.wantCorrected || result.Opening != tt.wantOpening {
+				t.Errorf("corrected = %v, opening = %v; want %v, %v", result.Corrected, result.Opening, tt.wantCorrected, tt.wantOpening)
+			}
+			if i.books.stock(bookID) != tt.wantStock {
+				t.Errorf("stock = %d, want %d", i.books.stock(bookID), tt.wantStock)
+			}
+			// An opening balance makes the ledger add up to the stock
+			if sum, _, _ := i.movements.SumByBookID(context.Background(), bookID); sum != tt.wantStock {
+				t.Errorf("ledger adds up to %d, want %d", sum, tt.wantStock)
+			}
+		})
+	}
+}
+
+// newLocation stores a location with a stock level of quantity for bookID
+func (i *inventory) newLocation(code string, bookID uuid.UUID, quantity int) uuid.UUID {
+	location := &model.Location{ID: uuid.New(), Code: code, Name: code}
+	i.locations.locations[location.ID] = location
+	i.levels.quantities[levelKey{bookID, location.ID}] = quantity
+	return location.ID
+}
+
+func TestAdjustStockAtLocation(t *testing.T) {
+	tests := []struct {
+		name string
+		// at adjusts at the store rather than the unallocated stock
+		at          bool
+		req         model.StockAdjustRequest
+		wantErr     error
+		wantStock   int
+		wantAtStore int
+	}{
+		{name: "sell at a location", at: true, req: model.StockAdjustRequest{Type: model.StockMovementSell, Quantity: 2},
+			wantStock: 3, wantAtStore: 1},
+		{name: "sell more than the location has", at: true, req: model.StockAdjustRequest{Type: model.StockMovementSell, Quantity: 4},
+			wantErr: service.ErrInsufficientStock, wantStock: 5, wantAtStore: 3},
+		{name: "unallocated sale leaves located stock alone", req: model.StockAdjustRequest{Type: model.StockMovementSell, Quantity: 2},
+			wantStock: 3, wantAtStore: 3},
+		{name: "unallocated sale cannot use located stock", req: model.StockAdjustRequest{Type: model.StockMovementSell, Quantity: 3},
+			wantErr: service.ErrInsufficientStock, wantStock: 5, wantAtStore: 3},
+		{name: "receive at a location", at: true, req: model.StockAdjustRequest{Type: model.StockMovementReceive, Quantity: 2},
+			wantStock: 7, wantAtStore: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := newInventory()
+			bookID := uuid.New()
+			i.books.setStock(bookID, 5)
+			store := i.newLocation("STORE", bookID, 3)
+			if tt.at {
+				tt.req.LocationID = &store
+			}
+
+			result, err := i.AdjustStock(context.Background(), bookID.String(), &tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AdjustStock() error = %v, want %v", err, tt.wantErr)
+			}
+			if i.books.stock(bookID) != tt.wantStock || i.levels.quantities[levelKey{bookID, store}] != tt.wantAtStore {
+				t.Errorf("stock = %d with %d at the store, want %d with %d",
+					i.books.stock(bookID), i.levels.quantities[levelKey{bookID, store}], tt.wantStock, tt.wantAtStore)
+			}
+			if err == nil && tt.at && (result.LocationStock == nil || *result.LocationStock != tt.wantAtStore) {
+				t.Errorf("location stock = %v, want %d", result.LocationStock, tt.wantAtStore)
+			}
+		})
+	}
+
+	i := newInventory()
+	bookID, closed := uuid.New(), uuid.New()
+	i.books.setStock(bookID, 5)
+	_, err := i.AdjustStock(context.Background(), bookID.String(),
+		&model.StockAdjustRequest{Type: model.StockMovementReceive, Quantity: 1, LocationID: &closed})
+	if !errors.Is(err, service.ErrLocationNotFound) {
+		t.Errorf("AdjustStock() at an unknown location error = %v, want ErrLocationNotFound", err)
+	}
+}
+
+func TestTransferStock(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity int
+		wantErr  error
+		wantFrom int
+		wantTo   int
+		wantLegs int
+	}{
+		{name: "transfer", quantity: 2, wantFrom: 1, wantTo: 3, wantLegs: 2},
+		{name: "transfer everything", quantity: 3, wantFrom: 0, wantTo: 4, wantLegs: 2},
+		{name: "more than the source has", quantity: 4, wantErr: service.ErrInsufficientStock, wantFrom: 3, wantTo: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := newInventory()
+			bookID := uuid.New()
+			i.books.setStock(bookID, 6)
+			warehouse := i.newLocation("WH", bookID, 3)
+			store := i.newLocation("STORE", bookID, 1)
+
+			result, err := i.TransferStock(context.Background(), bookID.String(), &model.StockTransferRequest{
+				FromLocationID: warehouse,
+				ToLocationID:   store,
+				Quantity:       tt.quantity,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TransferStock() error = %v, want %v", err, tt.wantErr)
+			}
+			if from, to := i.levels.quantities[levelKey{bookID, warehouse}], i.levels.quantities[levelKey{bookID, store}]; from != tt.wantFrom || to != tt.wantTo {
+				t.Errorf("levels = %d and %d, want %d and %d", from, to, tt.wantFrom, tt.wantTo)
+			}
+			// Moving copies between locations leaves the book's stock as it was
+			if i.books.stock(bookID) != 6 {
+				t.Errorf("stock = %d, want 6", i.books.stock(bookID))
+			}
+			if len(i.movements.movements) != tt.wantLegs {
+				t.Fatalf("recorded %d movements, want %d", len(i.movements.movements), tt.wantLegs)
+			}
+			if err != nil {
+				return
+			}
+			out, in := i.movements.movements[0], i.movements.movements[1]
+			if out.Quantity != -tt.quantity || in.Quantity != tt.quantity || *out.TransferID != *in.TransferID || *out.TransferID != result.TransferID {
+				t.Errorf("legs = %+v and %+v", out, in)
+			}
+		})
+	}
+}
+
+func TestGetBookStock(t *testing.T) {
+	i := newInventory()
+	bookID := uuid.New()
+	i.books.setStock(bookID, 10)
+	i.newLocation("WH", bookID, 4)
+	i.newLocation("STORE", bookID, 0)
+
+	stock, err := i.GetBookStock(context.Background(), bookID.String())
+	if err != nil {
+		t.Fatalf("GetBookStock() error = %v", err)
+	}
+	if stock.Stock != 10 || stock.Unallocated != 6 || len(stock.Locations) != 2 {
+		t.Errorf("stock = %d with %d unallocated at %d locations, want 10 with 6 at 2", stock.Stock, stock.Unallocated, len(stock.Locations))
+	}
+}
+
+func TestReconcileLocations(t *testing.T) {
+	i := newInventory()
+	bookID := uuid.New()
+	i.books.setStock(bookID, 5)
+	warehouse := i.newLocation("WH", bookID, 4)
+	store := i.newLocation("STORE", bookID, 1)
+	// Two copies unallocated, two at the warehouse and one at the store
+	i.movements.movements = []*model.StockMovement{
+		{BookID: bookID, Quantity: 2},
+		{BookID: bookID, LocationID: &warehouse, Quantity: 2},
+		{BookID: bookID, LocationID: &store, Quantity: 1},
+	}
+
+	result, err := i.ReconcileStock(context.Background(), bookID.String())
+	if err != nil {
+		t.Fatalf("ReconcileStock() error = %v", err)
+	}
+	if result.Corrected || len(result.Locations) != 1 || result.Locations[0].LocationID != warehouse {
+		t.Fatalf("reconciliation = %+v, want only the warehouse corrected", result)
+	}
+	if got := i.levels.quantities[levelKey{bookID, warehouse}]; got != 2 {
+		t.Errorf("warehouse level = %d, want 2", got)
+	}
+}
+
+// TestStockInvariant runs a mix of adjustments, some refused, and transfers,
+// checking after each that the book's cached stock still equals the sum of
+// its ledger and covers the stock held at its locations
+func TestStockInvariant(t *testing.T) {
+	i := newInventory()
+	bookID := uuid.New()
+	i.books.setStock(bookID, 0)
+	warehouse := i.newLocation("WH", bookID, 0)
+	store := i.newLocation("STORE", bookID, 0)
+
+	steps := []func() error{
+		func() error {
+			_, err := i.AdjustStock(context.Background(), bookID.String(),
+				&model.StockAdjustRequest{Type: model.StockMovementReceive, Quantity: 10, LocationID: &warehouse})
+			return err
+		},
+		func() error {
+			_, err := i.AdjustStock(context.Background(), bookID.String(),
+				&model.StockAdjustRequest{Type: model.StockMovementReceive, Quantity: 3})
+			return err
+		},
+		func() error {
+			_, err := i.TransferStock(context.Background(), bookID.String(),
+				&model.StockTransferRequest{FromLocationID: warehouse, ToLocationID: store, Quantity: 4})
+			return err
+		},
+		func() error {
+			_, err := i.AdjustStock(context.Background(), bookID.String(),
+				&model.StockAdjustRequest{Type: model.StockMovementSell, Quantity: 5, LocationID: &store})
+			return err
+		},
+		func() error {
+			_, err := i.AdjustStock(context.Background(), bookID.String(),
+				&model.StockAdjustRequest{Type: model.StockMovementSell, Quantity: 4})
+			return err
+		},
+		func() error {
+			_, err := i.AdjustStock(context.Background(), bookID.String(),
+				&model.StockAdjustRequest{Type: model.StockMovementSell, Quantity: 2, LocationID: &store})
+			return err
+		},
+		func() error {
+			_, err := i.TransferStock(context.Background(), bookID.String(),
+				&model.StockTransferRequest{FromLocationID: store, ToLocationID: warehouse, Quantity: 2})
+			return err
+		},
+		func() error {
+			_, err := i.AdjustStock(context.Background(), bookID.String(),
+				&model.StockAdjustRequest{Type: model.StockMovementSell, Quantity: 3})
+			return err
+		},
+	}
+
+	for step, run := range steps {
+		if err := run(); err != nil && !errors.Is(err, service.ErrInsufficientStock) {
+			t.Fatalf("step %d: error = %v", step, err)
+		}
+
+		ledger, _, _ := i.movements.SumByBookID(context.Background(), bookID)
+		stock, err := i.GetBookStock(context.Background(), bookID.String())
+		if err != nil {
+			t.Fatalf("step %d: GetBookStock() error = %v", step, err)
+		}
+		if stock.Stock != ledger || stock.Unallocated < 0 {
+			t.Fatalf("step %d: stock = %d with %d unallocated, ledger = %d", step, stock.Stock, stock.Unallocated, ledger)
+		}
+		byLocation, _ := i.movements.SumByLocation(context.Background(), bookID)
+		for _, location := range stock.Locations {
+			if location.Quantity != byLocation[location.Location.ID] {
+				t.Fatalf("step %d: %s holds %d, ledger = %d", step, location.Location.Code, location.Quantity, byLocation[location.Location.ID])
+			}
+		}
+	}
+}
+
+// queuedHolds is a hold queue in the order the holds were placed
+type queuedHolds struct {
+	repository.IHoldRepository
+	holds []*model.Hold
+}
+
+func (r *queuedHolds) CountReady(ctx context.Context, bookID, excludeUserID uuid.UUID) (int, error) {
+	count := 0
+	for _, hold := range r.holds {
+		if hold.BookID == bookID && hold.Status == model.HoldStatusReady && hold.UserID != excludeUserID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *queuedHolds) FindNextWaiting(ctx context.Context, bookID uuid.UUID, limit int) ([]*model.Hold, error) {
+	var holds []*model.Hold
+	for _, hold := range r.holds {
+		if hold.BookID == bookID && hold.Status == model.HoldStatusWaiting && len(holds) < limit {
+			copied := *hold
+			holds = append(holds, &copied)
+		}
+	}
+	return holds, nil
+}
+
+func (r *queuedHolds) Update(ctx context.Context, hold *model.Hold) error {
+	for i := range r.holds {
+		if r.holds[i].ID == hold.ID {
+			copied := *hold
+			r.holds[i] = &copied
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+// TestAdjustStockAssignsHolds checks that stock brought in by AdjustStock,
+// the only place holds are assigned from, goes to the head of the queue
+func TestAdjustStockAssignsHolds(t *testing.T) {
+	i := newInventory()
+	bookID := uuid.New()
+	i.books.setStock(bookID, 0)
+	first, second := uuid.New(), uuid.New()
+	queue := &queuedHolds{holds: []*model.Hold{
+		{ID: uuid.New(), BookID: bookID, UserID: first, Status: model.HoldStatusWaiting},
+		{ID: uuid.New(), BookID: bookID, UserID: second, Status: model.HoldStatusWaiting},
+	}}
+	i.withHolds(hold_service.NewHoldService(queue, i.books, i.notifications, repotest.Transactor{}, time.Hour))
+
+	if _, err := i.AdjustStock(context.Background(), bookID.String(),
+		&model.StockAdjustRequest{Type: model.StockMovementReceive, Quantity: 1}); err != nil {
+		t.Fatalf("AdjustStock() error = %v", err)
+	}
+	if head, next := queue.holds[0], queue.holds[1]; head.Status != model.HoldStatusReady || next.Status != model.HoldStatusWaiting {
+		t.Errorf("holds are %s and %s, want the head of the queue ready", head.Status, next.Status)
+	}
+	if sent := i.notifications.sent[first]; len(sent) != 1 || sent[0] != model.NotificationHoldReady {
+		t.Errorf("head of the queue was sent %v, want a hold_ready notification", sent)
+	}
+
+	// Stock going out leaves the queue alone
+	if _, err := i.AdjustStock(context.Background(), bookID.String(),
+		&model.StockAdjustRequest{Type: model.StockMovementAdjust, Quantity: -1}); err != nil {
+		t.Fatalf("AdjustStock() error = %v", err)
+	}
+	if queue.holds[1].Status != model.HoldStatusWaiting {
+		t.Errorf("a negative adjustment assigned a hold")
+	}
+}
@@ -0,0 +1,276 @@
+package location_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type locationService struct {
+	repo         repository.ILocationRepository
+	levelRepo    repository.IStockLevelRepository
+	bookRepo     repository.IBookRepository
+	movementRepo repository.IStockMovementRepository
+	transactor   repository.ITransactor
+}
+
+// NewLocationService creates a new location service
+func NewLocationService(
+	repo repository.ILocationRepository,
+	levelRepo repository.IStockLevelRepository,
+	bookRepo repository.IBookRepository,
+	movementRepo repository.IStockMovementRepository,
+	transactor repository.ITransactor,
+) service.ILocationService {
+	return &locationService{
+		repo:         repo,
+		levelRepo:    levelRepo,
+		bookRepo:     bookRepo,
+		movementRepo: movementRepo,
+		transactor:   transactor,
+	}
+}
+
+// CreateLocation creates a new location. Codes are stored upper-case and
+// must be unique.
+func (s *locationService) CreateLocation(ctx context.Context, req *model.LocationRequest) (*model.LocationResponse, error) {
+	now := time.Now()
+	location := &model.Location{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.apply(ctx, location, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, location); err != nil {
+		return nil, fmt.Errorf("failed to create location: %v", err)
+	}
+
+	return location.ToDTO(), nil
+}
+
+// GetLocation gets a location by ID
+func (s *locationService) GetLocation(ctx context.Context, id string) (*model.LocationResponse, error) {
+	location, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return location.ToDTO(), nil
+}
+
+// ListLocations gets a paginated list of locations
+func (s *locationService) ListLocations(ctx context.Context, page, pageSize int) (*model.LocationListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	locations, total, err := s.repo.FindAll(ctx, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list locations: %v", err)
+	}
+
+	locationDTOs := make([]*model.LocationResponse, len(locations))
+	for i, location := range locations {
+		locationDTOs[i] = location.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.LocationListResponse{
+		Data: locationDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// UpdateLocation replaces the editable fields of a location
+func (s *locationService) UpdateLocation(ctx context.Context, id string, req *model.LocationRequest) (*model.LocationResponse, error) {
+	location, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(ctx, location, req); err != nil {
+		return nil, err
+	}
+	location.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, location); err != nil {
+		return nil, fmt.Errorf("failed to update location: %v", err)
+	}
+
+	return location.ToDTO(), nil
+}
+
+// DeleteLocation deletes a location that has no stock left. Its ledger
+// entries are kept as history.
+func (s *locationService) DeleteLocation(ctx context.Context, id string) error {
+	location, err := s.find(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		inStock, err := s.levelRepo.ExistsInStockAtLocation(ctx, location.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check location stock: %v", err)
+		}
+		if inStock {
+			return service.ErrLocationHasStock
+		}
+
+		if err := s.levelRepo.DeleteByLocationID(ctx, location.ID); err != nil {
+			return fmt.Errorf("failed to delete location stock levels: %v", err)
+		}
+		if err := s.repo.Delete(ctx, location.ID); err != nil {
+			return fmt.Errorf("failed to delete location: %v", err)
+		}
+		return nil
+	})
+}
+
+// ListStock gets a paginated list of the books in stock at a location
+func (s *locationService) ListStock(ctx context.Context, id string, page, pageSize int) (*model.LocationStockListResponse, error) {
+	location, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	levels, total, err := s.levelRepo.FindInStockByLocationID(ctx, location.ID, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list location stock: %v", err)
+	}
+
+	bookIDs := make([]uuid.UUID, len(levels))
+	for i, level := range levels {
+		bookIDs[i] = level.BookID
+	}
+	books, err := s.bookRepo.FindByIDs(ctx, bookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find books: %v", err)
+	}
+	byID := make(map[uuid.UUID]*model.Book, len(books))
+	for _, book := range books {
+		byID[book.ID] = book
+	}
+
+	stockDTOs := make([]*model.LocationBookStock, 0, len(levels))
+	for _, level := range levels {
+		// Books in the trash keep their levels but are not listed
+		book, ok := byID[level.BookID]
+		if !ok {
+			continue
+		}
+		stockDTOs = append(stockDTOs, &model.LocationBookStock{
+			Book:     book.ToDTO(),
+			Quantity: level.Quantity,
+		})
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.LocationStockListResponse{
+		Data: stockDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// ListMovements gets a paginated ledger of a location, newest first
+func (s *locationService) ListMovements(ctx context.Context, id string, page, pageSize int) (*model.StockMovementListResponse, error) {
+	location, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	movements, total, err := s.movementRepo.FindByLocationID(ctx, location.ID, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock movements: %v", err)
+	}
+
+	movementDTOs := make([]*model.StockMovementResponse, len(movements))
+	for i, movement := range movements {
+		movementDTOs[i] = movement.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.StockMovementListResponse{
+		Data: movementDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// apply copies a request onto a location after checking its code is free
+func (s *locationService) apply(ctx context.Context, location *model.Location, req *model.LocationRequest) error {
+	code := strings.ToUpper(req.Code)
+	taken, err := s.repo.ExistsByCode(ctx, code, location.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check location code: %v", err)
+	}
+	if taken {
+		return fmt.Errorf("%w: %s", service.ErrLocationCodeTaken, code)
+	}
+
+	location.Code = code
+	location.Name = req.Name
+	location.Type = req.Type
+	location.Address = req.Address
+	return nil
+}
+
+// find gets a location by its ID in string form
+func (s *locationService) find(ctx context.Context, id string) (*model.Location, error) {
+	locationID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidLocationID, err)
+	}
+
+	location, err := s.repo.FindByID(ctx, locationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrLocationNotFound
+		}
+		return nil, fmt.Errorf("failed to find location: %v", err)
+	}
+	return location, nil
+}
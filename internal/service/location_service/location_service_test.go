@@ -0,0 +1,234 @@
+package location_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	inventory_service "book_system/internal/service/inventory_service"
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type fakeLocations struct {
+	repository.ILocationRepository
+	locations map[uuid.UUID]*model.Location
+}
+
+func (r *fakeLocations) Create(ctx context.Context, location *model.Location) error {
+	r.locations[location.ID] = location
+	return nil
+}
+
+func (r *fakeLocations) FindByID(ctx context.Context, id uuid.UUID) (*model.Location, error) {
+	location, ok := r.locations[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return location, nil
+}
+
+func (r *fakeLocations) FindByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Location, error) {
+	var locations []*model.Location
+	for _, id := range ids {
+		if location, ok := r.locations[id]; ok {
+			locations = append(locations, location)
+		}
+	}
+	return locations, nil
+}
+
+func (r *fakeLocations) ExistsByCode(ctx context.Context, code string, excludeID uuid.UUID) (bool, error) {
+	for _, location := range r.locations {
+		if location.Code == code && location.ID != excludeID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeLocations) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(r.locations, id)
+	return nil
+}
+
+type levelKey struct {
+	bookID, locationID uuid.UUID
+}
+
+// fakeLevels holds the stock of each book at each location. Like the
+// repository, it lists a book's levels largest first.
+type fakeLevels struct {
+	repository.IStockLevelRepository
+	quantities map[levelKey]int
+}
+
+func (r *fakeLevels) FindByBookID(ctx context.Context, bookID uuid.UUID) ([]*model.StockLevel, error) {
+	var levels []*model.StockLevel
+	for key, quantity := range r.quantities {
+		if key.bookID == bookID {
+			levels = append(levels, &model.StockLevel{BookID: bookID, LocationID: key.locationID, Quantity: quantity})
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Quantity > levels[j].Quantity })
+	return levels, nil
+}
+
+func (r *fakeLevels) FindInStockByLocationID(ctx context.Context, locationID uuid.UUID, page, pageSize int) ([]*model.StockLevel, int64, error) {
+	var levels []*model.StockLevel
+	for key, quantity := range r.quantities {
+		if key.locationID == locationID && quantity > 0 {
+			levels = append(levels, &model.StockLevel{BookID: key.bookID, LocationID: locationID, Quantity: quantity})
+		}
+	}
+	return levels, int64(len(levels)), nil
+}
+
+func (r *fakeLevels) ExistsInStockAtLocation(ctx context.Context, locationID uuid.UUID) (bool, error) {
+	for key, quantity := range r.quantities {
+		if key.locationID == locationID && quantity > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeLevels) DeleteByLocationID(ctx context.Context, locationID uuid.UUID) error {
+	for key := range r.quantities {
+		if key.locationID == locationID {
+			delete(r.quantities, key)
+		}
+	}
+	return nil
+}
+
+// stores wires the service to a book with 10 copies, 4 of them at the
+// warehouse, 5 at the store and none at the kiosk
+type stores struct {
+	service.ILocationService
+	books                   *repotest.Books
+	locations               *fakeLocations
+	levels                  *fakeLevels
+	book                    *model.Book
+	warehouse, store, kiosk uuid.UUID
+}
+
+func newStores() *stores {
+	s := &stores{
+		book:      &model.Book{ID: uuid.New(), Title: "Dune", ISBN: "9780306406157", Stock: 10, Version: 1},
+		locations: &fakeLocations{locations: make(map[uuid.UUID]*model.Location)},
+		levels:    &fakeLevels{quantities: make(map[levelKey]int)},
+	}
+	s.books = repotest.NewBooks(s.book)
+	s.ILocationService = NewLocationService(s.locations, s.levels, s.books, nil, repotest.Transactor{})
+	s.warehouse = s.newLocation("WH", model.LocationTypeWarehouse, 4)
+	s.store = s.newLocation("STORE", model.LocationTypeStore, 5)
+	s.kiosk = s.newLocation("KIOSK", model.LocationTypeStore, 0)
+	return s
+}
+
+// newLocation stores a location with a stock level of quantity for the book
+func (s *stores) newLocation(code, kind string, quantity int) uuid.UUID {
+	location := &model.Location{ID: uuid.New(), Code: code, Name: code, Type: kind}
+	s.locations.locations[location.ID] = location
+	s.levels.quantities[levelKey{s.book.ID, location.ID}] = quantity
+	return location.ID
+}
+
+func TestCreateLocation(t *testing.T) {
+	s := newStores()
+
+	location, err := s.CreateLocation(context.Background(), &model.LocationRequest{Code: "annex", Name: "Annex", Type: model.LocationTypeStore})
+	if err != nil {
+		t.Fatalf("CreateLocation() error = %v", err)
+	}
+	if location.Code != "ANNEX" {
+		t.Errorf("code = %q, want it upper-cased", location.Code)
+	}
+
+	_, err = s.CreateLocation(context.Background(), &model.LocationRequest{Code: "wh", Name: "Other", Type: model.LocationTypeWarehouse})
+	if !errors.Is(err, service.ErrLocationCodeTaken) {
+		t.Errorf("CreateLocation() error = %v, want %v", err, service.ErrLocationCodeTaken)
+	}
+}
+
+func TestDeleteLocation(t *testing.T) {
+	tests := []struct {
+		name string
+		// id picks the location to delete
+		id          func(s *stores) string
+		wantErr     error
+		wantDeleted bool
+	}{
+		{name: "empty location", id: func(s *stores) string { return s.kiosk.String() }, wantDeleted: true},
+		{name: "location still holding stock", id: func(s *stores) string { return s.warehouse.String() },
+			wantErr: service.ErrLocationHasStock},
+		{name: "unknown location", id: func(s *stores) string { return uuid.NewString() }, wantErr: service.ErrLocationNotFound},
+		{name: "invalid ID", id: func(s *stores) string { return "wh" }, wantErr: service.ErrInvalidLocationID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStores()
+
+			err := s.DeleteLocation(context.Background(), tt.id(s))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DeleteLocation() error = %v, want %v", err, tt.wantErr)
+			}
+
+			_, kept := s.locations.locations[s.kiosk]
+			if kept == tt.wantDeleted {
+				t.Errorf("kiosk kept = %v, want %v", kept, !tt.wantDeleted)
+			}
+			_, levelKept := s.levels.quantities[levelKey{s.book.ID, s.kiosk}]
+			if levelKept == tt.wantDeleted {
+				t.Errorf("kiosk stock level kept = %v, want %v", levelKept, !tt.wantDeleted)
+			}
+			if _, ok := s.locations.locations[s.warehouse]; !ok || s.levels.quantities[levelKey{s.book.ID, s.warehouse}] != 4 {
+				t.Errorf("warehouse or its stock removed")
+			}
+		})
+	}
+}
+
+func TestAvailabilityAcrossLocations(t *testing.T) {
+	s := newStores()
+	inventory := inventory_service.NewInventoryService(s.books, nil, s.locations, s.levels, repotest.Transactor{},
+		nil, nil, nil, 0)
+
+	availability, err := inventory.GetAvailability(context.Background(), s.book.ISBN)
+	if err != nil {
+		t.Fatalf("GetAvailability() error = %v", err)
+	}
+	// The kiosk is out of stock, the store holds the most
+	if len(availability.Locations) != 2 ||
+		availability.Locations[0].Location.Code != "STORE" || availability.Locations[0].Quantity != 5 ||
+		availability.Locations[1].Location.Code != "WH" || availability.Locations[1].Quantity != 4 {
+		t.Fatalf("availability = %+v", availability.Locations)
+	}
+
+	stock, err := inventory.GetBookStock(context.Background(), s.book.ID.String())
+	if err != nil {
+		t.Fatalf("GetBookStock() error = %v", err)
+	}
+	sum := 0
+	for _, location := range stock.Locations {
+		sum += location.Quantity
+	}
+	if sum != 9 || stock.Unallocated != 1 || sum+stock.Unallocated != s.book.Stock {
+		t.Errorf("locations hold %d and %d is unallocated, want 9 and 1 adding up to %d", sum, stock.Unallocated, s.book.Stock)
+	}
+
+	listed, err := s.ListStock(context.Background(), s.warehouse.String(), 1, 10)
+	if err != nil {
+		t.Fatalf("ListStock() error = %v", err)
+	}
+	if len(listed.Data) != 1 || listed.Data[0].Book.ID != s.book.ID || listed.Data[0].Quantity != 4 {
+		t.Errorf("warehouse stock = %+v", listed.Data)
+	}
+}
@@ -0,0 +1,182 @@
+package lookup_service
+
+import (
+	isbnlib "book_system/internal/baselib/isbn"
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// cacheKeyPrefix namespaces lookups in Redis
+	cacheKeyPrefix = "book:lookup:"
+	// notFoundMarker is cached for ISBNs the provider does not know
+	notFoundMarker = "-"
+	// notFoundTTL bounds how long a miss is remembered, so new titles show up soon
+	notFoundTTL = time.Hour
+	// maxCoverSize bounds downloaded cover images
+	maxCoverSize = 5 << 20 // 5 MB
+)
+
+// coverExtensions are the accepted cover image types
+var coverExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+	"image/gif":  ".gif",
+}
+
+type bookLookupService struct {
+	provider      service.IBookMetadataProvider
+	uploadService service.IUploadService
+	cache         *redis.Client
+	cacheTTL      time.Duration
+	client        *http.Client
+}
+
+// NewBookLookupService creates a new book lookup service. Lookups are
+// cached in Redis for cacheTTL; cache may be nil to disable caching.
+func NewBookLookupService(
+	provider service.IBookMetadataProvider,
+	uploadService service.IUploadService,
+	cache *redis.Client,
+	cacheTTL time.Duration,
+	timeout time.Duration,
+) service.IBookLookupService {
+	return &bookLookupService{
+		provider:      provider,
+		uploadService: uploadService,
+		cache:         cache,
+		cacheTTL:      cacheTTL,
+		client:        &http.Client{Timeout: timeout},
+	}
+}
+
+// LookupISBN fetches the metadata of an ISBN in any form. The cover is
+// copied into storage, and the metadata points at the copy. Metadata whose
+// cover could not be copied is returned without one and not cached, so the
+// next lookup tries again.
+func (s *bookLookupService) LookupISBN(ctx context.Context, isbn string) (*model.BookMetadata, error) {
+	isbn13, err := isbnlib.Normalize(isbn)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", service.ErrInvalidISBN, isbn)
+	}
+
+	if metadata, err := s.cached(ctx, isbn13); metadata != nil || err != nil {
+		return metadata, err
+	}
+
+	metadata, err := s.provider.Lookup(ctx, isbn13)
+	if err != nil {
+		if errors.Is(err, service.ErrBookMetadataNotFound) {
+			s.store(ctx, isbn13, notFoundMarker, min(notFoundTTL, s.cacheTTL))
+		}
+		return nil, err
+	}
+
+	if metadata.CoverURL != "" {
+		coverURL, err := s.storeCover(ctx, isbn13, metadata.CoverURL)
+		if err != nil {
+			slog.Warn("Failed to store looked up cover", slog.String("isbn", isbn13), slog.Any("error", err))
+			metadata.CoverURL = ""
+			return metadata, nil
+		}
+		metadata.CoverURL = coverURL
+	}
+
+	if data, err := json.Marshal(metadata); err == nil {
+		s.store(ctx, isbn13, string(data), s.cacheTTL)
+	}
+
+	return metadata, nil
+}
+
+// Enrich fills the empty fields of a create request from the metadata of its ISBN
+func (s *bookLookupService) Enrich(ctx context.Context, req *model.CreateBookRequest) error {
+	metadata, err := s.LookupISBN(ctx, req.ISBN)
+	if err != nil {
+		return err
+	}
+	metadata.Fill(req)
+	return nil
+}
+
+// cached returns a cached lookup: metadata, a not-found error, or neither on a miss
+func (s *bookLookupService) cached(ctx context.Context, isbn13 string) (*model.BookMetadata, error) {
+	if s.cache == nil {
+		return nil, nil
+	}
+
+	value, err := s.cache.Get(ctx, cacheKeyPrefix+isbn13).Result()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			slog.Warn("Failed to read lookup cache", slog.String("isbn", isbn13), slog.Any("error", err))
+		}
+		return nil, nil
+	}
+	if value == notFoundMarker {
+		return nil, service.ErrBookMetadataNotFound
+	}
+
+	var metadata model.BookMetadata
+	if err := json.Unmarshal([]byte(value), &metadata); err != nil {
+		return nil, nil
+	}
+	return &metadata, nil
+}
+
+func (s *bookLookupService) store(ctx context.Context, isbn13, value string, ttl time.Duration) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.Set(ctx, cacheKeyPrefix+isbn13, value, ttl).Err(); err != nil {
+		slog.Warn("Failed to write lookup cache", slog.String("isbn", isbn13), slog.Any("error", err))
+	}
+}
+
+// storeCover downloads a cover image into storage and returns its URL there
+func (s *bookLookupService) storeCover(ctx context.Context, isbn13, coverURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, coverURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("cover download returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCoverSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxCoverSize {
+		return "", fmt.Errorf("cover is larger than %d bytes", maxCoverSize)
+	}
+
+	// Trust the content over the header, which CDNs often get wrong
+	contentType := http.DetectContentType(data)
+	extension, ok := coverExtensions[contentType]
+	if !ok {
+		return "", fmt.Errorf("cover has unsupported type %s", contentType)
+	}
+
+	objectName := "covers/" + isbn13 + extension
+	if err := s.uploadService.PutObject(ctx, objectName, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return "", err
+	}
+	return s.uploadService.ObjectURL(objectName), nil
+}
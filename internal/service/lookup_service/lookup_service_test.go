@@ -0,0 +1,227 @@
+package lookup_service
+
+import (
+	"book_system/internal/service"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeRedis serves GET and SET over the Redis protocol from a map, enough
+// for the lookup cache
+type fakeRedis struct {
+	listener net.Listener
+	mu       sync.Mutex
+	values   map[string]string
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	r := &fakeRedis{listener: listener, values: make(map[string]string)}
+	go r.serve()
+	t.Cleanup(func() { listener.Close() })
+	return r
+}
+
+func (r *fakeRedis) client(t *testing.T) *redis.Client {
+	client := redis.NewClient(&redis.Options{
+		Addr:            r.listener.Addr().String(),
+		Protocol:        2,
+		DisableIdentity: true,
+	})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func (r *fakeRedis) get(key string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	value, ok := r.values[key]
+	return value, ok
+}
+
+func (r *fakeRedis) serve() {
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			return
+		}
+		go r.handle(conn)
+	}
+}
+
+func (r *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		r.mu.Lock()
+		switch strings.ToUpper(args[0]) {
+		case "GET":
+			if value, ok := r.values[args[1]]; ok {
+				fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(value), value)
+			} else {
+				io.WriteString(conn, "$-1\r\n")
+			}
+		case "SET":
+			r.values[args[1]] = args[2]
+			io.WriteString(conn, "+OK\r\n")
+		default:
+			io.WriteString(conn, "-ERR unknown command\r\n")
+		}
+		r.mu.Unlock()
+	}
+}
+
+// readCommand reads a command sent as an array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("unexpected command %q", line)
+	}
+	args := make([]string, n)
+	for i := range args {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func TestLookupISBNCache(t *testing.T) {
+	const found = `{"ISBN:9780306406157": {"title": "Dune", "authors": [{"name": "Frank Herbert"}]}}`
+	// The cover host refuses connections, so the cover cannot be copied
+	const coverless = `{"ISBN:9780306406157": {"title": "Dune", "authors": [{"name": "Frank Herbert"}],
+		"cover": {"large": "http://127.0.0.1:0/dune.jpg"}}}`
+
+	tests := []struct {
+		name string
+		// responses are the provider's answers, in order
+		responses   []string
+		status      int
+		wantErr     error
+		wantCached  string
+		wantFetches int
+	}{
+		{
+			name:        "hit after miss",
+			responses:   []string{found},
+			status:      http.StatusOK,
+			wantCached:  `"title":"Dune"`,
+			wantFetches: 1,
+		},
+		{
+			name:        "not found is remembered",
+			responses:   []string{`{}`},
+			status:      http.StatusOK,
+			wantErr:     service.ErrBookMetadataNotFound,
+			wantCached:  notFoundMarker,
+			wantFetches: 1,
+		},
+		{
+			name:        "failed cover is not cached",
+			responses:   []string{coverless, coverless},
+			status:      http.StatusOK,
+			wantFetches: 2,
+		},
+		{
+			name:        "server error is not cached",
+			responses:   []string{`down`, `down`},
+			status:      http.StatusServiceUnavailable,
+			wantErr:     service.ErrMetadataProviderError,
+			wantFetches: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fetches atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(fetches.Add(1))
+				if n > len(tt.responses) {
+					t.Errorf("provider called %d times", n)
+					return
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.responses[n-1]))
+			}))
+			defer server.Close()
+
+			cache := newFakeRedis(t)
+			s := NewBookLookupService(NewOpenLibraryProvider(server.URL, time.Second), nil, cache.client(t), time.Hour, time.Second)
+
+			// The ISBN-10 form shares the cache entry of its ISBN-13
+			for _, isbn := range []string{"978-0-306-40615-7", "0306406152"} {
+				metadata, err := s.LookupISBN(context.Background(), isbn)
+				if tt.wantErr != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Fatalf("LookupISBN(%s) error = %v, want %v", isbn, err, tt.wantErr)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("LookupISBN(%s) error = %v", isbn, err)
+				}
+				if metadata.Title != "Dune" || len(metadata.Authors) != 1 || metadata.CoverURL != "" {
+					t.Errorf("LookupISBN(%s) = %+v", isbn, metadata)
+				}
+			}
+
+			if got := int(fetches.Load()); got != tt.wantFetches {
+				t.Errorf("provider fetched %d times, want %d", got, tt.wantFetches)
+			}
+			cached, ok := cache.get(cacheKeyPrefix + "9780306406157")
+			if tt.wantCached == "" {
+				if ok {
+					t.Errorf("cached %q, want nothing", cached)
+				}
+				return
+			}
+			if !strings.Contains(cached, tt.wantCached) {
+				t.Errorf("cached %q, want it to contain %q", cached, tt.wantCached)
+			}
+		})
+	}
+}
+
+func TestLookupISBNInvalid(t *testing.T) {
+	s := NewBookLookupService(NewOpenLibraryProvider("http://127.0.0.1:0", time.Second), nil, nil, time.Hour, time.Second)
+	for _, isbn := range []string{"", "123", "9780306406158"} {
+		if _, err := s.LookupISBN(context.Background(), isbn); !errors.Is(err, service.ErrInvalidISBN) {
+			t.Errorf("LookupISBN(%q) error = %v, want ErrInvalidISBN", isbn, err)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package lookup_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// publishDateLayouts are the free-text date forms Open Library editions use
+var publishDateLayouts = []string{
+	"2006",
+	"2006-01-02",
+	"January 2006",
+	"Jan 2006",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+}
+
+type openLibraryProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewOpenLibraryProvider creates a metadata provider for the Open Library
+// books API at baseURL, or a service with the same API
+func NewOpenLibraryProvider(baseURL string, timeout time.Duration) service.IBookMetadataProvider {
+	return &openLibraryProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *openLibraryProvider) Name() string {
+	return "openlibrary"
+}
+
+// openLibraryBook is an edition as returned by the books API with jscmd=data
+type openLibraryBook struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle"`
+	Authors  []struct {
+		Name string `json:"name"`
+	} `json:"authors"`
+	PublishDate string          `json:"publish_date"`
+	Notes       json.RawMessage `json:"notes"`
+	Cover       struct {
+		Medium string `json:"medium"`
+		Large  string `json:"large"`
+	} `json:"cover"`
+}
+
+// Lookup gets the metadata of an ISBN-13
+func (p *openLibraryProvider) Lookup(ctx context.Context, isbn string) (*model.BookMetadata, error) {
+	query := url.Values{
+		"bibkeys": {"ISBN:" + isbn},
+		"format":  {"json"},
+		"jscmd":   {"data"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/api/books?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrMetadataProviderError, err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrMetadataProviderError, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", service.ErrMetadataProviderError, resp.StatusCode)
+	}
+
+	var books map[string]openLibraryBook
+	if err := json.NewDecoder(resp.Body).Decode(&books); err != nil {
+		return nil, fmt.Errorf("%w: invalid response: %v", service.ErrMetadataProviderError, err)
+	}
+	book, ok := books["ISBN:"+isbn]
+	if !ok || book.Title == "" {
+		return nil, service.ErrBookMetadataNotFound
+	}
+
+	metadata := &model.BookMetadata{
+		ISBN:        isbn,
+		Title:       book.Title,
+		Description: notesText(book.Notes),
+		CoverURL:    book.Cover.Large,
+		Source:      p.Name(),
+	}
+	if book.Subtitle != "" {
+		metadata.Title += ": " + book.Subtitle
+	}
+	if metadata.CoverURL == "" {
+		metadata.CoverURL = book.Cover.Medium
+	}
+	for _, author := range book.Authors {
+		if name := strings.TrimSpace(author.Name); name != "" {
+			metadata.Authors = append(metadata.Authors, name)
+		}
+	}
+	for _, layout := range publishDateLayouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(book.PublishDate)); err == nil {
+			metadata.PublishedAt = t
+			break
+		}
+	}
+
+	return metadata, nil
+}
+
+// notesText reads a text field, which Open Library sends either as a
+// string or as a typed {"type": "/type/text", "value": ...} object
+func notesText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return strings.TrimSpace(text)
+	}
+	var typed struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &typed); err == nil {
+		return strings.TrimSpace(typed.Value)
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package lookup_service
+
+import (
+	"book_system/internal/service"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOpenLibraryLookup(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantErr     error
+		wantTitle   string
+		wantAuthors []string
+		wantDesc    string
+		wantCover   string
+		wantDate    time.Time
+	}{
+		{
+			name:   "found",
+			status: http.StatusOK,
+			body: `{"ISBN:9780306406157": {
+				"title": "Dune", "subtitle": "Deluxe Edition",
+				"authors": [{"name": "Frank Herbert"}, {"name": " "}],
+				"publish_date": "June 1965",
+				"notes": {"type": "/type/text", "value": " A desert planet. "},
+				"cover": {"medium": "https://covers.example/m.jpg", "large": "https://covers.example/l.jpg"}
+			}}`,
+			wantTitle:   "Dune: Deluxe Edition",
+			wantAuthors: []string{"Frank Herbert"},
+			wantDesc:    "A desert planet.",
+			wantCover:   "https://covers.example/l.jpg",
+			wantDate:    time.Date(1965, time.June, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "medium cover and plain notes",
+			status: http.StatusOK,
+			body: `{"ISBN:9780306406157": {
+				"title": "Dune", "publish_date": "1965", "notes": "Plain notes",
+				"cover": {"medium": "https://covers.example/m.jpg"}
+			}}`,
+			wantTitle: "Dune",
+			wantDesc:  "Plain notes",
+			wantCover: "https://covers.example/m.jpg",
+			wantDate:  time.Date(1965, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "not found",
+			status:  http.StatusOK,
+			body:    `{}`,
+			wantErr: service.ErrBookMetadataNotFound,
+		},
+		{
+			name:    "record without title",
+			status:  http.StatusOK,
+			body:    `{"ISBN:9780306406157": {"publish_date": "1965"}}`,
+			wantErr: service.ErrBookMetadataNotFound,
+		},
+		{
+			name:    "server error",
+			status:  http.StatusBadGateway,
+			body:    `upstream down`,
+			wantErr: service.ErrMetadataProviderError,
+		},
+		{
+			name:    "invalid response",
+			status:  http.StatusOK,
+			body:    `<html>`,
+			wantErr: service.ErrMetadataProviderError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/books" || r.URL.Query().Get("bibkeys") != "ISBN:9780306406157" {
+					t.Errorf("unexpected request %s", r.URL)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := NewOpenLibraryProvider(server.URL+"/", time.Second)
+			metadata, err := provider.Lookup(context.Background(), "9780306406157")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Lookup() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+
+			if metadata.ISBN != "9780306406157" || metadata.Source != "openlibrary" {
+				t.Errorf("ISBN, Source = %q, %q", metadata.ISBN, metadata.Source)
+			}
+			if metadata.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", metadata.Title, tt.wantTitle)
+			}
+			if !reflect.DeepEqual(metadata.Authors, tt.wantAuthors) {
+				t.Errorf("Authors = %q, want %q", metadata.Authors, tt.wantAuthors)
+			}
+			if metadata.Description != tt.wantDesc {
+				t.Errorf("Description = %q, want %q", metadata.Description, tt.wantDesc)
+			}
+			if metadata.CoverURL != tt.wantCover {
+				t.Errorf("CoverURL = %q, want %q", metadata.CoverURL, tt.wantCover)
+			}
+			if !metadata.PublishedAt.Equal(tt.wantDate) {
+				t.Errorf("PublishedAt = %v, want %v", metadata.PublishedAt, tt.wantDate)
+			}
+		})
+	}
+}
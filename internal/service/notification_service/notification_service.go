@@ -0,0 +1,124 @@
+package notification_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type notificationService struct {
+	repo repository.INotificationRepository
+}
+
+// NewNotificationService creates a new notification service. Notifications
+// are delivered to the user's inbox.
+func NewNotificationService(repo repository.INotificationRepository) service.INotificationService {
+	return &notificationService{
+		repo: repo,
+	}
+}
+
+// Notify puts a message in a user's inbox. Called inside a transaction,
+// the message is only delivered if the transaction commits.
+func (s *notificationService) Notify(ctx context.Context, userID uuid.UUID, kind, message string, bookID *uuid.UUID) error {
+	notification := &model.Notification{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Type:      kind,
+		Message:   message,
+		BookID:    bookID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.Create(ctx, notification); err != nil {
+		return fmt.Errorf("failed to create notification: %v", err)
+	}
+
+	slog.Info("Notified user",
+		slog.String("user_id", userID.String()),
+		slog.String("type", kind),
+	)
+	return nil
+}
+
+// ListMyNotifications gets a paginated list of the current user's
+// notifications, newest first, with the number still unread
+func (s *notificationService) ListMyNotifications(ctx context.Context, page, pageSize int, unreadOnly bool) (*model.NotificationListResponse, error) {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	notifications, total, err := s.repo.FindByUserID(ctx, userID, unreadOnly, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %v", err)
+	}
+	unread, err := s.repo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread notifications: %v", err)
+	}
+
+	notificationDTOs := make([]*model.NotificationResponse, len(notifications))
+	for i, notification := range notifications {
+		notificationDTOs[i] = notification.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.NotificationListResponse{
+		Data: notificationDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+		Unread: unread,
+	}, nil
+}
+
+// MarkRead marks one of the current user's notifications as read
+func (s *notificationService) MarkRead(ctx context.Context, id string) error {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return err
+	}
+	notificationID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: %v", service.ErrInvalidNotificationID, err)
+	}
+
+	found, err := s.repo.MarkRead(ctx, notificationID, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %v", err)
+	}
+	if !found {
+		return service.ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread notification of the current user as read
+func (s *notificationService) MarkAllRead(ctx context.Context) error {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.repo.MarkAllRead(ctx, userID, time.Now()); err != nil {
+		return fmt.Errorf("failed to mark notifications read: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,429 @@
+package order_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// expireBatchSize is the most pending orders one expiry pass cancels
+const expireBatchSize = 100
+
+// errOrderSettled stops the expiry of an order paid or cancelled since it was found
+var errOrderSettled = errors.New("order is no longer pending")
+
+type orderService struct {
+	repo       repository.IOrderRepository
+	cartRepo   repository.ICartRepository
+	bookRepo   repository.IBookRepository
+	inventory  service.IInventoryService
+	pricing    service.IPricingService
+	holds      service.IHoldService
+	transactor repository.ITransactor
+	pendingTTL time.Duration
+}
+
+// NewOrderService creates a new order service. Orders are priced through
+// the pricing service and reserve their stock through the inventory
+// ledger, leaving alone the stock set aside for other users' holds.
+// Orders still pending pendingTTL after checkout are cancelled by the
+// expirer, which puts their stock back.
+func NewOrderService(
+	repo repository.IOrderRepository,
+	cartRepo repository.ICartRepository,
+	bookRepo repository.IBookRepository,
+	inventory service.IInventoryService,
+	pricing service.IPricingService,
+	holds service.IHoldService,
+	transactor repository.ITransactor,
+	pendingTTL time.Duration,
+) service.IOrderService {
+	return &orderService{
+		repo:       repo,
+		cartRepo:   cartRepo,
+		bookRepo:   bookRepo,
+		inventory:  inventory,
+		pricing:    pricing,
+		holds:      holds,
+		transactor: transactor,
+		pendingTTL: pendingTTL,
+	}
+}
+
+// Checkout turns the current user's cart into a pending order. In a single
+// transaction every line is sold out of the stock through the inventory
+// ledger, the order is saved at the books' effective prices in its
+// currency less the coupon, a use of the coupon is counted and the cart is
+// emptied; if any book lacks stock or the coupon has run out nothing is
+// reserved. Stock set aside for other users' ready holds cannot be
+// bought, and the user's own hold on a book they buy is fulfilled. Lines
+// converted from another currency keep the exchange rate snapshot they
+// used.
+func (s *orderService) Checkout(ctx context.Context, req *model.CheckoutRequest) (*model.OrderResponse, error) {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	currency, err := s.pricing.ResolveCurrency(ctx, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	order := &model.Order{
+		ID:              uuid.New(),
+		UserID:          userID,
+		Status:          model.OrderStatusPending,
+		LocationID:      req.LocationID,
+		Currency:        currency,
+		ShippingAddress: req.ShippingAddress,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		items, err := s.cartRepo.FindByUserID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to find cart: %v", err)
+		}
+		if len(items) == 0 {
+			return service.ErrCartEmpty
+		}
+		// A fixed order keeps concurrent checkouts from locking the same
+		// books the other way round
+		sort.Slice(items, func(i, j int) bool {
+			return bytes.Compare(items[i].BookID[:], items[j].BookID[:]) < 0
+		})
+
+		bookIDs := make([]uuid.UUID, len(items))
+		for i, item := range items {
+			bookIDs[i] = item.BookID
+		}
+		books, err := s.bookRepo.FindByIDs(ctx, bookIDs)
+		if err != nil {
+			return fmt.Errorf("failed to find books: %v", err)
+		}
+		byID := make(map[uuid.UUID]*model.Book, len(books))
+		for _, book := range books {
+			byID[book.ID] = book
+		}
+		quotes, err := s.pricing.PriceBooks(ctx, books, currency)
+		if err != nil {
+			return err
+		}
+
+		subtotal := decimal.Zero
+		for _, item := range items {
+			book, ok := byID[item.BookID]
+			if !ok {
+				return fmt.Errorf("%w: %s is no longer available", service.ErrBookNotFound, item.BookID)
+			}
+
+			sold, err := s.inventory.AdjustStock(ctx, book.ID.String(), &model.StockAdjustRequest{
+				Type:       model.StockMovementSell,
+				Quantity:   item.Quantity,
+				LocationID: order.LocationID,
+				Reason:     "order " + order.ID.String(),
+			})
+			if err != nil {
+				if errors.Is(err, service.ErrInsufficientStock) {
+					return fmt.Errorf("%w for %q: %v", service.ErrInsufficientStock, book.Title, err)
+				}
+				return err
+			}
+			reserved, err := s.holds.ReservedStock(ctx, book.ID, userID)
+			if err != nil {
+				return err
+			}
+			if sold.Stock < reserved {
+				return fmt.Errorf("%w for %q: %d copies are set aside for holds", service.ErrInsufficientStock, book.Title, reserved)
+			}
+			if err := s.holds.FulfillHold(ctx, book.ID, userID); err != nil {
+				return err
+			}
+
+			quote := quotes[book.ID]
+			lineTotal := quote.Price.Mul(decimal.NewFromInt(int64(item.Quantity)))
+			item := &model.OrderItem{
+				ID:        uuid.New(),
+				OrderID:   order.ID,
+				BookID:    book.ID,
+				Title:     book.Title,
+				ISBN:      book.ISBN,
+				ListPrice: quote.ListPrice,
+				UnitPrice: quote.Price,
+				Quantity:  item.Quantity,
+				LineTotal: lineTotal,
+			}
+			if quote.ExchangeRate != nil {
+				item.ExchangeRateID = &quote.ExchangeRate.ID
+			}
+			order.Items = append(order.Items, item)
+			subtotal = subtotal.Add(lineTotal)
+		}
+
+		total := subtotal
+		if req.CouponCode != "" {
+			discount, err := s.pricing.CouponDiscount(ctx, req.CouponCode, subtotal, currency)
+			if err != nil {
+				return err
+			}
+			if err := s.pricing.RedeemCoupon(ctx, discount.Coupon.ID); err != nil {
+				return err
+			}
+			order.CouponID = &discount.Coupon.ID
+			order.CouponCode = discount.Coupon.Code
+			order.Discount = discount.Amount
+			total = total.Sub(discount.Amount)
+		}
+		order.Subtotal = subtotal
+		order.Total = total
+
+		if err := s.repo.Create(ctx, order); err != nil {
+			return fmt.Errorf("failed to create order: %v", err)
+		}
+		if err := s.cartRepo.DeleteByUserID(ctx, userID); err != nil {
+			return fmt.Errorf("failed to clear cart: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order.ToDTO(), nil
+}
+
+// GetOrder gets an order by ID. Users only see their own orders; admins
+// see every order.
+func (s *orderService) GetOrder(ctx context.Context, id string) (*model.OrderResponse, error) {
+	orderID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidOrderID, err)
+	}
+
+	order, err := s.repo.FindByID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to find order: %v", err)
+	}
+	if !canSee(ctx, order) {
+		return nil, service.ErrOrderNotFound
+	}
+
+	return order.ToDTO(), nil
+}
+
+// ListMyOrders gets a paginated order history of the current user, newest first
+func (s *orderService) ListMyOrders(ctx context.Context, page, pageSize int, status string) (*model.OrderListResponse, error) {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := map[string]any{"user_id = ?": userID}
+	if status != "" {
+		filters["status = ?"] = status
+	}
+	return s.list(ctx, page, pageSize, filters)
+}
+
+// ListOrders gets a paginated list of all orders, newest first
+func (s *orderService) ListOrders(ctx context.Context, page, pageSize int, filters map[string]any) (*model.OrderListResponse, error) {
+	return s.list(ctx, page, pageSize, filters)
+}
+
+// CancelOrder cancels one of the current user's orders while it is still pending
+func (s *orderService) CancelOrder(ctx context.Context, id string) (*model.OrderResponse, error) {
+	return s.transition(ctx, id, model.OrderStatusCancelled, func(order *model.Order) error {
+		if !canSee(ctx, order) {
+			return service.ErrOrderNotFound
+		}
+		if order.Status != model.OrderStatusPending {
+			return fmt.Errorf("%w: %s orders can only be cancelled by an admin", service.ErrInvalidOrderTransition, order.Status)
+		}
+		return nil
+	})
+}
+
+// UpdateOrderStatus moves an order to another state of its lifecycle
+func (s *orderService) UpdateOrderStatus(ctx context.Context, id string, req *model.OrderStatusRequest) (*model.OrderResponse, error) {
+	return s.transition(ctx, id, req.Status, nil)
+}
+
+// ExpireOrders cancels the orders left pending for longer than their
+// payment window, putting their stock back, and returns how many it
+// cancelled. Orders paid or cancelled in the meantime are left alone.
+func (s *orderService) ExpireOrders(ctx context.Context) (int, error) {
+	orders, err := s.repo.FindExpired(ctx, time.Now().Add(-s.pendingTTL), expireBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired orders: %v", err)
+	}
+
+	expired := 0
+	for _, order := range orders {
+		_, err := s.transition(ctx, order.ID.String(), model.OrderStatusCancelled, func(order *model.Order) error {
+			if order.Status != model.OrderStatusPending {
+				return errOrderSettled
+			}
+			return nil
+		})
+		if errors.Is(err, errOrderSettled) {
+			continue
+		}
+		if err != nil {
+			slog.Error("Failed to expire order", slog.String("order_id", order.ID.String()), slog.Any("error", err))
+			continue
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+// RunExpirer expires pending orders every interval until ctx is done
+func (s *orderService) RunExpirer(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := s.ExpireOrders(ctx)
+			if err != nil {
+				slog.Error("Order expiry failed", slog.Any("error", err))
+				continue
+			}
+			if expired > 0 {
+				slog.Info("Cancelled unpaid orders", slog.Int("expired", expired))
+			}
+		}
+	}
+}
+
+// transition moves an order to status under a row lock, after check
+// allows it. Orders cancelled or refunded before they ship put their
+// stock back through the inventory ledger; cancelled orders also give
+// back their use of a coupon.
+func (s *orderService) transition(ctx context.Context, id, status string, check func(order *model.Order) error) (*model.OrderResponse, error) {
+	orderID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidOrderID, err)
+	}
+
+	var order *model.Order
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		order, err = s.repo.FindByIDForUpdate(ctx, orderID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return service.ErrOrderNotFound
+			}
+			return fmt.Errorf("failed to find order: %v", err)
+		}
+		if check != nil {
+			if err := check(order); err != nil {
+				return err
+			}
+		}
+		if !order.CanTransition(status) {
+			return fmt.Errorf("%w: %s to %s", service.ErrInvalidOrderTransition, order.Status, status)
+		}
+
+		if (status == model.OrderStatusCancelled || status == model.OrderStatusRefunded) && !order.Shipped() {
+			for _, item := range order.Items {
+				// Books deleted since checkout have no stock to return to
+				if _, err := s.bookRepo.FindByID(ctx, item.BookID); err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						continue
+					}
+					return fmt.Errorf("failed to find book: %v", err)
+				}
+				restock := &model.StockAdjustRequest{
+					Type:       model.StockMovementReturn,
+					Quantity:   item.Quantity,
+					LocationID: order.LocationID,
+					Reason:     fmt.Sprintf("order %s %s", order.ID, status),
+				}
+				_, err := s.inventory.AdjustStock(ctx, item.BookID.String(), restock)
+				// Stock of a location closed since checkout goes back unallocated
+				if errors.Is(err, service.ErrLocationNotFound) {
+					restock.LocationID = nil
+					_, err = s.inventory.AdjustStock(ctx, item.BookID.String(), restock)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if status == model.OrderStatusCancelled && order.CouponID != nil {
+			if err := s.pricing.ReleaseCoupon(ctx, *order.CouponID); err != nil {
+				return err
+			}
+		}
+
+		order.SetStatus(status, time.Now())
+		if err := s.repo.Update(ctx, order); err != nil {
+			return fmt.Errorf("failed to update order: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order.ToDTO(), nil
+}
+
+// list gets a paginated list of the orders matching filters
+func (s *orderService) list(ctx context.Context, page, pageSize int, filters map[string]any) (*model.OrderListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	orders, total, err := s.repo.FindAll(ctx, page, pageSize, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %v", err)
+	}
+
+	orderDTOs := make([]*model.OrderResponse, len(orders))
+	for i, order := range orders {
+		orderDTOs[i] = order.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.OrderListResponse{
+		Data: orderDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// canSee reports whether the current user may see an order
+func canSee(ctx context.Context, order *model.Order) bool {
+	return utils.UserRoleFromContext(ctx) == "admin" || utils.UserIDFromContext(ctx) == order.UserID.String()
+}
@@ -0,0 +1,339 @@
+package order_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type fakeOrders struct {
+	repository.IOrderRepository
+	orders map[uuid.UUID]*model.Order
+}
+
+func (r *fakeOrders) Create(ctx context.Context, order *model.Order) error {
+	copied := *order
+	r.orders[order.ID] = &copied
+	return nil
+}
+
+func (r *fakeOrders) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (r *fakeOrders) Update(ctx context.Context, order *model.Order) error {
+	copied := *order
+	r.orders[order.ID] = &copied
+	return nil
+}
+
+// FindExpired ignores the status so tests can see orders settled between
+// the query and the row lock.
+func (r *fakeOrders) FindExpired(ctx context.Context, before time.Time, limit int) ([]*model.Order, error) {
+	var expired []*model.Order
+	for _, order := range r.orders {
+		if order.CreatedAt.Before(before) {
+			copied := *order
+			expired = append(expired, &copied)
+		}
+	}
+	return expired, nil
+}
+
+// fakeInventory records restocks, and sells out of stock by book ID
+type fakeInventory struct {
+	service.IInventoryService
+	closed   map[uuid.UUID]bool
+	stock    map[string]int
+	restocks []*model.StockAdjustRequest
+}
+
+func (s *fakeInventory) AdjustStock(ctx context.Context, id string, req *model.StockAdjustRequest) (*model.StockAdjustResponse, error) {
+	if req.LocationID != nil && s.closed[*req.LocationID] {
+		return nil, service.ErrLocationNotFound
+	}
+	if req.Type == model.StockMovementSell {
+		if s.stock[id] < req.Quantity {
+			return nil, service.ErrInsufficientStock
+		}
+		s.stock[id] -= req.Quantity
+		return &model.StockAdjustResponse{Stock: s.stock[id]}, nil
+	}
+	s.restocks = append(s.restocks, req)
+	return &model.StockAdjustResponse{}, nil
+}
+
+type fakeHolds struct {
+	service.IHoldService
+}
+
+func (s *fakeHolds) ReservedStock(ctx context.Context, bookID, userID uuid.UUID) (int, error) {
+	return 0, nil
+}
+
+func (s *fakeHolds) FulfillHold(ctx context.Context, bookID, userID uuid.UUID) error {
+	return nil
+}
+
+type fakePricing struct {
+	service.IPricingService
+	released []uuid.UUID
+}
+
+func (s *fakePricing) ReleaseCoupon(ctx context.Context, id uuid.UUID) error {
+	s.released = append(s.released, id)
+	return nil
+}
+
+func (s *fakePricing) ResolveCurrency(ctx context.Context, requested string) (string, error) {
+	return "USD", nil
+}
+
+// PriceBooks prices books at their list price
+func (s *fakePricing) PriceBooks(ctx context.Context, books []*model.Book, currency string) (map[uuid.UUID]*model.PriceQuote, error) {
+	quotes := make(map[uuid.UUID]*model.PriceQuote, len(books))
+	for _, book := range books {
+		quotes[book.ID] = &model.PriceQuote{BookID: book.ID, Currency: currency, ListPrice: book.Price, Price: book.Price}
+	}
+	return quotes, nil
+}
+
+// booksOf stores the books an order bought
+func booksOf(order *model.Order) *repotest.Books {
+	books := repotest.NewBooks()
+	for _, item := range order.Items {
+		books.Put(&model.Book{ID: item.BookID, Version: 1})
+	}
+	return books
+}
+
+func TestCheckout(t *testing.T) {
+	tests := []struct {
+		name    string
+		stock   int
+		empty   bool
+		wantErr error
+		// wantCart is the number of items left in the cart
+		wantCart int
+	}{
+		{name: "order placed and cart cleared", stock: 3},
+		{name: "short stock keeps the cart", stock: 1, wantErr: service.ErrInsufficientStock, wantCart: 1},
+		{name: "empty cart", stock: 3, empty: true, wantErr: service.ErrCartEmpty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.New()
+			ctx := utils.WithCurrentUser(context.Background(), userID.String(), "user")
+			book := &model.Book{ID: uuid.New(), Title: "Dune", Price: decimal.RequireFromString("9.99"), Stock: tt.stock, Version: 1}
+			carts := repotest.NewCarts()
+			if !tt.empty {
+				_ = carts.Save(ctx, &model.CartItem{UserID: userID, BookID: book.ID, Quantity: 2})
+			}
+			orders := &fakeOrders{orders: make(map[uuid.UUID]*model.Order)}
+			inventory := &fakeInventory{stock: map[string]int{book.ID.String(): tt.stock}}
+			s := NewOrderService(orders, carts, repotest.NewBooks(book), inventory, &fakePricing{}, &fakeHolds{},
+				repotest.Transactor{}, time.Hour)
+
+			order, err := s.Checkout(ctx, &model.CheckoutRequest{ShippingAddress: "1 Arrakis Way"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Checkout() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if items, _ := carts.FindByUserID(ctx, userID); len(items) != tt.wantCart {
+				t.Errorf("cart holds %d items after checkout, want %d", len(items), tt.wantCart)
+			}
+			if tt.wantErr != nil {
+				if len(orders.orders) != 0 {
+					t.Errorf("a failed checkout saved an order")
+				}
+				return
+			}
+			saved := orders.orders[order.ID]
+			if saved == nil || saved.Status != model.OrderStatusPending || len(saved.Items) != 1 ||
+				!saved.Total.Equal(decimal.RequireFromString("19.98")) {
+				t.Errorf("order = %+v", saved)
+			}
+			if left := inventory.stock[book.ID.String()]; left != tt.stock-2 {
+				t.Errorf("stock left = %d, want %d", left, tt.stock-2)
+			}
+		})
+	}
+}
+
+func TestOrderTransitions(t *testing.T) {
+	ownerID := uuid.New()
+	owner := utils.WithCurrentUser(context.Background(), ownerID.String(), "user")
+	stranger := utils.WithCurrentUser(context.Background(), uuid.NewString(), "user")
+	admin := utils.WithCurrentUser(context.Background(), uuid.NewString(), "admin")
+
+	tests := []struct {
+		name    string
+		status  string
+		shipped bool
+		// cancel cancels through CancelOrder in ctx rather than UpdateOrderStatus
+		cancel      bool
+		ctx         context.Context
+		to          string
+		deletedBook bool
+		closed      bool
+		wantErr     error
+		wantStatus  string
+		// wantRestocks counts the lines put back into stock
+		wantRestocks int
+		wantReleased bool
+	}{
+		{name: "pending to paid", status: model.OrderStatusPending, ctx: admin, to: model.OrderStatusPaid, wantStatus: model.OrderStatusPaid},
+		{name: "pending cannot ship", status: model.OrderStatusPending, ctx: admin, to: model.OrderStatusShipped, wantErr: service.ErrInvalidOrderTransition},
+		{name: "paid to shipped", status: model.OrderStatusPaid, ctx: admin, to: model.OrderStatusShipped, wantStatus: model.OrderStatusShipped},
+		{name: "paid cancelled restocks and releases the coupon", status: model.OrderStatusPaid, ctx: admin, to: model.OrderStatusCancelled,
+			wantStatus: model.OrderStatusCancelled, wantRestocks: 2, wantReleased: true},
+		{name: "paid refunded restocks", status: model.OrderStatusPaid, ctx: admin, to: model.OrderStatusRefunded,
+			wantStatus: model.OrderStatusRefunded, wantRestocks: 2},
+		{name: "shipped to delivered", status: model.OrderStatusShipped, shipped: true, ctx: admin, to: model.OrderStatusDelivered, wantStatus: model.OrderStatusDelivered},
+		{name: "shipped cannot be cancelled", status: model.OrderStatusShipped, shipped: true, ctx: admin, to: model.OrderStatusCancelled, wantErr: service.ErrInvalidOrderTransition},
+		{name: "delivered refunded keeps the stock out", status: model.OrderStatusDelivered, shipped: true, ctx: admin, to: model.OrderStatusRefunded, wantStatus: model.OrderStatusRefunded},
+		{name: "cancelled is final", status: model.OrderStatusCancelled, ctx: admin, to: model.OrderStatusPaid, wantErr: service.ErrInvalidOrderTransition},
+		{name: "refunded is final", status: model.OrderStatusRefunded, ctx: admin, to: model.OrderStatusPaid, wantErr: service.ErrInvalidOrderTransition},
+		{name: "deleted books are not restocked", status: model.OrderStatusPaid, ctx: admin, to: model.OrderStatusCancelled, deletedBook: true,
+			wantStatus: model.OrderStatusCancelled, wantRestocks: 1, wantReleased: true},
+		{name: "stock of a closed location goes back unallocated", status: model.OrderStatusPaid, ctx: admin, to: model.OrderStatusRefunded, closed: true,
+			wantStatus: model.OrderStatusRefunded, wantRestocks: 2},
+		{name: "owner cancels a pending order", status: model.OrderStatusPending, cancel: true, ctx: owner,
+			wantStatus: model.OrderStatusCancelled, wantRestocks: 2, wantReleased: true},
+		{name: "owner cannot cancel a paid order", status: model.OrderStatusPaid, cancel: true, ctx: owner, wantErr: service.ErrInvalidOrderTransition},
+		{name: "others cannot cancel", status: model.OrderStatusPending, cancel: true, ctx: stranger, wantErr: service.ErrOrderNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locationID, couponID := uuid.New(), uuid.New()
+			order := &model.Order{
+				ID:         uuid.New(),
+				UserID:     ownerID,
+				Status:     tt.status,
+				LocationID: &locationID,
+				CouponID:   &couponID,
+				Items: []*model.OrderItem{
+					{BookID: uuid.New(), Quantity: 2},
+					{BookID: uuid.New(), Quantity: 1},
+				},
+			}
+			if tt.shipped {
+				shippedAt := time.Now()
+				order.ShippedAt = &shippedAt
+			}
+
+			orders := &fakeOrders{orders: map[uuid.UUID]*model.Order{order.ID: order}}
+			books := booksOf(order)
+			if tt.deletedBook {
+				books.Get(order.Items[0].BookID).DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+			}
+			inventory := &fakeInventory{closed: map[uuid.UUID]bool{locationID: tt.closed}}
+			pricing := &fakePricing{}
+			s := NewOrderService(orders, nil, books, inventory, pricing, nil, repotest.Transactor{}, time.Hour)
+
+			var err error
+			if tt.cancel {
+				_, err = s.CancelOrder(tt.ctx, order.ID.String())
+			} else {
+				_, err = s.UpdateOrderStatus(tt.ctx, order.ID.String(), &model.OrderStatusRequest{Status: tt.to})
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("transition error = %v, want %v", err, tt.wantErr)
+				}
+				if orders.orders[order.ID].Status != tt.status || len(inventory.restocks) > 0 {
+					t.Errorf("a refused transition changed the order or the stock")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("transition error = %v", err)
+			}
+
+			saved := orders.orders[order.ID]
+			if saved.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", saved.Status, tt.wantStatus)
+			}
+			if len(inventory.restocks) != tt.wantRestocks {
+				t.Errorf("restocked %d lines, want %d", len(inventory.restocks), tt.wantRestocks)
+			}
+			for _, restock := range inventory.restocks {
+				if restock.Type != model.StockMovementReturn || (restock.LocationID == nil) != tt.closed {
+					t.Errorf("restock = %+v", restock)
+				}
+			}
+			if released := len(pricing.released) == 1 && pricing.released[0] == couponID; released != tt.wantReleased {
+				t.Errorf("coupon released = %v, want %v", released, tt.wantReleased)
+			}
+		})
+	}
+}
+
+func TestExpireOrders(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       string
+		age          time.Duration
+		wantStatus   string
+		wantRestocks int
+		wantExpired  int
+	}{
+		{name: "unpaid past the window is cancelled", status: model.OrderStatusPending, age: 2 * time.Hour,
+			wantStatus: model.OrderStatusCancelled, wantRestocks: 1, wantExpired: 1},
+		{name: "unpaid within the window waits", status: model.OrderStatusPending, age: time.Minute, wantStatus: model.OrderStatusPending},
+		{name: "paid meanwhile is left alone", status: model.OrderStatusPaid, age: 2 * time.Hour, wantStatus: model.OrderStatusPaid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			couponID := uuid.New()
+			order := &model.Order{
+				ID:        uuid.New(),
+				UserID:    uuid.New(),
+				Status:    tt.status,
+				CouponID:  &couponID,
+				Items:     []*model.OrderItem{{BookID: uuid.New(), Quantity: 1}},
+				CreatedAt: time.Now().Add(-tt.age),
+			}
+
+			orders := &fakeOrders{orders: map[uuid.UUID]*model.Order{order.ID: order}}
+			inventory := &fakeInventory{}
+			pricing := &fakePricing{}
+			s := NewOrderService(orders, nil, booksOf(order), inventory, pricing, nil, repotest.Transactor{}, time.Hour)
+
+			expired, err := s.ExpireOrders(context.Background())
+			if err != nil {
+				t.Fatalf("ExpireOrders() error = %v", err)
+			}
+			if expired != tt.wantExpired {
+				t.Errorf("expired %d orders, want %d", expired, tt.wantExpired)
+			}
+			if status := orders.orders[order.ID].Status; status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", status, tt.wantStatus)
+			}
+			if len(inventory.restocks) != tt.wantRestocks {
+				t.Errorf("restocked %d lines, want %d", len(inventory.restocks), tt.wantRestocks)
+			}
+			if released := len(pricing.released) == 1; released != (tt.wantExpired == 1) {
+				t.Errorf("coupon released = %v", released)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package payment_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FakeSignatureHeader carries the signature of fake provider webhooks, as
+// "t=<unix time>,v1=<hex HMAC-SHA256 of the time, a dot and the body>"
+const FakeSignatureHeader = "Fake-Signature"
+
+// webhookTolerance is how old a webhook signature may be
+const webhookTolerance = 5 * time.Minute
+
+// FakeProvider is an in-memory payment provider for development and tests.
+// Nothing leaves the process: payments are held in a map until Approve or
+// Decline settles them, or, with autoApprove, approved as soon as they are
+// looked up. They are lost on restart, after which their refs are reported
+// with ErrPaymentIntentNotFound. Its webhooks are signed the same way as a
+// real provider's, with SignWebhook producing valid signatures.
+type FakeProvider struct {
+	secret      string
+	autoApprove bool
+
+	mu       sync.Mutex
+	payments map[string]*model.ProviderPayment
+	// intents maps idempotency keys to the payments they started
+	intents map[string]string
+}
+
+// fakeEvent is the body of a fake provider webhook
+type fakeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Ref           string `json:"ref"`
+		Status        string `json:"status"`
+		FailureReason string `json:"failure_reason,omitempty"`
+	} `json:"data"`
+}
+
+// NewFakeProvider creates a fake provider signing webhooks with secret
+func NewFakeProvider(secret string, autoApprove bool) *FakeProvider {
+	return &FakeProvider{
+		secret:      secret,
+		autoApprove: autoApprove,
+		payments:    make(map[string]*model.ProviderPayment),
+		intents:     make(map[string]string),
+	}
+}
+
+// Name identifies the provider on the payments it creates
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+// CreateIntent starts a pending payment, or returns the payment already
+// started with the same idempotency key
+func (p *FakeProvider) CreateIntent(ctx context.Context, intent *model.PaymentIntent) (*model.ProviderPayment, error) {
+	if !intent.Amount.IsPositive() {
+		return nil, fmt.Errorf("fake: invalid amount %s", intent.Amount)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ref, ok := p.intents[intent.IdempotencyKey]; ok && intent.IdempotencyKey != "" {
+		copied := *p.payments[ref]
+		return &copied, nil
+	}
+
+	ref := "fake_pi_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	payment := &model.ProviderPayment{
+		Ref:          ref,
+		ClientSecret: ref + "_secret_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Status:       model.PaymentStatusPending,
+	}
+	p.payments[ref] = payment
+	if intent.IdempotencyKey != "" {
+		p.intents[intent.IdempotencyKey] = ref
+	}
+	copied := *payment
+	return &copied, nil
+}
+
+// Capture collects an authorized payment
+func (p *FakeProvider) Capture(ctx context.Context, ref string) (*model.ProviderPayment, error) {
+	return p.settle(ref, model.PaymentStatusAuthorized, model.PaymentStatusCaptured, "")
+}
+
+// Refund pays a captured payment back
+func (p *FakeProvider) Refund(ctx context.Context, ref string) (*model.ProviderPayment, error) {
+	return p.settle(ref, model.PaymentStatusCaptured, model.PaymentStatusRefunded, "")
+}
+
+// Approve authorizes a pending payment, as a payer would
+func (p *FakeProvider) Approve(ref string) (*model.ProviderPayment, error) {
+	return p.settle(ref, model.PaymentStatusPending, model.PaymentStatusAuthorized, "")
+}
+
+// Decline fails a pending payment, as a bank would
+func (p *FakeProvider) Decline(ref, reason string) (*model.ProviderPayment, error) {
+	return p.settle(ref, model.PaymentStatusPending, model.PaymentStatusFailed, reason)
+}
+
+// GetPayment gets the current state of a payment
+func (p *FakeProvider) GetPayment(ctx context.Context, ref string) (*model.ProviderPayment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[ref]
+	if !ok {
+		return nil, fmt.Errorf("fake: %w: %s", service.ErrPaymentIntentNotFound, ref)
+	}
+	if p.autoApprove && payment.Status == model.PaymentStatusPending {
+		payment.Status = model.PaymentStatusAuthorized
+	}
+	copied := *payment
+	return &copied, nil
+}
+
+// VerifyWebhook checks the signature of a webhook delivery and parses its event
+func (p *FakeProvider) VerifyWebhook(payload []byte, header http.Header) (*model.PaymentWebhookEvent, error) {
+	var timestamp, signature string
+	for _, part := range strings.Split(header.Get(FakeSignatureHeader), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signature = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing timestamp", service.ErrInvalidWebhookSignature)
+	}
+	if age := time.Since(time.Unix(unix, 0)); age > webhookTolerance || age < -webhookTolerance {
+		return nil, fmt.Errorf("%w: timestamp outside tolerance", service.ErrInvalidWebhookSignature)
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, p.mac(timestamp, payload)) {
+		return nil, service.ErrInvalidWebhookSignature
+	}
+
+	var event fakeEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidWebhookSignature, err)
+	}
+	if event.ID == "" || event.Data.Ref == "" {
+		return nil, fmt.Errorf("%w: event without id or payment", service.ErrInvalidWebhookSignature)
+	}
+
+	return &model.PaymentWebhookEvent{
+		ID: event.ID,
+		Payment: model.ProviderPayment{
+			Ref:           event.Data.Ref,
+			Status:        event.Data.Status,
+			FailureReason: event.Data.FailureReason,
+		},
+	}, nil
+}
+
+// SignWebhook returns the signature header value of a webhook body sent at t
+func (p *FakeProvider) SignWebhook(payload []byte, t time.Time) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(p.mac(timestamp, payload))
+}
+
+// mac computes the HMAC of a webhook body and its timestamp
+func (p *FakeProvider) mac(timestamp string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(p.secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// settle moves a payment from one state to another
+func (p *FakeProvider) settle(ref, from, to, reason string) (*model.ProviderPayment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[ref]
+	if !ok {
+		return nil, fmt.Errorf("fake: %w: %s", service.ErrPaymentIntentNotFound, ref)
+	}
+	if payment.Status == model.PaymentStatusPending && from == model.PaymentStatusAuthorized && p.autoApprove {
+		payment.Status = model.PaymentStatusAuthorized
+	}
+	if payment.Status != from {
+		return nil, fmt.Errorf("fake: payment %s is %s, not %s", ref, payment.Status, from)
+	}
+
+	payment.Status = to
+	payment.FailureReason = reason
+	copied := *payment
+	return &copied, nil
+}
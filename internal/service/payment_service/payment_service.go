@@ -0,0 +1,558 @@
+package payment_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// reconcileBatchSize is the most stuck payments one reconciler run checks
+const reconcileBatchSize = 100
+
+type paymentService struct {
+	repo         repository.IPaymentRepository
+	orderRepo    repository.IOrderRepository
+	orderService service.IOrderService
+	provider     service.IPaymentProvider
+	transactor   repository.ITransactor
+	stuckAfter   time.Duration
+}
+
+// NewProvider creates the payment provider configured by name. Webhooks
+// from it are signed with webhookSecret. It returns ErrNoPaymentProvider
+// when name is empty. The fake provider approves every payment, so it is
+// refused unless allowFake is set.
+func NewProvider(name, webhookSecret string, allowFake bool) (service.IPaymentProvider, error) {
+	switch name {
+	case "":
+		return nil, service.ErrNoPaymentProvider
+	case "fake":
+		if !allowFake {
+			return nil, errors.New("the fake payment provider approves every payment and must be allowed explicitly")
+		}
+		return NewFakeProvider(webhookSecret, true), nil
+	}
+	return nil, fmt.Errorf("unknown payment provider %q", name)
+}
+
+// NewPaymentService creates a new payment service. Payments that stay
+// pending or authorized for longer than stuckAfter are checked against
+// the provider by the reconciler.
+func NewPaymentService(
+	repo repository.IPaymentRepository,
+	orderRepo repository.IOrderRepository,
+	orderService service.IOrderService,
+	provider service.IPaymentProvider,
+	transactor repository.ITransactor,
+	stuckAfter time.Duration,
+) service.IPaymentService {
+	return &paymentService{
+		repo:         repo,
+		orderRepo:    orderRepo,
+		orderService: orderService,
+		provider:     provider,
+		transactor:   transactor,
+		stuckAfter:   stuckAfter,
+	}
+}
+
+// CreatePayment starts the payment of one of the current user's pending
+// orders for its total. An order already being paid gets its current
+// payment back, so retried requests do not charge twice. The payment is
+// reserved with the order locked, so concurrent requests for it get the
+// same one; the provider is only called once that lock is released, with
+// an idempotency key derived from the order and attempt, so requests
+// racing for a reserved payment start a single intent.
+func (s *paymentService) CreatePayment(ctx context.Context, id string) (*model.PaymentResponse, error) {
+	orderID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidOrderID, err)
+	}
+
+	payment, err := s.reserve(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.ProviderRef == nil {
+		if payment, err = s.startIntent(ctx, payment); err != nil {
+			return nil, err
+		}
+	}
+
+	dto := payment.ToDTO()
+	dto.ClientSecret = payment.ClientSecret
+	return dto, nil
+}
+
+// reserve returns the active payment of a pending order of the current
+// user, saving a new pending payment, without a provider intent yet, when
+// there is none. The order is locked while it looks.
+func (s *paymentService) reserve(ctx context.Context, orderID uuid.UUID) (*model.Payment, error) {
+	var payment *model.Payment
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		order, err := s.orderRepo.FindByIDForUpdate(ctx, orderID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return service.ErrOrderNotFound
+			}
+			return fmt.Errorf("failed to find order: %v", err)
+		}
+		if utils.UserIDFromContext(ctx) != order.UserID.String() {
+			return service.ErrOrderNotFound
+		}
+		if order.Status != model.OrderStatusPending {
+			return fmt.Errorf("%w: order is %s", service.ErrOrderNotPayable, order.Status)
+		}
+
+		payment, err = s.repo.FindActiveByOrderID(ctx, order.ID)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to find order payment: %v", err)
+		}
+
+		attempts, err := s.repo.CountByOrderID(ctx, order.ID)
+		if err != nil {
+			return fmt.Errorf("failed to count order payments: %v", err)
+		}
+		now := time.Now()
+		payment = &model.Payment{
+			ID:        uuid.New(),
+			OrderID:   order.ID,
+			Attempt:   int(attempts) + 1,
+			UserID:    order.UserID,
+			Amount:    order.Total,
+			Currency:  order.Currency,
+			Provider:  s.provider.Name(),
+			Status:    model.PaymentStatusPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		for _, item := range order.Items {
+			payment.BookIDs = append(payment.BookIDs, item.BookID)
+		}
+		if err := s.repo.Create(ctx, payment); err != nil {
+			return fmt.Errorf("failed to create payment: %v", err)
+		}
+		return nil
+	})
+	return payment, err
+}
+
+// startIntent starts the provider intent of a payment, outside any
+// transaction, and records it. A payment whose intent cannot be started
+// stays reserved: a retried request or the reconciler starts it with the
+// same idempotency key.
+func (s *paymentService) startIntent(ctx context.Context, payment *model.Payment) (*model.Payment, error) {
+	intent, err := s.provider.CreateIntent(ctx, &model.PaymentIntent{
+		Amount:         payment.Amount,
+		Currency:       payment.Currency,
+		Reference:      payment.ID.String(),
+		IdempotencyKey: payment.IdempotencyKey(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrPaymentProviderError, err)
+	}
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		payment, err = s.repo.FindByIDForUpdate(ctx, payment.ID)
+		if err != nil {
+			return fmt.Errorf("failed to find payment: %v", err)
+		}
+		if payment.Ref() != intent.Ref {
+			// A payment that moved on keeps the intent it moved on with
+			if payment.Status != model.PaymentStatusPending {
+				return nil
+			}
+			payment.ProviderRef = &intent.Ref
+			payment.ClientSecret = intent.ClientSecret
+			payment.UpdatedAt = time.Now()
+			if err := s.repo.Update(ctx, payment); err != nil {
+				return fmt.Errorf("failed to update payment: %v", err)
+			}
+		}
+		_, err = s.apply(ctx, payment, intent)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// GetPayment gets a payment by ID. Users only see their own payments;
+// admins see every payment.
+func (s *paymentService) GetPayment(ctx context.Context, id string) (*model.PaymentResponse, error) {
+	payment, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if utils.UserRoleFromContext(ctx) != "admin" && utils.UserIDFromContext(ctx) != payment.UserID.String() {
+		return nil, service.ErrPaymentNotFound
+	}
+	return payment.ToDTO(), nil
+}
+
+// ListPayments gets a paginated list of payments, newest first
+func (s *paymentService) ListPayments(ctx context.Context, page, pageSize int, filters map[string]any) (*model.PaymentListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	payments, total, err := s.repo.FindAll(ctx, page, pageSize, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments: %v", err)
+	}
+
+	paymentDTOs := make([]*model.PaymentResponse, len(payments))
+	for i, payment := range payments {
+		paymentDTOs[i] = payment.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.PaymentListResponse{
+		Data: paymentDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// CapturePayment collects an authorized payment whose automatic capture failed
+func (s *paymentService) CapturePayment(ctx context.Context, id string) (*model.PaymentResponse, error) {
+	return s.settle(ctx, id, model.PaymentStatusAuthorized, s.provider.Capture)
+}
+
+// RefundPayment pays a captured payment back in full. Its order is marked
+// refunded, which puts the stock back if the order has not shipped.
+func (s *paymentService) RefundPayment(ctx context.Context, id string) (*model.PaymentResponse, error) {
+	return s.settle(ctx, id, model.PaymentStatusCaptured, s.provider.Refund)
+}
+
+// HandleWebhook applies a signed provider event to its payment. Events are
+// recorded by ID in the same transaction, so a redelivered event is
+// acknowledged without being applied twice.
+func (s *paymentService) HandleWebhook(ctx context.Context, payload []byte, header http.Header) error {
+	event, err := s.provider.VerifyWebhook(payload, header)
+	if err != nil {
+		return err
+	}
+
+	payment, err := s.repo.FindByProviderRef(ctx, s.provider.Name(), event.Payment.Ref)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %s", service.ErrPaymentNotFound, event.Payment.Ref)
+		}
+		return fmt.Errorf("failed to find payment: %v", err)
+	}
+
+	recorded := false
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		recorded, err = s.repo.RecordEvent(ctx, &model.PaymentEvent{
+			ID:         event.ID,
+			PaymentID:  payment.ID,
+			Status:     event.Payment.Status,
+			ReceivedAt: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record payment event: %v", err)
+		}
+		if !recorded {
+			slog.Info("Ignoring redelivered payment event", slog.String("event_id", event.ID))
+			return nil
+		}
+
+		payment, err = s.repo.FindByIDForUpdate(ctx, payment.ID)
+		if err != nil {
+			return fmt.Errorf("failed to find payment: %v", err)
+		}
+		_, err = s.apply(ctx, payment, &event.Payment)
+		return err
+	})
+	if err != nil || !recorded {
+		return err
+	}
+
+	if _, err := s.followUp(ctx, payment); err != nil {
+		slog.Error("Failed to settle payment", slog.String("payment_id", payment.ID.String()), slog.Any("error", err))
+	}
+	return nil
+}
+
+// ReconcilePayments checks the payments left pending or authorized for
+// longer than the stuck threshold against the provider, applying the
+// state the provider reports. Payments captured for an order cancelled in
+// the meantime are refunded.
+func (s *paymentService) ReconcilePayments(ctx context.Context) (*model.PaymentReconciliation, error) {
+	payments, err := s.repo.FindStuck(ctx, time.Now().Add(-s.stuckAfter), reconcileBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stuck payments: %v", err)
+	}
+
+	result := &model.PaymentReconciliation{}
+	for _, payment := range payments {
+		result.Checked++
+
+		state, err := s.check(ctx, payment)
+		if err != nil {
+			result.Failed++
+			slog.Error("Failed to get payment from provider", slog.String("payment_id", payment.ID.String()), slog.Any("error", err))
+			continue
+		}
+
+		current, changed, err := s.record(ctx, payment.ID, state)
+		if err == nil {
+			var settled bool
+			settled, err = s.followUp(ctx, current)
+			changed = changed || settled
+		}
+		if err != nil {
+			result.Failed++
+			slog.Error("Failed to reconcile payment", slog.String("payment_id", payment.ID.String()), slog.Any("error", err))
+			continue
+		}
+		if changed {
+			result.Resolved++
+		}
+	}
+
+	return result, nil
+}
+
+// check gets the state of a stuck payment from its provider. A pending
+// payment whose intent was never recorded, or that the provider does not
+// know, as the fake provider forgets its payments when restarted, has its
+// intent started again under its idempotency key; nothing was paid
+// through an intent the provider does not know.
+func (s *paymentService) check(ctx context.Context, payment *model.Payment) (*model.ProviderPayment, error) {
+	if payment.ProviderRef != nil {
+		state, err := s.provider.GetPayment(ctx, payment.Ref())
+		if !errors.Is(err, service.ErrPaymentIntentNotFound) || payment.Status != model.PaymentStatusPending {
+			return state, err
+		}
+		slog.Warn("Starting the unknown intent of a pending payment again",
+			slog.String("payment_id", payment.ID.String()),
+			slog.String("provider_ref", payment.Ref()),
+		)
+	}
+
+	started, err := s.startIntent(ctx, payment)
+	if err != nil {
+		return nil, err
+	}
+	return s.provider.GetPayment(ctx, started.Ref())
+}
+
+// RunReconciler reconciles stuck payments every interval until ctx is done
+func (s *paymentService) RunReconciler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.ReconcilePayments(ctx)
+			if err != nil {
+				slog.Error("Payment reconciliation failed", slog.Any("error", err))
+				continue
+			}
+			if result.Checked > 0 {
+				slog.Info("Reconciled stuck payments",
+					slog.Int("checked", result.Checked),
+					slog.Int("resolved", result.Resolved),
+					slog.Int("failed", result.Failed),
+				)
+			}
+		}
+	}
+}
+
+// settle asks the provider to move a payment on from status and records
+// the state it ends up in. The provider is called outside the transaction,
+// so no row stays locked while it answers; a payment that changed in the
+// meantime only moves where it still can.
+func (s *paymentService) settle(ctx context.Context, id, status string, call func(ctx context.Context, ref string) (*model.ProviderPayment, error)) (*model.PaymentResponse, error) {
+	payment, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != status {
+		return nil, fmt.Errorf("%w: payment is %s", service.ErrInvalidPaymentTransition, payment.Status)
+	}
+
+	state, err := call(ctx, payment.Ref())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrPaymentProviderError, err)
+	}
+	payment, _, err = s.record(ctx, payment.ID, state)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.followUp(ctx, payment); err != nil {
+		slog.Error("Failed to settle payment", slog.String("payment_id", payment.ID.String()), slog.Any("error", err))
+	}
+	return payment.ToDTO(), nil
+}
+
+// record applies the state a provider reports to a payment, with the
+// payment locked, and returns the payment as it ends up
+func (s *paymentService) record(ctx context.Context, id uuid.UUID, state *model.ProviderPayment) (*model.Payment, bool, error) {
+	var payment *model.Payment
+	var changed bool
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		payment, err = s.repo.FindByIDForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to find payment: %v", err)
+		}
+		changed, err = s.apply(ctx, payment, state)
+		return err
+	})
+	return payment, changed, err
+}
+
+// followUp makes the provider calls a recorded payment state calls for:
+// an authorized payment is captured, and a payment captured for an order
+// cancelled in the meantime is refunded. It runs outside any transaction
+// and reports whether the payment moved. A call that fails leaves the
+// payment for the reconciler, which picks up both cases.
+func (s *paymentService) followUp(ctx context.Context, payment *model.Payment) (bool, error) {
+	changed := false
+	for {
+		var call func(ctx context.Context, ref string) (*model.ProviderPayment, error)
+		switch payment.Status {
+		case model.PaymentStatusAuthorized:
+			call = s.provider.Capture
+		case model.PaymentStatusCaptured:
+			order, err := s.orderRepo.FindByID(ctx, payment.OrderID)
+			if err != nil {
+				return changed, fmt.Errorf("failed to find order: %v", err)
+			}
+			if order.Status != model.OrderStatusCancelled {
+				return changed, nil
+			}
+			slog.Warn("Refunding payment captured for a cancelled order",
+				slog.String("payment_id", payment.ID.String()),
+				slog.String("order_id", order.ID.String()),
+			)
+			call = s.provider.Refund
+		default:
+			return changed, nil
+		}
+
+		state, err := call(ctx, payment.Ref())
+		if err != nil {
+			return changed, fmt.Errorf("%w: %v", service.ErrPaymentProviderError, err)
+		}
+		next, moved, err := s.record(ctx, payment.ID, state)
+		if err != nil {
+			return changed, err
+		}
+		if !moved {
+			return changed, nil
+		}
+		changed = true
+		payment = next
+	}
+}
+
+// apply moves a locked payment to the state its provider reports and
+// reports whether it changed. States the payment cannot move to are
+// ignored, which makes replayed and out-of-order events harmless. It
+// does not call the provider; followUp does once the transaction is over.
+func (s *paymentService) apply(ctx context.Context, payment *model.Payment, state *model.ProviderPayment) (bool, error) {
+	if state.Status == payment.Status {
+		return false, nil
+	}
+	if !payment.CanTransition(state.Status) {
+		slog.Warn("Ignoring payment state change",
+			slog.String("payment_id", payment.ID.String()),
+			slog.String("status", payment.Status),
+			slog.String("provider_status", state.Status),
+		)
+		return false, nil
+	}
+
+	now := time.Now()
+	payment.Status = state.Status
+	payment.UpdatedAt = now
+	switch state.Status {
+	case model.PaymentStatusFailed:
+		payment.FailureReason = state.FailureReason
+	case model.PaymentStatusCaptured:
+		payment.CapturedAt = &now
+	case model.PaymentStatusRefunded:
+		payment.RefundedAt = &now
+	}
+	if err := s.repo.Update(ctx, payment); err != nil {
+		return false, fmt.Errorf("failed to update payment: %v", err)
+	}
+	if err := s.updateOrder(ctx, payment); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// updateOrder follows a payment's new state on its order. A payment
+// captured for an order cancelled in the meantime leaves the order
+// cancelled; followUp refunds it.
+func (s *paymentService) updateOrder(ctx context.Context, payment *model.Payment) error {
+	var status string
+	switch payment.Status {
+	case model.PaymentStatusCaptured:
+		status = model.OrderStatusPaid
+	case model.PaymentStatusRefunded:
+		status = model.OrderStatusRefunded
+	default:
+		return nil
+	}
+
+	order, err := s.orderRepo.FindByIDForUpdate(ctx, payment.OrderID)
+	if err != nil {
+		return fmt.Errorf("failed to find order: %v", err)
+	}
+	if !order.CanTransition(status) {
+		return nil
+	}
+	_, err = s.orderService.UpdateOrderStatus(ctx, order.ID.String(), &model.OrderStatusRequest{Status: status})
+	return err
+}
+
+// find gets a payment by its ID in string form
+func (s *paymentService) find(ctx context.Context, id string) (*model.Payment, error) {
+	paymentID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidPaymentID, err)
+	}
+
+	payment, err := s.repo.FindByID(ctx, paymentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("failed to find payment: %v", err)
+	}
+	return payment, nil
+}
@@ -0,0 +1,593 @@
+package payment_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+type fakePayments struct {
+	repository.IPaymentRepository
+	payments map[uuid.UUID]*model.Payment
+	events   map[string]bool
+	orders   *fakeOrders
+}
+
+func (r *fakePayments) Create(ctx context.Context, payment *model.Payment) error {
+	copied := *payment
+	r.payments[payment.ID] = &copied
+	return nil
+}
+
+func (r *fakePayments) FindByID(ctx context.Context, id uuid.UUID) (*model.Payment, error) {
+	payment, ok := r.payments[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *payment
+	return &copied, nil
+}
+
+func (r *fakePayments) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Payment, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (r *fakePayments) FindByProviderRef(ctx context.Context, provider, ref string) (*model.Payment, error) {
+	for _, payment := range r.payments {
+		if payment.Provider == provider && payment.Ref() == ref {
+			return r.FindByID(ctx, payment.ID)
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakePayments) FindActiveByOrderID(ctx context.Context, orderID uuid.UUID) (*model.Payment, error) {
+	for _, payment := range r.payments {
+		if payment.OrderID == orderID && payment.Active() {
+			return r.FindByID(ctx, payment.ID)
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakePayments) CountByOrderID(ctx context.Context, orderID uuid.UUID) (int64, error) {
+	var count int64
+	for _, payment := range r.payments {
+		if payment.OrderID == orderID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// FindStuck ignores the time, as every payment of a test is recent
+func (r *fakePayments) FindStuck(ctx context.Context, before time.Time, limit int) ([]*model.Payment, error) {
+	var payments []*model.Payment
+	for _, payment := range r.payments {
+		cancelled := r.orders.orders[payment.OrderID].Status == model.OrderStatusCancelled
+		if payment.Active() || (payment.Status == model.PaymentStatusCaptured && cancelled) {
+			copied := *payment
+			payments = append(payments, &copied)
+		}
+	}
+	return payments[:min(limit, len(payments))], nil
+}
+
+func (r *fakePayments) Update(ctx context.Context, payment *model.Payment) error {
+	copied := *payment
+	r.payments[payment.ID] = &copied
+	return nil
+}
+
+func (r *fakePayments) RecordEvent(ctx context.Context, event *model.PaymentEvent) (bool, error) {
+	if r.events[event.ID] {
+		return false, nil
+	}
+	r.events[event.ID] = true
+	return true, nil
+}
+
+type fakeOrders struct {
+	repository.IOrderRepository
+	orders map[uuid.UUID]*model.Order
+}
+
+func (r *fakeOrders) FindByID(ctx context.Context, id uuid.UUID) (*model.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (r *fakeOrders) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Order, error) {
+	return r.FindByID(ctx, id)
+}
+
+// fakeOrderService moves orders through their state machine without
+// touching stock
+type fakeOrderService struct {
+	service.IOrderService
+	orders *fakeOrders
+}
+
+func (s *fakeOrderService) UpdateOrderStatus(ctx context.Context, id string, req *model.OrderStatusRequest) (*model.OrderResponse, error) {
+	order := s.orders.orders[uuid.MustParse(id)]
+	if !order.CanTransition(req.Status) {
+		return nil, service.ErrInvalidOrderTransition
+	}
+	order.SetStatus(req.Status, time.Now())
+	return order.ToDTO(), nil
+}
+
+// paymentEnv is a payment service over fakes with one pending order
+type paymentEnv struct {
+	provider *FakeProvider
+	payments *fakePayments
+	orders   *fakeOrders
+	service  service.IPaymentService
+	order    *model.Order
+	owner    context.Context
+}
+
+func newPaymentEnv(t *testing.T, autoApprove bool) *paymentEnv {
+	t.Helper()
+	userID := uuid.New()
+	order := &model.Order{
+		ID:       uuid.New(),
+		UserID:   userID,
+		Status:   model.OrderStatusPending,
+		Currency: "EUR",
+		Total:    decimal.RequireFromString("19.99"),
+		Items:    []*model.OrderItem{{BookID: uuid.New(), Quantity: 1}},
+	}
+	orders := &fakeOrders{orders: map[uuid.UUID]*model.Order{order.ID: order}}
+	env := &paymentEnv{
+		provider: NewFakeProvider("secret", autoApprove),
+		payments: &fakePayments{payments: map[uuid.UUID]*model.Payment{}, events: map[string]bool{}, orders: orders},
+		orders:   orders,
+		order:    order,
+		owner:    utils.WithCurrentUser(context.Background(), userID.String(), "user"),
+	}
+	env.service = NewPaymentService(env.payments, orders, &fakeOrderService{orders: orders}, env.provider, repotest.Transactor{}, time.Minute)
+	return env
+}
+
+// webhook delivers a signed event reporting status, with the failure
+// reason the provider holds for the payment
+func (e *paymentEnv) webhook(t *testing.T, eventID string, payment *model.PaymentResponse, status string) error {
+	t.Helper()
+	ref := e.payments.payments[payment.ID].Ref()
+	state, err := e.provider.GetPayment(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("GetPayment() error = %v", err)
+	}
+	var event fakeEvent
+	event.ID, event.Type = eventID, "payment.updated"
+	event.Data.Ref, event.Data.Status, event.Data.FailureReason = ref, status, state.FailureReason
+	payload, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to encode event: %v", err)
+	}
+	header := http.Header{}
+	header.Set(FakeSignatureHeader, e.provider.SignWebhook(payload, time.Now()))
+	return e.service.HandleWebhook(context.Background(), payload, header)
+}
+
+func TestPaymentStateMachine(t *testing.T) {
+	type step struct {
+		// action is approve, decline, cancel (the order), event, refund or capture
+		action  string
+		eventID string
+		status  string
+		wantErr error
+	}
+
+	tests := []struct {
+		name          string
+		steps         []step
+		wantPayment   string
+		wantOrder     string
+		wantProvider  string
+		wantCaptured  bool
+		wantRefunded  bool
+		wantFailedWhy string
+	}{
+		{
+			name:         "authorized payments are captured and pay the order",
+			steps:        []step{{action: "approve"}, {action: "event", eventID: "evt_1", status: model.PaymentStatusAuthorized}},
+			wantPayment:  model.PaymentStatusCaptured,
+			wantOrder:    model.OrderStatusPaid,
+			wantProvider: model.PaymentStatusCaptured,
+			wantCaptured: true,
+		},
+		{
+			name:          "declined payments fail and leave the order pending",
+			steps:         []step{{action: "decline"}, {action: "event", eventID: "evt_1", status: model.PaymentStatusFailed}},
+			wantPayment:   model.PaymentStatusFailed,
+			wantOrder:     model.OrderStatusPending,
+			wantProvider:  model.PaymentStatusFailed,
+			wantFailedWhy: "card declined",
+		},
+		{
+			name: "redelivered events are acknowledged once",
+			steps: []step{
+				{action: "decline"},
+				{action: "event", eventID: "evt_1", status: model.PaymentStatusFailed},
+				{action: "event", eventID: "evt_1", status: model.PaymentStatusFailed},
+			},
+			wantPayment:   model.PaymentStatusFailed,
+			wantOrder:     model.OrderStatusPending,
+			wantProvider:  model.PaymentStatusFailed,
+			wantFailedWhy: "card declined",
+		},
+		{
+			name: "late events do not move payments back",
+			steps: []step{
+				{action: "approve"},
+				{action: "event", eventID: "evt_2", status: model.PaymentStatusAuthorized},
+				{action: "event", eventID: "evt_1", status: model.PaymentStatusPending},
+				{action: "event", eventID: "evt_3", status: model.PaymentStatusFailed},
+			},
+			wantPayment:  model.PaymentStatusCaptured,
+			wantOrder:    model.OrderStatusPaid,
+			wantProvider: model.PaymentStatusCaptured,
+			wantCaptured: true,
+		},
+		{
+			name: "payments captured for a cancelled order are refunded",
+			steps: []step{
+				{action: "approve"},
+				{action: "cancel"},
+				{action: "event", eventID: "evt_1", status: model.PaymentStatusAuthorized},
+			},
+			wantPayment:  model.PaymentStatusRefunded,
+			wantOrder:    model.OrderStatusCancelled,
+			wantProvider: model.PaymentStatusRefunded,
+			wantCaptured: true,
+			wantRefunded: true,
+		},
+		{
+			name: "refunds refund the order",
+			steps: []step{
+				{action: "approve"},
+				{action: "event", eventID: "evt_1", status: model.PaymentStatusAuthorized},
+				{action: "refund"},
+			},
+			wantPayment:  model.PaymentStatusRefunded,
+			wantOrder:    model.OrderStatusRefunded,
+			wantProvider: model.PaymentStatusRefunded,
+			wantCaptured: true,
+			wantRefunded: true,
+		},
+		{
+			name: "uncaptured payments cannot be refunded or captured",
+			steps: []step{
+				{action: "refund", wantErr: service.ErrInvalidPaymentTransition},
+				{action: "capture", wantErr: service.ErrInvalidPaymentTransition},
+			},
+			wantPayment:  model.PaymentStatusPending,
+			wantOrder:    model.OrderStatusPending,
+			wantProvider: model.PaymentStatusPending,
+		},
+		{
+			name: "a failed capture leaves the payment authorized for the reconciler",
+			steps: []step{
+				{action: "event", eventID: "evt_1", status: model.PaymentStatusAuthorized},
+			},
+			wantPayment:  model.PaymentStatusAuthorized,
+			wantOrder:    model.OrderStatusPending,
+			wantProvider: model.PaymentStatusPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newPaymentEnv(t, false)
+			payment, err := env.service.CreatePayment(env.owner, env.order.ID.String())
+			if err != nil {
+				t.Fatalf("CreatePayment() error = %v", err)
+			}
+			ref := env.payments.payments[payment.ID].Ref()
+
+			for i, step := range tt.steps {
+				switch step.action {
+				case "approve":
+					_, err = env.provider.Approve(ref)
+				case "decline":
+					_, err = env.provider.Decline(ref, "card declined")
+				case "cancel":
+					env.order.SetStatus(model.OrderStatusCancelled, time.Now())
+				case "event":
+					err = env.webhook(t, step.eventID, payment, step.status)
+				case "refund":
+					_, err = env.service.RefundPayment(context.Background(), payment.ID.String())
+				case "capture":
+					_, err = env.service.CapturePayment(context.Background(), payment.ID.String())
+				}
+				if !errors.Is(err, step.wantErr) {
+					t.Fatalf("step %d (%s): error = %v, want %v", i, step.action, err, step.wantErr)
+				}
+			}
+
+			saved := env.payments.payments[payment.ID]
+			if saved.Status != tt.wantPayment {
+				t.Errorf("payment status = %s, want %s", saved.Status, tt.wantPayment)
+			}
+			if env.order.Status != tt.wantOrder {
+				t.Errorf("order status = %s, want %s", env.order.Status, tt.wantOrder)
+			}
+			if state, _ := env.provider.GetPayment(context.Background(), ref); state.Status != tt.wantProvider {
+				t.Errorf("provider status = %s, want %s", state.Status, tt.wantProvider)
+			}
+			if (saved.CapturedAt != nil) != tt.wantCaptured || (saved.RefundedAt != nil) != tt.wantRefunded {
+				t.Errorf("captured at %v, refunded at %v", saved.CapturedAt, saved.RefundedAt)
+			}
+			if saved.FailureReason != tt.wantFailedWhy {
+				t.Errorf("failure reason = %q, want %q", saved.FailureReason, tt.wantFailedWhy)
+			}
+		})
+	}
+}
+
+func TestHandleWebhookSignature(t *testing.T) {
+	env := newPaymentEnv(t, false)
+	payload := []byte(`{"id":"evt_1","type":"payment.updated","data":{"ref":"fake_pi_1","status":"captured"}}`)
+
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{name: "missing", header: ""},
+		{name: "wrong secret", header: NewFakeProvider("other", false).SignWebhook(payload, time.Now())},
+		{name: "too old", header: env.provider.SignWebhook(payload, time.Now().Add(-time.Hour))},
+	}
+
+	for _, tt := range tests {
+		header := http.Header{}
+		header.Set(FakeSignatureHeader, tt.header)
+		if err := env.service.HandleWebhook(context.Background(), payload, header); !errors.Is(err, service.ErrInvalidWebhookSignature) {
+			t.Errorf("%s: HandleWebhook() error = %v, want ErrInvalidWebhookSignature", tt.name, err)
+		}
+	}
+}
+
+func TestCreatePayment(t *testing.T) {
+	env := newPaymentEnv(t, false)
+
+	first, err := env.service.CreatePayment(env.owner, env.order.ID.String())
+	if err != nil {
+		t.Fatalf("CreatePayment() error = %v", err)
+	}
+	if !first.Amount.Equal(env.order.Total) || first.Status != model.PaymentStatusPending || first.ClientSecret == "" {
+		t.Errorf("CreatePayment() = %+v", first)
+	}
+
+	// A retried request gets the same payment back
+	again, err := env.service.CreatePayment(env.owner, env.order.ID.String())
+	if err != nil || again.ID != first.ID {
+		t.Errorf("retried CreatePayment() = %v, %v, want payment %s", again, err, first.ID)
+	}
+
+	stranger := utils.WithCurrentUser(context.Background(), uuid.NewString(), "user")
+	if _, err := env.service.CreatePayment(stranger, env.order.ID.String()); !errors.Is(err, service.ErrOrderNotFound) {
+		t.Errorf("CreatePayment() by another user error = %v, want ErrOrderNotFound", err)
+	}
+
+	env.order.SetStatus(model.OrderStatusCancelled, time.Now())
+	if _, err := env.service.CreatePayment(env.owner, env.order.ID.String()); !errors.Is(err, service.ErrOrderNotPayable) {
+		t.Errorf("CreatePayment() of a cancelled order error = %v, want ErrOrderNotPayable", err)
+	}
+}
+
+// slowProvider takes a while to start payments, as a provider across the
+// network does
+type slowProvider struct {
+	*FakeProvider
+}
+
+func (p slowProvider) CreateIntent(ctx context.Context, intent *model.PaymentIntent) (*model.ProviderPayment, error) {
+	time.Sleep(10 * time.Millisecond)
+	return p.FakeProvider.CreateIntent(ctx, intent)
+}
+
+func TestCreatePaymentConcurrently(t *testing.T) {
+	env := newPaymentEnv(t, false)
+	s := NewPaymentService(env.payments, env.orders, &fakeOrderService{orders: env.orders}, slowProvider{env.provider}, &repotest.LockingTransactor{}, time.Minute)
+
+	const checkouts = 8
+	ids := make([]uuid.UUID, checkouts)
+	errs := make([]error, checkouts)
+	var wg sync.WaitGroup
+	for i := 0; i < checkouts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			payment, err := s.CreatePayment(env.owner, env.order.ID.String())
+			if err == nil {
+				ids[i] = payment.ID
+			}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range ids {
+		if errs[i] != nil {
+			t.Fatalf("CreatePayment() error = %v", errs[i])
+		}
+		if ids[i] != ids[0] {
+			t.Errorf("CreatePayment() made payments %s and %s for one order", ids[0], ids[i])
+		}
+	}
+	if len(env.payments.payments) != 1 || len(env.provider.payments) != 1 {
+		t.Errorf("made %d payments and %d provider intents, want 1 of each", len(env.payments.payments), len(env.provider.payments))
+	}
+}
+
+// flakyProvider fails to start intents while down, as a provider that
+// cannot be reached does
+type flakyProvider struct {
+	*FakeProvider
+	down bool
+}
+
+func (p *flakyProvider) CreateIntent(ctx context.Context, intent *model.PaymentIntent) (*model.ProviderPayment, error) {
+	if p.down {
+		return nil, errors.New("connection refused")
+	}
+	return p.FakeProvider.CreateIntent(ctx, intent)
+}
+
+func TestCreatePaymentProviderDown(t *testing.T) {
+	env := newPaymentEnv(t, false)
+	provider := &flakyProvider{FakeProvider: env.provider, down: true}
+	s := NewPaymentService(env.payments, env.orders, &fakeOrderService{orders: env.orders}, provider, repotest.Transactor{}, time.Minute)
+
+	if _, err := s.CreatePayment(env.owner, env.order.ID.String()); !errors.Is(err, service.ErrPaymentProviderError) {
+		t.Fatalf("CreatePayment() error = %v, want ErrPaymentProviderError", err)
+	}
+	if len(env.payments.payments) != 1 {
+		t.Fatalf("made %d payments, want the one reserved", len(env.payments.payments))
+	}
+	for _, reserved := range env.payments.payments {
+		if reserved.ProviderRef != nil || reserved.Status != model.PaymentStatusPending || reserved.Attempt != 1 {
+			t.Errorf("reserved payment = %+v, want pending attempt 1 without an intent", reserved)
+		}
+	}
+
+	// The retry starts the intent of the reserved payment
+	provider.down = false
+	payment, err := s.CreatePayment(env.owner, env.order.ID.String())
+	if err != nil {
+		t.Fatalf("retried CreatePayment() error = %v", err)
+	}
+	if len(env.payments.payments) != 1 || payment.ProviderRef == "" || payment.ClientSecret == "" {
+		t.Errorf("retried CreatePayment() = %+v with %d payments, want the reserved one started", payment, len(env.payments.payments))
+	}
+	if ref, ok := env.provider.intents["order-"+env.order.ID.String()+"-1"]; !ok || ref != payment.ProviderRef {
+		t.Errorf("provider intents = %v, want %s keyed by the order and attempt", env.provider.intents, payment.ProviderRef)
+	}
+}
+
+func TestReconcilePaymentsAfterProviderRestart(t *testing.T) {
+	env := newPaymentEnv(t, true)
+	payment, err := env.service.CreatePayment(env.owner, env.order.ID.String())
+	if err != nil {
+		t.Fatalf("CreatePayment() error = %v", err)
+	}
+
+	// The fake provider forgets its payments when the process restarts
+	restarted := NewFakeProvider("secret", true)
+	s := NewPaymentService(env.payments, env.orders, &fakeOrderService{orders: env.orders}, restarted, repotest.Transactor{}, time.Minute)
+
+	result, err := s.ReconcilePayments(context.Background())
+	if err != nil {
+		t.Fatalf("ReconcilePayments() error = %v", err)
+	}
+	if *result != (model.PaymentReconciliation{Checked: 1, Resolved: 1}) {
+		t.Errorf("ReconcilePayments() = %+v, want one resolved", result)
+	}
+	saved := env.payments.payments[payment.ID]
+	if saved.Status != model.PaymentStatusCaptured || saved.Ref() == payment.ProviderRef {
+		t.Errorf("payment %s with ref %s, want captured through a new intent", saved.Status, saved.Ref())
+	}
+}
+
+func TestFakeProviderIdempotency(t *testing.T) {
+	provider := NewFakeProvider("secret", false)
+	intent := &model.PaymentIntent{Amount: decimal.RequireFromString("5"), Currency: "EUR", IdempotencyKey: "payment-1"}
+
+	first, err := provider.CreateIntent(context.Background(), intent)
+	if err != nil {
+		t.Fatalf("CreateIntent() error = %v", err)
+	}
+	again, err := provider.CreateIntent(context.Background(), intent)
+	if err != nil || again.Ref != first.Ref || again.ClientSecret != first.ClientSecret {
+		t.Errorf("repeated CreateIntent() = %+v, %v, want intent %s", again, err, first.Ref)
+	}
+
+	intent.IdempotencyKey = "payment-2"
+	if other, err := provider.CreateIntent(context.Background(), intent); err != nil || other.Ref == first.Ref {
+		t.Errorf("CreateIntent() with another key = %+v, %v, want a new intent", other, err)
+	}
+}
+
+func TestReconcilePayments(t *testing.T) {
+	// The provider approves payments as soon as they are looked up
+	env := newPaymentEnv(t, true)
+	payment, err := env.service.CreatePayment(env.owner, env.order.ID.String())
+	if err != nil {
+		t.Fatalf("CreatePayment() error = %v", err)
+	}
+
+	result, err := env.service.ReconcilePayments(context.Background())
+	if err != nil {
+		t.Fatalf("ReconcilePayments() error = %v", err)
+	}
+	if *result != (model.PaymentReconciliation{Checked: 1, Resolved: 1}) {
+		t.Errorf("ReconcilePayments() = %+v, want one resolved", result)
+	}
+	if status := env.payments.payments[payment.ID].Status; status != model.PaymentStatusCaptured || env.order.Status != model.OrderStatusPaid {
+		t.Errorf("payment %s, order %s, want captured and paid", status, env.order.Status)
+	}
+
+	// The order is cancelled after its payment was captured
+	env.order.SetStatus(model.OrderStatusCancelled, time.Now())
+	if result, err = env.service.ReconcilePayments(context.Background()); err != nil {
+		t.Fatalf("ReconcilePayments() error = %v", err)
+	}
+	if *result != (model.PaymentReconciliation{Checked: 1, Resolved: 1}) {
+		t.Errorf("ReconcilePayments() = %+v, want one resolved", result)
+	}
+	if status := env.payments.payments[payment.ID].Status; status != model.PaymentStatusRefunded {
+		t.Errorf("payment %s, want refunded", status)
+	}
+
+	if result, err = env.service.ReconcilePayments(context.Background()); err != nil || result.Checked != 0 {
+		t.Errorf("ReconcilePayments() = %+v, %v, want nothing left to check", result, err)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		provider  string
+		allowFake bool
+		wantErr   bool
+	}{
+		{name: "none configured", provider: "", allowFake: true, wantErr: true},
+		{name: "fake not allowed", provider: "fake", wantErr: true},
+		{name: "fake allowed", provider: "fake", allowFake: true},
+		{name: "unknown", provider: "paypal", allowFake: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewProvider(tt.provider, "secret", tt.allowFake)
+			if tt.provider == "" && !errors.Is(err, service.ErrNoPaymentProvider) {
+				t.Errorf("NewProvider(%q) error = %v, want ErrNoPaymentProvider", tt.provider, err)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewProvider(%q, %v) error = %v, want error %v", tt.provider, tt.allowFake, err, tt.wantErr)
+			}
+			if err == nil && provider.Name() != tt.provider {
+				t.Errorf("NewProvider(%q) = %s", tt.provider, provider.Name())
+			}
+		})
+	}
+}
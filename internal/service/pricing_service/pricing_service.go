@@ -0,0 +1,722 @@
+package pricing_service
+
+import (
+	"book_system/internal/infrastructure"
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// Sources of the adjustments in a price quote
+const (
+	sourceRule   = "rule"
+	sourceCoupon = "coupon"
+)
+
+type pricingService struct {
+	ruleRepo      repository.IPriceRuleRepository
+	couponRepo    repository.ICouponRepository
+	bookRepo      repository.IBookRepository
+	bookPriceRepo repository.IBookPriceRepository
+	rateRepo      repository.IExchangeRateRepository
+	userRepo      repository.IUserRepository
+	currency      string
+}
+
+// NewPricingService creates a new pricing service. currency is the
+// catalog currency: the currency of book prices that do not name one, and
+// the default for price rules, coupons and quotes.
+func NewPricingService(
+	ruleRepo repository.IPriceRuleRepository,
+	couponRepo repository.ICouponRepository,
+	bookRepo repository.IBookRepository,
+	bookPriceRepo repository.IBookPriceRepository,
+	rateRepo repository.IExchangeRateRepository,
+	userRepo repository.IUserRepository,
+	currency string,
+) service.IPricingService {
+	return &pricingService{
+		ruleRepo:      ruleRepo,
+		couponRepo:    couponRepo,
+		bookRepo:      bookRepo,
+		bookPriceRepo: bookPriceRepo,
+		rateRepo:      rateRepo,
+		userRepo:      userRepo,
+		currency:      currency,
+	}
+}
+
+// CreatePriceRule creates a new price rule
+func (s *pricingService) CreatePriceRule(ctx context.Context, req *model.PriceRuleRequest) (*model.PriceRuleResponse, error) {
+	now := time.Now()
+	rule := &model.PriceRule{
+		ID:        uuid.New(),
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.applyRule(rule, req)
+
+	if err := s.ruleRepo.Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to create price rule: %v", err)
+	}
+
+	return rule.ToDTO(), nil
+}
+
+// GetPriceRule gets a price rule by ID
+func (s *pricingService) GetPriceRule(ctx context.Context, id string) (*model.PriceRuleResponse, error) {
+	rule, err := s.findRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return rule.ToDTO(), nil
+}
+
+// ListPriceRules gets a paginated list of price rules, newest first
+func (s *pricingService) ListPriceRules(ctx context.Context, page, pageSize int, filters map[string]any) (*model.PriceRuleListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	rules, total, err := s.ruleRepo.FindAll(ctx, page, pageSize, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list price rules: %v", err)
+	}
+
+	ruleDTOs := make([]*model.PriceRuleResponse, len(rules))
+	for i, rule := range rules {
+		ruleDTOs[i] = rule.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.PriceRuleListResponse{
+		Data: ruleDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// UpdatePriceRule replaces the editable fields of a price rule
+func (s *pricingService) UpdatePriceRule(ctx context.Context, id string, req *model.PriceRuleRequest) (*model.PriceRuleResponse, error) {
+	rule, err := s.findRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.applyRule(rule, req)
+	rule.UpdatedAt = time.Now()
+	if err := s.ruleRepo.Update(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to update price rule: %v", err)
+	}
+
+	return rule.ToDTO(), nil
+}
+
+// DeletePriceRule deletes a price rule. Orders keep the prices they were
+// placed at.
+func (s *pricingService) DeletePriceRule(ctx context.Context, id string) error {
+	rule, err := s.findRule(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.ruleRepo.Delete(ctx, rule.ID); err != nil {
+		return fmt.Errorf("failed to delete price rule: %v", err)
+	}
+	return nil
+}
+
+// CreateCoupon creates a new coupon. Codes are stored upper-case and must
+// be unique.
+func (s *pricingService) CreateCoupon(ctx context.Context, req *model.CouponRequest) (*model.CouponResponse, error) {
+	now := time.Now()
+	coupon := &model.Coupon{
+		ID:        uuid.New(),
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.applyCoupon(ctx, coupon, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.couponRepo.Create(ctx, coupon); err != nil {
+		return nil, fmt.Errorf("failed to create coupon: %v", err)
+	}
+
+	return coupon.ToDTO(), nil
+}
+
+// GetCoupon gets a coupon by ID
+func (s *pricingService) GetCoupon(ctx context.Context, id string) (*model.CouponResponse, error) {
+	coupon, err := s.findCoupon(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return coupon.ToDTO(), nil
+}
+
+// ListCoupons gets a paginated list of coupons, newest first
+func (s *pricingService) ListCoupons(ctx context.Context, page, pageSize int) (*model.CouponListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	coupons, total, err := s.couponRepo.FindAll(ctx, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list coupons: %v", err)
+	}
+
+	couponDTOs := make([]*model.CouponResponse, len(coupons))
+	for i, coupon := range coupons {
+		couponDTOs[i] = coupon.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.CouponListResponse{
+		Data: couponDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// UpdateCoupon replaces the editable fields of a coupon. Its use count is
+// kept.
+func (s *pricingService) UpdateCoupon(ctx context.Context, id string, req *model.CouponRequest) (*model.CouponResponse, error) {
+	coupon, err := s.findCoupon(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyCoupon(ctx, coupon, req); err != nil {
+		return nil, err
+	}
+	coupon.UpdatedAt = time.Now()
+	if err := s.couponRepo.Update(ctx, coupon); err != nil {
+		return nil, fmt.Errorf("failed to update coupon: %v", err)
+	}
+
+	return coupon.ToDTO(), nil
+}
+
+// DeleteCoupon deletes a coupon. Orders keep the discount they were placed
+// with.
+func (s *pricingService) DeleteCoupon(ctx context.Context, id string) error {
+	coupon, err := s.findCoupon(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.couponRepo.Delete(ctx, coupon.ID); err != nil {
+		return fmt.Errorf("failed to delete coupon: %v", err)
+	}
+	return nil
+}
+
+// QuoteBook gets the effective price of one copy of a book in currency
+// under the price rules in force, then under a coupon when couponCode is
+// set. An empty currency falls back to the user's preferred currency and
+// then to the catalog currency.
+func (s *pricingService) QuoteBook(ctx context.Context, bookID, couponCode, currency string) (*model.PriceQuote, error) {
+	book, err := s.findBook(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	currency, err = s.ResolveCurrency(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes, err := s.PriceBooks(ctx, []*model.Book{book}, currency)
+	if err != nil {
+		return nil, err
+	}
+	quote := quotes[book.ID]
+
+	if couponCode != "" {
+		discount, err := s.CouponDiscount(ctx, couponCode, quote.Price, currency)
+		if err != nil {
+			return nil, err
+		}
+		quote.Price = quote.Price.Sub(discount.Amount)
+		quote.Adjustments = append(quote.Adjustments, discount.Adjustment)
+	}
+
+	return quote, nil
+}
+
+// ResolveCurrency picks the currency to price in: the requested one when
+// set, else the current user's preferred currency, else the catalog
+// currency
+func (s *pricingService) ResolveCurrency(ctx context.Context, requested string) (string, error) {
+	if requested != "" {
+		currency := strings.ToUpper(requested)
+		if err := infrastructure.Validate.Var(currency, "iso4217"); err != nil {
+			return "", fmt.Errorf("%w: %s", service.ErrInvalidCurrency, requested)
+		}
+		return currency, nil
+	}
+
+	if userID, err := uuid.Parse(utils.UserIDFromContext(ctx)); err == nil {
+		user, err := s.userRepo.FindByID(ctx, userID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf("failed to find user: %v", err)
+		}
+		if user != nil && user.Currency != "" {
+			return user.Currency, nil
+		}
+	}
+	return s.currency, nil
+}
+
+// PriceBooks gets the effective prices of books in currency under the
+// price rules in force. A book's list price is its price set in currency
+// or else its own price converted with the latest exchange rate. The
+// lowest matching sale price replaces the list price, then the matching
+// discount that takes the most off it applies; discounts do not stack.
+// Rules whose amounts cannot be converted to currency are skipped.
+func (s *pricingService) PriceBooks(ctx context.Context, books []*model.Book, currency string) (map[uuid.UUID]*model.PriceQuote, error) {
+	quotes := make(map[uuid.UUID]*model.PriceQuote, len(books))
+	if len(books) == 0 {
+		return quotes, nil
+	}
+
+	converter, err := s.converter(ctx, books, currency)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.ruleRepo.FindEffective(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find price rules: %v", err)
+	}
+
+	for _, book := range books {
+		quote, err := s.quote(converter, book, rules)
+		if err != nil {
+			return nil, err
+		}
+		quotes[book.ID] = quote
+	}
+	return quotes, nil
+}
+
+// DisplayPrices sets the display price of books to their list price in
+// currency, resolved as by ResolveCurrency. Books whose price cannot be
+// converted are left without one.
+func (s *pricingService) DisplayPrices(ctx context.Context, books []*model.BookResponse, currency string) error {
+	currency, err := s.ResolveCurrency(ctx, currency)
+	if err != nil {
+		return err
+	}
+
+	entities := make([]*model.Book, len(books))
+	for i, book := range books {
+		entities[i] = &model.Book{ID: book.ID, Price: book.Price, Currency: book.Currency}
+	}
+	converter, err := s.converter(ctx, entities, currency)
+	if err != nil {
+		return err
+	}
+
+	for i, book := range books {
+		listPrice, _, err := converter.listPrice(entities[i])
+		if err != nil {
+			continue
+		}
+		book.DisplayPrice = &model.Money{Amount: listPrice, Currency: currency}
+	}
+	return nil
+}
+
+// CouponDiscount checks that a coupon is active, within its window, has
+// uses left and that subtotal in currency reaches its minimum, and works
+// out what it takes off subtotal. It does not count a use; see
+// RedeemCoupon.
+func (s *pricingService) CouponDiscount(ctx context.Context, code string, subtotal decimal.Decimal, currency string) (*model.CouponDiscount, error) {
+	coupon, err := s.couponRepo.FindByCode(ctx, strings.ToUpper(code))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrCouponNotFound
+		}
+		return nil, fmt.Errorf("failed to find coupon: %v", err)
+	}
+
+	if !coupon.Active || !model.InWindow(coupon.StartsAt, coupon.EndsAt, time.Now()) {
+		return nil, fmt.Errorf("%w: %s is not valid at this time", service.ErrCouponNotApplicable, coupon.Code)
+	}
+	if coupon.MaxUses > 0 && coupon.Uses >= coupon.MaxUses {
+		return nil, service.ErrCouponExhausted
+	}
+
+	converter, err := s.converter(ctx, nil, currency)
+	if err != nil {
+		return nil, err
+	}
+	value, minSubtotal := coupon.Value, coupon.MinSubtotal
+	if coupon.Type == model.PriceTypeAmountOff {
+		if value, err = converter.convert(value, coupon.Currency); err != nil {
+			return nil, err
+		}
+	}
+	if minSubtotal, err = converter.convert(minSubtotal, coupon.Currency); err != nil {
+		return nil, err
+	}
+	if subtotal.LessThan(minSubtotal) {
+		return nil, fmt.Errorf("%w: %s needs a subtotal of at least %s %s", service.ErrCouponNotApplicable, coupon.Code, minSubtotal.String(), currency)
+	}
+
+	amount := model.Discount(coupon.Type, value, subtotal, currency)
+	return &model.CouponDiscount{
+		Coupon: coupon,
+		Amount: amount,
+		Adjustment: &model.PriceAdjustment{
+			Source:     sourceCoupon,
+			ID:         coupon.ID,
+			Name:       coupon.Code,
+			Type:       coupon.Type,
+			Value:      value,
+			Amount:     amount,
+			PriceAfter: subtotal.Sub(amount),
+		},
+	}, nil
+}
+
+// RedeemCoupon counts a use of a coupon, failing once its uses run out
+// even under concurrent checkouts
+func (s *pricingService) RedeemCoupon(ctx context.Context, id uuid.UUID) error {
+	ok, err := s.couponRepo.Redeem(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to redeem coupon: %v", err)
+	}
+	if !ok {
+		return service.ErrCouponExhausted
+	}
+	return nil
+}
+
+// ReleaseCoupon gives back a use of a coupon
+func (s *pricingService) ReleaseCoupon(ctx context.Context, id uuid.UUID) error {
+	if err := s.couponRepo.Release(ctx, id); err != nil {
+		return fmt.Errorf("failed to release coupon: %v", err)
+	}
+	return nil
+}
+
+// ListBookPrices gets the list prices a book has in other currencies
+func (s *pricingService) ListBookPrices(ctx context.Context, bookID string) ([]*model.BookPriceResponse, error) {
+	book, err := s.findBook(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	prices, err := s.bookPriceRepo.FindByBookID(ctx, book.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find book prices: %v", err)
+	}
+
+	priceDTOs := make([]*model.BookPriceResponse, len(prices))
+	for i, price := range prices {
+		priceDTOs[i] = price.ToDTO()
+	}
+	return priceDTOs, nil
+}
+
+// SetBookPrice sets the list price of a book in a currency, used instead
+// of converting its own price
+func (s *pricingService) SetBookPrice(ctx context.Context, bookID, currency string, req *model.BookPriceRequest) (*model.BookPriceResponse, error) {
+	book, err := s.findBook(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	price := &model.BookPrice{BookID: book.ID, Currency: currency, Amount: req.Amount}
+	if err := s.bookPriceRepo.Save(ctx, price); err != nil {
+		return nil, fmt.Errorf("failed to save book price: %v", err)
+	}
+	return price.ToDTO(), nil
+}
+
+// DeleteBookPrice removes the list price of a book in a currency, which
+// is then converted from its own price again
+func (s *pricingService) DeleteBookPrice(ctx context.Context, bookID, currency string) error {
+	book, err := s.findBook(ctx, bookID)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.bookPriceRepo.Delete(ctx, book.ID, currency)
+	if err != nil {
+		return fmt.Errorf("failed to delete book price: %v", err)
+	}
+	if !deleted {
+		return service.ErrBookPriceNotFound
+	}
+	return nil
+}
+
+// quote prices a book under the rules in force
+func (s *pricingService) quote(converter *converter, book *model.Book, rules []*model.PriceRule) (*model.PriceQuote, error) {
+	listPrice, rate, err := converter.listPrice(book)
+	if err != nil {
+		return nil, err
+	}
+	quote := &model.PriceQuote{
+		BookID:      book.ID,
+		Currency:    converter.currency,
+		ListPrice:   listPrice,
+		Price:       listPrice,
+		Adjustments: []*model.PriceAdjustment{},
+	}
+	if rate != nil {
+		quote.ExchangeRate = rate.ToDTO()
+	}
+
+	var sale *model.PriceRule
+	var salePrice decimal.Decimal
+	for _, rule := range rules {
+		if rule.Type != model.PriceTypeSalePrice || !rule.Matches(book) {
+			continue
+		}
+		price, err := converter.convert(rule.Value, rule.Currency)
+		if err != nil {
+			continue
+		}
+		if price.LessThan(quote.Price) && (sale == nil || price.LessThan(salePrice)) {
+			sale, salePrice = rule, price
+		}
+	}
+	if sale != nil {
+		adjust(quote, sale, quote.Price.Sub(salePrice))
+	}
+
+	var discount *model.PriceRule
+	var best decimal.Decimal
+	for _, rule := range rules {
+		if rule.Type == model.PriceTypeSalePrice || !rule.Matches(book) {
+			continue
+		}
+		value := rule.Value
+		if rule.Type == model.PriceTypeAmountOff {
+			if value, err = converter.convert(value, rule.Currency); err != nil {
+				continue
+			}
+		}
+		if amount := model.Discount(rule.Type, value, quote.Price, converter.currency); amount.GreaterThan(best) {
+			discount, best = rule, amount
+		}
+	}
+	if discount != nil {
+		adjust(quote, discount, best)
+	}
+
+	return quote, nil
+}
+
+// adjust takes amount off a quote and records the rule that did
+func adjust(quote *model.PriceQuote, rule *model.PriceRule, amount decimal.Decimal) {
+	quote.Price = quote.Price.Sub(amount)
+	quote.Adjustments = append(quote.Adjustments, &model.PriceAdjustment{
+		Source:     sourceRule,
+		ID:         rule.ID,
+		Name:       rule.Name,
+		Type:       rule.Type,
+		Value:      rule.Value,
+		Amount:     amount,
+		PriceAfter: quote.Price,
+	})
+}
+
+// converter loads what it takes to price books in currency: the latest
+// exchange rates and the books' list prices set in currency
+func (s *pricingService) converter(ctx context.Context, books []*model.Book, currency string) (*converter, error) {
+	rates, err := s.rateRepo.FindLatest(ctx, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find exchange rates: %v", err)
+	}
+
+	bookIDs := make([]uuid.UUID, len(books))
+	for i, book := range books {
+		bookIDs[i] = book.ID
+	}
+	prices, err := s.bookPriceRepo.FindByBookIDs(ctx, bookIDs, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find book prices: %v", err)
+	}
+	listPrices := make(map[uuid.UUID]decimal.Decimal, len(prices))
+	for _, price := range prices {
+		listPrices[price.BookID] = price.Amount
+	}
+
+	return &converter{
+		currency:   currency,
+		catalog:    s.currency,
+		rates:      model.NewExchangeRates(rates),
+		listPrices: listPrices,
+	}, nil
+}
+
+// converter turns amounts in any currency into amounts in one currency
+type converter struct {
+	currency   string
+	catalog    string
+	rates      model.ExchangeRates
+	listPrices map[uuid.UUID]decimal.Decimal
+}
+
+// listPrice returns the list price of a book in the converter's currency
+// and the rate it was converted with, if it was
+func (c *converter) listPrice(book *model.Book) (decimal.Decimal, *model.ExchangeRate, error) {
+	if price, ok := c.listPrices[book.ID]; ok {
+		return price, nil, nil
+	}
+	from := book.Currency
+	if from == "" {
+		from = c.catalog
+	}
+	amount, rate, ok := c.rates.Convert(book.Price, from, c.currency)
+	if !ok {
+		return decimal.Zero, nil, fmt.Errorf("%w: %s to %s", service.ErrExchangeRateNotFound, from, c.currency)
+	}
+	return model.RoundMoney(amount, c.currency), rate, nil
+}
+
+// convert converts an amount in a currency, the catalog's when empty, to
+// the converter's currency
+func (c *converter) convert(amount decimal.Decimal, from string) (decimal.Decimal, error) {
+	if from == "" {
+		from = c.catalog
+	}
+	converted, _, ok := c.rates.Convert(amount, from, c.currency)
+	if !ok {
+		return decimal.Zero, fmt.Errorf("%w: %s to %s", service.ErrExchangeRateNotFound, from, c.currency)
+	}
+	return model.RoundMoney(converted, c.currency), nil
+}
+
+// applyRule copies a request onto a price rule. Amounts are in the
+// catalog currency unless the request names one.
+func (s *pricingService) applyRule(rule *model.PriceRule, req *model.PriceRuleRequest) {
+	rule.Name = req.Name
+	rule.Type = req.Type
+	rule.Value = req.Value
+	rule.Currency = req.Currency
+	if rule.Currency == "" {
+		rule.Currency = s.currency
+	}
+	rule.ScopeType = req.ScopeType
+	rule.ScopeValue = req.ScopeValue
+	if req.ScopeType == model.PriceScopeAll {
+		rule.ScopeValue = ""
+	}
+	rule.StartsAt = req.StartsAt
+	rule.EndsAt = req.EndsAt
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+}
+
+// applyCoupon copies a request onto a coupon after checking its code is free
+func (s *pricingService) applyCoupon(ctx context.Context, coupon *model.Coupon, req *model.CouponRequest) error {
+	code := strings.ToUpper(req.Code)
+	taken, err := s.couponRepo.ExistsByCode(ctx, code, coupon.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check coupon code: %v", err)
+	}
+	if taken {
+		return fmt.Errorf("%w: %s", service.ErrCouponCodeTaken, code)
+	}
+
+	coupon.Code = code
+	coupon.Type = req.Type
+	coupon.Currency = req.Currency
+	if coupon.Currency == "" {
+		coupon.Currency = s.currency
+	}
+	coupon.Value = req.Value
+	coupon.MinSubtotal = model.RoundMoney(req.MinSubtotal, coupon.Currency)
+	coupon.MaxUses = req.MaxUses
+	coupon.StartsAt = req.StartsAt
+	coupon.EndsAt = req.EndsAt
+	if req.Active != nil {
+		coupon.Active = *req.Active
+	}
+	return nil
+}
+
+// findBook parses a book ID and loads the book
+func (s *pricingService) findBook(ctx context.Context, id string) (*model.Book, error) {
+	bookID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookID, err)
+	}
+
+	book, err := s.bookRepo.FindByID(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrBookNotFound
+		}
+		return nil, fmt.Errorf("failed to find book: %v", err)
+	}
+	return book, nil
+}
+
+// findRule parses a price rule ID and loads the rule
+func (s *pricingService) findRule(ctx context.Context, id string) (*model.PriceRule, error) {
+	ruleID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidPriceRuleID, err)
+	}
+
+	rule, err := s.ruleRepo.FindByID(ctx, ruleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrPriceRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to find price rule: %v", err)
+	}
+	return rule, nil
+}
+
+// findCoupon parses a coupon ID and loads the coupon
+func (s *pricingService) findCoupon(ctx context.Context, id string) (*model.Coupon, error) {
+	couponID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidCouponID, err)
+	}
+
+	coupon, err := s.couponRepo.FindByID(ctx, couponID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrCouponNotFound
+		}
+		return nil, fmt.Errorf("failed to find coupon: %v", err)
+	}
+	return coupon, nil
+}
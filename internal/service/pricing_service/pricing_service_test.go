@@ -0,0 +1,169 @@
+package pricing_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func dec(value string) decimal.Decimal {
+	return decimal.RequireFromString(value)
+}
+
+func rule(name, kind, value, currency string) *model.PriceRule {
+	return &model.PriceRule{ID: uuid.New(), Name: name, Type: kind, Value: dec(value), Currency: currency, ScopeType: model.PriceScopeAll}
+}
+
+func TestQuote(t *testing.T) {
+	book := &model.Book{ID: uuid.New(), Author: "Frank Herbert", Price: dec("20"), Currency: "EUR"}
+	otherAuthor := rule("other author", model.PriceTypePercentOff, "90", "EUR")
+	otherAuthor.ScopeType, otherAuthor.ScopeValue = model.PriceScopeAuthor, "Jane Austen"
+
+	tests := []struct {
+		name     string
+		currency string
+		// listPrice is a list price set for the book in currency
+		listPrice string
+		rules     []*model.PriceRule
+		wantList  string
+		wantPrice string
+		// wantApplied names the rules applied, in order
+		wantApplied []string
+	}{
+		{
+			name:      "no rules",
+			currency:  "EUR",
+			rules:     nil,
+			wantList:  "20",
+			wantPrice: "20",
+		},
+		{
+			name:     "lowest sale price wins",
+			currency: "EUR",
+			rules: []*model.PriceRule{
+				rule("sale 15", model.PriceTypeSalePrice, "15", "EUR"),
+				rule("sale 12", model.PriceTypeSalePrice, "12", "EUR"),
+				rule("sale 25", model.PriceTypeSalePrice, "25", "EUR"),
+			},
+			wantList:    "20",
+			wantPrice:   "12",
+			wantApplied: []string{"sale 12"},
+		},
+		{
+			name:     "sale price above the list price is ignored",
+			currency: "EUR",
+			rules: []*model.PriceRule{
+				rule("sale 25", model.PriceTypeSalePrice, "25", "EUR"),
+			},
+			wantList:  "20",
+			wantPrice: "20",
+		},
+		{
+			name:     "largest discount applies after the sale price",
+			currency: "EUR",
+			rules: []*model.PriceRule{
+				rule("10% off", model.PriceTypePercentOff, "10", "EUR"),
+				rule("3 off", model.PriceTypeAmountOff, "3", "EUR"),
+				rule("sale 16", model.PriceTypeSalePrice, "16", "EUR"),
+				rule("15% off", model.PriceTypePercentOff, "15", "EUR"),
+			},
+			wantList:    "20",
+			wantPrice:   "13",
+			wantApplied: []string{"sale 16", "3 off"},
+		},
+		{
+			name:     "discounts do not stack",
+			currency: "EUR",
+			rules: []*model.PriceRule{
+				rule("25% off", model.PriceTypePercentOff, "25", "EUR"),
+				rule("4 off", model.PriceTypeAmountOff, "4", "EUR"),
+			},
+			wantList:    "20",
+			wantPrice:   "15",
+			wantApplied: []string{"25% off"},
+		},
+		{
+			name:     "rules out of scope are skipped",
+			currency: "EUR",
+			rules: []*model.PriceRule{
+				otherAuthor,
+				rule("5% off", model.PriceTypePercentOff, "5", "EUR"),
+			},
+			wantList:    "20",
+			wantPrice:   "19",
+			wantApplied: []string{"5% off"},
+		},
+		{
+			name:     "amounts are converted and rounded",
+			currency: "TND",
+			rules: []*model.PriceRule{
+				rule("sale 15 EUR", model.PriceTypeSalePrice, "15", "EUR"),
+				rule("1 USD off", model.PriceTypeAmountOff, "1", "USD"),
+				rule("1 GBP off", model.PriceTypeAmountOff, "1", "GBP"),
+			},
+			wantList:    "66.667",
+			wantPrice:   "46.875",
+			wantApplied: []string{"sale 15 EUR", "1 USD off"},
+		},
+		{
+			name:        "list price set in the currency",
+			currency:    "TND",
+			listPrice:   "60",
+			rules:       []*model.PriceRule{rule("10% off", model.PriceTypePercentOff, "10", "")},
+			wantList:    "60",
+			wantPrice:   "54",
+			wantApplied: []string{"10% off"},
+		},
+	}
+
+	rates := model.NewExchangeRates([]*model.ExchangeRate{
+		{BaseCurrency: "EUR", QuoteCurrency: "TND", Rate: dec("3.33333")},
+		{BaseCurrency: "TND", QuoteCurrency: "USD", Rate: dec("0.32")},
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &converter{currency: tt.currency, catalog: "EUR", rates: rates, listPrices: map[uuid.UUID]decimal.Decimal{}}
+			if tt.listPrice != "" {
+				c.listPrices[book.ID] = dec(tt.listPrice)
+			}
+
+			quote, err := (&pricingService{currency: "EUR"}).quote(c, book, tt.rules)
+			if err != nil {
+				t.Fatalf("quote() error = %v", err)
+			}
+			if !quote.ListPrice.Equal(dec(tt.wantList)) || !quote.Price.Equal(dec(tt.wantPrice)) {
+				t.Errorf("quote() = %s from %s, want %s from %s", quote.Price, quote.ListPrice, tt.wantPrice, tt.wantList)
+			}
+
+			var applied []string
+			price := quote.ListPrice
+			for _, adjustment := range quote.Adjustments {
+				applied = append(applied, adjustment.Name)
+				if price = price.Sub(adjustment.Amount); !price.Equal(adjustment.PriceAfter) {
+					t.Errorf("adjustment %s leaves %s, want %s", adjustment.Name, adjustment.PriceAfter, price)
+				}
+			}
+			if len(applied) != len(tt.wantApplied) {
+				t.Fatalf("applied %q, want %q", applied, tt.wantApplied)
+			}
+			for i := range applied {
+				if applied[i] != tt.wantApplied[i] {
+					t.Errorf("applied %q, want %q", applied, tt.wantApplied)
+				}
+			}
+		})
+	}
+}
+
+func TestQuoteWithoutRate(t *testing.T) {
+	book := &model.Book{ID: uuid.New(), Price: dec("20")}
+	c := &converter{currency: "GBP", catalog: "EUR", rates: model.NewExchangeRates(nil)}
+	if _, err := (&pricingService{}).quote(c, book, nil); !errors.Is(err, service.ErrExchangeRateNotFound) {
+		t.Errorf("quote() error = %v, want ErrExchangeRateNotFound", err)
+	}
+}
@@ -0,0 +1,627 @@
+package reading_list_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// shareTokenBytes is how many random bytes a share token is made of
+const shareTokenBytes = 32
+
+type readingListService struct {
+	repo       repository.IReadingListRepository
+	itemRepo   repository.IReadingListItemRepository
+	bookRepo   repository.IBookRepository
+	transactor repository.ITransactor
+}
+
+// NewReadingListService creates a new reading list service
+func NewReadingListService(
+	repo repository.IReadingListRepository,
+	itemRepo repository.IReadingListItemRepository,
+	bookRepo repository.IBookRepository,
+	transactor repository.ITransactor,
+) service.IReadingListService {
+	return &readingListService{
+		repo:       repo,
+		itemRepo:   itemRepo,
+		bookRepo:   bookRepo,
+		transactor: transactor,
+	}
+}
+
+// ListMyLists gets every reading list of the current user, the built-in
+// lists first, creating those the user does not have yet
+func (s *readingListService) ListMyLists(ctx context.Context) (*model.ReadingListCollection, error) {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lists, err := s.userLists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	listIDs := make([]uuid.UUID, len(lists))
+	for i, list := range lists {
+		listIDs[i] = list.ID
+	}
+	counts, err := s.itemRepo.CountByListIDs(ctx, listIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reading list items: %v", err)
+	}
+
+	listDTOs := make([]*model.ReadingListResponse, len(lists))
+	for i, list := range lists {
+		listDTOs[i] = ownerDTO(list)
+		listDTOs[i].ItemCount = counts[list.ID]
+	}
+	return &model.ReadingListCollection{Data: listDTOs}, nil
+}
+
+// CreateList creates a custom reading list for the current user. A user's
+// lists have unique names.
+func (s *readingListService) CreateList(ctx context.Context, req *model.ReadingListRequest) (*model.ReadingListResponse, error) {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.userLists(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	list := &model.ReadingList{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Kind:      model.ReadingListCustom,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.setName(ctx, list, req.Name); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, list); err != nil {
+		return nil, fmt.Errorf("failed to create reading list: %v", err)
+	}
+
+	return ownerDTO(list), nil
+}
+
+// GetList gets one of the current user's reading lists with its books
+func (s *readingListService) GetList(ctx context.Context, id string) (*model.ReadingListResponse, error) {
+	list, err := s.findList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withItems(ctx, list, ownerDTO(list))
+}
+
+// RenameList renames one of the current user's reading lists, built-in
+// lists included
+func (s *readingListService) RenameList(ctx context.Context, id string, req *model.ReadingListRequest) (*model.ReadingListResponse, error) {
+	list, err := s.findList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.setName(ctx, list, req.Name); err != nil {
+		return nil, err
+	}
+	list.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, list); err != nil {
+		return nil, fmt.Errorf("failed to update reading list: %v", err)
+	}
+
+	return s.withItems(ctx, list, ownerDTO(list))
+}
+
+// DeleteList deletes one of the current user's custom reading lists with
+// the books on it. Built-in lists cannot be deleted.
+func (s *readingListService) DeleteList(ctx context.Context, id string) error {
+	list, err := s.findList(ctx, id)
+	if err != nil {
+		return err
+	}
+	if list.Kind != model.ReadingListCustom {
+		return service.ErrReadingListBuiltIn
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.itemRepo.DeleteByListID(ctx, list.ID); err != nil {
+			return fmt.Errorf("failed to empty reading list: %v", err)
+		}
+		if err := s.repo.Delete(ctx, list.ID); err != nil {
+			return fmt.Errorf("failed to delete reading list: %v", err)
+		}
+		return nil
+	})
+}
+
+// AddItem puts a book at the end of one of the current user's reading
+// lists. A book put on the to read, reading or finished list is taken off
+// the other two.
+func (s *readingListService) AddItem(ctx context.Context, id string, req *model.ReadingListItemRequest) (*model.ReadingListResponse, error) {
+	listID, err := parseListID(id)
+	if err != nil {
+		return nil, err
+	}
+	book, err := s.bookRepo.FindByID(ctx, req.BookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrBookNotFound
+		}
+		return nil, fmt.Errorf("failed to find book: %v", err)
+	}
+
+	var list *model.ReadingList
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		list, err = s.lockList(ctx, listID)
+		if err != nil {
+			return err
+		}
+
+		_, err := s.itemRepo.Find(ctx, list.ID, book.ID)
+		if err == nil {
+			return service.ErrBookOnList
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to find reading list item: %v", err)
+		}
+
+		if list.Shelf() {
+			if err := s.takeOffOtherShelves(ctx, list, book.ID); err != nil {
+				return err
+			}
+		}
+
+		last, err := s.itemRepo.MaxPosition(ctx, list.ID)
+		if err != nil {
+			return fmt.Errorf("failed to find reading list position: %v", err)
+		}
+		now := time.Now()
+		item := &model.ReadingListItem{
+			ListID:    list.ID,
+			BookID:    book.ID,
+			Position:  last + 1,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := s.itemRepo.Create(ctx, item); err != nil {
+			return fmt.Errorf("failed to add book to reading list: %v", err)
+		}
+
+		list.UpdatedAt = now
+		if err := s.repo.Update(ctx, list); err != nil {
+			return fmt.Errorf("failed to update reading list: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withItems(ctx, list, ownerDTO(list))
+}
+
+// RemoveItem takes a book off one of the current user's reading lists
+func (s *readingListService) RemoveItem(ctx context.Context, id, bookID string) (*model.ReadingListResponse, error) {
+	listID, err := parseListID(id)
+	if err != nil {
+		return nil, err
+	}
+	itemBookID, err := uuid.Parse(bookID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookID, err)
+	}
+
+	var list *model.ReadingList
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		list, err = s.lockList(ctx, listID)
+		if err != nil {
+			return err
+		}
+		if _, err := s.findItem(ctx, list.ID, itemBookID); err != nil {
+			return err
+		}
+
+		if err := s.itemRepo.Delete(ctx, []uuid.UUID{list.ID}, itemBookID); err != nil {
+			return fmt.Errorf("failed to remove book from reading list: %v", err)
+		}
+		list.UpdatedAt = time.Now()
+		if err := s.repo.Update(ctx, list); err != nil {
+			return fmt.Errorf("failed to update reading list: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withItems(ctx, list, ownerDTO(list))
+}
+
+// ReorderItems puts the books of one of the current user's reading lists
+// in the order given, which must hold every book on the list exactly once
+func (s *readingListService) ReorderItems(ctx context.Context, id string, req *model.ReadingListOrderRequest) (*model.ReadingListResponse, error) {
+	listID, err := parseListID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var list *model.ReadingList
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		list, err = s.lockList(ctx, listID)
+		if err != nil {
+			return err
+		}
+
+		items, err := s.itemRepo.FindByListID(ctx, list.ID)
+		if err != nil {
+			return fmt.Errorf("failed to find reading list items: %v", err)
+		}
+		if len(req.BookIDs) != len(items) {
+			return fmt.Errorf("%w: the list has %d books, %d were given", service.ErrInvalidListOrder, len(items), len(req.BookIDs))
+		}
+		byBookID := make(map[uuid.UUID]*model.ReadingListItem, len(items))
+		for _, item := range items {
+			byBookID[item.BookID] = item
+		}
+
+		now := time.Now()
+		for i, bookID := range req.BookIDs {
+			item, ok := byBookID[bookID]
+			if !ok {
+				return fmt.Errorf("%w: book %s is not on the list", service.ErrInvalidListOrder, bookID)
+			}
+			if item.Position == i+1 {
+				continue
+			}
+			item.Position = i + 1
+			item.UpdatedAt = now
+			if err := s.itemRepo.Update(ctx, item); err != nil {
+				return fmt.Errorf("failed to update reading list item: %v", err)
+			}
+		}
+
+		list.UpdatedAt = now
+		if err := s.repo.Update(ctx, list); err != nil {
+			return fmt.Errorf("failed to update reading list: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withItems(ctx, list, ownerDTO(list))
+}
+
+// UpdateProgress records how far the current user has read a book on
+// their reading list, as a page or a percentage. A page sets the
+// percentage too, and the other way round, when the book's page count is
+// known.
+func (s *readingListService) UpdateProgress(ctx context.Context, id, bookID string, req *model.ReadingProgressRequest) (*model.ReadingListItemResponse, error) {
+	list, err := s.findList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if list.Kind != model.ReadingListReading {
+		return nil, service.ErrProgressNotTracked
+	}
+	itemBookID, err := uuid.Parse(bookID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookID, err)
+	}
+	if req.Page == nil && req.Percent == nil {
+		return nil, fmt.Errorf("%w: give a page or a percentage", service.ErrInvalidProgress)
+	}
+
+	item, err := s.findItem(ctx, list.ID, itemBookID)
+	if err != nil {
+		return nil, err
+	}
+	book, err := s.bookRepo.FindByID(ctx, item.BookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrBookNotFound
+		}
+		return nil, fmt.Errorf("failed to find book: %v", err)
+	}
+
+	if req.Page != nil {
+		if book.PageCount > 0 && *req.Page > book.PageCount {
+			return nil, fmt.Errorf("%w: the book has %d pages", service.ErrInvalidProgress, book.PageCount)
+		}
+		item.Page = *req.Page
+		if req.Percent == nil && book.PageCount > 0 {
+			item.Percent = item.Page * 100 / book.PageCount
+		}
+	}
+	if req.Percent != nil {
+		item.Percent = *req.Percent
+		if req.Page == nil && book.PageCount > 0 {
+			item.Page = item.Percent * book.PageCount / 100
+		}
+	}
+	item.UpdatedAt = time.Now()
+	if err := s.itemRepo.Update(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to update reading progress: %v", err)
+	}
+
+	return itemDTO(item, book), nil
+}
+
+// ShareList makes one of the current user's reading lists readable by
+// anyone with its share link. Sharing a shared list keeps its link.
+func (s *readingListService) ShareList(ctx context.Context, id string) (*model.ReadingListResponse, error) {
+	list, err := s.findList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if list.ShareToken == nil {
+		token, err := newShareToken()
+		if err != nil {
+			return nil, err
+		}
+		list.ShareToken = &token
+		list.UpdatedAt = time.Now()
+		if err := s.repo.Update(ctx, list); err != nil {
+			return nil, fmt.Errorf("failed to update reading list: %v", err)
+		}
+	}
+
+	return s.withItems(ctx, list, ownerDTO(list))
+}
+
+// UnshareList revokes the share link of one of the current user's reading
+// lists. Sharing it again makes a new link.
+func (s *readingListService) UnshareList(ctx context.Context, id string) (*model.ReadingListResponse, error) {
+	list, err := s.findList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if list.ShareToken != nil {
+		list.ShareToken = nil
+		list.UpdatedAt = time.Now()
+		if err := s.repo.Update(ctx, list); err != nil {
+			return nil, fmt.Errorf("failed to update reading list: %v", err)
+		}
+	}
+
+	return s.withItems(ctx, list, ownerDTO(list))
+}
+
+// GetSharedList gets a shared reading list with its books by its share
+// token, without the token
+func (s *readingListService) GetSharedList(ctx context.Context, token string) (*model.ReadingListResponse, error) {
+	list, err := s.repo.FindByShareToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrReadingListNotFound
+		}
+		return nil, fmt.Errorf("failed to find reading list: %v", err)
+	}
+
+	return s.withItems(ctx, list, list.ToDTO())
+}
+
+// userLists gets the reading lists of a user, creating the built-in lists
+// they do not have yet, built-in lists first
+func (s *readingListService) userLists(ctx context.Context, userID uuid.UUID) ([]*model.ReadingList, error) {
+	lists, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reading lists: %v", err)
+	}
+
+	byKind := make(map[string]*model.ReadingList, len(model.DefaultReadingLists))
+	custom := make([]*model.ReadingList, 0, len(lists))
+	for _, list := range lists {
+		if list.Kind == model.ReadingListCustom {
+			custom = append(custom, list)
+		} else {
+			byKind[list.Kind] = list
+		}
+	}
+
+	result := make([]*model.ReadingList, 0, len(model.DefaultReadingLists)+len(custom))
+	for _, builtIn := range model.DefaultReadingLists {
+		list, ok := byKind[builtIn.Kind]
+		if !ok {
+			now := time.Now()
+			list = &model.ReadingList{
+				ID:        uuid.New(),
+				UserID:    userID,
+				Name:      builtIn.Name,
+				Kind:      builtIn.Kind,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			if err := s.repo.Create(ctx, list); err != nil {
+				return nil, fmt.Errorf("failed to create %s list: %v", builtIn.Kind, err)
+			}
+		}
+		result = append(result, list)
+	}
+	return append(result, custom...), nil
+}
+
+// takeOffOtherShelves takes a book off the user's to read, reading and
+// finished lists other than list
+func (s *readingListService) takeOffOtherShelves(ctx context.Context, list *model.ReadingList, bookID uuid.UUID) error {
+	lists, err := s.repo.FindByUserID(ctx, list.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to find reading lists: %v", err)
+	}
+
+	var shelves []uuid.UUID
+	for _, other := range lists {
+		if other.Shelf() && other.ID != list.ID {
+			shelves = append(shelves, other.ID)
+		}
+	}
+	if len(shelves) == 0 {
+		return nil
+	}
+	if err := s.itemRepo.Delete(ctx, shelves, bookID); err != nil {
+		return fmt.Errorf("failed to move book off other lists: %v", err)
+	}
+	return nil
+}
+
+// withItems adds the books on a reading list to its DTO. Books in the
+// trash are left out but stay on the list.
+func (s *readingListService) withItems(ctx context.Context, list *model.ReadingList, dto *model.ReadingListResponse) (*model.ReadingListResponse, error) {
+	items, err := s.itemRepo.FindByListID(ctx, list.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reading list items: %v", err)
+	}
+
+	bookIDs := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		bookIDs[i] = item.BookID
+	}
+	books, err := s.bookRepo.FindByIDs(ctx, bookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find books: %v", err)
+	}
+	byID := make(map[uuid.UUID]*model.Book, len(books))
+	for _, book := range books {
+		byID[book.ID] = book
+	}
+
+	dto.Items = make([]*model.ReadingListItemResponse, 0, len(items))
+	for _, item := range items {
+		book, ok := byID[item.BookID]
+		if !ok {
+			continue
+		}
+		dto.Items = append(dto.Items, itemDTO(item, book))
+	}
+	dto.ItemCount = len(dto.Items)
+	return dto, nil
+}
+
+// setName names a reading list after checking its owner has no other list
+// by that name
+func (s *readingListService) setName(ctx context.Context, list *model.ReadingList, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("%w: name is empty", service.ErrInvalidReadingListName)
+	}
+
+	taken, err := s.repo.ExistsByName(ctx, list.UserID, name, list.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check reading list name: %v", err)
+	}
+	if taken {
+		return fmt.Errorf("%w: %q", service.ErrReadingListNameTaken, name)
+	}
+
+	list.Name = name
+	return nil
+}
+
+// findList finds one of the current user's reading lists by ID
+func (s *readingListService) findList(ctx context.Context, id string) (*model.ReadingList, error) {
+	listID, err := parseListID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.repo.FindByID(ctx, listID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrReadingListNotFound
+		}
+		return nil, fmt.Errorf("failed to find reading list: %v", err)
+	}
+	if utils.UserIDFromContext(ctx) != list.UserID.String() {
+		return nil, service.ErrReadingListNotFound
+	}
+	return list, nil
+}
+
+// lockList finds one of the current user's reading lists by ID and locks
+// it for the surrounding transaction
+func (s *readingListService) lockList(ctx context.Context, id uuid.UUID) (*model.ReadingList, error) {
+	list, err := s.repo.FindByIDForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrReadingListNotFound
+		}
+		return nil, fmt.Errorf("failed to find reading list: %v", err)
+	}
+	if utils.UserIDFromContext(ctx) != list.UserID.String() {
+		return nil, service.ErrReadingListNotFound
+	}
+	return list, nil
+}
+
+// findItem finds a book on a reading list
+func (s *readingListService) findItem(ctx context.Context, listID, bookID uuid.UUID) (*model.ReadingListItem, error) {
+	item, err := s.itemRepo.Find(ctx, listID, bookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrBookNotOnList
+		}
+		return nil, fmt.Errorf("failed to find reading list item: %v", err)
+	}
+	return item, nil
+}
+
+// ownerDTO converts a reading list to the DTO its owner sees, with its
+// share token
+func ownerDTO(list *model.ReadingList) *model.ReadingListResponse {
+	dto := list.ToDTO()
+	if list.ShareToken != nil {
+		dto.ShareToken = *list.ShareToken
+	}
+	return dto
+}
+
+// itemDTO converts a book on a reading list to its DTO
+func itemDTO(item *model.ReadingListItem, book *model.Book) *model.ReadingListItemResponse {
+	return &model.ReadingListItemResponse{
+		Book:     book.ToDTO(),
+		Position: item.Position,
+		Page:     item.Page,
+		Percent:  item.Percent,
+		AddedAt:  item.CreatedAt,
+	}
+}
+
+// newShareToken makes an unguessable token for a share link
+func newShareToken() (string, error) {
+	b := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to make share token: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// parseListID parses a reading list ID
+func parseListID(id string) (uuid.UUID, error) {
+	listID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %v", service.ErrInvalidReadingListID, err)
+	}
+	return listID, nil
+}
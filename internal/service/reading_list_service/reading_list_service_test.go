@@ -0,0 +1,438 @@
+package reading_list_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type fakeLists struct {
+	repository.IReadingListRepository
+	lists map[uuid.UUID]*model.ReadingList
+}
+
+func (r *fakeLists) Create(ctx context.Context, list *model.ReadingList) error {
+	copied := *list
+	r.lists[list.ID] = &copied
+	return nil
+}
+
+func (r *fakeLists) FindByID(ctx context.Context, id uuid.UUID) (*model.ReadingList, error) {
+	list, ok := r.lists[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *list
+	return &copied, nil
+}
+
+func (r *fakeLists) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.ReadingList, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (r *fakeLists) FindByShareToken(ctx context.Context, token string) (*model.ReadingList, error) {
+	for _, list := range r.lists {
+		if list.ShareToken != nil && *list.ShareToken == token {
+			copied := *list
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeLists) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*model.ReadingList, error) {
+	var lists []*model.ReadingList
+	for _, list := range r.lists {
+		if list.UserID == userID {
+			copied := *list
+			lists = append(lists, &copied)
+		}
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].Name < lists[j].Name })
+	return lists, nil
+}
+
+func (r *fakeLists) ExistsByName(ctx context.Context, userID uuid.UUID, name string, excludeID uuid.UUID) (bool, error) {
+	for _, list := range r.lists {
+		if list.UserID == userID && list.Name == name && list.ID != excludeID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeLists) Update(ctx context.Context, list *model.ReadingList) error {
+	copied := *list
+	r.lists[list.ID] = &copied
+	return nil
+}
+
+// fakeItems keeps the books on every list
+type fakeItems struct {
+	repository.IReadingListItemRepository
+	items []*model.ReadingListItem
+}
+
+func (r *fakeItems) FindByListID(ctx context.Context, listID uuid.UUID) ([]*model.ReadingListItem, error) {
+	var items []*model.ReadingListItem
+	for _, item := range r.items {
+		if item.ListID == listID {
+			copied := *item
+			items = append(items, &copied)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Position < items[j].Position })
+	return items, nil
+}
+
+func (r *fakeItems) Find(ctx context.Context, listID, bookID uuid.UUID) (*model.ReadingListItem, error) {
+	for _, item := range r.items {
+		if item.ListID == listID && item.BookID == bookID {
+			copied := *item
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeItems) CountByListIDs(ctx context.Context, listIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int)
+	for _, item := range r.items {
+		counts[item.ListID]++
+	}
+	return counts, nil
+}
+
+func (r *fakeItems) MaxPosition(ctx context.Context, listID uuid.UUID) (int, error) {
+	last := 0
+	for _, item := range r.items {
+		if item.ListID == listID {
+			last = max(last, item.Position)
+		}
+	}
+	return last, nil
+}
+
+func (r *fakeItems) Create(ctx context.Context, item *model.ReadingListItem) error {
+	r.items = append(r.items, item)
+	return nil
+}
+
+func (r *fakeItems) Update(ctx context.Context, item *model.ReadingListItem) error {
+	for i, stored := range r.items {
+		if stored.ListID == item.ListID && stored.BookID == item.BookID {
+			copied := *item
+			r.items[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (r *fakeItems) Delete(ctx context.Context, listIDs []uuid.UUID, bookID uuid.UUID) error {
+	kept := r.items[:0]
+	for _, item := range r.items {
+		if item.BookID != bookID || !contains(listIDs, item.ListID) {
+			kept = append(kept, item)
+		}
+	}
+	r.items = kept
+	return nil
+}
+
+func contains(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// reader wraps a reading list service with its fakes, acting as one user
+// with three books to put on their lists
+type reader struct {
+	service.IReadingListService
+	ctx   context.Context
+	lists *fakeLists
+	items *fakeItems
+	books []*model.Book
+}
+
+func newReader() *reader {
+	r := &reader{
+		ctx:   utils.WithCurrentUser(context.Background(), uuid.NewString(), "user"),
+		lists: &fakeLists{lists: make(map[uuid.UUID]*model.ReadingList)},
+		items: &fakeItems{},
+	}
+	books := repotest.NewBooks()
+	for _, pageCount := range []int{400, 0, 120} {
+		book := &model.Book{ID: uuid.New(), PageCount: pageCount}
+		r.books = append(r.books, book)
+		books.Put(book)
+	}
+	r.IReadingListService = NewReadingListService(r.lists, r.items, books, repotest.Transactor{})
+	return r
+}
+
+// list returns the ID of the reader's built-in list of a kind
+func (r *reader) list(t *testing.T, kind string) string {
+	t.Helper()
+	lists, err := r.ListMyLists(r.ctx)
+	if err != nil {
+		t.Fatalf("ListMyLists() error = %v", err)
+	}
+	for _, list := range lists.Data {
+		if list.Kind == kind {
+			return list.ID.String()
+		}
+	}
+	t.Fatalf("no %s list", kind)
+	return ""
+}
+
+// add puts books on a list, failing the test on error
+func (r *reader) add(t *testing.T, listID string, books ...*model.Book) {
+	t.Helper()
+	for _, book := range books {
+		if _, err := r.AddItem(r.ctx, listID, &model.ReadingListItemRequest{BookID: book.ID}); err != nil {
+			t.Fatalf("AddItem() error = %v", err)
+		}
+	}
+}
+
+// bookIDs returns the IDs of the books on a list, in order
+func bookIDs(list *model.ReadingListResponse) []uuid.UUID {
+	ids := make([]uuid.UUID, len(list.Items))
+	for i, item := range list.Items {
+		ids[i] = item.Book.ID
+	}
+	return ids
+}
+
+func sameIDs(got, want []uuid.UUID) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestListMyLists(t *testing.T) {
+	r := newReader()
+	if _, err := r.CreateList(r.ctx, &model.ReadingListRequest{Name: " Summer "}); err != nil {
+		t.Fatalf("CreateList() error = %v", err)
+	}
+	if _, err := r.CreateList(r.ctx, &model.ReadingListRequest{Name: "Summer"}); !errors.Is(err, service.ErrReadingListNameTaken) {
+		t.Errorf("CreateList() of a taken name error = %v, want ErrReadingListNameTaken", err)
+	}
+
+	lists, err := r.ListMyLists(r.ctx)
+	if err != nil {
+		t.Fatalf("ListMyLists() error = %v", err)
+	}
+	wantKinds := []string{model.ReadingListWishlist, model.ReadingListToRead, model.ReadingListReading,
+		model.ReadingListFinished, model.ReadingListCustom}
+	if len(lists.Data) != len(wantKinds) {
+		t.Fatalf("ListMyLists() returned %d lists, want %d", len(lists.Data), len(wantKinds))
+	}
+	for i, list := range lists.Data {
+		if list.Kind != wantKinds[i] {
+			t.Errorf("list %d is %s, want %s", i, list.Kind, wantKinds[i])
+		}
+	}
+	// The built-in lists are only created once
+	if len(r.lists.lists) != len(wantKinds) {
+		t.Errorf("stored %d lists, want %d", len(r.lists.lists), len(wantKinds))
+	}
+
+	if err := r.DeleteList(r.ctx, lists.Data[0].ID.String()); !errors.Is(err, service.ErrReadingListBuiltIn) {
+		t.Errorf("DeleteList() of the wishlist error = %v, want ErrReadingListBuiltIn", err)
+	}
+}
+
+func TestAddItem(t *testing.T) {
+	r := newReader()
+	wishlist, toRead, reading := r.list(t, model.ReadingListWishlist), r.list(t, model.ReadingListToRead), r.list(t, model.ReadingListReading)
+	r.add(t, wishlist, r.books[0])
+	r.add(t, toRead, r.books[0], r.books[1])
+
+	list, err := r.AddItem(r.ctx, reading, &model.ReadingListItemRequest{BookID: r.books[0].ID})
+	if err != nil {
+		t.Fatalf("AddItem() error = %v", err)
+	}
+	if !sameIDs(bookIDs(list), []uuid.UUID{r.books[0].ID}) {
+		t.Errorf("reading list = %v", bookIDs(list))
+	}
+
+	// A book on the reading list comes off to read, but not off the wishlist
+	wantLists := map[string][]uuid.UUID{
+		wishlist: {r.books[0].ID},
+		toRead:   {r.books[1].ID},
+	}
+	for id, want := range wantLists {
+		list, err := r.GetList(r.ctx, id)
+		if err != nil {
+			t.Fatalf("GetList() error = %v", err)
+		}
+		if !sameIDs(bookIDs(list), want) {
+			t.Errorf("%s list = %v, want %v", list.Kind, bookIDs(list), want)
+		}
+	}
+
+	if _, err := r.AddItem(r.ctx, reading, &model.ReadingListItemRequest{BookID: r.books[0].ID}); !errors.Is(err, service.ErrBookOnList) {
+		t.Errorf("AddItem() of a book on the list error = %v, want ErrBookOnList", err)
+	}
+	if _, err := r.AddItem(r.ctx, reading, &model.ReadingListItemRequest{BookID: uuid.New()}); !errors.Is(err, service.ErrBookNotFound) {
+		t.Errorf("AddItem() of an unknown book error = %v, want ErrBookNotFound", err)
+	}
+
+	stranger := utils.WithCurrentUser(context.Background(), uuid.NewString(), "user")
+	if _, err := r.AddItem(stranger, reading, &model.ReadingListItemRequest{BookID: r.books[2].ID}); !errors.Is(err, service.ErrReadingListNotFound) {
+		t.Errorf("AddItem() to another user's list error = %v, want ErrReadingListNotFound", err)
+	}
+}
+
+func TestReorderItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   func(books []*model.Book) []uuid.UUID
+		wantErr error
+	}{
+		{
+			name:  "new order",
+			order: func(b []*model.Book) []uuid.UUID { return []uuid.UUID{b[2].ID, b[0].ID, b[1].ID} },
+		},
+		{
+			name:    "a book left out",
+			order:   func(b []*model.Book) []uuid.UUID { return []uuid.UUID{b[2].ID, b[0].ID} },
+			wantErr: service.ErrInvalidListOrder,
+		},
+		{
+			name:    "a book not on the list",
+			order:   func(b []*model.Book) []uuid.UUID { return []uuid.UUID{b[2].ID, b[0].ID, uuid.New()} },
+			wantErr: service.ErrInvalidListOrder,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newReader()
+			wishlist := r.list(t, model.ReadingListWishlist)
+			r.add(t, wishlist, r.books...)
+			order := tt.order(r.books)
+
+			_, err := r.ReorderItems(r.ctx, wishlist, &model.ReadingListOrderRequest{BookIDs: order})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReorderItems() error = %v, want %v", err, tt.wantErr)
+			}
+			// A refused order is rolled back, which the fakes do not do
+			if err != nil {
+				return
+			}
+
+			list, err := r.GetList(r.ctx, wishlist)
+			if err != nil {
+				t.Fatalf("GetList() error = %v", err)
+			}
+			if !sameIDs(bookIDs(list), order) {
+				t.Errorf("list = %v, want %v", bookIDs(list), order)
+			}
+		})
+	}
+}
+
+func TestUpdateProgress(t *testing.T) {
+	page := func(n int) *int { return &n }
+
+	tests := []struct {
+		name string
+		// book is the index of the book read: 0 has 400 pages, 1 an unknown count
+		book        int
+		kind        string
+		req         *model.ReadingProgressRequest
+		wantErr     error
+		wantPage    int
+		wantPercent int
+	}{
+		{name: "page sets the percentage", book: 0, req: &model.ReadingProgressRequest{Page: page(100)}, wantPage: 100, wantPercent: 25},
+		{name: "percentage sets the page", book: 0, req: &model.ReadingProgressRequest{Percent: page(50)}, wantPage: 200, wantPercent: 50},
+		{name: "both given are kept", book: 0, req: &model.ReadingProgressRequest{Page: page(10), Percent: page(90)}, wantPage: 10, wantPercent: 90},
+		{name: "unknown page count", book: 1, req: &model.ReadingProgressRequest{Page: page(1000)}, wantPage: 1000},
+		{name: "past the last page", book: 0, req: &model.ReadingProgressRequest{Page: page(401)}, wantErr: service.ErrInvalidProgress},
+		{name: "nothing given", book: 0, req: &model.ReadingProgressRequest{}, wantErr: service.ErrInvalidProgress},
+		{name: "only on the reading list", book: 0, kind: model.ReadingListToRead, req: &model.ReadingProgressRequest{Page: page(1)}, wantErr: service.ErrProgressNotTracked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newReader()
+			kind := tt.kind
+			if kind == "" {
+				kind = model.ReadingListReading
+			}
+			listID := r.list(t, kind)
+			book := r.books[tt.book]
+			r.add(t, listID, book)
+
+			item, err := r.UpdateProgress(r.ctx, listID, book.ID.String(), tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateProgress() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (item.Page != tt.wantPage || item.Percent != tt.wantPercent) {
+				t.Errorf("progress = page %d, %d%%, want page %d, %d%%", item.Page, item.Percent, tt.wantPage, tt.wantPercent)
+			}
+		})
+	}
+}
+
+func TestShareList(t *testing.T) {
+	r := newReader()
+	wishlist := r.list(t, model.ReadingListWishlist)
+	r.add(t, wishlist, r.books[0])
+
+	shared, err := r.ShareList(r.ctx, wishlist)
+	if err != nil {
+		t.Fatalf("ShareList() error = %v", err)
+	}
+	if !shared.Shared || shared.ShareToken == "" {
+		t.Fatalf("ShareList() = %+v, want a share token", shared)
+	}
+	// Sharing again keeps the link
+	again, err := r.ShareList(r.ctx, wishlist)
+	if err != nil || again.ShareToken != shared.ShareToken {
+		t.Errorf("ShareList() again = %q, %v, want %q", again.ShareToken, err, shared.ShareToken)
+	}
+
+	public, err := r.GetSharedList(context.Background(), shared.ShareToken)
+	if err != nil {
+		t.Fatalf("GetSharedList() error = %v", err)
+	}
+	if public.ShareToken != "" || len(public.Items) != 1 {
+		t.Errorf("GetSharedList() = %+v, want the books without the token", public)
+	}
+
+	if _, err := r.UnshareList(r.ctx, wishlist); err != nil {
+		t.Fatalf("UnshareList() error = %v", err)
+	}
+	if _, err := r.GetSharedList(context.Background(), shared.ShareToken); !errors.Is(err, service.ErrReadingListNotFound) {
+		t.Errorf("GetSharedList() after unsharing error = %v, want ErrReadingListNotFound", err)
+	}
+
+	reshared, err := r.ShareList(r.ctx, wishlist)
+	if err != nil || reshared.ShareToken == shared.ShareToken {
+		t.Errorf("ShareList() after unsharing = %q, %v, want a new token", reshared.ShareToken, err)
+	}
+}
@@ -0,0 +1,601 @@
+package recommendation_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	// cacheKeyPrefix namespaces recommendations in Redis
+	cacheKeyPrefix = "recommendations:"
+	// maxRecommendations is the most books a list of recommendations holds
+	maxRecommendations = 50
+	// popularPoolSize is how many of the most viewed books are kept at hand
+	// to fall back on
+	popularPoolSize = 200
+	// viewsPerUser is how many of a user's most recent views count towards
+	// similarities, bounding the pairs each user adds
+	viewsPerUser = 100
+	// recentViews is how many of a user's most recent views their
+	// recommendations are based on
+	recentViews = 20
+	// batchSize is how many rows the refresh reads at a time
+	batchSize = 1000
+	// usersPerBatch is how many users' views the refresh reads at a time,
+	// up to viewsPerUser each
+	usersPerBatch = 100
+
+	// coViewWeight and contentWeight split a similarity between books
+	// viewed by the same users and books that look alike
+	coViewWeight  = 0.7
+	contentWeight = 0.3
+	// authorWeight, priceWeight and dateWeight split the content
+	// similarity between the same author, a similar price and a close
+	// publication date
+	authorWeight = 0.6
+	priceWeight  = 0.2
+	dateWeight   = 0.2
+	// dateHorizon is how far apart publication dates can be and still
+	// count as close
+	dateHorizon = 5 * 365 * 24 * time.Hour
+)
+
+type recommendationService struct {
+	viewRepo       repository.IBookViewRepository
+	similarityRepo repository.IBookSimilarityRepository
+	bookRepo       repository.IBookRepository
+	transactor     repository.ITransactor
+	cache          *redis.Client
+	cacheTTL       time.Duration
+	neighbours     int
+}
+
+// NewRecommendationService creates a new recommendation service. Each
+// book keeps its closest neighbours similar books, and recommendations
+// are cached in Redis for cacheTTL; cache may be nil to disable caching.
+func NewRecommendationService(
+	viewRepo repository.IBookViewRepository,
+	similarityRepo repository.IBookSimilarityRepository,
+	bookRepo repository.IBookRepository,
+	transactor repository.ITransactor,
+	cache *redis.Client,
+	cacheTTL time.Duration,
+	neighbours int,
+) service.IRecommendationService {
+	return &recommendationService{
+		viewRepo:       viewRepo,
+		similarityRepo: similarityRepo,
+		bookRepo:       bookRepo,
+		transactor:     transactor,
+		cache:          cache,
+		cacheTTL:       cacheTTL,
+		neighbours:     neighbours,
+	}
+}
+
+// cachedList is a list of recommended books as cached in Redis. Only the
+// IDs are cached so the books themselves are always current.
+type cachedList struct {
+	BookIDs []uuid.UUID `json:"book_ids"`
+	Source  string      `json:"source"`
+}
+
+// bookFeatures is what content similarity compares books on
+type bookFeatures struct {
+	author      string
+	price       float64
+	currency    string
+	publishedAt time.Time
+}
+
+// RecordView counts a view of a book's detail page by the current user
+func (s *recommendationService) RecordView(ctx context.Context, bookID uuid.UUID) error {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.viewRepo.Record(ctx, userID, bookID, time.Now()); err != nil {
+		return fmt.Errorf("failed to record book view: %v", err)
+	}
+	return nil
+}
+
+// SimilarBooks gets the books most similar to a book, topped up with the
+// most viewed books when it has too few
+func (s *recommendationService) SimilarBooks(ctx context.Context, bookID string, limit int) (*model.RecommendationListResponse, error) {
+	id, err := uuid.Parse(bookID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookID, err)
+	}
+	book, err := s.bookRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrBookNotFound
+		}
+		return nil, fmt.Errorf("failed to find book: %v", err)
+	}
+
+	key := cacheKeyPrefix + "similar:" + book.ID.String()
+	list := s.cached(ctx, key)
+	if list == nil {
+		similarities, err := s.similarityRepo.FindByBookID(ctx, book.ID, maxRecommendations)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find similar books: %v", err)
+		}
+
+		list = &cachedList{Source: model.RecommendationSimilar}
+		for _, similarity := range similarities {
+			list.BookIDs = append(list.BookIDs, similarity.SimilarBookID)
+		}
+		if len(list.BookIDs) == 0 {
+			list.Source = model.RecommendationPopular
+		}
+		if list.BookIDs, err = s.topUp(ctx, list.BookIDs, book.ID); err != nil {
+			return nil, err
+		}
+		s.store(ctx, key, list)
+	}
+
+	return s.response(ctx, list, limit)
+}
+
+// MyRecommendations gets the books most similar to those the current user
+// viewed lately, leaving out the ones they viewed, topped up with the
+// most viewed books when there are too few
+func (s *recommendationService) MyRecommendations(ctx context.Context, limit int) (*model.RecommendationListResponse, error) {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key := cacheKeyPrefix + "user:" + userID.String()
+	list := s.cached(ctx, key)
+	if list == nil {
+		views, err := s.viewRepo.FindByUserID(ctx, userID, recentViews)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find book views: %v", err)
+		}
+
+		viewed := make([]uuid.UUID, len(views))
+		for i, view := range views {
+			viewed[i] = view.BookID
+		}
+		var similarities []*model.BookSimilarity
+		if len(viewed) > 0 {
+			similarities, err = s.similarityRepo.FindByBookIDs(ctx, viewed)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find similar books: %v", err)
+			}
+		}
+
+		list = &cachedList{Source: model.RecommendationSimilar}
+		list.BookIDs = rank(similarities, viewed)
+		if len(list.BookIDs) == 0 {
+			list.Source = model.RecommendationPopular
+		}
+		if list.BookIDs, err = s.topUp(ctx, list.BookIDs, viewed...); err != nil {
+			return nil, err
+		}
+		s.store(ctx, key, list)
+	}
+
+	return s.response(ctx, list, limit)
+}
+
+// RefreshSimilarities recomputes the similar books of every book. Two
+// books are similar when the same users viewed both, scored by cosine
+// similarity, and when they are alike: by the same author, at a similar
+// price and published around the same time. Besides the books viewed
+// along with it and its author's, a book is compared with the books
+// closest to it in price and in publication date. Books with none of
+// these keep no similar books and fall back on the most viewed ones.
+// Cached recommendations are cleared once the new similarities are in.
+func (s *recommendationService) RefreshSimilarities(ctx context.Context) (*model.SimilarityRefresh, error) {
+	// Whole seconds survive the round trip through the database, so the
+	// stale rows can be told apart from the ones saved below
+	start := time.Now().Truncate(time.Second)
+
+	features := make(map[uuid.UUID]*bookFeatures)
+	byAuthor := make(map[string][]uuid.UUID)
+	byPrice := make(map[string][]uuid.UUID)
+	var byDate []uuid.UUID
+	err := s.bookRepo.FindInBatches(ctx, nil, batchSize, func(books []*model.Book) error {
+		for _, book := range books {
+			author := strings.ToLower(strings.TrimSpace(book.Author))
+			features[book.ID] = &bookFeatures{
+				author:      author,
+				price:       book.Price.InexactFloat64(),
+				currency:    book.Currency,
+				publishedAt: book.PublishedAt,
+			}
+			if author != "" {
+				byAuthor[author] = append(byAuthor[author], book.ID)
+			}
+			if book.Price.IsPositive() {
+				byPrice[book.Currency] = append(byPrice[book.Currency], book.ID)
+			}
+			if !book.PublishedAt.IsZero() {
+				byDate = append(byDate, book.ID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read books: %v", err)
+	}
+
+	priceOrders := make(map[string]*ordering, len(byPrice))
+	for currency, ids := range byPrice {
+		priceOrders[currency] = newOrdering(ids, func(a, b uuid.UUID) bool {
+			return features[a].price < features[b].price
+		})
+	}
+	dateOrder := newOrdering(byDate, func(a, b uuid.UUID) bool {
+		return features[a].publishedAt.Before(features[b].publishedAt)
+	})
+
+	// Only one batch of users' recent views is held at a time; what stays
+	// is the co-view counts, bounded by the pairs of books viewed together
+	viewers := make(map[uuid.UUID]int)
+	coViews := make(map[uuid.UUID]map[uuid.UUID]int)
+	users := 0
+	err = s.viewRepo.FindRecentByUserInBatches(ctx, viewsPerUser, usersPerBatch, func(views []*model.BookView) error {
+		for start := 0; start < len(views); {
+			end := start + 1
+			for end < len(views) && views[end].UserID == views[start].UserID {
+				end++
+			}
+			if addUserViews(views[start:end], features, viewers, coViews) {
+				users++
+			}
+			start = end
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read book views: %v", err)
+	}
+
+	result := &model.SimilarityRefresh{Viewers: users}
+	for bookID, book := range features {
+		scores := make(map[uuid.UUID]float64)
+		for otherID, count := range coViews[bookID] {
+			scores[otherID] += coViewWeight * float64(count) / math.Sqrt(float64(viewers[bookID]*viewers[otherID]))
+		}
+		candidates := [][]uuid.UUID{byAuthor[book.author], dateOrder.around(bookID, s.neighbours)}
+		if order, ok := priceOrders[book.currency]; ok {
+			candidates = append(candidates, order.around(bookID, s.neighbours))
+		}
+		for _, ids := range candidates {
+			for _, otherID := range ids {
+				if _, ok := scores[otherID]; !ok && otherID != bookID {
+					scores[otherID] = 0
+				}
+			}
+		}
+		if len(scores) == 0 {
+			continue
+		}
+
+		similarities := make([]*model.BookSimilarity, 0, len(scores))
+		for otherID, score := range scores {
+			similarities = append(similarities, &model.BookSimilarity{
+				BookID:        bookID,
+				SimilarBookID: otherID,
+				Score:         score + contentWeight*contentSimilarity(book, features[otherID]),
+				CoViews:       coViews[bookID][otherID],
+				UpdatedAt:     start,
+			})
+		}
+		sort.Slice(similarities, func(i, j int) bool {
+			if similarities[i].Score != similarities[j].Score {
+				return similarities[i].Score > similarities[j].Score
+			}
+			return similarities[i].SimilarBookID.String() < similarities[j].SimilarBookID.String()
+		})
+		if len(similarities) > s.neighbours {
+			similarities = similarities[:s.neighbours]
+		}
+
+		err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			return s.similarityRepo.Replace(ctx, bookID, similarities)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to save similar books: %v", err)
+		}
+		result.Books++
+		result.Similarities += len(similarities)
+	}
+
+	if err := s.similarityRepo.DeleteBefore(ctx, start); err != nil {
+		return nil, fmt.Errorf("failed to delete stale similarities: %v", err)
+	}
+	s.forgetAll(ctx)
+
+	return result, nil
+}
+
+// RunRefresher recomputes the similarities between books every interval
+// until ctx is done
+func (s *recommendationService) RunRefresher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.RefreshSimilarities(ctx)
+			if err != nil {
+				slog.Error("Similarity refresh failed", slog.Any("error", err))
+				continue
+			}
+			slog.Info("Refreshed book similarities",
+				slog.Int("books", result.Books),
+				slog.Int("viewers", result.Viewers),
+				slog.Int("similarities", result.Similarities),
+			)
+		}
+	}
+}
+
+// topUp appends the most viewed books to bookIDs, leaving out those in
+// bookIDs or exclude, until it holds maxRecommendations books
+func (s *recommendationService) topUp(ctx context.Context, bookIDs []uuid.UUID, exclude ...uuid.UUID) ([]uuid.UUID, error) {
+	if len(bookIDs) >= maxRecommendations {
+		return bookIDs, nil
+	}
+	popular, err := s.popular(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uuid.UUID]bool, len(bookIDs)+len(exclude))
+	for _, id := range append(bookIDs, exclude...) {
+		seen[id] = true
+	}
+	for _, id := range popular {
+		if len(bookIDs) >= maxRecommendations {
+			break
+		}
+		if !seen[id] {
+			bookIDs = append(bookIDs, id)
+			seen[id] = true
+		}
+	}
+	return bookIDs, nil
+}
+
+// popular gets the IDs of the most viewed books
+func (s *recommendationService) popular(ctx context.Context) ([]uuid.UUID, error) {
+	key := cacheKeyPrefix + "popular"
+	if list := s.cached(ctx, key); list != nil {
+		return list.BookIDs, nil
+	}
+
+	bookIDs, err := s.viewRepo.MostViewed(ctx, popularPoolSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find most viewed books: %v", err)
+	}
+	s.store(ctx, key, &cachedList{BookIDs: bookIDs, Source: model.RecommendationPopular})
+	return bookIDs, nil
+}
+
+// response loads the first limit books of a list of recommendations that
+// are still in the catalog, in list order
+func (s *recommendationService) response(ctx context.Context, list *cachedList, limit int) (*model.RecommendationListResponse, error) {
+	if limit < 1 || limit > maxRecommendations {
+		limit = 10
+	}
+
+	books, err := s.bookRepo.FindByIDs(ctx, list.BookIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find books: %v", err)
+	}
+	byID := make(map[uuid.UUID]*model.Book, len(books))
+	for _, book := range books {
+		byID[book.ID] = book
+	}
+
+	bookDTOs := make([]*model.BookResponse, 0, limit)
+	for _, id := range list.BookIDs {
+		if len(bookDTOs) == limit {
+			break
+		}
+		if book, ok := byID[id]; ok {
+			bookDTOs = append(bookDTOs, book.ToDTO())
+		}
+	}
+	return &model.RecommendationListResponse{Data: bookDTOs, Source: list.Source}, nil
+}
+
+// cached returns a cached list of recommendations, or nil on a miss
+func (s *recommendationService) cached(ctx context.Context, key string) *cachedList {
+	if s.cache == nil {
+		return nil
+	}
+
+	value, err := s.cache.Get(ctx, key).Result()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			slog.Warn("Failed to read recommendation cache", slog.String("key", key), slog.Any("error", err))
+		}
+		return nil
+	}
+
+	var list cachedList
+	if err := json.Unmarshal([]byte(value), &list); err != nil {
+		return nil
+	}
+	return &list
+}
+
+func (s *recommendationService) store(ctx context.Context, key string, list *cachedList) {
+	if s.cache == nil {
+		return
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return
+	}
+	if err := s.cache.Set(ctx, key, data, s.cacheTTL).Err(); err != nil {
+		slog.Warn("Failed to write recommendation cache", slog.String("key", key), slog.Any("error", err))
+	}
+}
+
+// forgetAll clears every cached list of recommendations: the similar
+// books, the users' recommendations and the most viewed books
+func (s *recommendationService) forgetAll(ctx context.Context) {
+	if s.cache == nil {
+		return
+	}
+
+	iter := s.cache.Scan(ctx, 0, cacheKeyPrefix+"*", batchSize).Iterator()
+	keys := make([]string, 0, batchSize)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == batchSize {
+			s.forget(ctx, keys...)
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		slog.Warn("Failed to list recommendation cache", slog.Any("error", err))
+	}
+	if len(keys) > 0 {
+		s.forget(ctx, keys...)
+	}
+}
+
+func (s *recommendationService) forget(ctx context.Context, keys ...string) {
+	if err := s.cache.Del(ctx, keys...).Err(); err != nil {
+		slog.Warn("Failed to clear recommendation cache", slog.Int("keys", len(keys)), slog.Any("error", err))
+	}
+}
+
+// rank orders the books similar to any of the viewed books by their total
+// similarity to them, leaving out the viewed books themselves
+func rank(similarities []*model.BookSimilarity, viewed []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(viewed))
+	for _, id := range viewed {
+		seen[id] = true
+	}
+
+	scores := make(map[uuid.UUID]float64)
+	for _, similarity := range similarities {
+		if !seen[similarity.SimilarBookID] {
+			scores[similarity.SimilarBookID] += similarity.Score
+		}
+	}
+
+	bookIDs := make([]uuid.UUID, 0, len(scores))
+	for id := range scores {
+		bookIDs = append(bookIDs, id)
+	}
+	sort.Slice(bookIDs, func(i, j int) bool {
+		if scores[bookIDs[i]] != scores[bookIDs[j]] {
+			return scores[bookIDs[i]] > scores[bookIDs[j]]
+		}
+		return bookIDs[i].String() < bookIDs[j].String()
+	})
+	if len(bookIDs) > maxRecommendations {
+		bookIDs = bookIDs[:maxRecommendations]
+	}
+	return bookIDs
+}
+
+// contentSimilarity scores from 0 to 1 how alike two books are by author,
+// price and publication date. Prices only compare in the same currency.
+func contentSimilarity(a, b *bookFeatures) float64 {
+	score := 0.0
+	if a.author != "" && a.author == b.author {
+		score += authorWeight
+	}
+	if a.currency == b.currency && a.price > 0 && b.price > 0 {
+		score += priceWeight * math.Min(a.price, b.price) / math.Max(a.price, b.price)
+	}
+	if !a.publishedAt.IsZero() && !b.publishedAt.IsZero() {
+		apart := a.publishedAt.Sub(b.publishedAt)
+		if apart < 0 {
+			apart = -apart
+		}
+		if apart < dateHorizon {
+			score += dateWeight * (1 - float64(apart)/float64(dateHorizon))
+		}
+	}
+	return score
+}
+
+// ordering is a list of books sorted on one feature, to find the books
+// closest to a book in it
+type ordering struct {
+	ids      []uuid.UUID
+	position map[uuid.UUID]int
+}
+
+func newOrdering(ids []uuid.UUID, less func(a, b uuid.UUID) bool) *ordering {
+	sort.Slice(ids, func(i, j int) bool {
+		return less(ids[i], ids[j])
+	})
+	position := make(map[uuid.UUID]int, len(ids))
+	for i, id := range ids {
+		position[id] = i
+	}
+	return &ordering{ids: ids, position: position}
+}
+
+// around returns up to n books on either side of a book in the ordering,
+// or none when the book is not in it
+func (o *ordering) around(id uuid.UUID, n int) []uuid.UUID {
+	i, ok := o.position[id]
+	if !ok {
+		return nil
+	}
+	around := make([]uuid.UUID, 0, 2*n)
+	around = append(around, o.ids[max(i-n, 0):i]...)
+	return append(around, o.ids[i+1:min(i+n+1, len(o.ids))]...)
+}
+
+// addCoView counts a user who viewed both a and b
+// addUserViews counts the books a user viewed and the pairs of them viewed
+// together, leaving out the books no longer in the catalog. It reports
+// whether any of the user's books were counted.
+func addUserViews(views []*model.BookView, features map[uuid.UUID]*bookFeatures, viewers map[uuid.UUID]int, coViews map[uuid.UUID]map[uuid.UUID]int) bool {
+	books := make([]uuid.UUID, 0, len(views))
+	for _, view := range views {
+		if _, ok := features[view.BookID]; ok {
+			books = append(books, view.BookID)
+		}
+	}
+	for i, a := range books {
+		viewers[a]++
+		for _, b := range books[i+1:] {
+			addCoView(coViews, a, b)
+			addCoView(coViews, b, a)
+		}
+	}
+	return len(books) > 0
+}
+
+func addCoView(coViews map[uuid.UUID]map[uuid.UUID]int, a, b uuid.UUID) {
+	if coViews[a] == nil {
+		coViews[a] = make(map[uuid.UUID]int)
+	}
+	coViews[a][b]++
+}
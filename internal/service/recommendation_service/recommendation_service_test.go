@@ -0,0 +1,304 @@
+package recommendation_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type fakeViews struct {
+	repository.IBookViewRepository
+	views   []*model.BookView
+	popular []uuid.UUID
+}
+
+func (r *fakeViews) FindByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*model.BookView, error) {
+	var views []*model.BookView
+	for _, view := range r.views {
+		if view.UserID == userID && len(views) < limit {
+			views = append(views, view)
+		}
+	}
+	return views, nil
+}
+
+// FindRecentByUserInBatches groups the views by user in ID order, most
+// recent first, and hands them over batchSize users at a time. Unlike the
+// repository it keeps the views of books no longer in the catalog.
+func (r *fakeViews) FindRecentByUserInBatches(ctx context.Context, perUser, batchSize int, fn func(views []*model.BookView) error) error {
+	byUser := make(map[uuid.UUID][]*model.BookView)
+	var users []uuid.UUID
+	for _, view := range r.views {
+		if byUser[view.UserID] == nil {
+			users = append(users, view.UserID)
+		}
+		byUser[view.UserID] = append(byUser[view.UserID], view)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].String() < users[j].String() })
+
+	for start := 0; start < len(users); start += batchSize {
+		var batch []*model.BookView
+		for _, user := range users[start:min(start+batchSize, len(users))] {
+			views := byUser[user]
+			sort.SliceStable(views, func(i, j int) bool { return views[i].LastViewedAt.After(views[j].LastViewedAt) })
+			batch = append(batch, views[:min(perUser, len(views))]...)
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *fakeViews) MostViewed(ctx context.Context, limit int) ([]uuid.UUID, error) {
+	return r.popular, nil
+}
+
+// fakeSimilarities keeps the similar books of each book, closest first
+type fakeSimilarities struct {
+	repository.IBookSimilarityRepository
+	similarities map[uuid.UUID][]*model.BookSimilarity
+}
+
+func (r *fakeSimilarities) FindByBookID(ctx context.Context, bookID uuid.UUID, limit int) ([]*model.BookSimilarity, error) {
+	similarities := r.similarities[bookID]
+	return similarities[:min(limit, len(similarities))], nil
+}
+
+func (r *fakeSimilarities) FindByBookIDs(ctx context.Context, bookIDs []uuid.UUID) ([]*model.BookSimilarity, error) {
+	var similarities []*model.BookSimilarity
+	for _, id := range bookIDs {
+		similarities = append(similarities, r.similarities[id]...)
+	}
+	return similarities, nil
+}
+
+func (r *fakeSimilarities) Replace(ctx context.Context, bookID uuid.UUID, similarities []*model.BookSimilarity) error {
+	r.similarities[bookID] = similarities
+	return nil
+}
+
+func (r *fakeSimilarities) DeleteBefore(ctx context.Context, before time.Time) error {
+	for bookID, similarities := range r.similarities {
+		if len(similarities) > 0 && similarities[0].UpdatedAt.Before(before) {
+			delete(r.similarities, bookID)
+		}
+	}
+	return nil
+}
+
+// catalog holds four books: a and b by the same author a few years apart,
+// c at a's price but long before, and d with nothing to compare it on
+type catalog struct {
+	a, b, c, d   *model.Book
+	books        *repotest.Books
+	views        *fakeViews
+	similarities *fakeSimilarities
+}
+
+func newCatalog() *catalog {
+	date := func(year int, month time.Month) time.Time { return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC) }
+	c := &catalog{
+		a: &model.Book{ID: uuid.New(), Author: "Frank Herbert", Price: decimal.NewFromInt(10), Currency: "EUR", PublishedAt: date(1965, time.August)},
+		b: &model.Book{ID: uuid.New(), Author: " frank herbert", Price: decimal.NewFromInt(12), Currency: "EUR", PublishedAt: date(1969, time.June)},
+		c: &model.Book{ID: uuid.New(), Author: "Jane Austen", Price: decimal.NewFromInt(10), Currency: "EUR", PublishedAt: date(1813, time.January)},
+		d: &model.Book{ID: uuid.New()},
+	}
+	c.books = repotest.NewBooks(c.a, c.b, c.c, c.d)
+	c.views = &fakeViews{}
+	c.similarities = &fakeSimilarities{similarities: make(map[uuid.UUID][]*model.BookSimilarity)}
+	return c
+}
+
+func (c *catalog) service() service.IRecommendationService {
+	return NewRecommendationService(c.views, c.similarities, c.books, repotest.Transactor{}, nil, time.Minute, 5)
+}
+
+// view records that a user viewed books
+func (c *catalog) view(userID uuid.UUID, books ...uuid.UUID) {
+	for _, bookID := range books {
+		c.views.views = append(c.views.views, &model.BookView{ID: uuid.New(), UserID: userID, BookID: bookID, Views: 1, LastViewedAt: time.Now()})
+	}
+}
+
+// similar stores the similar books of a book, closest first
+func (c *catalog) similar(book *model.Book, scores map[*model.Book]float64, order ...*model.Book) {
+	for _, other := range order {
+		c.similarities.similarities[book.ID] = append(c.similarities.similarities[book.ID],
+			&model.BookSimilarity{BookID: book.ID, SimilarBookID: other.ID, Score: scores[other]})
+	}
+}
+
+func TestRefreshSimilarities(t *testing.T) {
+	c := newCatalog()
+	// Two readers viewed a and c; one also viewed a book since purged
+	first, second := uuid.New(), uuid.New()
+	c.view(first, c.a.ID, c.c.ID, uuid.New())
+	c.view(second, c.a.ID, c.c.ID)
+	// A similarity left from an earlier refresh
+	c.similarities.similarities[c.d.ID] = []*model.BookSimilarity{
+		{BookID: c.d.ID, SimilarBookID: c.a.ID, Score: 1, UpdatedAt: time.Now().Add(-time.Hour)},
+	}
+
+	result, err := c.service().RefreshSimilarities(context.Background())
+	if err != nil {
+		t.Fatalf("RefreshSimilarities() error = %v", err)
+	}
+	if result.Books != 3 || result.Viewers != 2 || result.Similarities != 6 {
+		t.Errorf("RefreshSimilarities() = %+v, want 3 books, 2 viewers, 6 similarities", result)
+	}
+
+	type neighbour struct {
+		book    *model.Book
+		score   float64
+		coViews int
+	}
+	want := map[*model.Book][]neighbour{
+		// Viewed by the same readers outweighs the same author
+		c.a: {{c.c, 0.7 + 0.3*0.2, 2}, {c.b, 0.3 * (0.6 + 0.2*10/12.0 + 0.2*(1-46/60.0)), 0}},
+		c.b: {{c.a, 0.3 * (0.6 + 0.2*10/12.0 + 0.2*(1-46/60.0)), 0}, {c.c, 0.3 * 0.2 * 10 / 12.0, 0}},
+		c.c: {{c.a, 0.7 + 0.3*0.2, 2}, {c.b, 0.3 * 0.2 * 10 / 12.0, 0}},
+	}
+	names := map[*model.Book]string{c.a: "a", c.b: "b", c.c: "c", c.d: "d"}
+	for book, neighbours := range want {
+		got := c.similarities.similarities[book.ID]
+		if len(got) != len(neighbours) {
+			t.Fatalf("%s has %d similar books, want %d", names[book], len(got), len(neighbours))
+		}
+		for i, n := range neighbours {
+			// Months are not all as long, so the date part is only close
+			if got[i].SimilarBookID != n.book.ID || math.Abs(got[i].Score-n.score) > 0.001 || got[i].CoViews != n.coViews {
+				t.Errorf("%s similar book %d = %v scored %.4f with %d co-views, want %s scored %.4f with %d",
+					names[book], i, got[i].SimilarBookID, got[i].Score, got[i].CoViews, names[n.book], n.score, n.coViews)
+			}
+		}
+	}
+	// A book with nothing to go on keeps no similar books, stale ones included
+	if got := c.similarities.similarities[c.d.ID]; len(got) != 0 {
+		t.Errorf("d has %d similar books, want none", len(got))
+	}
+}
+
+// titles names the books of a response after the catalog's fields
+func (c *catalog) titles(response *model.RecommendationListResponse) []string {
+	names := map[uuid.UUID]string{c.a.ID: "a", c.b.ID: "b", c.c.ID: "c", c.d.ID: "d"}
+	titles := make([]string, len(response.Data))
+	for i, book := range response.Data {
+		titles[i] = names[book.ID]
+	}
+	return titles
+}
+
+// newRecommendingCatalog returns a catalog with similarities already
+// computed and the most viewed books d, a, c and one since purged
+func newRecommendingCatalog() *catalog {
+	c := newCatalog()
+	c.similar(c.a, map[*model.Book]float64{c.c: 0.76, c.b: 0.24}, c.c, c.b)
+	c.similar(c.b, map[*model.Book]float64{c.a: 0.24, c.c: 0.05}, c.a, c.c)
+	c.similar(c.c, map[*model.Book]float64{c.a: 0.76, c.b: 0.05}, c.a, c.b)
+	c.views.popular = []uuid.UUID{c.d.ID, c.a.ID, c.c.ID, uuid.New()}
+	return c
+}
+
+func TestSimilarBooks(t *testing.T) {
+	c := newRecommendingCatalog()
+	s := c.service()
+
+	tests := []struct {
+		name       string
+		book       string
+		limit      int
+		want       []string
+		wantSource string
+		wantErr    error
+	}{
+		{name: "similar books topped up with the most viewed", book: c.a.ID.String(), limit: 10, want: []string{"c", "b", "d"}, wantSource: model.RecommendationSimilar},
+		{name: "limit", book: c.a.ID.String(), limit: 1, want: []string{"c"}, wantSource: model.RecommendationSimilar},
+		{name: "cold start", book: c.d.ID.String(), limit: 10, want: []string{"a", "c"}, wantSource: model.RecommendationPopular},
+		{name: "unknown book", book: uuid.NewString(), wantErr: service.ErrBookNotFound},
+		{name: "bad ID", book: "not-a-uuid", wantErr: service.ErrInvalidBookID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := s.SimilarBooks(context.Background(), tt.book, tt.limit)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SimilarBooks() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := c.titles(response); !equal(got, tt.want) || response.Source != tt.wantSource {
+				t.Errorf("SimilarBooks() = %q from %s, want %q from %s", got, response.Source, tt.want, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestMyRecommendations(t *testing.T) {
+	tests := []struct {
+		name       string
+		viewed     func(c *catalog) []uuid.UUID
+		want       []string
+		wantSource string
+	}{
+		{
+			name:       "neighbours of a viewed book",
+			viewed:     func(c *catalog) []uuid.UUID { return []uuid.UUID{c.a.ID} },
+			want:       []string{"c", "b", "d"},
+			wantSource: model.RecommendationSimilar,
+		},
+		{
+			name:       "viewed books are left out",
+			viewed:     func(c *catalog) []uuid.UUID { return []uuid.UUID{c.a.ID, c.b.ID} },
+			want:       []string{"c", "d"},
+			wantSource: model.RecommendationSimilar,
+		},
+		{
+			name:       "cold start",
+			viewed:     func(c *catalog) []uuid.UUID { return nil },
+			want:       []string{"d", "a", "c"},
+			wantSource: model.RecommendationPopular,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newRecommendingCatalog()
+			userID := uuid.New()
+			c.view(userID, tt.viewed(c)...)
+			ctx := utils.WithCurrentUser(context.Background(), userID.String(), "user")
+
+			response, err := c.service().MyRecommendations(ctx, 10)
+			if err != nil {
+				t.Fatalf("MyRecommendations() error = %v", err)
+			}
+			if got := c.titles(response); !equal(got, tt.want) || response.Source != tt.wantSource {
+				t.Errorf("MyRecommendations() = %q from %s, want %q from %s", got, response.Source, tt.want, tt.wantSource)
+			}
+		})
+	}
+}
+
+func equal(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,367 @@
+package review_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type reviewService struct {
+	repo       repository.IReviewRepository
+	bookRepo   repository.IBookRepository
+	userRepo   repository.IUserRepository
+	transactor repository.ITransactor
+}
+
+// NewReviewService creates a new review service
+func NewReviewService(
+	repo repository.IReviewRepository,
+	bookRepo repository.IBookRepository,
+	userRepo repository.IUserRepository,
+	transactor repository.ITransactor,
+) service.IReviewService {
+	return &reviewService{
+		repo:       repo,
+		bookRepo:   bookRepo,
+		userRepo:   userRepo,
+		transactor: transactor,
+	}
+}
+
+// CreateReview rates and reviews a book as the current user and adds the
+// rating to the book's. Users review each book at most once.
+func (s *reviewService) CreateReview(ctx context.Context, bookID string, req *model.ReviewRequest) (*model.ReviewResponse, error) {
+	book, err := s.findBook(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	author, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.repo.FindByBookAndUser(ctx, book.ID, author.ID)
+	if err == nil {
+		return nil, service.ErrReviewExists
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to find review: %v", err)
+	}
+
+	now := time.Now()
+	review := &model.Review{
+		ID:        uuid.New(),
+		BookID:    book.ID,
+		UserID:    author.ID,
+		Author:    displayName(author),
+		Rating:    req.Rating,
+		Body:      req.Body,
+		Status:    model.ReviewStatusPublished,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, review); err != nil {
+			return fmt.Errorf("failed to create review: %v", err)
+		}
+		if err := s.bookRepo.AdjustRating(ctx, book.ID, review.Rating, 1); err != nil {
+			return fmt.Errorf("failed to update book rating: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return review.ToDTO(), nil
+}
+
+// GetReview gets a review by ID. Hidden reviews are only shown to their
+// author and admins.
+func (s *reviewService) GetReview(ctx context.Context, id string) (*model.ReviewResponse, error) {
+	reviewID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidReviewID, err)
+	}
+
+	review, err := s.repo.FindByID(ctx, reviewID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("failed to find review: %v", err)
+	}
+	if !canSee(ctx, review) {
+		return nil, service.ErrReviewNotFound
+	}
+
+	return review.ToDTO(), nil
+}
+
+// GetMyReview gets the current user's review of a book
+func (s *reviewService) GetMyReview(ctx context.Context, bookID string) (*model.ReviewResponse, error) {
+	book, err := s.findBook(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	review, err := s.repo.FindByBookAndUser(ctx, book.ID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("failed to find review: %v", err)
+	}
+
+	return review.ToDTO(), nil
+}
+
+// UpdateReview replaces the rating and text of one of the current user's
+// reviews and moves the book's rating with it. Moderation decisions stand.
+func (s *reviewService) UpdateReview(ctx context.Context, id string, req *model.ReviewRequest) (*model.ReviewResponse, error) {
+	reviewID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidReviewID, err)
+	}
+	author, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var review *model.Review
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		review, err = s.lockReview(ctx, reviewID)
+		if err != nil {
+			return err
+		}
+		if review.UserID != author.ID {
+			return service.ErrReviewNotFound
+		}
+
+		delta := req.Rating - review.Rating
+		review.Author = displayName(author)
+		review.Rating = req.Rating
+		review.Body = req.Body
+		review.UpdatedAt = time.Now()
+		if err := s.repo.Update(ctx, review); err != nil {
+			return fmt.Errorf("failed to update review: %v", err)
+		}
+		if review.Counted() && delta != 0 {
+			if err := s.bookRepo.AdjustRating(ctx, review.BookID, delta, 0); err != nil {
+				return fmt.Errorf("failed to update book rating: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return review.ToDTO(), nil
+}
+
+// DeleteReview deletes a review and takes its rating off the book's.
+// Users may delete their own reviews, admins any review.
+func (s *reviewService) DeleteReview(ctx context.Context, id string) error {
+	reviewID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("%w: %v", service.ErrInvalidReviewID, err)
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		review, err := s.lockReview(ctx, reviewID)
+		if err != nil {
+			return err
+		}
+		if utils.UserRoleFromContext(ctx) != "admin" && utils.UserIDFromContext(ctx) != review.UserID.String() {
+			return service.ErrReviewNotFound
+		}
+
+		if err := s.repo.Delete(ctx, review.ID); err != nil {
+			return fmt.Errorf("failed to delete review: %v", err)
+		}
+		if review.Counted() {
+			if err := s.bookRepo.AdjustRating(ctx, review.BookID, -review.Rating, -1); err != nil {
+				return fmt.Errorf("failed to update book rating: %v", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ListBookReviews gets a paginated list of the reviews of a book, newest
+// first. Only admins see hidden reviews.
+func (s *reviewService) ListBookReviews(ctx context.Context, bookID string, page, pageSize int, status string) (*model.ReviewListResponse, error) {
+	book, err := s.findBook(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.list(ctx, page, pageSize, status, map[string]any{"book_id = ?": book.ID})
+}
+
+// ListReviews gets a paginated list of reviews of every book, newest
+// first, such as the flagged reviews awaiting a moderator
+func (s *reviewService) ListReviews(ctx context.Context, page, pageSize int, status string) (*model.ReviewListResponse, error) {
+	return s.list(ctx, page, pageSize, status, map[string]any{})
+}
+
+// ModerateReview publishes, flags or hides a review. Hiding a review takes
+// its rating off the book's and publishing it again puts it back.
+func (s *reviewService) ModerateReview(ctx context.Context, id string, req *model.ReviewModerationRequest) (*model.ReviewResponse, error) {
+	reviewID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidReviewID, err)
+	}
+
+	var review *model.Review
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		review, err = s.lockReview(ctx, reviewID)
+		if err != nil {
+			return err
+		}
+
+		wasCounted := review.Counted()
+		now := time.Now()
+		review.Status = req.Status
+		review.ModerationNote = req.Note
+		review.ModeratedBy = utils.UserIDFromContext(ctx)
+		review.ModeratedAt = &now
+		review.UpdatedAt = now
+		if err := s.repo.Update(ctx, review); err != nil {
+			return fmt.Errorf("failed to update review: %v", err)
+		}
+
+		switch {
+		case wasCounted && !review.Counted():
+			err = s.bookRepo.AdjustRating(ctx, review.BookID, -review.Rating, -1)
+		case !wasCounted && review.Counted():
+			err = s.bookRepo.AdjustRating(ctx, review.BookID, review.Rating, 1)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update book rating: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return review.ToDTO(), nil
+}
+
+// list gets a paginated list of the reviews matching filters and status,
+// leaving hidden reviews out for users other than admins
+func (s *reviewService) list(ctx context.Context, page, pageSize int, status string, filters map[string]any) (*model.ReviewListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	switch status {
+	case "":
+	case model.ReviewStatusPublished, model.ReviewStatusFlagged, model.ReviewStatusHidden:
+		filters["status = ?"] = status
+	default:
+		return nil, fmt.Errorf("%w: %q", service.ErrInvalidReviewStatus, status)
+	}
+	if utils.UserRoleFromContext(ctx) != "admin" {
+		filters["status <> ?"] = model.ReviewStatusHidden
+	}
+
+	reviews, total, err := s.repo.FindAll(ctx, page, pageSize, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reviews: %v", err)
+	}
+
+	reviewDTOs := make([]*model.ReviewResponse, len(reviews))
+	for i, review := range reviews {
+		reviewDTOs[i] = review.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.ReviewListResponse{
+		Data: reviewDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// lockReview finds a review by ID and locks it for the surrounding transaction
+func (s *reviewService) lockReview(ctx context.Context, id uuid.UUID) (*model.Review, error) {
+	review, err := s.repo.FindByIDForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("failed to find review: %v", err)
+	}
+	return review, nil
+}
+
+// findBook finds a book by ID
+func (s *reviewService) findBook(ctx context.Context, id string) (*model.Book, error) {
+	bookID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidBookID, err)
+	}
+
+	book, err := s.bookRepo.FindByID(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrBookNotFound
+		}
+		return nil, fmt.Errorf("failed to find book: %v", err)
+	}
+	return book, nil
+}
+
+// currentUser finds the authenticated user
+func (s *reviewService) currentUser(ctx context.Context) (*model.User, error) {
+	userID, err := service.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: user no longer exists", service.ErrInvalidUserID)
+		}
+		return nil, fmt.Errorf("failed to find user: %v", err)
+	}
+	return user, nil
+}
+
+// displayName is the name a review is signed with
+func displayName(user *model.User) string {
+	if user.FullName != "" {
+		return user.FullName
+	}
+	return user.Username
+}
+
+// canSee reports whether the current user may see a review: hidden
+// reviews are only shown to their author and admins
+func canSee(ctx context.Context, review *model.Review) bool {
+	return review.Counted() || utils.UserRoleFromContext(ctx) == "admin" || utils.UserIDFromContext(ctx) == review.UserID.String()
+}
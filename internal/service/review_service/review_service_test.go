@@ -0,0 +1,251 @@
+package review_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	"book_system/internal/utils"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type fakeReviews struct {
+	repository.IReviewRepository
+	reviews map[uuid.UUID]*model.Review
+}
+
+func (r *fakeReviews) Create(ctx context.Context, review *model.Review) error {
+	copied := *review
+	r.reviews[review.ID] = &copied
+	return nil
+}
+
+func (r *fakeReviews) FindByID(ctx context.Context, id uuid.UUID) (*model.Review, error) {
+	review, ok := r.reviews[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *review
+	return &copied, nil
+}
+
+func (r *fakeReviews) FindByIDForUpdate(ctx context.Context, id uuid.UUID) (*model.Review, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (r *fakeReviews) FindByBookAndUser(ctx context.Context, bookID, userID uuid.UUID) (*model.Review, error) {
+	for _, review := range r.reviews {
+		if review.BookID == bookID && review.UserID == userID {
+			copied := *review
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeReviews) Update(ctx context.Context, review *model.Review) error {
+	copied := *review
+	r.reviews[review.ID] = &copied
+	return nil
+}
+
+func (r *fakeReviews) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(r.reviews, id)
+	return nil
+}
+
+// shelf wraps a review service with its fakes, holding one book and the
+// readers who review it
+type shelf struct {
+	service.IReviewService
+	reviews *fakeReviews
+	book    *model.Book
+	readers []*model.User
+}
+
+func newShelf(readers int) *shelf {
+	s := &shelf{
+		reviews: &fakeReviews{reviews: make(map[uuid.UUID]*model.Review)},
+		book:    &model.Book{ID: uuid.New(), Title: "Dune"},
+	}
+	users := repotest.NewUsers()
+	for i := 0; i < readers; i++ {
+		reader := &model.User{ID: uuid.New(), Username: "reader", Role: "user"}
+		s.readers = append(s.readers, reader)
+		users.Put(reader)
+	}
+	s.IReviewService = NewReviewService(s.reviews, repotest.NewBooks(s.book), users, repotest.Transactor{})
+	return s
+}
+
+// as returns a context authenticated as the i-th reader
+func (s *shelf) as(i int) context.Context {
+	return utils.WithCurrentUser(context.Background(), s.readers[i].ID.String(), s.readers[i].Role)
+}
+
+func asAdmin() context.Context {
+	return utils.WithCurrentUser(context.Background(), uuid.NewString(), "admin")
+}
+
+func TestBookRatingFollowsReviews(t *testing.T) {
+	s := newShelf(3)
+	bookID := s.book.ID.String()
+	var ids []string
+
+	steps := []struct {
+		name      string
+		do        func() error
+		wantSum   int
+		wantCount int
+		wantAvg   float64
+	}{
+		{
+			name: "three reviews",
+			do: func() error {
+				for i, rating := range []int{5, 4, 4} {
+					review, err := s.CreateReview(s.as(i), bookID, &model.ReviewRequest{Rating: rating})
+					if err != nil {
+						return err
+					}
+					ids = append(ids, review.ID.String())
+				}
+				return nil
+			},
+			wantSum: 13, wantCount: 3, wantAvg: 4.33,
+		},
+		{
+			name: "a reader changes their rating",
+			do: func() error {
+				_, err := s.UpdateReview(s.as(2), ids[2], &model.ReviewRequest{Rating: 1, Body: "Changed my mind"})
+				return err
+			},
+			wantSum: 10, wantCount: 3, wantAvg: 3.33,
+		},
+		{
+			name: "a flagged review still counts",
+			do: func() error {
+				_, err := s.ModerateReview(asAdmin(), ids[2], &model.ReviewModerationRequest{Status: model.ReviewStatusFlagged})
+				return err
+			},
+			wantSum: 10, wantCount: 3, wantAvg: 3.33,
+		},
+		{
+			name: "a hidden review does not",
+			do: func() error {
+				_, err := s.ModerateReview(asAdmin(), ids[2], &model.ReviewModerationRequest{Status: model.ReviewStatusHidden})
+				return err
+			},
+			wantSum: 9, wantCount: 2, wantAvg: 4.5,
+		},
+		{
+			name: "a hidden review changed by its author stays out",
+			do: func() error {
+				_, err := s.UpdateReview(s.as(2), ids[2], &model.ReviewRequest{Rating: 2})
+				return err
+			},
+			wantSum: 9, wantCount: 2, wantAvg: 4.5,
+		},
+		{
+			name: "publishing it again puts it back",
+			do: func() error {
+				_, err := s.ModerateReview(asAdmin(), ids[2], &model.ReviewModerationRequest{Status: model.ReviewStatusPublished})
+				return err
+			},
+			wantSum: 11, wantCount: 3, wantAvg: 3.67,
+		},
+		{
+			name: "a deleted review is taken off",
+			do: func() error {
+				return s.DeleteReview(s.as(0), ids[0])
+			},
+			wantSum: 6, wantCount: 2, wantAvg: 3,
+		},
+	}
+
+	for _, step := range steps {
+		if err := step.do(); err != nil {
+			t.Fatalf("%s: error = %v", step.name, err)
+		}
+		if s.book.RatingSum != step.wantSum || s.book.RatingCount != step.wantCount || s.book.Rating() != step.wantAvg {
+			t.Errorf("%s: book rated %d from %d ratings, %v on average; want %d from %d, %v",
+				step.name, s.book.RatingSum, s.book.RatingCount, s.book.Rating(), step.wantSum, step.wantCount, step.wantAvg)
+		}
+	}
+}
+
+func TestOneReviewPerBook(t *testing.T) {
+	s := newShelf(2)
+	bookID := s.book.ID.String()
+
+	if _, err := s.CreateReview(s.as(0), bookID, &model.ReviewRequest{Rating: 5}); err != nil {
+		t.Fatalf("CreateReview() error = %v", err)
+	}
+	if _, err := s.CreateReview(s.as(0), bookID, &model.ReviewRequest{Rating: 1}); !errors.Is(err, service.ErrReviewExists) {
+		t.Errorf("second CreateReview() error = %v, want ErrReviewExists", err)
+	}
+	if _, err := s.CreateReview(s.as(1), bookID, &model.ReviewRequest{Rating: 3}); err != nil {
+		t.Errorf("CreateReview() by another reader error = %v", err)
+	}
+	if s.book.RatingCount != 2 {
+		t.Errorf("book has %d ratings, want 2", s.book.RatingCount)
+	}
+}
+
+func TestReviewAccess(t *testing.T) {
+	tests := []struct {
+		name string
+		// as is the reader acting, -1 for an admin
+		as      int
+		hidden  bool
+		action  func(s *shelf, ctx context.Context, id string) error
+		wantErr error
+	}{
+		{name: "anyone sees a published review", as: 1, action: get},
+		{name: "the author sees their hidden review", as: 0, hidden: true, action: get},
+		{name: "an admin sees a hidden review", as: -1, hidden: true, action: get},
+		{name: "others do not see a hidden review", as: 1, hidden: true, action: get, wantErr: service.ErrReviewNotFound},
+		{name: "others cannot change a review", as: 1, action: update, wantErr: service.ErrReviewNotFound},
+		{name: "others cannot delete a review", as: 1, action: remove, wantErr: service.ErrReviewNotFound},
+		{name: "an admin deletes any review", as: -1, action: remove},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newShelf(2)
+			review, err := s.CreateReview(s.as(0), s.book.ID.String(), &model.ReviewRequest{Rating: 4})
+			if err != nil {
+				t.Fatalf("CreateReview() error = %v", err)
+			}
+			if tt.hidden {
+				s.reviews.reviews[review.ID].Status = model.ReviewStatusHidden
+			}
+
+			ctx := asAdmin()
+			if tt.as >= 0 {
+				ctx = s.as(tt.as)
+			}
+			if err := tt.action(s, ctx, review.ID.String()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func get(s *shelf, ctx context.Context, id string) error {
+	_, err := s.GetReview(ctx, id)
+	return err
+}
+
+func update(s *shelf, ctx context.Context, id string) error {
+	_, err := s.UpdateReview(ctx, id, &model.ReviewRequest{Rating: 1})
+	return err
+}
+
+func remove(s *shelf, ctx context.Context, id string) error {
+	return s.DeleteReview(ctx, id)
+}
@@ -0,0 +1,155 @@
+package series_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type seriesService struct {
+	repo       repository.ISeriesRepository
+	workRepo   repository.IWorkRepository
+	transactor repository.ITransactor
+}
+
+// NewSeriesService creates a new series service
+func NewSeriesService(
+	repo repository.ISeriesRepository,
+	workRepo repository.IWorkRepository,
+	transactor repository.ITransactor,
+) service.ISeriesService {
+	return &seriesService{
+		repo:       repo,
+		workRepo:   workRepo,
+		transactor: transactor,
+	}
+}
+
+// CreateSeries creates a new series
+func (s *seriesService) CreateSeries(ctx context.Context, req *model.SeriesRequest) (*model.SeriesResponse, error) {
+	now := time.Now()
+	series := &model.Series{
+		ID:          uuid.New(),
+		Title:       req.Title,
+		Description: req.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.repo.Create(ctx, series); err != nil {
+		return nil, fmt.Errorf("failed to create series: %v", err)
+	}
+
+	return series.ToDTO(), nil
+}
+
+// GetSeries gets a series with its volumes in reading order
+func (s *seriesService) GetSeries(ctx context.Context, id string) (*model.SeriesResponse, error) {
+	series, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	volumes, err := s.workRepo.FindBySeriesID(ctx, series.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list series volumes: %v", err)
+	}
+
+	dto := series.ToDTO()
+	dto.Volumes = make([]*model.WorkResponse, len(volumes))
+	for i, volume := range volumes {
+		dto.Volumes[i] = volume.ToDTO()
+	}
+	return dto, nil
+}
+
+// ListSeries gets a paginated list of series
+func (s *seriesService) ListSeries(ctx context.Context, page, pageSize int) (*model.SeriesListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	series, total, err := s.repo.FindAll(ctx, page, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list series: %v", err)
+	}
+
+	seriesDTOs := make([]*model.SeriesResponse, len(series))
+	for i, item := range series {
+		seriesDTOs[i] = item.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.SeriesListResponse{
+		Data: seriesDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// UpdateSeries replaces the editable fields of a series
+func (s *seriesService) UpdateSeries(ctx context.Context, id string, req *model.SeriesRequest) (*model.SeriesResponse, error) {
+	series, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	series.Title = req.Title
+	series.Description = req.Description
+	series.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, series); err != nil {
+		return nil, fmt.Errorf("failed to update series: %v", err)
+	}
+
+	return series.ToDTO(), nil
+}
+
+// DeleteSeries deletes a series. Its volumes remain as standalone works.
+func (s *seriesService) DeleteSeries(ctx context.Context, id string) error {
+	series, err := s.find(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.workRepo.DetachSeries(ctx, series.ID); err != nil {
+			return fmt.Errorf("failed to detach series volumes: %v", err)
+		}
+		if err := s.repo.Delete(ctx, series.ID); err != nil {
+			return fmt.Errorf("failed to delete series: %v", err)
+		}
+		return nil
+	})
+}
+
+// find gets a series by its ID in string form
+func (s *seriesService) find(ctx context.Context, id string) (*model.Series, error) {
+	seriesID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidSeriesID, err)
+	}
+
+	series, err := s.repo.FindByID(ctx, seriesID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrSeriesNotFound
+		}
+		return nil, fmt.Errorf("failed to find series: %v", err)
+	}
+	return series, nil
+}
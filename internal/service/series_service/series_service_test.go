@@ -0,0 +1,195 @@
+package series_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	work_service "book_system/internal/service/work_service"
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// fakeWorks lists the volumes of a series by position, like the repository
+type fakeWorks struct {
+	repository.IWorkRepository
+	works map[uuid.UUID]*model.Work
+}
+
+func (r *fakeWorks) FindByID(ctx context.Context, id uuid.UUID) (*model.Work, error) {
+	work, ok := r.works[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *work
+	return &copied, nil
+}
+
+func (r *fakeWorks) FindBySeriesID(ctx context.Context, seriesID uuid.UUID) ([]*model.Work, error) {
+	var volumes []*model.Work
+	for _, work := range r.works {
+		if work.SeriesID != nil && *work.SeriesID == seriesID {
+			volumes = append(volumes, work)
+		}
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].SeriesPosition < volumes[j].SeriesPosition })
+	return volumes, nil
+}
+
+func (r *fakeWorks) DetachSeries(ctx context.Context, seriesID uuid.UUID) error {
+	for _, work := range r.works {
+		if work.SeriesID != nil && *work.SeriesID == seriesID {
+			work.SeriesID, work.SeriesPosition = nil, 0
+		}
+	}
+	return nil
+}
+
+type fakeSeries struct {
+	repository.ISeriesRepository
+	series map[uuid.UUID]*model.Series
+}
+
+func (r *fakeSeries) Create(ctx context.Context, series *model.Series) error {
+	r.series[series.ID] = series
+	return nil
+}
+
+func (r *fakeSeries) FindByID(ctx context.Context, id uuid.UUID) (*model.Series, error) {
+	series, ok := r.series[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return series, nil
+}
+
+func (r *fakeSeries) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(r.series, id)
+	return nil
+}
+
+// newSeries creates a series through s and stores a volume at each
+// position, returning the series and its volumes in the order given
+func newSeries(t *testing.T, s service.ISeriesService, works *fakeWorks, positions ...float64) (*model.SeriesResponse, []*model.Work) {
+	t.Helper()
+	series, err := s.CreateSeries(context.Background(), &model.SeriesRequest{Title: "Dune Chronicles"})
+	if err != nil {
+		t.Fatalf("CreateSeries() error = %v", err)
+	}
+	volumes := make([]*model.Work, len(positions))
+	for i, position := range positions {
+		volumes[i] = &model.Work{ID: uuid.New(), Title: "Volume", SeriesID: &series.ID, SeriesPosition: position}
+		works.works[volumes[i].ID] = volumes[i]
+	}
+	return series, volumes
+}
+
+func TestGetSeries(t *testing.T) {
+	works := &fakeWorks{works: make(map[uuid.UUID]*model.Work)}
+	seriesRepo := &fakeSeries{series: make(map[uuid.UUID]*model.Series)}
+	s := NewSeriesService(seriesRepo, works, repotest.Transactor{})
+	// Stored out of order; a novella sits between whole volumes
+	series, volumes := newSeries(t, s, works, 3, 1, 1.5, 2)
+	_, others := newSeries(t, s, works, 1)
+
+	tests := []struct {
+		name        string
+		id          string
+		wantVolumes []*model.Work
+		wantErr     error
+	}{
+		{name: "volumes in reading order", id: series.ID.String(),
+			wantVolumes: []*model.Work{volumes[1], volumes[2], volumes[3], volumes[0]}},
+		{name: "other series", id: others[0].SeriesID.String(), wantVolumes: others},
+		{name: "unknown series", id: uuid.NewString(), wantErr: service.ErrSeriesNotFound},
+		{name: "invalid ID", id: "dune", wantErr: service.ErrInvalidSeriesID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.GetSeries(context.Background(), tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetSeries() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got.Volumes) != len(tt.wantVolumes) {
+				t.Fatalf("got %d volumes, want %d", len(got.Volumes), len(tt.wantVolumes))
+			}
+			for i, volume := range got.Volumes {
+				if volume.ID != tt.wantVolumes[i].ID {
+					t.Errorf("volume %d is at position %g, want %g", i, volume.SeriesPosition, tt.wantVolumes[i].SeriesPosition)
+				}
+			}
+		})
+	}
+}
+
+func TestSeriesNavigationFollowsReadingOrder(t *testing.T) {
+	works := &fakeWorks{works: make(map[uuid.UUID]*model.Work)}
+	seriesRepo := &fakeSeries{series: make(map[uuid.UUID]*model.Series)}
+	s := NewSeriesService(seriesRepo, works, repotest.Transactor{})
+	navigator := work_service.NewWorkService(works, seriesRepo, nil)
+	series, _ := newSeries(t, s, works, 2, 1, 3.5, 3)
+
+	got, err := s.GetSeries(context.Background(), series.ID.String())
+	if err != nil {
+		t.Fatalf("GetSeries() error = %v", err)
+	}
+	for i, volume := range got.Volumes {
+		navigation, err := navigator.GetSeriesNavigation(context.Background(), volume.ID.String())
+		if err != nil {
+			t.Fatalf("GetSeriesNavigation() error = %v", err)
+		}
+		if navigation.Series.ID != series.ID {
+			t.Errorf("volume %d navigates series %s, want %s", i, navigation.Series.ID, series.ID)
+		}
+
+		var wantPrevious, wantNext *model.WorkResponse
+		if i > 0 {
+			wantPrevious = got.Volumes[i-1]
+		}
+		if i < len(got.Volumes)-1 {
+			wantNext = got.Volumes[i+1]
+		}
+		if !sameWork(navigation.Previous, wantPrevious) || !sameWork(navigation.Next, wantNext) {
+			t.Errorf("volume %d: previous = %v, next = %v, want %v and %v", i, navigation.Previous, navigation.Next, wantPrevious, wantNext)
+		}
+	}
+}
+
+func sameWork(got, want *model.WorkResponse) bool {
+	if got == nil || want == nil {
+		return got == nil && want == nil
+	}
+	return got.ID == want.ID
+}
+
+func TestDeleteSeries(t *testing.T) {
+	works := &fakeWorks{works: make(map[uuid.UUID]*model.Work)}
+	seriesRepo := &fakeSeries{series: make(map[uuid.UUID]*model.Series)}
+	s := NewSeriesService(seriesRepo, works, repotest.Transactor{})
+	series, volumes := newSeries(t, s, works, 1, 2)
+	_, others := newSeries(t, s, works, 1)
+
+	if err := s.DeleteSeries(context.Background(), series.ID.String()); err != nil {
+		t.Fatalf("DeleteSeries() error = %v", err)
+	}
+	if _, err := s.GetSeries(context.Background(), series.ID.String()); !errors.Is(err, service.ErrSeriesNotFound) {
+		t.Errorf("GetSeries() after delete error = %v, want %v", err, service.ErrSeriesNotFound)
+	}
+	for _, volume := range volumes {
+		if volume.SeriesID != nil || volume.SeriesPosition != 0 {
+			t.Errorf("volume still in series %v at %g", volume.SeriesID, volume.SeriesPosition)
+		}
+	}
+	if others[0].SeriesID == nil {
+		t.Errorf("volume of another series was detached")
+	}
+}
@@ -3,14 +3,27 @@ package service
 import (
 	"book_system/internal/model"
 	"context"
+	"io"
 	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type IUploadService interface {
 	UploadFile(ctx context.Context, fileHeader *multipart.FileHeader, customPath ...string) (string, error)
+	// DeleteFile deletes a stored object; objectName may also be a URL returned by UploadFile
 	DeleteFile(ctx context.Context, objectName string) error
 	GetFileURL(ctx context.Context, objectName string) (string, error)
 	GetFile(ctx context.Context, objectName string) (*multipart.FileHeader, error)
+	// PutObject stores the content of reader under objectName; size -1 means unknown
+	PutObject(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) error
+	// OpenObject opens a stored object for reading; objectName may also be a URL returned by UploadFile
+	OpenObject(ctx context.Context, objectName string) (io.ReadCloser, error)
+	// ObjectURL returns the permanent URL of a stored object, like the ones UploadFile returns
+	ObjectURL(objectName string) string
 }
 
 type IUserService interface {
@@ -44,10 +57,446 @@ type IBookService interface {
 	CreateBook(ctx context.Context, req *model.CreateBookRequest) (*model.BookResponse, error)
 	// GetBookByID gets a book by ID
 	GetBookByID(ctx context.Context, id string) (*model.BookResponse, error)
-	// ListBooks gets a paginated list of books
-	ListBooks(ctx context.Context, page, pageSize int, filters map[string]any) (*model.BookListResponse, error)
-	// UpdateBook updates a book
-	UpdateBook(ctx context.Context, id string, req *model.UpdateBookRequest) (*model.BookResponse, error)
-	// DeleteBook deletes a book
-	DeleteBook(ctx context.Context, id string) error
+	// ListBooks gets a paginated list of books, in the given order if not empty
+	ListBooks(ctx context.Context, page, pageSize int, filters map[string]any, sort string) (*model.BookListResponse, error)
+	// UpdateBook updates a book; a non-zero expectedVersion must match the current version
+	UpdateBook(ctx context.Context, id string, req *model.UpdateBookRequest, expectedVersion int) (*model.BookResponse, error)
+	// PatchBook applies a JSON Merge Patch or JSON Patch, as named by mediaType, to a book
+	PatchBook(ctx context.Context, id, mediaType string, patch []byte, expectedVersion int) (*model.BookResponse, error)
+	// DeleteBook moves a book to the trash; a non-zero expectedVersion must match the current version
+	DeleteBook(ctx context.Context, id string, expectedVersion int) error
+	// ListTrashedBooks gets a paginated list of soft-deleted books
+	ListTrashedBooks(ctx context.Context, page, pageSize int) (*model.BookListResponse, error)
+	// RestoreBook moves a book out of the trash
+	RestoreBook(ctx context.Context, id string) (*model.BookResponse, error)
+	// PurgeBook permanently deletes a book in the trash
+	PurgeBook(ctx context.Context, id string) error
+
+	// GetBookHistory gets a paginated change history of a book
+	GetBookHistory(ctx context.Context, id string, page, pageSize int) (*model.BookHistoryResponse, error)
+	// GetBookVersion gets a book as it was at the given version
+	GetBookVersion(ctx context.Context, id string, version int) (*model.BookVersionResponse, error)
+	// RevertBook restores a book's fields to those of a previous version
+	RevertBook(ctx context.Context, id string, version int) (*model.BookResponse, error)
+
+	// GetTranslations gets the translations of a book's title and description
+	GetTranslations(ctx context.Context, id string) (model.BookTranslations, error)
+	// SetTranslation adds or replaces the translation of a book into lang
+	SetTranslation(ctx context.Context, id, lang string, translation *model.BookTranslation, expectedVersion int) (*model.BookResponse, error)
+	// DeleteTranslation removes the translation of a book into lang
+	DeleteTranslation(ctx context.Context, id, lang string, expectedVersion int) (*model.BookResponse, error)
+}
+
+// IInventoryService defines the interface for stock keeping through the inventory ledger
+type IInventoryService interface {
+	// AdjustStock atomically changes a book's stock and records the movement
+	AdjustStock(ctx context.Context, id string, req *model.StockAdjustRequest) (*model.StockAdjustResponse, error)
+	// ListMovements gets a paginated ledger of a book, newest first
+	ListMovements(ctx context.Context, id string, page, pageSize int) (*model.StockMovementListResponse, error)
+	// ReconcileStock compares a book's stock with its ledger and corrects the stock
+	ReconcileStock(ctx context.Context, id string) (*model.StockReconciliation, error)
+	// ListLowStock gets a paginated list of books at or below the low-stock threshold
+	ListLowStock(ctx context.Context, page, pageSize int) (*model.BookListResponse, error)
+	// TransferStock atomically moves copies of a book between two locations
+	TransferStock(ctx context.Context, id string, req *model.StockTransferRequest) (*model.StockTransferResponse, error)
+	// GetBookStock breaks a book's stock down by location
+	GetBookStock(ctx context.Context, id string) (*model.BookStockResponse, error)
+	// GetAvailability lists the locations that have a book, found by ISBN, in stock
+	GetAvailability(ctx context.Context, isbn string) (*model.BookAvailability, error)
+}
+
+// ILocationService defines the interface for stores and warehouses
+type ILocationService interface {
+	// CreateLocation creates a new location
+	CreateLocation(ctx context.Context, req *model.LocationRequest) (*model.LocationResponse, error)
+	// GetLocation gets a location by ID
+	GetLocation(ctx context.Context, id string) (*model.LocationResponse, error)
+	// ListLocations gets a paginated list of locations
+	ListLocations(ctx context.Context, page, pageSize int) (*model.LocationListResponse, error)
+	// UpdateLocation replaces the editable fields of a location
+	UpdateLocation(ctx context.Context, id string, req *model.LocationRequest) (*model.LocationResponse, error)
+	// DeleteLocation deletes a location that has no stock left
+	DeleteLocation(ctx context.Context, id string) error
+	// ListStock gets a paginated list of the books in stock at a location
+	ListStock(ctx context.Context, id string, page, pageSize int) (*model.LocationStockListResponse, error)
+	// ListMovements gets a paginated ledger of a location, newest first
+	ListMovements(ctx context.Context, id string, page, pageSize int) (*model.StockMovementListResponse, error)
+}
+
+// IPricingService defines the interface for price rules, coupons and effective prices
+type IPricingService interface {
+	// CreatePriceRule creates a new price rule
+	CreatePriceRule(ctx context.Context, req *model.PriceRuleRequest) (*model.PriceRuleResponse, error)
+	// GetPriceRule gets a price rule by ID
+	GetPriceRule(ctx context.Context, id string) (*model.PriceRuleResponse, error)
+	// ListPriceRules gets a paginated list of price rules
+	ListPriceRules(ctx context.Context, page, pageSize int, filters map[string]any) (*model.PriceRuleListResponse, error)
+	// UpdatePriceRule replaces the editable fields of a price rule
+	UpdatePriceRule(ctx context.Context, id string, req *model.PriceRuleRequest) (*model.PriceRuleResponse, error)
+	// DeletePriceRule deletes a price rule
+	DeletePriceRule(ctx context.Context, id string) error
+	// CreateCoupon creates a new coupon
+	CreateCoupon(ctx context.Context, req *model.CouponRequest) (*model.CouponResponse, error)
+	// GetCoupon gets a coupon by ID
+	GetCoupon(ctx context.Context, id string) (*model.CouponResponse, error)
+	// ListCoupons gets a paginated list of coupons
+	ListCoupons(ctx context.Context, page, pageSize int) (*model.CouponListResponse, error)
+	// UpdateCoupon replaces the editable fields of a coupon
+	UpdateCoupon(ctx context.Context, id string, req *model.CouponRequest) (*model.CouponResponse, error)
+	// DeleteCoupon deletes a coupon
+	DeleteCoupon(ctx context.Context, id string) error
+	// QuoteBook gets the effective price of a book in a currency, optionally with a coupon
+	QuoteBook(ctx context.Context, bookID, couponCode, currency string) (*model.PriceQuote, error)
+	// ResolveCurrency picks the requested currency, else the user's preferred one, else the catalog's
+	ResolveCurrency(ctx context.Context, requested string) (string, error)
+	// PriceBooks gets the effective prices of books in a currency by book ID
+	PriceBooks(ctx context.Context, books []*model.Book, currency string) (map[uuid.UUID]*model.PriceQuote, error)
+	// DisplayPrices sets the display price of books to their list price in a currency
+	DisplayPrices(ctx context.Context, books []*model.BookResponse, currency string) error
+	// CouponDiscount checks a coupon code and works out what it takes off a subtotal in a currency
+	CouponDiscount(ctx context.Context, code string, subtotal decimal.Decimal, currency string) (*model.CouponDiscount, error)
+	// RedeemCoupon counts a use of a coupon
+	RedeemCoupon(ctx context.Context, id uuid.UUID) error
+	// ReleaseCoupon gives back a use of a coupon
+	ReleaseCoupon(ctx context.Context, id uuid.UUID) error
+	// ListBookPrices gets the list prices a book has in other currencies
+	ListBookPrices(ctx context.Context, bookID string) ([]*model.BookPriceResponse, error)
+	// SetBookPrice sets the list price of a book in a currency
+	SetBookPrice(ctx context.Context, bookID, currency string, req *model.BookPriceRequest) (*model.BookPriceResponse, error)
+	// DeleteBookPrice removes the list price of a book in a currency
+	DeleteBookPrice(ctx context.Context, bookID, currency string) error
+}
+
+// IExchangeRateService defines the interface for exchange rate snapshots
+type IExchangeRateService interface {
+	// RecordRates records new exchange rate snapshots
+	RecordRates(ctx context.Context, req *model.ExchangeRateBatchRequest) ([]*model.ExchangeRateResponse, error)
+	// ImportRates records the exchange rates of a CSV file
+	ImportRates(ctx context.Context, file io.Reader) ([]*model.ExchangeRateResponse, error)
+	// ListRates gets a paginated history of exchange rates
+	ListRates(ctx context.Context, page, pageSize int, filters map[string]any) (*model.ExchangeRateListResponse, error)
+	// LatestRates gets the rate in force for every currency pair
+	LatestRates(ctx context.Context) ([]*model.ExchangeRateResponse, error)
+}
+
+// ICartService defines the interface for the current user's shopping cart
+type ICartService interface {
+	// GetCart gets the cart at the books' effective prices in a currency, optionally with a coupon
+	GetCart(ctx context.Context, couponCode, currency string) (*model.CartResponse, error)
+	// AddItem adds copies of a book to the cart
+	AddItem(ctx context.Context, req *model.CartItemRequest) (*model.CartResponse, error)
+	// SetItemQuantity sets the number of copies of a book in the cart; 0 removes it
+	SetItemQuantity(ctx context.Context, bookID string, req *model.CartQuantityRequest) (*model.CartResponse, error)
+	// RemoveItem removes a book from the cart
+	RemoveItem(ctx context.Context, bookID string) (*model.CartResponse, error)
+	// ClearCart empties the cart
+	ClearCart(ctx context.Context) error
+}
+
+// IOrderService defines the interface for checkout and the order lifecycle
+type IOrderService interface {
+	// Checkout turns the current user's cart into a pending order, reserving its stock
+	Checkout(ctx context.Context, req *model.CheckoutRequest) (*model.OrderResponse, error)
+	// GetOrder gets an order by ID, if the current user may see it
+	GetOrder(ctx context.Context, id string) (*model.OrderResponse, error)
+	// ListMyOrders gets a paginated order history of the current user
+	ListMyOrders(ctx context.Context, page, pageSize int, status string) (*model.OrderListResponse, error)
+	// ListOrders gets a paginated list of all orders
+	ListOrders(ctx context.Context, page, pageSize int, filters map[string]any) (*model.OrderListResponse, error)
+	// CancelOrder cancels one of the current user's pending orders
+	CancelOrder(ctx context.Context, id string) (*model.OrderResponse, error)
+	// UpdateOrderStatus moves an order to another state of its lifecycle
+	UpdateOrderStatus(ctx context.Context, id string, req *model.OrderStatusRequest) (*model.OrderResponse, error)
+	// ExpireOrders cancels the pending orders past their payment window
+	ExpireOrders(ctx context.Context) (int, error)
+	// RunExpirer expires pending orders every interval until ctx is done
+	RunExpirer(ctx context.Context, interval time.Duration)
+}
+
+// IPaymentProvider defines the interface for payment gateways
+type IPaymentProvider interface {
+	// Name identifies the provider on the payments it creates
+	Name() string
+	// CreateIntent starts collecting a payment
+	CreateIntent(ctx context.Context, intent *model.PaymentIntent) (*model.ProviderPayment, error)
+	// Capture collects an authorized payment
+	Capture(ctx context.Context, ref string) (*model.ProviderPayment, error)
+	// Refund pays a captured payment back in full
+	Refund(ctx context.Context, ref string) (*model.ProviderPayment, error)
+	// GetPayment gets the current state of a payment at the provider, or
+	// returns ErrPaymentIntentNotFound for a ref it does not know
+	GetPayment(ctx context.Context, ref string) (*model.ProviderPayment, error)
+	// VerifyWebhook checks the signature of a webhook delivery and parses
+	// its event, or returns ErrInvalidWebhookSignature
+	VerifyWebhook(payload []byte, header http.Header) (*model.PaymentWebhookEvent, error)
+}
+
+// IPaymentService defines the interface for paying orders
+type IPaymentService interface {
+	// CreatePayment starts the payment of one of the current user's pending orders
+	CreatePayment(ctx context.Context, orderID string) (*model.PaymentResponse, error)
+	// GetPayment gets a payment by ID, if the current user may see it
+	GetPayment(ctx context.Context, id string) (*model.PaymentResponse, error)
+	// ListPayments gets a paginated list of payments
+	ListPayments(ctx context.Context, page, pageSize int, filters map[string]any) (*model.PaymentListResponse, error)
+	// CapturePayment collects an authorized payment
+	CapturePayment(ctx context.Context, id string) (*model.PaymentResponse, error)
+	// RefundPayment pays a captured payment back in full
+	RefundPayment(ctx context.Context, id string) (*model.PaymentResponse, error)
+	// HandleWebhook applies a signed provider event to its payment, idempotently
+	HandleWebhook(ctx context.Context, payload []byte, header http.Header) error
+	// ReconcilePayments resolves stuck payments against the provider's state
+	ReconcilePayments(ctx context.Context) (*model.PaymentReconciliation, error)
+	// RunReconciler reconciles stuck payments every interval until ctx is done
+	RunReconciler(ctx context.Context, interval time.Duration)
+}
+
+// ICirculationService defines the interface for lending physical copies of books
+type ICirculationService interface {
+	// AddCopy adds a physical copy of a book to the holdings
+	AddCopy(ctx context.Context, bookID string, req *model.CopyRequest) (*model.CopyResponse, error)
+	// GetCopy gets a copy by ID
+	GetCopy(ctx context.Context, id string) (*model.CopyResponse, error)
+	// ListCopies gets a paginated list of copies
+	ListCopies(ctx context.Context, page, pageSize int, filters map[string]any) (*model.CopyListResponse, error)
+	// ListBookCopies gets a paginated list of the copies of a book
+	ListBookCopies(ctx context.Context, bookID string, page, pageSize int) (*model.CopyListResponse, error)
+	// UpdateCopy replaces the editable fields of a copy
+	UpdateCopy(ctx context.Context, id string, req *model.CopyRequest) (*model.CopyResponse, error)
+	// DeleteCopy removes a copy that is not on loan from the holdings
+	DeleteCopy(ctx context.Context, id string) error
+	// CheckoutCopy lends a copy to a member for the loan period of their role
+	CheckoutCopy(ctx context.Context, req *model.LoanCheckoutRequest) (*model.LoanResponse, error)
+	// CheckinCopy ends the loan of a returned copy
+	CheckinCopy(ctx context.Context, req *model.LoanCheckinRequest) (*model.LoanResponse, error)
+	// RenewLoan pushes the due date of an active loan back by another loan period
+	RenewLoan(ctx context.Context, id string) (*model.LoanResponse, error)
+	// GetLoan gets a loan by ID, if the current user may see it
+	GetLoan(ctx context.Context, id string) (*model.LoanResponse, error)
+	// ListMyLoans gets a paginated list of the current user's loans
+	ListMyLoans(ctx context.Context, page, pageSize int, status string) (*model.LoanListResponse, error)
+	// ListLoans gets a paginated list of all loans
+	ListLoans(ctx context.Context, page, pageSize int, status string, filters map[string]any) (*model.LoanListResponse, error)
+}
+
+// IHoldService defines the interface for the queues of users waiting for books out of stock
+type IHoldService interface {
+	// PlaceHold puts the current user at the back of the queue for a book out of stock
+	PlaceHold(ctx context.Context, bookID string) (*model.HoldResponse, error)
+	// GetHold gets a hold by ID, if the current user may see it
+	GetHold(ctx context.Context, id string) (*model.HoldResponse, error)
+	// CancelHold gives up a waiting or ready hold
+	CancelHold(ctx context.Context, id string) (*model.HoldResponse, error)
+	// ListMyHolds gets a paginated list of the current user's holds
+	ListMyHolds(ctx context.Context, page, pageSize int, status string) (*model.HoldListResponse, error)
+	// ListBookHolds gets the queue of waiting and ready holds of a book
+	ListBookHolds(ctx context.Context, bookID string, page, pageSize int) (*model.HoldListResponse, error)
+	// AssignHolds sets the free stock of a book aside for the holds at the front of its queue.
+	// The inventory service calls it whenever AdjustStock brings stock in, within the adjustment's
+	// transaction; the hold service calls it when a ready hold is cancelled or expires.
+	AssignHolds(ctx context.Context, bookID uuid.UUID) (int, error)
+	// ReservedStock returns how much of a book's stock is set aside for other users' ready holds
+	ReservedStock(ctx context.Context, bookID, userID uuid.UUID) (int, error)
+	// FulfillHold closes the hold a user has on a book they bought, if any
+	FulfillHold(ctx context.Context, bookID, userID uuid.UUID) error
+	// ExpireHolds expires the ready holds past their pickup window
+	ExpireHolds(ctx context.Context) (*model.HoldExpiry, error)
+	// RunExpirer expires lapsed holds every interval until ctx is done
+	RunExpirer(ctx context.Context, interval time.Duration)
+}
+
+// INotificationService defines the interface for users' notification inboxes
+type INotificationService interface {
+	// Notify puts a message in a user's inbox
+	Notify(ctx context.Context, userID uuid.UUID, kind, message string, bookID *uuid.UUID) error
+	// ListMyNotifications gets a paginated list of the current user's notifications
+	ListMyNotifications(ctx context.Context, page, pageSize int, unreadOnly bool) (*model.NotificationListResponse, error)
+	// MarkRead marks one of the current user's notifications as read
+	MarkRead(ctx context.Context, id string) error
+	// MarkAllRead marks every notification of the current user as read
+	MarkAllRead(ctx context.Context) error
+}
+
+// IFineService defines the interface for overdue fines and member balances
+type IFineService interface {
+	// CreateFinePolicy creates a fine policy for a role, or the default policy
+	CreateFinePolicy(ctx context.Context, req *model.FinePolicyRequest) (*model.FinePolicyResponse, error)
+	// ListFinePolicies gets every fine policy
+	ListFinePolicies(ctx context.Context) (*model.FinePolicyListResponse, error)
+	// UpdateFinePolicy replaces the editable fields of a fine policy
+	UpdateFinePolicy(ctx context.Context, id string, req *model.FinePolicyRequest) (*model.FinePolicyResponse, error)
+	// DeleteFinePolicy deletes a fine policy
+	DeleteFinePolicy(ctx context.Context, id string) error
+	// ChargeFine brings the fine of a loan up to what is due at the given time; the caller saves the loan
+	ChargeFine(ctx context.Context, loan *model.Loan, at time.Time) error
+	// AccrueFines charges the fines due so far on every overdue loan
+	AccrueFines(ctx context.Context) (*model.FineAccrual, error)
+	// RunNightly accrues fines every day at the given hour until ctx is done
+	RunNightly(ctx context.Context, hour int)
+	// CheckCanBorrow returns ErrBalanceOverLimit when a member owes too much to borrow
+	CheckCanBorrow(ctx context.Context, userID uuid.UUID) error
+	// GetMyAccount gets the current user's balance and ledger
+	GetMyAccount(ctx context.Context, page, pageSize int) (*model.AccountResponse, error)
+	// GetAccount gets a member's balance and ledger
+	GetAccount(ctx context.Context, userID string, page, pageSize int) (*model.AccountResponse, error)
+	// RecordPayment takes a payment made by a member off their balance
+	RecordPayment(ctx context.Context, userID string, req *model.AccountCreditRequest) (*model.AccountEntryResponse, error)
+	// WaiveFine forgives part or all of a member's balance
+	WaiveFine(ctx context.Context, userID string, req *model.AccountCreditRequest) (*model.AccountEntryResponse, error)
+}
+
+// IReviewService defines the interface for book reviews and ratings
+type IReviewService interface {
+	// CreateReview rates and reviews a book as the current user
+	CreateReview(ctx context.Context, bookID string, req *model.ReviewRequest) (*model.ReviewResponse, error)
+	// GetReview gets a review by ID, if the current user may see it
+	GetReview(ctx context.Context, id string) (*model.ReviewResponse, error)
+	// GetMyReview gets the current user's review of a book
+	GetMyReview(ctx context.Context, bookID string) (*model.ReviewResponse, error)
+	// UpdateReview replaces one of the current user's reviews
+	UpdateReview(ctx context.Context, id string, req *model.ReviewRequest) (*model.ReviewResponse, error)
+	// DeleteReview deletes one of the current user's reviews, or any review for admins
+	DeleteReview(ctx context.Context, id string) error
+	// ListBookReviews gets a paginated list of the reviews of a book
+	ListBookReviews(ctx context.Context, bookID string, page, pageSize int, status string) (*model.ReviewListResponse, error)
+	// ListReviews gets a paginated list of reviews of every book
+	ListReviews(ctx context.Context, page, pageSize int, status string) (*model.ReviewListResponse, error)
+	// ModerateReview publishes, flags or hides a review
+	ModerateReview(ctx context.Context, id string, req *model.ReviewModerationRequest) (*model.ReviewResponse, error)
+}
+
+// IReadingListService defines the interface for users' wishlists and reading lists
+type IReadingListService interface {
+	// ListMyLists gets every reading list of the current user
+	ListMyLists(ctx context.Context) (*model.ReadingListCollection, error)
+	// CreateList creates a custom reading list for the current user
+	CreateList(ctx context.Context, req *model.ReadingListRequest) (*model.ReadingListResponse, error)
+	// GetList gets one of the current user's reading lists with its books
+	GetList(ctx context.Context, id string) (*model.ReadingListResponse, error)
+	// RenameList renames one of the current user's reading lists
+	RenameList(ctx context.Context, id string, req *model.ReadingListRequest) (*model.ReadingListResponse, error)
+	// DeleteList deletes one of the current user's custom reading lists
+	DeleteList(ctx context.Context, id string) error
+	// AddItem puts a book at the end of one of the current user's reading lists
+	AddItem(ctx context.Context, id string, req *model.ReadingListItemRequest) (*model.ReadingListResponse, error)
+	// RemoveItem takes a book off one of the current user's reading lists
+	RemoveItem(ctx context.Context, id, bookID string) (*model.ReadingListResponse, error)
+	// ReorderItems puts the books of one of the current user's reading lists in a new order
+	ReorderItems(ctx context.Context, id string, req *model.ReadingListOrderRequest) (*model.ReadingListResponse, error)
+	// UpdateProgress records how far the current user has read a book on their reading list
+	UpdateProgress(ctx context.Context, id, bookID string, req *model.ReadingProgressRequest) (*model.ReadingListItemResponse, error)
+	// ShareList makes one of the current user's reading lists readable through a share link
+	ShareList(ctx context.Context, id string) (*model.ReadingListResponse, error)
+	// UnshareList revokes the share link of one of the current user's reading lists
+	UnshareList(ctx context.Context, id string) (*model.ReadingListResponse, error)
+	// GetSharedList gets a shared reading list by its share token
+	GetSharedList(ctx context.Context, token string) (*model.ReadingListResponse, error)
+}
+
+// IRecommendationService defines the interface for book recommendations
+type IRecommendationService interface {
+	// RecordView counts a view of a book's detail page by the current user
+	RecordView(ctx context.Context, bookID uuid.UUID) error
+	// SimilarBooks gets the books most similar to a book
+	SimilarBooks(ctx context.Context, bookID string, limit int) (*model.RecommendationListResponse, error)
+	// MyRecommendations gets books for the current user based on the books they viewed
+	MyRecommendations(ctx context.Context, limit int) (*model.RecommendationListResponse, error)
+	// RefreshSimilarities recomputes the similar books of every book
+	RefreshSimilarities(ctx context.Context) (*model.SimilarityRefresh, error)
+	// RunRefresher recomputes the similar books every interval until ctx is done
+	RunRefresher(ctx context.Context, interval time.Duration)
+}
+
+// IWorkService defines the interface for works and their editions
+type IWorkService interface {
+	// CreateWork creates a new work
+	CreateWork(ctx context.Context, req *model.WorkRequest) (*model.WorkResponse, error)
+	// GetWork gets a work by ID
+	GetWork(ctx context.Context, id string) (*model.WorkResponse, error)
+	// ListWorks gets a paginated list of works
+	ListWorks(ctx context.Context, page, pageSize int, filters map[string]any) (*model.WorkListResponse, error)
+	// UpdateWork replaces the editable fields of a work
+	UpdateWork(ctx context.Context, id string, req *model.WorkRequest) (*model.WorkResponse, error)
+	// DeleteWork deletes a work that has no editions left
+	DeleteWork(ctx context.Context, id string) error
+	// ListEditions gets a work together with all its editions
+	ListEditions(ctx context.Context, id string) (*model.WorkEditionsResponse, error)
+	// GetSeriesNavigation gets the volumes before and after a work in its series
+	GetSeriesNavigation(ctx context.Context, id string) (*model.SeriesNavigation, error)
+}
+
+// ISeriesService defines the interface for series of works
+type ISeriesService interface {
+	// CreateSeries creates a new series
+	CreateSeries(ctx context.Context, req *model.SeriesRequest) (*model.SeriesResponse, error)
+	// GetSeries gets a series with its volumes in order
+	GetSeries(ctx context.Context, id string) (*model.SeriesResponse, error)
+	// ListSeries gets a paginated list of series
+	ListSeries(ctx context.Context, page, pageSize int) (*model.SeriesListResponse, error)
+	// UpdateSeries replaces the editable fields of a series
+	UpdateSeries(ctx context.Context, id string, req *model.SeriesRequest) (*model.SeriesResponse, error)
+	// DeleteSeries deletes a series; its volumes remain as standalone works
+	DeleteSeries(ctx context.Context, id string) error
+}
+
+// ICategoryService defines the interface for book categories
+type ICategoryService interface {
+	// CreateCategory creates a new category
+	CreateCategory(ctx context.Context, req *model.CategoryRequest) (*model.CategoryResponse, error)
+	// GetCategory gets a category by ID
+	GetCategory(ctx context.Context, id string) (*model.CategoryResponse, error)
+	// ListCategories gets a paginated list of categories
+	ListCategories(ctx context.Context, page, pageSize int) (*model.CategoryListResponse, error)
+	// UpdateCategory replaces the name and translations of a category
+	UpdateCategory(ctx context.Context, id string, req *model.CategoryRequest) (*model.CategoryResponse, error)
+	// DeleteCategory deletes a category no book is filed under
+	DeleteCategory(ctx context.Context, id string) error
+}
+
+// IBookCoverService defines the interface for book cover uploads
+type IBookCoverService interface {
+	// UploadCover stores resized variants of a cover image and sets them on
+	// the book, deleting the variants of the cover it replaces. A non-zero
+	// expectedVersion must match the book's current version.
+	UploadCover(ctx context.Context, id string, image io.Reader, expectedVersion int) (*model.BookResponse, error)
+}
+
+// IBookImportService defines the interface for bulk catalog imports
+type IBookImportService interface {
+	// StartImport stores the import source and processes it in the background.
+	// upload is nil when req references an object already in storage.
+	StartImport(ctx context.Context, req *model.BookImportRequest, upload io.Reader, size int64, filename string) (*model.JobResponse, error)
+	// GetImportJob gets the status of an import job started by the current user, or by anyone for admins
+	GetImportJob(ctx context.Context, id string) (*model.JobResponse, error)
+	// RunWorkers processes queued imports until ctx is done
+	RunWorkers(ctx context.Context)
+}
+
+// IBookExportService defines the interface for catalog exports
+type IBookExportService interface {
+	// ExportBooks streams the books matching filters to w in the given format
+	ExportBooks(ctx context.Context, format string, filters map[string]any, w io.Writer) error
+	// StartExport exports the books matching filters to storage in the background
+	StartExport(ctx context.Context, format string, filters map[string]any) (*model.JobResponse, error)
+	// GetExportJob gets the status of an export job started by the current
+	// user, or by anyone for admins, with a download URL once it is done
+	GetExportJob(ctx context.Context, id string) (*model.JobResponse, error)
+	// RunWorkers processes queued exports until ctx is done
+	RunWorkers(ctx context.Context)
+}
+
+// IBookMetadataProvider fetches bibliographic metadata from an external catalog
+type IBookMetadataProvider interface {
+	// Name identifies the provider in metadata it returns
+	Name() string
+	// Lookup gets the metadata of an ISBN-13, or ErrBookMetadataNotFound
+	Lookup(ctx context.Context, isbn string) (*model.BookMetadata, error)
+}
+
+// IBookLookupService defines the interface for ISBN metadata lookups
+type IBookLookupService interface {
+	// LookupISBN fetches the metadata of an ISBN in any form, storing its cover
+	LookupISBN(ctx context.Context, isbn string) (*model.BookMetadata, error)
+	// Enrich fills the empty fields of a create request from the metadata of its ISBN
+	Enrich(ctx context.Context, req *model.CreateBookRequest) error
 }
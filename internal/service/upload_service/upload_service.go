@@ -22,13 +22,34 @@ func (s *uploadService) UploadFile(ctx context.Context, fileHeader *multipart.Fi
 }
 
 func (s *uploadService) DeleteFile(ctx context.Context, objectName string) error {
-	return infrastructure.DeleteFile(ctx, objectName)
+	return infrastructure.DeleteFile(ctx, infrastructure.ObjectNameFromURL(objectName))
 }
 
 func (s *uploadService) GetFileURL(ctx context.Context, objectName string) (string, error) {
 	return infrastructure.GetFileURL(ctx, objectName)
 }
 
+func (s *uploadService) PutObject(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) error {
+	return infrastructure.PutObject(ctx, objectName, reader, size, contentType)
+}
+
+func (s *uploadService) ObjectURL(objectName string) string {
+	return infrastructure.ObjectURL(objectName)
+}
+
+func (s *uploadService) OpenObject(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	obj, err := infrastructure.GetFile(ctx, infrastructure.ObjectNameFromURL(objectName))
+	if err != nil {
+		return nil, err
+	}
+	// GetObject is lazy, Stat surfaces a missing object before reading
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, err
+	}
+	return obj, nil
+}
+
 func (s *uploadService) GetFile(ctx context.Context, objectName string) (*multipart.FileHeader, error) {
 	minioObj, err := infrastructure.GetFile(ctx, objectName)
 	if err != nil {
@@ -133,6 +133,9 @@ func (s *userService) UpdateUser(ctx context.Context, id string, req *model.Upda
 	if req.Avatar != nil {
 		user.Avatar = *req.Avatar
 	}
+	if req.Currency != nil {
+		user.Currency = *req.Currency
+	}
 
 	user.UpdatedAt = time.Now()
 
@@ -0,0 +1,240 @@
+package work_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/service"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type workService struct {
+	repo       repository.IWorkRepository
+	seriesRepo repository.ISeriesRepository
+	bookRepo   repository.IBookRepository
+}
+
+// NewWorkService creates a new work service
+func NewWorkService(
+	repo repository.IWorkRepository,
+	seriesRepo repository.ISeriesRepository,
+	bookRepo repository.IBookRepository,
+) service.IWorkService {
+	return &workService{
+		repo:       repo,
+		seriesRepo: seriesRepo,
+		bookRepo:   bookRepo,
+	}
+}
+
+// CreateWork creates a new work
+func (s *workService) CreateWork(ctx context.Context, req *model.WorkRequest) (*model.WorkResponse, error) {
+	work := &model.Work{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+	}
+	if err := s.apply(ctx, work, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, work); err != nil {
+		return nil, fmt.Errorf("failed to create work: %v", err)
+	}
+
+	return work.ToDTO(), nil
+}
+
+// GetWork gets a work by ID
+func (s *workService) GetWork(ctx context.Context, id string) (*model.WorkResponse, error) {
+	work, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return work.ToDTO(), nil
+}
+
+// ListWorks gets a paginated list of works
+func (s *workService) ListWorks(ctx context.Context, page, pageSize int, filters map[string]any) (*model.WorkListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	works, total, err := s.repo.FindAll(ctx, page, pageSize, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list works: %v", err)
+	}
+
+	workDTOs := make([]*model.WorkResponse, len(works))
+	for i, work := range works {
+		workDTOs[i] = work.ToDTO()
+	}
+
+	totalPage := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	return &model.WorkListResponse{
+		Data: workDTOs,
+		Pagination: model.Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: totalPage,
+		},
+	}, nil
+}
+
+// UpdateWork replaces the editable fields of a work
+func (s *workService) UpdateWork(ctx context.Context, id string, req *model.WorkRequest) (*model.WorkResponse, error) {
+	work, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, work, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, work); err != nil {
+		return nil, fmt.Errorf("failed to update work: %v", err)
+	}
+
+	return work.ToDTO(), nil
+}
+
+// DeleteWork deletes a work. Works that still have editions, even ones in
+// the trash, cannot be deleted.
+func (s *workService) DeleteWork(ctx context.Context, id string) error {
+	work, err := s.find(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	hasEditions, err := s.bookRepo.ExistsByWorkID(ctx, work.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check work editions: %v", err)
+	}
+	if hasEditions {
+		return service.ErrWorkHasEditions
+	}
+
+	if err := s.repo.Delete(ctx, work.ID); err != nil {
+		return fmt.Errorf("failed to delete work: %v", err)
+	}
+	return nil
+}
+
+// ListEditions gets a work together with all its editions
+func (s *workService) ListEditions(ctx context.Context, id string) (*model.WorkEditionsResponse, error) {
+	work, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	books, err := s.bookRepo.FindByWorkID(ctx, work.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list editions: %v", err)
+	}
+
+	editions := make([]*model.BookResponse, len(books))
+	for i, book := range books {
+		editions[i] = book.ToDTO()
+	}
+
+	return &model.WorkEditionsResponse{
+		Work:     work.ToDTO(),
+		Editions: editions,
+	}, nil
+}
+
+// GetSeriesNavigation gets the volumes before and after a work in its series
+func (s *workService) GetSeriesNavigation(ctx context.Context, id string) (*model.SeriesNavigation, error) {
+	work, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if work.SeriesID == nil {
+		return nil, service.ErrWorkNotInSeries
+	}
+
+	series, err := s.seriesRepo.FindByID(ctx, *work.SeriesID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find series: %v", err)
+	}
+	volumes, err := s.repo.FindBySeriesID(ctx, series.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list series volumes: %v", err)
+	}
+
+	navigation := &model.SeriesNavigation{
+		Series: series.ToDTO(),
+		Work:   work.ToDTO(),
+	}
+	for i, volume := range volumes {
+		if volume.ID != work.ID {
+			continue
+		}
+		if i > 0 {
+			navigation.Previous = volumes[i-1].ToDTO()
+		}
+		if i < len(volumes)-1 {
+			navigation.Next = volumes[i+1].ToDTO()
+		}
+		break
+	}
+
+	return navigation, nil
+}
+
+// apply copies the fields of req onto work, checking its series placement
+func (s *workService) apply(ctx context.Context, work *model.Work, req *model.WorkRequest) error {
+	if req.SeriesID != nil {
+		if _, err := s.seriesRepo.FindByID(ctx, *req.SeriesID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: %s", service.ErrSeriesNotFound, req.SeriesID)
+			}
+			return fmt.Errorf("failed to find series: %v", err)
+		}
+
+		taken, err := s.repo.ExistsInSeries(ctx, *req.SeriesID, req.SeriesPosition, work.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check series position: %v", err)
+		}
+		if taken {
+			return fmt.Errorf("%w: %g", service.ErrSeriesPositionTaken, req.SeriesPosition)
+		}
+	}
+
+	work.Title = req.Title
+	work.Author = req.Author
+	work.Description = req.Description
+	work.SeriesID = req.SeriesID
+	work.SeriesPosition = 0
+	if req.SeriesID != nil {
+		work.SeriesPosition = req.SeriesPosition
+	}
+	work.UpdatedAt = time.Now()
+	return nil
+}
+
+// find gets a work by its ID in string form
+func (s *workService) find(ctx context.Context, id string) (*model.Work, error) {
+	workID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", service.ErrInvalidWorkID, err)
+	}
+
+	work, err := s.repo.FindByID(ctx, workID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, service.ErrWorkNotFound
+		}
+		return nil, fmt.Errorf("failed to find work: %v", err)
+	}
+	return work, nil
+}
@@ -0,0 +1,177 @@
+package work_service
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/repository"
+	"book_system/internal/repository/repotest"
+	"book_system/internal/service"
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type fakeWorks struct {
+	repository.IWorkRepository
+	works map[uuid.UUID]*model.Work
+}
+
+func (r *fakeWorks) Create(ctx context.Context, work *model.Work) error {
+	r.works[work.ID] = work
+	return nil
+}
+
+func (r *fakeWorks) FindByID(ctx context.Context, id uuid.UUID) (*model.Work, error) {
+	work, ok := r.works[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *work
+	return &copied, nil
+}
+
+func (r *fakeWorks) FindBySeriesID(ctx context.Context, seriesID uuid.UUID) ([]*model.Work, error) {
+	var volumes []*model.Work
+	for _, work := range r.works {
+		if work.SeriesID != nil && *work.SeriesID == seriesID {
+			volumes = append(volumes, work)
+		}
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].SeriesPosition < volumes[j].SeriesPosition })
+	return volumes, nil
+}
+
+func (r *fakeWorks) ExistsInSeries(ctx context.Context, seriesID uuid.UUID, position float64, excludeID uuid.UUID) (bool, error) {
+	for _, work := range r.works {
+		if work.ID != excludeID && work.SeriesID != nil && *work.SeriesID == seriesID && work.SeriesPosition == position {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeWorks) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(r.works, id)
+	return nil
+}
+
+type fakeSeries struct {
+	repository.ISeriesRepository
+	series map[uuid.UUID]*model.Series
+}
+
+func (r *fakeSeries) FindByID(ctx context.Context, id uuid.UUID) (*model.Series, error) {
+	series, ok := r.series[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return series, nil
+}
+
+// newSeries stores a series with a volume at each position, returning the volumes in order
+func newSeries(works *fakeWorks, series *fakeSeries, positions ...float64) []*model.Work {
+	seriesID := uuid.New()
+	series.series[seriesID] = &model.Series{ID: seriesID, Title: "Dune Chronicles"}
+	volumes := make([]*model.Work, len(positions))
+	for i, position := range positions {
+		volumes[i] = &model.Work{ID: uuid.New(), SeriesID: &seriesID, SeriesPosition: position}
+		works.works[volumes[i].ID] = volumes[i]
+	}
+	return volumes
+}
+
+func TestGetSeriesNavigation(t *testing.T) {
+	works := &fakeWorks{works: make(map[uuid.UUID]*model.Work)}
+	series := &fakeSeries{series: make(map[uuid.UUID]*model.Series)}
+	// Stored out of order; half volumes sit between whole ones
+	volumes := newSeries(works, series, 1, 3, 2.5)
+	standalone := &model.Work{ID: uuid.New()}
+	works.works[standalone.ID] = standalone
+	s := NewWorkService(works, series, nil)
+
+	tests := []struct {
+		name         string
+		work         *model.Work
+		wantPrevious *model.Work
+		wantNext     *model.Work
+		wantErr      error
+	}{
+		{name: "first volume", work: volumes[0], wantNext: volumes[2]},
+		{name: "between volumes", work: volumes[2], wantPrevious: volumes[0], wantNext: volumes[1]},
+		{name: "last volume", work: volumes[1], wantPrevious: volumes[2]},
+		{name: "not in a series", work: standalone, wantErr: service.ErrWorkNotInSeries},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			navigation, err := s.GetSeriesNavigation(context.Background(), tt.work.ID.String())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetSeriesNavigation() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !sameWork(navigation.Previous, tt.wantPrevious) || !sameWork(navigation.Next, tt.wantNext) {
+				t.Errorf("previous = %v, next = %v, want %v and %v", navigation.Previous, navigation.Next, tt.wantPrevious, tt.wantNext)
+			}
+		})
+	}
+}
+
+func sameWork(got *model.WorkResponse, want *model.Work) bool {
+	if got == nil || want == nil {
+		return got == nil && want == nil
+	}
+	return got.ID == want.ID
+}
+
+func TestCreateWorkInSeries(t *testing.T) {
+	works := &fakeWorks{works: make(map[uuid.UUID]*model.Work)}
+	series := &fakeSeries{series: make(map[uuid.UUID]*model.Series)}
+	volumes := newSeries(works, series, 1)
+	seriesID := *volumes[0].SeriesID
+	unknown := uuid.New()
+	s := NewWorkService(works, series, nil)
+
+	tests := []struct {
+		name    string
+		req     *model.WorkRequest
+		wantErr error
+	}{
+		{name: "free position", req: &model.WorkRequest{Title: "Dune Messiah", SeriesID: &seriesID, SeriesPosition: 2}},
+		{name: "taken position", req: &model.WorkRequest{Title: "Dune again", SeriesID: &seriesID, SeriesPosition: 1}, wantErr: service.ErrSeriesPositionTaken},
+		{name: "unknown series", req: &model.WorkRequest{Title: "Lost", SeriesID: &unknown, SeriesPosition: 1}, wantErr: service.ErrSeriesNotFound},
+		{name: "standalone ignores the position", req: &model.WorkRequest{Title: "Standalone", SeriesPosition: 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			work, err := s.CreateWork(context.Background(), tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateWork() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && tt.req.SeriesID == nil && work.SeriesPosition != 0 {
+				t.Errorf("standalone work kept series position %g", work.SeriesPosition)
+			}
+		})
+	}
+}
+
+func TestDeleteWork(t *testing.T) {
+	withEditions, without := &model.Work{ID: uuid.New()}, &model.Work{ID: uuid.New()}
+	works := &fakeWorks{works: map[uuid.UUID]*model.Work{withEditions.ID: withEditions, without.ID: without}}
+	s := NewWorkService(works, nil, repotest.NewBooks(&model.Book{ID: uuid.New(), WorkID: &withEditions.ID}))
+
+	if err := s.DeleteWork(context.Background(), withEditions.ID.String()); !errors.Is(err, service.ErrWorkHasEditions) {
+		t.Errorf("DeleteWork() of a work with editions error = %v, want ErrWorkHasEditions", err)
+	}
+	if err := s.DeleteWork(context.Background(), without.ID.String()); err != nil {
+		t.Errorf("DeleteWork() error = %v", err)
+	}
+	if _, ok := works.works[without.ID]; ok {
+		t.Errorf("work without editions was not deleted")
+	}
+}
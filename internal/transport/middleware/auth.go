@@ -5,6 +5,7 @@ import (
 	"book_system/internal/utils"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
@@ -25,25 +26,46 @@ func Authorizator(authority ...string) gin.HandlerFunc {
 	}
 }
 
-var e *casbin.Enforcer
-var err error
-
-func init() {
-	// adapter, err := xormadapter.NewAdapter("mysql", config.MustGet().Casbin.DSN)
-	// if err != nil {
-	// 	panic(err)
-	// }
-	e, err = casbin.NewEnforcer("casbin/model.conf", "casbin/policy.csv")
-	if err != nil {
-		panic(err)
+// RequireRole only lets the request through when the authenticated user's
+// role, as set by AuthMiddleware, is one of roles
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !utils.Contains(roles, c.GetString("userRole")) {
+			errType := Forbidden
+			c.JSON(http.StatusForbidden, gin.H{"code": errType.Code, "message": errType.GetMesssageI18n(utils.GetCurrentLang(c))})
+			c.Abort()
+			return
+		}
+
+		c.Next()
 	}
-	// err = e.LoadPolicy()
-	// if err != nil {
-	// 	panic(err)
-	// }
+}
+
+var e *casbin.Enforcer
+var loadOnce sync.Once
+
+// loadEnforcer reads the policy on first use rather than in init, so the
+// package can be imported from anywhere, tests included
+func loadEnforcer() {
+	loadOnce.Do(func() {
+		// adapter, err := xormadapter.NewAdapter("mysql", config.MustGet().Casbin.DSN)
+		// if err != nil {
+		// 	panic(err)
+		// }
+		var err error
+		e, err = casbin.NewEnforcer("casbin/model.conf", "casbin/policy.csv")
+		if err != nil {
+			panic(err)
+		}
+		// err = e.LoadPolicy()
+		// if err != nil {
+		// 	panic(err)
+		// }
+	})
 }
 
 func Authorize() gin.HandlerFunc {
+	loadEnforcer()
 	return func(c *gin.Context) {
 		sub := c.GetString("user")
 		obj := c.Request.URL.Path
@@ -99,6 +121,7 @@ func AuthMiddleware(tokenService service.ITokenService) gin.HandlerFunc {
 		// Add user ID to context
 		c.Set("userID", claims.UserID)
 		c.Set("userRole", claims.Role)
+		c.Request = c.Request.WithContext(utils.WithCurrentUser(c.Request.Context(), claims.UserID, claims.Role))
 
 		c.Next()
 	}
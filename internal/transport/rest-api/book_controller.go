@@ -1,34 +1,75 @@
 package restapi
 
 import (
+	isbnlib "book_system/internal/baselib/isbn"
+	"book_system/internal/baselib/jsonpatch"
+	"book_system/internal/baselib/marc"
 	"book_system/internal/model"
 	"book_system/internal/service"
+	"book_system/internal/transport/middleware"
 	"book_system/internal/transport/response"
+	"bytes"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // BookController handles book related HTTP requests
 type BookController struct {
-	bookService service.IBookService
+	bookService    service.IBookService
+	lookupService  service.IBookLookupService
+	coverService   service.IBookCoverService
+	pricingService service.IPricingService
+	recommender    service.IRecommendationService
+	requireIfMatch bool
 }
 
-// NewBookController creates a new book transport
-func NewBookController(bookService service.IBookService) *BookController {
+// NewBookController creates a new book transport. With requireIfMatch set,
+// writes without an If-Match header are rejected with 428.
+func NewBookController(
+	bookService service.IBookService,
+	lookupService service.IBookLookupService,
+	coverService service.IBookCoverService,
+	pricingService service.IPricingService,
+	recommender service.IRecommendationService,
+	requireIfMatch bool,
+) *BookController {
 	return &BookController{
-		bookService: bookService,
+		bookService:    bookService,
+		lookupService:  lookupService,
+		coverService:   coverService,
+		pricingService: pricingService,
+		recommender:    recommender,
+		requireIfMatch: requireIfMatch,
 	}
 }
 
 func (c *BookController) SetupBooksRoutes(router *gin.RouterGroup) {
 	router.POST("", c.CreateBook)
+	router.POST("lookup/:isbn", c.LookupBook)
 	router.GET("", c.ListBooks)
 	router.GET(":id", c.GetBookByID)
 	router.PUT(":id", c.UpdateBook)
+	router.PATCH(":id", c.PatchBook)
 	router.DELETE(":id", c.DeleteBook)
+	router.GET(":id/history", c.GetBookHistory)
+	router.GET(":id/versions/:version", c.GetBookVersion)
+	router.POST(":id/versions/:version/revert", c.RevertBook)
+	router.POST(":id/cover", c.UploadCover)
+	router.GET(":id/translations", c.GetTranslations)
+	router.PUT(":id/translations/:lang", c.SetTranslation)
+	router.DELETE(":id/translations/:lang", c.DeleteTranslation)
+
+	// Trash management (admin only)
+	admin := router.Group("", middleware.RequireRole("admin"))
+	admin.GET("trash", c.ListTrashedBooks)
+	admin.POST(":id/restore", c.RestoreBook)
+	admin.DELETE(":id/purge", c.PurgeBook)
 }
 
 // CreateBook godoc
@@ -38,10 +79,13 @@ func (c *BookController) SetupBooksRoutes(router *gin.RouterGroup) {
 // @Accept  json
 // @Produce  json
 // @Param input body model.CreateBookRequest true "Book data"
+// @Param enrich query bool false "Fill empty fields from the ISBN's metadata before validating. A request with every required field is created as sent when the metadata cannot be fetched"
 // @Success 201 {object} response.Response{data=model.BookResponse} "Successfully created book"
 // @Failure 400 {object} response.Response "Invalid input"
+// @Failure 404 {object} response.Response "No metadata found for the ISBN of an incomplete request (enrich only)"
 // @Failure 409 {object} response.Response "Book with this ISBN already exists"
 // @Failure 500 {object} response.Response "Internal server error"
+// @Failure 502 {object} response.Response "Metadata provider failed for an incomplete request (enrich only)"
 // @Router /api/v1/books [post]
 func (c *BookController) CreateBook(ctx *gin.Context) {
 	var req model.CreateBookRequest
@@ -50,6 +94,17 @@ func (c *BookController) CreateBook(ctx *gin.Context) {
 		return
 	}
 
+	if enrich, _ := strconv.ParseBool(ctx.Query("enrich")); enrich {
+		if err := c.lookupService.Enrich(ctx.Request.Context(), &req); err != nil {
+			// A complete request does not need the metadata
+			if req.Validate() != nil {
+				c.lookupError(ctx, err)
+				return
+			}
+			slog.Warn("Creating book without its ISBN metadata", slog.String("isbn", req.ISBN), slog.Any("error", err))
+		}
+	}
+
 	// Validate request
 	if err := req.Validate(); err != nil {
 		response.BadRequest(ctx, err.Error())
@@ -58,26 +113,75 @@ func (c *BookController) CreateBook(ctx *gin.Context) {
 
 	book, err := c.bookService.CreateBook(ctx.Request.Context(), &req)
 	if err != nil {
-		if err.Error() == "book with this ISBN already exists" {
+		switch {
+		case errors.Is(err, service.ErrWorkNotFound), errors.Is(err, service.ErrCategoryNotFound):
+			response.BadRequest(ctx, err.Error())
+		case errors.Is(err, service.ErrBookISBNExists):
 			response.JSON(ctx, http.StatusConflict, err.Error(), nil)
-			return
+		default:
+			slog.Error("Failed to create book", slog.Any("error", err))
+			response.InternalServerError(ctx, "Failed to create book")
 		}
-		slog.Error("Failed to create book", slog.Any("error", err))
-		response.InternalServerError(ctx, "Failed to create book")
 		return
 	}
 
+	ctx.Header("ETag", jsonETag(book))
 	response.Created(ctx, book)
 }
 
+// LookupBook godoc
+// @Summary Look up book metadata by ISBN
+// @Description Fetch title, authors, description, publication date and cover for an ISBN from the metadata provider. The cover is copied into storage. The response includes a create request pre-filled from the metadata
+// @Tags books
+// @Produce  json
+// @Param isbn path string true "ISBN-10 or ISBN-13"
+// @Success 200 {object} response.Response{data=model.BookLookupResponse} "Metadata found"
+// @Failure 400 {object} response.Response "Invalid ISBN"
+// @Failure 404 {object} response.Response "No metadata found for the ISBN"
+// @Failure 502 {object} response.Response "Metadata provider failed"
+// @Router /api/v1/books/lookup/{isbn} [post]
+func (c *BookController) LookupBook(ctx *gin.Context) {
+	metadata, err := c.lookupService.LookupISBN(ctx.Request.Context(), ctx.Param("isbn"))
+	if err != nil {
+		c.lookupError(ctx, err)
+		return
+	}
+
+	response.Success(ctx, &model.BookLookupResponse{
+		Metadata: metadata,
+		Book:     metadata.ToCreateRequest(),
+	})
+}
+
+// lookupError writes the response for a failed metadata lookup
+func (c *BookController) lookupError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidISBN):
+		response.BadRequest(ctx, err.Error())
+	case errors.Is(err, service.ErrBookMetadataNotFound):
+		response.NotFound(ctx, "No metadata found for this ISBN")
+	case errors.Is(err, service.ErrMetadataProviderError):
+		slog.Error("Metadata provider failed", slog.Any("error", err))
+		response.JSON(ctx, http.StatusBadGateway, "Metadata provider failed", nil)
+	default:
+		slog.Error("Failed to look up book metadata", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to look up book metadata")
+	}
+}
+
 // GetBookByID godoc
 // @Summary Get a book by ID
-// @Description Get a book by its ID
+// @Description Get a book by its ID. The response carries an ETag of the book's version and of the rendered body, so it changes with stock, rating, language, currency and format too. With format=marcxml the book is returned as a MARCXML bibliographic record. display_price is the list price in the requested currency, else the user's preferred currency, else the catalog currency
 // @Tags books
 // @Accept  json
-// @Produce  json
+// @Produce  json,application/marcxml+xml
 // @Param id path string true "Book ID"
+// @Param format query string false "json (default) or marcxml"
+// @Param currency query string false "ISO 4217 currency code for the display price"
+// @Param Accept-Language header string false "Preferred languages for the title, description and category name, with q-values"
+// @Param If-None-Match header string false "ETag from a previous read"
 // @Success 200 {object} response.Response{data=model.BookResponse} "Successfully retrieved book"
+// @Success 304 "Book has not changed since the given ETag"
 // @Failure 400 {object} response.Response "Invalid book ID"
 // @Failure 404 {object} response.Response "Book not found"
 // @Failure 500 {object} response.Response "Internal server error"
@@ -89,9 +193,19 @@ func (c *BookController) GetBookByID(ctx *gin.Context) {
 		return
 	}
 
+	format := ctx.DefaultQuery("format", "json")
+	if format != "json" && format != "marcxml" {
+		response.BadRequest(ctx, "format must be json or marcxml")
+		return
+	}
+
 	book, err := c.bookService.GetBookByID(ctx.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "book not found" {
+		switch {
+		case errors.Is(err, service.ErrInvalidBookID):
+			response.BadRequest(ctx, "Invalid book ID")
+			return
+		case errors.Is(err, service.ErrBookNotFound):
 			response.NotFound(ctx, "Book not found")
 			return
 		}
@@ -100,18 +214,67 @@ func (c *BookController) GetBookByID(ctx *gin.Context) {
 		return
 	}
 
+	// The body varies with the language and, through the preferred
+	// currency, with the user; a 304 has to say so as well
+	ctx.Header("Vary", "Accept-Language, Authorization")
+
+	if format == "marcxml" {
+		var record bytes.Buffer
+		if err := marc.WriteXML(&record, book.ToMARC()); err != nil {
+			slog.Error("Failed to write MARCXML record", slog.Any("error", err))
+			response.InternalServerError(ctx, "Failed to get book")
+			return
+		}
+		if c.notModified(ctx, representationETag(book.Version, record.Bytes())) {
+			return
+		}
+		ctx.Data(http.StatusOK, marc.MediaType, record.Bytes())
+		return
+	}
+
+	if !c.displayPrices(ctx, []*model.BookResponse{book}) {
+		return
+	}
+	book.Localize(ctx.GetHeader("Accept-Language"))
+	ctx.Header("Content-Language", book.ContentLanguage)
+	if c.notModified(ctx, jsonETag(book)) {
+		return
+	}
+
+	// Only full reads count as views, not revalidations or catalog exports.
+	// A view that cannot be recorded only weakens recommendations.
+	if err := c.recommender.RecordView(ctx.Request.Context(), book.ID); err != nil {
+		slog.Warn("Failed to record book view", slog.String("book_id", book.ID.String()), slog.Any("error", err))
+	}
 	response.Success(ctx, book)
 }
 
+// notModified sets the ETag of a rendered book and answers 304 when the
+// request's If-None-Match already has it, reporting whether it did
+func (c *BookController) notModified(ctx *gin.Context, etag string) bool {
+	ctx.Header("ETag", etag)
+	if ifNoneMatch := ctx.GetHeader("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag) {
+		ctx.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
 // ListBooks godoc
 // @Summary List all books with pagination
-// @Description Get a paginated list of books with optional filters
+// @Description Get a paginated list of books with optional filters. display_price is the list price in the requested currency, else the user's preferred currency, else the catalog currency
 // @Tags books
 // @Accept  json
 // @Produce  json
 // @Param page query int false "Page number (default: 1)"
 // @Param page_size query int false "Number of items per page (default: 10, max: 100)"
 // @Param author query string false "Filter by author"
+// @Param isbn query string false "Filter by ISBN-10 or ISBN-13, with or without hyphens"
+// @Param category_id query string false "Filter by category ID"
+// @Param min_rating query number false "Only books rated at least this many stars on average, 1 to 5"
+// @Param sort query string false "Order: rating for the best rated first, reviews for the most rated first"
+// @Param currency query string false "ISO 4217 currency code for display prices"
+// @Param Accept-Language header string false "Preferred languages for titles, descriptions and category names, with q-values"
 // @Success 200 {object} response.Response{data=model.BookListResponse} "Successfully retrieved books"
 // @Failure 400 {object} response.Response "Invalid query parameters"
 // @Failure 500 {object} response.Response "Internal server error"
@@ -121,35 +284,67 @@ func (c *BookController) ListBooks(ctx *gin.Context) {
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
 
-	// Build filters
-	filters := make(map[string]any)
-	if author := ctx.Query("author"); author != "" {
-		filters["author = ?"] = author
-	}
-
 	// Get books from service
-	result, err := c.bookService.ListBooks(ctx.Request.Context(), page, pageSize, filters)
+	result, err := c.bookService.ListBooks(ctx.Request.Context(), page, pageSize, bookFilters(ctx), ctx.Query("sort"))
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidBookSort) {
+			response.BadRequest(ctx, err.Error())
+			return
+		}
 		slog.Error("Failed to list books", slog.Any("error", err))
 		response.InternalServerError(ctx, "Failed to list books")
 		return
 	}
 
+	if !c.displayPrices(ctx, result.Data) {
+		return
+	}
+	acceptLanguage := ctx.GetHeader("Accept-Language")
+	for _, book := range result.Data {
+		book.Localize(acceptLanguage)
+	}
+	ctx.Header("Vary", "Accept-Language, Authorization")
 	response.Success(ctx, result)
 }
 
+// bookFilters builds the repository filters from the book list query
+// parameters. An ISBN matches in any form. A minimum rating outside 1 to 5
+// is ignored.
+func bookFilters(ctx *gin.Context) map[string]any {
+	filters := make(map[string]any)
+	if author := ctx.Query("author"); author != "" {
+		filters["author = ?"] = author
+	}
+	if value := ctx.Query("isbn"); value != "" {
+		if isbn13, err := isbnlib.Normalize(value); err == nil {
+			value = isbn13
+		}
+		filters["isbn = ?"] = value
+	}
+	if categoryID, err := uuid.Parse(ctx.Query("category_id")); err == nil {
+		filters["category_id = ?"] = categoryID
+	}
+	if minRating, err := strconv.ParseFloat(ctx.Query("min_rating"), 64); err == nil && minRating >= 1 && minRating <= 5 {
+		filters["rating_count > 0 AND rating_sum >= ? * rating_count"] = minRating
+	}
+	return filters
+}
+
 // UpdateBook godoc
-// @Summary Update a book
-// @Description Update a book with the input payload
+// @Summary Replace a book
+// @Description Replace all editable fields of a book. Omitted optional fields are cleared; use PATCH for partial updates. Stock is not editable here and is refused; change it through POST /books/{id}/stock/adjust
 // @Tags books
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Book ID"
-// @Param book body model.UpdateBookRequest true "Update book"
+// @Param If-Match header string false "ETag of the version being updated"
+// @Param book body model.ReplaceBookRequest true "Book data"
 // @Success 200 {object} response.Response{data=model.BookResponse} "Successfully updated book"
-// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 400 {object} response.Response "Invalid input, or a stock was sent"
 // @Failure 404 {object} response.Response "Book not found"
 // @Failure 409 {object} response.Response "Book with this ISBN already exists"
+// @Failure 412 {object} response.Response "Book was modified since the given ETag, or If-Match is not a single strong ETag"
+// @Failure 428 {object} response.Response "If-Match header is required"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /api/v1/books/{id} [put]
 func (c *BookController) UpdateBook(ctx *gin.Context) {
@@ -159,7 +354,12 @@ func (c *BookController) UpdateBook(ctx *gin.Context) {
 		return
 	}
 
-	var req model.UpdateBookRequest
+	expectedVersion, ok := c.expectedVersion(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.ReplaceBookRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		response.BadRequest(ctx, "Invalid request body")
 		return
@@ -171,13 +371,20 @@ func (c *BookController) UpdateBook(ctx *gin.Context) {
 		return
 	}
 
-	book, err := c.bookService.UpdateBook(ctx.Request.Context(), id, &req)
+	book, err := c.bookService.UpdateBook(ctx.Request.Context(), id, req.ToUpdate(), expectedVersion)
 	if err != nil {
-		switch err.Error() {
-		case "book not found":
+		switch {
+		case errors.Is(err, service.ErrInvalidBookID):
+			response.BadRequest(ctx, "Invalid book ID")
+		case errors.Is(err, service.ErrWorkNotFound), errors.Is(err, service.ErrCategoryNotFound),
+			errors.Is(err, service.ErrStockNotEditable):
+			response.BadRequest(ctx, err.Error())
+		case errors.Is(err, service.ErrBookNotFound):
 			response.JSON(ctx, http.StatusNotFound, err.Error(), nil)
-		case "book with this ISBN already exists":
+		case errors.Is(err, service.ErrBookISBNExists):
 			response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+		case errors.Is(err, service.ErrBookVersionMismatch):
+			response.JSON(ctx, http.StatusPreconditionFailed, err.Error(), nil)
 		default:
 			slog.Error("Failed to update book", slog.Any("error", err))
 			response.InternalServerError(ctx, "Failed to update book")
@@ -185,19 +392,89 @@ func (c *BookController) UpdateBook(ctx *gin.Context) {
 		return
 	}
 
+	ctx.Header("ETag", jsonETag(book))
+	response.Success(ctx, book)
+}
+
+// PatchBook godoc
+// @Summary Partially update a book
+// @Description Apply a JSON Merge Patch (RFC 7396) or JSON Patch (RFC 6902) to a book. Optional fields can be cleared by setting them to null (merge patch) or removing them (JSON Patch). Stock is not part of the patched document; change it through POST /books/{id}/stock/adjust
+// @Tags books
+// @Accept  application/merge-patch+json
+// @Accept  application/json-patch+json
+// @Produce  json
+// @Param id path string true "Book ID"
+// @Param If-Match header string false "ETag of the version being updated"
+// @Param patch body object true "Patch document"
+// @Success 200 {object} response.Response{data=model.BookResponse} "Successfully updated book"
+// @Failure 400 {object} response.Response "Invalid patch, patched book fails validation, or the patch sets a stock"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 409 {object} response.Response "ISBN already exists or a test operation failed"
+// @Failure 412 {object} response.Response "Book was modified since the given ETag, or If-Match is not a single strong ETag"
+// @Failure 415 {object} response.Response "Unsupported patch media type"
+// @Failure 428 {object} response.Response "If-Match header is required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id} [patch]
+func (c *BookController) PatchBook(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	mediaType := ctx.ContentType()
+	if mediaType != jsonpatch.MergePatchMediaType && mediaType != jsonpatch.JSONPatchMediaType {
+		ctx.Header("Accept-Patch", jsonpatch.MergePatchMediaType+", "+jsonpatch.JSONPatchMediaType)
+		response.JSON(ctx, http.StatusUnsupportedMediaType, "Unsupported patch media type", nil)
+		return
+	}
+
+	expectedVersion, ok := c.expectedVersion(ctx)
+	if !ok {
+		return
+	}
+
+	const maxPatchSize = 1 << 20 // 1 MB
+	patch, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxPatchSize))
+	if err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	book, err := c.bookService.PatchBook(ctx.Request.Context(), id, mediaType, patch, expectedVersion)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidBookID):
+			response.BadRequest(ctx, "Invalid book ID")
+		case errors.Is(err, service.ErrInvalidBookPatch), errors.Is(err, service.ErrWorkNotFound),
+			errors.Is(err, service.ErrCategoryNotFound), errors.Is(err, service.ErrStockNotEditable):
+			response.BadRequest(ctx, err.Error())
+		case errors.Is(err, service.ErrBookNotFound):
+			response.NotFound(ctx, "Book not found")
+		case errors.Is(err, service.ErrBookISBNExists), errors.Is(err, service.ErrBookPatchTestFailed):
+			response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+		case errors.Is(err, service.ErrBookVersionMismatch):
+			response.JSON(ctx, http.StatusPreconditionFailed, err.Error(), nil)
+		default:
+			slog.Error("Failed to patch book", slog.Any("error", err))
+			response.InternalServerError(ctx, "Failed to patch book")
+		}
+		return
+	}
+
+	ctx.Header("ETag", jsonETag(book))
 	response.Success(ctx, book)
 }
 
 // DeleteBook godoc
 // @Summary Delete a book
-// @Description Delete a book by its ID
+// @Description Move a book to the trash. It can be restored until it is purged
 // @Tags books
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Book ID"
+// @Param If-Match header string false "ETag of the version being deleted"
 // @Success 200 {object} response.Response "Successfully deleted book"
 // @Failure 400 {object} response.Response "Invalid book ID"
 // @Failure 404 {object} response.Response "Book not found"
+// @Failure 412 {object} response.Response "Book was modified since the given ETag, or If-Match is not a single strong ETag"
+// @Failure 428 {object} response.Response "If-Match header is required"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /api/v1/books/{id} [delete]
 func (c *BookController) DeleteBook(ctx *gin.Context) {
@@ -207,11 +484,23 @@ func (c *BookController) DeleteBook(ctx *gin.Context) {
 		return
 	}
 
-	err := c.bookService.DeleteBook(ctx.Request.Context(), id)
+	expectedVersion, ok := c.expectedVersion(ctx)
+	if !ok {
+		return
+	}
+
+	err := c.bookService.DeleteBook(ctx.Request.Context(), id, expectedVersion)
 	if err != nil {
-		if err.Error() == "book not found" {
+		switch {
+		case errors.Is(err, service.ErrInvalidBookID):
+			response.BadRequest(ctx, "Invalid book ID")
+			return
+		case errors.Is(err, service.ErrBookNotFound):
 			response.NotFound(ctx, "Book not found")
 			return
+		case errors.Is(err, service.ErrBookVersionMismatch):
+			response.JSON(ctx, http.StatusPreconditionFailed, err.Error(), nil)
+			return
 		}
 		slog.Error("Failed to delete book", slog.Any("error", err))
 		response.InternalServerError(ctx, "Failed to delete book")
@@ -220,3 +509,265 @@ func (c *BookController) DeleteBook(ctx *gin.Context) {
 
 	response.Success(ctx, nil)
 }
+
+// ListTrashedBooks godoc
+// @Summary List books in the trash
+// @Description Get a paginated list of soft-deleted books (admin only)
+// @Tags books
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=model.BookListResponse} "Successfully retrieved trashed books"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/trash [get]
+func (c *BookController) ListTrashedBooks(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	result, err := c.bookService.ListTrashedBooks(ctx.Request.Context(), page, pageSize)
+	if err != nil {
+		slog.Error("Failed to list trashed books", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to list trashed books")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// RestoreBook godoc
+// @Summary Restore a book from the trash
+// @Description Move a soft-deleted book back to the catalog (admin only)
+// @Tags books
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Success 200 {object} response.Response{data=model.BookResponse} "Successfully restored book"
+// @Failure 400 {object} response.Response "Invalid book ID"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Book not found in trash"
+// @Failure 409 {object} response.Response "Another book already uses this ISBN"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/restore [post]
+func (c *BookController) RestoreBook(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	book, err := c.bookService.RestoreBook(ctx.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidBookID):
+			response.BadRequest(ctx, "Invalid book ID")
+		case errors.Is(err, service.ErrBookNotFound):
+			response.NotFound(ctx, "Book not found in trash")
+		case errors.Is(err, service.ErrBookISBNExists):
+			response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+		default:
+			slog.Error("Failed to restore book", slog.Any("error", err))
+			response.InternalServerError(ctx, "Failed to restore book")
+		}
+		return
+	}
+
+	response.Success(ctx, book)
+}
+
+// PurgeBook godoc
+// @Summary Permanently delete a book
+// @Description Permanently delete a book in the trash; live books must be deleted first (admin only)
+// @Tags books
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Success 200 {object} response.Response "Successfully purged book"
+// @Failure 400 {object} response.Response "Invalid book ID"
+// @Failure 403 {object} response.Response "Forbidden"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/purge [delete]
+func (c *BookController) PurgeBook(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	if err := c.bookService.PurgeBook(ctx.Request.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidBookID):
+			response.BadRequest(ctx, "Invalid book ID")
+			return
+		case errors.Is(err, service.ErrBookNotFound):
+			response.NotFound(ctx, "Book not found")
+			return
+		}
+		slog.Error("Failed to purge book", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to purge book")
+		return
+	}
+
+	response.Success(ctx, nil)
+}
+
+// GetBookHistory godoc
+// @Summary Get the change history of a book
+// @Description Get a paginated list of versions of a book with actor, timestamp and field-level changes, newest first
+// @Tags books
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=model.BookHistoryResponse} "Successfully retrieved book history"
+// @Failure 400 {object} response.Response "Invalid book ID"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/history [get]
+func (c *BookController) GetBookHistory(ctx *gin.Context) {
+	id := ctx.Param("id")
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	result, err := c.bookService.GetBookHistory(ctx.Request.Context(), id, page, pageSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidBookID):
+			response.BadRequest(ctx, "Invalid book ID")
+		case errors.Is(err, service.ErrBookNotFound):
+			response.NotFound(ctx, "Book not found")
+		default:
+			slog.Error("Failed to get book history", slog.Any("error", err))
+			response.InternalServerError(ctx, "Failed to get book history")
+		}
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// GetBookVersion godoc
+// @Summary Get a version of a book
+// @Description Get a book as it was at the given version
+// @Tags books
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param version path int true "Version number"
+// @Success 200 {object} response.Response{data=model.BookVersionResponse} "Successfully retrieved book version"
+// @Failure 400 {object} response.Response "Invalid book ID or version"
+// @Failure 404 {object} response.Response "Book version not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/versions/{version} [get]
+func (c *BookController) GetBookVersion(ctx *gin.Context) {
+	id := ctx.Param("id")
+	version, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil || version < 1 {
+		response.BadRequest(ctx, "Invalid version")
+		return
+	}
+
+	result, err := c.bookService.GetBookVersion(ctx.Request.Context(), id, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidBookID):
+			response.BadRequest(ctx, "Invalid book ID")
+		case errors.Is(err, service.ErrBookVersionNotFound):
+			response.NotFound(ctx, "Book version not found")
+		default:
+			slog.Error("Failed to get book version", slog.Any("error", err))
+			response.InternalServerError(ctx, "Failed to get book version")
+		}
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// RevertBook godoc
+// @Summary Revert a book to a previous version
+// @Description Restore a book's fields to those of the given version, recording the revert as a new version
+// @Tags books
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param version path int true "Version number"
+// @Success 200 {object} response.Response{data=model.BookResponse} "Successfully reverted book"
+// @Failure 400 {object} response.Response "Invalid book ID or version"
+// @Failure 404 {object} response.Response "Book or version not found"
+// @Failure 409 {object} response.Response "Another book already uses the version's ISBN"
+// @Failure 412 {object} response.Response "Book was modified concurrently"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/versions/{version}/revert [post]
+func (c *BookController) RevertBook(ctx *gin.Context) {
+	id := ctx.Param("id")
+	version, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil || version < 1 {
+		response.BadRequest(ctx, "Invalid version")
+		return
+	}
+
+	book, err := c.bookService.RevertBook(ctx.Request.Context(), id, version)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidBookID):
+			response.BadRequest(ctx, "Invalid book ID")
+		case errors.Is(err, service.ErrBookNotFound):
+			response.NotFound(ctx, "Book not found")
+		case errors.Is(err, service.ErrBookVersionNotFound):
+			response.NotFound(ctx, "Book version not found")
+		case errors.Is(err, service.ErrWorkNotFound), errors.Is(err, service.ErrCategoryNotFound):
+			response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+		case errors.Is(err, service.ErrBookISBNExists):
+			response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+		case errors.Is(err, service.ErrBookVersionMismatch):
+			response.JSON(ctx, http.StatusPreconditionFailed, err.Error(), nil)
+		default:
+			slog.Error("Failed to revert book", slog.Any("error", err))
+			response.InternalServerError(ctx, "Failed to revert book")
+		}
+		return
+	}
+
+	response.Success(ctx, book)
+}
+
+// expectedVersion reads the If-Match header of a write request. It writes
+// the error response itself and returns false when the request must stop.
+func (c *BookController) expectedVersion(ctx *gin.Context) (int, bool) {
+	ifMatch := ctx.GetHeader("If-Match")
+	if ifMatch == "" && c.requireIfMatch {
+		response.JSON(ctx, http.StatusPreconditionRequired, "If-Match header is required", nil)
+		return 0, false
+	}
+
+	version, err := parseIfMatch(ifMatch)
+	if err != nil {
+		if errors.Is(err, errUnmatchableETag) {
+			response.JSON(ctx, http.StatusPreconditionFailed, err.Error(), nil)
+			return 0, false
+		}
+		response.BadRequest(ctx, "Invalid If-Match header")
+		return 0, false
+	}
+
+	return version, true
+}
+
+// displayPrices sets the display price of books in the currency the
+// request asks for, writing the error response and reporting false when
+// it cannot
+func (c *BookController) displayPrices(ctx *gin.Context, books []*model.BookResponse) bool {
+	err := c.pricingService.DisplayPrices(ctx.Request.Context(), books, ctx.Query("currency"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCurrency) {
+			response.BadRequest(ctx, err.Error())
+			return false
+		}
+		slog.Error("Failed to convert book prices", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to convert book prices")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package restapi
+
+import (
+	"book_system/internal/service"
+	"book_system/internal/service/cover_service"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadCover godoc
+// @Summary Upload a book cover
+// @Description Upload a JPEG, PNG or WebP cover image for a book. The content must be a real image; it is re-encoded without EXIF data into thumbnail, medium and large variants, whose URLs are returned in the book's cover field. The large variant becomes the book's cover_image, and the variants of the replaced cover are deleted
+// @Tags books
+// @Accept  multipart/form-data
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param file formData file true "Cover image"
+// @Param If-Match header string false "ETag of the version being replaced"
+// @Success 200 {object} response.Response{data=model.BookResponse} "Cover uploaded"
+// @Failure 400 {object} response.Response "Invalid book ID or image"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 412 {object} response.Response "Book has been modified"
+// @Failure 428 {object} response.Response "If-Match header is required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/cover [post]
+func (c *BookController) UploadCover(ctx *gin.Context) {
+	expectedVersion, ok := c.expectedVersion(ctx)
+	if !ok {
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		response.BadRequest(ctx, "Cover image is required")
+		return
+	}
+	if fileHeader.Size > cover_service.MaxCoverSize {
+		response.BadRequest(ctx, "file size exceeds the limit of 10MB")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.BadRequest(ctx, "Invalid file")
+		return
+	}
+	defer file.Close()
+
+	book, err := c.coverService.UploadCover(ctx.Request.Context(), ctx.Param("id"), file, expectedVersion)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidBookID):
+			response.BadRequest(ctx, "Invalid book ID")
+		case errors.Is(err, service.ErrInvalidCover):
+			response.BadRequest(ctx, err.Error())
+		case errors.Is(err, service.ErrBookNotFound):
+			response.NotFound(ctx, "Book not found")
+		case errors.Is(err, service.ErrBookVersionMismatch):
+			response.JSON(ctx, http.StatusPreconditionFailed, err.Error(), nil)
+		default:
+			slog.Error("Failed to upload book cover", slog.Any("error", err))
+			response.InternalServerError(ctx, "Failed to upload book cover")
+		}
+		return
+	}
+
+	ctx.Header("ETag", jsonETag(book))
+	response.Success(ctx, book)
+}
@@ -0,0 +1,119 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BookExportController handles catalog export requests
+type BookExportController struct {
+	exportService service.IBookExportService
+}
+
+// NewBookExportController creates a new book export transport
+func NewBookExportController(exportService service.IBookExportService) *BookExportController {
+	return &BookExportController{
+		exportService: exportService,
+	}
+}
+
+func (c *BookExportController) SetupBookExportRoutes(router *gin.RouterGroup) {
+	router.GET("", c.ExportBooks)
+	router.GET(":job_id", c.GetExportJob)
+}
+
+// ExportBooks godoc
+// @Summary Export books
+// @Description Export the books matching the list filters as CSV, NDJSON, XLSX or an ONIX 3.0 message. The file is streamed as it is read from the database; with async=true it is written to storage in the background instead, and the returned job links to it once done
+// @Tags books
+// @Accept  json
+// @Produce  text/csv,application/x-ndjson,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,application/xml,json
+// @Security BearerAuth
+// @Param format query string false "csv, ndjson, xlsx or onix (default: csv)"
+// @Param async query bool false "Export to storage in the background"
+// @Param author query string false "Filter by author"
+// @Param isbn query string false "Filter by ISBN-10 or ISBN-13, with or without hyphens"
+// @Param min_rating query number false "Only books rated at least this many stars on average, 1 to 5"
+// @Success 200 {file} file "Exported books"
+// @Success 202 {object} response.Response{data=model.JobResponse} "Export job started"
+// @Failure 400 {object} response.Response "Invalid query parameters"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Failure 503 {object} response.Response "Too many exports are waiting"
+// @Router /api/v1/books/export [get]
+func (c *BookExportController) ExportBooks(ctx *gin.Context) {
+	var req model.BookExportRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(ctx, "Invalid query parameters")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+	if req.Format == "" {
+		req.Format = model.BookExportFormatCSV
+	}
+
+	if req.Async {
+		job, err := c.exportService.StartExport(ctx.Request.Context(), req.Format, bookFilters(ctx))
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidExport) {
+				response.BadRequest(ctx, err.Error())
+				return
+			}
+			if errors.Is(err, service.ErrJobQueueFull) {
+				response.JSON(ctx, http.StatusServiceUnavailable, err.Error(), nil)
+				return
+			}
+			slog.Error("Failed to start book export", slog.Any("error", err))
+			response.InternalServerError(ctx, "Failed to start book export")
+			return
+		}
+		response.JSON(ctx, http.StatusAccepted, "accepted", job)
+		return
+	}
+
+	ctx.Header("Content-Type", model.BookExportContentTypes[req.Format])
+	ctx.Header("Content-Disposition", "attachment; filename="+model.BookExportFileName(req.Format))
+	ctx.Status(http.StatusOK)
+
+	// Headers are already sent, so a failure can only cut the stream short
+	if err := c.exportService.ExportBooks(ctx.Request.Context(), req.Format, bookFilters(ctx), ctx.Writer); err != nil {
+		slog.Error("Failed to export books", slog.Any("error", err))
+		_ = ctx.Error(err)
+	}
+}
+
+// GetExportJob godoc
+// @Summary Get a book export job
+// @Description Get the status of an async export job started by the current user; admins see every job. Once it has completed, result_url is a presigned link to the exported file
+// @Tags books
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param job_id path string true "Job ID"
+// @Success 200 {object} response.Response{data=model.JobResponse} "Successfully retrieved export job"
+// @Failure 404 {object} response.Response "Job not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/export/{job_id} [get]
+func (c *BookExportController) GetExportJob(ctx *gin.Context) {
+	job, err := c.exportService.GetExportJob(ctx.Request.Context(), ctx.Param("job_id"))
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			response.NotFound(ctx, "Job not found")
+			return
+		}
+		slog.Error("Failed to get export job", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to get export job")
+		return
+	}
+
+	response.Success(ctx, job)
+}
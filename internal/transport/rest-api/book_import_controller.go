@@ -0,0 +1,121 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize bounds catalog files uploaded directly with an import request
+const maxImportSize = 200 << 20 // 200 MB
+
+// BookImportController handles bulk book import requests
+type BookImportController struct {
+	importService service.IBookImportService
+}
+
+// NewBookImportController creates a new book import transport
+func NewBookImportController(importService service.IBookImportService) *BookImportController {
+	return &BookImportController{
+		importService: importService,
+	}
+}
+
+func (c *BookImportController) SetupBookImportRoutes(router *gin.RouterGroup) {
+	router.POST("", c.StartImport)
+	router.GET(":job_id", c.GetImportJob)
+}
+
+// StartImport godoc
+// @Summary Import books in bulk
+// @Description Start a background import of books from a CSV, NDJSON, ONIX 3.0, MARC 21 or MARCXML file, either uploaded as "file" or referenced by the name of an object already uploaded to storage. Each row, ONIX product or MARC record is validated like a create request and upserted by ISBN; MARC records are also kept whole on the book. Poll the returned job for progress, the per-row error report and, for ONIX and MARC, the counts of data that could not be mapped
+// @Tags books
+// @Accept  multipart/form-data
+// @Produce  json
+// @Security BearerAuth
+// @Param file formData file false "CSV, NDJSON, ONIX, MARC or MARCXML file"
+// @Param object formData string false "Name or URL of an uploaded object to import instead of a file"
+// @Param format formData string false "csv, ndjson, onix, marc or marcxml (default: inferred from the file name; .xml is read as ONIX)"
+// @Param dry_run formData bool false "Validate and report without writing any book"
+// @Success 202 {object} response.Response{data=model.JobResponse} "Import job started"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Failure 503 {object} response.Response "Too many imports are waiting"
+// @Router /api/v1/books/import [post]
+func (c *BookImportController) StartImport(ctx *gin.Context) {
+	var req model.BookImportRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	var job *model.JobResponse
+	var err error
+	if fileHeader, formErr := ctx.FormFile("file"); formErr == nil {
+		if fileHeader.Size > maxImportSize {
+			response.BadRequest(ctx, "file size exceeds the limit of 200MB")
+			return
+		}
+		file, openErr := fileHeader.Open()
+		if openErr != nil {
+			response.BadRequest(ctx, "Invalid file")
+			return
+		}
+		defer file.Close()
+		job, err = c.importService.StartImport(ctx.Request.Context(), &req, file, fileHeader.Size, fileHeader.Filename)
+	} else {
+		job, err = c.importService.StartImport(ctx.Request.Context(), &req, nil, 0, "")
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidImport) {
+			response.BadRequest(ctx, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrJobQueueFull) {
+			response.JSON(ctx, http.StatusServiceUnavailable, err.Error(), nil)
+			return
+		}
+		slog.Error("Failed to start book import", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to start book import")
+		return
+	}
+
+	response.JSON(ctx, http.StatusAccepted, "accepted", job)
+}
+
+// GetImportJob godoc
+// @Summary Get a book import job
+// @Description Get the status and counters of an import job started by the current user; admins see every job. Once it has finished with failed rows, result_url links to the per-row error report
+// @Tags books
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param job_id path string true "Job ID"
+// @Success 200 {object} response.Response{data=model.JobResponse} "Successfully retrieved import job"
+// @Failure 404 {object} response.Response "Job not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/import/{job_id} [get]
+func (c *BookImportController) GetImportJob(ctx *gin.Context) {
+	job, err := c.importService.GetImportJob(ctx.Request.Context(), ctx.Param("job_id"))
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			response.NotFound(ctx, "Job not found")
+			return
+		}
+		slog.Error("Failed to get import job", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to get import job")
+		return
+	}
+
+	response.Success(ctx, job)
+}
@@ -0,0 +1,127 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTranslations godoc
+// @Summary List the translations of a book
+// @Description Get the book's title and description in every language it has been translated into, keyed by language code
+// @Tags books
+// @Produce  json
+// @Param id path string true "Book ID"
+// @Success 200 {object} response.Response{data=model.BookTranslations} "Successfully retrieved translations"
+// @Failure 400 {object} response.Response "Invalid book ID"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/translations [get]
+func (c *BookController) GetTranslations(ctx *gin.Context) {
+	translations, err := c.bookService.GetTranslations(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.translationError(ctx, err, "Failed to get book translations")
+		return
+	}
+
+	response.Success(ctx, translations)
+}
+
+// SetTranslation godoc
+// @Summary Translate a book
+// @Description Add or replace the book's title and description in a language. The language must be one the application supports, other than the book's own
+// @Tags books
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Book ID"
+// @Param lang path string true "Language code, such as vi"
+// @Param If-Match header string false "ETag of the version being updated"
+// @Param input body model.BookTranslation true "Translated title and description"
+// @Success 200 {object} response.Response{data=model.BookResponse} "Successfully saved translation"
+// @Failure 400 {object} response.Response "Invalid input or unsupported language"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 412 {object} response.Response "Book was modified since the given ETag, or If-Match is not a single strong ETag"
+// @Failure 428 {object} response.Response "If-Match header is required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/translations/{lang} [put]
+func (c *BookController) SetTranslation(ctx *gin.Context) {
+	expectedVersion, ok := c.expectedVersion(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.BookTranslation
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	book, err := c.bookService.SetTranslation(ctx.Request.Context(), ctx.Param("id"), ctx.Param("lang"), &req, expectedVersion)
+	if err != nil {
+		c.translationError(ctx, err, "Failed to save book translation")
+		return
+	}
+
+	ctx.Header("ETag", jsonETag(book))
+	response.Success(ctx, book)
+}
+
+// DeleteTranslation godoc
+// @Summary Delete a book translation
+// @Description Remove the book's title and description in a language
+// @Tags books
+// @Produce  json
+// @Param id path string true "Book ID"
+// @Param lang path string true "Language code"
+// @Param If-Match header string false "ETag of the version being updated"
+// @Success 200 {object} response.Response{data=model.BookResponse} "Successfully deleted translation"
+// @Failure 400 {object} response.Response "Invalid book ID"
+// @Failure 404 {object} response.Response "Book or translation not found"
+// @Failure 412 {object} response.Response "Book was modified since the given ETag, or If-Match is not a single strong ETag"
+// @Failure 428 {object} response.Response "If-Match header is required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/translations/{lang} [delete]
+func (c *BookController) DeleteTranslation(ctx *gin.Context) {
+	expectedVersion, ok := c.expectedVersion(ctx)
+	if !ok {
+		return
+	}
+
+	book, err := c.bookService.DeleteTranslation(ctx.Request.Context(), ctx.Param("id"), ctx.Param("lang"), expectedVersion)
+	if err != nil {
+		c.translationError(ctx, err, "Failed to delete book translation")
+		return
+	}
+
+	ctx.Header("ETag", jsonETag(book))
+	response.Success(ctx, book)
+}
+
+// translationError writes the response for a failed translation request
+func (c *BookController) translationError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidBookID):
+		response.BadRequest(ctx, "Invalid book ID")
+	case errors.Is(err, service.ErrUnsupportedLanguage):
+		response.BadRequest(ctx, err.Error())
+	case errors.Is(err, service.ErrBookNotFound):
+		response.NotFound(ctx, "Book not found")
+	case errors.Is(err, service.ErrTranslationNotFound):
+		response.NotFound(ctx, err.Error())
+	case errors.Is(err, service.ErrBookVersionMismatch):
+		response.JSON(ctx, http.StatusPreconditionFailed, err.Error(), nil)
+	default:
+		slog.Error(msg, slog.Any("error", err))
+		response.InternalServerError(ctx, msg)
+	}
+}
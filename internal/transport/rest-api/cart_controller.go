@@ -0,0 +1,185 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CartController handles shopping cart HTTP requests
+type CartController struct {
+	cartService service.ICartService
+}
+
+// NewCartController creates a new cart transport
+func NewCartController(cartService service.ICartService) *CartController {
+	return &CartController{
+		cartService: cartService,
+	}
+}
+
+func (c *CartController) SetupCartRoutes(router *gin.RouterGroup) {
+	router.GET("", c.GetCart)
+	router.DELETE("", c.ClearCart)
+	router.POST("items", c.AddItem)
+	router.PUT("items/:book_id", c.SetItemQuantity)
+	router.DELETE("items/:book_id", c.RemoveItem)
+}
+
+// GetCart godoc
+// @Summary Get the shopping cart
+// @Description Get the current user's cart at the books' effective prices, after sale prices and discounts, in the requested currency, else the user's preferred currency, else the catalog currency. With a coupon code the cart shows what the coupon would take off, without using it. Books deleted since they were added are dropped
+// @Tags cart
+// @Produce  json
+// @Security BearerAuth
+// @Param coupon query string false "Coupon code to preview"
+// @Param currency query string false "ISO 4217 currency code to price in"
+// @Success 200 {object} response.Response{data=model.CartResponse} "Successfully retrieved cart"
+// @Failure 400 {object} response.Response "Invalid currency or coupon cannot be applied"
+// @Failure 404 {object} response.Response "Coupon not found"
+// @Failure 409 {object} response.Response "Coupon has no uses left or no exchange rate to the currency"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/cart [get]
+func (c *CartController) GetCart(ctx *gin.Context) {
+	cart, err := c.cartService.GetCart(ctx.Request.Context(), ctx.Query("coupon"), ctx.Query("currency"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get cart")
+		return
+	}
+
+	response.Success(ctx, cart)
+}
+
+// AddItem godoc
+// @Summary Add a book to the cart
+// @Description Add copies of a book to the current user's cart, on top of any already in it
+// @Tags cart
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param input body model.CartItemRequest true "Cart item"
+// @Success 200 {object} response.Response{data=model.CartResponse} "Book added"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/cart/items [post]
+func (c *CartController) AddItem(ctx *gin.Context) {
+	var req model.CartItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	cart, err := c.cartService.AddItem(ctx.Request.Context(), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to add book to cart")
+		return
+	}
+
+	response.Success(ctx, cart)
+}
+
+// SetItemQuantity godoc
+// @Summary Set the quantity of a book in the cart
+// @Description Set the number of copies of a book in the current user's cart. A quantity of 0 removes the book
+// @Tags cart
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param book_id path string true "Book ID"
+// @Param input body model.CartQuantityRequest true "Quantity"
+// @Success 200 {object} response.Response{data=model.CartResponse} "Quantity updated"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/cart/items/{book_id} [put]
+func (c *CartController) SetItemQuantity(ctx *gin.Context) {
+	var req model.CartQuantityRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	cart, err := c.cartService.SetItemQuantity(ctx.Request.Context(), ctx.Param("book_id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to update cart")
+		return
+	}
+
+	response.Success(ctx, cart)
+}
+
+// RemoveItem godoc
+// @Summary Remove a book from the cart
+// @Description Remove a book from the current user's cart
+// @Tags cart
+// @Produce  json
+// @Security BearerAuth
+// @Param book_id path string true "Book ID"
+// @Success 200 {object} response.Response{data=model.CartResponse} "Book removed"
+// @Failure 400 {object} response.Response "Invalid book ID"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/cart/items/{book_id} [delete]
+func (c *CartController) RemoveItem(ctx *gin.Context) {
+	cart, err := c.cartService.RemoveItem(ctx.Request.Context(), ctx.Param("book_id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to remove book from cart")
+		return
+	}
+
+	response.Success(ctx, cart)
+}
+
+// ClearCart godoc
+// @Summary Empty the cart
+// @Description Remove every book from the current user's cart
+// @Tags cart
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} response.Response "Cart emptied"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/cart [delete]
+func (c *CartController) ClearCart(ctx *gin.Context) {
+	if err := c.cartService.ClearCart(ctx.Request.Context()); err != nil {
+		c.writeError(ctx, err, "Failed to clear cart")
+		return
+	}
+
+	response.Success(ctx, nil)
+}
+
+// writeError writes the response for a failed cart operation
+func (c *CartController) writeError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidBookID):
+		response.BadRequest(ctx, "Invalid book ID")
+	case errors.Is(err, service.ErrInvalidCartQuantity):
+		response.BadRequest(ctx, err.Error())
+	case errors.Is(err, service.ErrBookNotFound):
+		response.NotFound(ctx, "Book not found")
+	case errors.Is(err, service.ErrCouponNotApplicable), errors.Is(err, service.ErrInvalidCurrency):
+		response.BadRequest(ctx, err.Error())
+	case errors.Is(err, service.ErrCouponNotFound):
+		response.NotFound(ctx, "Coupon not found")
+	case errors.Is(err, service.ErrCouponExhausted), errors.Is(err, service.ErrExchangeRateNotFound):
+		response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+	default:
+		slog.Error(msg, slog.Any("error", err))
+		response.InternalServerError(ctx, msg)
+	}
+}
@@ -0,0 +1,205 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/transport/middleware"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CategoryController handles category related HTTP requests
+type CategoryController struct {
+	categoryService service.ICategoryService
+}
+
+// NewCategoryController creates a new category transport
+func NewCategoryController(categoryService service.ICategoryService) *CategoryController {
+	return &CategoryController{
+		categoryService: categoryService,
+	}
+}
+
+func (c *CategoryController) SetupCategoriesRoutes(router *gin.RouterGroup) {
+	router.GET("", c.ListCategories)
+	router.GET(":id", c.GetCategory)
+
+	admin := router.Group("", middleware.RequireRole("admin"))
+	admin.POST("", c.CreateCategory)
+	admin.PUT(":id", c.UpdateCategory)
+	admin.DELETE(":id", c.DeleteCategory)
+}
+
+// CreateCategory godoc
+// @Summary Create a new category
+// @Description Create a category. The name is in the default language; translations are keyed by the other languages of the i18n bundle. Books are filed under it by setting their category_id. Admin only
+// @Tags categories
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param input body model.CategoryRequest true "Category data"
+// @Success 201 {object} response.Response{data=model.CategoryResponse} "Successfully created category"
+// @Failure 400 {object} response.Response "Invalid input or unsupported language"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 409 {object} response.Response "Category name is already taken"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/categories [post]
+func (c *CategoryController) CreateCategory(ctx *gin.Context) {
+	var req model.CategoryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	category, err := c.categoryService.CreateCategory(ctx.Request.Context(), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to create category")
+		return
+	}
+
+	response.Created(ctx, category)
+}
+
+// GetCategory godoc
+// @Summary Get a category by ID
+// @Description Get a category, named in the language that best matches Accept-Language
+// @Tags categories
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Category ID"
+// @Param Accept-Language header string false "Preferred languages for the name, with q-values"
+// @Success 200 {object} response.Response{data=model.CategoryResponse} "Successfully retrieved category"
+// @Failure 400 {object} response.Response "Invalid category ID"
+// @Failure 404 {object} response.Response "Category not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/categories/{id} [get]
+func (c *CategoryController) GetCategory(ctx *gin.Context) {
+	category, err := c.categoryService.GetCategory(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get category")
+		return
+	}
+
+	category.Localize(ctx.GetHeader("Accept-Language"))
+	response.Success(ctx, category)
+}
+
+// ListCategories godoc
+// @Summary List categories with pagination
+// @Description Get a paginated list of categories ordered by their default name, each named in the language that best matches Accept-Language
+// @Tags categories
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Param Accept-Language header string false "Preferred languages for the names, with q-values"
+// @Success 200 {object} response.Response{data=model.CategoryListResponse} "Successfully retrieved categories"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/categories [get]
+func (c *CategoryController) ListCategories(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	result, err := c.categoryService.ListCategories(ctx.Request.Context(), page, pageSize)
+	if err != nil {
+		slog.Error("Failed to list categories", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to list categories")
+		return
+	}
+
+	acceptLanguage := ctx.GetHeader("Accept-Language")
+	for _, category := range result.Data {
+		category.Localize(acceptLanguage)
+	}
+	response.Success(ctx, result)
+}
+
+// UpdateCategory godoc
+// @Summary Replace a category
+// @Description Replace the name and translations of a category. Admin only
+// @Tags categories
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Category ID"
+// @Param input body model.CategoryRequest true "Category data"
+// @Success 200 {object} response.Response{data=model.CategoryResponse} "Successfully updated category"
+// @Failure 400 {object} response.Response "Invalid input or unsupported language"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Category not found"
+// @Failure 409 {object} response.Response "Category name is already taken"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/categories/{id} [put]
+func (c *CategoryController) UpdateCategory(ctx *gin.Context) {
+	var req model.CategoryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	category, err := c.categoryService.UpdateCategory(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to update category")
+		return
+	}
+
+	response.Success(ctx, category)
+}
+
+// DeleteCategory godoc
+// @Summary Delete a category
+// @Description Delete a category no book is filed under, including books in the trash. Admin only
+// @Tags categories
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Category ID"
+// @Success 200 {object} response.Response "Successfully deleted category"
+// @Failure 400 {object} response.Response "Invalid category ID"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Category not found"
+// @Failure 409 {object} response.Response "Category still has books"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/categories/{id} [delete]
+func (c *CategoryController) DeleteCategory(ctx *gin.Context) {
+	if err := c.categoryService.DeleteCategory(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		c.writeError(ctx, err, "Failed to delete category")
+		return
+	}
+
+	response.Success(ctx, nil)
+}
+
+// writeError writes the response for a failed category operation
+func (c *CategoryController) writeError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCategoryID):
+		response.BadRequest(ctx, "Invalid category ID")
+	case errors.Is(err, service.ErrUnsupportedLanguage):
+		response.BadRequest(ctx, err.Error())
+	case errors.Is(err, service.ErrCategoryNotFound):
+		response.NotFound(ctx, "Category not found")
+	case errors.Is(err, service.ErrCategoryNameTaken), errors.Is(err, service.ErrCategoryHasBooks):
+		response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+	default:
+		slog.Error(msg, slog.Any("error", err))
+		response.InternalServerError(ctx, msg)
+	}
+}
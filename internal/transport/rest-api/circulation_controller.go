@@ -0,0 +1,460 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/transport/middleware"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CirculationController handles copy and loan HTTP requests
+type CirculationController struct {
+	circulationService service.ICirculationService
+}
+
+// NewCirculationController creates a new circulation transport
+func NewCirculationController(circulationService service.ICirculationService) *CirculationController {
+	return &CirculationController{
+		circulationService: circulationService,
+	}
+}
+
+func (c *CirculationController) SetupBookCopiesRoutes(router *gin.RouterGroup) {
+	router.GET(":id/copies", c.ListBookCopies)
+
+	admin := router.Group("", middleware.RequireRole("admin"))
+	admin.POST(":id/copies", c.AddCopy)
+}
+
+func (c *CirculationController) SetupCopiesRoutes(router *gin.RouterGroup) {
+	router.GET("", c.ListCopies)
+	router.GET(":id", c.GetCopy)
+
+	admin := router.Group("", middleware.RequireRole("admin"))
+	admin.PUT(":id", c.UpdateCopy)
+	admin.DELETE(":id", c.DeleteCopy)
+}
+
+func (c *CirculationController) SetupLoansRoutes(router *gin.RouterGroup) {
+	router.GET("", c.ListMyLoans)
+	router.GET(":id", c.GetLoan)
+	router.POST(":id/renew", c.RenewLoan)
+}
+
+func (c *CirculationController) SetupAdminLoansRoutes(router *gin.RouterGroup) {
+	router.Use(middleware.RequireRole("admin"))
+	router.GET("", c.ListLoans)
+	router.GET(":id", c.GetLoan)
+	router.POST(":id/renew", c.RenewLoan)
+	router.POST("checkout", c.CheckoutCopy)
+	router.POST("checkin", c.CheckinCopy)
+}
+
+// AddCopy godoc
+// @Summary Add a copy of a book
+// @Description Add a physical copy of a book that members can borrow. Barcodes must be unique. Copies are counted apart from the stock for sale (admin only)
+// @Tags circulation
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param input body model.CopyRequest true "Copy data"
+// @Success 201 {object} response.Response{data=model.CopyResponse} "Successfully added copy"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Book or location not found"
+// @Failure 409 {object} response.Response "Barcode already taken"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/copies [post]
+func (c *CirculationController) AddCopy(ctx *gin.Context) {
+	var req model.CopyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	bookCopy, err := c.circulationService.AddCopy(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to add copy")
+		return
+	}
+
+	response.Created(ctx, bookCopy)
+}
+
+// ListBookCopies godoc
+// @Summary List the copies of a book
+// @Description Get a paginated list of the physical copies of a book with their status, ordered by barcode
+// @Tags circulation
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=model.CopyListResponse} "Successfully retrieved copies"
+// @Failure 400 {object} response.Response "Invalid book ID"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/copies [get]
+func (c *CirculationController) ListBookCopies(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	result, err := c.circulationService.ListBookCopies(ctx.Request.Context(), ctx.Param("id"), page, pageSize)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list copies")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// ListCopies godoc
+// @Summary List copies
+// @Description Get a paginated list of physical copies, ordered by barcode
+// @Tags circulation
+// @Produce  json
+// @Security BearerAuth
+// @Param barcode query string false "Filter by barcode"
+// @Param book_id query string false "Filter by book ID"
+// @Param location_id query string false "Filter by location ID"
+// @Param status query string false "Filter by status: available, on_loan, lost or withdrawn"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=model.CopyListResponse} "Successfully retrieved copies"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/copies [get]
+func (c *CirculationController) ListCopies(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	filters := make(map[string]any)
+	if barcode := ctx.Query("barcode"); barcode != "" {
+		filters["barcode = ?"] = barcode
+	}
+	if bookID := ctx.Query("book_id"); bookID != "" {
+		filters["book_id = ?"] = bookID
+	}
+	if locationID := ctx.Query("location_id"); locationID != "" {
+		filters["location_id = ?"] = locationID
+	}
+	if status := ctx.Query("status"); status != "" {
+		filters["status = ?"] = status
+	}
+
+	result, err := c.circulationService.ListCopies(ctx.Request.Context(), page, pageSize, filters)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list copies")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// GetCopy godoc
+// @Summary Get a copy by ID
+// @Description Get a physical copy of a book by ID
+// @Tags circulation
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Copy ID"
+// @Success 200 {object} response.Response{data=model.CopyResponse} "Successfully retrieved copy"
+// @Failure 400 {object} response.Response "Invalid copy ID"
+// @Failure 404 {object} response.Response "Copy not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/copies/{id} [get]
+func (c *CirculationController) GetCopy(ctx *gin.Context) {
+	bookCopy, err := c.circulationService.GetCopy(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get copy")
+		return
+	}
+
+	response.Success(ctx, bookCopy)
+}
+
+// UpdateCopy godoc
+// @Summary Update a copy
+// @Description Replace the barcode, location and status of a copy. The status of a copy on loan only changes when it is checked in (admin only)
+// @Tags circulation
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Copy ID"
+// @Param input body model.CopyRequest true "Copy data"
+// @Success 200 {object} response.Response{data=model.CopyResponse} "Successfully updated copy"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Copy or location not found"
+// @Failure 409 {object} response.Response "Barcode already taken or copy on loan"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/copies/{id} [put]
+func (c *CirculationController) UpdateCopy(ctx *gin.Context) {
+	var req model.CopyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	bookCopy, err := c.circulationService.UpdateCopy(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to update copy")
+		return
+	}
+
+	response.Success(ctx, bookCopy)
+}
+
+// DeleteCopy godoc
+// @Summary Delete a copy
+// @Description Remove a copy that is not on loan from the holdings. Its loans are kept as history (admin only)
+// @Tags circulation
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Copy ID"
+// @Success 200 {object} response.Response "Successfully deleted copy"
+// @Failure 400 {object} response.Response "Invalid copy ID"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Copy not found"
+// @Failure 409 {object} response.Response "Copy on loan"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/copies/{id} [delete]
+func (c *CirculationController) DeleteCopy(ctx *gin.Context) {
+	if err := c.circulationService.DeleteCopy(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		c.writeError(ctx, err, "Failed to delete copy")
+		return
+	}
+
+	response.Success(ctx, nil)
+}
+
+// CheckoutCopy godoc
+// @Summary Lend a copy
+// @Description Lend an available copy, found by barcode, to an active member. The loan is due after the loan period of the member's role. Members owing more than the fine block threshold cannot borrow (admin only)
+// @Tags circulation
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param input body model.LoanCheckoutRequest true "Copy barcode and member"
+// @Success 201 {object} response.Response{data=model.LoanResponse} "Copy lent"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Copy, book or member not found"
+// @Failure 409 {object} response.Response "Copy not available, member inactive or owing too much"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/loans/checkout [post]
+func (c *CirculationController) CheckoutCopy(ctx *gin.Context) {
+	var req model.LoanCheckoutRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	loan, err := c.circulationService.CheckoutCopy(ctx.Request.Context(), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to lend copy")
+		return
+	}
+
+	response.Created(ctx, loan)
+}
+
+// CheckinCopy godoc
+// @Summary Return a copy
+// @Description End the loan of a copy handed back, found by barcode, charging the fine due if it is late. With a location_id the copy is shelved at that location from then on (admin only)
+// @Tags circulation
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param input body model.LoanCheckinRequest true "Copy barcode and return location"
+// @Success 200 {object} response.Response{data=model.LoanResponse} "Copy returned"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Copy or location not found"
+// @Failure 409 {object} response.Response "Copy not on loan"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/loans/checkin [post]
+func (c *CirculationController) CheckinCopy(ctx *gin.Context) {
+	var req model.LoanCheckinRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	loan, err := c.circulationService.CheckinCopy(ctx.Request.Context(), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to return copy")
+		return
+	}
+
+	response.Success(ctx, loan)
+}
+
+// RenewLoan godoc
+// @Summary Renew a loan
+// @Description Push the due date of an active loan back by another loan period. Members renew their own loans while they are not overdue, up to the renewal limit
+// @Tags circulation
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Loan ID"
+// @Success 200 {object} response.Response{data=model.LoanResponse} "Loan renewed"
+// @Failure 400 {object} response.Response "Invalid loan ID"
+// @Failure 404 {object} response.Response "Loan not found"
+// @Failure 409 {object} response.Response "Loan returned, overdue or renewed too often"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/loans/{id}/renew [post]
+func (c *CirculationController) RenewLoan(ctx *gin.Context) {
+	loan, err := c.circulationService.RenewLoan(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to renew loan")
+		return
+	}
+
+	response.Success(ctx, loan)
+}
+
+// GetLoan godoc
+// @Summary Get a loan by ID
+// @Description Get a loan with its due date. Members only see their own loans
+// @Tags circulation
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Loan ID"
+// @Success 200 {object} response.Response{data=model.LoanResponse} "Successfully retrieved loan"
+// @Failure 400 {object} response.Response "Invalid loan ID"
+// @Failure 404 {object} response.Response "Loan not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/loans/{id} [get]
+func (c *CirculationController) GetLoan(ctx *gin.Context) {
+	loan, err := c.circulationService.GetLoan(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get loan")
+		return
+	}
+
+	response.Success(ctx, loan)
+}
+
+// ListMyLoans godoc
+// @Summary List my loans
+// @Description Get the current user's current and past loans, newest first
+// @Tags circulation
+// @Produce  json
+// @Security BearerAuth
+// @Param status query string false "Filter by status: active, overdue or returned"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 20, max: 100)"
+// @Success 200 {object} response.Response{data=model.LoanListResponse} "Successfully retrieved loans"
+// @Failure 400 {object} response.Response "Invalid status"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/loans [get]
+func (c *CirculationController) ListMyLoans(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+
+	result, err := c.circulationService.ListMyLoans(ctx.Request.Context(), page, pageSize, ctx.Query("status"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list loans")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// ListLoans godoc
+// @Summary List all loans
+// @Description Get a paginated list of every member's loans, newest first (admin only)
+// @Tags circulation
+// @Produce  json
+// @Security BearerAuth
+// @Param status query string false "Filter by status: active, overdue or returned"
+// @Param user_id query string false "Filter by member ID"
+// @Param book_id query string false "Filter by book ID"
+// @Param copy_id query string false "Filter by copy ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 20, max: 100)"
+// @Success 200 {object} response.Response{data=model.LoanListResponse} "Successfully retrieved loans"
+// @Failure 400 {object} response.Response "Invalid status"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/loans [get]
+func (c *CirculationController) ListLoans(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+
+	filters := make(map[string]any)
+	if userID := ctx.Query("user_id"); userID != "" {
+		filters["user_id = ?"] = userID
+	}
+	if bookID := ctx.Query("book_id"); bookID != "" {
+		filters["book_id = ?"] = bookID
+	}
+	if copyID := ctx.Query("copy_id"); copyID != "" {
+		filters["copy_id = ?"] = copyID
+	}
+
+	result, err := c.circulationService.ListLoans(ctx.Request.Context(), page, pageSize, ctx.Query("status"), filters)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list loans")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// writeError writes the response for a failed circulation operation
+func (c *CirculationController) writeError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidBookID):
+		response.BadRequest(ctx, "Invalid book ID")
+	case errors.Is(err, service.ErrInvalidCopyID):
+		response.BadRequest(ctx, "Invalid copy ID")
+	case errors.Is(err, service.ErrInvalidLoanID):
+		response.BadRequest(ctx, "Invalid loan ID")
+	case errors.Is(err, service.ErrInvalidUserID), errors.Is(err, service.ErrInvalidLoanStatus):
+		response.BadRequest(ctx, err.Error())
+	case errors.Is(err, service.ErrBookNotFound):
+		response.NotFound(ctx, "Book not found")
+	case errors.Is(err, service.ErrCopyNotFound):
+		response.NotFound(ctx, "Copy not found")
+	case errors.Is(err, service.ErrLoanNotFound):
+		response.NotFound(ctx, "Loan not found")
+	case errors.Is(err, service.ErrMemberNotFound):
+		response.NotFound(ctx, "Member not found")
+	case errors.Is(err, service.ErrLocationNotFound):
+		response.NotFound(ctx, "Location not found")
+	case errors.Is(err, service.ErrCopyBarcodeTaken), errors.Is(err, service.ErrCopyNotAvailable), errors.Is(err, service.ErrCopyOnLoan),
+		errors.Is(err, service.ErrCopyNotOnLoan), errors.Is(err, service.ErrMemberInactive), errors.Is(err, service.ErrLoanNotActive),
+		errors.Is(err, service.ErrLoanOverdue), errors.Is(err, service.ErrRenewalLimitReached), errors.Is(err, service.ErrBalanceOverLimit):
+		response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+	default:
+		slog.Error(msg, slog.Any("error", err))
+		response.InternalServerError(ctx, msg)
+	}
+}
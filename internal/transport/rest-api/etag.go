@@ -0,0 +1,80 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	errInvalidETag = errors.New("invalid entity tag")
+	// errUnmatchableETag is an If-Match header that can never match, such
+	// as a weak tag or a list of several; it fails the precondition
+	errUnmatchableETag = errors.New("If-Match must be a single strong entity tag")
+)
+
+// representationETag formats a strong entity tag for a rendered book: the
+// version it was rendered from and a digest of the rendered payload. The
+// digest changes with everything the version does not count, such as the
+// stock, the rating, the language, the display currency and the format.
+func representationETag(version int, payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return fmt.Sprintf(`"%d-%x"`, version, sum[:8])
+}
+
+// jsonETag tags the JSON representation of a book
+func jsonETag(book *model.BookResponse) string {
+	payload, err := json.Marshal(book)
+	if err != nil {
+		// The version alone still guards writes; reads just revalidate less
+		return `"` + strconv.Itoa(book.Version) + `"`
+	}
+	return representationETag(book.Version, payload)
+}
+
+// parseIfMatch reads the version out of an If-Match header. It returns 0
+// when the header is empty or "*", meaning any current version is accepted.
+// The precondition is on the version only, so a tag taken from any
+// representation of the book matches as long as its catalog fields have
+// not changed.
+func parseIfMatch(header string) (int, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	// If-Match uses the strong comparison, which a weak tag never passes
+	if strings.Contains(header, ",") || strings.HasPrefix(header, "W/") {
+		return 0, errUnmatchableETag
+	}
+	return parseVersionETag(header)
+}
+
+// etagMatches reports whether an If-None-Match header matches etag
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		// Weak comparison, as If-None-Match requires
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// parseVersionETag reads the version out of a tag made by
+// representationETag, or out of a bare version tag
+func parseVersionETag(tag string) (int, error) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, errInvalidETag
+	}
+	value, _, _ := strings.Cut(tag[1:len(tag)-1], "-")
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return 0, errInvalidETag
+	}
+	return version, nil
+}
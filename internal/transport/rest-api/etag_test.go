@@ -0,0 +1,79 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"errors"
+	"testing"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    int
+		wantErr error
+	}{
+		{name: "empty", header: "", want: 0},
+		{name: "any", header: " * ", want: 0},
+		{name: "bare version", header: `"3"`, want: 3},
+		{name: "representation tag", header: `"12-0123456789abcdef"`, want: 12},
+		{name: "weak tag", header: `W/"3-0123456789abcdef"`, wantErr: errUnmatchableETag},
+		{name: "several tags", header: `"3", "4"`, wantErr: errUnmatchableETag},
+		{name: "unquoted", header: `3`, wantErr: errInvalidETag},
+		{name: "not a version", header: `"abc"`, wantErr: errInvalidETag},
+		{name: "zero version", header: `"0"`, wantErr: errInvalidETag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIfMatch(tt.header)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseIfMatch(%q) error = %v, want %v", tt.header, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseIfMatch(%q) = %d, want %d", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	etag := representationETag(7, []byte(`{"title":"Dune"}`))
+
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "same tag", header: etag, want: true},
+		{name: "weak form", header: "W/" + etag, want: true},
+		{name: "in a list", header: `"6-0000000000000000", ` + etag, want: true},
+		{name: "any", header: "*", want: true},
+		{name: "same version, other payload", header: representationETag(7, []byte(`{"title":"Emma"}`)), want: false},
+		{name: "bare version", header: `"7"`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := etagMatches(tt.header, etag); got != tt.want {
+				t.Errorf("etagMatches(%q, %q) = %v, want %v", tt.header, etag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONETag(t *testing.T) {
+	book := &model.BookResponse{Title: "Dune", Version: 4}
+	etag := jsonETag(book)
+
+	version, err := parseIfMatch(etag)
+	if err != nil || version != 4 {
+		t.Fatalf("parseIfMatch(%s) = %d, %v, want 4", etag, version, err)
+	}
+
+	// Fields outside the version, such as the stock, change the tag too
+	book.Stock = 3
+	if jsonETag(book) == etag {
+		t.Errorf("jsonETag did not change with the stock")
+	}
+}
@@ -0,0 +1,179 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/transport/middleware"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxExchangeRateFileSize caps exchange rate imports
+const maxExchangeRateFileSize = 1 << 20
+
+// ExchangeRateController handles exchange rate HTTP requests
+type ExchangeRateController struct {
+	exchangeRateService service.IExchangeRateService
+}
+
+// NewExchangeRateController creates a new exchange rate transport
+func NewExchangeRateController(exchangeRateService service.IExchangeRateService) *ExchangeRateController {
+	return &ExchangeRateController{
+		exchangeRateService: exchangeRateService,
+	}
+}
+
+func (c *ExchangeRateController) SetupExchangeRatesRoutes(router *gin.RouterGroup) {
+	router.GET("", c.LatestRates)
+}
+
+func (c *ExchangeRateController) SetupAdminExchangeRatesRoutes(router *gin.RouterGroup) {
+	router.Use(middleware.RequireRole("admin"))
+	router.GET("", c.ListRates)
+	router.POST("", c.RecordRates)
+	router.POST("import", c.ImportRates)
+}
+
+// LatestRates godoc
+// @Summary Get the exchange rates in force
+// @Description Get the latest snapshot of every currency pair. A rate is what one unit of the base currency is worth in the quote currency; pairs also convert the other way with the inverse rate
+// @Tags currencies
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]model.ExchangeRateResponse} "Successfully retrieved exchange rates"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/exchange-rates [get]
+func (c *ExchangeRateController) LatestRates(ctx *gin.Context) {
+	rates, err := c.exchangeRateService.LatestRates(ctx.Request.Context())
+	if err != nil {
+		slog.Error("Failed to get exchange rates", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to get exchange rates")
+		return
+	}
+
+	response.Success(ctx, rates)
+}
+
+// ListRates godoc
+// @Summary List the history of exchange rates
+// @Description Get a paginated history of exchange rate snapshots, latest first (admin only)
+// @Tags currencies
+// @Produce  json
+// @Security BearerAuth
+// @Param base_currency query string false "Filter by base currency"
+// @Param quote_currency query string false "Filter by quote currency"
+// @Param source query string false "Filter by source (manual, import)"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 20, max: 100)"
+// @Success 200 {object} response.Response{data=model.ExchangeRateListResponse} "Successfully retrieved exchange rates"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/exchange-rates [get]
+func (c *ExchangeRateController) ListRates(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+
+	filters := make(map[string]any)
+	if base := ctx.Query("base_currency"); base != "" {
+		filters["base_currency = ?"] = strings.ToUpper(base)
+	}
+	if quote := ctx.Query("quote_currency"); quote != "" {
+		filters["quote_currency = ?"] = strings.ToUpper(quote)
+	}
+	if source := ctx.Query("source"); source != "" {
+		filters["source = ?"] = source
+	}
+
+	result, err := c.exchangeRateService.ListRates(ctx.Request.Context(), page, pageSize, filters)
+	if err != nil {
+		slog.Error("Failed to list exchange rates", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to list exchange rates")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// RecordRates godoc
+// @Summary Record exchange rates
+// @Description Record new exchange rate snapshots, effective now unless effective_at is given. Earlier snapshots are kept so converted prices can still be explained (admin only)
+// @Tags currencies
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param input body model.ExchangeRateBatchRequest true "Exchange rates"
+// @Success 201 {object} response.Response{data=[]model.ExchangeRateResponse} "Successfully recorded exchange rates"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/exchange-rates [post]
+func (c *ExchangeRateController) RecordRates(ctx *gin.Context) {
+	var req model.ExchangeRateBatchRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	rates, err := c.exchangeRateService.RecordRates(ctx.Request.Context(), &req)
+	if err != nil {
+		slog.Error("Failed to record exchange rates", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to record exchange rates")
+		return
+	}
+
+	response.Created(ctx, rates)
+}
+
+// ImportRates godoc
+// @Summary Import exchange rates
+// @Description Record the exchange rates of a CSV file with a header naming the base_currency, quote_currency, rate and optional effective_at (RFC 3339) columns. The file is recorded whole or not at all (admin only)
+// @Tags currencies
+// @Accept  multipart/form-data
+// @Produce  json
+// @Security BearerAuth
+// @Param file formData file true "CSV file of exchange rates"
+// @Success 201 {object} response.Response{data=[]model.ExchangeRateResponse} "Successfully imported exchange rates"
+// @Failure 400 {object} response.Response "Invalid file"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/exchange-rates/import [post]
+func (c *ExchangeRateController) ImportRates(ctx *gin.Context) {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		response.BadRequest(ctx, "file is required")
+		return
+	}
+	if fileHeader.Size > maxExchangeRateFileSize {
+		response.BadRequest(ctx, "file size exceeds the limit of 1MB")
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.BadRequest(ctx, "Invalid file")
+		return
+	}
+	defer file.Close()
+
+	rates, err := c.exchangeRateService.ImportRates(ctx.Request.Context(), file)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidExchangeRateFile) {
+			response.BadRequest(ctx, err.Error())
+			return
+		}
+		slog.Error("Failed to import exchange rates", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to import exchange rates")
+		return
+	}
+
+	response.Created(ctx, rates)
+}
@@ -0,0 +1,332 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/transport/middleware"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FineController handles fine and member account HTTP requests
+type FineController struct {
+	fineService service.IFineService
+}
+
+// NewFineController creates a new fine transport
+func NewFineController(fineService service.IFineService) *FineController {
+	return &FineController{
+		fineService: fineService,
+	}
+}
+
+func (c *FineController) SetupAccountRoutes(router *gin.RouterGroup) {
+	router.GET("", c.GetMyAccount)
+}
+
+func (c *FineController) SetupAdminAccountsRoutes(router *gin.RouterGroup) {
+	router.Use(middleware.RequireRole("admin"))
+	router.GET(":id", c.GetAccount)
+	router.POST(":id/payments", c.RecordPayment)
+	router.POST(":id/waivers", c.WaiveFine)
+}
+
+func (c *FineController) SetupFinePoliciesRoutes(router *gin.RouterGroup) {
+	router.Use(middleware.RequireRole("admin"))
+	router.GET("", c.ListFinePolicies)
+	router.POST("", c.CreateFinePolicy)
+	router.PUT(":id", c.UpdateFinePolicy)
+	router.DELETE(":id", c.DeleteFinePolicy)
+}
+
+func (c *FineController) SetupAdminFinesRoutes(router *gin.RouterGroup) {
+	router.Use(middleware.RequireRole("admin"))
+	router.POST("accrue", c.AccrueFines)
+}
+
+// GetMyAccount godoc
+// @Summary Get my account
+// @Description Get the current user's balance, newest ledger entries first. Fines are positive, payments and waivers negative. Blocked members cannot borrow until they pay down their balance
+// @Tags fines
+// @Produce  json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 20, max: 100)"
+// @Success 200 {object} response.Response{data=model.AccountResponse} "Successfully retrieved account"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/account [get]
+func (c *FineController) GetMyAccount(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+
+	account, err := c.fineService.GetMyAccount(ctx.Request.Context(), page, pageSize)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get account")
+		return
+	}
+
+	response.Success(ctx, account)
+}
+
+// GetAccount godoc
+// @Summary Get a member's account
+// @Description Get a member's balance, newest ledger entries first (admin only)
+// @Tags fines
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 20, max: 100)"
+// @Success 200 {object} response.Response{data=model.AccountResponse} "Successfully retrieved account"
+// @Failure 400 {object} response.Response "Invalid user ID"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Member not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/accounts/{id} [get]
+func (c *FineController) GetAccount(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+
+	account, err := c.fineService.GetAccount(ctx.Request.Context(), ctx.Param("id"), page, pageSize)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get account")
+		return
+	}
+
+	response.Success(ctx, account)
+}
+
+// RecordPayment godoc
+// @Summary Record a payment
+// @Description Take a payment made by a member at the desk off their balance. Payments cannot exceed the balance owed (admin only)
+// @Tags fines
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param input body model.AccountCreditRequest true "Payment data"
+// @Success 201 {object} response.Response{data=model.AccountEntryResponse} "Payment recorded"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Member or loan not found"
+// @Failure 409 {object} response.Response "Amount exceeds the balance owed"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/accounts/{id}/payments [post]
+func (c *FineController) RecordPayment(ctx *gin.Context) {
+	var req model.AccountCreditRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	entry, err := c.fineService.RecordPayment(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to record payment")
+		return
+	}
+
+	response.Created(ctx, entry)
+}
+
+// WaiveFine godoc
+// @Summary Waive a fine
+// @Description Forgive part or all of a member's balance, optionally against one loan. Waivers cannot exceed the balance owed (admin only)
+// @Tags fines
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param input body model.AccountCreditRequest true "Waiver data"
+// @Success 201 {object} response.Response{data=model.AccountEntryResponse} "Fine waived"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Member or loan not found"
+// @Failure 409 {object} response.Response "Amount exceeds the balance owed"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/accounts/{id}/waivers [post]
+func (c *FineController) WaiveFine(ctx *gin.Context) {
+	var req model.AccountCreditRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	entry, err := c.fineService.WaiveFine(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to waive fine")
+		return
+	}
+
+	response.Created(ctx, entry)
+}
+
+// ListFinePolicies godoc
+// @Summary List fine policies
+// @Description Get every fine policy. The policy with an empty role is the default for roles without their own (admin only)
+// @Tags fines
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.FinePolicyListResponse} "Successfully retrieved fine policies"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/fine-policies [get]
+func (c *FineController) ListFinePolicies(ctx *gin.Context) {
+	result, err := c.fineService.ListFinePolicies(ctx.Request.Context())
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list fine policies")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// CreateFinePolicy godoc
+// @Summary Create a fine policy
+// @Description Create the fine policy for a role, or the default policy with an empty role. Overdue loans are charged the daily rate for each full day past the grace period, up to the cap per item when it is not zero (admin only)
+// @Tags fines
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param input body model.FinePolicyRequest true "Fine policy data"
+// @Success 201 {object} response.Response{data=model.FinePolicyResponse} "Successfully created fine policy"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 409 {object} response.Response "Role already has a fine policy"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/fine-policies [post]
+func (c *FineController) CreateFinePolicy(ctx *gin.Context) {
+	var req model.FinePolicyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	policy, err := c.fineService.CreateFinePolicy(ctx.Request.Context(), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to create fine policy")
+		return
+	}
+
+	response.Created(ctx, policy)
+}
+
+// UpdateFinePolicy godoc
+// @Summary Update a fine policy
+// @Description Replace a fine policy. Fines already charged are not recalculated (admin only)
+// @Tags fines
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Fine policy ID"
+// @Param input body model.FinePolicyRequest true "Fine policy data"
+// @Success 200 {object} response.Response{data=model.FinePolicyResponse} "Successfully updated fine policy"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Fine policy not found"
+// @Failure 409 {object} response.Response "Role already has a fine policy"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/fine-policies/{id} [put]
+func (c *FineController) UpdateFinePolicy(ctx *gin.Context) {
+	var req model.FinePolicyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	policy, err := c.fineService.UpdateFinePolicy(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to update fine policy")
+		return
+	}
+
+	response.Success(ctx, policy)
+}
+
+// DeleteFinePolicy godoc
+// @Summary Delete a fine policy
+// @Description Delete a fine policy. Members of its role fall back to the default policy (admin only)
+// @Tags fines
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Fine policy ID"
+// @Success 200 {object} response.Response "Successfully deleted fine policy"
+// @Failure 400 {object} response.Response "Invalid fine policy ID"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Fine policy not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/fine-policies/{id} [delete]
+func (c *FineController) DeleteFinePolicy(ctx *gin.Context) {
+	if err := c.fineService.DeleteFinePolicy(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		c.writeError(ctx, err, "Failed to delete fine policy")
+		return
+	}
+
+	response.Success(ctx, nil)
+}
+
+// AccrueFines godoc
+// @Summary Accrue overdue fines
+// @Description Charge the fines due so far on every overdue loan. This also runs nightly in the background; running it again the same day charges nothing more (admin only)
+// @Tags fines
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.FineAccrual} "Fines accrued"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/fines/accrue [post]
+func (c *FineController) AccrueFines(ctx *gin.Context) {
+	result, err := c.fineService.AccrueFines(ctx.Request.Context())
+	if err != nil {
+		slog.Error("Failed to accrue fines", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to accrue fines")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// writeError writes the response for a failed fine operation
+func (c *FineController) writeError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidFinePolicyID):
+		response.BadRequest(ctx, "Invalid fine policy ID")
+	case errors.Is(err, service.ErrInvalidUserID), errors.Is(err, service.ErrInvalidAmount):
+		response.BadRequest(ctx, err.Error())
+	case errors.Is(err, service.ErrFinePolicyNotFound):
+		response.NotFound(ctx, "Fine policy not found")
+	case errors.Is(err, service.ErrMemberNotFound):
+		response.NotFound(ctx, "Member not found")
+	case errors.Is(err, service.ErrLoanNotFound):
+		response.NotFound(ctx, "Loan not found")
+	case errors.Is(err, service.ErrFinePolicyRoleTaken), errors.Is(err, service.ErrCreditExceedsBalance):
+		response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+	default:
+		slog.Error(msg, slog.Any("error", err))
+		response.InternalServerError(ctx, msg)
+	}
+}
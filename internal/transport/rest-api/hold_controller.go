@@ -0,0 +1,206 @@
+package restapi
+
+import (
+	"book_system/internal/service"
+	"book_system/internal/transport/middleware"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HoldController handles hold queue HTTP requests
+type HoldController struct {
+	holdService service.IHoldService
+}
+
+// NewHoldController creates a new hold transport
+func NewHoldController(holdService service.IHoldService) *HoldController {
+	return &HoldController{
+		holdService: holdService,
+	}
+}
+
+func (c *HoldController) SetupBookHoldsRoutes(router *gin.RouterGroup) {
+	router.POST(":id/holds", c.PlaceHold)
+
+	admin := router.Group("", middleware.RequireRole("admin"))
+	admin.GET(":id/holds", c.ListBookHolds)
+}
+
+func (c *HoldController) SetupHoldsRoutes(router *gin.RouterGroup) {
+	router.GET("", c.ListMyHolds)
+	router.GET(":id", c.GetHold)
+	router.POST(":id/cancel", c.CancelHold)
+}
+
+func (c *HoldController) SetupAdminHoldsRoutes(router *gin.RouterGroup) {
+	router.Use(middleware.RequireRole("admin"))
+	router.POST("expire", c.ExpireHolds)
+}
+
+// PlaceHold godoc
+// @Summary Place a hold on a book
+// @Description Join the queue for a book out of stock. Holds are served first come, first served: when stock comes in, a copy is set aside for the hold at the front of the queue until its pickup window ends, and its user is notified. Books with stock free cannot be held
+// @Tags holds
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Success 201 {object} response.Response{data=model.HoldResponse} "Hold placed, with its place in the queue"
+// @Failure 400 {object} response.Response "Invalid book ID"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 409 {object} response.Response "Book in stock or already on hold"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/holds [post]
+func (c *HoldController) PlaceHold(ctx *gin.Context) {
+	hold, err := c.holdService.PlaceHold(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to place hold")
+		return
+	}
+
+	response.Created(ctx, hold)
+}
+
+// ListBookHolds godoc
+// @Summary List the hold queue of a book
+// @Description Get the waiting and ready holds of a book in the order they were placed (admin only)
+// @Tags holds
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=model.HoldListResponse} "Successfully retrieved holds"
+// @Failure 400 {object} response.Response "Invalid book ID"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/holds [get]
+func (c *HoldController) ListBookHolds(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	result, err := c.holdService.ListBookHolds(ctx.Request.Context(), ctx.Param("id"), page, pageSize)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list holds")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// ListMyHolds godoc
+// @Summary List my holds
+// @Description Get the current user's holds in the order they were placed, with the place of waiting holds in their queues
+// @Tags holds
+// @Produce  json
+// @Security BearerAuth
+// @Param status query string false "Filter by status: waiting, ready, fulfilled, cancelled or expired"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=model.HoldListResponse} "Successfully retrieved holds"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/holds [get]
+func (c *HoldController) ListMyHolds(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	result, err := c.holdService.ListMyHolds(ctx.Request.Context(), page, pageSize, ctx.Query("status"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list holds")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// GetHold godoc
+// @Summary Get a hold by ID
+// @Description Get a hold with its place in the queue. Users only see their own holds
+// @Tags holds
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Hold ID"
+// @Success 200 {object} response.Response{data=model.HoldResponse} "Successfully retrieved hold"
+// @Failure 400 {object} response.Response "Invalid hold ID"
+// @Failure 404 {object} response.Response "Hold not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/holds/{id} [get]
+func (c *HoldController) GetHold(ctx *gin.Context) {
+	hold, err := c.holdService.GetHold(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get hold")
+		return
+	}
+
+	response.Success(ctx, hold)
+}
+
+// CancelHold godoc
+// @Summary Cancel a hold
+// @Description Give up a waiting or ready hold. A copy set aside for the hold goes to the next hold in the queue
+// @Tags holds
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Hold ID"
+// @Success 200 {object} response.Response{data=model.HoldResponse} "Hold cancelled"
+// @Failure 400 {object} response.Response "Invalid hold ID"
+// @Failure 404 {object} response.Response "Hold not found"
+// @Failure 409 {object} response.Response "Hold no longer active"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/holds/{id}/cancel [post]
+func (c *HoldController) CancelHold(ctx *gin.Context) {
+	hold, err := c.holdService.CancelHold(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to cancel hold")
+		return
+	}
+
+	response.Success(ctx, hold)
+}
+
+// ExpireHolds godoc
+// @Summary Expire lapsed holds
+// @Description Expire the ready holds whose pickup window has ended and pass their copies on to the next holds in the queue. This also runs periodically in the background (admin only)
+// @Tags holds
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.HoldExpiry} "Holds expired"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/holds/expire [post]
+func (c *HoldController) ExpireHolds(ctx *gin.Context) {
+	result, err := c.holdService.ExpireHolds(ctx.Request.Context())
+	if err != nil {
+		slog.Error("Failed to expire holds", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to expire holds")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// writeError writes the response for a failed hold operation
+func (c *HoldController) writeError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidBookID):
+		response.BadRequest(ctx, "Invalid book ID")
+	case errors.Is(err, service.ErrInvalidHoldID):
+		response.BadRequest(ctx, "Invalid hold ID")
+	case errors.Is(err, service.ErrInvalidUserID):
+		response.BadRequest(ctx, err.Error())
+	case errors.Is(err, service.ErrBookNotFound):
+		response.NotFound(ctx, "Book not found")
+	case errors.Is(err, service.ErrHoldNotFound):
+		response.NotFound(ctx, "Hold not found")
+	case errors.Is(err, service.ErrBookInStock), errors.Is(err, service.ErrHoldExists), errors.Is(err, service.ErrHoldNotActive):
+		response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+	default:
+		slog.Error(msg, slog.Any("error", err))
+		response.InternalServerError(ctx, msg)
+	}
+}
@@ -0,0 +1,242 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/transport/middleware"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InventoryController handles stock keeping HTTP requests
+type InventoryController struct {
+	inventoryService service.IInventoryService
+}
+
+// NewInventoryController creates a new inventory transport
+func NewInventoryController(inventoryService service.IInventoryService) *InventoryController {
+	return &InventoryController{
+		inventoryService: inventoryService,
+	}
+}
+
+func (c *InventoryController) SetupInventoryRoutes(router *gin.RouterGroup) {
+	router.POST(":id/stock/adjust", c.AdjustStock)
+	router.POST(":id/stock/transfer", c.TransferStock)
+	router.GET(":id/stock", c.GetBookStock)
+	router.GET(":id/stock/movements", c.ListMovements)
+	router.GET("low-stock", c.ListLowStock)
+	router.GET("availability", c.GetAvailability)
+
+	admin := router.Group("", middleware.RequireRole("admin"))
+	admin.POST(":id/stock/reconcile", c.ReconcileStock)
+}
+
+// AdjustStock godoc
+// @Summary Adjust a book's stock
+// @Description Atomically change a book's stock and record the movement in the inventory ledger. receive, sell and return take a positive quantity of copies; adjust takes a signed correction. With a location_id the stock at that location changes along with the book's total; without one only stock not held at any location can be used. The stock never goes negative
+// @Tags inventory
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Book ID"
+// @Param input body model.StockAdjustRequest true "Stock movement"
+// @Success 200 {object} response.Response{data=model.StockAdjustResponse} "Stock adjusted"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 404 {object} response.Response "Book or location not found"
+// @Failure 409 {object} response.Response "Not enough stock"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/stock/adjust [post]
+func (c *InventoryController) AdjustStock(ctx *gin.Context) {
+	var req model.StockAdjustRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	result, err := c.inventoryService.AdjustStock(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to adjust stock")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// TransferStock godoc
+// @Summary Transfer stock between locations
+// @Description Atomically move copies of a book from one location to another. Both ends are recorded in the inventory ledger as transfer movements sharing a transfer ID; the book's total stock does not change
+// @Tags inventory
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Book ID"
+// @Param input body model.StockTransferRequest true "Stock transfer"
+// @Success 200 {object} response.Response{data=model.StockTransferResponse} "Stock transferred"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 404 {object} response.Response "Book or location not found"
+// @Failure 409 {object} response.Response "Not enough stock at the source location"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/stock/transfer [post]
+func (c *InventoryController) TransferStock(ctx *gin.Context) {
+	var req model.StockTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	result, err := c.inventoryService.TransferStock(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to transfer stock")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// GetBookStock godoc
+// @Summary Get a book's stock by location
+// @Description Break a book's total stock down into the stock held at each location and the unallocated rest
+// @Tags inventory
+// @Produce  json
+// @Param id path string true "Book ID"
+// @Success 200 {object} response.Response{data=model.BookStockResponse} "Successfully retrieved stock"
+// @Failure 400 {object} response.Response "Invalid book ID"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/stock [get]
+func (c *InventoryController) GetBookStock(ctx *gin.Context) {
+	result, err := c.inventoryService.GetBookStock(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get stock")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// GetAvailability godoc
+// @Summary Find where a book is in stock
+// @Description List the locations that have copies of a book in stock, largest quantities first. The ISBN may be given in any form
+// @Tags inventory
+// @Produce  json
+// @Param isbn query string true "ISBN"
+// @Success 200 {object} response.Response{data=model.BookAvailability} "Successfully retrieved availability"
+// @Failure 400 {object} response.Response "Invalid ISBN"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/availability [get]
+func (c *InventoryController) GetAvailability(ctx *gin.Context) {
+	result, err := c.inventoryService.GetAvailability(ctx.Request.Context(), ctx.Query("isbn"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get availability")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// ListMovements godoc
+// @Summary List a book's stock movements
+// @Description Get the inventory ledger of a book, newest first
+// @Tags inventory
+// @Produce  json
+// @Param id path string true "Book ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 20, max: 100)"
+// @Success 200 {object} response.Response{data=model.StockMovementListResponse} "Successfully retrieved stock movements"
+// @Failure 400 {object} response.Response "Invalid book ID"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/stock/movements [get]
+func (c *InventoryController) ListMovements(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+
+	result, err := c.inventoryService.ListMovements(ctx.Request.Context(), ctx.Param("id"), page, pageSize)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list stock movements")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// ReconcileStock godoc
+// @Summary Reconcile a book's stock with its ledger
+// @Description Compare a book's stock, and its stock at each location, with the sum of its ledger and correct them when they differ. A book without ledger entries gets an opening balance for its current stock (admin only)
+// @Tags inventory
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Success 200 {object} response.Response{data=model.StockReconciliation} "Stock reconciled"
+// @Failure 400 {object} response.Response "Invalid book ID"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/stock/reconcile [post]
+func (c *InventoryController) ReconcileStock(ctx *gin.Context) {
+	result, err := c.inventoryService.ReconcileStock(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to reconcile stock")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// ListLowStock godoc
+// @Summary List books with low stock
+// @Description Get a paginated list of books at or below the low-stock threshold
+// @Tags inventory
+// @Produce  json
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=model.BookListResponse} "Successfully retrieved books"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/low-stock [get]
+func (c *InventoryController) ListLowStock(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	result, err := c.inventoryService.ListLowStock(ctx.Request.Context(), page, pageSize)
+	if err != nil {
+		slog.Error("Failed to list low-stock books", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to list low-stock books")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// writeError writes the response for a failed inventory operation
+func (c *InventoryController) writeError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidBookID):
+		response.BadRequest(ctx, "Invalid book ID")
+	case errors.Is(err, service.ErrInvalidISBN):
+		response.BadRequest(ctx, "Invalid ISBN")
+	case errors.Is(err, service.ErrBookNotFound):
+		response.NotFound(ctx, "Book not found")
+	case errors.Is(err, service.ErrLocationNotFound):
+		response.NotFound(ctx, "Location not found")
+	case errors.Is(err, service.ErrInsufficientStock):
+		response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+	default:
+		slog.Error(msg, slog.Any("error", err))
+		response.InternalServerError(ctx, msg)
+	}
+}
@@ -0,0 +1,248 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/transport/middleware"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocationController handles store and warehouse HTTP requests
+type LocationController struct {
+	locationService service.ILocationService
+}
+
+// NewLocationController creates a new location transport
+func NewLocationController(locationService service.ILocationService) *LocationController {
+	return &LocationController{
+		locationService: locationService,
+	}
+}
+
+func (c *LocationController) SetupLocationsRoutes(router *gin.RouterGroup) {
+	router.GET("", c.ListLocations)
+	router.GET(":id", c.GetLocation)
+	router.GET(":id/stock", c.ListStock)
+	router.GET(":id/movements", c.ListMovements)
+
+	admin := router.Group("", middleware.RequireRole("admin"))
+	admin.POST("", c.CreateLocation)
+	admin.PUT(":id", c.UpdateLocation)
+	admin.DELETE(":id", c.DeleteLocation)
+}
+
+// CreateLocation godoc
+// @Summary Create a new location
+// @Description Create a store or warehouse that can hold stock. Codes are stored upper-case and must be unique (admin only)
+// @Tags locations
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param input body model.LocationRequest true "Location data"
+// @Success 201 {object} response.Response{data=model.LocationResponse} "Successfully created location"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 409 {object} response.Response "Location code already taken"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/locations [post]
+func (c *LocationController) CreateLocation(ctx *gin.Context) {
+	var req model.LocationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	location, err := c.locationService.CreateLocation(ctx.Request.Context(), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to create location")
+		return
+	}
+
+	response.Created(ctx, location)
+}
+
+// GetLocation godoc
+// @Summary Get a location by ID
+// @Description Get a store or warehouse by ID
+// @Tags locations
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Location ID"
+// @Success 200 {object} response.Response{data=model.LocationResponse} "Successfully retrieved location"
+// @Failure 400 {object} response.Response "Invalid location ID"
+// @Failure 404 {object} response.Response "Location not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/locations/{id} [get]
+func (c *LocationController) GetLocation(ctx *gin.Context) {
+	location, err := c.locationService.GetLocation(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get location")
+		return
+	}
+
+	response.Success(ctx, location)
+}
+
+// ListLocations godoc
+// @Summary List all locations with pagination
+// @Description Get a paginated list of stores and warehouses ordered by code
+// @Tags locations
+// @Accept  json
+// @Produce  json
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=model.LocationListResponse} "Successfully retrieved locations"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/locations [get]
+func (c *LocationController) ListLocations(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	result, err := c.locationService.ListLocations(ctx.Request.Context(), page, pageSize)
+	if err != nil {
+		slog.Error("Failed to list locations", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to list locations")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// UpdateLocation godoc
+// @Summary Replace a location
+// @Description Replace all editable fields of a location (admin only)
+// @Tags locations
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Location ID"
+// @Param input body model.LocationRequest true "Location data"
+// @Success 200 {object} response.Response{data=model.LocationResponse} "Successfully updated location"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Location not found"
+// @Failure 409 {object} response.Response "Location code already taken"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/locations/{id} [put]
+func (c *LocationController) UpdateLocation(ctx *gin.Context) {
+	var req model.LocationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	location, err := c.locationService.UpdateLocation(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to update location")
+		return
+	}
+
+	response.Success(ctx, location)
+}
+
+// DeleteLocation godoc
+// @Summary Delete a location
+// @Description Delete a location that has no stock left. Its stock movements are kept in the ledger (admin only)
+// @Tags locations
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Location ID"
+// @Success 200 {object} response.Response "Successfully deleted location"
+// @Failure 400 {object} response.Response "Invalid location ID"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Location not found"
+// @Failure 409 {object} response.Response "Location still has stock"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/locations/{id} [delete]
+func (c *LocationController) DeleteLocation(ctx *gin.Context) {
+	if err := c.locationService.DeleteLocation(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		c.writeError(ctx, err, "Failed to delete location")
+		return
+	}
+
+	response.Success(ctx, nil)
+}
+
+// ListStock godoc
+// @Summary List the books in stock at a location
+// @Description Get a paginated list of the books a location has in stock, largest quantities first
+// @Tags locations
+// @Produce  json
+// @Param id path string true "Location ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=model.LocationStockListResponse} "Successfully retrieved stock"
+// @Failure 400 {object} response.Response "Invalid location ID"
+// @Failure 404 {object} response.Response "Location not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/locations/{id}/stock [get]
+func (c *LocationController) ListStock(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	result, err := c.locationService.ListStock(ctx.Request.Context(), ctx.Param("id"), page, pageSize)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list location stock")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// ListMovements godoc
+// @Summary List a location's stock movements
+// @Description Get the inventory ledger entries recorded at a location, transfers included, newest first
+// @Tags locations
+// @Produce  json
+// @Param id path string true "Location ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 20, max: 100)"
+// @Success 200 {object} response.Response{data=model.StockMovementListResponse} "Successfully retrieved stock movements"
+// @Failure 400 {object} response.Response "Invalid location ID"
+// @Failure 404 {object} response.Response "Location not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/locations/{id}/movements [get]
+func (c *LocationController) ListMovements(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+
+	result, err := c.locationService.ListMovements(ctx.Request.Context(), ctx.Param("id"), page, pageSize)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list stock movements")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// writeError writes the response for a failed location operation
+func (c *LocationController) writeError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidLocationID):
+		response.BadRequest(ctx, "Invalid location ID")
+	case errors.Is(err, service.ErrLocationNotFound):
+		response.NotFound(ctx, "Location not found")
+	case errors.Is(err, service.ErrLocationCodeTaken), errors.Is(err, service.ErrLocationHasStock):
+		response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+	default:
+		slog.Error(msg, slog.Any("error", err))
+		response.InternalServerError(ctx, msg)
+	}
+}
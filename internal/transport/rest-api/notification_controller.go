@@ -0,0 +1,109 @@
+package restapi
+
+import (
+	"book_system/internal/service"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationController handles notification inbox HTTP requests
+type NotificationController struct {
+	notificationService service.INotificationService
+}
+
+// NewNotificationController creates a new notification transport
+func NewNotificationController(notificationService service.INotificationService) *NotificationController {
+	return &NotificationController{
+		notificationService: notificationService,
+	}
+}
+
+func (c *NotificationController) SetupNotificationsRoutes(router *gin.RouterGroup) {
+	router.GET("", c.ListMyNotifications)
+	router.POST(":id/read", c.MarkRead)
+	router.POST("read-all", c.MarkAllRead)
+}
+
+// ListMyNotifications godoc
+// @Summary List my notifications
+// @Description Get the current user's notifications, newest first, with the number still unread
+// @Tags notifications
+// @Produce  json
+// @Security BearerAuth
+// @Param unread query bool false "Only list unread notifications"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 20, max: 100)"
+// @Success 200 {object} response.Response{data=model.NotificationListResponse} "Successfully retrieved notifications"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/notifications [get]
+func (c *NotificationController) ListMyNotifications(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "20"))
+	unreadOnly, _ := strconv.ParseBool(ctx.Query("unread"))
+
+	result, err := c.notificationService.ListMyNotifications(ctx.Request.Context(), page, pageSize, unreadOnly)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list notifications")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// MarkRead godoc
+// @Summary Mark a notification as read
+// @Description Mark one of the current user's notifications as read
+// @Tags notifications
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 200 {object} response.Response "Notification marked as read"
+// @Failure 400 {object} response.Response "Invalid notification ID"
+// @Failure 404 {object} response.Response "Notification not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/notifications/{id}/read [post]
+func (c *NotificationController) MarkRead(ctx *gin.Context) {
+	if err := c.notificationService.MarkRead(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		c.writeError(ctx, err, "Failed to mark notification as read")
+		return
+	}
+
+	response.Success(ctx, nil)
+}
+
+// MarkAllRead godoc
+// @Summary Mark all notifications as read
+// @Description Mark every unread notification of the current user as read
+// @Tags notifications
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} response.Response "Notifications marked as read"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/notifications/read-all [post]
+func (c *NotificationController) MarkAllRead(ctx *gin.Context) {
+	if err := c.notificationService.MarkAllRead(ctx.Request.Context()); err != nil {
+		c.writeError(ctx, err, "Failed to mark notifications as read")
+		return
+	}
+
+	response.Success(ctx, nil)
+}
+
+// writeError writes the response for a failed notification operation
+func (c *NotificationController) writeError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidNotificationID):
+		response.BadRequest(ctx, "Invalid notification ID")
+	case errors.Is(err, service.ErrInvalidUserID):
+		response.BadRequest(ctx, err.Error())
+	case errors.Is(err, service.ErrNotificationNotFound):
+		response.NotFound(ctx, "Notification not found")
+	default:
+		slog.Error(msg, slog.Any("error", err))
+		response.InternalServerError(ctx, msg)
+	}
+}
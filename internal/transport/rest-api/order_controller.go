@@ -0,0 +1,240 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/transport/middleware"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrderController handles checkout and order HTTP requests
+type OrderController struct {
+	orderService service.IOrderService
+}
+
+// NewOrderController creates a new order transport
+func NewOrderController(orderService service.IOrderService) *OrderController {
+	return &OrderController{
+		orderService: orderService,
+	}
+}
+
+func (c *OrderController) SetupOrdersRoutes(router *gin.RouterGroup) {
+	router.POST("", c.Checkout)
+	router.GET("", c.ListMyOrders)
+	router.GET(":id", c.GetOrder)
+	router.POST(":id/cancel", c.CancelOrder)
+}
+
+func (c *OrderController) SetupAdminOrdersRoutes(router *gin.RouterGroup) {
+	router.Use(middleware.RequireRole("admin"))
+	router.GET("", c.ListOrders)
+	router.GET(":id", c.GetOrder)
+	router.PUT(":id/status", c.UpdateOrderStatus)
+}
+
+// Checkout godoc
+// @Summary Check out the cart
+// @Description Turn the current user's cart into a pending order at the books' effective prices, less the coupon when coupon_code is given. The order is priced in currency, else the user's preferred currency, else the catalog currency. The stock of every book is reserved and a use of the coupon counted in the same transaction, from location_id when given and from the unallocated stock otherwise; if any book lacks stock or the coupon has run out nothing is reserved. The cart is emptied
+// @Tags orders
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param input body model.CheckoutRequest true "Checkout data"
+// @Success 201 {object} response.Response{data=model.OrderResponse} "Order placed"
+// @Failure 400 {object} response.Response "Invalid input, empty cart or coupon cannot be applied"
+// @Failure 404 {object} response.Response "Book, location or coupon not found"
+// @Failure 409 {object} response.Response "Not enough stock, coupon has no uses left or no exchange rate to the currency"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/orders [post]
+func (c *OrderController) Checkout(ctx *gin.Context) {
+	var req model.CheckoutRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	order, err := c.orderService.Checkout(ctx.Request.Context(), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to place order")
+		return
+	}
+
+	response.Created(ctx, order)
+}
+
+// ListMyOrders godoc
+// @Summary List my orders
+// @Description Get the current user's order history, newest first
+// @Tags orders
+// @Produce  json
+// @Security BearerAuth
+// @Param status query string false "Filter by status"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=model.OrderListResponse} "Successfully retrieved orders"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/orders [get]
+func (c *OrderController) ListMyOrders(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	result, err := c.orderService.ListMyOrders(ctx.Request.Context(), page, pageSize, ctx.Query("status"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list orders")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// GetOrder godoc
+// @Summary Get an order by ID
+// @Description Get an order with its items. Users only see their own orders
+// @Tags orders
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {object} response.Response{data=model.OrderResponse} "Successfully retrieved order"
+// @Failure 400 {object} response.Response "Invalid order ID"
+// @Failure 404 {object} response.Response "Order not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/orders/{id} [get]
+func (c *OrderController) GetOrder(ctx *gin.Context) {
+	order, err := c.orderService.GetOrder(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get order")
+		return
+	}
+
+	response.Success(ctx, order)
+}
+
+// CancelOrder godoc
+// @Summary Cancel an order
+// @Description Cancel one of the current user's orders while it is still pending. Its stock is put back
+// @Tags orders
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 200 {object} response.Response{data=model.OrderResponse} "Order cancelled"
+// @Failure 400 {object} response.Response "Invalid order ID"
+// @Failure 404 {object} response.Response "Order not found"
+// @Failure 409 {object} response.Response "Order can no longer be cancelled"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/orders/{id}/cancel [post]
+func (c *OrderController) CancelOrder(ctx *gin.Context) {
+	order, err := c.orderService.CancelOrder(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to cancel order")
+		return
+	}
+
+	response.Success(ctx, order)
+}
+
+// ListOrders godoc
+// @Summary List all orders
+// @Description Get a paginated list of every user's orders, newest first (admin only)
+// @Tags orders
+// @Produce  json
+// @Security BearerAuth
+// @Param status query string false "Filter by status"
+// @Param user_id query string false "Filter by user ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=model.OrderListResponse} "Successfully retrieved orders"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/orders [get]
+func (c *OrderController) ListOrders(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	filters := make(map[string]any)
+	if status := ctx.Query("status"); status != "" {
+		filters["status = ?"] = status
+	}
+	if userID := ctx.Query("user_id"); userID != "" {
+		filters["user_id = ?"] = userID
+	}
+
+	result, err := c.orderService.ListOrders(ctx.Request.Context(), page, pageSize, filters)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list orders")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// UpdateOrderStatus godoc
+// @Summary Change the status of an order
+// @Description Move an order along its lifecycle: pending or paid to cancelled, paid to shipped, shipped to delivered. Orders become paid or refunded only through their payment. Orders cancelled before they ship put their stock back (admin only)
+// @Tags orders
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Param input body model.OrderStatusRequest true "New status"
+// @Success 200 {object} response.Response{data=model.OrderResponse} "Order updated"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Order not found"
+// @Failure 409 {object} response.Response "Status change not allowed"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/orders/{id}/status [put]
+func (c *OrderController) UpdateOrderStatus(ctx *gin.Context) {
+	var req model.OrderStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	order, err := c.orderService.UpdateOrderStatus(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to update order")
+		return
+	}
+
+	response.Success(ctx, order)
+}
+
+// writeError writes the response for a failed order operation
+func (c *OrderController) writeError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidOrderID):
+		response.BadRequest(ctx, "Invalid order ID")
+	case errors.Is(err, service.ErrCartEmpty):
+		response.BadRequest(ctx, "Cart is empty")
+	case errors.Is(err, service.ErrCouponNotApplicable), errors.Is(err, service.ErrInvalidCurrency):
+		response.BadRequest(ctx, err.Error())
+	case errors.Is(err, service.ErrCouponNotFound):
+		response.NotFound(ctx, "Coupon not found")
+	case errors.Is(err, service.ErrOrderNotFound):
+		response.NotFound(ctx, "Order not found")
+	case errors.Is(err, service.ErrBookNotFound), errors.Is(err, service.ErrLocationNotFound):
+		response.NotFound(ctx, err.Error())
+	case errors.Is(err, service.ErrInsufficientStock), errors.Is(err, service.ErrInvalidOrderTransition), errors.Is(err, service.ErrCouponExhausted), errors.Is(err, service.ErrExchangeRateNotFound):
+		response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+	default:
+		slog.Error(msg, slog.Any("error", err))
+		response.InternalServerError(ctx, msg)
+	}
+}
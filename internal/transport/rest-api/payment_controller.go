@@ -0,0 +1,253 @@
+package restapi
+
+import (
+	"book_system/internal/service"
+	"book_system/internal/transport/middleware"
+	"book_system/internal/transport/response"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookSize is the largest webhook body accepted from a payment provider
+const maxWebhookSize = 1 << 20
+
+// PaymentController handles payment HTTP requests
+type PaymentController struct {
+	paymentService service.IPaymentService
+}
+
+// NewPaymentController creates a new payment transport
+func NewPaymentController(paymentService service.IPaymentService) *PaymentController {
+	return &PaymentController{
+		paymentService: paymentService,
+	}
+}
+
+func (c *PaymentController) SetupPaymentsRoutes(router *gin.RouterGroup) {
+	router.GET(":id", c.GetPayment)
+}
+
+func (c *PaymentController) SetupOrderPaymentRoutes(router *gin.RouterGroup) {
+	router.POST(":id/payment", c.CreatePayment)
+}
+
+func (c *PaymentController) SetupWebhookRoutes(router *gin.RouterGroup) {
+	router.POST("payments", c.HandleWebhook)
+}
+
+func (c *PaymentController) SetupAdminPaymentsRoutes(router *gin.RouterGroup) {
+	router.Use(middleware.RequireRole("admin"))
+	router.GET("", c.ListPayments)
+	router.GET(":id", c.GetPayment)
+	router.POST(":id/capture", c.CapturePayment)
+	router.POST(":id/refund", c.RefundPayment)
+	router.POST("reconcile", c.ReconcilePayments)
+}
+
+// CreatePayment godoc
+// @Summary Pay for an order
+// @Description Start the payment of one of the current user's pending orders for its total. The response carries the client secret the payer completes the payment with at the provider. An order already being paid gets its current payment back. Each attempt is started at the provider under a key derived from the order and attempt, so a request retried after a provider failure starts the same intent
+// @Tags payments
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Order ID"
+// @Success 201 {object} response.Response{data=model.PaymentResponse} "Payment started"
+// @Failure 400 {object} response.Response "Invalid order ID"
+// @Failure 404 {object} response.Response "Order not found"
+// @Failure 409 {object} response.Response "Order is not awaiting payment"
+// @Failure 502 {object} response.Response "Payment provider unavailable"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/orders/{id}/payment [post]
+func (c *PaymentController) CreatePayment(ctx *gin.Context) {
+	payment, err := c.paymentService.CreatePayment(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to create payment")
+		return
+	}
+
+	response.Created(ctx, payment)
+}
+
+// GetPayment godoc
+// @Summary Get a payment by ID
+// @Description Get a payment. Users only see their own payments
+// @Tags payments
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Success 200 {object} response.Response{data=model.PaymentResponse} "Successfully retrieved payment"
+// @Failure 400 {object} response.Response "Invalid payment ID"
+// @Failure 404 {object} response.Response "Payment not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/payments/{id} [get]
+func (c *PaymentController) GetPayment(ctx *gin.Context) {
+	payment, err := c.paymentService.GetPayment(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get payment")
+		return
+	}
+
+	response.Success(ctx, payment)
+}
+
+// HandleWebhook godoc
+// @Summary Receive a payment provider webhook
+// @Description Apply a signed payment event from the provider. Redelivered events are acknowledged without being applied twice, and events that would move a payment back are ignored
+// @Tags payments
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} response.Response "Event processed"
+// @Failure 400 {object} response.Response "Invalid signature or payload"
+// @Failure 404 {object} response.Response "Payment not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/webhooks/payments [post]
+func (c *PaymentController) HandleWebhook(ctx *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxWebhookSize))
+	if err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := c.paymentService.HandleWebhook(ctx.Request.Context(), payload, ctx.Request.Header); err != nil {
+		c.writeError(ctx, err, "Failed to process payment webhook")
+		return
+	}
+
+	response.Success(ctx, nil)
+}
+
+// ListPayments godoc
+// @Summary List all payments
+// @Description Get a paginated list of payments, newest first (admin only)
+// @Tags payments
+// @Produce  json
+// @Security BearerAuth
+// @Param status query string false "Filter by status"
+// @Param order_id query string false "Filter by order ID"
+// @Param user_id query string false "Filter by payer ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=model.PaymentListResponse} "Successfully retrieved payments"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/payments [get]
+func (c *PaymentController) ListPayments(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	filters := make(map[string]any)
+	for _, field := range []string{"status", "order_id", "user_id"} {
+		if value := ctx.Query(field); value != "" {
+			filters[field+" = ?"] = value
+		}
+	}
+
+	result, err := c.paymentService.ListPayments(ctx.Request.Context(), page, pageSize, filters)
+	if err != nil {
+		slog.Error("Failed to list payments", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to list payments")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// CapturePayment godoc
+// @Summary Capture an authorized payment
+// @Description Collect a payment whose automatic capture failed (admin only)
+// @Tags payments
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Success 200 {object} response.Response{data=model.PaymentResponse} "Payment captured"
+// @Failure 400 {object} response.Response "Invalid payment ID"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Payment not found"
+// @Failure 409 {object} response.Response "Payment is not authorized"
+// @Failure 502 {object} response.Response "Payment provider unavailable"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/payments/{id}/capture [post]
+func (c *PaymentController) CapturePayment(ctx *gin.Context) {
+	payment, err := c.paymentService.CapturePayment(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to capture payment")
+		return
+	}
+
+	response.Success(ctx, payment)
+}
+
+// RefundPayment godoc
+// @Summary Refund a payment
+// @Description Pay a captured payment back in full. Its order is marked refunded, which puts the stock back if the order has not shipped (admin only)
+// @Tags payments
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Success 200 {object} response.Response{data=model.PaymentResponse} "Payment refunded"
+// @Failure 400 {object} response.Response "Invalid payment ID"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Payment not found"
+// @Failure 409 {object} response.Response "Payment is not captured"
+// @Failure 502 {object} response.Response "Payment provider unavailable"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/payments/{id}/refund [post]
+func (c *PaymentController) RefundPayment(ctx *gin.Context) {
+	payment, err := c.paymentService.RefundPayment(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to refund payment")
+		return
+	}
+
+	response.Success(ctx, payment)
+}
+
+// ReconcilePayments godoc
+// @Summary Reconcile stuck payments
+// @Description Check payments left pending or authorized for too long against the provider and apply the state it reports, and refund payments captured for orders cancelled in the meantime. This also runs periodically in the background (admin only)
+// @Tags payments
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.PaymentReconciliation} "Payments reconciled"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/payments/reconcile [post]
+func (c *PaymentController) ReconcilePayments(ctx *gin.Context) {
+	result, err := c.paymentService.ReconcilePayments(ctx.Request.Context())
+	if err != nil {
+		slog.Error("Failed to reconcile payments", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to reconcile payments")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// writeError writes the response for a failed payment operation
+func (c *PaymentController) writeError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPaymentID):
+		response.BadRequest(ctx, "Invalid payment ID")
+	case errors.Is(err, service.ErrInvalidOrderID):
+		response.BadRequest(ctx, "Invalid order ID")
+	case errors.Is(err, service.ErrInvalidWebhookSignature):
+		response.BadRequest(ctx, err.Error())
+	case errors.Is(err, service.ErrPaymentNotFound):
+		response.NotFound(ctx, "Payment not found")
+	case errors.Is(err, service.ErrOrderNotFound):
+		response.NotFound(ctx, "Order not found")
+	case errors.Is(err, service.ErrOrderNotPayable), errors.Is(err, service.ErrInvalidPaymentTransition):
+		response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, service.ErrPaymentProviderError):
+		slog.Error(msg, slog.Any("error", err))
+		response.JSON(ctx, http.StatusBadGateway, "Payment provider is unavailable", nil)
+	default:
+		slog.Error(msg, slog.Any("error", err))
+		response.InternalServerError(ctx, msg)
+	}
+}
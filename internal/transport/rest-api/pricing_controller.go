@@ -0,0 +1,484 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/transport/middleware"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PricingController handles price rule, coupon and price quote HTTP requests
+type PricingController struct {
+	pricingService service.IPricingService
+}
+
+// NewPricingController creates a new pricing transport
+func NewPricingController(pricingService service.IPricingService) *PricingController {
+	return &PricingController{
+		pricingService: pricingService,
+	}
+}
+
+func (c *PricingController) SetupBookPriceRoutes(router *gin.RouterGroup) {
+	router.GET(":id/price", c.QuoteBook)
+	router.GET(":id/prices", c.ListBookPrices)
+	router.PUT(":id/prices/:currency", c.SetBookPrice)
+	router.DELETE(":id/prices/:currency", c.DeleteBookPrice)
+}
+
+func (c *PricingController) SetupPriceRulesRoutes(router *gin.RouterGroup) {
+	router.Use(middleware.RequireRole("admin"))
+	router.GET("", c.ListPriceRules)
+	router.GET(":id", c.GetPriceRule)
+	router.POST("", c.CreatePriceRule)
+	router.PUT(":id", c.UpdatePriceRule)
+	router.DELETE(":id", c.DeletePriceRule)
+}
+
+func (c *PricingController) SetupCouponsRoutes(router *gin.RouterGroup) {
+	router.Use(middleware.RequireRole("admin"))
+	router.GET("", c.ListCoupons)
+	router.GET(":id", c.GetCoupon)
+	router.POST("", c.CreateCoupon)
+	router.PUT(":id", c.UpdateCoupon)
+	router.DELETE(":id", c.DeleteCoupon)
+}
+
+// QuoteBook godoc
+// @Summary Get the effective price of a book
+// @Description Get the price of one copy of a book under the price rules in force, with every adjustment that led to it from the list price. The list price is the book's price set in the currency or its own price converted with the latest exchange rate, rounded to the currency's minor unit. The lowest matching sale price replaces the list price, then the matching discount that takes the most off applies; discounts do not stack. With a coupon code the coupon is applied last, without using it. Amounts are exact decimal strings
+// @Tags pricing
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param coupon query string false "Coupon code to apply"
+// @Param currency query string false "ISO 4217 currency code to price in (default: the user's preferred currency, else the catalog currency)"
+// @Success 200 {object} response.Response{data=model.PriceQuote} "Successfully priced book"
+// @Failure 400 {object} response.Response "Invalid book ID or currency, or coupon cannot be applied"
+// @Failure 404 {object} response.Response "Book or coupon not found"
+// @Failure 409 {object} response.Response "Coupon has no uses left or no exchange rate to the currency"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/price [get]
+func (c *PricingController) QuoteBook(ctx *gin.Context) {
+	quote, err := c.pricingService.QuoteBook(ctx.Request.Context(), ctx.Param("id"), ctx.Query("coupon"), ctx.Query("currency"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to price book")
+		return
+	}
+
+	response.Success(ctx, quote)
+}
+
+// ListBookPrices godoc
+// @Summary List the prices of a book in other currencies
+// @Description Get the list prices set for a book in currencies other than its own. Currencies without one are converted from the book's price
+// @Tags pricing
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Success 200 {object} response.Response{data=[]model.BookPriceResponse} "Successfully retrieved book prices"
+// @Failure 400 {object} response.Response "Invalid book ID"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/prices [get]
+func (c *PricingController) ListBookPrices(ctx *gin.Context) {
+	prices, err := c.pricingService.ListBookPrices(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list book prices")
+		return
+	}
+
+	response.Success(ctx, prices)
+}
+
+// SetBookPrice godoc
+// @Summary Set the price of a book in a currency
+// @Description Set the list price of a book in a currency, used instead of converting its own price. The amount must fit the currency's minor unit: VND has none
+// @Tags pricing
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param currency path string true "ISO 4217 currency code"
+// @Param input body model.BookPriceRequest true "List price"
+// @Success 200 {object} response.Response{data=model.BookPriceResponse} "Successfully set book price"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/prices/{currency} [put]
+func (c *PricingController) SetBookPrice(ctx *gin.Context) {
+	var req model.BookPriceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	currency := strings.ToUpper(ctx.Param("currency"))
+	if err := req.Validate(currency); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	price, err := c.pricingService.SetBookPrice(ctx.Request.Context(), ctx.Param("id"), currency, &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to set book price")
+		return
+	}
+
+	response.Success(ctx, price)
+}
+
+// DeleteBookPrice godoc
+// @Summary Remove the price of a book in a currency
+// @Description Remove the list price of a book in a currency, which is then converted from the book's own price again
+// @Tags pricing
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param currency path string true "ISO 4217 currency code"
+// @Success 200 {object} response.Response "Successfully removed book price"
+// @Failure 400 {object} response.Response "Invalid book ID"
+// @Failure 404 {object} response.Response "Book or book price not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/prices/{currency} [delete]
+func (c *PricingController) DeleteBookPrice(ctx *gin.Context) {
+	currency := strings.ToUpper(ctx.Param("currency"))
+	if err := c.pricingService.DeleteBookPrice(ctx.Request.Context(), ctx.Param("id"), currency); err != nil {
+		c.writeError(ctx, err, "Failed to remove book price")
+		return
+	}
+
+	response.Success(ctx, nil)
+}
+
+// CreatePriceRule godoc
+// @Summary Create a new price rule
+// @Description Create a scheduled sale price, percentage discount or amount discount for a book, an author, the editions of a work, the books of a category (scope_value is the category ID), a format or the whole catalog. A rule without starts_at or ends_at is open on that end. Sale prices and amounts are in currency, the catalog currency by default, and are converted to the currency a book is priced in (admin only)
+// @Tags pricing
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param input body model.PriceRuleRequest true "Price rule data"
+// @Success 201 {object} response.Response{data=model.PriceRuleResponse} "Successfully created price rule"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/price-rules [post]
+func (c *PricingController) CreatePriceRule(ctx *gin.Context) {
+	var req model.PriceRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	rule, err := c.pricingService.CreatePriceRule(ctx.Request.Context(), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to create price rule")
+		return
+	}
+
+	response.Created(ctx, rule)
+}
+
+// GetPriceRule godoc
+// @Summary Get a price rule by ID
+// @Description Get a price rule by ID (admin only)
+// @Tags pricing
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Price rule ID"
+// @Success 200 {object} response.Response{data=model.PriceRuleResponse} "Successfully retrieved price rule"
+// @Failure 400 {object} response.Response "Invalid price rule ID"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Price rule not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/price-rules/{id} [get]
+func (c *PricingController) GetPriceRule(ctx *gin.Context) {
+	rule, err := c.pricingService.GetPriceRule(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get price rule")
+		return
+	}
+
+	response.Success(ctx, rule)
+}
+
+// ListPriceRules godoc
+// @Summary List price rules
+// @Description Get a paginated list of price rules, newest first (admin only)
+// @Tags pricing
+// @Produce  json
+// @Security BearerAuth
+// @Param type query string false "Filter by type (sale_price, percent_off, amount_off)"
+// @Param scope_type query string false "Filter by scope type (all, book, author, work, category, format)"
+// @Param active query bool false "Filter by active flag"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=model.PriceRuleListResponse} "Successfully retrieved price rules"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/price-rules [get]
+func (c *PricingController) ListPriceRules(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	filters := make(map[string]any)
+	if ruleType := ctx.Query("type"); ruleType != "" {
+		filters["type = ?"] = ruleType
+	}
+	if scopeType := ctx.Query("scope_type"); scopeType != "" {
+		filters["scope_type = ?"] = scopeType
+	}
+	if active, err := strconv.ParseBool(ctx.Query("active")); err == nil {
+		filters["active = ?"] = active
+	}
+
+	result, err := c.pricingService.ListPriceRules(ctx.Request.Context(), page, pageSize, filters)
+	if err != nil {
+		slog.Error("Failed to list price rules", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to list price rules")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// UpdatePriceRule godoc
+// @Summary Update a price rule
+// @Description Replace the editable fields of a price rule. Orders keep the prices they were placed at (admin only)
+// @Tags pricing
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Price rule ID"
+// @Param input body model.PriceRuleRequest true "Price rule data"
+// @Success 200 {object} response.Response{data=model.PriceRuleResponse} "Successfully updated price rule"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Price rule not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/price-rules/{id} [put]
+func (c *PricingController) UpdatePriceRule(ctx *gin.Context) {
+	var req model.PriceRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	rule, err := c.pricingService.UpdatePriceRule(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to update price rule")
+		return
+	}
+
+	response.Success(ctx, rule)
+}
+
+// DeletePriceRule godoc
+// @Summary Delete a price rule
+// @Description Delete a price rule. Orders keep the prices they were placed at (admin only)
+// @Tags pricing
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Price rule ID"
+// @Success 200 {object} response.Response "Successfully deleted price rule"
+// @Failure 400 {object} response.Response "Invalid price rule ID"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Price rule not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/price-rules/{id} [delete]
+func (c *PricingController) DeletePriceRule(ctx *gin.Context) {
+	if err := c.pricingService.DeletePriceRule(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		c.writeError(ctx, err, "Failed to delete price rule")
+		return
+	}
+
+	response.Success(ctx, nil)
+}
+
+// CreateCoupon godoc
+// @Summary Create a new coupon
+// @Description Create a coupon code that takes a percentage or an amount off an order's subtotal. Codes are stored upper-case and must be unique; max_uses of 0 means unlimited (admin only)
+// @Tags pricing
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param input body model.CouponRequest true "Coupon data"
+// @Success 201 {object} response.Response{data=model.CouponResponse} "Successfully created coupon"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 409 {object} response.Response "Coupon code already taken"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/coupons [post]
+func (c *PricingController) CreateCoupon(ctx *gin.Context) {
+	var req model.CouponRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	coupon, err := c.pricingService.CreateCoupon(ctx.Request.Context(), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to create coupon")
+		return
+	}
+
+	response.Created(ctx, coupon)
+}
+
+// GetCoupon godoc
+// @Summary Get a coupon by ID
+// @Description Get a coupon by ID with its use count (admin only)
+// @Tags pricing
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Coupon ID"
+// @Success 200 {object} response.Response{data=model.CouponResponse} "Successfully retrieved coupon"
+// @Failure 400 {object} response.Response "Invalid coupon ID"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Coupon not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/coupons/{id} [get]
+func (c *PricingController) GetCoupon(ctx *gin.Context) {
+	coupon, err := c.pricingService.GetCoupon(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get coupon")
+		return
+	}
+
+	response.Success(ctx, coupon)
+}
+
+// ListCoupons godoc
+// @Summary List coupons
+// @Description Get a paginated list of coupons, newest first (admin only)
+// @Tags pricing
+// @Produce  json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=model.CouponListResponse} "Successfully retrieved coupons"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/coupons [get]
+func (c *PricingController) ListCoupons(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	result, err := c.pricingService.ListCoupons(ctx.Request.Context(), page, pageSize)
+	if err != nil {
+		slog.Error("Failed to list coupons", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to list coupons")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// UpdateCoupon godoc
+// @Summary Update a coupon
+// @Description Replace the editable fields of a coupon. Its use count is kept (admin only)
+// @Tags pricing
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Coupon ID"
+// @Param input body model.CouponRequest true "Coupon data"
+// @Success 200 {object} response.Response{data=model.CouponResponse} "Successfully updated coupon"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Coupon not found"
+// @Failure 409 {object} response.Response "Coupon code already taken"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/coupons/{id} [put]
+func (c *PricingController) UpdateCoupon(ctx *gin.Context) {
+	var req model.CouponRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	coupon, err := c.pricingService.UpdateCoupon(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to update coupon")
+		return
+	}
+
+	response.Success(ctx, coupon)
+}
+
+// DeleteCoupon godoc
+// @Summary Delete a coupon
+// @Description Delete a coupon. Orders keep the discount they were placed with (admin only)
+// @Tags pricing
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Coupon ID"
+// @Success 200 {object} response.Response "Successfully deleted coupon"
+// @Failure 400 {object} response.Response "Invalid coupon ID"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Coupon not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/coupons/{id} [delete]
+func (c *PricingController) DeleteCoupon(ctx *gin.Context) {
+	if err := c.pricingService.DeleteCoupon(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		c.writeError(ctx, err, "Failed to delete coupon")
+		return
+	}
+
+	response.Success(ctx, nil)
+}
+
+// writeError writes the response for a failed pricing operation
+func (c *PricingController) writeError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidBookID):
+		response.BadRequest(ctx, "Invalid book ID")
+	case errors.Is(err, service.ErrInvalidPriceRuleID):
+		response.BadRequest(ctx, "Invalid price rule ID")
+	case errors.Is(err, service.ErrInvalidCouponID):
+		response.BadRequest(ctx, "Invalid coupon ID")
+	case errors.Is(err, service.ErrCouponNotApplicable), errors.Is(err, service.ErrInvalidCurrency):
+		response.BadRequest(ctx, err.Error())
+	case errors.Is(err, service.ErrBookNotFound):
+		response.NotFound(ctx, "Book not found")
+	case errors.Is(err, service.ErrBookPriceNotFound):
+		response.NotFound(ctx, err.Error())
+	case errors.Is(err, service.ErrPriceRuleNotFound):
+		response.NotFound(ctx, "Price rule not found")
+	case errors.Is(err, service.ErrCouponNotFound):
+		response.NotFound(ctx, "Coupon not found")
+	case errors.Is(err, service.ErrCouponCodeTaken), errors.Is(err, service.ErrCouponExhausted), errors.Is(err, service.ErrExchangeRateNotFound):
+		response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+	default:
+		slog.Error(msg, slog.Any("error", err))
+		response.InternalServerError(ctx, msg)
+	}
+}
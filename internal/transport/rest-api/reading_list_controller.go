@@ -0,0 +1,395 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadingListController handles wishlist and reading list HTTP requests
+type ReadingListController struct {
+	readingListService service.IReadingListService
+}
+
+// NewReadingListController creates a new reading list transport
+func NewReadingListController(readingListService service.IReadingListService) *ReadingListController {
+	return &ReadingListController{
+		readingListService: readingListService,
+	}
+}
+
+func (c *ReadingListController) SetupReadingListsRoutes(router *gin.RouterGroup) {
+	router.GET("", c.ListMyLists)
+	router.POST("", c.CreateList)
+	router.GET(":id", c.GetList)
+	router.PUT(":id", c.RenameList)
+	router.DELETE(":id", c.DeleteList)
+	router.POST(":id/items", c.AddItem)
+	router.DELETE(":id/items/:book_id", c.RemoveItem)
+	router.PUT(":id/items/:book_id/progress", c.UpdateProgress)
+	router.PUT(":id/order", c.ReorderItems)
+	router.POST(":id/share", c.ShareList)
+	router.DELETE(":id/share", c.UnshareList)
+}
+
+func (c *ReadingListController) SetupSharedReadingListsRoutes(router *gin.RouterGroup) {
+	router.GET(":token", c.GetSharedList)
+}
+
+// ListMyLists godoc
+// @Summary List my reading lists
+// @Description Get the current user's reading lists with the number of books on each: the built-in wishlist, to read, reading and finished lists first, then custom lists
+// @Tags reading-lists
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.ReadingListCollection} "Successfully retrieved reading lists"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/reading-lists [get]
+func (c *ReadingListController) ListMyLists(ctx *gin.Context) {
+	result, err := c.readingListService.ListMyLists(ctx.Request.Context())
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list reading lists")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// CreateList godoc
+// @Summary Create a reading list
+// @Description Create a custom reading list. A user's lists have unique names
+// @Tags reading-lists
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param input body model.ReadingListRequest true "Reading list name"
+// @Success 201 {object} response.Response{data=model.ReadingListResponse} "Successfully created reading list"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 409 {object} response.Response "Name already taken"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/reading-lists [post]
+func (c *ReadingListController) CreateList(ctx *gin.Context) {
+	var req model.ReadingListRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	list, err := c.readingListService.CreateList(ctx.Request.Context(), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to create reading list")
+		return
+	}
+
+	response.Created(ctx, list)
+}
+
+// GetList godoc
+// @Summary Get a reading list
+// @Description Get one of the current user's reading lists with its books in list order
+// @Tags reading-lists
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Reading list ID"
+// @Success 200 {object} response.Response{data=model.ReadingListResponse} "Successfully retrieved reading list"
+// @Failure 400 {object} response.Response "Invalid reading list ID"
+// @Failure 404 {object} response.Response "Reading list not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/reading-lists/{id} [get]
+func (c *ReadingListController) GetList(ctx *gin.Context) {
+	list, err := c.readingListService.GetList(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get reading list")
+		return
+	}
+
+	response.Success(ctx, list)
+}
+
+// RenameList godoc
+// @Summary Rename a reading list
+// @Description Rename one of the current user's reading lists, built-in lists included
+// @Tags reading-lists
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Reading list ID"
+// @Param input body model.ReadingListRequest true "Reading list name"
+// @Success 200 {object} response.Response{data=model.ReadingListResponse} "Successfully renamed reading list"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 404 {object} response.Response "Reading list not found"
+// @Failure 409 {object} response.Response "Name already taken"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/reading-lists/{id} [put]
+func (c *ReadingListController) RenameList(ctx *gin.Context) {
+	var req model.ReadingListRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	list, err := c.readingListService.RenameList(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to rename reading list")
+		return
+	}
+
+	response.Success(ctx, list)
+}
+
+// DeleteList godoc
+// @Summary Delete a reading list
+// @Description Delete one of the current user's custom reading lists with the books on it. Built-in lists cannot be deleted
+// @Tags reading-lists
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Reading list ID"
+// @Success 200 {object} response.Response "Successfully deleted reading list"
+// @Failure 400 {object} response.Response "Invalid reading list ID"
+// @Failure 404 {object} response.Response "Reading list not found"
+// @Failure 409 {object} response.Response "Built-in reading list"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/reading-lists/{id} [delete]
+func (c *ReadingListController) DeleteList(ctx *gin.Context) {
+	if err := c.readingListService.DeleteList(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		c.writeError(ctx, err, "Failed to delete reading list")
+		return
+	}
+
+	response.Success(ctx, nil)
+}
+
+// AddItem godoc
+// @Summary Add a book to a reading list
+// @Description Put a book at the end of one of the current user's reading lists. A book put on the to read, reading or finished list is taken off the other two
+// @Tags reading-lists
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Reading list ID"
+// @Param input body model.ReadingListItemRequest true "Book to add"
+// @Success 200 {object} response.Response{data=model.ReadingListResponse} "Book added"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 404 {object} response.Response "Reading list or book not found"
+// @Failure 409 {object} response.Response "Book already on the list"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/reading-lists/{id}/items [post]
+func (c *ReadingListController) AddItem(ctx *gin.Context) {
+	var req model.ReadingListItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	list, err := c.readingListService.AddItem(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to add book to reading list")
+		return
+	}
+
+	response.Success(ctx, list)
+}
+
+// RemoveItem godoc
+// @Summary Remove a book from a reading list
+// @Description Take a book off one of the current user's reading lists
+// @Tags reading-lists
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Reading list ID"
+// @Param book_id path string true "Book ID"
+// @Success 200 {object} response.Response{data=model.ReadingListResponse} "Book removed"
+// @Failure 400 {object} response.Response "Invalid reading list or book ID"
+// @Failure 404 {object} response.Response "Reading list not found or book not on it"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/reading-lists/{id}/items/{book_id} [delete]
+func (c *ReadingListController) RemoveItem(ctx *gin.Context) {
+	list, err := c.readingListService.RemoveItem(ctx.Request.Context(), ctx.Param("id"), ctx.Param("book_id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to remove book from reading list")
+		return
+	}
+
+	response.Success(ctx, list)
+}
+
+// UpdateProgress godoc
+// @Summary Update reading progress
+// @Description Record how far the current user has read a book on their reading list, as a page or a percentage. A page sets the percentage too, and the other way round, when the book's page count is known
+// @Tags reading-lists
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Reading list ID"
+// @Param book_id path string true "Book ID"
+// @Param input body model.ReadingProgressRequest true "Reading progress"
+// @Success 200 {object} response.Response{data=model.ReadingListItemResponse} "Progress recorded"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 404 {object} response.Response "Reading list not found or book not on it"
+// @Failure 409 {object} response.Response "Not the reading list"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/reading-lists/{id}/items/{book_id}/progress [put]
+func (c *ReadingListController) UpdateProgress(ctx *gin.Context) {
+	var req model.ReadingProgressRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	item, err := c.readingListService.UpdateProgress(ctx.Request.Context(), ctx.Param("id"), ctx.Param("book_id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to update reading progress")
+		return
+	}
+
+	response.Success(ctx, item)
+}
+
+// ReorderItems godoc
+// @Summary Reorder a reading list
+// @Description Put the books of one of the current user's reading lists in a new order. The order must hold every book on the list exactly once
+// @Tags reading-lists
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Reading list ID"
+// @Param input body model.ReadingListOrderRequest true "Book IDs in their new order"
+// @Success 200 {object} response.Response{data=model.ReadingListResponse} "Reading list reordered"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 404 {object} response.Response "Reading list not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/reading-lists/{id}/order [put]
+func (c *ReadingListController) ReorderItems(ctx *gin.Context) {
+	var req model.ReadingListOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	list, err := c.readingListService.ReorderItems(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to reorder reading list")
+		return
+	}
+
+	response.Success(ctx, list)
+}
+
+// ShareList godoc
+// @Summary Share a reading list
+// @Description Make one of the current user's reading lists readable by anyone with its share link, /api/v1/shared/reading-lists/{share_token}. Sharing a shared list keeps its link
+// @Tags reading-lists
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Reading list ID"
+// @Success 200 {object} response.Response{data=model.ReadingListResponse} "Reading list shared, with its share token"
+// @Failure 400 {object} response.Response "Invalid reading list ID"
+// @Failure 404 {object} response.Response "Reading list not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/reading-lists/{id}/share [post]
+func (c *ReadingListController) ShareList(ctx *gin.Context) {
+	list, err := c.readingListService.ShareList(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to share reading list")
+		return
+	}
+
+	response.Success(ctx, list)
+}
+
+// UnshareList godoc
+// @Summary Stop sharing a reading list
+// @Description Revoke the share link of one of the current user's reading lists. Sharing it again makes a new link
+// @Tags reading-lists
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Reading list ID"
+// @Success 200 {object} response.Response{data=model.ReadingListResponse} "Reading list no longer shared"
+// @Failure 400 {object} response.Response "Invalid reading list ID"
+// @Failure 404 {object} response.Response "Reading list not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/reading-lists/{id}/share [delete]
+func (c *ReadingListController) UnshareList(ctx *gin.Context) {
+	list, err := c.readingListService.UnshareList(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to stop sharing reading list")
+		return
+	}
+
+	response.Success(ctx, list)
+}
+
+// GetSharedList godoc
+// @Summary Get a shared reading list
+// @Description Get a reading list shared by its owner, with its books in list order. No authentication is needed
+// @Tags reading-lists
+// @Produce  json
+// @Param token path string true "Share token"
+// @Success 200 {object} response.Response{data=model.ReadingListResponse} "Successfully retrieved reading list"
+// @Failure 404 {object} response.Response "Reading list not found or no longer shared"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/shared/reading-lists/{token} [get]
+func (c *ReadingListController) GetSharedList(ctx *gin.Context) {
+	list, err := c.readingListService.GetSharedList(ctx.Request.Context(), ctx.Param("token"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get reading list")
+		return
+	}
+
+	response.Success(ctx, list)
+}
+
+// writeError writes the response for a failed reading list operation
+func (c *ReadingListController) writeError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidReadingListID):
+		response.BadRequest(ctx, "Invalid reading list ID")
+	case errors.Is(err, service.ErrInvalidBookID):
+		response.BadRequest(ctx, "Invalid book ID")
+	case errors.Is(err, service.ErrInvalidUserID), errors.Is(err, service.ErrInvalidReadingListName),
+		errors.Is(err, service.ErrInvalidListOrder), errors.Is(err, service.ErrInvalidProgress):
+		response.BadRequest(ctx, err.Error())
+	case errors.Is(err, service.ErrReadingListNotFound):
+		response.NotFound(ctx, "Reading list not found")
+	case errors.Is(err, service.ErrBookNotFound):
+		response.NotFound(ctx, "Book not found")
+	case errors.Is(err, service.ErrBookNotOnList):
+		response.NotFound(ctx, "Book is not on this list")
+	case errors.Is(err, service.ErrReadingListNameTaken), errors.Is(err, service.ErrReadingListBuiltIn),
+		errors.Is(err, service.ErrBookOnList), errors.Is(err, service.ErrProgressNotTracked):
+		response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+	default:
+		slog.Error(msg, slog.Any("error", err))
+		response.InternalServerError(ctx, msg)
+	}
+}
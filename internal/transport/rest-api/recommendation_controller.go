@@ -0,0 +1,150 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/transport/middleware"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecommendationController handles book recommendation HTTP requests
+type RecommendationController struct {
+	recommendationService service.IRecommendationService
+	pricingService        service.IPricingService
+}
+
+// NewRecommendationController creates a new recommendation transport
+func NewRecommendationController(recommendationService service.IRecommendationService, pricingService service.IPricingService) *RecommendationController {
+	return &RecommendationController{
+		recommendationService: recommendationService,
+		pricingService:        pricingService,
+	}
+}
+
+func (c *RecommendationController) SetupBookSimilarRoutes(router *gin.RouterGroup) {
+	router.GET(":id/similar", c.SimilarBooks)
+}
+
+func (c *RecommendationController) SetupUserRecommendationsRoutes(router *gin.RouterGroup) {
+	router.GET("/me/recommendations", c.MyRecommendations)
+}
+
+func (c *RecommendationController) SetupAdminRecommendationsRoutes(router *gin.RouterGroup) {
+	router.Use(middleware.RequireRole("admin"))
+	router.POST("refresh", c.RefreshSimilarities)
+}
+
+// SimilarBooks godoc
+// @Summary Get books similar to a book
+// @Description Get the books most similar to a book: books viewed by the same users, and books by the same author, at a similar price or published around the same time. Books with too few similar books are topped up with the most viewed books; source is popular when none were similar. display_price is the list price in the requested currency, else the user's preferred currency, else the catalog currency
+// @Tags recommendations
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param limit query int false "Number of books (default: 10, max: 50)"
+// @Param currency query string false "ISO 4217 currency code for display prices"
+// @Param Accept-Language header string false "Preferred languages for titles and descriptions, with q-values"
+// @Success 200 {object} response.Response{data=model.RecommendationListResponse} "Successfully retrieved similar books"
+// @Failure 400 {object} response.Response "Invalid book ID or currency"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/similar [get]
+func (c *RecommendationController) SimilarBooks(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+
+	result, err := c.recommendationService.SimilarBooks(ctx.Request.Context(), ctx.Param("id"), limit)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get similar books")
+		return
+	}
+
+	c.writeBooks(ctx, result)
+}
+
+// MyRecommendations godoc
+// @Summary Get my recommendations
+// @Description Get books similar to those the current user viewed lately, leaving out the ones they viewed. Users with too few recommendations get the most viewed books as well; source is popular when nothing was similar. display_price is the list price in the requested currency, else the user's preferred currency, else the catalog currency
+// @Tags recommendations
+// @Produce  json
+// @Security BearerAuth
+// @Param limit query int false "Number of books (default: 10, max: 50)"
+// @Param currency query string false "ISO 4217 currency code for display prices"
+// @Param Accept-Language header string false "Preferred languages for titles and descriptions, with q-values"
+// @Success 200 {object} response.Response{data=model.RecommendationListResponse} "Successfully retrieved recommendations"
+// @Failure 400 {object} response.Response "Invalid currency"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/users/me/recommendations [get]
+func (c *RecommendationController) MyRecommendations(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+
+	result, err := c.recommendationService.MyRecommendations(ctx.Request.Context(), limit)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get recommendations")
+		return
+	}
+
+	c.writeBooks(ctx, result)
+}
+
+// RefreshSimilarities godoc
+// @Summary Refresh similar books
+// @Description Recompute the similar books of every book from the books users viewed and the books themselves. Books are compared with the books viewed along with them, by the same author, and closest in price and publication date. Cached recommendations are cleared. This also runs periodically in the background (admin only)
+// @Tags recommendations
+// @Produce  json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.SimilarityRefresh} "Similar books refreshed"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/recommendations/refresh [post]
+func (c *RecommendationController) RefreshSimilarities(ctx *gin.Context) {
+	result, err := c.recommendationService.RefreshSimilarities(ctx.Request.Context())
+	if err != nil {
+		slog.Error("Failed to refresh similar books", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to refresh similar books")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// writeBooks writes recommended books with their display prices, in the
+// language the request asks for
+func (c *RecommendationController) writeBooks(ctx *gin.Context, result *model.RecommendationListResponse) {
+	err := c.pricingService.DisplayPrices(ctx.Request.Context(), result.Data, ctx.Query("currency"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCurrency) {
+			response.BadRequest(ctx, err.Error())
+			return
+		}
+		slog.Error("Failed to convert book prices", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to convert book prices")
+		return
+	}
+
+	acceptLanguage := ctx.GetHeader("Accept-Language")
+	for _, book := range result.Data {
+		book.Localize(acceptLanguage)
+	}
+	ctx.Header("Vary", "Accept-Language, Authorization")
+	response.Success(ctx, result)
+}
+
+// writeError writes the response for a failed recommendation operation
+func (c *RecommendationController) writeError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidBookID):
+		response.BadRequest(ctx, "Invalid book ID")
+	case errors.Is(err, service.ErrInvalidUserID):
+		response.BadRequest(ctx, err.Error())
+	case errors.Is(err, service.ErrBookNotFound):
+		response.NotFound(ctx, "Book not found")
+	default:
+		slog.Error(msg, slog.Any("error", err))
+		response.InternalServerError(ctx, msg)
+	}
+}
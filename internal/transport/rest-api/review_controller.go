@@ -0,0 +1,292 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/transport/middleware"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReviewController handles book review HTTP requests
+type ReviewController struct {
+	reviewService service.IReviewService
+}
+
+// NewReviewController creates a new review transport
+func NewReviewController(reviewService service.IReviewService) *ReviewController {
+	return &ReviewController{
+		reviewService: reviewService,
+	}
+}
+
+func (c *ReviewController) SetupBookReviewsRoutes(router *gin.RouterGroup) {
+	router.GET(":id/reviews", c.ListBookReviews)
+	router.POST(":id/reviews", c.CreateReview)
+	router.GET(":id/reviews/mine", c.GetMyReview)
+}
+
+func (c *ReviewController) SetupReviewsRoutes(router *gin.RouterGroup) {
+	router.GET(":id", c.GetReview)
+	router.PUT(":id", c.UpdateReview)
+	router.DELETE(":id", c.DeleteReview)
+}
+
+func (c *ReviewController) SetupAdminReviewsRoutes(router *gin.RouterGroup) {
+	router.Use(middleware.RequireRole("admin"))
+	router.GET("", c.ListReviews)
+	router.POST(":id/moderate", c.ModerateReview)
+}
+
+// CreateReview godoc
+// @Summary Review a book
+// @Description Rate a book from 1 to 5 stars, with an optional written review. Each user reviews a book once; change the review instead of writing another
+// @Tags reviews
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param input body model.ReviewRequest true "Review data"
+// @Success 201 {object} response.Response{data=model.ReviewResponse} "Successfully created review"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 409 {object} response.Response "Book already reviewed"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/reviews [post]
+func (c *ReviewController) CreateReview(ctx *gin.Context) {
+	var req model.ReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	review, err := c.reviewService.CreateReview(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to create review")
+		return
+	}
+
+	response.Created(ctx, review)
+}
+
+// ListBookReviews godoc
+// @Summary List the reviews of a book
+// @Description Get the reviews of a book, newest first. Hidden reviews are only listed for admins
+// @Tags reviews
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Param status query string false "Filter by status: published, flagged or hidden"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=model.ReviewListResponse} "Successfully retrieved reviews"
+// @Failure 400 {object} response.Response "Invalid book ID or status"
+// @Failure 404 {object} response.Response "Book not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/reviews [get]
+func (c *ReviewController) ListBookReviews(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	result, err := c.reviewService.ListBookReviews(ctx.Request.Context(), ctx.Param("id"), page, pageSize, ctx.Query("status"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list reviews")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// GetMyReview godoc
+// @Summary Get my review of a book
+// @Description Get the current user's review of a book, whatever its status
+// @Tags reviews
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Book ID"
+// @Success 200 {object} response.Response{data=model.ReviewResponse} "Successfully retrieved review"
+// @Failure 400 {object} response.Response "Invalid book ID"
+// @Failure 404 {object} response.Response "Book or review not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/books/{id}/reviews/mine [get]
+func (c *ReviewController) GetMyReview(ctx *gin.Context) {
+	review, err := c.reviewService.GetMyReview(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get review")
+		return
+	}
+
+	response.Success(ctx, review)
+}
+
+// GetReview godoc
+// @Summary Get a review by ID
+// @Description Get a review. Hidden reviews are only shown to their author and admins
+// @Tags reviews
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Review ID"
+// @Success 200 {object} response.Response{data=model.ReviewResponse} "Successfully retrieved review"
+// @Failure 400 {object} response.Response "Invalid review ID"
+// @Failure 404 {object} response.Response "Review not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/reviews/{id} [get]
+func (c *ReviewController) GetReview(ctx *gin.Context) {
+	review, err := c.reviewService.GetReview(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get review")
+		return
+	}
+
+	response.Success(ctx, review)
+}
+
+// UpdateReview godoc
+// @Summary Update my review
+// @Description Replace the rating and text of one of the current user's reviews. The book's rating follows; a moderator's decision on the review stands
+// @Tags reviews
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Review ID"
+// @Param input body model.ReviewRequest true "Review data"
+// @Success 200 {object} response.Response{data=model.ReviewResponse} "Successfully updated review"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 404 {object} response.Response "Review not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/reviews/{id} [put]
+func (c *ReviewController) UpdateReview(ctx *gin.Context) {
+	var req model.ReviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	review, err := c.reviewService.UpdateReview(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to update review")
+		return
+	}
+
+	response.Success(ctx, review)
+}
+
+// DeleteReview godoc
+// @Summary Delete a review
+// @Description Delete one of the current user's reviews, or any review for admins, and take its rating off the book's
+// @Tags reviews
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Review ID"
+// @Success 200 {object} response.Response "Successfully deleted review"
+// @Failure 400 {object} response.Response "Invalid review ID"
+// @Failure 404 {object} response.Response "Review not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/reviews/{id} [delete]
+func (c *ReviewController) DeleteReview(ctx *gin.Context) {
+	if err := c.reviewService.DeleteReview(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		c.writeError(ctx, err, "Failed to delete review")
+		return
+	}
+
+	response.Success(ctx, nil)
+}
+
+// ListReviews godoc
+// @Summary List reviews
+// @Description Get the reviews of every book, newest first, such as the flagged reviews awaiting moderation (admin only)
+// @Tags reviews
+// @Produce  json
+// @Security BearerAuth
+// @Param status query string false "Filter by status: published, flagged or hidden"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=model.ReviewListResponse} "Successfully retrieved reviews"
+// @Failure 400 {object} response.Response "Invalid status"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/reviews [get]
+func (c *ReviewController) ListReviews(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	result, err := c.reviewService.ListReviews(ctx.Request.Context(), page, pageSize, ctx.Query("status"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list reviews")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// ModerateReview godoc
+// @Summary Moderate a review
+// @Description Publish, flag or hide a review, with an optional note. Flagged reviews stay public; hidden reviews are only shown to their author and admins and do not count towards the book's rating (admin only)
+// @Tags reviews
+// @Accept  json
+// @Produce  json
+// @Security BearerAuth
+// @Param id path string true "Review ID"
+// @Param input body model.ReviewModerationRequest true "Moderation decision"
+// @Success 200 {object} response.Response{data=model.ReviewResponse} "Review moderated"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 403 {object} response.Response "Admin role required"
+// @Failure 404 {object} response.Response "Review not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/admin/reviews/{id}/moderate [post]
+func (c *ReviewController) ModerateReview(ctx *gin.Context) {
+	var req model.ReviewModerationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	review, err := c.reviewService.ModerateReview(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to moderate review")
+		return
+	}
+
+	response.Success(ctx, review)
+}
+
+// writeError writes the response for a failed review operation
+func (c *ReviewController) writeError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidBookID):
+		response.BadRequest(ctx, "Invalid book ID")
+	case errors.Is(err, service.ErrInvalidReviewID):
+		response.BadRequest(ctx, "Invalid review ID")
+	case errors.Is(err, service.ErrInvalidUserID), errors.Is(err, service.ErrInvalidReviewStatus):
+		response.BadRequest(ctx, err.Error())
+	case errors.Is(err, service.ErrBookNotFound):
+		response.NotFound(ctx, "Book not found")
+	case errors.Is(err, service.ErrReviewNotFound):
+		response.NotFound(ctx, "Review not found")
+	case errors.Is(err, service.ErrReviewExists):
+		response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+	default:
+		slog.Error(msg, slog.Any("error", err))
+		response.InternalServerError(ctx, msg)
+	}
+}
@@ -3,20 +3,50 @@ package restapi
 import (
 	"book_system/internal/config"
 	"book_system/internal/infrastructure"
+	"book_system/internal/model"
 	"book_system/internal/repository"
+	"book_system/internal/service"
 	book_service "book_system/internal/service/book_service"
+	cart_service "book_system/internal/service/cart_service"
+	category_service "book_system/internal/service/category_service"
+	circulation_service "book_system/internal/service/circulation_service"
+	cover_service "book_system/internal/service/cover_service"
+	exchange_rate_service "book_system/internal/service/exchange_rate_service"
+	export_service "book_system/internal/service/export_service"
+	fine_service "book_system/internal/service/fine_service"
+	hold_service "book_system/internal/service/hold_service"
+	import_service "book_system/internal/service/import_service"
+	inventory_service "book_system/internal/service/inventory_service"
+	location_service "book_system/internal/service/location_service"
+	lookup_service "book_system/internal/service/lookup_service"
+	notification_service "book_system/internal/service/notification_service"
+	order_service "book_system/internal/service/order_service"
+	payment_service "book_system/internal/service/payment_service"
+	pricing_service "book_system/internal/service/pricing_service"
+	reading_list_service "book_system/internal/service/reading_list_service"
+	recommendation_service "book_system/internal/service/recommendation_service"
+	review_service "book_system/internal/service/review_service"
+	series_service "book_system/internal/service/series_service"
 	token_service "book_system/internal/service/token_service"
 	upload_service "book_system/internal/service/upload_service"
 	user_service "book_system/internal/service/user_service"
+	work_service "book_system/internal/service/work_service"
 	"book_system/internal/transport/middleware"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
 type Router struct {
 	db *gorm.DB
+	// workers tracks the background jobs that must finish before the database closes
+	workers sync.WaitGroup
 }
 
 func NewRouter(db *gorm.DB) *Router {
@@ -25,7 +55,24 @@ func NewRouter(db *gorm.DB) *Router {
 	}
 }
 
-func (r *Router) SetupRoutes(router *gin.Engine) {
+// Wait waits for the background jobs tracked by SetupRoutes to stop after
+// the ctx given to it is cancelled
+func (r *Router) Wait() {
+	r.workers.Wait()
+}
+
+// run runs fn in the background, tracked by Wait
+func (r *Router) run(fn func()) {
+	r.workers.Add(1)
+	go func() {
+		defer r.workers.Done()
+		fn()
+	}()
+}
+
+// SetupRoutes registers the API on router and starts the background jobs,
+// which run until ctx is cancelled
+func (r *Router) SetupRoutes(ctx context.Context, router *gin.Engine) {
 	// Initialize health check with database connection
 	db, err := r.db.DB()
 	if err != nil {
@@ -71,6 +118,33 @@ func (r *Router) SetupRoutes(router *gin.Engine) {
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(r.db)
 	bookRepo := repository.NewBookRepository(r.db)
+	bookVersionRepo := repository.NewBookVersionRepository(r.db)
+	jobRepo := repository.NewJobRepository(r.db)
+	workRepo := repository.NewWorkRepository(r.db)
+	seriesRepo := repository.NewSeriesRepository(r.db)
+	categoryRepo := repository.NewCategoryRepository(r.db)
+	stockMovementRepo := repository.NewStockMovementRepository(r.db)
+	locationRepo := repository.NewLocationRepository(r.db)
+	stockLevelRepo := repository.NewStockLevelRepository(r.db)
+	cartRepo := repository.NewCartRepository(r.db)
+	orderRepo := repository.NewOrderRepository(r.db)
+	paymentRepo := repository.NewPaymentRepository(r.db)
+	priceRuleRepo := repository.NewPriceRuleRepository(r.db)
+	couponRepo := repository.NewCouponRepository(r.db)
+	bookPriceRepo := repository.NewBookPriceRepository(r.db)
+	exchangeRateRepo := repository.NewExchangeRateRepository(r.db)
+	copyRepo := repository.NewCopyRepository(r.db)
+	loanRepo := repository.NewLoanRepository(r.db)
+	holdRepo := repository.NewHoldRepository(r.db)
+	notificationRepo := repository.NewNotificationRepository(r.db)
+	finePolicyRepo := repository.NewFinePolicyRepository(r.db)
+	accountEntryRepo := repository.NewAccountEntryRepository(r.db)
+	reviewRepo := repository.NewReviewRepository(r.db)
+	readingListRepo := repository.NewReadingListRepository(r.db)
+	readingListItemRepo := repository.NewReadingListItemRepository(r.db)
+	bookViewRepo := repository.NewBookViewRepository(r.db)
+	bookSimilarityRepo := repository.NewBookSimilarityRepository(r.db)
+	transactor := repository.NewTransactor(r.db)
 
 	// Initialize services
 	tokenSvc := token_service.NewTokenService(
@@ -81,13 +155,145 @@ func (r *Router) SetupRoutes(router *gin.Engine) {
 	)
 
 	userService := user_service.NewUserService(userRepo, tokenSvc)
-	bookService := book_service.NewBookService(bookRepo)
+	notificationService := notification_service.NewNotificationService(notificationRepo)
+	holdService := hold_service.NewHoldService(holdRepo, bookRepo, notificationService, transactor, time.Duration(config.MustGet().Holds.PickupHours)*time.Hour)
+	if interval := config.MustGet().Holds.ExpireInterval; interval > 0 {
+		r.run(func() { holdService.RunExpirer(ctx, time.Duration(interval)*time.Minute) })
+	}
+	inventoryService := inventory_service.NewInventoryService(bookRepo, stockMovementRepo, locationRepo, stockLevelRepo, transactor, holdService, userRepo, notificationService, config.MustGet().Book.LowStockThreshold)
+	locationService := location_service.NewLocationService(locationRepo, stockLevelRepo, bookRepo, stockMovementRepo, transactor)
+	pricingService := pricing_service.NewPricingService(
+		priceRuleRepo,
+		couponRepo,
+		bookRepo,
+		bookPriceRepo,
+		exchangeRateRepo,
+		userRepo,
+		config.MustGet().Book.Currency,
+	)
+	exchangeRateService := exchange_rate_service.NewExchangeRateService(exchangeRateRepo)
+	cartService := cart_service.NewCartService(cartRepo, bookRepo, pricingService)
+	orderService := order_service.NewOrderService(
+		orderRepo,
+		cartRepo,
+		bookRepo,
+		inventoryService,
+		pricingService,
+		holdService,
+		transactor,
+		time.Duration(config.MustGet().Orders.PendingMinutes)*time.Minute,
+	)
+	if interval := config.MustGet().Orders.ExpireInterval; interval > 0 {
+		r.run(func() { orderService.RunExpirer(ctx, time.Duration(interval)*time.Minute) })
+	}
+	// Payments are optional: without a usable provider the rest of the API
+	// still starts, only the payment routes are left out
+	var paymentService service.IPaymentService
+	paymentProvider, err := payment_service.NewProvider(
+		config.MustGet().Payment.Provider,
+		config.MustGet().Payment.WebhookSecret,
+		config.MustGet().Payment.AllowFake,
+	)
+	switch {
+	case errors.Is(err, service.ErrNoPaymentProvider):
+		slog.Warn("No payment provider configured, payment routes are disabled")
+	case err != nil:
+		slog.Error("Failed to create payment provider, payment routes are disabled", slog.Any("error", err))
+	default:
+		paymentService = payment_service.NewPaymentService(
+			paymentRepo,
+			orderRepo,
+			orderService,
+			paymentProvider,
+			transactor,
+			time.Duration(config.MustGet().Payment.StuckAfter)*time.Minute,
+		)
+		if interval := config.MustGet().Payment.ReconcileInterval; interval > 0 {
+			r.run(func() { paymentService.RunReconciler(ctx, time.Duration(interval)*time.Minute) })
+		}
+	}
+	loanPeriods := make(map[string]time.Duration)
+	for role, days := range config.MustGet().Circulation.LoanDays {
+		loanPeriods[role] = time.Duration(days) * 24 * time.Hour
+	}
+	fineService := fine_service.NewFineService(
+		finePolicyRepo,
+		accountEntryRepo,
+		loanRepo,
+		userRepo,
+		transactor,
+		config.MustGet().Book.Currency,
+		model.RoundMoney(decimal.NewFromFloat(config.MustGet().Fines.BlockThreshold), config.MustGet().Book.Currency),
+	)
+	if hour := config.MustGet().Fines.AccrueHour; hour >= 0 {
+		r.run(func() { fineService.RunNightly(ctx, hour) })
+	}
+	circulationService := circulation_service.NewCirculationService(
+		copyRepo,
+		loanRepo,
+		bookRepo,
+		locationRepo,
+		userRepo,
+		fineService,
+		transactor,
+		loanPeriods,
+		time.Duration(config.MustGet().Circulation.DefaultLoanDays)*24*time.Hour,
+		config.MustGet().Circulation.RenewalLimit,
+	)
 	uploadService := upload_service.NewUploadService()
+	bookService := book_service.NewBookService(bookRepo, bookVersionRepo, workRepo, categoryRepo, inventoryService, uploadService, transactor)
+	reviewService := review_service.NewReviewService(reviewRepo, bookRepo, userRepo, transactor)
+	readingListService := reading_list_service.NewReadingListService(readingListRepo, readingListItemRepo, bookRepo, transactor)
+	recommendationService := recommendation_service.NewRecommendationService(
+		bookViewRepo,
+		bookSimilarityRepo,
+		bookRepo,
+		transactor,
+		infrastructure.GetRedis(),
+		time.Duration(config.MustGet().Recommendations.CacheTTL)*time.Minute,
+		config.MustGet().Recommendations.Neighbours,
+	)
+	if interval := config.MustGet().Recommendations.RefreshInterval; interval > 0 {
+		r.run(func() { recommendationService.RunRefresher(ctx, time.Duration(interval)*time.Minute) })
+	}
+	workService := work_service.NewWorkService(workRepo, seriesRepo, bookRepo)
+	seriesService := series_service.NewSeriesService(seriesRepo, workRepo, transactor)
+	categoryService := category_service.NewCategoryService(categoryRepo, bookRepo)
+	bookImportService := import_service.NewBookImportService(bookService, bookRepo, jobRepo, uploadService, config.MustGet().Book.Currency)
+	r.run(func() { bookImportService.RunWorkers(ctx) })
+	bookExportService := export_service.NewBookExportService(bookRepo, jobRepo, uploadService, config.MustGet().Onix.SenderName, config.MustGet().Book.Currency)
+	r.run(func() { bookExportService.RunWorkers(ctx) })
+	bookCoverService := cover_service.NewBookCoverService(bookService, uploadService)
+	bookLookupService := lookup_service.NewBookLookupService(
+		lookup_service.NewOpenLibraryProvider(config.MustGet().Lookup.BaseURL, time.Duration(config.MustGet().Lookup.Timeout)*time.Second),
+		uploadService,
+		infrastructure.GetRedis(),
+		time.Duration(config.MustGet().Lookup.CacheTTL)*time.Minute,
+		time.Duration(config.MustGet().Lookup.Timeout)*time.Second,
+	)
 
 	// Initialize transports
 	userController := NewUserController(userService)
-	bookController := NewBookController(bookService)
+	bookController := NewBookController(bookService, bookLookupService, bookCoverService, pricingService, recommendationService, config.MustGet().Book.RequireIfMatch)
+	inventoryController := NewInventoryController(inventoryService)
+	locationController := NewLocationController(locationService)
+	pricingController := NewPricingController(pricingService)
+	exchangeRateController := NewExchangeRateController(exchangeRateService)
+	cartController := NewCartController(cartService)
+	orderController := NewOrderController(orderService)
+	circulationController := NewCirculationController(circulationService)
+	holdController := NewHoldController(holdService)
+	fineController := NewFineController(fineService)
+	reviewController := NewReviewController(reviewService)
+	readingListController := NewReadingListController(readingListService)
+	recommendationController := NewRecommendationController(recommendationService, pricingService)
+	notificationController := NewNotificationController(notificationService)
+	workController := NewWorkController(workService)
+	seriesController := NewSeriesController(seriesService)
+	categoryController := NewCategoryController(categoryService)
 	uploadController := NewUploadController(uploadService)
+	bookImportController := NewBookImportController(bookImportService)
+	bookExportController := NewBookExportController(bookExportService)
 
 	// Public routes
 	v1 := router.Group("/api/v1")
@@ -105,10 +311,152 @@ func (r *Router) SetupRoutes(router *gin.Engine) {
 		usersGroup := v1.Group("/users")
 		usersGroup.Use(middleware.AuthMiddleware(tokenSvc))
 		userController.SetupUsersRoutes(usersGroup)
+		recommendationController.SetupUserRecommendationsRoutes(usersGroup)
 
 		// Book routes (protected)
 		booksGroup := v1.Group("/books")
 		booksGroup.Use(middleware.AuthMiddleware(tokenSvc))
 		bookController.SetupBooksRoutes(booksGroup)
+		bookImportController.SetupBookImportRoutes(booksGroup.Group("/import"))
+		bookExportController.SetupBookExportRoutes(booksGroup.Group("/export"))
+		inventoryController.SetupInventoryRoutes(booksGroup)
+		pricingController.SetupBookPriceRoutes(booksGroup)
+		circulationController.SetupBookCopiesRoutes(booksGroup)
+		holdController.SetupBookHoldsRoutes(booksGroup)
+		reviewController.SetupBookReviewsRoutes(booksGroup)
+		recommendationController.SetupBookSimilarRoutes(booksGroup)
+
+		// Review routes (protected, moderation is admin only)
+		reviewsGroup := v1.Group("/reviews")
+		reviewsGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		reviewController.SetupReviewsRoutes(reviewsGroup)
+
+		adminReviewsGroup := v1.Group("/admin/reviews")
+		adminReviewsGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		reviewController.SetupAdminReviewsRoutes(adminReviewsGroup)
+
+		// Reading list routes (protected, except lists shared by link)
+		readingListsGroup := v1.Group("/reading-lists")
+		readingListsGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		readingListController.SetupReadingListsRoutes(readingListsGroup)
+
+		sharedReadingListsGroup := v1.Group("/shared/reading-lists")
+		readingListController.SetupSharedReadingListsRoutes(sharedReadingListsGroup)
+
+		// Recommendation refresh route (admin only)
+		adminRecommendationsGroup := v1.Group("/admin/recommendations")
+		adminRecommendationsGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		recommendationController.SetupAdminRecommendationsRoutes(adminRecommendationsGroup)
+
+		// Work and series routes (protected)
+		worksGroup := v1.Group("/works")
+		worksGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		workController.SetupWorksRoutes(worksGroup)
+
+		seriesGroup := v1.Group("/series")
+		seriesGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		seriesController.SetupSeriesRoutes(seriesGroup)
+
+		// Category routes (protected, changes are admin only)
+		categoriesGroup := v1.Group("/categories")
+		categoriesGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		categoryController.SetupCategoriesRoutes(categoriesGroup)
+
+		// Store and warehouse routes (protected)
+		locationsGroup := v1.Group("/locations")
+		locationsGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		locationController.SetupLocationsRoutes(locationsGroup)
+
+		// Price rule and coupon routes (admin only)
+		adminPriceRulesGroup := v1.Group("/admin/price-rules")
+		adminPriceRulesGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		pricingController.SetupPriceRulesRoutes(adminPriceRulesGroup)
+
+		adminCouponsGroup := v1.Group("/admin/coupons")
+		adminCouponsGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		pricingController.SetupCouponsRoutes(adminCouponsGroup)
+
+		// Exchange rate routes (protected, recording them is admin only)
+		exchangeRatesGroup := v1.Group("/exchange-rates")
+		exchangeRatesGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		exchangeRateController.SetupExchangeRatesRoutes(exchangeRatesGroup)
+
+		adminExchangeRatesGroup := v1.Group("/admin/exchange-rates")
+		adminExchangeRatesGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		exchangeRateController.SetupAdminExchangeRatesRoutes(adminExchangeRatesGroup)
+
+		// Cart and order routes (protected)
+		cartGroup := v1.Group("/cart")
+		cartGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		cartController.SetupCartRoutes(cartGroup)
+
+		ordersGroup := v1.Group("/orders")
+		ordersGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		orderController.SetupOrdersRoutes(ordersGroup)
+
+		adminOrdersGroup := v1.Group("/admin/orders")
+		adminOrdersGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		orderController.SetupAdminOrdersRoutes(adminOrdersGroup)
+
+		// Library copy and loan routes (protected, lending at the desk is admin only)
+		copiesGroup := v1.Group("/copies")
+		copiesGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		circulationController.SetupCopiesRoutes(copiesGroup)
+
+		loansGroup := v1.Group("/loans")
+		loansGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		circulationController.SetupLoansRoutes(loansGroup)
+
+		adminLoansGroup := v1.Group("/admin/loans")
+		adminLoansGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		circulationController.SetupAdminLoansRoutes(adminLoansGroup)
+
+		// Hold and notification routes (protected, expiring holds is admin only)
+		holdsGroup := v1.Group("/holds")
+		holdsGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		holdController.SetupHoldsRoutes(holdsGroup)
+
+		adminHoldsGroup := v1.Group("/admin/holds")
+		adminHoldsGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		holdController.SetupAdminHoldsRoutes(adminHoldsGroup)
+
+		notificationsGroup := v1.Group("/notifications")
+		notificationsGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		notificationController.SetupNotificationsRoutes(notificationsGroup)
+
+		// Fine and member account routes (protected, managing them is admin only)
+		accountGroup := v1.Group("/account")
+		accountGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		fineController.SetupAccountRoutes(accountGroup)
+
+		adminAccountsGroup := v1.Group("/admin/accounts")
+		adminAccountsGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		fineController.SetupAdminAccountsRoutes(adminAccountsGroup)
+
+		adminFinePoliciesGroup := v1.Group("/admin/fine-policies")
+		adminFinePoliciesGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		fineController.SetupFinePoliciesRoutes(adminFinePoliciesGroup)
+
+		adminFinesGroup := v1.Group("/admin/fines")
+		adminFinesGroup.Use(middleware.AuthMiddleware(tokenSvc))
+		fineController.SetupAdminFinesRoutes(adminFinesGroup)
+
+		// Payment routes (protected, except provider webhooks which are
+		// signed), only when a payment provider is configured
+		if paymentService != nil {
+			paymentController := NewPaymentController(paymentService)
+			paymentController.SetupOrderPaymentRoutes(ordersGroup)
+
+			paymentsGroup := v1.Group("/payments")
+			paymentsGroup.Use(middleware.AuthMiddleware(tokenSvc))
+			paymentController.SetupPaymentsRoutes(paymentsGroup)
+
+			adminPaymentsGroup := v1.Group("/admin/payments")
+			adminPaymentsGroup.Use(middleware.AuthMiddleware(tokenSvc))
+			paymentController.SetupAdminPaymentsRoutes(adminPaymentsGroup)
+
+			webhooksGroup := v1.Group("/webhooks")
+			paymentController.SetupWebhookRoutes(webhooksGroup)
+		}
 	}
 }
@@ -0,0 +1,49 @@
+package restapi
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestRouterWait checks that Wait holds shutdown until every background
+// loop started through run has returned, including one still finishing
+// its work after ctx is cancelled
+func TestRouterWait(t *testing.T) {
+	var r Router
+	ctx, cancel := context.WithCancel(context.Background())
+
+	const loops = 5
+	var exited atomic.Int32
+	for i := range loops {
+		r.run(func() {
+			<-ctx.Done()
+			// The last pass of a loop may still be in a transaction
+			time.Sleep(time.Duration(i) * 10 * time.Millisecond)
+			exited.Add(1)
+		})
+	}
+
+	waited := make(chan struct{})
+	go func() {
+		r.Wait()
+		close(waited)
+	}()
+
+	select {
+	case <-waited:
+		t.Fatal("Wait() returned before ctx was cancelled")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case <-waited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wait() did not return after ctx was cancelled")
+	}
+	if n := exited.Load(); n != loops {
+		t.Errorf("Wait() returned with %d of %d loops exited", n, loops)
+	}
+}
@@ -0,0 +1,180 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SeriesController handles series related HTTP requests
+type SeriesController struct {
+	seriesService service.ISeriesService
+}
+
+// NewSeriesController creates a new series transport
+func NewSeriesController(seriesService service.ISeriesService) *SeriesController {
+	return &SeriesController{
+		seriesService: seriesService,
+	}
+}
+
+func (c *SeriesController) SetupSeriesRoutes(router *gin.RouterGroup) {
+	router.POST("", c.CreateSeries)
+	router.GET("", c.ListSeries)
+	router.GET(":id", c.GetSeries)
+	router.PUT(":id", c.UpdateSeries)
+	router.DELETE(":id", c.DeleteSeries)
+}
+
+// CreateSeries godoc
+// @Summary Create a new series
+// @Description Create a series. Works join it by setting their series_id and position
+// @Tags series
+// @Accept  json
+// @Produce  json
+// @Param input body model.SeriesRequest true "Series data"
+// @Success 201 {object} response.Response{data=model.SeriesResponse} "Successfully created series"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/series [post]
+func (c *SeriesController) CreateSeries(ctx *gin.Context) {
+	var req model.SeriesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	series, err := c.seriesService.CreateSeries(ctx.Request.Context(), &req)
+	if err != nil {
+		slog.Error("Failed to create series", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to create series")
+		return
+	}
+
+	response.Created(ctx, series)
+}
+
+// GetSeries godoc
+// @Summary Get a series by ID
+// @Description Get a series with its volumes in reading order
+// @Tags series
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Series ID"
+// @Success 200 {object} response.Response{data=model.SeriesResponse} "Successfully retrieved series"
+// @Failure 400 {object} response.Response "Invalid series ID"
+// @Failure 404 {object} response.Response "Series not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/series/{id} [get]
+func (c *SeriesController) GetSeries(ctx *gin.Context) {
+	series, err := c.seriesService.GetSeries(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get series")
+		return
+	}
+
+	response.Success(ctx, series)
+}
+
+// ListSeries godoc
+// @Summary List all series with pagination
+// @Description Get a paginated list of series ordered by title
+// @Tags series
+// @Accept  json
+// @Produce  json
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Success 200 {object} response.Response{data=model.SeriesListResponse} "Successfully retrieved series"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/series [get]
+func (c *SeriesController) ListSeries(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	result, err := c.seriesService.ListSeries(ctx.Request.Context(), page, pageSize)
+	if err != nil {
+		slog.Error("Failed to list series", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to list series")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// UpdateSeries godoc
+// @Summary Replace a series
+// @Description Replace all editable fields of a series
+// @Tags series
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Series ID"
+// @Param input body model.SeriesRequest true "Series data"
+// @Success 200 {object} response.Response{data=model.SeriesResponse} "Successfully updated series"
+// @Failure 400 {object} response.Response "Invalid input"
+// @Failure 404 {object} response.Response "Series not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/series/{id} [put]
+func (c *SeriesController) UpdateSeries(ctx *gin.Context) {
+	var req model.SeriesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	series, err := c.seriesService.UpdateSeries(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to update series")
+		return
+	}
+
+	response.Success(ctx, series)
+}
+
+// DeleteSeries godoc
+// @Summary Delete a series
+// @Description Delete a series. Its volumes are kept as standalone works
+// @Tags series
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Series ID"
+// @Success 200 {object} response.Response "Successfully deleted series"
+// @Failure 400 {object} response.Response "Invalid series ID"
+// @Failure 404 {object} response.Response "Series not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/series/{id} [delete]
+func (c *SeriesController) DeleteSeries(ctx *gin.Context) {
+	if err := c.seriesService.DeleteSeries(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		c.writeError(ctx, err, "Failed to delete series")
+		return
+	}
+
+	response.Success(ctx, nil)
+}
+
+// writeError writes the response for a failed series operation
+func (c *SeriesController) writeError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidSeriesID):
+		response.BadRequest(ctx, "Invalid series ID")
+	case errors.Is(err, service.ErrSeriesNotFound):
+		response.NotFound(ctx, "Series not found")
+	default:
+		slog.Error(msg, slog.Any("error", err))
+		response.InternalServerError(ctx, msg)
+	}
+}
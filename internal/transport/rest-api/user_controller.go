@@ -117,7 +117,7 @@ func (uc *UserController) GetUserProfile(c *gin.Context) {
 
 // UpdateUserProfile godoc
 // @Summary Update user profile
-// @Description Update the profile of the authenticated user
+// @Description Update the profile of the authenticated user. Currency is the ISO 4217 code prices are shown in when a request does not ask for one
 // @Tags users
 // @Security BearerAuth
 // @Accept  json
@@ -140,6 +140,11 @@ func (uc *UserController) UpdateUserProfile(c *gin.Context) {
 		return
 	}
 
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	updatedUser, err := uc.userService.UpdateUser(c.Request.Context(), userID.(string), &req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -0,0 +1,243 @@
+package restapi
+
+import (
+	"book_system/internal/model"
+	"book_system/internal/service"
+	"book_system/internal/transport/response"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkController handles work related HTTP requests
+type WorkController struct {
+	workService service.IWorkService
+}
+
+// NewWorkController creates a new work transport
+func NewWorkController(workService service.IWorkService) *WorkController {
+	return &WorkController{
+		workService: workService,
+	}
+}
+
+func (c *WorkController) SetupWorksRoutes(router *gin.RouterGroup) {
+	router.POST("", c.CreateWork)
+	router.GET("", c.ListWorks)
+	router.GET(":id", c.GetWork)
+	router.PUT(":id", c.UpdateWork)
+	router.DELETE(":id", c.DeleteWork)
+	router.GET(":id/editions", c.ListEditions)
+	router.GET(":id/series", c.GetSeriesNavigation)
+}
+
+// CreateWork godoc
+// @Summary Create a new work
+// @Description Create a work, the grouping of all editions of a book. A work may be placed in a series at a position that no other volume holds
+// @Tags works
+// @Accept  json
+// @Produce  json
+// @Param input body model.WorkRequest true "Work data"
+// @Success 201 {object} response.Response{data=model.WorkResponse} "Successfully created work"
+// @Failure 400 {object} response.Response "Invalid input or unknown series"
+// @Failure 409 {object} response.Response "Series position is already taken"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/works [post]
+func (c *WorkController) CreateWork(ctx *gin.Context) {
+	var req model.WorkRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	work, err := c.workService.CreateWork(ctx.Request.Context(), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to create work")
+		return
+	}
+
+	response.Created(ctx, work)
+}
+
+// GetWork godoc
+// @Summary Get a work by ID
+// @Description Get a work by its ID
+// @Tags works
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Work ID"
+// @Success 200 {object} response.Response{data=model.WorkResponse} "Successfully retrieved work"
+// @Failure 400 {object} response.Response "Invalid work ID"
+// @Failure 404 {object} response.Response "Work not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/works/{id} [get]
+func (c *WorkController) GetWork(ctx *gin.Context) {
+	work, err := c.workService.GetWork(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get work")
+		return
+	}
+
+	response.Success(ctx, work)
+}
+
+// ListWorks godoc
+// @Summary List all works with pagination
+// @Description Get a paginated list of works ordered by title
+// @Tags works
+// @Accept  json
+// @Produce  json
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Number of items per page (default: 10, max: 100)"
+// @Param author query string false "Filter by author"
+// @Param series_id query string false "Filter by series"
+// @Success 200 {object} response.Response{data=model.WorkListResponse} "Successfully retrieved works"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/works [get]
+func (c *WorkController) ListWorks(ctx *gin.Context) {
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+
+	filters := make(map[string]any)
+	if author := ctx.Query("author"); author != "" {
+		filters["author = ?"] = author
+	}
+	if seriesID := ctx.Query("series_id"); seriesID != "" {
+		filters["series_id = ?"] = seriesID
+	}
+
+	result, err := c.workService.ListWorks(ctx.Request.Context(), page, pageSize, filters)
+	if err != nil {
+		slog.Error("Failed to list works", slog.Any("error", err))
+		response.InternalServerError(ctx, "Failed to list works")
+		return
+	}
+
+	response.Success(ctx, result)
+}
+
+// UpdateWork godoc
+// @Summary Replace a work
+// @Description Replace all editable fields of a work. Omitting series_id takes the work out of its series
+// @Tags works
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Work ID"
+// @Param input body model.WorkRequest true "Work data"
+// @Success 200 {object} response.Response{data=model.WorkResponse} "Successfully updated work"
+// @Failure 400 {object} response.Response "Invalid input or unknown series"
+// @Failure 404 {object} response.Response "Work not found"
+// @Failure 409 {object} response.Response "Series position is already taken"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/works/{id} [put]
+func (c *WorkController) UpdateWork(ctx *gin.Context) {
+	var req model.WorkRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.BadRequest(ctx, err.Error())
+		return
+	}
+
+	work, err := c.workService.UpdateWork(ctx.Request.Context(), ctx.Param("id"), &req)
+	if err != nil {
+		c.writeError(ctx, err, "Failed to update work")
+		return
+	}
+
+	response.Success(ctx, work)
+}
+
+// DeleteWork godoc
+// @Summary Delete a work
+// @Description Delete a work. Its editions must be moved to another work or purged first
+// @Tags works
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Work ID"
+// @Success 200 {object} response.Response "Successfully deleted work"
+// @Failure 400 {object} response.Response "Invalid work ID"
+// @Failure 404 {object} response.Response "Work not found"
+// @Failure 409 {object} response.Response "Work still has editions"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/works/{id} [delete]
+func (c *WorkController) DeleteWork(ctx *gin.Context) {
+	if err := c.workService.DeleteWork(ctx.Request.Context(), ctx.Param("id")); err != nil {
+		c.writeError(ctx, err, "Failed to delete work")
+		return
+	}
+
+	response.Success(ctx, nil)
+}
+
+// ListEditions godoc
+// @Summary List the editions of a work
+// @Description Get a work with all its editions, such as hardcover, paperback, ebook and audiobook, oldest first
+// @Tags works
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Work ID"
+// @Success 200 {object} response.Response{data=model.WorkEditionsResponse} "Successfully retrieved editions"
+// @Failure 400 {object} response.Response "Invalid work ID"
+// @Failure 404 {object} response.Response "Work not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/works/{id}/editions [get]
+func (c *WorkController) ListEditions(ctx *gin.Context) {
+	editions, err := c.workService.ListEditions(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to list editions")
+		return
+	}
+
+	response.Success(ctx, editions)
+}
+
+// GetSeriesNavigation godoc
+// @Summary Navigate a work's series
+// @Description Get the series of a work with the previous and next volumes; either is null at the ends of the series
+// @Tags works
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Work ID"
+// @Success 200 {object} response.Response{data=model.SeriesNavigation} "Successfully retrieved series navigation"
+// @Failure 400 {object} response.Response "Invalid work ID"
+// @Failure 404 {object} response.Response "Work not found or not part of a series"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /api/v1/works/{id}/series [get]
+func (c *WorkController) GetSeriesNavigation(ctx *gin.Context) {
+	navigation, err := c.workService.GetSeriesNavigation(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		c.writeError(ctx, err, "Failed to get series navigation")
+		return
+	}
+
+	response.Success(ctx, navigation)
+}
+
+// writeError writes the response for a failed work operation
+func (c *WorkController) writeError(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidWorkID):
+		response.BadRequest(ctx, "Invalid work ID")
+	case errors.Is(err, service.ErrSeriesNotFound):
+		response.BadRequest(ctx, err.Error())
+	case errors.Is(err, service.ErrWorkNotFound), errors.Is(err, service.ErrWorkNotInSeries):
+		response.NotFound(ctx, err.Error())
+	case errors.Is(err, service.ErrSeriesPositionTaken), errors.Is(err, service.ErrWorkHasEditions):
+		response.JSON(ctx, http.StatusConflict, err.Error(), nil)
+	default:
+		slog.Error(msg, slog.Any("error", err))
+		response.InternalServerError(ctx, msg)
+	}
+}
@@ -8,10 +8,14 @@ import (
 
 const base62Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
 
-var secretKey uint32 = config.MustGet().Codec.SecretKey
+// secretKey obfuscates encoded IDs. It is read when first used, after the
+// configuration has been loaded.
+func secretKey() uint32 {
+	return config.MustGet().Codec.SecretKey
+}
 
 func encodeID(id uint32) string {
-	obfuscated := id ^ secretKey
+	obfuscated := id ^ secretKey()
 	if obfuscated == 0 {
 		return string(base62Chars[0])
 	}
@@ -68,11 +72,11 @@ func combineIDs(a, b uint32) uint64 {
 		x, y = uint32(b), uint32(a)
 	}
 	combined := (uint64(x) << 32) | uint64(y)
-	return combined ^ uint64(secretKey)
+	return combined ^ uint64(secretKey())
 }
 
 func splitIDs(encoded uint64) (uint32, uint32) {
-	decoded := encoded ^ uint64(secretKey)
+	decoded := encoded ^ uint64(secretKey())
 	a := uint32(decoded >> 32)
 	b := uint32(decoded & 0xFFFFFFFF)
 	return a, b
@@ -101,5 +105,5 @@ func decodeID(s string) (uint32, error) {
 		}
 		num = num*62 + uint32(index)
 	}
-	return num ^ secretKey, nil
+	return num ^ secretKey(), nil
 }
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"book_system/internal/config"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCodec loads the secret key the way main does, through config.Load;
+// the configuration loads once per process, so this is the only test that
+// sets it
+func TestCodec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("codec:\n  secret-key: 1234567890\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if err := config.Load(path); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	for _, id := range []uint32{0, 1, 61, 62, 1234567890, 1<<32 - 1} {
+		encoded := encodeID(id)
+		if decoded, err := decodeID(encoded); err != nil || decoded != id {
+			t.Errorf("decodeID(encodeID(%d)) = %d, %v", id, decoded, err)
+		}
+	}
+	if encodeID(7) == encodeBase62(7) {
+		t.Errorf("encodeID(7) = %q, want it obfuscated by the secret key", encodeID(7))
+	}
+
+	a, b, err := decodeRoomId(generateRoomId(42, 7))
+	if err != nil || a != 7 || b != 42 {
+		t.Errorf("decodeRoomId(generateRoomId(42, 7)) = %d, %d, %v; want 7, 42", a, b, err)
+	}
+	if _, err := decodeID("not/base62"); err == nil {
+		t.Errorf("decodeID() of an invalid ID error = nil")
+	}
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"book_system/i18n"
 	"math/rand"
 	"strconv"
 	"strings"
@@ -129,12 +130,9 @@ func GetCurrentUserId(c *gin.Context) string {
 	return ""
 }
 
+// GetCurrentLang negotiates the message language from the Accept-Language header
 func GetCurrentLang(c *gin.Context) string {
-	id := c.Request.Header["Accept-Language"]
-	if len(id) > 0 {
-		return id[0]
-	}
-	return "en"
+	return i18n.Match(c.GetHeader("Accept-Language"))
 }
 
 func GetCurrentPosition(c *gin.Context) int {
@@ -32,3 +32,26 @@ func (c *MyContext) Err() error {
 func (c *MyContext) Value(key any) any {
 	return c.Context.Value(key)
 }
+
+const (
+	UserIDContextKey   ContextKey = "user_id"
+	UserRoleContextKey ContextKey = "user_role"
+)
+
+// WithCurrentUser returns a copy of ctx carrying the authenticated user
+func WithCurrentUser(ctx context.Context, userID, role string) context.Context {
+	ctx = context.WithValue(ctx, UserIDContextKey, userID)
+	return context.WithValue(ctx, UserRoleContextKey, role)
+}
+
+// UserIDFromContext returns the authenticated user ID carried by ctx, if any
+func UserIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(UserIDContextKey).(string)
+	return userID
+}
+
+// UserRoleFromContext returns the authenticated user role carried by ctx, if any
+func UserRoleFromContext(ctx context.Context) string {
+	role, _ := ctx.Value(UserRoleContextKey).(string)
+	return role
+}
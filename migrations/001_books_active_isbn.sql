@@ -0,0 +1,10 @@
+-- Moves deleted books to a trash instead of removing them. The ISBNs of
+-- live books stay unique, leaving books in the trash free to share theirs.
+
+ALTER TABLE books
+    ADD COLUMN deleted_at DATETIME(3),
+    ADD COLUMN active_isbn VARCHAR(20) GENERATED ALWAYS AS (IF(deleted_at IS NULL, isbn, NULL)) STORED AFTER isbn,
+    DROP INDEX idx_books_isbn,
+    ADD INDEX idx_books_isbn (isbn),
+    ADD UNIQUE INDEX idx_books_active_isbn (active_isbn),
+    ADD INDEX idx_books_deleted_at (deleted_at);
@@ -0,0 +1,14 @@
+-- Keeps every version of a book, with the changes from the one before it.
+
+CREATE TABLE IF NOT EXISTS book_versions (
+    id         CHAR(36)    NOT NULL,
+    book_id    CHAR(36)    NOT NULL,
+    version    BIGINT      NOT NULL,
+    action     VARCHAR(20) NOT NULL,
+    actor_id   VARCHAR(64),
+    snapshot   JSON        NOT NULL,
+    changes    JSON,
+    created_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_book_versions_book_version (book_id, version)
+);
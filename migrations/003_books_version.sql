@@ -0,0 +1,5 @@
+-- Numbers the versions of a book, for ETags and If-Match. Existing books
+-- start at version 1.
+
+ALTER TABLE books
+    ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
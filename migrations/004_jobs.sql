@@ -0,0 +1,21 @@
+-- Tracks background jobs such as bulk imports.
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id            CHAR(36)     NOT NULL,
+    type          VARCHAR(50)  NOT NULL,
+    status        VARCHAR(20)  NOT NULL,
+    actor_id      VARCHAR(64),
+    params        JSON,
+    total         BIGINT       NOT NULL DEFAULT 0,
+    succeeded     BIGINT       NOT NULL DEFAULT 0,
+    failed        BIGINT       NOT NULL DEFAULT 0,
+    result_object VARCHAR(512),
+    error         TEXT,
+    started_at    DATETIME(3),
+    finished_at   DATETIME(3),
+    created_at    DATETIME(3)  NOT NULL,
+    updated_at    DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_jobs_type (type),
+    INDEX idx_jobs_status (status)
+);
@@ -0,0 +1,4 @@
+-- Counts the fields of imported ONIX records that had no book field.
+
+ALTER TABLE jobs
+    ADD COLUMN unmapped JSON AFTER result_object;
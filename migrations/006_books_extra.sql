@@ -0,0 +1,4 @@
+-- Keeps the MARC fields of imported books that have no column of their own.
+
+ALTER TABLE books
+    ADD COLUMN extra JSON;
@@ -0,0 +1,6 @@
+-- Keeps the ISBN-10 form of a book's ISBN next to the ISBN-13. Run
+-- `make migrate-isbn` after it to rewrite existing ISBNs in canonical form
+-- and fill the column in.
+
+ALTER TABLE books
+    ADD COLUMN isbn10 VARCHAR(10) AFTER isbn;
@@ -0,0 +1,4 @@
+-- Keeps the variants of covers uploaded through the cover pipeline.
+
+ALTER TABLE books
+    ADD COLUMN cover JSON;
@@ -0,0 +1,30 @@
+-- Groups the editions of a book into works, and works into series.
+
+CREATE TABLE IF NOT EXISTS series (
+    id          CHAR(36)     NOT NULL,
+    title       VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at  DATETIME(3)  NOT NULL,
+    updated_at  DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS works (
+    id              CHAR(36)      NOT NULL,
+    title           VARCHAR(255)  NOT NULL,
+    author          VARCHAR(255),
+    description     TEXT,
+    series_id       CHAR(36),
+    series_position DECIMAL(6, 2) NOT NULL DEFAULT 0,
+    created_at      DATETIME(3)   NOT NULL,
+    updated_at      DATETIME(3)   NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_works_series_position (series_id, series_position)
+);
+
+ALTER TABLE books
+    ADD COLUMN work_id CHAR(36),
+    ADD COLUMN format VARCHAR(20),
+    ADD COLUMN language VARCHAR(35),
+    ADD COLUMN page_count BIGINT NOT NULL DEFAULT 0,
+    ADD INDEX idx_books_work_id (work_id);
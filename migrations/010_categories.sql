@@ -0,0 +1,17 @@
+-- Files books under categories and keeps the translations of their titles,
+-- descriptions and category names.
+
+CREATE TABLE IF NOT EXISTS categories (
+    id           CHAR(36)     NOT NULL,
+    name         VARCHAR(100) NOT NULL,
+    translations JSON,
+    created_at   DATETIME(3)  NOT NULL,
+    updated_at   DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_categories_name (name)
+);
+
+ALTER TABLE books
+    ADD COLUMN category_id CHAR(36) AFTER work_id,
+    ADD COLUMN translations JSON,
+    ADD INDEX idx_books_category_id (category_id);
@@ -0,0 +1,20 @@
+-- Records every change to a book's stock in a ledger, opening it with an
+-- adjustment to the stock each book has now.
+
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id          CHAR(36)     NOT NULL,
+    book_id     CHAR(36)     NOT NULL,
+    type        VARCHAR(20)  NOT NULL,
+    quantity    BIGINT       NOT NULL,
+    stock_after BIGINT       NOT NULL,
+    reason      VARCHAR(255),
+    actor_id    VARCHAR(64),
+    created_at  DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_stock_movements_book_created (book_id, created_at)
+);
+
+INSERT INTO stock_movements (id, book_id, type, quantity, stock_after, reason, created_at)
+SELECT UUID(), id, 'adjust', stock, stock, 'opening stock', NOW(3)
+FROM books
+WHERE stock <> 0;
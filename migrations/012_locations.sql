@@ -0,0 +1,29 @@
+-- Splits stock across locations. Existing stock stays unallocated until it
+-- is moved to a location.
+
+CREATE TABLE IF NOT EXISTS locations (
+    id         CHAR(36)     NOT NULL,
+    code       VARCHAR(20)  NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    type       VARCHAR(20)  NOT NULL,
+    address    VARCHAR(512),
+    created_at DATETIME(3)  NOT NULL,
+    updated_at DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_locations_code (code)
+);
+
+CREATE TABLE IF NOT EXISTS stock_levels (
+    book_id     CHAR(36)    NOT NULL,
+    location_id CHAR(36)    NOT NULL,
+    quantity    BIGINT      NOT NULL DEFAULT 0,
+    updated_at  DATETIME(3) NOT NULL,
+    PRIMARY KEY (book_id, location_id),
+    INDEX idx_stock_levels_location_id (location_id)
+);
+
+ALTER TABLE stock_movements
+    ADD COLUMN location_id CHAR(36) AFTER book_id,
+    ADD COLUMN transfer_id CHAR(36) AFTER location_id,
+    ADD INDEX idx_stock_movements_location_id (location_id),
+    ADD INDEX idx_stock_movements_transfer_id (transfer_id);
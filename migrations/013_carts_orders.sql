@@ -0,0 +1,45 @@
+-- Keeps each user's cart, and the orders checked out from it.
+
+CREATE TABLE IF NOT EXISTS cart_items (
+    user_id    CHAR(36)    NOT NULL,
+    book_id    CHAR(36)    NOT NULL,
+    quantity   BIGINT      NOT NULL,
+    created_at DATETIME(3) NOT NULL,
+    updated_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (user_id, book_id)
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id               CHAR(36)       NOT NULL,
+    user_id          CHAR(36)       NOT NULL,
+    status           VARCHAR(20)    NOT NULL,
+    location_id      CHAR(36),
+    currency         VARCHAR(3)     NOT NULL,
+    total            DECIMAL(16, 3) NOT NULL,
+    shipping_address VARCHAR(512)   NOT NULL,
+    paid_at          DATETIME(3),
+    shipped_at       DATETIME(3),
+    delivered_at     DATETIME(3),
+    cancelled_at     DATETIME(3),
+    refunded_at      DATETIME(3),
+    created_at       DATETIME(3)    NOT NULL,
+    updated_at       DATETIME(3)    NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_orders_user_id (user_id),
+    INDEX idx_orders_status (status),
+    INDEX idx_orders_created_at (created_at)
+);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id         CHAR(36)       NOT NULL,
+    order_id   CHAR(36)       NOT NULL,
+    book_id    CHAR(36)       NOT NULL,
+    title      VARCHAR(255)   NOT NULL,
+    isbn       VARCHAR(20),
+    unit_price DECIMAL(16, 3) NOT NULL,
+    quantity   BIGINT         NOT NULL,
+    line_total DECIMAL(16, 3) NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_order_items_order_id (order_id),
+    INDEX idx_order_items_book_id (book_id)
+);
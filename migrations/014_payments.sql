@@ -0,0 +1,36 @@
+-- Keeps the payments of orders and the provider webhook events applied to
+-- them, so a redelivered event is applied once.
+
+CREATE TABLE IF NOT EXISTS payments (
+    id             CHAR(36)       NOT NULL,
+    order_id       CHAR(36)       NOT NULL,
+    attempt        BIGINT         NOT NULL DEFAULT 1,
+    user_id        CHAR(36)       NOT NULL,
+    amount         DECIMAL(16, 3) NOT NULL,
+    currency       VARCHAR(3)     NOT NULL,
+    book_ids       JSON,
+    provider       VARCHAR(50)    NOT NULL,
+    provider_ref   VARCHAR(100),
+    client_secret  VARCHAR(255),
+    status         VARCHAR(20)    NOT NULL,
+    failure_reason VARCHAR(255),
+    captured_at    DATETIME(3),
+    refunded_at    DATETIME(3),
+    created_at     DATETIME(3)    NOT NULL,
+    updated_at     DATETIME(3)    NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_payments_order_attempt (order_id, attempt),
+    UNIQUE INDEX idx_payments_provider_ref (provider_ref),
+    INDEX idx_payments_user_id (user_id),
+    INDEX idx_payments_status (status),
+    INDEX idx_payments_created_at (created_at)
+);
+
+CREATE TABLE IF NOT EXISTS payment_events (
+    id          VARCHAR(100) NOT NULL,
+    payment_id  CHAR(36)     NOT NULL,
+    status      VARCHAR(20)  NOT NULL,
+    received_at DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_payment_events_payment_id (payment_id)
+);
@@ -0,0 +1,56 @@
+-- Adds price rules and coupons, and keeps the discount of each order. Orders
+-- placed before it had no discount, so their subtotal is their total.
+
+CREATE TABLE IF NOT EXISTS price_rules (
+    id          CHAR(36)       NOT NULL,
+    name        VARCHAR(255)   NOT NULL,
+    type        VARCHAR(20)    NOT NULL,
+    value       DECIMAL(16, 3) NOT NULL,
+    scope_type  VARCHAR(20)    NOT NULL,
+    scope_value VARCHAR(255),
+    starts_at   DATETIME(3),
+    ends_at     DATETIME(3),
+    active      BOOLEAN        NOT NULL DEFAULT TRUE,
+    created_at  DATETIME(3)    NOT NULL,
+    updated_at  DATETIME(3)    NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_price_rules_starts_at (starts_at),
+    INDEX idx_price_rules_ends_at (ends_at)
+);
+
+CREATE TABLE IF NOT EXISTS coupons (
+    id           CHAR(36)       NOT NULL,
+    code         VARCHAR(50)    NOT NULL,
+    type         VARCHAR(20)    NOT NULL,
+    value        DECIMAL(16, 3) NOT NULL,
+    min_subtotal DECIMAL(16, 3) NOT NULL DEFAULT 0,
+    max_uses     BIGINT         NOT NULL DEFAULT 0,
+    uses         BIGINT         NOT NULL DEFAULT 0,
+    starts_at    DATETIME(3),
+    ends_at      DATETIME(3),
+    active       BOOLEAN        NOT NULL DEFAULT TRUE,
+    created_at   DATETIME(3)    NOT NULL,
+    updated_at   DATETIME(3)    NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_coupons_code (code)
+);
+
+ALTER TABLE orders
+    ADD COLUMN subtotal DECIMAL(16, 3) NOT NULL DEFAULT 0 AFTER currency,
+    ADD COLUMN discount DECIMAL(16, 3) NOT NULL DEFAULT 0 AFTER subtotal,
+    ADD COLUMN coupon_id CHAR(36) AFTER discount,
+    ADD COLUMN coupon_code VARCHAR(50) AFTER coupon_id,
+    ADD INDEX idx_orders_coupon_id (coupon_id);
+
+UPDATE orders SET subtotal = total;
+
+ALTER TABLE orders
+    ALTER COLUMN subtotal DROP DEFAULT;
+
+ALTER TABLE order_items
+    ADD COLUMN list_price DECIMAL(16, 3) NOT NULL DEFAULT 0 AFTER isbn;
+
+UPDATE order_items SET list_price = unit_price;
+
+ALTER TABLE order_items
+    ALTER COLUMN list_price DROP DEFAULT;
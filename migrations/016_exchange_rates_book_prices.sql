@@ -0,0 +1,51 @@
+-- Adds prices in other currencies and the exchange rates between them.
+-- Price rules and coupons created before it are in the catalog currency,
+-- book.currency of the configuration: set @catalog_currency to it.
+
+SET @catalog_currency = 'USD';
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id             CHAR(36)        NOT NULL,
+    base_currency  VARCHAR(3)      NOT NULL,
+    quote_currency VARCHAR(3)      NOT NULL,
+    rate           DECIMAL(20, 10) NOT NULL,
+    source         VARCHAR(20)     NOT NULL,
+    effective_at   DATETIME(3)     NOT NULL,
+    created_at     DATETIME(3)     NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_exchange_rates_pair (base_currency, quote_currency),
+    INDEX idx_exchange_rates_effective_at (effective_at)
+);
+
+CREATE TABLE IF NOT EXISTS book_prices (
+    book_id    CHAR(36)       NOT NULL,
+    currency   VARCHAR(3)     NOT NULL,
+    amount     DECIMAL(16, 3) NOT NULL,
+    updated_at DATETIME(3)    NOT NULL,
+    PRIMARY KEY (book_id, currency)
+);
+
+ALTER TABLE books
+    MODIFY COLUMN price DECIMAL(11, 3) NOT NULL,
+    ADD COLUMN currency VARCHAR(3) AFTER price;
+
+ALTER TABLE users
+    ADD COLUMN currency VARCHAR(3) AFTER avatar;
+
+ALTER TABLE order_items
+    ADD COLUMN exchange_rate_id CHAR(36);
+
+ALTER TABLE price_rules
+    ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT '' AFTER value;
+
+ALTER TABLE coupons
+    ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT '' AFTER value;
+
+UPDATE price_rules SET currency = @catalog_currency;
+UPDATE coupons SET currency = @catalog_currency;
+
+ALTER TABLE price_rules
+    ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE coupons
+    ALTER COLUMN currency DROP DEFAULT;
@@ -0,0 +1,39 @@
+-- Tracks the copies of books a library lends, and their loans.
+
+CREATE TABLE IF NOT EXISTS copies (
+    id          CHAR(36)    NOT NULL,
+    book_id     CHAR(36)    NOT NULL,
+    barcode     VARCHAR(50) NOT NULL,
+    location_id CHAR(36),
+    status      VARCHAR(20) NOT NULL,
+    created_at  DATETIME(3) NOT NULL,
+    updated_at  DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_copies_barcode (barcode),
+    INDEX idx_copies_book_id (book_id),
+    INDEX idx_copies_location_id (location_id),
+    INDEX idx_copies_status (status)
+);
+
+CREATE TABLE IF NOT EXISTS loans (
+    id          CHAR(36)     NOT NULL,
+    copy_id     CHAR(36)     NOT NULL,
+    book_id     CHAR(36)     NOT NULL,
+    user_id     CHAR(36)     NOT NULL,
+    barcode     VARCHAR(50)  NOT NULL,
+    title       VARCHAR(255) NOT NULL,
+    status      VARCHAR(20)  NOT NULL,
+    loaned_at   DATETIME(3)  NOT NULL,
+    due_at      DATETIME(3)  NOT NULL,
+    returned_at DATETIME(3),
+    renewals    BIGINT       NOT NULL DEFAULT 0,
+    created_at  DATETIME(3)  NOT NULL,
+    updated_at  DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_loans_copy_id (copy_id),
+    INDEX idx_loans_book_id (book_id),
+    INDEX idx_loans_user_id (user_id),
+    INDEX idx_loans_status (status),
+    INDEX idx_loans_loaned_at (loaned_at),
+    INDEX idx_loans_due_at (due_at)
+);
@@ -0,0 +1,32 @@
+-- Queues holds on books and keeps the notifications sent to users.
+
+CREATE TABLE IF NOT EXISTS holds (
+    id           CHAR(36)    NOT NULL,
+    book_id      CHAR(36)    NOT NULL,
+    user_id      CHAR(36)    NOT NULL,
+    status       VARCHAR(20) NOT NULL,
+    ready_at     DATETIME(3),
+    expires_at   DATETIME(3),
+    fulfilled_at DATETIME(3),
+    cancelled_at DATETIME(3),
+    expired_at   DATETIME(3),
+    created_at   DATETIME(3) NOT NULL,
+    updated_at   DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_holds_queue (book_id, status, created_at),
+    INDEX idx_holds_user_id (user_id),
+    INDEX idx_holds_expires_at (expires_at)
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id         CHAR(36)     NOT NULL,
+    user_id    CHAR(36)     NOT NULL,
+    type       VARCHAR(50)  NOT NULL,
+    message    VARCHAR(512) NOT NULL,
+    book_id    CHAR(36),
+    read_at    DATETIME(3),
+    created_at DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_notifications_user_id (user_id),
+    INDEX idx_notifications_created_at (created_at)
+);
@@ -0,0 +1,32 @@
+-- Adds fines on overdue loans, the policies they follow and the member
+-- accounts they are charged to.
+
+CREATE TABLE IF NOT EXISTS fine_policies (
+    id           CHAR(36)       NOT NULL,
+    role         VARCHAR(20)    NOT NULL,
+    daily_rate   DECIMAL(16, 3) NOT NULL,
+    grace_days   BIGINT         NOT NULL DEFAULT 0,
+    max_per_item DECIMAL(16, 3) NOT NULL DEFAULT 0,
+    created_at   DATETIME(3)    NOT NULL,
+    updated_at   DATETIME(3)    NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_fine_policies_role (role)
+);
+
+CREATE TABLE IF NOT EXISTS account_entries (
+    id         CHAR(36)       NOT NULL,
+    user_id    CHAR(36)       NOT NULL,
+    loan_id    CHAR(36),
+    type       VARCHAR(20)    NOT NULL,
+    amount     DECIMAL(16, 3) NOT NULL,
+    note       VARCHAR(255),
+    actor_id   VARCHAR(36),
+    created_at DATETIME(3)    NOT NULL,
+    PRIMARY KEY (id),
+    INDEX idx_account_entries_user_id (user_id),
+    INDEX idx_account_entries_loan_id (loan_id),
+    INDEX idx_account_entries_created_at (created_at)
+);
+
+ALTER TABLE loans
+    ADD COLUMN fine DECIMAL(16, 3) NOT NULL DEFAULT 0 AFTER renewals;
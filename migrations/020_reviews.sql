@@ -0,0 +1,27 @@
+-- Adds reviews of books, and the sum and count of each book's visible
+-- ratings.
+
+CREATE TABLE IF NOT EXISTS reviews (
+    id              CHAR(36)     NOT NULL,
+    book_id         CHAR(36)     NOT NULL,
+    user_id         CHAR(36)     NOT NULL,
+    author          VARCHAR(100) NOT NULL,
+    rating          BIGINT       NOT NULL,
+    body            TEXT,
+    status          VARCHAR(20)  NOT NULL,
+    moderation_note VARCHAR(255),
+    moderated_by    VARCHAR(36),
+    moderated_at    DATETIME(3),
+    created_at      DATETIME(3)  NOT NULL,
+    updated_at      DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_reviews_book_user (book_id, user_id),
+    INDEX idx_reviews_user_id (user_id),
+    INDEX idx_reviews_status (status),
+    INDEX idx_reviews_created_at (created_at)
+);
+
+ALTER TABLE books
+    ADD COLUMN rating_sum BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN rating_count BIGINT NOT NULL DEFAULT 0,
+    ADD INDEX idx_books_rating_count (rating_count);
@@ -0,0 +1,26 @@
+-- Keeps users' reading lists and their reading progress.
+
+CREATE TABLE IF NOT EXISTS reading_lists (
+    id          CHAR(36)     NOT NULL,
+    user_id     CHAR(36)     NOT NULL,
+    name        VARCHAR(100) NOT NULL,
+    kind        VARCHAR(20)  NOT NULL,
+    share_token VARCHAR(64),
+    created_at  DATETIME(3)  NOT NULL,
+    updated_at  DATETIME(3)  NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_reading_lists_user_name (user_id, name),
+    UNIQUE INDEX idx_reading_lists_share_token (share_token)
+);
+
+CREATE TABLE IF NOT EXISTS reading_list_items (
+    list_id    CHAR(36)    NOT NULL,
+    book_id    CHAR(36)    NOT NULL,
+    position   BIGINT      NOT NULL,
+    page       BIGINT      NOT NULL DEFAULT 0,
+    percent    BIGINT      NOT NULL DEFAULT 0,
+    created_at DATETIME(3) NOT NULL,
+    updated_at DATETIME(3) NOT NULL,
+    PRIMARY KEY (list_id, book_id),
+    INDEX idx_reading_list_items_book_id (book_id)
+);
@@ -0,0 +1,25 @@
+-- Counts the books each user views, and the similar books worked out from
+-- them for recommendations.
+
+CREATE TABLE IF NOT EXISTS book_views (
+    id             CHAR(36)    NOT NULL,
+    user_id        CHAR(36)    NOT NULL,
+    book_id        CHAR(36)    NOT NULL,
+    views          BIGINT      NOT NULL DEFAULT 1,
+    last_viewed_at DATETIME(3) NOT NULL,
+    created_at     DATETIME(3) NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE INDEX idx_book_views_user_book (user_id, book_id),
+    INDEX idx_book_views_book_id (book_id),
+    INDEX idx_book_views_last_viewed_at (last_viewed_at)
+);
+
+CREATE TABLE IF NOT EXISTS book_similarities (
+    book_id         CHAR(36)    NOT NULL,
+    similar_book_id CHAR(36)    NOT NULL,
+    score           DOUBLE      NOT NULL,
+    co_views        BIGINT      NOT NULL DEFAULT 0,
+    updated_at      DATETIME(3) NOT NULL,
+    PRIMARY KEY (book_id, similar_book_id),
+    INDEX idx_book_similarities_updated_at (updated_at)
+);